@@ -7,19 +7,90 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 	"web3-ecommerce-app/internal/config"
+	orderDomain "web3-ecommerce-app/internal/domain/order"
+	productDomain "web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/address"
+	addressHandler "web3-ecommerce-app/internal/module/address/handler"
+	addressRepo "web3-ecommerce-app/internal/module/address/repository"
+	addressService "web3-ecommerce-app/internal/module/address/service"
 	"web3-ecommerce-app/internal/module/admin"
 	adminHandler "web3-ecommerce-app/internal/module/admin/handler"
 	adminRepo "web3-ecommerce-app/internal/module/admin/repository"
 	adminService "web3-ecommerce-app/internal/module/admin/service"
+	"web3-ecommerce-app/internal/module/cart"
+	cartHandler "web3-ecommerce-app/internal/module/cart/handler"
+	cartRepo "web3-ecommerce-app/internal/module/cart/repository"
+	cartService "web3-ecommerce-app/internal/module/cart/service"
+	"web3-ecommerce-app/internal/module/digital"
+	digitalHandler "web3-ecommerce-app/internal/module/digital/handler"
+	digitalRepo "web3-ecommerce-app/internal/module/digital/repository"
+	digitalService "web3-ecommerce-app/internal/module/digital/service"
+	"web3-ecommerce-app/internal/module/giftcard"
+	giftCardHandler "web3-ecommerce-app/internal/module/giftcard/handler"
+	giftCardRepo "web3-ecommerce-app/internal/module/giftcard/repository"
+	giftCardService "web3-ecommerce-app/internal/module/giftcard/service"
+	"web3-ecommerce-app/internal/module/invoice"
+	invoiceHandler "web3-ecommerce-app/internal/module/invoice/handler"
+	invoiceRepo "web3-ecommerce-app/internal/module/invoice/repository"
+	invoiceService "web3-ecommerce-app/internal/module/invoice/service"
+	"web3-ecommerce-app/internal/module/merchant"
+	merchantHandler "web3-ecommerce-app/internal/module/merchant/handler"
+	merchantRepo "web3-ecommerce-app/internal/module/merchant/repository"
+	merchantService "web3-ecommerce-app/internal/module/merchant/service"
+	"web3-ecommerce-app/internal/module/order"
+	orderHandler "web3-ecommerce-app/internal/module/order/handler"
+	orderSN "web3-ecommerce-app/internal/module/order/ordersn"
+	orderRepo "web3-ecommerce-app/internal/module/order/repository"
+	orderService "web3-ecommerce-app/internal/module/order/service"
+	"web3-ecommerce-app/internal/module/product"
+	productHandler "web3-ecommerce-app/internal/module/product/handler"
+	productRepo "web3-ecommerce-app/internal/module/product/repository"
+	productSearch "web3-ecommerce-app/internal/module/product/search"
+	productService "web3-ecommerce-app/internal/module/product/service"
+	"web3-ecommerce-app/internal/module/promotion"
+	promotionHandler "web3-ecommerce-app/internal/module/promotion/handler"
+	promotionRepo "web3-ecommerce-app/internal/module/promotion/repository"
+	promotionService "web3-ecommerce-app/internal/module/promotion/service"
+	"web3-ecommerce-app/internal/module/review"
+	reviewHandler "web3-ecommerce-app/internal/module/review/handler"
+	reviewRepo "web3-ecommerce-app/internal/module/review/repository"
+	reviewService "web3-ecommerce-app/internal/module/review/service"
+	"web3-ecommerce-app/internal/module/shipping"
+	shippingHandler "web3-ecommerce-app/internal/module/shipping/handler"
+	shippingRepo "web3-ecommerce-app/internal/module/shipping/repository"
+	shippingService "web3-ecommerce-app/internal/module/shipping/service"
+	"web3-ecommerce-app/internal/module/subscription"
+	subscriptionHandler "web3-ecommerce-app/internal/module/subscription/handler"
+	subscriptionRepo "web3-ecommerce-app/internal/module/subscription/repository"
+	subscriptionService "web3-ecommerce-app/internal/module/subscription/service"
+	taxRepo "web3-ecommerce-app/internal/module/tax/repository"
+	taxService "web3-ecommerce-app/internal/module/tax/service"
 	"web3-ecommerce-app/internal/module/user"
 	"web3-ecommerce-app/internal/module/user/handler"
 	"web3-ecommerce-app/internal/module/user/repository"
 	"web3-ecommerce-app/internal/module/user/service"
+	"web3-ecommerce-app/internal/module/wallet"
+	walletHandler "web3-ecommerce-app/internal/module/wallet/handler"
+	walletRepo "web3-ecommerce-app/internal/module/wallet/repository"
+	walletService "web3-ecommerce-app/internal/module/wallet/service"
+	"web3-ecommerce-app/internal/module/wishlist"
+	wishlistHandler "web3-ecommerce-app/internal/module/wishlist/handler"
+	wishlistRepo "web3-ecommerce-app/internal/module/wishlist/repository"
+	wishlistService "web3-ecommerce-app/internal/module/wishlist/service"
+	"web3-ecommerce-app/internal/platform/blobstore"
+	"web3-ecommerce-app/internal/platform/cache"
+	"web3-ecommerce-app/internal/platform/chain"
 	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/internal/platform/eventbus"
 	"web3-ecommerce-app/internal/platform/httprouter"
+	"web3-ecommerce-app/internal/platform/idempotency"
+	"web3-ecommerce-app/internal/platform/notify"
+	"web3-ecommerce-app/internal/platform/scheduler"
 )
 
 func main() {
@@ -38,23 +109,175 @@ func main() {
 	// 初始化仓库
 	userRepo := repository.NewGormUserRepository(db)
 	adminRepo := adminRepo.NewGormAdminRepository(db)
+	productRepository := productRepo.NewGormProductRepository(db)
+	inventoryRepository := productRepo.NewGormInventoryRepository(db)
+	imageRepository := productRepo.NewGormImageRepository(db)
+	gateRuleRepository := productRepo.NewGormGateRuleRepository(db)
+	priceRepository := productRepo.NewGormPriceRepository(db)
+	reviewRepository := reviewRepo.NewGormReviewRepository(db)
+	promotionRepository := promotionRepo.NewGormPromotionRepository(db)
+	wishlistRepository := wishlistRepo.NewGormWishlistRepository(db)
+	cartRepository := cartRepo.NewGormCartRepository(db)
+	addressRepository := addressRepo.NewGormAddressRepository(db)
+	shippingZoneRepository := shippingRepo.NewGormZoneRepository(db)
+	taxRateRepository := taxRepo.NewGormRateRepository(db)
+	walletRepository := walletRepo.NewGormWalletRepository(db)
+	merchantRepository := merchantRepo.NewGormMerchantRepository(db)
+	subscriptionRepository := subscriptionRepo.NewGormSubscriptionRepository(db)
+	giftCardRepository := giftCardRepo.NewGormGiftCardRepository(db)
+	orderRepository := orderRepo.NewGormOrderRepository(db)
+	snSegmentRepository := orderRepo.NewGormSNSegmentRepository(db)
+	licenseKeyRepository := digitalRepo.NewGormLicenseKeyRepository(db)
+	assetRepository := digitalRepo.NewGormAssetRepository(db)
+	downloadGrantRepository := digitalRepo.NewGormDownloadGrantRepository(db)
+	invoiceDocumentRepository := invoiceRepo.NewGormDocumentRepository(db)
+
+	// 初始化对象存储
+	blobStore, err := blobstore.New(&cfg.Storage)
+	if err != nil {
+		log.Fatalf("对象存储初始化失败: %v", err)
+	}
+	// 数字商品文件使用独立的私有存储，只能通过签名链接下载
+	digitalStore, err := blobstore.New(&cfg.Digital.Storage)
+	if err != nil {
+		log.Fatalf("数字文件存储初始化失败: %v", err)
+	}
+	// 发票PDF同样使用私有存储，开具后的文件不再修改
+	invoiceStore, err := blobstore.New(&cfg.Invoice.Storage)
+	if err != nil {
+		log.Fatalf("发票存储初始化失败: %v", err)
+	}
+
+	// 初始化缓存，未启用Redis时不缓存
+	kvCache := cache.New(&cfg.Redis)
+
+	// 初始化幂等记录存储，移动端重试写请求时返回第一次请求的结果
+	idempotencyStore, err := idempotency.New(&cfg.Idempotency, &cfg.Redis, db)
+	if err != nil {
+		log.Fatalf("幂等记录存储初始化失败: %v", err)
+	}
+	idempotent := middleware.Idempotency(idempotencyStore, &cfg.Idempotency)
+
+	// 初始化通知发送
+	notifier, err := notify.New(&cfg.Notify)
+	if err != nil {
+		log.Fatalf("通知发送初始化失败: %v", err)
+	}
+
+	// 初始化链上查询，用于验证持币门槛
+	chainReader, err := chain.New(&cfg.Web3)
+	if err != nil {
+		log.Fatalf("链上查询初始化失败: %v", err)
+	}
+
+	// 初始化商品搜索索引
+	searchIndex, err := productSearch.NewSearchIndex(&cfg.Search, db)
+	if err != nil {
+		log.Fatalf("搜索索引初始化失败: %v", err)
+	}
+
+	// 初始化订单号生成器
+	snGenerator, err := orderSN.NewGenerator(&cfg.Order.SN, snSegmentRepository)
+	if err != nil {
+		log.Fatalf("订单号生成器初始化失败: %v", err)
+	}
+
+	// 初始化事件总线，用于模块之间传递领域事件
+	bus := eventbus.New()
 
 	// 初始化服务
 	userService := service.NewUserService(userRepo, &cfg.JWT, &cfg.Web3)
+	productSvc := productService.NewProductService(productRepository, priceRepository, searchIndex, bus)
+	inventorySvc := productService.NewInventoryService(inventoryRepository, productSvc, &cfg.Payment)
+	imageSvc := productService.NewImageService(productRepository, imageRepository, blobStore, &cfg.Image)
+	catalogSvc := productService.NewCatalogService(productSvc, productRepository)
+	priceSvc := productService.NewPriceService(productRepository, priceRepository)
+	gateSvc := productService.NewGateService(gateRuleRepository, productRepository, userRepo, chainReader, cfg.Web3.GateCacheTTL)
+	digitalSvc := digitalService.NewDigitalService(licenseKeyRepository, assetRepository, downloadGrantRepository, productSvc, digitalStore, &cfg.Digital)
+	promotionSvc := promotionService.NewPromotionService(promotionRepository, productRepository, gateSvc)
+	wishlistSvc := wishlistService.NewWishlistService(wishlistRepository, productRepository, notifier)
+	cartSvc := cartService.NewCartService(cartRepository, productRepository, kvCache, &cfg.Cart)
+	addressSvc := addressService.NewAddressService(addressRepository)
+	shippingSvc := shippingService.NewShippingService(shippingZoneRepository, productRepository)
+	walletSvc := walletService.NewWalletService(walletRepository)
+	taxSvc := taxService.NewTaxService(taxRateRepository, &cfg.Tax)
+	giftCardSvc := giftCardService.NewGiftCardService(giftCardRepository, orderRepository, productRepository, walletSvc, notifier)
+	orderSvc := orderService.NewOrderService(orderRepository, productRepository, inventoryRepository, merchantRepository, productSvc, gateSvc, promotionSvc, digitalSvc, cartSvc, addressSvc, shippingSvc, walletSvc, giftCardSvc, taxSvc, snGenerator, bus, &cfg.Payment)
+	merchantSvc := merchantService.NewMerchantService(merchantRepository, userRepo, productSvc, &cfg.Merchant)
+	subscriptionSvc := subscriptionService.NewSubscriptionService(subscriptionRepository, productRepository, orderSvc, notifier, &cfg.Subscription)
+	invoiceSvc := invoiceService.NewInvoiceService(invoiceDocumentRepository, orderSvc, invoiceStore, &cfg.Invoice)
+	// 订单服务提供购买记录校验，只有买过商品的用户才能评价
+	reviewSvc := reviewService.NewReviewService(reviewRepository, productRepository, orderSvc)
+
+	// 订阅领域事件
+	bus.Subscribe(productDomain.EventProductRestocked, wishlistSvc.HandleRestock)
+	bus.Subscribe(orderDomain.EventOrderPaid, subscriptionSvc.HandleOrderPaid)
+	bus.Subscribe(orderDomain.EventOrderPaid, giftCardSvc.HandleOrderPaid)
+
+	// 内存索引在启动时需要从数据库全量构建
+	if cfg.Search.Driver == "memory" {
+		if err := productSvc.RebuildSearchIndex(context.Background()); err != nil {
+			log.Fatalf("构建搜索索引失败: %v", err)
+		}
+	}
 
 	// 初始化管理后台服务
-	adminSvc := adminService.NewAdminService(adminRepo, userRepo, userService)
+	adminSvc := adminService.NewAdminService(adminRepo, userRepo, userService, productSvc, imageSvc, catalogSvc, priceSvc, gateSvc, promotionSvc, reviewSvc, digitalSvc, orderSvc, shippingSvc, walletSvc, taxSvc, merchantSvc, subscriptionSvc, giftCardSvc)
 
 	// 初始化处理器
 	userHandler := handler.NewUserHTTPHandler(userService)
+	productHTTPHandler := productHandler.NewProductHTTPHandler(productSvc, gateSvc, priceSvc)
+	promotionHTTPHandler := promotionHandler.NewPromotionHTTPHandler(promotionSvc)
+	orderHTTPHandler := orderHandler.NewOrderHTTPHandler(orderSvc)
+	cartHTTPHandler := cartHandler.NewCartHTTPHandler(cartSvc, &cfg.Cart)
+	addressHTTPHandler := addressHandler.NewAddressHTTPHandler(addressSvc)
+	shippingHTTPHandler := shippingHandler.NewShippingHTTPHandler(shippingSvc)
+	walletHTTPHandler := walletHandler.NewWalletHTTPHandler(walletSvc)
+	wishlistHTTPHandler := wishlistHandler.NewWishlistHTTPHandler(wishlistSvc)
+	reviewHTTPHandler := reviewHandler.NewReviewHTTPHandler(reviewSvc)
+	digitalHTTPHandler := digitalHandler.NewDigitalHTTPHandler(digitalSvc)
+	invoiceHTTPHandler := invoiceHandler.NewInvoiceHTTPHandler(invoiceSvc)
+	merchantHTTPHandler := merchantHandler.NewMerchantHTTPHandler(merchantSvc, orderSvc)
+	subscriptionHTTPHandler := subscriptionHandler.NewSubscriptionHTTPHandler(subscriptionSvc)
+	giftCardHTTPHandler := giftCardHandler.NewGiftCardHTTPHandler(giftCardSvc)
 	adminHandler := adminHandler.NewAdminHTTPHandler(adminSvc)
 
 	// 初始化HTTP路由器,创建对应的gin引擎
 	router := httprouter.NewGinEngine(&cfg.Server)
 
+	// 使用本地存储时由服务器直接提供上传的文件
+	if cfg.Storage.Driver == "local" && strings.HasPrefix(cfg.Storage.PublicURL, "/") {
+		router.Static(cfg.Storage.PublicURL, cfg.Storage.LocalDir)
+	}
+
 	// 注册路由
 	user.RegisterRoutes(router, userHandler, &cfg.JWT)
-	admin.RegisterRoutes(router, adminHandler, &cfg.JWT)
+	product.RegisterRoutes(router, productHTTPHandler, &cfg.JWT)
+	promotion.RegisterRoutes(router, promotionHTTPHandler, &cfg.JWT)
+	review.RegisterRoutes(router, reviewHTTPHandler, &cfg.JWT)
+	digital.RegisterRoutes(router, digitalHTTPHandler, &cfg.JWT)
+	order.RegisterRoutes(router, orderHTTPHandler, &cfg.JWT, idempotent)
+	invoice.RegisterRoutes(router, invoiceHTTPHandler, &cfg.JWT)
+	cart.RegisterRoutes(router, cartHTTPHandler, &cfg.JWT)
+	wishlist.RegisterRoutes(router, wishlistHTTPHandler, &cfg.JWT)
+	address.RegisterRoutes(router, addressHTTPHandler, &cfg.JWT)
+	shipping.RegisterRoutes(router, shippingHTTPHandler)
+	wallet.RegisterRoutes(router, walletHTTPHandler, &cfg.JWT, idempotent)
+	merchant.RegisterRoutes(router, merchantHTTPHandler, &cfg.JWT, idempotent)
+	subscription.RegisterRoutes(router, subscriptionHTTPHandler, &cfg.JWT, idempotent)
+	giftcard.RegisterRoutes(router, giftCardHTTPHandler, &cfg.JWT, idempotent)
+	admin.RegisterRoutes(router, adminHandler, &cfg.JWT, idempotent)
+
+	// 启动后台定时任务，服务器关闭时一并停止
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go scheduler.Every(workerCtx, "释放过期库存预占", cfg.Inventory.ReleaseInterval, inventorySvc.ReleaseExpired)
+	go scheduler.Every(workerCtx, "商品定时上下架", cfg.Product.ScheduleInterval, productSvc.RunScheduledTransitions)
+	go scheduler.Every(workerCtx, "商品定时调价", cfg.Product.ScheduleInterval, priceSvc.RunScheduledPriceChanges)
+	go scheduler.Every(workerCtx, "清理过期购物车", cfg.Cart.CleanupInterval, cartSvc.CleanupExpired)
+	go scheduler.Every(workerCtx, "关闭超时未支付订单", cfg.Order.ExpireInterval, orderSvc.ExpireUnpaidOrders)
+	go scheduler.Every(workerCtx, "订阅续费和催缴", cfg.Subscription.BillingInterval, subscriptionSvc.RunBilling)
+	go scheduler.Every(workerCtx, "清理过期幂等记录", cfg.Idempotency.CleanupInterval, idempotencyStore.DeleteExpired)
 
 	// 创建HTTP服务器
 	server := &http.Server{
@@ -81,6 +304,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("正在关闭服务器...")
+	stopWorkers()
 
 	// 设置5秒的超时时间
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -89,5 +313,8 @@ func main() {
 		log.Fatal("服务器被强制关闭:", err)
 	}
 
+	// 等待正在处理的事件完成，例如已认领但尚未发出的到货通知
+	bus.Wait()
+
 	log.Println("服务器已优雅关闭")
 }
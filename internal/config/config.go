@@ -8,11 +8,26 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Web3     Web3Config
+	Server       ServerConfig
+	Database     DatabaseConfig
+	Redis        RedisConfig
+	JWT          JWTConfig
+	Web3         Web3Config
+	Payment      PaymentConfig
+	Inventory    InventoryConfig
+	Product      ProductConfig
+	Storage      StorageConfig
+	Image        ImageConfig
+	Search       SearchConfig
+	Digital      DigitalConfig
+	Notify       NotifyConfig
+	Cart         CartConfig
+	Order        OrderConfig
+	Tax          TaxConfig
+	Invoice      InvoiceConfig
+	Idempotency  IdempotencyConfig
+	Merchant     MerchantConfig
+	Subscription SubscriptionConfig
 }
 
 type ServerConfig struct {
@@ -34,6 +49,7 @@ type DatabaseConfig struct {
 }
 
 type RedisConfig struct {
+	Enabled  bool // 未启用时不使用缓存，所有读取直接访问数据库
 	Host     string
 	Port     int
 	Password string
@@ -46,9 +62,119 @@ type JWTConfig struct {
 }
 
 type Web3Config struct {
-	RPCURL      string `mapstructure:"rpc_url"`
-	ChainID     int    `mapstructure:"chain_id"`
-	NonceExpire time.Duration
+	RPCURL       string `mapstructure:"rpc_url"`
+	ChainID      int    `mapstructure:"chain_id"`
+	NonceExpire  time.Duration
+	RPCURLs      map[string]string `mapstructure:"rpc_urls"`       // 其它链的节点地址，key为链ID
+	ChainReader  string            `mapstructure:"chain_reader"`   // rpc 或 fake(本地开发)
+	GateCacheTTL time.Duration     `mapstructure:"gate_cache_ttl"` // 持仓查询结果的缓存时长
+}
+
+type PaymentConfig struct {
+	PaymentWindow time.Duration `mapstructure:"payment_window"` // 下单后等待链上支付的时间
+}
+
+type InventoryConfig struct {
+	ReleaseInterval time.Duration `mapstructure:"release_interval"` // 扫描并释放过期库存预占的间隔
+}
+
+type ProductConfig struct {
+	ScheduleInterval time.Duration `mapstructure:"schedule_interval"` // 扫描定时上下架商品和定时调价的间隔
+}
+
+type StorageConfig struct {
+	Driver    string // local 或 s3
+	LocalDir  string `mapstructure:"local_dir"`
+	PublicURL string `mapstructure:"public_url"` // 文件对外访问的URL前缀
+	S3        S3Config
+}
+
+type S3Config struct {
+	Endpoint     string
+	Region       string
+	Bucket       string
+	AccessKey    string `mapstructure:"access_key"`
+	SecretKey    string `mapstructure:"secret_key"`
+	UsePathStyle bool   `mapstructure:"use_path_style"` // MinIO等自建服务通常需要路径风格
+}
+
+type ImageConfig struct {
+	MaxSize         int64 `mapstructure:"max_size"`         // 上传图片的最大字节数
+	ThumbnailWidths []int `mapstructure:"thumbnail_widths"` // 需要生成的缩略图宽度
+}
+
+type SearchConfig struct {
+	Driver string // mysql 使用FULLTEXT索引，memory 使用内存倒排索引
+}
+
+type DigitalConfig struct {
+	SigningSecret string        `mapstructure:"signing_secret"` // 下载链接HMAC签名密钥
+	LinkTTL       time.Duration `mapstructure:"link_ttl"`       // 下载链接有效期
+	DownloadLimit int           `mapstructure:"download_limit"` // 每个订单每件商品的最大下载次数
+	MaxFileSize   int64         `mapstructure:"max_file_size"`  // 上传数字文件的最大字节数
+	Storage       StorageConfig // 数字文件的私有存储，不能与公开的图片存储共用
+}
+
+type NotifyConfig struct {
+	Driver        string // log 或 webhook
+	WebhookURL    string `mapstructure:"webhook_url"`
+	WebhookSecret string `mapstructure:"webhook_secret"` // 用于对请求体签名
+	Timeout       time.Duration
+}
+
+type CartConfig struct {
+	GuestTTL        time.Duration `mapstructure:"guest_ttl"`        // 游客购物车在最后一次修改后的保留时长
+	CacheTTL        time.Duration `mapstructure:"cache_ttl"`        // 购物车在Redis中的缓存时长
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"` // 清理过期游客购物车的间隔
+	MaxItems        int           `mapstructure:"max_items"`        // 购物车中商品种类的上限
+	MaxQuantity     int           `mapstructure:"max_quantity"`     // 单个商品的数量上限
+	CookieSecure    bool          `mapstructure:"cookie_secure"`    // 游客购物车Cookie是否只通过HTTPS发送
+}
+
+type OrderConfig struct {
+	ExpireInterval time.Duration `mapstructure:"expire_interval"` // 扫描并关闭超时未支付订单的间隔
+	SN             OrderSNConfig `mapstructure:"sn"`
+}
+
+type OrderSNConfig struct {
+	ShopCode    string `mapstructure:"shop_code"`   // 订单号开头的店铺代码，最多8位大写字母或数字，可为空
+	DateLayout  string `mapstructure:"date_layout"` // 日期部分的Go时间格式，只能包含数字，默认060102；snowflake模式下必须包含年月日
+	Timezone    string // 日期部分使用的时区，默认服务器本地时区
+	Sequence    string // snowflake(默认) 或 segment
+	NodeID      int    `mapstructure:"node_id"`      // snowflake模式下每个实例唯一的节点号，0-1023
+	SegmentStep int    `mapstructure:"segment_step"` // segment模式下每次从数据库申请的序号数量
+}
+
+type TaxConfig struct {
+	Mode string // exclusive(默认): 标价不含税，结算时加税; inclusive: 标价已含税
+}
+
+type InvoiceConfig struct {
+	SellerName       string        `mapstructure:"seller_name"`
+	SellerAddress    string        `mapstructure:"seller_address"`
+	SellerTaxID      string        `mapstructure:"seller_tax_id"` // 纳税人识别号或VAT号码
+	Currency         string        // 显示在金额旁的币种，如USDT
+	InvoicePrefix    string        `mapstructure:"invoice_prefix"`     // 发票号前缀，默认INV
+	CreditNotePrefix string        `mapstructure:"credit_note_prefix"` // 红字发票号前缀，默认CN
+	Storage          StorageConfig // 已开具的发票文件的私有存储，不能与公开的图片存储共用
+}
+
+type IdempotencyConfig struct {
+	Driver          string        // memory(默认)、database或redis，多实例部署时不能使用memory
+	TTL             time.Duration // 幂等记录的保留时长
+	LockTTL         time.Duration `mapstructure:"lock_ttl"`         // 处理中记录的锁定时长，请求异常中断后超过该时长可以用同一个key重试
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"` // 清理过期记录的间隔，redis自动过期不需要清理
+}
+
+type MerchantConfig struct {
+	CommissionRate int `mapstructure:"commission_rate"` // 新入驻商家的默认佣金比例，万分比，审核时可单独调整
+}
+
+type SubscriptionConfig struct {
+	BillingInterval time.Duration `mapstructure:"billing_interval"` // 扫描到期订阅、生成续费订单和发送催缴通知的间隔
+	RenewAhead      time.Duration `mapstructure:"renew_ahead"`      // 在周期结束前多久生成续费订单
+	GracePeriod     time.Duration `mapstructure:"grace_period"`     // 周期结束后等待续费付款的时长，超过后自动取消订阅
+	DunningInterval time.Duration `mapstructure:"dunning_interval"` // 宽限期内两次催缴通知的最小间隔
 }
 
 // LoadConfig 从指定路径加载配置文件
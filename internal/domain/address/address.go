@@ -0,0 +1,58 @@
+package address
+
+import (
+	"context"
+	"time"
+	"web3-ecommerce-app/internal/domain/order"
+)
+
+// Address 用户地址簿中的收货地址
+type Address struct {
+	ID        uint   `json:"id"`
+	UserID    uint   `json:"user_id"`
+	Label     string `json:"label,omitempty"` // 用户自定义的名称，如"家"、"公司"
+	IsDefault bool   `json:"is_default"`
+	order.Address
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Snapshot 复制一份地址内容用于写入订单
+func (a *Address) Snapshot() *order.Address {
+	snapshot := a.Address
+	return &snapshot
+}
+
+// AddressRepository 地址簿仓库接口
+type AddressRepository interface {
+	// FindByUser 查询用户的全部地址，默认地址在前，其余按创建时间倒序
+	FindByUser(ctx context.Context, userID uint) ([]Address, error)
+
+	// FindByID 查询用户的某个地址，其他用户的地址视为不存在
+	FindByID(ctx context.Context, userID uint, id uint) (*Address, error)
+
+	// FindDefault 查询用户的默认地址，没有时返回nil
+	FindDefault(ctx context.Context, userID uint) (*Address, error)
+
+	// Count 统计用户的地址数量
+	Count(ctx context.Context, userID uint) (int, error)
+
+	// Create 创建地址，IsDefault为true时同时取消其它地址的默认状态
+	Create(ctx context.Context, address *Address) error
+
+	// Update 更新地址内容和名称，不修改默认状态
+	Update(ctx context.Context, address *Address) error
+
+	// Delete 删除地址，删除的是默认地址时将最近创建的地址设为默认
+	Delete(ctx context.Context, userID uint, id uint) error
+
+	// SetDefault 将地址设为默认，同时取消其它地址的默认状态
+	SetDefault(ctx context.Context, userID uint, id uint) error
+}
+
+// AddressInput 新增或修改地址的输入参数
+type AddressInput struct {
+	Label     string `json:"label" binding:"max=30"`
+	IsDefault bool   `json:"is_default"` // 修改地址时忽略，请使用设为默认的接口
+	order.Address
+}
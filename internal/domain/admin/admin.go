@@ -27,6 +27,20 @@ type ProductFilter struct {
 	Search     string
 }
 
+// ReviewFilter 评价过滤条件，Status为空时返回待审核队列
+type ReviewFilter struct {
+	PaginationParam
+	ProductID uint
+	Status    string
+}
+
+// PromotionFilter 促销过滤条件
+type PromotionFilter struct {
+	PaginationParam
+	Active *bool
+	Code   string
+}
+
 // OrderFilter 订单过滤条件
 type OrderFilter struct {
 	PaginationParam
@@ -0,0 +1,110 @@
+package cart
+
+import (
+	"context"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/product"
+)
+
+// 购物车商品的问题，结算前需要用户处理
+const (
+	IssueUnavailable       = "unavailable"        // 商品已删除或已下架
+	IssueOutOfStock        = "out_of_stock"       // 商品已售罄
+	IssueInsufficientStock = "insufficient_stock" // 可售库存少于购物车中的数量
+)
+
+// Cart 购物车
+// 登录用户的购物车按用户ID识别，游客的购物车按Cookie中的令牌识别
+type Cart struct {
+	ID        uint       `json:"id"`
+	UserID    *uint      `json:"user_id,omitempty"`
+	Token     string     `json:"-"`
+	Items     []Item     `json:"items"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // 游客购物车的过期时间
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Item 购物车中的商品
+type Item struct {
+	ProductID uint         `json:"product_id"`
+	Quantity  int          `json:"quantity"`
+	UnitPrice common.Money `json:"unit_price"` // 用户最近一次看到的单价，用于发现价格变化
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// Owner 购物车的归属，UserID和Token二选一
+type Owner struct {
+	UserID uint
+	Token  string
+}
+
+// IsGuest 是否为游客
+func (o Owner) IsGuest() bool {
+	return o.UserID == 0
+}
+
+// View 按商品最新价格和库存计算后的购物车
+type View struct {
+	Items     []Line       `json:"items"`
+	ItemCount int          `json:"item_count"` // 商品总件数
+	Subtotal  common.Money `json:"subtotal"`   // 不含有问题的商品
+	Checkout  bool         `json:"checkout"`   // 购物车非空且没有需要处理的问题时才能结算
+	UpdatedAt time.Time    `json:"updated_at"`
+	Token     string       `json:"-"` // 游客购物车的令牌，由处理器写入Cookie
+}
+
+// Line 购物车中的一行
+type Line struct {
+	ProductID     uint             `json:"product_id"`
+	Product       *product.Product `json:"product,omitempty"` // 商品已删除时为空
+	Quantity      int              `json:"quantity"`
+	UnitPrice     common.Money     `json:"unit_price"`               // 当前售价
+	PreviousPrice *common.Money    `json:"previous_price,omitempty"` // 价格自上次查看后发生变化时为原价格
+	LineTotal     common.Money     `json:"line_total"`
+	Available     int              `json:"available"` // 当前可售库存
+	Issue         string           `json:"issue,omitempty"`
+}
+
+// CartRepository 购物车仓库接口
+type CartRepository interface {
+	// FindByUser 查询用户的购物车，不存在时返回nil
+	FindByUser(ctx context.Context, userID uint) (*Cart, error)
+
+	// FindByToken 查询未过期的游客购物车，不存在时返回nil
+	FindByToken(ctx context.Context, token string, now time.Time) (*Cart, error)
+
+	// Create 创建空购物车
+	Create(ctx context.Context, cart *Cart) error
+
+	// SetItem 设置商品数量和单价，商品不在购物车中时加入
+	// expiresAt不为空时同时延长游客购物车的有效期
+	SetItem(ctx context.Context, cartID uint, item Item, expiresAt *time.Time) error
+
+	// RemoveItem 从购物车移除商品，返回是否存在
+	RemoveItem(ctx context.Context, cartID uint, productID uint) (bool, error)
+
+	// Clear 清空购物车
+	Clear(ctx context.Context, cartID uint) error
+
+	// UpdatePrices 更新商品单价，用户看到新价格后调用
+	UpdatePrices(ctx context.Context, cartID uint, prices map[uint]common.Money) error
+
+	// Merge 将游客购物车合并到用户购物车并删除游客购物车
+	// 两边都有的商品数量相加，不超过maxQuantity
+	Merge(ctx context.Context, fromID uint, toID uint, maxQuantity int) error
+
+	// DeleteExpired 删除已过期的游客购物车，返回删除数量
+	DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// SetItemInput 加入购物车或修改数量的输入参数
+type SetItemInput struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,gte=1"`
+}
+
+// UpdateItemInput 修改购物车商品数量的输入参数
+type UpdateItemInput struct {
+	Quantity int `json:"quantity" binding:"required,gte=1"`
+}
@@ -0,0 +1,153 @@
+package digital
+
+import (
+	"context"
+	"time"
+)
+
+// LicenseKey 表示数字商品激活码池中的一个激活码
+// 管理员批量导入，订单支付确认后按导入顺序分配给买家
+type LicenseKey struct {
+	ID         uint       `json:"id"`
+	ProductID  uint       `json:"product_id"`
+	Key        string     `json:"key"`
+	Status     string     `json:"status"`
+	OrderID    uint       `json:"order_id,omitempty"`
+	UserID     uint       `json:"user_id,omitempty"`
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// LicenseKeyStatus 激活码状态常量
+const (
+	LicenseKeyStatusAvailable = "available" // 未分配
+	LicenseKeyStatusAssigned  = "assigned"  // 已分配给订单
+	LicenseKeyStatusRevoked   = "revoked"   // 订单退款后吊销，已展示给买家，不再分配
+)
+
+// LicenseKeyStats 商品激活码池的库存统计
+type LicenseKeyStats struct {
+	Available int `json:"available"`
+	Assigned  int `json:"assigned"`
+	Revoked   int `json:"revoked"`
+}
+
+// ImportLicenseKeysResult 导入激活码的结果
+type ImportLicenseKeysResult struct {
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"` // 文件内重复或已存在于池中的激活码
+}
+
+// Asset 表示数字商品的可下载文件，保存在私有对象存储中
+type Asset struct {
+	ID          uint      `json:"id"`
+	ProductID   uint      `json:"product_id"`
+	Key         string    `json:"-"` // 对象存储key，只能通过签名链接下载
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DownloadGrant 订单对某个数字商品全部文件的下载授权，限制总下载次数
+type DownloadGrant struct {
+	ID           uint      `json:"id"`
+	OrderID      uint      `json:"order_id"`
+	UserID       uint      `json:"user_id"`
+	ProductID    uint      `json:"product_id"`
+	Downloads    int       `json:"downloads"`
+	MaxDownloads int       `json:"max_downloads"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// RemainingDownloads 返回剩余下载次数
+func (g *DownloadGrant) RemainingDownloads() int {
+	return max(g.MaxDownloads-g.Downloads, 0)
+}
+
+// DeliveryItem 订单中需要发放的商品及数量
+type DeliveryItem struct {
+	ProductID uint
+	Quantity  int
+}
+
+// RevokeItem 退款时需要收回的商品
+type RevokeItem struct {
+	ProductID uint
+	Quantity  int  // 吊销的激活码数量
+	Downloads bool // 是否撤销下载授权，订单行全部退款时撤销
+}
+
+// DownloadLink 带签名和有效期的下载链接
+type DownloadLink struct {
+	AssetID   uint      `json:"asset_id"`
+	FileName  string    `json:"file_name"`
+	Size      int64     `json:"size"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DeliveredProduct 订单中某个数字商品已发放的内容
+type DeliveredProduct struct {
+	ProductID          uint           `json:"product_id"`
+	LicenseKeys        []string       `json:"license_keys"`
+	Downloads          []DownloadLink `json:"downloads"`
+	RemainingDownloads int            `json:"remaining_downloads"`
+}
+
+// Delivery 订单的数字商品发放详情
+type Delivery struct {
+	OrderID  uint               `json:"order_id"`
+	Products []DeliveredProduct `json:"products"`
+}
+
+// LicenseKeyRepository 激活码仓库接口
+type LicenseKeyRepository interface {
+	// Import 批量导入激活码，忽略池中已存在的激活码，返回实际导入数量
+	Import(ctx context.Context, productID uint, keys []string) (int, error)
+
+	// Stats 统计商品激活码池的使用情况
+	Stats(ctx context.Context, productID uint) (*LicenseKeyStats, error)
+
+	// Assign 原子地为订单分配激活码，已分配过的部分不会重复分配，可用激活码不足时整体失败
+	Assign(ctx context.Context, orderID uint, userID uint, productID uint, quantity int) ([]LicenseKey, error)
+
+	// FindByOrder 查询订单已分配的激活码，包括已吊销的激活码
+	FindByOrder(ctx context.Context, orderID uint) ([]LicenseKey, error)
+
+	// Revoke 吊销订单已分配的激活码，返回实际吊销的数量
+	Revoke(ctx context.Context, orderID uint, productID uint, quantity int) (int, error)
+}
+
+// AssetRepository 数字文件仓库接口
+type AssetRepository interface {
+	// FindByID 根据ID查找文件
+	FindByID(ctx context.Context, id uint) (*Asset, error)
+
+	// FindByProduct 查询商品的全部文件
+	FindByProduct(ctx context.Context, productID uint) ([]Asset, error)
+
+	// Create 创建文件记录
+	Create(ctx context.Context, asset *Asset) error
+
+	// Delete 删除文件记录
+	Delete(ctx context.Context, id uint) error
+}
+
+// DownloadGrantRepository 下载授权仓库接口
+type DownloadGrantRepository interface {
+	// FindByID 根据ID查找下载授权
+	FindByID(ctx context.Context, id uint) (*DownloadGrant, error)
+
+	// FindByOrder 查询订单的全部下载授权
+	FindByOrder(ctx context.Context, orderID uint) ([]DownloadGrant, error)
+
+	// CreateIfAbsent 创建下载授权，同一订单同一商品已存在时不做修改
+	CreateIfAbsent(ctx context.Context, grant *DownloadGrant) error
+
+	// ConsumeDownload 原子地消耗一次下载次数，次数已用完时返回false
+	ConsumeDownload(ctx context.Context, id uint) (bool, error)
+
+	// Revoke 撤销订单对商品的下载授权，已签发的下载链接随之失效
+	Revoke(ctx context.Context, orderID uint, productID uint) error
+}
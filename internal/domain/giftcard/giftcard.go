@@ -0,0 +1,261 @@
+package giftcard
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// 礼品卡状态
+const (
+	StatusActive   = "active"   // 可使用
+	StatusDisabled = "disabled" // 已停用，如卡号泄露
+	StatusVoided   = "voided"   // 来源订单退款后作废，不能再启用
+)
+
+// 礼品卡来源
+const (
+	SourceAdmin = "admin" // 管理员发放
+	SourceOrder = "order" // 用户购买礼品卡商品
+)
+
+// GiftCard 礼品卡
+// 兑换码只在发放时返回一次，数据库中只保存哈希和末四位，无法找回完整的兑换码
+type GiftCard struct {
+	ID            uint         `json:"id"`
+	CodeHash      string       `json:"-"`
+	Last4         string       `json:"last4"` // 兑换码末四位，用于展示和核对
+	InitialAmount common.Money `json:"initial_amount"`
+	Balance       common.Money `json:"balance"`
+	Status        string       `json:"status"`
+	Source        string       `json:"source"`
+	OrderID       uint         `json:"order_id,omitempty"`     // 购买礼品卡的订单，管理员发放时为0
+	ProductID     uint         `json:"product_id,omitempty"`   // 购买的礼品卡商品
+	PurchaserID   uint         `json:"purchaser_id,omitempty"` // 购买礼品卡的用户
+	IssuedBy      uint         `json:"issued_by,omitempty"`    // 发放的管理员ID
+	Note          string       `json:"note,omitempty"`
+	ExpiresAt     *time.Time   `json:"expires_at,omitempty"` // 为空表示不过期
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// IsExpired 礼品卡在now时是否已过期
+func (g *GiftCard) IsExpired(now time.Time) bool {
+	return g.ExpiresAt != nil && !now.Before(*g.ExpiresAt)
+}
+
+// CheckUsable 校验礼品卡在now时可以兑换
+func (g *GiftCard) CheckUsable(now time.Time) error {
+	if g.Status != StatusActive {
+		return apierror.NewInvalidStateTransitionError("礼品卡已停用", fmt.Sprintf("尾号: %s", g.Last4))
+	}
+	if g.IsExpired(now) {
+		return apierror.NewInvalidStateTransitionError("礼品卡已过期", fmt.Sprintf("尾号: %s", g.Last4))
+	}
+	if g.Balance <= 0 {
+		return apierror.NewInvalidStateTransitionError("礼品卡余额不足", fmt.Sprintf("尾号: %s", g.Last4))
+	}
+	return nil
+}
+
+// Redeem 从礼品卡中兑换最多amount的金额，返回实际兑换的金额
+// 余额不足amount时兑换全部余额，剩余部分由调用方通过其他方式支付
+func (g *GiftCard) Redeem(amount common.Money, now time.Time) (common.Money, error) {
+	if err := g.CheckUsable(now); err != nil {
+		return 0, err
+	}
+	if amount <= 0 {
+		return 0, apierror.NewValidationError("无效的兑换金额", fmt.Sprintf("兑换金额必须大于0: %s", amount))
+	}
+	redeemed := min(amount, g.Balance)
+	g.Balance -= redeemed
+	return redeemed, nil
+}
+
+// IsUnused 礼品卡是否从未兑换过
+func (g *GiftCard) IsUnused() bool {
+	return g.Balance == g.InitialAmount
+}
+
+// Void 来源订单退款时作废礼品卡，已兑换过的礼品卡不能作废
+func (g *GiftCard) Void() error {
+	if g.Status == StatusVoided {
+		return apierror.NewInvalidStateTransitionError("礼品卡已作废", fmt.Sprintf("尾号: %s", g.Last4))
+	}
+	if !g.IsUnused() {
+		return apierror.NewInvalidStateTransitionError("礼品卡已被使用，不能退款", fmt.Sprintf("尾号: %s, 余额: %s", g.Last4, g.Balance))
+	}
+	g.Status = StatusVoided
+	return nil
+}
+
+// ChangeStatus 启用或停用礼品卡
+func (g *GiftCard) ChangeStatus(status string) error {
+	switch status {
+	case StatusActive, StatusDisabled:
+	default:
+		return apierror.NewValidationError("无效的礼品卡状态", status)
+	}
+	if g.Status == StatusVoided {
+		return apierror.NewInvalidStateTransitionError("礼品卡已作废", fmt.Sprintf("ID: %d", g.ID))
+	}
+	if g.Status == status {
+		return apierror.NewInvalidStateTransitionError("礼品卡已处于该状态", fmt.Sprintf("ID: %d, 状态: %s", g.ID, status))
+	}
+	g.Status = status
+	return nil
+}
+
+// BalanceView 凭兑换码查询到的礼品卡余额，不包含来源等内部信息
+type BalanceView struct {
+	Last4     string       `json:"last4"`
+	Balance   common.Money `json:"balance"`
+	Status    string       `json:"status"`
+	Expired   bool         `json:"expired"`
+	ExpiresAt *time.Time   `json:"expires_at,omitempty"`
+}
+
+// View 返回礼品卡在now时的余额信息
+func (g *GiftCard) View(now time.Time) *BalanceView {
+	return &BalanceView{
+		Last4:     g.Last4,
+		Balance:   g.Balance,
+		Status:    g.Status,
+		Expired:   g.IsExpired(now),
+		ExpiresAt: g.ExpiresAt,
+	}
+}
+
+// IssuedGiftCard 刚发放的礼品卡及其完整兑换码
+type IssuedGiftCard struct {
+	GiftCard
+	Code string `json:"code"`
+}
+
+// Redemption 礼品卡的兑换记录，兑换的金额记入用户的购物金
+type Redemption struct {
+	ID           uint         `json:"id"`
+	GiftCardID   uint         `json:"gift_card_id"`
+	UserID       uint         `json:"user_id"`
+	OrderID      uint         `json:"order_id,omitempty"` // 下单时抵扣的订单，直接兑换到余额时为0
+	Amount       common.Money `json:"amount"`
+	BalanceAfter common.Money `json:"balance_after"` // 兑换后礼品卡的余额
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// Denomination 礼品卡商品的面额，用户购买该商品并支付后按购买数量发放礼品卡
+type Denomination struct {
+	ProductID uint         `json:"product_id"`
+	Amount    common.Money `json:"amount"`
+	ValidDays int          `json:"valid_days"` // 有效天数，0表示不过期
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// ExpiresAt 按有效天数计算从issuedAt开始的过期时间，不过期时返回nil
+func (d *Denomination) ExpiresAt(issuedAt time.Time) *time.Time {
+	if d.ValidDays <= 0 {
+		return nil
+	}
+	expiresAt := issuedAt.AddDate(0, 0, d.ValidDays)
+	return &expiresAt
+}
+
+// GiftCardQuery 礼品卡查询条件
+type GiftCardQuery struct {
+	Status      string // 为空时不限
+	Source      string // 为空时不限
+	PurchaserID uint   // 为0时不限
+	OrderID     uint   // 为0时不限
+	Page        int
+	PageSize    int
+}
+
+// GiftCardPaginationResult 礼品卡分页结果
+type GiftCardPaginationResult struct {
+	Total     int        `json:"total"`
+	GiftCards []GiftCard `json:"gift_cards"`
+}
+
+// GiftCardRepository 礼品卡仓库接口
+type GiftCardRepository interface {
+	// Create 创建礼品卡，兑换码哈希重复时返回错误
+	Create(ctx context.Context, card *GiftCard) error
+
+	// FindByID 根据ID查询礼品卡
+	FindByID(ctx context.Context, id uint) (*GiftCard, error)
+
+	// FindByCodeHash 根据兑换码哈希查询礼品卡
+	FindByCodeHash(ctx context.Context, codeHash string) (*GiftCard, error)
+
+	// LockByID 在事务中锁定礼品卡行并返回最新的礼品卡
+	LockByID(ctx context.Context, id uint) (*GiftCard, error)
+
+	// LockByCodeHash 在事务中锁定礼品卡行并返回最新的礼品卡，用于串行化同一张卡的兑换
+	LockByCodeHash(ctx context.Context, codeHash string) (*GiftCard, error)
+
+	// Find 按条件分页查询礼品卡，按创建时间倒序
+	Find(ctx context.Context, query GiftCardQuery) (*GiftCardPaginationResult, error)
+
+	// CountByOrder 统计订单已发放的礼品卡数量
+	CountByOrder(ctx context.Context, orderID uint) (int, error)
+
+	// LockByOrder 在事务中按ID顺序锁定订单购买的礼品卡，用于串行化退款作废和兑换
+	LockByOrder(ctx context.Context, orderID uint) ([]GiftCard, error)
+
+	// Update 更新礼品卡的余额、状态、过期时间和备注
+	Update(ctx context.Context, card *GiftCard) error
+
+	// CreateRedemption 记录兑换
+	CreateRedemption(ctx context.Context, redemption *Redemption) error
+
+	// FindRedemptions 按时间顺序查询礼品卡的兑换记录
+	FindRedemptions(ctx context.Context, giftCardID uint) ([]Redemption, error)
+
+	// FindDenomination 查询商品的礼品卡面额，商品不是礼品卡商品时返回nil
+	FindDenomination(ctx context.Context, productID uint) (*Denomination, error)
+
+	// FindDenominations 查询全部礼品卡商品的面额
+	FindDenominations(ctx context.Context) ([]Denomination, error)
+
+	// SaveDenomination 创建或更新商品的礼品卡面额
+	SaveDenomination(ctx context.Context, denomination *Denomination) error
+
+	// DeleteDenomination 删除商品的礼品卡面额，已发放的礼品卡不受影响
+	DeleteDenomination(ctx context.Context, productID uint) error
+
+	// Transaction 在事务中执行fn，fn内通过ctx调用的仓库方法共用同一个事务
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// IssueInput 管理员发放礼品卡的输入参数
+type IssueInput struct {
+	Amount    common.Money `json:"amount" binding:"gt=0"`
+	Quantity  int          `json:"quantity" binding:"omitempty,gte=1,lte=100"` // 默认为1
+	ExpiresAt *time.Time   `json:"expires_at"`                                 // 为空表示不过期
+	Note      string       `json:"note" binding:"max=500"`
+}
+
+// UpdateInput 更新礼品卡的输入参数，为空的字段不更新
+type UpdateInput struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+	Note      *string    `json:"note" binding:"omitempty,max=500"`
+}
+
+// ChangeStatusInput 修改礼品卡状态的输入参数
+type ChangeStatusInput struct {
+	Status string `json:"status" binding:"required,oneof=active disabled"`
+}
+
+// CodeInput 凭兑换码查询余额或兑换的输入参数
+type CodeInput struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// SaveDenominationInput 设置礼品卡商品面额的输入参数
+type SaveDenominationInput struct {
+	Amount    common.Money `json:"amount" binding:"gt=0"`
+	ValidDays int          `json:"valid_days" binding:"gte=0,lte=3650"`
+}
@@ -0,0 +1,50 @@
+package giftcard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGiftCardVoid(t *testing.T) {
+	tests := []struct {
+		name    string
+		card    GiftCard
+		wantErr bool
+	}{
+		{name: "未使用", card: GiftCard{InitialAmount: 10000, Balance: 10000, Status: StatusActive}},
+		{name: "已停用但未使用", card: GiftCard{InitialAmount: 10000, Balance: 10000, Status: StatusDisabled}},
+		{name: "部分兑换", card: GiftCard{InitialAmount: 10000, Balance: 2500, Status: StatusActive}, wantErr: true},
+		{name: "全部兑换", card: GiftCard{InitialAmount: 10000, Balance: 0, Status: StatusActive}, wantErr: true},
+		{name: "重复作废", card: GiftCard{InitialAmount: 10000, Balance: 10000, Status: StatusVoided}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := tt.card
+			err := card.Void()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Void() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && card.Status != StatusVoided {
+				t.Errorf("status = %s, want %s", card.Status, StatusVoided)
+			}
+			if err != nil && card.Status != tt.card.Status {
+				t.Errorf("作废失败时状态被修改: %s", card.Status)
+			}
+		})
+	}
+}
+
+func TestVoidedGiftCardCannotBeUsed(t *testing.T) {
+	card := GiftCard{InitialAmount: 10000, Balance: 10000, Status: StatusActive}
+	if err := card.Void(); err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+
+	if _, err := card.Redeem(100, time.Now()); err == nil {
+		t.Error("作废的礼品卡仍可兑换")
+	}
+	if err := card.ChangeStatus(StatusActive); err == nil {
+		t.Error("作废的礼品卡被重新启用")
+	}
+}
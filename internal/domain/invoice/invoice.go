@@ -0,0 +1,83 @@
+package invoice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+// 单据类型
+const (
+	TypeInvoice    = "invoice"     // 发票，订单支付后开具
+	TypeCreditNote = "credit_note" // 红字发票，每次退款开具一张，冲减原发票
+)
+
+// 单据语言
+const (
+	LanguageZH = "zh"
+	LanguageEN = "en"
+)
+
+// Seller 开具单据时的销售方信息快照
+type Seller struct {
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+	TaxID   string `json:"tax_id,omitempty"`
+}
+
+// Document 已开具的发票或红字发票
+// 开具后不可修改，PDF文件按语言在首次下载时生成并保存，之后总是返回同一个文件
+type Document struct {
+	ID        uint         `json:"id"`
+	Type      string       `json:"type"`
+	Number    string       `json:"number"` // 按类型和年份连续编号，如 INV-2026-000001
+	OrderID   uint         `json:"order_id"`
+	UserID    uint         `json:"user_id"`
+	RefundID  uint         `json:"refund_id,omitempty"` // 红字发票对应的退款
+	Reference string       `json:"reference,omitempty"` // 红字发票冲减的原发票号
+	Amount    common.Money `json:"amount"`              // 发票为订单应付金额，红字发票为退款金额
+	TaxTotal  common.Money `json:"tax_total"`           // 发票为订单税额合计，红字发票为退款中包含的税额
+	Currency  string       `json:"currency,omitempty"`  // 开具时的币种
+	TxHash    string       `json:"tx_hash,omitempty"`   // 订单支付的链上交易哈希
+	Seller    Seller       `json:"seller"`
+	IssuedAt  time.Time    `json:"issued_at"`
+}
+
+// FileKey 单据PDF文件在对象存储中的key
+func (d *Document) FileKey(language string) string {
+	return fmt.Sprintf("invoices/%d/%s.%s.pdf", d.IssuedAt.Year(), d.Number, language)
+}
+
+// FileName 下载时使用的文件名
+func (d *Document) FileName() string {
+	return d.Number + ".pdf"
+}
+
+// FormatNumber 生成单据号，如 INV-2026-000001
+func FormatNumber(prefix string, year int, seq int64) string {
+	return fmt.Sprintf("%s-%d-%06d", prefix, year, seq)
+}
+
+// NormalizeLanguage 解析单据语言，无法识别时使用中文
+func NormalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if strings.HasPrefix(language, LanguageEN) {
+		return LanguageEN
+	}
+	return LanguageZH
+}
+
+// DocumentRepository 单据仓库接口
+type DocumentRepository interface {
+	// FindIssued 查询订单已开具的单据，发票的refundID为0，未开具时返回nil
+	FindIssued(ctx context.Context, docType string, orderID uint, refundID uint) (*Document, error)
+
+	// FindByOrderID 查询订单的全部单据，按开具顺序排列
+	FindByOrderID(ctx context.Context, orderID uint) ([]Document, error)
+
+	// Issue 在事务中分配连续的单据号并保存单据，每个前缀每年从1开始编号
+	// 同一订单的同一单据已被并发开具时将doc替换为已有的单据
+	Issue(ctx context.Context, doc *Document, prefix string) error
+}
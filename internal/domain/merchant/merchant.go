@@ -0,0 +1,175 @@
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// 商家状态
+// 商家申请审核通过前不能管理商品，被暂停的商家店铺不对外展示，商品也不能下单
+const (
+	MerchantStatusPending   = "pending"   // 入驻申请待审核
+	MerchantStatusActive    = "active"    // 正常营业
+	MerchantStatusRejected  = "rejected"  // 入驻申请被拒绝
+	MerchantStatusSuspended = "suspended" // 被平台暂停
+)
+
+// commissionScale 佣金比例的单位为万分之一
+const commissionScale = 10000
+
+// slugPattern 店铺地址只能包含小写字母、数字和连字符
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// reservedSlugs 与店铺路由冲突的保留地址
+var reservedSlugs = map[string]bool{"apply": true, "application": true}
+
+// Merchant 入驻商家，由一个用户拥有
+type Merchant struct {
+	ID             uint       `json:"id"`
+	OwnerID        uint       `json:"owner_id"`
+	Name           string     `json:"name"`
+	Slug           string     `json:"slug"` // 店铺地址，全局唯一
+	Description    string     `json:"description"`
+	LogoURL        string     `json:"logo_url,omitempty"`
+	Status         string     `json:"status"`
+	CommissionRate int        `json:"commission_rate"`       // 平台佣金比例，万分比，下单时快照到子订单
+	ReviewNote     string     `json:"review_note,omitempty"` // 审核或暂停的说明
+	ReviewedBy     uint       `json:"reviewed_by,omitempty"` // 最后审核的管理员ID
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// IsActive 商家是否正常营业
+func (m *Merchant) IsActive() bool {
+	return m.Status == MerchantStatusActive
+}
+
+// Review 管理员审核入驻申请或调整商家状态
+// 待审核的申请可以通过或拒绝，营业中的商家可以暂停，暂停的商家可以恢复营业
+func (m *Merchant) Review(status string, note string, adminID uint, now time.Time) error {
+	allowed := false
+	switch m.Status {
+	case MerchantStatusPending:
+		allowed = status == MerchantStatusActive || status == MerchantStatusRejected
+	case MerchantStatusActive:
+		allowed = status == MerchantStatusSuspended
+	case MerchantStatusSuspended:
+		allowed = status == MerchantStatusActive
+	}
+	if !allowed {
+		return apierror.NewInvalidStateTransitionError("商家当前状态不能执行该操作", fmt.Sprintf("%s -> %s", m.Status, status))
+	}
+
+	m.Status = status
+	m.ReviewNote = strings.TrimSpace(note)
+	m.ReviewedBy = adminID
+	m.ReviewedAt = &now
+	return nil
+}
+
+// Commission 按万分比计算佣金，四舍五入
+func Commission(amount common.Money, rate int) common.Money {
+	if amount <= 0 || rate <= 0 {
+		return 0
+	}
+	return (amount*common.Money(rate)*2 + commissionScale) / (commissionScale * 2)
+}
+
+// ValidateCommissionRate 校验佣金比例
+func ValidateCommissionRate(rate int) error {
+	if rate < 0 || rate > commissionScale {
+		return apierror.NewValidationError("无效的佣金比例", fmt.Sprintf("佣金比例必须在0到%d之间(万分比)", commissionScale))
+	}
+	return nil
+}
+
+// MerchantQuery 商家查询条件
+type MerchantQuery struct {
+	Status   string
+	Search   string // 按名称或店铺地址模糊匹配
+	Page     int
+	PageSize int
+}
+
+// MerchantPaginationResult 商家分页结果
+type MerchantPaginationResult struct {
+	Total     int        `json:"total"`
+	Merchants []Merchant `json:"merchants"`
+}
+
+// MerchantRepository 商家仓库接口
+type MerchantRepository interface {
+	// FindByID 根据ID查询商家
+	FindByID(ctx context.Context, id uint) (*Merchant, error)
+
+	// FindBySlug 根据店铺地址查询商家
+	FindBySlug(ctx context.Context, slug string) (*Merchant, error)
+
+	// FindByOwnerID 查询用户拥有的商家，不存在时返回nil
+	FindByOwnerID(ctx context.Context, ownerID uint) (*Merchant, error)
+
+	// Find 按条件分页查询商家，按创建时间倒序
+	Find(ctx context.Context, query MerchantQuery) (*MerchantPaginationResult, error)
+
+	// Create 创建商家，店铺地址或所有者重复时返回冲突错误
+	Create(ctx context.Context, merchant *Merchant) error
+
+	// Update 更新店铺资料、状态和佣金比例
+	Update(ctx context.Context, merchant *Merchant) error
+
+	// LockByID 在事务中锁定商家行并返回最新的商家，用于串行化审核操作
+	LockByID(ctx context.Context, id uint) (*Merchant, error)
+
+	// Transaction 在事务中执行fn，fn内通过ctx调用的仓库方法共用同一个事务
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ApplyInput 用户申请入驻的输入参数
+type ApplyInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Slug        string `json:"slug" binding:"required,min=3,max=50"`
+	Description string `json:"description" binding:"max=2000"`
+	LogoURL     string `json:"logo_url" binding:"omitempty,url,max=500"`
+}
+
+// Normalize 规范化输入
+func (in *ApplyInput) Normalize() {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Slug = strings.ToLower(strings.TrimSpace(in.Slug))
+	in.Description = strings.TrimSpace(in.Description)
+}
+
+// Validate 校验店铺地址格式
+func (in *ApplyInput) Validate() error {
+	if in.Name == "" {
+		return apierror.NewValidationError("店铺名称不能为空", "")
+	}
+	if !slugPattern.MatchString(in.Slug) {
+		return apierror.NewValidationError("无效的店铺地址", "只能包含小写字母、数字和连字符，且不能以连字符开头或结尾")
+	}
+	if reservedSlugs[in.Slug] {
+		return apierror.NewValidationError("无效的店铺地址", fmt.Sprintf("%s 是保留地址", in.Slug))
+	}
+	return nil
+}
+
+// UpdateProfileInput 商家修改店铺资料的输入参数，为空的字段不更新
+// 店铺地址创建后不能修改，避免已分享的链接失效
+type UpdateProfileInput struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+	LogoURL     *string `json:"logo_url" binding:"omitempty,max=500"`
+}
+
+// ReviewInput 管理员审核或调整商家的输入参数
+type ReviewInput struct {
+	Status         string `json:"status" binding:"required,oneof=active rejected suspended"`
+	CommissionRate *int   `json:"commission_rate" binding:"omitempty,gte=0,lte=10000"` // 为空时不修改
+	Note           string `json:"note" binding:"max=500"`
+}
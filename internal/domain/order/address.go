@@ -0,0 +1,87 @@
+package order
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// Address 收货地址
+// 下单时复制到订单中作为快照，之后修改地址簿不会影响已有订单
+type Address struct {
+	Recipient  string `json:"recipient" binding:"required,max=50"`
+	Phone      string `json:"phone" binding:"required,max=20"`
+	Country    string `json:"country" binding:"required,len=2"` // ISO 3166-1 二字码，如CN、US
+	Province   string `json:"province" binding:"max=50"`        // 省、州或都道府县
+	City       string `json:"city" binding:"required,max=50"`
+	District   string `json:"district" binding:"max=50"`
+	Line1      string `json:"line1" binding:"required,max=200"`
+	Line2      string `json:"line2" binding:"max=200"`
+	PostalCode string `json:"postal_code" binding:"max=20"`
+}
+
+// countryRule 国家或地区的地址校验规则
+type countryRule struct {
+	PostalCode      *regexp.Regexp // 为空时不校验邮编格式
+	PostalCodeLabel string         // 错误提示中的示例格式
+	RequirePostal   bool
+	RequireProvince bool
+	ProvinceLabel   string // 错误提示中省级行政区的叫法
+}
+
+// countryRules 按国家代码配置的地址规则，未列出的国家只做通用校验
+var countryRules = map[string]countryRule{
+	"CN": {PostalCode: regexp.MustCompile(`^\d{6}$`), PostalCodeLabel: "100000", RequirePostal: true, RequireProvince: true, ProvinceLabel: "省份"},
+	"US": {PostalCode: regexp.MustCompile(`^\d{5}(-\d{4})?$`), PostalCodeLabel: "94105 或 94105-1234", RequirePostal: true, RequireProvince: true, ProvinceLabel: "州"},
+	"CA": {PostalCode: regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`), PostalCodeLabel: "K1A 0B1", RequirePostal: true, RequireProvince: true, ProvinceLabel: "省份"},
+	"GB": {PostalCode: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`), PostalCodeLabel: "SW1A 1AA", RequirePostal: true},
+	"JP": {PostalCode: regexp.MustCompile(`^\d{3}-?\d{4}$`), PostalCodeLabel: "100-0001", RequirePostal: true, RequireProvince: true, ProvinceLabel: "都道府县"},
+	"KR": {PostalCode: regexp.MustCompile(`^\d{5}$`), PostalCodeLabel: "03187", RequirePostal: true},
+	"DE": {PostalCode: regexp.MustCompile(`^\d{5}$`), PostalCodeLabel: "10115", RequirePostal: true},
+	"FR": {PostalCode: regexp.MustCompile(`^\d{5}$`), PostalCodeLabel: "75001", RequirePostal: true},
+	"AU": {PostalCode: regexp.MustCompile(`^\d{4}$`), PostalCodeLabel: "2000", RequirePostal: true, RequireProvince: true, ProvinceLabel: "州"},
+	"SG": {PostalCode: regexp.MustCompile(`^\d{6}$`), PostalCodeLabel: "018956", RequirePostal: true},
+	"HK": {}, // 香港没有邮政编码
+}
+
+// Normalize 去除首尾空白，国家代码和邮编统一为大写
+func (a *Address) Normalize() {
+	a.Recipient = strings.TrimSpace(a.Recipient)
+	a.Phone = strings.TrimSpace(a.Phone)
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.Province = strings.TrimSpace(a.Province)
+	a.City = strings.TrimSpace(a.City)
+	a.District = strings.TrimSpace(a.District)
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.Line2 = strings.TrimSpace(a.Line2)
+	a.PostalCode = strings.ToUpper(strings.TrimSpace(a.PostalCode))
+}
+
+// Validate 按国家规则校验地址，调用前应先Normalize
+func (a *Address) Validate() error {
+	if a.Recipient == "" || a.Phone == "" || a.City == "" || a.Line1 == "" {
+		return apierror.NewValidationError("收货地址不完整", "收件人、电话、城市和详细地址不能为空")
+	}
+	if len(a.Country) != 2 || a.Country[0] < 'A' || a.Country[0] > 'Z' || a.Country[1] < 'A' || a.Country[1] > 'Z' {
+		return apierror.NewValidationError("无效的国家代码", fmt.Sprintf("%q 不是ISO 3166-1二字码", a.Country))
+	}
+
+	rule, ok := countryRules[a.Country]
+	if !ok {
+		return nil
+	}
+	if rule.RequireProvince && a.Province == "" {
+		return apierror.NewValidationError("收货地址不完整", fmt.Sprintf("%s地址必须填写%s", a.Country, rule.ProvinceLabel))
+	}
+	if a.PostalCode == "" {
+		if rule.RequirePostal {
+			return apierror.NewValidationError("收货地址不完整", fmt.Sprintf("%s地址必须填写邮政编码", a.Country))
+		}
+		return nil
+	}
+	if rule.PostalCode != nil && !rule.PostalCode.MatchString(a.PostalCode) {
+		return apierror.NewValidationError("邮政编码格式不正确", fmt.Sprintf("%s的邮政编码格式应为 %s", a.Country, rule.PostalCodeLabel))
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package order
+
+import (
+	"errors"
+	"testing"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+func TestAddressValidate(t *testing.T) {
+	address := func(country, province, postalCode string) Address {
+		return Address{
+			Recipient:  "张三",
+			Phone:      "13800000000",
+			Country:    country,
+			Province:   province,
+			City:       "城市",
+			Line1:      "详细地址",
+			PostalCode: postalCode,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		address Address
+		wantErr string // 为空表示校验通过
+	}{
+		{name: "中国地址", address: address("CN", "北京市", "100000")},
+		{name: "中国缺少省份", address: address("CN", "", "100000"), wantErr: "收货地址不完整"},
+		{name: "中国缺少邮编", address: address("CN", "北京市", ""), wantErr: "收货地址不完整"},
+		{name: "中国邮编位数不对", address: address("CN", "北京市", "10000"), wantErr: "邮政编码格式不正确"},
+		{name: "美国五位邮编", address: address("US", "CA", "94105")},
+		{name: "美国ZIP+4邮编", address: address("US", "CA", "94105-1234")},
+		{name: "美国邮编格式不对", address: address("US", "CA", "9410"), wantErr: "邮政编码格式不正确"},
+		{name: "美国缺少州", address: address("US", "", "94105"), wantErr: "收货地址不完整"},
+		{name: "加拿大邮编带空格", address: address("CA", "ON", "K1A 0B1")},
+		{name: "加拿大邮编小写不带空格", address: address("ca", "ON", "k1a0b1")},
+		{name: "加拿大邮编格式不对", address: address("CA", "ON", "12345"), wantErr: "邮政编码格式不正确"},
+		{name: "英国不要求填写州", address: address("GB", "", "SW1A 1AA")},
+		{name: "英国邮编格式不对", address: address("GB", "", "SW1A"), wantErr: "邮政编码格式不正确"},
+		{name: "日本邮编不带连字符", address: address("JP", "東京都", "1000001")},
+		{name: "日本缺少都道府县", address: address("JP", "", "100-0001"), wantErr: "收货地址不完整"},
+		{name: "德国缺少邮编", address: address("DE", "", ""), wantErr: "收货地址不完整"},
+		{name: "香港没有邮编", address: address("HK", "", "")},
+		{name: "未配置规则的国家只做通用校验", address: address("BR", "", "任意格式")},
+		{name: "国家代码不是两个字母", address: address("C1", "", ""), wantErr: "无效的国家代码"},
+		{name: "国家代码过长", address: address("CHN", "北京市", "100000"), wantErr: "无效的国家代码"},
+		{name: "缺少收件人", address: Address{Phone: "13800000000", Country: "CN", City: "城市", Line1: "详细地址"}, wantErr: "收货地址不完整"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.address.Normalize()
+			err := tt.address.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var apiErr *apierror.APIError
+			if !errors.As(err, &apiErr) || apiErr.Message != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
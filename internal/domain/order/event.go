@@ -0,0 +1,16 @@
+package order
+
+import "time"
+
+// 订单领域事件主题
+const (
+	// EventOrderPaid 订单支付完成，包括逾期付款重新激活的订单，载荷为PaidEvent
+	EventOrderPaid = "order.paid"
+)
+
+// PaidEvent 订单支付事件
+type PaidEvent struct {
+	OrderID uint
+	UserID  uint
+	PaidAt  time.Time
+}
@@ -0,0 +1,81 @@
+package order
+
+import (
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+// 逾期付款的处理状态
+const (
+	LatePaymentStatusPending     = "pending"     // 等待管理员处理
+	LatePaymentStatusReactivated = "reactivated" // 已重新激活订单
+	LatePaymentStatusRefunded    = "refunded"    // 已将款项退回给用户
+)
+
+// 逾期付款的处理方式
+const (
+	LatePaymentActionReactivate = "reactivate"
+	LatePaymentActionRefund     = "refund"
+)
+
+// Payment 订单收到的付款
+type Payment struct {
+	TxHash string       `json:"tx_hash"` // 链上交易哈希，管理员手动确认时为空
+	Amount common.Money `json:"amount"`  // 为0时视为按订单金额全额付款
+	Note   string       `json:"note"`    // 付款说明，记录到订单时间线
+}
+
+// Received 是否有款项已到账的凭据：链上交易或到账金额
+// 管理员只修改订单状态时两者都为空
+func (p Payment) Received() bool {
+	return p.TxHash != "" || p.Amount > 0
+}
+
+// ConfirmPaymentInput 管理员确认订单收款的输入参数
+type ConfirmPaymentInput struct {
+	TxHash string       `json:"tx_hash" binding:"omitempty,len=66,hexadecimal"`
+	Amount common.Money `json:"amount" binding:"gte=0"`
+	Note   string       `json:"note" binding:"max=500"`
+}
+
+// LatePayment 订单已超时关闭或取消后才到账的付款
+// 款项不能直接丢弃，记录后由管理员决定重新激活订单或退款
+type LatePayment struct {
+	ID         uint         `json:"id"`
+	OrderID    uint         `json:"order_id"`
+	UserID     uint         `json:"user_id"`
+	TxHash     string       `json:"tx_hash,omitempty"`
+	Amount     common.Money `json:"amount"`
+	Status     string       `json:"status"`
+	Note       string       `json:"note,omitempty"`
+	ResolvedBy uint         `json:"resolved_by,omitempty"` // 处理的管理员ID
+	ResolvedAt *time.Time   `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// Resolve 记录处理结果
+func (p *LatePayment) Resolve(status string, adminID uint, note string, now time.Time) {
+	p.Status = status
+	p.ResolvedBy = adminID
+	p.Note = note
+	p.ResolvedAt = &now
+}
+
+// LatePaymentQuery 逾期付款查询条件
+type LatePaymentQuery struct {
+	Status   string
+	Page     int
+	PageSize int
+}
+
+// LatePaymentPaginationResult 逾期付款分页结果
+type LatePaymentPaginationResult struct {
+	Total        int           `json:"total"`
+	LatePayments []LatePayment `json:"late_payments"`
+}
+
+// ResolveLatePaymentInput 管理员处理逾期付款的输入参数
+type ResolveLatePaymentInput struct {
+	Action string `json:"action" binding:"required,oneof=reactivate refund"`
+	Note   string `json:"note" binding:"max=500"`
+}
@@ -0,0 +1,291 @@
+package order
+
+import (
+	"context"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/tax"
+)
+
+// Order 订单
+// 商品名称、单价和优惠在下单时记录快照，之后商品改价或促销变化不影响已有订单
+type Order struct {
+	ID               uint         `json:"id"`
+	UserID           uint         `json:"user_id"`
+	OrderSN          string       `json:"order_sn"`
+	Status           string       `json:"status"`
+	Items            []OrderItem  `json:"items"`
+	Subtotal         common.Money `json:"subtotal"`       // 商品原价合计
+	DiscountTotal    common.Money `json:"discount_total"` // 促销减免合计(含运费减免)
+	ShippingFee      common.Money `json:"shipping_fee"`
+	ShippingTax      common.Money `json:"shipping_tax"`                 // 运费的税额
+	TaxTotal         common.Money `json:"tax_total"`                    // 商品和运费的税额合计
+	TaxInclusive     bool         `json:"tax_inclusive"`                // 标价是否已含税，含税时税额不另外计入应付金额
+	TotalPrice       common.Money `json:"total_price"`                  // 应付金额
+	BalancePaid      common.Money `json:"balance_paid"`                 // 购物金和站内余额抵扣的金额，剩余部分需要链上支付
+	StoreCreditPaid  common.Money `json:"store_credit_paid"`            // 抵扣金额中购物金的部分，退款时退回购物金
+	ShippingAddress  *Address     `json:"shipping_address,omitempty"`   // 只有数字商品的订单为空
+	ShippingMethodID uint         `json:"shipping_method_id,omitempty"` // 只有数字商品的订单为0
+	ShippingMethod   string       `json:"shipping_method,omitempty"`    // 下单时配送方式名称的快照
+	PromotionCodes   []string     `json:"promotion_codes"`
+	PaymentTxHash    string       `json:"payment_tx_hash,omitempty"` // 支付的链上交易哈希，管理员手动确认支付时为空
+	Note             string       `json:"note,omitempty"`
+	ExpiresAt        time.Time    `json:"expires_at"` // 支付截止时间，与库存预占的有效期一致
+	PaidAt           *time.Time   `json:"paid_at,omitempty"`
+	ShippedAt        *time.Time   `json:"shipped_at,omitempty"`
+	DeliveredAt      *time.Time   `json:"delivered_at,omitempty"`
+	CompletedAt      *time.Time   `json:"completed_at,omitempty"`
+	CancelledAt      *time.Time   `json:"cancelled_at,omitempty"` // 取消或超时关闭的时间
+	RefundedAt       *time.Time   `json:"refunded_at,omitempty"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+// OrderItem 订单中的商品
+type OrderItem struct {
+	ID          uint         `json:"id"`
+	OrderID     uint         `json:"order_id"`
+	ProductID   uint         `json:"product_id"`
+	ProductName string       `json:"product_name"`
+	SKU         string       `json:"sku"`
+	ProductType string       `json:"product_type"`
+	MerchantID  uint         `json:"merchant_id"` // 商品所属商家，0表示平台自营
+	Price       common.Money `json:"price"`       // 下单时的单价，已包含持币价格
+	Quantity    int          `json:"quantity"`
+	Discount    common.Money `json:"discount"` // 分摊到该商品的促销减免
+	Total       common.Money `json:"total"`    // 单价乘数量减去减免
+	TaxClass    string       `json:"tax_class"`
+	TaxName     string       `json:"tax_name,omitempty"` // 适用的税种名称，没有适用税率时为空
+	TaxRate     int          `json:"tax_rate"`           // 万分比
+	TaxAmount   common.Money `json:"tax_amount"`         // 按Total计算的税额
+}
+
+// HasPhysicalItems 订单是否包含需要发货的实物商品
+func (o *Order) HasPhysicalItems() bool {
+	for _, item := range o.Items {
+		if item.ProductType != product.ProductTypeDigital {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateTotal 根据订单行计算金额合计
+// 商品减免已分摊到订单行，shippingDiscount为运费减免
+func (o *Order) CalculateTotal(shippingDiscount common.Money) {
+	o.Subtotal = 0
+	o.DiscountTotal = shippingDiscount
+	for i := range o.Items {
+		item := &o.Items[i]
+		item.Total = item.Price.Mul(item.Quantity) - item.Discount
+		o.Subtotal += item.Price.Mul(item.Quantity)
+		o.DiscountTotal += item.Discount
+	}
+	o.TotalPrice = o.Subtotal + o.ShippingFee - o.DiscountTotal
+}
+
+// TaxRequest 按订单行实付金额和运费构建计税请求，只有数字商品的订单没有收货地址，只匹配"*"税率
+func (o *Order) TaxRequest() tax.Request {
+	req := tax.Request{
+		Lines:    make([]tax.Line, 0, len(o.Items)),
+		Shipping: o.ShippingFee - o.shippingDiscount(),
+	}
+	if o.ShippingAddress != nil {
+		req.Country = o.ShippingAddress.Country
+		req.Region = o.ShippingAddress.Province
+	}
+	for _, item := range o.Items {
+		req.Lines = append(req.Lines, tax.Line{Key: item.ProductID, TaxClass: item.TaxClass, Amount: item.Total})
+	}
+	return req
+}
+
+// ApplyTax 记录每个订单行和运费的税额，标价不含税时税额计入应付金额
+func (o *Order) ApplyTax(result *tax.Result) {
+	byKey := make(map[uint]tax.LineTax, len(result.Lines))
+	for _, line := range result.Lines {
+		byKey[line.Key] = line
+	}
+	for i := range o.Items {
+		item := &o.Items[i]
+		line := byKey[item.ProductID]
+		item.TaxName = line.Name
+		item.TaxRate = line.Rate
+		item.TaxAmount = line.Amount
+	}
+	o.ShippingTax = result.Shipping.Amount
+	o.TaxTotal = result.Total
+	o.TaxInclusive = result.Inclusive
+
+	o.TotalPrice = o.Subtotal + o.ShippingFee - o.DiscountTotal
+	if !o.TaxInclusive {
+		o.TotalPrice += o.TaxTotal
+	}
+}
+
+// AmountDue 扣除礼品卡和余额抵扣后需要链上支付的金额
+func (o *Order) AmountDue() common.Money {
+	return max(o.TotalPrice-o.BalancePaid, 0)
+}
+
+// ItemPaid 订单行的实付金额，标价不含税时包含税额
+func (o *Order) ItemPaid(item *OrderItem) common.Money {
+	if o.TaxInclusive {
+		return item.Total
+	}
+	return item.Total + item.TaxAmount
+}
+
+// shippingDiscount 运费减免，即减免合计中未分摊到订单行的部分
+func (o *Order) shippingDiscount() common.Money {
+	itemDiscount := common.Money(0)
+	for _, item := range o.Items {
+		itemDiscount += item.Discount
+	}
+	return o.DiscountTotal - itemDiscount
+}
+
+// OrderQuery 订单查询条件，时间范围为左闭右开
+type OrderQuery struct {
+	UserID    uint
+	Status    string
+	StartDate time.Time // 为零值时不限制
+	EndDate   time.Time // 为零值时不限制
+	Page      int
+	PageSize  int
+}
+
+// OrderPaginationResult 订单分页结果
+type OrderPaginationResult struct {
+	Total  int     `json:"total"`
+	Orders []Order `json:"orders"`
+}
+
+// OrderRepository 订单仓库接口
+type OrderRepository interface {
+	// FindByID 根据ID查询订单及订单行
+	FindByID(ctx context.Context, id uint) (*Order, error)
+
+	// FindBySN 根据订单号查询订单及订单行
+	FindBySN(ctx context.Context, sn string) (*Order, error)
+
+	// Find 按条件分页查询订单，按创建时间倒序
+	Find(ctx context.Context, query OrderQuery) (*OrderPaginationResult, error)
+
+	// Create 创建订单及订单行
+	Create(ctx context.Context, order *Order) error
+
+	// UpdateStatus 仅当订单仍处于from状态时更新状态及时间，返回是否更新成功
+	UpdateStatus(ctx context.Context, order *Order, from string) (bool, error)
+
+	// FindExpired 查询支付截止时间早于now的待支付订单，最多返回limit条
+	FindExpired(ctx context.Context, now time.Time, limit int) ([]Order, error)
+
+	// HasPurchased 判断用户是否有包含该商品的已支付订单
+	HasPurchased(ctx context.Context, userID uint, productID uint) (bool, error)
+
+	// AppendTimeline 记录一条状态变更
+	AppendTimeline(ctx context.Context, event *TimelineEvent) error
+
+	// FindTimeline 按时间顺序查询订单的状态变更记录
+	FindTimeline(ctx context.Context, orderID uint) ([]TimelineEvent, error)
+
+	// CreateLatePayment 记录逾期付款
+	CreateLatePayment(ctx context.Context, payment *LatePayment) error
+
+	// FindLatePaymentByID 根据ID查询逾期付款
+	FindLatePaymentByID(ctx context.Context, id uint) (*LatePayment, error)
+
+	// FindLatePayments 按条件分页查询逾期付款，按创建时间倒序
+	FindLatePayments(ctx context.Context, query LatePaymentQuery) (*LatePaymentPaginationResult, error)
+
+	// ResolveLatePayment 仅当逾期付款仍待处理时写入处理结果，返回是否更新成功
+	ResolveLatePayment(ctx context.Context, payment *LatePayment) (bool, error)
+
+	// LockByID 在事务中锁定订单行并返回最新的订单，用于串行化同一订单的发货操作
+	LockByID(ctx context.Context, id uint) (*Order, error)
+
+	// SetBalancePaid 记录订单用购物金和余额抵扣的金额，storeCredit为其中购物金的部分
+	SetBalancePaid(ctx context.Context, orderID uint, amount common.Money, storeCredit common.Money) error
+
+	// CreateShipment 创建包裹及包裹商品
+	CreateShipment(ctx context.Context, shipment *Shipment) error
+
+	// FindShipments 按创建顺序查询订单的包裹
+	FindShipments(ctx context.Context, orderID uint) ([]Shipment, error)
+
+	// MarkShipmentDelivered 仅当包裹仍未签收时标记为已签收，返回是否更新成功
+	MarkShipmentDelivered(ctx context.Context, shipment *Shipment) (bool, error)
+
+	// CreateRefund 记录退款
+	CreateRefund(ctx context.Context, refund *Refund) error
+
+	// SetRefundWithdrawal 关联转账退款的提现单
+	SetRefundWithdrawal(ctx context.Context, refundID uint, withdrawalID uint) error
+
+	// FindRefunds 按创建顺序查询订单的退款
+	FindRefunds(ctx context.Context, orderID uint) ([]Refund, error)
+
+	// CreateReturn 创建退货申请
+	CreateReturn(ctx context.Context, request *ReturnRequest) error
+
+	// FindReturnByID 根据ID查询退货申请
+	FindReturnByID(ctx context.Context, id uint) (*ReturnRequest, error)
+
+	// FindReturnsByOrder 按创建顺序查询订单的退货申请
+	FindReturnsByOrder(ctx context.Context, orderID uint) ([]ReturnRequest, error)
+
+	// FindReturns 按条件分页查询退货申请，按创建时间倒序
+	FindReturns(ctx context.Context, query ReturnQuery) (*ReturnPaginationResult, error)
+
+	// UpdateReturn 仅当退货申请仍处于from状态时写入状态和处理结果，返回是否更新成功
+	UpdateReturn(ctx context.Context, request *ReturnRequest, from string) (bool, error)
+
+	// CreateSubOrders 创建订单的子订单
+	CreateSubOrders(ctx context.Context, subOrders []SubOrder) error
+
+	// FindSubOrders 按拆分顺序查询订单的子订单
+	FindSubOrders(ctx context.Context, orderID uint) ([]SubOrder, error)
+
+	// FindSubOrderByID 根据ID查询子订单
+	FindSubOrderByID(ctx context.Context, id uint) (*SubOrder, error)
+
+	// FindMerchantSubOrders 按条件分页查询商家的子订单，按创建时间倒序
+	FindMerchantSubOrders(ctx context.Context, query SubOrderQuery) (*SubOrderPaginationResult, error)
+
+	// UpdateSubOrder 仅当子订单仍处于from状态时写入状态和结算结果，返回是否更新成功
+	UpdateSubOrder(ctx context.Context, subOrder *SubOrder, from string) (bool, error)
+
+	// Transaction 在事务中执行fn，fn内通过ctx调用的仓库方法共用同一个事务
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// CreateOrderInput 下单的输入参数
+type CreateOrderInput struct {
+	Items            []CreateOrderItemInput `json:"items" binding:"required,min=1,max=100,dive"`
+	Codes            []string               `json:"codes" binding:"max=5"`
+	AddressID        uint                   `json:"address_id"`         // 地址簿中的地址，优先于shipping_address
+	ShippingAddress  *Address               `json:"shipping_address"`   // 都未填写时使用默认地址，只有数字商品时忽略
+	ShippingMethodID uint                   `json:"shipping_method_id"` // 包含实物商品时必填
+	Note             string                 `json:"note" binding:"max=500"`
+	GiftCardCode     string                 `json:"gift_card_code" binding:"max=32"` // 礼品卡只兑换抵扣所需的部分，剩余金额留在卡内
+	UseBalance       bool                   `json:"use_balance"`                     // 用站内余额抵扣礼品卡之外的剩余金额
+}
+
+// CreateOrderItemInput 下单的商品
+type CreateOrderItemInput struct {
+	ProductID uint          `json:"product_id" binding:"required"`
+	Quantity  int           `json:"quantity" binding:"required,gte=1"`
+	Price     *common.Money `json:"price" binding:"required,gte=0"` // 用户看到的单价，与实际单价不一致时拒绝下单
+}
+
+// CreateSubscriptionOrderInput 生成订阅首期或续费订单的参数
+// 订单只包含订阅的数字商品一件，按计划价格计价，不使用优惠码，也不预占库存
+type CreateSubscriptionOrderInput struct {
+	UserID    uint
+	ProductID uint
+	Price     common.Money
+	ExpiresAt time.Time // 支付截止时间，为零值时使用默认的支付窗口
+	Note      string
+}
@@ -0,0 +1,78 @@
+package order
+
+import (
+	"testing"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/tax"
+)
+
+func TestOrderApplyTax(t *testing.T) {
+	rates := map[string]int{tax.ClassStandard: 1300, tax.ClassReduced: 900, tax.ClassShipping: 1300}
+	// calculate 按税类的固定税率逐行计税
+	calculate := func(req tax.Request, inclusive bool) *tax.Result {
+		result := &tax.Result{Inclusive: inclusive}
+		for _, line := range req.Lines {
+			rate := rates[line.TaxClass]
+			amount := tax.Amount(line.Amount, rate, inclusive)
+			result.Lines = append(result.Lines, tax.LineTax{Key: line.Key, Name: "VAT", Rate: rate, Amount: amount})
+			result.Total += amount
+		}
+		result.Shipping = tax.LineTax{Name: "VAT", Rate: rates[tax.ClassShipping], Amount: tax.Amount(req.Shipping, rates[tax.ClassShipping], inclusive)}
+		result.Total += result.Shipping.Amount
+		return result
+	}
+
+	tests := []struct {
+		name         string
+		inclusive    bool
+		wantLines    []common.Money
+		wantShipping common.Money
+		wantTotal    common.Money
+	}{
+		{
+			// 9000*13%=1170, 3333*9%=299.97, (1000-200)*13%=104，税额加到应付金额
+			name:         "标价不含税",
+			wantLines:    []common.Money{1170, 300},
+			wantShipping: 104,
+			wantTotal:    13133 + 1574,
+		},
+		{
+			// 9000*13/113=1035.4, 3333*9/109=275.2, 800*13/113=92.0，应付金额不变
+			name:         "标价含税",
+			inclusive:    true,
+			wantLines:    []common.Money{1035, 275},
+			wantShipping: 92,
+			wantTotal:    13133,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Order{
+				ShippingFee: 1000,
+				Items: []OrderItem{
+					{ProductID: 1, Price: 5000, Quantity: 2, Discount: 1000, TaxClass: tax.ClassStandard},
+					{ProductID: 2, Price: 3333, Quantity: 1, TaxClass: tax.ClassReduced},
+				},
+			}
+			o.CalculateTotal(200)
+
+			o.ApplyTax(calculate(o.TaxRequest(), tt.inclusive))
+
+			var wantTax common.Money
+			for i, want := range tt.wantLines {
+				if o.Items[i].TaxAmount != want || o.Items[i].TaxRate != rates[o.Items[i].TaxClass] {
+					t.Errorf("item %d tax = %s at %d, want %s", i, o.Items[i].TaxAmount, o.Items[i].TaxRate, want)
+				}
+				wantTax += want
+			}
+			wantTax += tt.wantShipping
+			if o.ShippingTax != tt.wantShipping || o.TaxTotal != wantTax {
+				t.Errorf("shipping tax = %s total tax = %s, want %s %s", o.ShippingTax, o.TaxTotal, tt.wantShipping, wantTax)
+			}
+			if o.TotalPrice != tt.wantTotal || o.TaxInclusive != tt.inclusive {
+				t.Errorf("total = %s inclusive = %v, want %s %v", o.TotalPrice, o.TaxInclusive, tt.wantTotal, tt.inclusive)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package order
+
+import (
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// 退款方式
+const (
+	RefundMethodBalance  = "balance"  // 退到站内余额
+	RefundMethodTransfer = "transfer" // 通过提现流程转账到用户的收款地址
+)
+
+// Refund 一次退款，可以只退部分订单行的部分数量
+// 累计退款金额达到订单应付金额时订单变为已退款
+type Refund struct {
+	ID             uint         `json:"id"`
+	OrderID        uint         `json:"order_id"`
+	UserID         uint         `json:"user_id"`
+	ReturnID       uint         `json:"return_id,omitempty"` // 由退货申请产生时为退货申请ID
+	Items          []RefundItem `json:"items"`
+	ShippingAmount common.Money `json:"shipping_amount"` // 退还的运费
+	Amount         common.Money `json:"amount"`          // 退款总额，包含运费
+	Method         string       `json:"method"`
+	StoreCredit    common.Money `json:"store_credit,omitempty"`  // 退回购物金的部分
+	WithdrawalID   uint         `json:"withdrawal_id,omitempty"` // 转账退款对应的提现单
+	Restocked      bool         `json:"restocked"`               // 实物商品是否已退回库存
+	Reason         string       `json:"reason,omitempty"`
+	Actor          Actor        `json:"actor"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// RefundItem 退款涉及的订单行
+type RefundItem struct {
+	OrderItemID uint         `json:"order_item_id"`
+	ProductID   uint         `json:"product_id"`
+	Quantity    int          `json:"quantity"`
+	Amount      common.Money `json:"amount"`
+}
+
+// Refundable 订单行剩余可退的数量和金额
+type Refundable struct {
+	Quantity int
+	Amount   common.Money
+}
+
+// ShippingPaid 用户实际支付的运费，即运费减去促销的运费减免，标价不含税时包含运费的税额
+func (o *Order) ShippingPaid() common.Money {
+	paid := o.ShippingFee - o.shippingDiscount()
+	if !o.TaxInclusive {
+		paid += o.ShippingTax
+	}
+	return paid
+}
+
+// Refundable 根据已有退款计算每个订单行剩余可退的数量和金额，以及剩余可退的运费
+func (o *Order) Refundable(refunds []Refund) (map[uint]Refundable, common.Money) {
+	remaining := make(map[uint]Refundable, len(o.Items))
+	for _, item := range o.Items {
+		remaining[item.ID] = Refundable{Quantity: item.Quantity, Amount: o.ItemPaid(&item)}
+	}
+	shipping := o.ShippingPaid()
+	for _, refund := range refunds {
+		for _, item := range refund.Items {
+			r := remaining[item.OrderItemID]
+			r.Quantity -= item.Quantity
+			r.Amount -= item.Amount
+			remaining[item.OrderItemID] = r
+		}
+		shipping -= refund.ShippingAmount
+	}
+	return remaining, shipping
+}
+
+// ProratedRefund 按数量分摊订单行的实付金额，退完剩余全部数量时退还剩余全部金额，避免分摊的尾差
+func (o *Order) ProratedRefund(item *OrderItem, remaining Refundable, quantity int) (common.Money, error) {
+	if quantity <= 0 || quantity > remaining.Quantity {
+		return 0, apierror.NewValidationError("退款数量超过可退数量", fmt.Sprintf("订单行 %d 可退数量 %d", item.ID, remaining.Quantity))
+	}
+	if quantity == remaining.Quantity {
+		return remaining.Amount, nil
+	}
+	amount := o.ItemPaid(item) * common.Money(quantity) / common.Money(item.Quantity)
+	if amount > remaining.Amount {
+		amount = remaining.Amount
+	}
+	return amount, nil
+}
+
+// RefundedTotal 已退款总额
+func RefundedTotal(refunds []Refund) common.Money {
+	total := common.Money(0)
+	for _, refund := range refunds {
+		total += refund.Amount
+	}
+	return total
+}
+
+// TransferableRefund 剩余可以转账退款的金额，即链上实付金额减去之前转账退回的部分
+// 购物金和余额抵扣的金额不能转账退回，每笔转账退款按顺序先占用链上实付金额
+func (o *Order) TransferableRefund(refunds []Refund) common.Money {
+	left := o.AmountDue()
+	for _, refund := range refunds {
+		if refund.Method == RefundMethodTransfer {
+			left -= min(refund.Amount, left)
+		}
+	}
+	return left
+}
+
+// SplitRefund 将退款金额拆分为退回现金和退回购物金的部分
+// 链上实付和站内余额抵扣的金额先退，购物金抵扣的金额最后退回购物金，不会变成可以提现的余额
+func (o *Order) SplitRefund(refunds []Refund, amount common.Money) (cash common.Money, storeCredit common.Money) {
+	cashLeft := o.TotalPrice - o.StoreCreditPaid
+	for _, refund := range refunds {
+		cashLeft -= refund.Amount - refund.StoreCredit
+	}
+	cash = min(amount, max(cashLeft, 0))
+	return cash, amount - cash
+}
+
+// RefundTax 退款金额中包含的税额，按退款金额占订单行和运费实付金额的比例分摊
+func (o *Order) RefundTax(refund *Refund) common.Money {
+	items := make(map[uint]*OrderItem, len(o.Items))
+	for i := range o.Items {
+		items[o.Items[i].ID] = &o.Items[i]
+	}
+
+	total := common.Money(0)
+	for _, refunded := range refund.Items {
+		if item, ok := items[refunded.OrderItemID]; ok {
+			total += prorate(item.TaxAmount, refunded.Amount, o.ItemPaid(item))
+		}
+	}
+	total += prorate(o.ShippingTax, refund.ShippingAmount, o.ShippingPaid())
+	return total
+}
+
+// prorate 按part占whole的比例分摊amount，四舍五入
+func prorate(amount, part, whole common.Money) common.Money {
+	if amount <= 0 || part <= 0 || whole <= 0 {
+		return 0
+	}
+	if part >= whole {
+		return amount
+	}
+	return (amount*part*2 + whole) / (whole * 2)
+}
+
+// CanRefund 订单是否处于可以退款的状态
+func (o *Order) CanRefund() bool {
+	return o.CanTransitionTo(OrderStatusRefunded)
+}
+
+// CreateRefundInput 管理员直接退款的输入参数
+type CreateRefundInput struct {
+	Items          []CreateRefundItemInput `json:"items" binding:"max=100,dive"`
+	ShippingAmount common.Money            `json:"shipping_amount" binding:"gte=0"`
+	Method         string                  `json:"method" binding:"required,oneof=balance transfer"`
+	Restock        bool                    `json:"restock"` // 是否将实物商品退回库存，未发货或已收到退货时为true
+	Reason         string                  `json:"reason" binding:"max=500"`
+}
+
+// CreateRefundItemInput 退款的订单行，Amount为空时按数量分摊实付金额
+type CreateRefundItemInput struct {
+	OrderItemID uint          `json:"order_item_id" binding:"required"`
+	Quantity    int           `json:"quantity" binding:"required,gte=1"`
+	Amount      *common.Money `json:"amount" binding:"omitempty,gte=0"`
+}
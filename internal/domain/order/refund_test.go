@@ -0,0 +1,57 @@
+package order
+
+import (
+	"testing"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+func TestOrderSplitRefund(t *testing.T) {
+	// 应付100，其中购物金抵扣30、余额抵扣20，链上实付50
+	o := &Order{TotalPrice: 10000, BalancePaid: 5000, StoreCreditPaid: 3000}
+
+	tests := []struct {
+		name            string
+		refunds         []Refund
+		amount          common.Money
+		wantCash        common.Money
+		wantStoreCredit common.Money
+	}{
+		{name: "全额退款", amount: 10000, wantCash: 7000, wantStoreCredit: 3000},
+		{name: "部分退款先退现金", amount: 4000, wantCash: 4000},
+		{
+			name:            "现金已退完只退购物金",
+			refunds:         []Refund{{Amount: 7000}},
+			amount:          3000,
+			wantStoreCredit: 3000,
+		},
+		{
+			name:            "跨过现金部分",
+			refunds:         []Refund{{Amount: 6000}},
+			amount:          3000,
+			wantCash:        1000,
+			wantStoreCredit: 2000,
+		},
+		{
+			name:            "之前的退款已包含购物金",
+			refunds:         []Refund{{Amount: 8000, StoreCredit: 1000}},
+			amount:          2000,
+			wantStoreCredit: 2000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cash, storeCredit := o.SplitRefund(tt.refunds, tt.amount)
+			if cash != tt.wantCash || storeCredit != tt.wantStoreCredit {
+				t.Errorf("SplitRefund = (%s, %s), want (%s, %s)", cash, storeCredit, tt.wantCash, tt.wantStoreCredit)
+			}
+		})
+	}
+}
+
+func TestOrderSplitRefundWithoutStoreCredit(t *testing.T) {
+	o := &Order{TotalPrice: 10000, BalancePaid: 2000}
+	if cash, storeCredit := o.SplitRefund(nil, 10000); cash != 10000 || storeCredit != 0 {
+		t.Errorf("SplitRefund = (%s, %s), want (100.00, 0.00)", cash, storeCredit)
+	}
+}
@@ -0,0 +1,127 @@
+package order
+
+import (
+	"fmt"
+	"time"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// 退货申请状态
+const (
+	ReturnStatusRequested = "requested" // 等待管理员审核
+	ReturnStatusApproved  = "approved"  // 已同意，等待买家寄回商品
+	ReturnStatusRejected  = "rejected"  // 已拒绝
+	ReturnStatusRefunded  = "refunded"  // 已收到退货并退款
+)
+
+// 退货原因
+const (
+	ReturnReasonDamaged        = "damaged"          // 商品损坏
+	ReturnReasonWrongItem      = "wrong_item"       // 发错商品
+	ReturnReasonNotAsDescribed = "not_as_described" // 与描述不符
+	ReturnReasonNoLongerNeeded = "no_longer_needed" // 不想要了
+	ReturnReasonOther          = "other"
+)
+
+// 管理员审核退货申请的方式
+const (
+	ReturnActionApprove = "approve"
+	ReturnActionReject  = "reject"
+)
+
+// ReturnRequest 买家发起的退货申请
+// 管理员同意后等待买家寄回，收到退货后按申请的数量退款；只有数字商品时同意即退款
+type ReturnRequest struct {
+	ID           uint         `json:"id"`
+	OrderID      uint         `json:"order_id"`
+	UserID       uint         `json:"user_id"`
+	Status       string       `json:"status"`
+	Reason       string       `json:"reason"`
+	Description  string       `json:"description,omitempty"`
+	RefundMethod string       `json:"refund_method"`
+	Items        []ReturnItem `json:"items"`
+	ReviewNote   string       `json:"review_note,omitempty"`
+	ReviewedBy   uint         `json:"reviewed_by,omitempty"` // 审核的管理员ID
+	ReviewedAt   *time.Time   `json:"reviewed_at,omitempty"`
+	ReceivedAt   *time.Time   `json:"received_at,omitempty"`
+	RefundID     uint         `json:"refund_id,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// ReturnItem 退货申请中的订单行及数量
+type ReturnItem struct {
+	OrderItemID uint `json:"order_item_id"`
+	ProductID   uint `json:"product_id"`
+	Quantity    int  `json:"quantity"`
+}
+
+// IsOpen 退货申请是否仍在处理中，处理中的数量不能再次申请
+func (r *ReturnRequest) IsOpen() bool {
+	return r.Status == ReturnStatusRequested || r.Status == ReturnStatusApproved
+}
+
+// Review 记录审核结果
+func (r *ReturnRequest) Review(action string, adminID uint, note string, now time.Time) error {
+	if r.Status != ReturnStatusRequested {
+		return apierror.NewInvalidStateTransitionError("退货申请已审核", fmt.Sprintf("ID: %d, 状态: %s", r.ID, r.Status))
+	}
+	r.Status = ReturnStatusApproved
+	if action == ReturnActionReject {
+		r.Status = ReturnStatusRejected
+	}
+	r.ReviewNote = note
+	r.ReviewedBy = adminID
+	r.ReviewedAt = &now
+	return nil
+}
+
+// MarkAsRefunded 记录已收到退货并退款
+func (r *ReturnRequest) MarkAsRefunded(refundID uint, now time.Time) error {
+	if r.Status != ReturnStatusApproved {
+		return apierror.NewInvalidStateTransitionError("退货申请尚未同意或已退款", fmt.Sprintf("ID: %d, 状态: %s", r.ID, r.Status))
+	}
+	r.Status = ReturnStatusRefunded
+	r.RefundID = refundID
+	r.ReceivedAt = &now
+	return nil
+}
+
+// ReturnQuery 退货申请查询条件
+type ReturnQuery struct {
+	Status   string
+	Page     int
+	PageSize int
+}
+
+// ReturnPaginationResult 退货申请分页结果
+type ReturnPaginationResult struct {
+	Total   int             `json:"total"`
+	Returns []ReturnRequest `json:"returns"`
+}
+
+// CreateReturnInput 买家申请退货的输入参数
+type CreateReturnInput struct {
+	Reason       string                  `json:"reason" binding:"required,oneof=damaged wrong_item not_as_described no_longer_needed other"`
+	Description  string                  `json:"description" binding:"max=1000"`
+	RefundMethod string                  `json:"refund_method" binding:"omitempty,oneof=balance transfer"` // 默认退到余额
+	Items        []CreateReturnItemInput `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// CreateReturnItemInput 退货的订单行及数量
+type CreateReturnItemInput struct {
+	OrderItemID uint `json:"order_item_id" binding:"required"`
+	Quantity    int  `json:"quantity" binding:"required,gte=1"`
+}
+
+// ReviewReturnInput 管理员审核退货申请的输入参数
+type ReviewReturnInput struct {
+	Action string `json:"action" binding:"required,oneof=approve reject"`
+	Note   string `json:"note" binding:"max=500"`
+}
+
+// ReceiveReturnInput 管理员确认收到退货的输入参数
+type ReceiveReturnInput struct {
+	Restock *bool  `json:"restock"` // 是否退回库存，默认为true，商品损坏无法再售时为false
+	Note    string `json:"note" binding:"max=500"`
+}
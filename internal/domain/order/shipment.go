@@ -0,0 +1,89 @@
+package order
+
+import (
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// 包裹状态
+const (
+	ShipmentStatusShipped   = "shipped"   // 已交给承运商
+	ShipmentStatusDelivered = "delivered" // 已签收
+)
+
+// Shipment 订单的一个包裹，一个订单可以分多个包裹发货
+// 全部实物商品发出后订单变为已发货，全部包裹签收后订单变为已签收
+type Shipment struct {
+	ID             uint           `json:"id"`
+	OrderID        uint           `json:"order_id"`
+	Carrier        string         `json:"carrier"`
+	TrackingNumber string         `json:"tracking_number"`
+	Status         string         `json:"status"`
+	Items          []ShipmentItem `json:"items"`
+	ShippedAt      time.Time      `json:"shipped_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// ShipmentItem 包裹中的商品
+type ShipmentItem struct {
+	OrderItemID uint `json:"order_item_id"`
+	ProductID   uint `json:"product_id"`
+	Quantity    int  `json:"quantity"`
+}
+
+// MarkAsDelivered 标记包裹已签收
+func (s *Shipment) MarkAsDelivered(now time.Time) error {
+	if s.Status != ShipmentStatusShipped {
+		return apierror.NewInvalidStateTransitionError("包裹已签收", fmt.Sprintf("包裹ID: %d", s.ID))
+	}
+	s.Status = ShipmentStatusDelivered
+	s.DeliveredAt = &now
+	return nil
+}
+
+// UnshippedQuantities 计算每个实物订单行还未发货的数量，key为订单行ID，已全部发出的订单行不包含在内
+func (o *Order) UnshippedQuantities(shipments []Shipment) map[uint]int {
+	remaining := make(map[uint]int, len(o.Items))
+	for _, item := range o.Items {
+		if item.ProductType != product.ProductTypeDigital {
+			remaining[item.ID] = item.Quantity
+		}
+	}
+	for _, s := range shipments {
+		for _, item := range s.Items {
+			remaining[item.OrderItemID] -= item.Quantity
+		}
+	}
+	for id, quantity := range remaining {
+		if quantity <= 0 {
+			delete(remaining, id)
+		}
+	}
+	return remaining
+}
+
+// AllDelivered 判断全部包裹是否都已签收
+func AllDelivered(shipments []Shipment) bool {
+	for _, s := range shipments {
+		if s.Status != ShipmentStatusDelivered {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateShipmentInput 管理员创建包裹的输入参数
+type CreateShipmentInput struct {
+	Carrier        string                    `json:"carrier" binding:"required,max=50"`
+	TrackingNumber string                    `json:"tracking_number" binding:"required,max=100"`
+	Items          []CreateShipmentItemInput `json:"items" binding:"max=100,dive"` // 为空时发出全部未发货的商品
+}
+
+// CreateShipmentItemInput 包裹中的商品及数量
+type CreateShipmentItemInput struct {
+	OrderItemID uint `json:"order_item_id" binding:"required"`
+	Quantity    int  `json:"quantity" binding:"required,gte=1"`
+}
@@ -0,0 +1,49 @@
+package order
+
+import (
+	"context"
+	"time"
+)
+
+// SNGenerator 订单号生成器
+// 多个应用实例同时下单时生成的订单号不能重复
+type SNGenerator interface {
+	// Next 生成下单时间为now的订单号
+	Next(ctx context.Context, now time.Time) (string, error)
+}
+
+// CheckDigit 按Luhn算法计算订单号的校验位，只计算其中的数字，店铺代码中的字母不参与
+func CheckDigit(body string) byte {
+	sum := 0
+	double := true
+	for i := len(body) - 1; i >= 0; i-- {
+		c := body[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// ValidOrderSN 校验订单号的最后一位校验位，用于在查询前拦截输错的订单号
+func ValidOrderSN(sn string) bool {
+	if len(sn) < 2 {
+		return false
+	}
+	return CheckDigit(sn[:len(sn)-1]) == sn[len(sn)-1]
+}
+
+// SNSegmentRepository 订单号号段仓库，用于号段模式的订单号生成
+type SNSegmentRepository interface {
+	// Allocate 原子地申请长度为size的一段连续序号，返回其中的第一个序号
+	Allocate(ctx context.Context, size int) (uint64, error)
+}
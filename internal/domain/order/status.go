@@ -0,0 +1,156 @@
+package order
+
+import (
+	"fmt"
+	"time"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// OrderStatus 订单状态常量
+const (
+	OrderStatusPendingPayment = "pending_payment" // 待支付，库存已预占
+	OrderStatusPaid           = "paid"            // 已支付，等待处理
+	OrderStatusProcessing     = "processing"      // 备货中
+	OrderStatusShipped        = "shipped"         // 已发货
+	OrderStatusDelivered      = "delivered"       // 已签收
+	OrderStatusCompleted      = "completed"       // 已完成
+	OrderStatusCancelled      = "cancelled"       // 支付前被取消，库存和优惠已归还
+	OrderStatusRefunded       = "refunded"        // 支付后已全额退款
+	OrderStatusExpired        = "expired"         // 超过支付时间未支付，库存和优惠已归还
+)
+
+// orderStatusTransitions 订单允许的状态转换
+// 只有数字商品的订单没有发货环节，支付后可以直接完成，由MarkAsCompleted单独校验
+var orderStatusTransitions = map[string][]string{
+	OrderStatusPendingPayment: {OrderStatusPaid, OrderStatusCancelled, OrderStatusExpired},
+	OrderStatusPaid:           {OrderStatusProcessing, OrderStatusCompleted, OrderStatusRefunded},
+	OrderStatusProcessing:     {OrderStatusShipped, OrderStatusRefunded},
+	OrderStatusShipped:        {OrderStatusDelivered, OrderStatusRefunded},
+	OrderStatusDelivered:      {OrderStatusCompleted, OrderStatusRefunded},
+	OrderStatusCompleted:      {OrderStatusRefunded},
+	OrderStatusCancelled:      {},
+	OrderStatusRefunded:       {},
+	OrderStatusExpired:        {},
+}
+
+// PurchasedStatuses 视为已购买的订单状态
+var PurchasedStatuses = []string{
+	OrderStatusPaid,
+	OrderStatusProcessing,
+	OrderStatusShipped,
+	OrderStatusDelivered,
+	OrderStatusCompleted,
+}
+
+// IsValidOrderStatus 判断是否为有效的订单状态
+func IsValidOrderStatus(status string) bool {
+	_, ok := orderStatusTransitions[status]
+	return ok
+}
+
+// CanTransitionTo 判断订单是否允许切换到目标状态
+func (o *Order) CanTransitionTo(status string) bool {
+	for _, next := range orderStatusTransitions[o.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkAsPaid 标记为已支付
+func (o *Order) MarkAsPaid(now time.Time) error {
+	if err := o.moveTo(OrderStatusPaid); err != nil {
+		return err
+	}
+	o.PaidAt = &now
+	return nil
+}
+
+// StartProcessing 开始备货
+func (o *Order) StartProcessing(now time.Time) error {
+	return o.moveTo(OrderStatusProcessing)
+}
+
+// MarkAsShipped 标记为已发货
+func (o *Order) MarkAsShipped(now time.Time) error {
+	if err := o.moveTo(OrderStatusShipped); err != nil {
+		return err
+	}
+	o.ShippedAt = &now
+	return nil
+}
+
+// MarkAsDelivered 标记为已签收
+func (o *Order) MarkAsDelivered(now time.Time) error {
+	if err := o.moveTo(OrderStatusDelivered); err != nil {
+		return err
+	}
+	o.DeliveredAt = &now
+	return nil
+}
+
+// MarkAsCompleted 标记为已完成，包含实物商品的订单必须先签收
+func (o *Order) MarkAsCompleted(now time.Time) error {
+	if o.Status == OrderStatusPaid && o.HasPhysicalItems() {
+		return apierror.NewInvalidStateTransitionError("订单尚未签收", fmt.Sprintf("%s -> %s", o.Status, OrderStatusCompleted))
+	}
+	if err := o.moveTo(OrderStatusCompleted); err != nil {
+		return err
+	}
+	o.CompletedAt = &now
+	return nil
+}
+
+// Cancel 取消待支付的订单
+func (o *Order) Cancel(now time.Time) error {
+	if err := o.moveTo(OrderStatusCancelled); err != nil {
+		return err
+	}
+	o.CancelledAt = &now
+	return nil
+}
+
+// Expire 关闭超过支付时间的订单
+func (o *Order) Expire(now time.Time) error {
+	if err := o.moveTo(OrderStatusExpired); err != nil {
+		return err
+	}
+	o.CancelledAt = &now
+	return nil
+}
+
+// MarkAsRefunded 标记为已全额退款
+func (o *Order) MarkAsRefunded(now time.Time) error {
+	if err := o.moveTo(OrderStatusRefunded); err != nil {
+		return err
+	}
+	o.RefundedAt = &now
+	return nil
+}
+
+// Reactivate 收到逾期付款后将已关闭的订单恢复为已支付
+// 不在状态转换表中，只能由管理员处理逾期付款时调用
+func (o *Order) Reactivate(now time.Time) error {
+	if !o.IsClosed() {
+		return apierror.NewInvalidStateTransitionError("只有已关闭的订单可以重新激活", fmt.Sprintf("%s -> %s", o.Status, OrderStatusPaid))
+	}
+	o.Status = OrderStatusPaid
+	o.PaidAt = &now
+	o.CancelledAt = nil
+	return nil
+}
+
+// IsClosed 订单是否在支付前已被取消或超时关闭
+func (o *Order) IsClosed() bool {
+	return o.Status == OrderStatusCancelled || o.Status == OrderStatusExpired
+}
+
+// moveTo 按状态转换表校验并切换状态
+func (o *Order) moveTo(status string) error {
+	if !o.CanTransitionTo(status) {
+		return apierror.NewInvalidStateTransitionError("订单状态不允许该操作", fmt.Sprintf("%s -> %s", o.Status, status))
+	}
+	o.Status = status
+	return nil
+}
@@ -0,0 +1,145 @@
+package order
+
+import (
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/merchant"
+	"web3-ecommerce-app/internal/domain/product"
+)
+
+// 子订单状态
+const (
+	SubOrderStatusPending   = "pending"   // 订单完成前等待结算
+	SubOrderStatusSettled   = "settled"   // 订单完成后已结算到商家余额
+	SubOrderStatusCancelled = "cancelled" // 订单关闭或在完成前全额退款，不再结算
+)
+
+// SubOrder 订单中属于同一商家的部分
+// 用户下单和支付仍以订单为单位，下单时按商品所属商家拆分出子订单，用于商家查看订单和结算；
+// 平台自营商品的子订单MerchantID为0，只记录金额，不产生结算流水
+type SubOrder struct {
+	ID             uint         `json:"id"`
+	OrderID        uint         `json:"order_id"`
+	MerchantID     uint         `json:"merchant_id"`
+	Status         string       `json:"status"`
+	ItemsTotal     common.Money `json:"items_total"`     // 该商家订单行的实付金额合计
+	ShippingAmount common.Money `json:"shipping_amount"` // 按实物商品金额分摊的运费
+	Amount         common.Money `json:"amount"`          // 商品和运费合计，各子订单之和等于订单应付金额
+	CommissionRate int          `json:"commission_rate"` // 下单时的佣金比例快照，万分比
+	Commission     common.Money `json:"commission"`      // 结算时按扣除退款后的金额计算的佣金
+	SettledAmount  common.Money `json:"settled_amount"`  // 结算给商家的金额，结算后发生退款时相应冲减
+	SettledAt      *time.Time   `json:"settled_at,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+
+	// 以下字段在商家查询子订单时填充
+	OrderSN         string      `json:"order_sn,omitempty"`
+	OrderStatus     string      `json:"order_status,omitempty"`
+	ShippingAddress *Address    `json:"shipping_address,omitempty"`
+	Items           []OrderItem `json:"items,omitempty"`
+}
+
+// SplitByMerchant 按商品所属商家拆分子订单，子订单按商家在订单行中首次出现的顺序排列
+// 运费按各商家实物商品的金额分摊，尾差计入最后一个有实物商品的子订单；
+// rates为各商家当前的佣金比例，平台自营的子订单不收取佣金
+func (o *Order) SplitByMerchant(rates map[uint]int) []SubOrder {
+	index := make(map[uint]int)
+	subOrders := make([]SubOrder, 0, 1)
+	weights := make([]common.Money, 0, 1)
+	totalWeight := common.Money(0)
+	for i := range o.Items {
+		item := &o.Items[i]
+		j, ok := index[item.MerchantID]
+		if !ok {
+			j = len(subOrders)
+			index[item.MerchantID] = j
+			subOrders = append(subOrders, SubOrder{
+				OrderID:        o.ID,
+				MerchantID:     item.MerchantID,
+				Status:         SubOrderStatusPending,
+				CommissionRate: rates[item.MerchantID],
+			})
+			weights = append(weights, 0)
+		}
+		subOrders[j].ItemsTotal += o.ItemPaid(item)
+		if item.ProductType != product.ProductTypeDigital {
+			weights[j] += item.Total
+			totalWeight += item.Total
+		}
+	}
+
+	shipping := o.ShippingPaid()
+	last := -1
+	for j := range weights {
+		if weights[j] > 0 {
+			last = j
+		}
+	}
+	allocated := common.Money(0)
+	for j := range subOrders {
+		if shipping > 0 && weights[j] > 0 {
+			if j == last {
+				subOrders[j].ShippingAmount = shipping - allocated
+			} else {
+				subOrders[j].ShippingAmount = shipping * weights[j] / totalWeight
+			}
+			allocated += subOrders[j].ShippingAmount
+		}
+		subOrders[j].Amount = subOrders[j].ItemsTotal + subOrders[j].ShippingAmount
+	}
+	return subOrders
+}
+
+// Refunded 订单的退款中属于该子订单的金额：该商家订单行的退款加上按运费比例分摊的运费退款，不超过子订单金额
+func (s *SubOrder) Refunded(o *Order, refunds []Refund) common.Money {
+	merchantOf := make(map[uint]uint, len(o.Items))
+	for _, item := range o.Items {
+		merchantOf[item.ID] = item.MerchantID
+	}
+
+	total := common.Money(0)
+	for _, refund := range refunds {
+		for _, item := range refund.Items {
+			if merchantOf[item.OrderItemID] == s.MerchantID {
+				total += item.Amount
+			}
+		}
+		total += prorate(refund.ShippingAmount, s.ShippingAmount, o.ShippingPaid())
+	}
+	return min(total, s.Amount)
+}
+
+// Settle 订单完成时按扣除退款后的金额结算
+func (s *SubOrder) Settle(refunded common.Money, now time.Time) {
+	s.Status = SubOrderStatusSettled
+	s.Commission, s.SettledAmount = s.settlement(refunded)
+	s.SettledAt = &now
+}
+
+// Resettle 已结算的子订单发生退款后重新计算佣金和结算金额，返回需要从商家余额冲回的金额
+func (s *SubOrder) Resettle(refunded common.Money) common.Money {
+	previous := s.SettledAmount
+	s.Commission, s.SettledAmount = s.settlement(refunded)
+	return previous - s.SettledAmount
+}
+
+// settlement 按扣除退款后的金额计算佣金和商家应得的金额
+func (s *SubOrder) settlement(refunded common.Money) (common.Money, common.Money) {
+	net := max(s.Amount-refunded, 0)
+	commission := merchant.Commission(net, s.CommissionRate)
+	return commission, net - commission
+}
+
+// SubOrderQuery 商家子订单查询条件
+type SubOrderQuery struct {
+	MerchantID uint
+	Status     string // 子订单状态，为空时不限
+	Page       int
+	PageSize   int
+}
+
+// SubOrderPaginationResult 子订单分页结果
+type SubOrderPaginationResult struct {
+	Total     int        `json:"total"`
+	SubOrders []SubOrder `json:"sub_orders"`
+}
@@ -0,0 +1,49 @@
+package order
+
+import (
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+// 状态变更的操作者类型
+const (
+	ActorUser     = "user"     // 下单用户
+	ActorAdmin    = "admin"    // 管理员
+	ActorMerchant = "merchant" // 商家，ID为商家ID
+	ActorSystem   = "system"   // 定时任务、支付回调等系统流程
+)
+
+// Actor 状态变更的操作者
+type Actor struct {
+	Type string `json:"type"`
+	ID   uint   `json:"id,omitempty"` // 系统操作为0
+}
+
+// SystemActor 系统操作者
+var SystemActor = Actor{Type: ActorSystem}
+
+// TimelineEvent 订单时间线上的一条状态变更记录
+type TimelineEvent struct {
+	ID         uint      `json:"id"`
+	OrderID    uint      `json:"order_id"`
+	FromStatus string    `json:"from_status,omitempty"` // 创建订单时为空
+	ToStatus   string    `json:"to_status"`
+	Actor      Actor     `json:"actor"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// UpdateStatusInput 管理员修改订单状态的输入参数
+// 超时关闭和退款由对应的流程触发，发货和签收由包裹状态驱动，不能直接修改
+// 改为已支付时可以附带链上交易哈希和实付金额，写入订单后发票会显示对应的交易
+type UpdateStatusInput struct {
+	Status string       `json:"status" binding:"required,oneof=paid processing completed cancelled"`
+	Reason string       `json:"reason" binding:"max=500"`
+	TxHash string       `json:"tx_hash" binding:"omitempty,len=66,hexadecimal"`
+	Amount common.Money `json:"amount" binding:"gte=0"`
+}
+
+// CancelOrderInput 用户取消订单的输入参数
+type CancelOrderInput struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
@@ -0,0 +1,60 @@
+package product
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 商品目录导入导出支持的文件格式
+const (
+	CatalogFormatCSV   = "csv"
+	CatalogFormatJSONL = "jsonl"
+)
+
+// CatalogColumns 导入导出文件的列，CSV表头和JSONL字段名一致
+var CatalogColumns = []string{"sku", "name", "description", "price", "stock", "category_id", "status"}
+
+// CatalogRow 商品目录文件中的一行
+// 价格使用两位小数的字符串(如 "12.34")，方便在表格软件中编辑；
+// 库存和状态为空时表示不修改已有商品的对应字段
+type CatalogRow struct {
+	SKU         string      `json:"sku"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       json.Number `json:"price"`
+	Stock       *int        `json:"stock,omitempty"`
+	CategoryID  uint        `json:"category_id"`
+	Status      string      `json:"status,omitempty"`
+}
+
+// ImportJobStatus 导入任务状态常量
+const (
+	ImportJobStatusPending   = "pending"   // 等待执行
+	ImportJobStatusRunning   = "running"   // 执行中
+	ImportJobStatusCompleted = "completed" // 已完成(可能包含失败的行)
+	ImportJobStatusFailed    = "failed"    // 文件无法解析等致命错误
+)
+
+// ImportRowError 导入时某一行的错误
+type ImportRowError struct {
+	Row     int    `json:"row"` // 文件中的行号，CSV包含表头行
+	SKU     string `json:"sku,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportJob 商品批量导入任务
+type ImportJob struct {
+	ID            string           `json:"id"`
+	Format        string           `json:"format"`
+	DryRun        bool             `json:"dry_run"`
+	Status        string           `json:"status"`
+	TotalRows     int              `json:"total_rows"`
+	ProcessedRows int              `json:"processed_rows"`
+	Created       int              `json:"created"`
+	Updated       int              `json:"updated"`
+	Failed        int              `json:"failed"`
+	Errors        []ImportRowError `json:"errors"`
+	Error         string           `json:"error,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	FinishedAt    *time.Time       `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,17 @@
+package product
+
+import "time"
+
+// 商品领域事件主题
+const (
+	// EventProductRestocked 在售商品的可售库存从零恢复为正数，载荷为RestockedEvent
+	EventProductRestocked = "product.restocked"
+)
+
+// RestockedEvent 商品到货事件
+type RestockedEvent struct {
+	ProductID   uint
+	Name        string
+	Available   int
+	RestockedAt time.Time // 恢复为上架的时间，同一次到货只通知一次
+}
@@ -0,0 +1,103 @@
+package product
+
+import (
+	"context"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+// GateEffect 持币门槛规则的作用
+const (
+	GateEffectAccess = "access" // 只有持有者可以购买
+	GateEffectPrice  = "price"  // 持有者享受专属价格
+)
+
+// TokenStandard 代币标准
+const (
+	TokenStandardERC20   = "erc20"
+	TokenStandardERC721  = "erc721"
+	TokenStandardERC1155 = "erc1155"
+)
+
+// MaxGateTokenRange 代币ID范围的最大跨度，范围内的每个代币都需要单独查询链上状态
+const MaxGateTokenRange = 100
+
+// GateRule 商品的持币门槛规则
+// 同一商品有多条access规则时满足任意一条即可购买，
+// 满足多条price规则时取最低的专属价格
+type GateRule struct {
+	ID          uint         `json:"id"`
+	ProductID   uint         `json:"product_id"`
+	Effect      string       `json:"effect"`
+	ChainID     int64        `json:"chain_id"`
+	Standard    string       `json:"standard"`
+	Contract    string       `json:"contract"`
+	TokenIDMin  string       `json:"token_id_min,omitempty"` // 十进制字符串，为空表示不限代币ID
+	TokenIDMax  string       `json:"token_id_max,omitempty"`
+	MinBalance  string       `json:"min_balance"`            // 最少持有数量，ERC-20按最小单位计
+	HolderPrice common.Money `json:"holder_price,omitempty"` // 仅price规则有效
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// HasTokenRange 判断规则是否限定了代币ID范围
+func (r *GateRule) HasTokenRange() bool {
+	return r.TokenIDMin != ""
+}
+
+// GateRuleCheck 单条规则的验证结果
+type GateRuleCheck struct {
+	GateRule
+	Satisfied bool `json:"satisfied"`
+}
+
+// GateResult 用户对商品持币门槛的验证结果
+type GateResult struct {
+	ProductID    uint            `json:"product_id"`
+	WalletLinked bool            `json:"wallet_linked"`
+	Gated        bool            `json:"gated"`   // 商品是否仅限持有者购买
+	Allowed      bool            `json:"allowed"` // 用户是否可以购买
+	Price        common.Money    `json:"price"`   // 用户适用的价格
+	Rules        []GateRuleCheck `json:"rules"`
+}
+
+// Honors 判断下单时锁定的单价在当前验证结果下是否仍然有效
+// 单价低于当前价格且等于一条已不再满足的price规则的专属价格时，说明持有者价格已失效；
+// 商品调价导致的价格变化不影响已下单的价格
+func (r *GateResult) Honors(price common.Money) bool {
+	if price >= r.Price {
+		return true
+	}
+	for _, rule := range r.Rules {
+		if rule.Effect == GateEffectPrice && !rule.Satisfied && rule.HolderPrice == price {
+			return false
+		}
+	}
+	return true
+}
+
+// GateRuleRepository 持币门槛规则仓库接口
+type GateRuleRepository interface {
+	// FindByID 根据ID查找规则
+	FindByID(ctx context.Context, id uint) (*GateRule, error)
+
+	// FindByProduct 查询商品的全部规则
+	FindByProduct(ctx context.Context, productID uint) ([]GateRule, error)
+
+	// Create 创建规则
+	Create(ctx context.Context, rule *GateRule) error
+
+	// Delete 删除规则
+	Delete(ctx context.Context, id uint) error
+}
+
+// CreateGateRuleInput 创建持币门槛规则的输入参数
+type CreateGateRuleInput struct {
+	Effect      string        `json:"effect" binding:"required,oneof=access price"`
+	ChainID     int64         `json:"chain_id" binding:"required,gt=0"`
+	Standard    string        `json:"standard" binding:"required,oneof=erc20 erc721 erc1155"`
+	Contract    string        `json:"contract" binding:"required,eth_addr"`
+	TokenIDMin  string        `json:"token_id_min" binding:"omitempty,numeric"`
+	TokenIDMax  string        `json:"token_id_max" binding:"omitempty,numeric"`
+	MinBalance  string        `json:"min_balance" binding:"omitempty,numeric"` // 默认为1
+	HolderPrice *common.Money `json:"holder_price" binding:"required_if=Effect price,omitempty,gte=0"`
+}
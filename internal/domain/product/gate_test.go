@@ -0,0 +1,72 @@
+package product
+
+import (
+	"testing"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+func TestGateResultHonors(t *testing.T) {
+	holderRule := func(price common.Money, satisfied bool) GateRuleCheck {
+		return GateRuleCheck{
+			GateRule:  GateRule{Effect: GateEffectPrice, HolderPrice: price},
+			Satisfied: satisfied,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		result GateResult
+		price  common.Money
+		want   bool
+	}{
+		{
+			name:   "仍持有代币",
+			result: GateResult{Price: 800, Rules: []GateRuleCheck{holderRule(800, true)}},
+			price:  800,
+			want:   true,
+		},
+		{
+			name:   "转走代币后专属价格失效",
+			result: GateResult{Price: 1000, Rules: []GateRuleCheck{holderRule(800, false)}},
+			price:  800,
+			want:   false,
+		},
+		{
+			name:   "商品涨价不影响已下单价格",
+			result: GateResult{Price: 1200, Rules: []GateRuleCheck{holderRule(800, false)}},
+			price:  1000,
+			want:   true,
+		},
+		{
+			name:   "商品降价",
+			result: GateResult{Price: 900},
+			price:  1000,
+			want:   true,
+		},
+		{
+			name: "满足另一条更低的专属价格规则",
+			result: GateResult{Price: 700, Rules: []GateRuleCheck{
+				holderRule(800, false),
+				holderRule(700, true),
+			}},
+			price: 800,
+			want:  true,
+		},
+		{
+			name: "access规则不影响价格判断",
+			result: GateResult{Price: 1000, Rules: []GateRuleCheck{
+				{GateRule: GateRule{Effect: GateEffectAccess}, Satisfied: false},
+			}},
+			price: 900,
+			want:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Honors(tt.price); got != tt.want {
+				t.Errorf("Honors(%s) = %v, want %v", tt.price, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package product
+
+import (
+	"context"
+	"time"
+)
+
+// Image 表示商品图片值对象
+type Image struct {
+	ID          uint        `json:"id"`
+	ProductID   uint        `json:"product_id"`
+	Key         string      `json:"-"` // 对象存储中的key
+	URL         string      `json:"url"`
+	ContentType string      `json:"content_type"`
+	Width       int         `json:"width"`
+	Height      int         `json:"height"`
+	Size        int64       `json:"size"`
+	SortOrder   int         `json:"sort_order"`
+	IsPrimary   bool        `json:"is_primary"`
+	Thumbnails  []Thumbnail `json:"thumbnails"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// Thumbnail 表示图片的缩略图
+type Thumbnail struct {
+	Key    string `json:"-"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ImageRepository 商品图片仓库接口
+type ImageRepository interface {
+	// FindByID 根据ID查找图片
+	FindByID(ctx context.Context, id uint) (*Image, error)
+
+	// FindByProduct 按排序查询商品的全部图片
+	FindByProduct(ctx context.Context, productID uint) ([]Image, error)
+
+	// Create 创建图片
+	Create(ctx context.Context, image *Image) error
+
+	// Delete 删除图片
+	Delete(ctx context.Context, id uint) error
+
+	// Reorder 按给定的图片ID顺序重新排序
+	Reorder(ctx context.Context, productID uint, imageIDs []uint) error
+
+	// SetPrimary 设置商品主图，同一商品只有一张主图
+	SetPrimary(ctx context.Context, productID uint, imageID uint) error
+}
+
+// ReorderImagesInput 图片排序的输入参数
+type ReorderImagesInput struct {
+	ImageIDs []uint `json:"image_ids" binding:"required,min=1"`
+}
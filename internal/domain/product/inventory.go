@@ -0,0 +1,55 @@
+package product
+
+import (
+	"context"
+	"time"
+)
+
+// Reservation 表示订单对商品库存的预占记录
+// 下单时创建，支付确认后转为实际扣减，订单取消或超时后释放
+type Reservation struct {
+	ID        uint      `json:"id"`
+	OrderID   uint      `json:"order_id"`
+	ProductID uint      `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReservationStatus 预占状态常量
+const (
+	ReservationStatusActive    = "active"    // 预占中
+	ReservationStatusConfirmed = "confirmed" // 已支付，转为实际扣减
+	ReservationStatusReleased  = "released"  // 已释放
+)
+
+// IsExpired 判断预占在指定时间是否已过期
+func (r *Reservation) IsExpired(now time.Time) bool {
+	return r.Status == ReservationStatusActive && !now.Before(r.ExpiresAt)
+}
+
+// ReservationItem 需要预占的商品及数量
+type ReservationItem struct {
+	ProductID uint
+	Quantity  int
+}
+
+// InventoryRepository 库存预占仓库接口
+type InventoryRepository interface {
+	// Reserve 在同一事务中为订单预占多个商品的库存，任一商品库存不足则整体失败
+	Reserve(ctx context.Context, orderID uint, items []ReservationItem, expiresAt time.Time) ([]Reservation, error)
+
+	// ConfirmByOrder 将订单的有效预占转为实际库存扣减
+	ConfirmByOrder(ctx context.Context, orderID uint) error
+
+	// ReleaseByOrder 释放订单的有效预占
+	ReleaseByOrder(ctx context.Context, orderID uint) error
+
+	// ReleaseExpired 释放在指定时间之前过期的预占，返回被释放的预占
+	ReleaseExpired(ctx context.Context, now time.Time, limit int) ([]Reservation, error)
+
+	// FindByOrder 查询订单的全部预占记录
+	FindByOrder(ctx context.Context, orderID uint) ([]Reservation, error)
+}
@@ -0,0 +1,124 @@
+package product
+
+import (
+	"fmt"
+	"time"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// ProductStatus 商品状态常量
+const (
+	ProductStatusDraft      = "draft"        // 草稿
+	ProductStatusScheduled  = "scheduled"    // 等待定时上架
+	ProductStatusPublished  = "published"    // 已上架
+	ProductStatusOutOfStock = "out_of_stock" // 已上架但可售库存为零，由系统自动切换
+	ProductStatusArchived   = "archived"     // 已归档
+)
+
+// productStatusTransitions 允许手动执行的状态转换
+// 上架与缺货之间的切换只能由库存变化触发，不在此表中
+var productStatusTransitions = map[string][]string{
+	ProductStatusDraft:      {ProductStatusScheduled, ProductStatusPublished, ProductStatusArchived},
+	ProductStatusScheduled:  {ProductStatusDraft, ProductStatusPublished, ProductStatusArchived},
+	ProductStatusPublished:  {ProductStatusDraft, ProductStatusArchived},
+	ProductStatusOutOfStock: {ProductStatusDraft, ProductStatusArchived},
+	ProductStatusArchived:   {ProductStatusDraft},
+}
+
+// IsValidProductStatus 判断是否为有效的商品状态
+func IsValidProductStatus(status string) bool {
+	_, ok := productStatusTransitions[status]
+	return ok
+}
+
+// VisibleProductStatuses 对外可见的商品状态
+var VisibleProductStatuses = []string{ProductStatusPublished, ProductStatusOutOfStock}
+
+// IsVisible 判断商品是否对买家可见，缺货商品仍然展示
+func (p *Product) IsVisible() bool {
+	return p.Status == ProductStatusPublished || p.Status == ProductStatusOutOfStock
+}
+
+// CanTransitionTo 判断商品是否允许手动切换到目标状态
+func (p *Product) CanTransitionTo(status string) bool {
+	for _, next := range productStatusTransitions[p.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeStatusInput 修改商品状态的输入参数
+type ChangeStatusInput struct {
+	Status      string     `json:"status" binding:"required"`
+	PublishAt   *time.Time `json:"publish_at"`   // status为scheduled时必填
+	UnpublishAt *time.Time `json:"unpublish_at"` // 可选，到期后自动归档
+}
+
+// TransitionTo 校验并执行手动状态转换，同时设置定时上下架时间
+// 转换到上架状态时如果可售库存为零，会直接进入缺货状态
+func (p *Product) TransitionTo(input ChangeStatusInput, now time.Time) error {
+	if !IsValidProductStatus(input.Status) {
+		return apierror.NewValidationError("无效的商品状态", input.Status)
+	}
+	if !p.CanTransitionTo(input.Status) {
+		return apierror.NewInvalidStateTransitionError("不允许的商品状态变更", fmt.Sprintf("%s -> %s", p.Status, input.Status))
+	}
+
+	switch input.Status {
+	case ProductStatusScheduled:
+		if input.PublishAt == nil || !input.PublishAt.After(now) {
+			return apierror.NewValidationError("无效的定时上架时间", "定时上架时间必须晚于当前时间")
+		}
+		if input.UnpublishAt != nil && !input.UnpublishAt.After(*input.PublishAt) {
+			return apierror.NewValidationError("无效的定时下架时间", "定时下架时间必须晚于上架时间")
+		}
+		p.PublishAt = input.PublishAt
+		p.UnpublishAt = input.UnpublishAt
+	case ProductStatusPublished:
+		if input.PublishAt != nil {
+			return apierror.NewValidationError("无效的定时上架时间", "立即上架时不能指定上架时间")
+		}
+		if input.UnpublishAt != nil && !input.UnpublishAt.After(now) {
+			return apierror.NewValidationError("无效的定时下架时间", "定时下架时间必须晚于当前时间")
+		}
+		p.PublishAt = nil
+		p.UnpublishAt = input.UnpublishAt
+	default:
+		if input.PublishAt != nil || input.UnpublishAt != nil {
+			return apierror.NewValidationError("无效的请求数据", fmt.Sprintf("%s 状态不支持定时上下架", input.Status))
+		}
+		p.PublishAt = nil
+		p.UnpublishAt = nil
+	}
+
+	p.Status = input.Status
+	if p.Status == ProductStatusPublished && p.AvailableStock() <= 0 {
+		p.Status = ProductStatusOutOfStock
+	}
+	return nil
+}
+
+// Publish 定时上架时间到达后由系统执行上架
+func (p *Product) Publish() error {
+	if p.Status != ProductStatusScheduled {
+		return apierror.NewInvalidStateTransitionError("不允许的商品状态变更", fmt.Sprintf("%s -> %s", p.Status, ProductStatusPublished))
+	}
+	p.Status = ProductStatusPublished
+	if p.AvailableStock() <= 0 {
+		p.Status = ProductStatusOutOfStock
+	}
+	p.PublishAt = nil
+	return nil
+}
+
+// Unpublish 定时下架时间到达后由系统将商品归档
+func (p *Product) Unpublish() error {
+	if !p.IsVisible() {
+		return apierror.NewInvalidStateTransitionError("不允许的商品状态变更", fmt.Sprintf("%s -> %s", p.Status, ProductStatusArchived))
+	}
+	p.Status = ProductStatusArchived
+	p.UnpublishAt = nil
+	return nil
+}
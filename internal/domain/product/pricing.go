@@ -0,0 +1,139 @@
+package product
+
+import (
+	"context"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+// PriceScheduleStatus 定时调价状态常量
+const (
+	PriceScheduleStatusPending   = "pending"   // 等待生效
+	PriceScheduleStatusActive    = "active"    // 限时促销进行中
+	PriceScheduleStatusCompleted = "completed" // 已生效的永久调价或已结束的促销
+	PriceScheduleStatusCancelled = "cancelled" // 已取消
+)
+
+// LowestPriceDays 价格下调时需要展示的最低价统计天数，依据欧盟价格指令
+const LowestPriceDays = 30
+
+// PriceSchedule 定时调价记录
+// EndsAt为空时在StartsAt永久调整常规价格；否则为限时促销，期间原常规价格作为划线价展示，
+// 结束后恢复常规价格。同一商品的限时促销时间段不能重叠
+type PriceSchedule struct {
+	ID        uint         `json:"id"`
+	ProductID uint         `json:"product_id"`
+	Price     common.Money `json:"price"`
+	StartsAt  time.Time    `json:"starts_at"`
+	EndsAt    *time.Time   `json:"ends_at,omitempty"`
+	Status    string       `json:"status"`
+	Note      string       `json:"note"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// IsSale 判断是否为限时促销
+func (s *PriceSchedule) IsSale() bool {
+	return s.EndsAt != nil
+}
+
+// RegularPrice 返回商品的常规价格，促销期间为划线价
+func (p *Product) RegularPrice() common.Money {
+	if p.CompareAtPrice != nil {
+		return *p.CompareAtPrice
+	}
+	return p.Price
+}
+
+// SetRegularPrice 修改常规价格，促销期间只修改促销结束后恢复的价格
+func (p *Product) SetRegularPrice(price common.Money) {
+	if p.CompareAtPrice != nil {
+		p.CompareAtPrice = &price
+		return
+	}
+	p.Price = price
+}
+
+// StartSale 开始限时促销，当前常规价格作为划线价
+func (p *Product) StartSale(price common.Money) {
+	regular := p.RegularPrice()
+	p.Price = price
+	p.CompareAtPrice = &regular
+}
+
+// EndSale 结束限时促销，恢复常规价格
+func (p *Product) EndSale() {
+	if p.CompareAtPrice != nil {
+		p.Price = *p.CompareAtPrice
+		p.CompareAtPrice = nil
+	}
+}
+
+// PricePoint 价格历史中的一次售价变化
+type PricePoint struct {
+	Price     common.Money `json:"price"`
+	ChangedAt time.Time    `json:"changed_at"`
+}
+
+// LowestPrice 当前售价生效前days天内的最低售价，作为降价前的参考价格
+type LowestPrice struct {
+	ProductID      uint          `json:"product_id"`
+	Price          common.Money  `json:"price"`
+	CompareAtPrice *common.Money `json:"compare_at_price,omitempty"`
+	LowestPrice    common.Money  `json:"lowest_price"`
+	Days           int           `json:"days"`
+	Since          time.Time     `json:"since"`
+	Until          time.Time     `json:"until"` // 当前售价的生效时间，统计不包含当前售价
+}
+
+// LowestPriceBefore 返回until之前生效过的最低售价，points按时间排序，第一条为统计开始时生效的售价
+// until及之后的售价变化不计入；until之前没有售价时返回false
+func LowestPriceBefore(points []PricePoint, until time.Time) (common.Money, bool) {
+	var lowest common.Money
+	found := false
+	for _, point := range points {
+		if !point.ChangedAt.Before(until) {
+			break
+		}
+		if !found || point.Price < lowest {
+			lowest = point.Price
+			found = true
+		}
+	}
+	return lowest, found
+}
+
+// PriceRepository 商品价格仓库接口
+// 所有改变售价的操作都在同一事务中追加价格历史
+type PriceRepository interface {
+	// FindScheduleByID 根据ID查找定时调价
+	FindScheduleByID(ctx context.Context, id uint) (*PriceSchedule, error)
+
+	// FindSchedules 按生效时间查询商品的全部定时调价
+	FindSchedules(ctx context.Context, productID uint) ([]PriceSchedule, error)
+
+	// CreateSchedule 创建定时调价，限时促销与未结束的促销时间段重叠时返回错误
+	CreateSchedule(ctx context.Context, schedule *PriceSchedule) error
+
+	// CancelSchedule 取消定时调价，进行中的促销会立即结束
+	CancelSchedule(ctx context.Context, id uint, now time.Time) error
+
+	// FindDueSchedules 查询需要结束的促销和需要生效的调价，结束在前
+	FindDueSchedules(ctx context.Context, now time.Time, limit int) ([]PriceSchedule, error)
+
+	// ApplySchedule 执行到期的定时调价，返回商品售价是否发生变化
+	ApplySchedule(ctx context.Context, id uint, now time.Time) (bool, error)
+
+	// SetRegularPrice 修改商品的常规价格
+	SetRegularPrice(ctx context.Context, productID uint, price common.Money, now time.Time) error
+
+	// FindHistory 查询since之后的售价变化，包含since时刻生效的售价
+	FindHistory(ctx context.Context, productID uint, since time.Time) ([]PricePoint, error)
+}
+
+// CreatePriceScheduleInput 创建定时调价的输入参数
+type CreatePriceScheduleInput struct {
+	Price    common.Money `json:"price" binding:"gte=0"`
+	StartsAt time.Time    `json:"starts_at" binding:"required"`
+	EndsAt   *time.Time   `json:"ends_at" binding:"omitempty,gtfield=StartsAt"` // 为空表示永久调价
+	Note     string       `json:"note" binding:"max=200"`
+}
@@ -0,0 +1,153 @@
+package product
+
+import (
+	"context"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+// Product 表示商品领域模型
+type Product struct {
+	ID             uint          `json:"id"`
+	SKU            string        `json:"sku"`
+	Name           string        `json:"name"`
+	Description    string        `json:"description"`
+	Price          common.Money  `json:"price"`                      // 当前售价，单位：分
+	CompareAtPrice *common.Money `json:"compare_at_price,omitempty"` // 限时促销期间的划线价(常规价格)
+	Stock          int           `json:"stock"`                      // 实际库存
+	Reserved       int           `json:"reserved"`                   // 已被未支付订单预占的库存
+	Weight         int           `json:"weight"`                     // 单件重量，单位：克，用于计算运费
+	TaxClass       string        `json:"tax_class"`                  // 税类，按收货地区和税类匹配税率
+	Type           string        `json:"type"`
+	Status         string        `json:"status"`
+	CategoryID     uint          `json:"category_id"`
+	MerchantID     uint          `json:"merchant_id"`            // 所属商家，0表示平台自营
+	PublishAt      *time.Time    `json:"publish_at,omitempty"`   // 定时上架时间，仅scheduled状态有效
+	UnpublishAt    *time.Time    `json:"unpublish_at,omitempty"` // 定时下架时间
+	Rating         Rating        `json:"rating"`
+	Images         []Image       `json:"images"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// ProductType 商品类型常量
+const (
+	ProductTypePhysical = "physical" // 实物商品，需要发货
+	ProductTypeDigital  = "digital"  // 数字商品，支付后发放激活码或下载链接
+)
+
+// IsDigital 判断是否为数字商品
+func (p *Product) IsDigital() bool {
+	return p.Type == ProductTypeDigital
+}
+
+// Rating 商品评分汇总，只统计审核通过的评价，由评价模块维护
+type Rating struct {
+	Average      float64     `json:"average"`
+	Count        int         `json:"count"`
+	Distribution map[int]int `json:"distribution"` // 星级 -> 评价数
+}
+
+// AvailableStock 返回可售库存(实际库存减去预占库存)
+func (p *Product) AvailableStock() int {
+	return p.Stock - p.Reserved
+}
+
+// ProductQuery 商品查询条件
+type ProductQuery struct {
+	Page       int
+	PageSize   int
+	CategoryID uint
+	MerchantID uint     // 为0表示不限商家
+	Statuses   []string // 为空表示不限状态
+	Search     string
+}
+
+// ProductPaginationResult 商品分页结果
+type ProductPaginationResult struct {
+	Total    int       `json:"total"`
+	Products []Product `json:"products"`
+}
+
+// ProductRepository 商品仓库接口
+type ProductRepository interface {
+	// FindByID 根据ID查找商品
+	FindByID(ctx context.Context, id uint) (*Product, error)
+
+	// FindBySKU 根据SKU查找商品
+	FindBySKU(ctx context.Context, sku string) (*Product, error)
+
+	// FindByIDs 按给定ID的顺序批量查找商品，不存在的ID会被忽略
+	FindByIDs(ctx context.Context, ids []uint) ([]Product, error)
+
+	// Find 按条件分页查询商品，不处理全文搜索条件
+	Find(ctx context.Context, query ProductQuery) (*ProductPaginationResult, error)
+
+	// Create 创建商品
+	Create(ctx context.Context, product *Product) error
+
+	// Update 更新商品基础信息，不会覆盖价格、库存、状态和评分字段
+	Update(ctx context.Context, product *Product) error
+
+	// UpdateStatus 当商品仍处于from状态时将其改为to状态，并写入定时上下架时间
+	// 返回false表示商品状态已被其它流程修改
+	UpdateStatus(ctx context.Context, p *Product, from string) (bool, error)
+
+	// SyncStockStatus 根据可售库存在上架和缺货状态之间切换，返回状态发生变化的商品ID
+	SyncStockStatus(ctx context.Context, ids []uint) ([]uint, error)
+
+	// FindDueForPublish 查询定时上架时间已到的商品
+	FindDueForPublish(ctx context.Context, now time.Time, limit int) ([]Product, error)
+
+	// FindDueForUnpublish 查询定时下架时间已到且仍在售的商品
+	FindDueForUnpublish(ctx context.Context, now time.Time, limit int) ([]Product, error)
+
+	// Delete 删除商品
+	Delete(ctx context.Context, id uint) error
+
+	// UpdateStock 原子地增减实际库存，扣减后库存不能低于已预占数量
+	UpdateStock(ctx context.Context, id uint, delta int) error
+
+	// LockByID 在事务中锁定商品行并返回最新的商品
+	LockByID(ctx context.Context, id uint) (*Product, error)
+
+	// Transaction 在事务中执行fn，fn内通过ctx调用的仓库方法共用同一个事务
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// CreateProductInput 创建商品的输入参数
+type CreateProductInput struct {
+	SKU         string       `json:"sku" binding:"required,max=64"`
+	Name        string       `json:"name" binding:"required,max=200"`
+	Description string       `json:"description"`
+	Type        string       `json:"type" binding:"omitempty,oneof=physical digital"` // 默认为实物商品
+	Price       common.Money `json:"price" binding:"gte=0"`
+	Stock       int          `json:"stock" binding:"gte=0"`
+	Weight      int          `json:"weight" binding:"gte=0"`
+	TaxClass    string       `json:"tax_class" binding:"max=30"` // 默认为standard
+	CategoryID  uint         `json:"category_id"`
+	MerchantID  uint         `json:"-"` // 由商家后台设置为当前商家，管理员创建的商品为平台自营
+}
+
+// UpdateProductInput 更新商品的输入参数，为空的字段不更新
+type UpdateProductInput struct {
+	Name        *string       `json:"name" binding:"omitempty,max=200"`
+	Description *string       `json:"description"`
+	Type        *string       `json:"type" binding:"omitempty,oneof=physical digital"`
+	Price       *common.Money `json:"price" binding:"omitempty,gte=0"`
+	Weight      *int          `json:"weight" binding:"omitempty,gte=0"`
+	TaxClass    *string       `json:"tax_class" binding:"omitempty,max=30"`
+	CategoryID  *uint         `json:"category_id"`
+}
+
+// ImportUpdateInput 按导入行更新商品的输入参数，为空的字段不更新
+type ImportUpdateInput struct {
+	UpdateProductInput
+	Stock  *int   // 目标库存，不是增量
+	Status string // 目标状态
+}
+
+// AdjustStockInput 调整库存的输入参数
+type AdjustStockInput struct {
+	Delta int `json:"delta" binding:"required"`
+}
@@ -0,0 +1,31 @@
+package product
+
+import "context"
+
+// SearchQuery 商品全文搜索条件
+type SearchQuery struct {
+	Text       string
+	CategoryID uint
+	MerchantID uint
+	Statuses   []string
+	Page       int
+	PageSize   int
+}
+
+// SearchResult 商品搜索结果，ProductIDs按相关度从高到低排列
+type SearchResult struct {
+	Total      int
+	ProductIDs []uint
+}
+
+// SearchIndex 商品全文搜索索引接口
+type SearchIndex interface {
+	// Index 新增或更新商品的索引
+	Index(ctx context.Context, product *Product) error
+
+	// Remove 从索引中删除商品
+	Remove(ctx context.Context, productID uint) error
+
+	// Search 按相关度搜索商品
+	Search(ctx context.Context, query SearchQuery) (*SearchResult, error)
+}
@@ -0,0 +1,322 @@
+package promotion
+
+import (
+	"cmp"
+	"slices"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+// Basket 参与优惠计算的购物篮，价格由调用方按结算时的售价填写
+type Basket struct {
+	UserID      uint
+	Lines       []BasketLine
+	ShippingFee common.Money
+	Codes       []string
+}
+
+// BasketLine 购物篮中的一行，同一商品只能出现一次
+type BasketLine struct {
+	ProductID  uint         `json:"product_id"`
+	CategoryID uint         `json:"category_id"`
+	Quantity   int          `json:"quantity"`
+	UnitPrice  common.Money `json:"unit_price"`
+}
+
+// Subtotal 返回订单行的原始金额
+func (l BasketLine) Subtotal() common.Money {
+	return l.UnitPrice.Mul(l.Quantity)
+}
+
+// LineDiscount 促销在某一订单行上的减免金额
+type LineDiscount struct {
+	ProductID uint         `json:"product_id"`
+	Amount    common.Money `json:"amount"`
+}
+
+// AppliedPromotion 最终生效的促销及其减免明细
+type AppliedPromotion struct {
+	PromotionID      uint           `json:"promotion_id"`
+	Name             string         `json:"name"`
+	Code             string         `json:"code,omitempty"`
+	Type             string         `json:"type"`
+	Discount         common.Money   `json:"discount"` // 订单行减免合计
+	ShippingDiscount common.Money   `json:"shipping_discount"`
+	Lines            []LineDiscount `json:"lines"`
+}
+
+// LineResult 优惠后的订单行
+type LineResult struct {
+	BasketLine
+	Subtotal common.Money `json:"subtotal"`
+	Discount common.Money `json:"discount"`
+	Total    common.Money `json:"total"`
+}
+
+// RejectedCode 未生效的优惠码及原因
+type RejectedCode struct {
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+}
+
+// Evaluation 优惠计算结果
+type Evaluation struct {
+	Lines            []LineResult       `json:"lines"`
+	Subtotal         common.Money       `json:"subtotal"`
+	Discount         common.Money       `json:"discount"`
+	ShippingFee      common.Money       `json:"shipping_fee"`
+	ShippingDiscount common.Money       `json:"shipping_discount"`
+	FreeShipping     bool               `json:"free_shipping"`
+	Total            common.Money       `json:"total"` // 商品合计加运费减去全部优惠
+	Applied          []AppliedPromotion `json:"applied"`
+	Rejected         []RejectedCode     `json:"rejected"`
+}
+
+// Evaluate 计算购物篮可享受的优惠
+// candidates需要事先排除不在有效期内或超出使用次数的促销，rejected为调用方已拒绝的优惠码。
+// 促销按优先级从高到低、ID从小到大排序后计算：每个独占促销单独作为一个方案，
+// 全部可叠加的促销依次作用于剩余金额作为一个方案，取优惠总额最大的方案，
+// 金额相同时取排序靠前的方案，因此相同输入总是得到相同结果
+func Evaluate(basket Basket, candidates []Promotion, rejected []RejectedCode) *Evaluation {
+	promotions := slices.Clone(candidates)
+	slices.SortStableFunc(promotions, func(a, b Promotion) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	// 排除不满足最低金额或没有适用商品的促销
+	applicable := make([]Promotion, 0, len(promotions))
+	for _, p := range promotions {
+		if reason := checkEligibility(basket, &p); reason != "" {
+			if p.Code != "" {
+				rejected = append(rejected, RejectedCode{Code: p.Code, Reason: reason})
+			}
+			continue
+		}
+		applicable = append(applicable, p)
+	}
+
+	// 按排序生成候选方案
+	var options [][]Promotion
+	stackIndex := -1
+	for _, p := range applicable {
+		if p.Exclusive {
+			options = append(options, []Promotion{p})
+			continue
+		}
+		if stackIndex < 0 {
+			stackIndex = len(options)
+			options = append(options, nil)
+		}
+		options[stackIndex] = append(options[stackIndex], p)
+	}
+
+	best := apply(basket, nil)
+	for _, option := range options {
+		result := apply(basket, option)
+		// 优惠金额相同时，有促销生效的方案优先于不使用任何促销，例如尚未计算运费时的免运费
+		total, bestTotal := result.Discount+result.ShippingDiscount, best.Discount+best.ShippingDiscount
+		if total > bestTotal || (total == bestTotal && len(best.Applied) == 0 && len(result.Applied) > 0) {
+			best = result
+		}
+	}
+
+	// 未被选中方案包含的优惠码
+	for _, p := range applicable {
+		if p.Code == "" || slices.ContainsFunc(best.Applied, func(a AppliedPromotion) bool { return a.PromotionID == p.ID }) {
+			continue
+		}
+		rejected = append(rejected, RejectedCode{Code: p.Code, Reason: "不能与其它优惠叠加或没有可减免的金额"})
+	}
+	best.Rejected = rejected
+	if best.Rejected == nil {
+		best.Rejected = []RejectedCode{}
+	}
+	return best
+}
+
+// checkEligibility 检查促销的适用条件，返回不满足的原因
+func checkEligibility(basket Basket, p *Promotion) string {
+	var subtotal common.Money
+	units := 0
+	for _, line := range basket.Lines {
+		if p.AppliesTo(line) {
+			subtotal += line.Subtotal()
+			units += line.Quantity
+		}
+	}
+
+	switch {
+	case units == 0:
+		return "购物篮中没有适用的商品"
+	case subtotal < p.MinSubtotal:
+		return "未达到最低消费金额 " + p.MinSubtotal.String()
+	case p.Type == PromotionTypeBuyXGetY && units < p.BuyQuantity+p.GetQuantity:
+		return "适用商品数量不足"
+	}
+	return ""
+}
+
+// apply 依次计算方案中的促销，每个促销作用于前面促销减免后的剩余金额
+func apply(basket Basket, promotions []Promotion) *Evaluation {
+	remaining := make([]common.Money, len(basket.Lines))
+	var subtotal common.Money
+	for i, line := range basket.Lines {
+		remaining[i] = line.Subtotal()
+		subtotal += remaining[i]
+	}
+	shippingRemaining := basket.ShippingFee
+
+	result := &Evaluation{
+		Subtotal:    subtotal,
+		ShippingFee: basket.ShippingFee,
+		Applied:     []AppliedPromotion{},
+	}
+
+	for _, p := range promotions {
+		var discounts []common.Money
+		var shippingDiscount common.Money
+
+		switch p.Type {
+		case PromotionTypePercentage:
+			discounts = percentageDiscounts(basket, &p, remaining)
+		case PromotionTypeFixedAmount:
+			discounts = fixedAmountDiscounts(basket, &p, remaining)
+		case PromotionTypeBuyXGetY:
+			discounts = buyXGetYDiscounts(basket, &p, remaining)
+		case PromotionTypeFreeShipping:
+			shippingDiscount = shippingRemaining
+			shippingRemaining = 0
+			result.FreeShipping = true
+		}
+
+		applied := AppliedPromotion{
+			PromotionID:      p.ID,
+			Name:             p.Name,
+			Code:             p.Code,
+			Type:             p.Type,
+			ShippingDiscount: shippingDiscount,
+			Lines:            []LineDiscount{},
+		}
+		for i, d := range discounts {
+			if d <= 0 {
+				continue
+			}
+			remaining[i] -= d
+			applied.Discount += d
+			applied.Lines = append(applied.Lines, LineDiscount{ProductID: basket.Lines[i].ProductID, Amount: d})
+		}
+
+		// 前面的促销已经减免全部金额时不计入
+		if applied.Discount == 0 && applied.ShippingDiscount == 0 && p.Type != PromotionTypeFreeShipping {
+			continue
+		}
+		result.Applied = append(result.Applied, applied)
+		result.Discount += applied.Discount
+		result.ShippingDiscount += applied.ShippingDiscount
+	}
+
+	result.Lines = make([]LineResult, 0, len(basket.Lines))
+	for i, line := range basket.Lines {
+		result.Lines = append(result.Lines, LineResult{
+			BasketLine: line,
+			Subtotal:   line.Subtotal(),
+			Discount:   line.Subtotal() - remaining[i],
+			Total:      remaining[i],
+		})
+	}
+	result.Total = subtotal - result.Discount + basket.ShippingFee - result.ShippingDiscount
+	return result
+}
+
+// percentageDiscounts 按比例减免每个适用订单行的剩余金额，向下取整到分
+func percentageDiscounts(basket Basket, p *Promotion, remaining []common.Money) []common.Money {
+	discounts := make([]common.Money, len(basket.Lines))
+	for i, line := range basket.Lines {
+		if p.AppliesTo(line) {
+			discounts[i] = remaining[i] * common.Money(p.Percent) / 100
+		}
+	}
+	return discounts
+}
+
+// fixedAmountDiscounts 将固定减免金额按剩余金额比例分摊到适用订单行
+// 向下取整产生的零头按订单行顺序逐分补齐
+func fixedAmountDiscounts(basket Basket, p *Promotion, remaining []common.Money) []common.Money {
+	discounts := make([]common.Money, len(basket.Lines))
+
+	var eligible common.Money
+	for i, line := range basket.Lines {
+		if p.AppliesTo(line) {
+			eligible += remaining[i]
+		}
+	}
+	if eligible <= 0 {
+		return discounts
+	}
+
+	target := min(p.Amount, eligible)
+	var allocated common.Money
+	for i, line := range basket.Lines {
+		if p.AppliesTo(line) {
+			discounts[i] = target * remaining[i] / eligible
+			allocated += discounts[i]
+		}
+	}
+	for i, line := range basket.Lines {
+		if allocated >= target {
+			break
+		}
+		if p.AppliesTo(line) && discounts[i] < remaining[i] {
+			discounts[i]++
+			allocated++
+		}
+	}
+	return discounts
+}
+
+// buyXGetYDiscounts 将适用商品按单价从高到低排列，每X+Y件中最便宜的Y件按比例折扣
+// 按订单行在排列中的区间计算免单件数，计算量与商品数量无关
+func buyXGetYDiscounts(basket Basket, p *Promotion, remaining []common.Money) []common.Money {
+	discounts := make([]common.Money, len(basket.Lines))
+
+	groupSize := p.BuyQuantity + p.GetQuantity
+	if p.GetQuantity <= 0 || groupSize <= 0 {
+		return discounts
+	}
+
+	var lines []int
+	total := 0
+	for i, line := range basket.Lines {
+		if p.AppliesTo(line) && line.Quantity > 0 {
+			lines = append(lines, i)
+			total += line.Quantity
+		}
+	}
+	slices.SortStableFunc(lines, func(a, b int) int {
+		return cmp.Compare(basket.Lines[b].UnitPrice, basket.Lines[a].UnitPrice)
+	})
+
+	// freeBefore 返回排列中前n件里的免单件数，凑不满一组的尾数不参与
+	grouped := total / groupSize * groupSize
+	freeBefore := func(n int) int {
+		n = min(n, grouped)
+		return n/groupSize*p.GetQuantity + max(0, n%groupSize-p.BuyQuantity)
+	}
+
+	// 折扣按前面促销减免后的单件剩余金额计算
+	offset := 0
+	for _, i := range lines {
+		quantity := basket.Lines[i].Quantity
+		free := freeBefore(offset+quantity) - freeBefore(offset)
+		offset += quantity
+		if free == 0 {
+			continue
+		}
+		unitRemaining := remaining[i] / common.Money(quantity)
+		d := unitRemaining * common.Money(p.Percent) / 100
+		discounts[i] = min(d.Mul(free), remaining[i])
+	}
+	return discounts
+}
@@ -0,0 +1,86 @@
+package promotion
+
+import (
+	"testing"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+func TestBuyXGetYDiscounts(t *testing.T) {
+	buy2get1 := &Promotion{Type: PromotionTypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1, Percent: 100}
+
+	tests := []struct {
+		name  string
+		promo *Promotion
+		lines []BasketLine
+		want  []common.Money
+	}{
+		{
+			name:  "同一商品买二送一",
+			promo: buy2get1,
+			lines: []BasketLine{{ProductID: 1, Quantity: 3, UnitPrice: 1000}},
+			want:  []common.Money{1000},
+		},
+		{
+			name:  "不满一组不减免",
+			promo: buy2get1,
+			lines: []BasketLine{{ProductID: 1, Quantity: 2, UnitPrice: 1000}},
+			want:  []common.Money{0},
+		},
+		{
+			name:  "每组中最便宜的一件免费",
+			promo: buy2get1,
+			lines: []BasketLine{
+				{ProductID: 1, Quantity: 1, UnitPrice: 500},
+				{ProductID: 2, Quantity: 2, UnitPrice: 2000},
+			},
+			want: []common.Money{500, 0},
+		},
+		{
+			name:  "跨订单行分组",
+			promo: buy2get1,
+			lines: []BasketLine{
+				{ProductID: 1, Quantity: 4, UnitPrice: 3000},
+				{ProductID: 2, Quantity: 3, UnitPrice: 1000},
+			},
+			// 排列为 3000x4, 1000x3，第3件和第6件免费，第7件凑不满一组
+			want: []common.Money{3000, 1000},
+		},
+		{
+			name:  "半价",
+			promo: &Promotion{Type: PromotionTypeBuyXGetY, BuyQuantity: 1, GetQuantity: 1, Percent: 50},
+			lines: []BasketLine{{ProductID: 1, Quantity: 4, UnitPrice: 1000}},
+			want:  []common.Money{1000},
+		},
+		{
+			name:  "大数量按算术计算",
+			promo: buy2get1,
+			lines: []BasketLine{{ProductID: 1, Quantity: 300000001, UnitPrice: 1}},
+			want:  []common.Money{100000000},
+		},
+		{
+			name:  "不适用的商品不参与",
+			promo: &Promotion{Type: PromotionTypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1, Percent: 100, ProductIDs: []uint{1}},
+			lines: []BasketLine{
+				{ProductID: 1, Quantity: 2, UnitPrice: 1000},
+				{ProductID: 2, Quantity: 1, UnitPrice: 100},
+			},
+			want: []common.Money{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining := make([]common.Money, len(tt.lines))
+			for i, line := range tt.lines {
+				remaining[i] = line.Subtotal()
+			}
+
+			got := buyXGetYDiscounts(Basket{Lines: tt.lines}, tt.promo, remaining)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("line %d discount = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package promotion
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+// PromotionType 促销类型常量
+const (
+	PromotionTypePercentage   = "percentage"    // 按比例折扣
+	PromotionTypeFixedAmount  = "fixed_amount"  // 固定金额减免，按金额比例分摊到各订单行
+	PromotionTypeBuyXGetY     = "buy_x_get_y"   // 买X件，其中价格最低的Y件按比例折扣
+	PromotionTypeFreeShipping = "free_shipping" // 免运费
+)
+
+// Promotion 促销活动
+// Code为空的促销满足条件时自动应用，否则需要在结算时输入优惠码
+type Promotion struct {
+	ID           uint         `json:"id"`
+	Name         string       `json:"name"`
+	Code         string       `json:"code,omitempty"`
+	Type         string       `json:"type"`
+	Percent      int          `json:"percent,omitempty"` // percentage和buy_x_get_y的折扣比例，100表示免费
+	Amount       common.Money `json:"amount,omitempty"`  // fixed_amount的减免金额
+	BuyQuantity  int          `json:"buy_quantity,omitempty"`
+	GetQuantity  int          `json:"get_quantity,omitempty"`
+	MinSubtotal  common.Money `json:"min_subtotal"` // 适用商品的最低合计金额
+	ProductIDs   []uint       `json:"product_ids"`  // 适用商品，与分类都为空表示全部商品
+	CategoryIDs  []uint       `json:"category_ids"` // 适用分类
+	StartsAt     *time.Time   `json:"starts_at,omitempty"`
+	EndsAt       *time.Time   `json:"ends_at,omitempty"`
+	UsageLimit   int          `json:"usage_limit"`    // 总使用次数上限，0表示不限
+	PerUserLimit int          `json:"per_user_limit"` // 每个用户的使用次数上限，0表示不限
+	UsedCount    int          `json:"used_count"`
+	Exclusive    bool         `json:"exclusive"` // 不能与其它促销叠加
+	Priority     int          `json:"priority"`  // 数值越大越先计算
+	Active       bool         `json:"active"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// NormalizeCode 统一优惠码格式，优惠码不区分大小写
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsWithinWindow 判断当前时间是否在有效期内
+func (p *Promotion) IsWithinWindow(now time.Time) bool {
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// IsExhausted 判断总使用次数是否已达上限
+func (p *Promotion) IsExhausted() bool {
+	return p.UsageLimit > 0 && p.UsedCount >= p.UsageLimit
+}
+
+// AppliesTo 判断订单行是否在促销的适用范围内
+func (p *Promotion) AppliesTo(line BasketLine) bool {
+	if len(p.ProductIDs) == 0 && len(p.CategoryIDs) == 0 {
+		return true
+	}
+	return slices.Contains(p.ProductIDs, line.ProductID) || slices.Contains(p.CategoryIDs, line.CategoryID)
+}
+
+// PromotionQuery 促销查询条件
+type PromotionQuery struct {
+	Page     int
+	PageSize int
+	Active   *bool
+	Code     string
+}
+
+// PromotionPaginationResult 促销分页结果
+type PromotionPaginationResult struct {
+	Total      int         `json:"total"`
+	Promotions []Promotion `json:"promotions"`
+}
+
+// Redemption 一次订单对促销的使用记录
+type Redemption struct {
+	ID          uint         `json:"id"`
+	PromotionID uint         `json:"promotion_id"`
+	UserID      uint         `json:"user_id"`
+	OrderID     uint         `json:"order_id"`
+	Amount      common.Money `json:"amount"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// PromotionRepository 促销仓库接口
+type PromotionRepository interface {
+	// FindByID 根据ID查找促销
+	FindByID(ctx context.Context, id uint) (*Promotion, error)
+
+	// FindByCode 根据优惠码查找促销
+	FindByCode(ctx context.Context, code string) (*Promotion, error)
+
+	// FindAutomatic 查询当前有效期内已启用的自动促销
+	FindAutomatic(ctx context.Context, now time.Time) ([]Promotion, error)
+
+	// Find 按条件分页查询促销
+	Find(ctx context.Context, query PromotionQuery) (*PromotionPaginationResult, error)
+
+	// Create 创建促销，优惠码重复时返回错误
+	Create(ctx context.Context, promotion *Promotion) error
+
+	// Update 更新促销，不会覆盖使用次数
+	Update(ctx context.Context, promotion *Promotion) error
+
+	// Delete 删除促销
+	Delete(ctx context.Context, id uint) error
+
+	// CountUserRedemptions 统计用户使用各促销的次数
+	CountUserRedemptions(ctx context.Context, userID uint, promotionIDs []uint) (map[uint]int, error)
+
+	// Redeem 在同一事务中记录订单使用的促销并增加使用次数，任一促销超出使用上限时全部回滚
+	Redeem(ctx context.Context, redemptions []Redemption) error
+
+	// ReleaseOrder 订单取消后删除使用记录并归还使用次数
+	ReleaseOrder(ctx context.Context, orderID uint) error
+}
+
+// PromotionInput 创建和更新促销的输入参数
+type PromotionInput struct {
+	Name         string       `json:"name" binding:"required,max=100"`
+	Code         string       `json:"code" binding:"max=50"`
+	Type         string       `json:"type" binding:"required,oneof=percentage fixed_amount buy_x_get_y free_shipping"`
+	Percent      int          `json:"percent" binding:"gte=0,lte=100"`
+	Amount       common.Money `json:"amount" binding:"gte=0"`
+	BuyQuantity  int          `json:"buy_quantity" binding:"gte=0"`
+	GetQuantity  int          `json:"get_quantity" binding:"gte=0"`
+	MinSubtotal  common.Money `json:"min_subtotal" binding:"gte=0"`
+	ProductIDs   []uint       `json:"product_ids"`
+	CategoryIDs  []uint       `json:"category_ids"`
+	StartsAt     *time.Time   `json:"starts_at"`
+	EndsAt       *time.Time   `json:"ends_at"`
+	UsageLimit   int          `json:"usage_limit" binding:"gte=0"`
+	PerUserLimit int          `json:"per_user_limit" binding:"gte=0"`
+	Exclusive    bool         `json:"exclusive"`
+	Priority     int          `json:"priority"`
+	Active       bool         `json:"active"`
+}
+
+// PreviewInput 结算前预览优惠的输入参数
+type PreviewInput struct {
+	Items []PreviewItem `json:"items" binding:"required,min=1,dive"`
+	Codes []string      `json:"codes" binding:"max=5"`
+}
+
+// PreviewItem 预览的商品和数量，数量上限与购物车一致
+type PreviewItem struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,gt=0,lte=99"`
+}
@@ -0,0 +1,109 @@
+package review
+
+import (
+	"context"
+	"time"
+)
+
+// Review 表示买家对已购商品的评价
+type Review struct {
+	ID             uint      `json:"id"`
+	ProductID      uint      `json:"product_id"`
+	UserID         uint      `json:"user_id"`
+	Rating         int       `json:"rating"` // 1-5星
+	Title          string    `json:"title"`
+	Content        string    `json:"content"`
+	Status         string    `json:"status"`
+	HelpfulCount   int       `json:"helpful_count"`
+	ModerationNote string    `json:"moderation_note,omitempty"` // 管理员审核备注
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ReviewStatus 评价状态常量
+// 新建和修改后的评价需要重新审核，只有审核通过的评价对外展示并计入商品评分
+const (
+	ReviewStatusPending  = "pending"  // 待审核
+	ReviewStatusApproved = "approved" // 审核通过
+	ReviewStatusHidden   = "hidden"   // 已隐藏
+)
+
+// IsValidModerationStatus 判断是否为管理员可设置的审核结果
+func IsValidModerationStatus(status string) bool {
+	return status == ReviewStatusApproved || status == ReviewStatusHidden
+}
+
+// 评价列表排序方式
+const (
+	ReviewSortNewest  = "newest"  // 最新发布
+	ReviewSortHelpful = "helpful" // 最有帮助
+)
+
+// ReviewQuery 评价查询条件
+type ReviewQuery struct {
+	Page      int
+	PageSize  int
+	ProductID uint
+	UserID    uint
+	Status    string
+	Rating    int
+	Sort      string
+}
+
+// ReviewPaginationResult 评价分页结果
+type ReviewPaginationResult struct {
+	Total   int      `json:"total"`
+	Reviews []Review `json:"reviews"`
+}
+
+// ReviewRepository 评价仓库接口
+// 所有可能改变审核通过评价集合的写操作，都会在同一事务中重新计算商品的评分汇总
+type ReviewRepository interface {
+	// FindByID 根据ID查找评价
+	FindByID(ctx context.Context, id uint) (*Review, error)
+
+	// Find 按条件分页查询评价
+	Find(ctx context.Context, query ReviewQuery) (*ReviewPaginationResult, error)
+
+	// Create 创建评价，同一用户对同一商品只能评价一次
+	Create(ctx context.Context, review *Review) error
+
+	// Update 更新评价内容和状态
+	Update(ctx context.Context, review *Review) error
+
+	// Delete 删除评价及其投票
+	Delete(ctx context.Context, id uint) error
+
+	// AddHelpfulVote 记录用户认为评价有帮助，重复投票返回错误
+	AddHelpfulVote(ctx context.Context, reviewID uint, userID uint) error
+
+	// RemoveHelpfulVote 撤销用户的有帮助投票
+	RemoveHelpfulVote(ctx context.Context, reviewID uint, userID uint) error
+}
+
+// PurchaseVerifier 校验用户是否购买过商品
+// 由订单模块实现，评价模块只依赖该接口
+type PurchaseVerifier interface {
+	// HasPurchased 判断用户是否有包含该商品的已支付订单
+	HasPurchased(ctx context.Context, userID uint, productID uint) (bool, error)
+}
+
+// CreateReviewInput 创建评价的输入参数
+type CreateReviewInput struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Title   string `json:"title" binding:"max=100"`
+	Content string `json:"content" binding:"required,max=2000"`
+}
+
+// UpdateReviewInput 修改评价的输入参数，为空的字段不更新
+type UpdateReviewInput struct {
+	Rating  *int    `json:"rating" binding:"omitempty,min=1,max=5"`
+	Title   *string `json:"title" binding:"omitempty,max=100"`
+	Content *string `json:"content" binding:"omitempty,min=1,max=2000"`
+}
+
+// ModerateReviewInput 审核评价的输入参数
+type ModerateReviewInput struct {
+	Status string `json:"status" binding:"required"`
+	Note   string `json:"note" binding:"max=500"`
+}
@@ -0,0 +1,198 @@
+package shipping
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// RestOfWorld 配送区域中代表其它所有国家的代码，只有没有专门区域的国家才会匹配
+const RestOfWorld = "*"
+
+// 运费计算方式
+const (
+	RateTypeFlat   = "flat"   // 固定运费
+	RateTypeWeight = "weight" // 按重量区间计费
+)
+
+// Zone 配送区域，一个国家只能属于一个区域
+type Zone struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Countries []string  `json:"countries"` // ISO 3166-1 二字码，"*"表示其它所有国家
+	Methods   []Method  `json:"methods"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Method 区域内可选的配送方式及其运费表
+type Method struct {
+	ID          uint          `json:"id"`
+	ZoneID      uint          `json:"zone_id"`
+	Name        string        `json:"name"`
+	RateType    string        `json:"rate_type"`
+	FlatRate    common.Money  `json:"flat_rate"`              // RateType为flat时的运费
+	WeightRates []WeightRate  `json:"weight_rates,omitempty"` // RateType为weight时的重量区间，按MaxWeight升序
+	FreeOver    *common.Money `json:"free_over,omitempty"`    // 商品金额达到该值时免运费
+	MinDays     int           `json:"min_days"`               // 预计送达的最短天数
+	MaxDays     int           `json:"max_days"`
+	Active      bool          `json:"active"`
+}
+
+// WeightRate 重量区间运费，包裹重量不超过MaxWeight时收取Fee
+type WeightRate struct {
+	MaxWeight int          `json:"max_weight" binding:"required,gt=0"` // 单位：克
+	Fee       common.Money `json:"fee" binding:"gte=0"`
+}
+
+// Covers 判断区域是否明确包含该国家，不考虑"*"
+func (z *Zone) Covers(country string) bool {
+	for _, c := range z.Countries {
+		if c == country {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRestOfWorld 判断是否为兜底区域
+func (z *Zone) IsRestOfWorld() bool {
+	return z.Covers(RestOfWorld)
+}
+
+// Validate 校验运费表
+func (m *Method) Validate() error {
+	if m.MaxDays < m.MinDays {
+		return apierror.NewValidationError("无效的配送时效", fmt.Sprintf("%s: 最长天数不能小于最短天数", m.Name))
+	}
+	if m.RateType != RateTypeWeight {
+		return nil
+	}
+	if len(m.WeightRates) == 0 {
+		return apierror.NewValidationError("无效的运费表", fmt.Sprintf("%s: 按重量计费至少需要一个重量区间", m.Name))
+	}
+	for i := 1; i < len(m.WeightRates); i++ {
+		if m.WeightRates[i].MaxWeight <= m.WeightRates[i-1].MaxWeight {
+			return apierror.NewValidationError("无效的运费表", fmt.Sprintf("%s: 重量区间必须按重量从小到大排列", m.Name))
+		}
+	}
+	return nil
+}
+
+// Fee 计算运费，subtotal为商品金额，weight为包裹总重量(克)
+// 超过最大重量区间时返回错误，即使达到免运费门槛该配送方式也不可用
+func (m *Method) Fee(subtotal common.Money, weight int) (common.Money, error) {
+	fee := m.FlatRate
+	if m.RateType == RateTypeWeight {
+		i := sort.Search(len(m.WeightRates), func(i int) bool { return weight <= m.WeightRates[i].MaxWeight })
+		if i == len(m.WeightRates) {
+			return 0, apierror.NewValidationError("包裹超重", fmt.Sprintf("%s 最多配送 %d 克", m.Name, m.WeightRates[i-1].MaxWeight))
+		}
+		fee = m.WeightRates[i].Fee
+	}
+	if m.FreeOver != nil && subtotal >= *m.FreeOver {
+		return 0, nil
+	}
+	return fee, nil
+}
+
+// QuoteRequest 运费报价的条件
+type QuoteRequest struct {
+	Country  string
+	Subtotal common.Money // 商品金额，用于判断是否达到免运费门槛
+	Weight   int          // 实物商品总重量，单位：克
+}
+
+// Quote 一种配送方式的运费报价
+type Quote struct {
+	MethodID     uint         `json:"method_id"`
+	Name         string       `json:"name"`
+	Fee          common.Money `json:"fee"`
+	FreeShipping bool         `json:"free_shipping"` // 是否因达到免运费门槛而免运费
+	MinDays      int          `json:"min_days"`
+	MaxDays      int          `json:"max_days"`
+}
+
+// NewQuote 计算配送方式的报价
+func NewQuote(m *Method, req QuoteRequest) (*Quote, error) {
+	fee, err := m.Fee(req.Subtotal, req.Weight)
+	if err != nil {
+		return nil, err
+	}
+	return &Quote{
+		MethodID:     m.ID,
+		Name:         m.Name,
+		Fee:          fee,
+		FreeShipping: m.FreeOver != nil && req.Subtotal >= *m.FreeOver,
+		MinDays:      m.MinDays,
+		MaxDays:      m.MaxDays,
+	}, nil
+}
+
+// ZoneRepository 配送区域仓库接口
+type ZoneRepository interface {
+	// FindAll 查询全部配送区域及其配送方式
+	FindAll(ctx context.Context) ([]Zone, error)
+
+	// FindByID 根据ID查询配送区域
+	FindByID(ctx context.Context, id uint) (*Zone, error)
+
+	// Create 创建配送区域及其配送方式
+	Create(ctx context.Context, zone *Zone) error
+
+	// Update 更新配送区域，配送方式整体替换
+	Update(ctx context.Context, zone *Zone) error
+
+	// Delete 删除配送区域及其配送方式
+	Delete(ctx context.Context, id uint) error
+}
+
+// ZoneInput 创建或更新配送区域的输入参数
+type ZoneInput struct {
+	Name      string        `json:"name" binding:"required,max=50"`
+	Countries []string      `json:"countries" binding:"required,min=1,dive,required"`
+	Methods   []MethodInput `json:"methods" binding:"required,min=1,max=20,dive"`
+}
+
+// MethodInput 配送方式的输入参数
+type MethodInput struct {
+	Name        string        `json:"name" binding:"required,max=50"`
+	RateType    string        `json:"rate_type" binding:"required,oneof=flat weight"`
+	FlatRate    common.Money  `json:"flat_rate" binding:"gte=0"`
+	WeightRates []WeightRate  `json:"weight_rates" binding:"max=50,dive"`
+	FreeOver    *common.Money `json:"free_over" binding:"omitempty,gte=0"`
+	MinDays     int           `json:"min_days" binding:"gte=0"`
+	MaxDays     int           `json:"max_days" binding:"gte=0"`
+	Active      *bool         `json:"active"` // 默认为true
+}
+
+// NormalizeCountries 国家代码去重并统一为大写
+func NormalizeCountries(countries []string) []string {
+	seen := make(map[string]bool, len(countries))
+	result := make([]string, 0, len(countries))
+	for _, c := range countries {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if c == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+		result = append(result, c)
+	}
+	return result
+}
+
+// QuoteInput 查询运费的输入参数
+type QuoteInput struct {
+	Country string           `json:"country" binding:"required,len=2"`
+	Items   []QuoteItemInput `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// QuoteItemInput 查询运费的商品
+type QuoteItemInput struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,gte=1"`
+}
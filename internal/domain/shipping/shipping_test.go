@@ -0,0 +1,83 @@
+package shipping
+
+import (
+	"testing"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+func TestNewQuote(t *testing.T) {
+	freeOver := common.Money(9900)
+	flat := &Method{Name: "标准快递", RateType: RateTypeFlat, FlatRate: 1000}
+	flatFreeOver := &Method{Name: "满额包邮", RateType: RateTypeFlat, FlatRate: 1000, FreeOver: &freeOver}
+	weightRates := []WeightRate{{MaxWeight: 500, Fee: 800}, {MaxWeight: 2000, Fee: 1500}, {MaxWeight: 5000, Fee: 3000}}
+	byWeight := &Method{Name: "按重量", RateType: RateTypeWeight, WeightRates: weightRates}
+	byWeightFreeOver := &Method{Name: "按重量满额包邮", RateType: RateTypeWeight, WeightRates: weightRates, FreeOver: &freeOver}
+
+	tests := []struct {
+		name     string
+		method   *Method
+		subtotal common.Money
+		weight   int
+		wantFee  common.Money
+		wantFree bool
+		wantErr  bool
+	}{
+		{name: "固定运费", method: flat, subtotal: 50000, weight: 8000, wantFee: 1000},
+		{name: "未达到免运费门槛", method: flatFreeOver, subtotal: 9899, wantFee: 1000},
+		{name: "正好达到免运费门槛", method: flatFreeOver, subtotal: 9900, wantFee: 0, wantFree: true},
+		{name: "没有重量按第一档", method: byWeight, weight: 0, wantFee: 800},
+		{name: "重量等于区间上限", method: byWeight, weight: 500, wantFee: 800},
+		{name: "超过上限进入下一档", method: byWeight, weight: 501, wantFee: 1500},
+		{name: "最大重量区间", method: byWeight, weight: 5000, wantFee: 3000},
+		{name: "超过最大重量", method: byWeight, weight: 5001, wantErr: true},
+		{name: "按重量计费满额包邮", method: byWeightFreeOver, subtotal: 10000, weight: 1800, wantFee: 0, wantFree: true},
+		{name: "满额包邮也不能超重", method: byWeightFreeOver, subtotal: 10000, weight: 6000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := NewQuote(tt.method, QuoteRequest{Country: "CN", Subtotal: tt.subtotal, Weight: tt.weight})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewQuote() = %+v, want error", quote)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewQuote() error = %v", err)
+			}
+			if quote.Fee != tt.wantFee || quote.FreeShipping != tt.wantFree {
+				t.Errorf("fee = %s free = %v, want %s %v", quote.Fee, quote.FreeShipping, tt.wantFee, tt.wantFree)
+			}
+		})
+	}
+}
+
+func TestMethodValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  Method
+		wantErr bool
+	}{
+		{name: "固定运费", method: Method{RateType: RateTypeFlat, MinDays: 1, MaxDays: 3}},
+		{name: "时效颠倒", method: Method{RateType: RateTypeFlat, MinDays: 5, MaxDays: 3}, wantErr: true},
+		{name: "按重量没有区间", method: Method{RateType: RateTypeWeight}, wantErr: true},
+		{
+			name:   "重量区间升序",
+			method: Method{RateType: RateTypeWeight, WeightRates: []WeightRate{{MaxWeight: 500}, {MaxWeight: 1000}}},
+		},
+		{
+			name:    "重量区间重复",
+			method:  Method{RateType: RateTypeWeight, WeightRates: []WeightRate{{MaxWeight: 500}, {MaxWeight: 500}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.method.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
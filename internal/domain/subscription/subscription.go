@@ -0,0 +1,356 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// 计费周期单位
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+	IntervalYear  = "year"
+)
+
+// 订阅计划状态
+const (
+	PlanStatusActive   = "active"   // 可以订阅
+	PlanStatusArchived = "archived" // 不再接受新订阅，已有订阅继续按计划续费
+)
+
+// 订阅状态
+const (
+	StatusIncomplete = "incomplete" // 首期订单待支付，支付后开始第一个周期
+	StatusTrialing   = "trialing"   // 试用期内，试用结束前生成首期续费订单
+	StatusActive     = "active"     // 当前周期已付款
+	StatusPastDue    = "past_due"   // 当前周期已结束但续费订单未支付，处于宽限期
+	StatusCancelled  = "cancelled"  // 已取消，不再续费
+)
+
+// Plan 订阅计划，绑定一个数字商品，每个计费周期生成一笔该商品的订单
+// 续费按计划的当前价格计价，计费周期创建后不能修改
+type Plan struct {
+	ID            uint         `json:"id"`
+	ProductID     uint         `json:"product_id"`
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Interval      string       `json:"interval"`
+	IntervalCount int          `json:"interval_count"` // 每个计费周期包含几个Interval，如3个月
+	Price         common.Money `json:"price"`          // 每个计费周期的价格
+	TrialDays     int          `json:"trial_days"`     // 试用天数，0表示没有试用期，每个用户只能试用一次
+	Status        string       `json:"status"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// IsActive 计划是否接受新订阅
+func (p *Plan) IsActive() bool {
+	return p.Status == PlanStatusActive
+}
+
+// PeriodEnd 从计费起点开始第n个周期的结束时间
+// 按月或按年计费时，起点日期在目标月份中不存在的取该月最后一天，如1月31日起按月计费的周期依次在2月28日、3月31日结束
+func (p *Plan) PeriodEnd(anchor time.Time, n int) time.Time {
+	count := p.IntervalCount * n
+	switch p.Interval {
+	case IntervalDay:
+		return anchor.AddDate(0, 0, count)
+	case IntervalWeek:
+		return anchor.AddDate(0, 0, 7*count)
+	case IntervalYear:
+		return addMonths(anchor, 12*count)
+	default:
+		return addMonths(anchor, count)
+	}
+}
+
+// addMonths 增加月份，日期超出目标月份的天数时取该月最后一天
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, lastDay)-1)
+}
+
+// ChangeStatus 上架或归档计划
+func (p *Plan) ChangeStatus(status string) error {
+	if status != PlanStatusActive && status != PlanStatusArchived {
+		return apierror.NewValidationError("无效的计划状态", status)
+	}
+	p.Status = status
+	return nil
+}
+
+// Subscription 用户的订阅
+// 每个周期结束前按计划生成一笔续费订单，订单支付后进入下一个周期；
+// 周期结束时续费订单仍未支付则进入宽限期并定期催缴，宽限期结束仍未支付时自动取消
+type Subscription struct {
+	ID                 uint       `json:"id"`
+	UserID             uint       `json:"user_id"`
+	PlanID             uint       `json:"plan_id"`
+	ProductID          uint       `json:"product_id"`
+	Status             string     `json:"status"`
+	TrialEndsAt        *time.Time `json:"trial_ends_at,omitempty"`
+	AnchorAt           *time.Time `json:"anchor_at,omitempty"`            // 计费起点，首期付款时间或试用结束时间
+	Periods            int        `json:"periods"`                        // 已付款的周期数
+	CurrentPeriodStart *time.Time `json:"current_period_start,omitempty"` // 首期订单支付前为空
+	CurrentPeriodEnd   *time.Time `json:"current_period_end,omitempty"`
+	CancelAtPeriodEnd  bool       `json:"cancel_at_period_end"`       // 当前周期结束后取消，不再续费
+	PendingOrderID     uint       `json:"pending_order_id,omitempty"` // 待支付的首期或续费订单
+	PaymentDueAt       *time.Time `json:"payment_due_at,omitempty"`   // 待支付订单的付款截止时间
+	DunningCount       int        `json:"dunning_count"`              // 本次宽限期内已发送的催缴通知次数
+	LastDunningAt      *time.Time `json:"last_dunning_at,omitempty"`  // 最近一次催缴通知的时间
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`     // 取消时间
+	CancelReason       string     `json:"cancel_reason,omitempty"`    // 取消原因，到期取消时在设置时记录
+	CancelledBy        string     `json:"cancelled_by,omitempty"`     // 取消方: user, admin, system
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// 取消方
+const (
+	CancelledByUser   = "user"
+	CancelledByAdmin  = "admin"
+	CancelledBySystem = "system"
+)
+
+// IsCancelled 订阅是否已取消
+func (s *Subscription) IsCancelled() bool {
+	return s.Status == StatusCancelled
+}
+
+// IsCurrent 订阅当前周期是否有效，试用期和已付款的周期内有效，宽限期内不再有效
+func (s *Subscription) IsCurrent(now time.Time) bool {
+	if s.Status != StatusTrialing && s.Status != StatusActive {
+		return false
+	}
+	return s.CurrentPeriodEnd != nil && now.Before(*s.CurrentPeriodEnd)
+}
+
+// StartTrial 开始试用，试用结束时间即计费起点
+func (s *Subscription) StartTrial(plan *Plan, now time.Time) {
+	trialEnd := now.AddDate(0, 0, plan.TrialDays)
+	s.Status = StatusTrialing
+	s.TrialEndsAt = &trialEnd
+	s.AnchorAt = &trialEnd
+	s.CurrentPeriodStart = &now
+	s.CurrentPeriodEnd = &trialEnd
+}
+
+// AwaitPayment 记录待支付的首期或续费订单
+func (s *Subscription) AwaitPayment(orderID uint, dueAt time.Time) {
+	s.PendingOrderID = orderID
+	s.PaymentDueAt = &dueAt
+}
+
+// ClearPayment 待支付订单被关闭后清除，续费任务会重新生成订单
+func (s *Subscription) ClearPayment() {
+	s.PendingOrderID = 0
+	s.PaymentDueAt = nil
+}
+
+// Renew 待支付订单付款后进入下一个周期
+// 首期订单以付款时间为计费起点；续费在宽限期内付款时仍从上一个周期的结束时间起算，保持计费日期不变
+func (s *Subscription) Renew(plan *Plan, paidAt time.Time) error {
+	switch s.Status {
+	case StatusIncomplete:
+		s.AnchorAt = &paidAt
+		s.Periods = 0
+	case StatusTrialing, StatusActive, StatusPastDue:
+	default:
+		return apierror.NewInvalidStateTransitionError("订阅已取消", fmt.Sprintf("ID: %d", s.ID))
+	}
+
+	start := plan.PeriodEnd(*s.AnchorAt, s.Periods)
+	s.Periods++
+	end := plan.PeriodEnd(*s.AnchorAt, s.Periods)
+	s.Status = StatusActive
+	s.CurrentPeriodStart = &start
+	s.CurrentPeriodEnd = &end
+	s.DunningCount = 0
+	s.LastDunningAt = nil
+	s.ClearPayment()
+	return nil
+}
+
+// NeedsRenewalOrder 是否需要生成续费订单：没有待支付订单、不会在周期结束后取消，且距离周期结束不超过ahead
+func (s *Subscription) NeedsRenewalOrder(now time.Time, ahead time.Duration) bool {
+	switch s.Status {
+	case StatusTrialing, StatusActive:
+		if s.CancelAtPeriodEnd {
+			return false
+		}
+	case StatusPastDue:
+	default:
+		return false
+	}
+	return s.PendingOrderID == 0 && s.CurrentPeriodEnd != nil && !now.Before(s.CurrentPeriodEnd.Add(-ahead))
+}
+
+// GraceEndsAt 宽限期结束时间
+func (s *Subscription) GraceEndsAt(grace time.Duration) time.Time {
+	return s.CurrentPeriodEnd.Add(grace)
+}
+
+// MarkPastDue 周期结束时续费仍未支付，进入宽限期
+func (s *Subscription) MarkPastDue() error {
+	if s.Status != StatusTrialing && s.Status != StatusActive {
+		return apierror.NewInvalidStateTransitionError("不允许的订阅状态变更", fmt.Sprintf("%s -> %s", s.Status, StatusPastDue))
+	}
+	s.Status = StatusPastDue
+	return nil
+}
+
+// DunningDue 宽限期内是否应该发送催缴通知，首次进入宽限期时立即发送，之后至少间隔interval
+func (s *Subscription) DunningDue(now time.Time, interval time.Duration) bool {
+	if s.Status != StatusPastDue {
+		return false
+	}
+	return s.LastDunningAt == nil || !now.Before(s.LastDunningAt.Add(interval))
+}
+
+// RecordDunning 记录一次催缴通知
+func (s *Subscription) RecordDunning(now time.Time) {
+	s.DunningCount++
+	s.LastDunningAt = &now
+}
+
+// ScheduleCancel 设置为当前周期结束后取消，用户仍可以使用到周期结束，取消方和原因在到期取消时使用
+func (s *Subscription) ScheduleCancel(by string, reason string) error {
+	if s.Status != StatusTrialing && s.Status != StatusActive {
+		return apierror.NewInvalidStateTransitionError("只有试用中或生效中的订阅可以到期取消", fmt.Sprintf("状态: %s", s.Status))
+	}
+	s.CancelAtPeriodEnd = true
+	s.CancelledBy = by
+	s.CancelReason = strings.TrimSpace(reason)
+	return nil
+}
+
+// Resume 撤销到期取消，周期结束前可以恢复续费
+func (s *Subscription) Resume(now time.Time) error {
+	if !s.CancelAtPeriodEnd || !s.IsCurrent(now) {
+		return apierror.NewInvalidStateTransitionError("订阅不能恢复", fmt.Sprintf("状态: %s", s.Status))
+	}
+	s.CancelAtPeriodEnd = false
+	s.CancelledBy = ""
+	s.CancelReason = ""
+	return nil
+}
+
+// Cancel 立即取消订阅
+func (s *Subscription) Cancel(by string, reason string, now time.Time) error {
+	if s.IsCancelled() {
+		return apierror.NewInvalidStateTransitionError("订阅已取消", fmt.Sprintf("ID: %d", s.ID))
+	}
+	s.Status = StatusCancelled
+	s.CancelAtPeriodEnd = false
+	s.CancelledAt = &now
+	s.CancelledBy = by
+	s.CancelReason = strings.TrimSpace(reason)
+	return nil
+}
+
+// PlanQuery 订阅计划查询条件
+type PlanQuery struct {
+	ProductID uint   // 为0时不限
+	Status    string // 为空时不限
+}
+
+// SubscriptionQuery 订阅查询条件
+type SubscriptionQuery struct {
+	UserID   uint   // 为0时不限
+	PlanID   uint   // 为0时不限
+	Status   string // 为空时不限
+	Page     int
+	PageSize int
+}
+
+// SubscriptionPaginationResult 订阅分页结果
+type SubscriptionPaginationResult struct {
+	Total         int            `json:"total"`
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// SubscriptionRepository 订阅仓库接口
+type SubscriptionRepository interface {
+	// FindPlanByID 根据ID查询订阅计划
+	FindPlanByID(ctx context.Context, id uint) (*Plan, error)
+
+	// FindPlans 按条件查询订阅计划，按价格排序
+	FindPlans(ctx context.Context, query PlanQuery) ([]Plan, error)
+
+	// CreatePlan 创建订阅计划
+	CreatePlan(ctx context.Context, plan *Plan) error
+
+	// UpdatePlan 更新订阅计划的名称、描述、价格、试用天数和状态
+	UpdatePlan(ctx context.Context, plan *Plan) error
+
+	// FindByID 根据ID查询订阅
+	FindByID(ctx context.Context, id uint) (*Subscription, error)
+
+	// LockByID 在事务中锁定订阅行并返回最新的订阅，用于串行化续费、付款和取消
+	LockByID(ctx context.Context, id uint) (*Subscription, error)
+
+	// FindByUser 查询用户的全部订阅，按创建时间倒序
+	FindByUser(ctx context.Context, userID uint) ([]Subscription, error)
+
+	// FindByPendingOrder 查询等待该订单付款的订阅，不存在时返回nil
+	FindByPendingOrder(ctx context.Context, orderID uint) (*Subscription, error)
+
+	// Find 按条件分页查询订阅，按创建时间倒序
+	Find(ctx context.Context, query SubscriptionQuery) (*SubscriptionPaginationResult, error)
+
+	// FindDue 查询需要续费任务处理的订阅：首期待支付、宽限期内，以及周期在renewBefore之前结束的订阅
+	// 按ID顺序返回ID大于afterID的最多limit条
+	FindDue(ctx context.Context, renewBefore time.Time, afterID uint, limit int) ([]Subscription, error)
+
+	// Create 创建订阅
+	Create(ctx context.Context, subscription *Subscription) error
+
+	// Update 更新订阅的状态、周期、待支付订单、催缴和取消信息
+	Update(ctx context.Context, subscription *Subscription) error
+
+	// Transaction 在事务中执行fn，fn内通过ctx调用的仓库方法共用同一个事务
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// CreatePlanInput 创建订阅计划的输入参数
+type CreatePlanInput struct {
+	ProductID     uint         `json:"product_id" binding:"required"`
+	Name          string       `json:"name" binding:"required,max=100"`
+	Description   string       `json:"description" binding:"max=2000"`
+	Interval      string       `json:"interval" binding:"required,oneof=day week month year"`
+	IntervalCount int          `json:"interval_count" binding:"omitempty,gte=1,lte=365"` // 默认为1
+	Price         common.Money `json:"price" binding:"gt=0"`
+	TrialDays     int          `json:"trial_days" binding:"gte=0,lte=365"`
+}
+
+// UpdatePlanInput 更新订阅计划的输入参数，为空的字段不更新
+// 价格修改从已有订阅的下一次续费开始生效
+type UpdatePlanInput struct {
+	Name        *string       `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string       `json:"description" binding:"omitempty,max=2000"`
+	Price       *common.Money `json:"price" binding:"omitempty,gt=0"`
+	TrialDays   *int          `json:"trial_days" binding:"omitempty,gte=0,lte=365"`
+}
+
+// ChangePlanStatusInput 修改订阅计划状态的输入参数
+type ChangePlanStatusInput struct {
+	Status string `json:"status" binding:"required,oneof=active archived"`
+}
+
+// SubscribeInput 用户订阅的输入参数
+type SubscribeInput struct {
+	PlanID uint `json:"plan_id" binding:"required"`
+}
+
+// CancelInput 取消订阅的输入参数
+type CancelInput struct {
+	Immediately bool   `json:"immediately"` // 为false时试用中或生效中的订阅到当前周期结束后取消
+	Reason      string `json:"reason" binding:"max=500"`
+}
@@ -0,0 +1,195 @@
+package tax
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// AnyCountry 税率中代表其它所有国家的代码，只有没有专门税率的国家才会匹配
+const AnyCountry = "*"
+
+// 计价方式
+const (
+	ModeExclusive = "exclusive" // 标价不含税，税额在结算时加到应付金额
+	ModeInclusive = "inclusive" // 标价已含税，税额从实付金额中拆分出来，不改变应付金额
+)
+
+// 税类，商品可以使用其它自定义税类，只要配置了对应的税率
+const (
+	ClassStandard = "standard" // 商品默认的税类
+	ClassReduced  = "reduced"  // 低税率商品，如图书、食品
+	ClassZero     = "zero"     // 免税商品
+	ClassShipping = "shipping" // 运费使用的税类，不能设置给商品
+)
+
+// rateScale Rate的单位为万分之一
+const rateScale = 10000
+
+// Rate 税率，按国家、地区和税类匹配
+type Rate struct {
+	ID        uint      `json:"id"`
+	Country   string    `json:"country"`          // ISO 3166-1 二字码，"*"表示其它所有国家
+	Region    string    `json:"region,omitempty"` // 省或州，与收货地址的province一致，为空时适用于整个国家
+	TaxClass  string    `json:"tax_class"`
+	Name      string    `json:"name"` // 税种名称，如VAT、GST，显示在订单和发票中
+	Rate      int       `json:"rate"` // 万分比，1300表示13%
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FormatRate 以百分比的形式格式化万分比税率，如 "13%"、"8.25%"
+func FormatRate(rate int) string {
+	s := fmt.Sprintf("%d.%02d", rate/100, rate%100)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	return s + "%"
+}
+
+// Amount 计算金额中的税额，四舍五入到分
+// 标价含税时税额为 amount*rate/(1+rate)，否则为 amount*rate
+func Amount(amount common.Money, rate int, inclusive bool) common.Money {
+	if amount <= 0 || rate <= 0 {
+		return 0
+	}
+	divisor := common.Money(rateScale)
+	if inclusive {
+		divisor += common.Money(rate)
+	}
+	return (amount*common.Money(rate)*2 + divisor) / (divisor * 2)
+}
+
+// Match 从税率表中选出适用的税率，地区匹配优先于整个国家，整个国家优先于"*"
+// 没有适用的税率时返回nil
+func Match(rates []Rate, country, region, taxClass string) *Rate {
+	var best *Rate
+	bestScore := 0
+	for i := range rates {
+		r := &rates[i]
+		if r.TaxClass != taxClass {
+			continue
+		}
+		score := 0
+		switch {
+		case r.Country == country && r.Region != "" && strings.EqualFold(r.Region, region):
+			score = 3
+		case r.Country == country && r.Region == "":
+			score = 2
+		case r.Country == AnyCountry:
+			score = 1
+		}
+		if score > bestScore {
+			best, bestScore = r, score
+		}
+	}
+	return best
+}
+
+// NormalizeClass 税类统一为小写，为空时使用默认税类
+func NormalizeClass(taxClass string) string {
+	taxClass = strings.ToLower(strings.TrimSpace(taxClass))
+	if taxClass == "" {
+		return ClassStandard
+	}
+	return taxClass
+}
+
+// ValidateProductClass 校验商品的税类
+func ValidateProductClass(taxClass string) error {
+	if taxClass == ClassShipping {
+		return apierror.NewValidationError("商品不能使用运费税类", taxClass)
+	}
+	return nil
+}
+
+// Line 需要计税的一行
+type Line struct {
+	Key      uint         // 调用方用来对应计税结果的标识，如商品ID
+	TaxClass string       // 为空时使用默认税类
+	Amount   common.Money // 减去优惠后的实付金额
+}
+
+// Request 计税请求，国家为空时只匹配"*"税率
+type Request struct {
+	Country  string
+	Region   string
+	Lines    []Line
+	Shipping common.Money // 减去运费减免后的运费，按ClassShipping计税
+}
+
+// LineTax 一行的税额
+type LineTax struct {
+	Key    uint         `json:"key"`
+	Name   string       `json:"name,omitempty"` // 没有适用税率时为空
+	Rate   int          `json:"rate"`           // 万分比
+	Amount common.Money `json:"amount"`
+}
+
+// Result 计税结果
+type Result struct {
+	Inclusive bool         `json:"inclusive"`
+	Lines     []LineTax    `json:"lines"` // 与请求中的行一一对应
+	Shipping  LineTax      `json:"shipping"`
+	Total     common.Money `json:"total"`
+}
+
+// Calculator 税费计算接口
+// 默认实现按后台配置的税率表计算，需要时可以替换为第三方税务服务
+type Calculator interface {
+	// Calculate 计算每一行和运费的税额
+	Calculate(ctx context.Context, req Request) (*Result, error)
+}
+
+// RateRepository 税率仓库接口
+type RateRepository interface {
+	// FindAll 查询全部税率，按国家、地区和税类排序
+	FindAll(ctx context.Context) ([]Rate, error)
+
+	// FindByCountry 查询适用于该国家的税率，包括"*"税率
+	FindByCountry(ctx context.Context, country string) ([]Rate, error)
+
+	// FindByID 根据ID查询税率
+	FindByID(ctx context.Context, id uint) (*Rate, error)
+
+	// Create 创建税率
+	Create(ctx context.Context, rate *Rate) error
+
+	// Update 更新税率
+	Update(ctx context.Context, rate *Rate) error
+
+	// Delete 删除税率
+	Delete(ctx context.Context, id uint) error
+}
+
+// RateInput 创建或更新税率的输入参数
+type RateInput struct {
+	Country  string `json:"country" binding:"required,max=2"` // ISO 3166-1 二字码或"*"
+	Region   string `json:"region" binding:"max=50"`
+	TaxClass string `json:"tax_class" binding:"max=30"` // 为空时为standard
+	Name     string `json:"name" binding:"required,max=50"`
+	Rate     int    `json:"rate" binding:"gte=0,lte=10000"` // 万分比
+}
+
+// Normalize 去除首尾空白，国家代码统一为大写，税类统一为小写
+func (in *RateInput) Normalize() {
+	in.Country = strings.ToUpper(strings.TrimSpace(in.Country))
+	in.Region = strings.TrimSpace(in.Region)
+	in.TaxClass = NormalizeClass(in.TaxClass)
+	in.Name = strings.TrimSpace(in.Name)
+}
+
+// Validate 校验国家代码，"*"税率不能限定地区
+func (in *RateInput) Validate() error {
+	if in.Country == AnyCountry {
+		if in.Region != "" {
+			return apierror.NewValidationError("其它国家的税率不能指定地区", in.Region)
+		}
+		return nil
+	}
+	if len(in.Country) != 2 || strings.Trim(in.Country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return apierror.NewValidationError("无效的国家代码", in.Country)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package tax
+
+import (
+	"testing"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    common.Money
+		rate      int
+		inclusive bool
+		want      common.Money
+	}{
+		{name: "不含税", amount: 10000, rate: 1300, want: 1300},
+		{name: "含税", amount: 11300, rate: 1300, inclusive: true, want: 1300},
+		{name: "不含税82.4175舍为82", amount: 999, rate: 825, want: 82},
+		{name: "不含税0.5进为1", amount: 1, rate: 5000, want: 1},
+		{name: "含税90.909进为91", amount: 1000, rate: 1000, inclusive: true, want: 91},
+		{name: "含税275.2舍为275", amount: 3333, rate: 900, inclusive: true, want: 275},
+		{name: "含税10.5进为11", amount: 21, rate: 10000, inclusive: true, want: 11},
+		{name: "同额不含税为100", amount: 1000, rate: 1000, want: 100},
+		{name: "零税率", amount: 10000, rate: 0, want: 0},
+		{name: "零金额", amount: 0, rate: 1300, want: 0},
+		{name: "负金额", amount: -500, rate: 1300, inclusive: true, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Amount(tt.amount, tt.rate, tt.inclusive); got != tt.want {
+				t.Errorf("Amount(%s, %d, %v) = %s, want %s", tt.amount, tt.rate, tt.inclusive, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rates := []Rate{
+		{ID: 1, Country: AnyCountry, TaxClass: ClassStandard, Rate: 2000},
+		{ID: 2, Country: "CN", TaxClass: ClassStandard, Rate: 1300},
+		{ID: 3, Country: "CN", TaxClass: ClassReduced, Rate: 900},
+		{ID: 4, Country: "US", Region: "CA", TaxClass: ClassStandard, Rate: 725},
+	}
+
+	tests := []struct {
+		name     string
+		country  string
+		region   string
+		taxClass string
+		want     uint // 0表示没有适用的税率
+	}{
+		{name: "整个国家", country: "CN", region: "北京市", taxClass: ClassStandard, want: 2},
+		{name: "按税类匹配", country: "CN", taxClass: ClassReduced, want: 3},
+		{name: "地区优先于国家和其它国家", country: "US", region: "ca", taxClass: ClassStandard, want: 4},
+		{name: "其它地区使用其它国家税率", country: "US", region: "NY", taxClass: ClassStandard, want: 1},
+		{name: "没有国家时只匹配其它国家", taxClass: ClassStandard, want: 1},
+		{name: "没有配置的税类", country: "JP", taxClass: ClassZero},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got uint
+			if rate := Match(rates, tt.country, tt.region, tt.taxClass); rate != nil {
+				got = rate.ID
+			}
+			if got != tt.want {
+				t.Errorf("Match() = rate %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
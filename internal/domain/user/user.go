@@ -19,8 +19,9 @@ type User struct {
 
 // UserType 用户类型常量
 const (
-	UserTypeRegular = "regular" // 普通用户
-	UserTypeAdmin   = "admin"   // 管理员
+	UserTypeRegular  = "regular"  // 普通用户
+	UserTypeAdmin    = "admin"    // 管理员
+	UserTypeMerchant = "merchant" // 入驻商家的所有者，商家申请审核通过后设置
 )
 
 // UserRepository 用户仓库接口
@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"context"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+)
+
+// 余额流水类型
+const (
+	EntryTypeRefund             = "refund"              // 订单退款到余额
+	EntryTypeWithdrawal         = "withdrawal"          // 提现扣减
+	EntryTypeWithdrawalReversal = "withdrawal_reversal" // 提现失败退回余额
+	EntryTypeSettlement         = "settlement"          // 商家子订单结算入账，已扣除平台佣金
+	EntryTypeSettlementReversal = "settlement_reversal" // 已结算的子订单发生退款，冲回结算金额
+	EntryTypeGiftCard           = "gift_card"           // 礼品卡兑换到购物金
+	EntryTypeOrderPayment       = "order_payment"       // 下单时用余额抵扣订单金额
+	EntryTypeOrderPaymentReturn = "payment_return"      // 订单取消或超时关闭，退回抵扣的余额
+)
+
+// 账户中的资金类型
+const (
+	FundsBalance     = "balance"      // 站内余额，可以提现
+	FundsStoreCredit = "store_credit" // 礼品卡兑换的购物金，只能下单抵扣，不能提现或转账退款
+)
+
+// 余额流水关联的业务类型
+const (
+	RefTypeRefund     = "refund"
+	RefTypeWithdrawal = "withdrawal"
+	RefTypeSubOrder   = "sub_order"
+	RefTypeGiftCard   = "gift_card"
+	RefTypeOrder      = "order"
+)
+
+// Account 用户的站内余额账户
+type Account struct {
+	UserID        uint         `json:"user_id"`
+	Balance       common.Money `json:"balance"`
+	StoreCredit   common.Money `json:"store_credit"`             // 礼品卡兑换的购物金
+	PayoutAddress string       `json:"payout_address,omitempty"` // 提现和退款转账的收款地址
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// Entry 余额流水，金额为正表示入账，为负表示出账
+type Entry struct {
+	ID           uint         `json:"id"`
+	UserID       uint         `json:"user_id"`
+	Funds        string       `json:"funds"` // 变更的资金类型，为空时视为站内余额
+	Type         string       `json:"type"`
+	Amount       common.Money `json:"amount"`
+	BalanceAfter common.Money `json:"balance_after"` // 变更后该资金类型的余额
+	RefType      string       `json:"ref_type,omitempty"`
+	RefID        uint         `json:"ref_id,omitempty"`
+	Note         string       `json:"note,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// EntryPaginationResult 余额流水分页结果
+type EntryPaginationResult struct {
+	Total   int     `json:"total"`
+	Entries []Entry `json:"entries"`
+}
+
+// WalletRepository 余额仓库接口
+type WalletRepository interface {
+	// FindAccount 查询用户的余额账户，不存在时返回余额为0的账户
+	FindAccount(ctx context.Context, userID uint) (*Account, error)
+
+	// SetPayoutAddress 设置收款地址，账户不存在时创建
+	SetPayoutAddress(ctx context.Context, userID uint, address string) error
+
+	// AddEntry 按entry.Funds原子地变更余额或购物金并记录流水，扣减后不能为负，entry的BalanceAfter会被填充
+	AddEntry(ctx context.Context, entry *Entry) error
+
+	// FindEntries 按时间倒序分页查询用户的余额流水
+	FindEntries(ctx context.Context, userID uint, page, pageSize int) (*EntryPaginationResult, error)
+
+	// CreateWithdrawal 创建提现单
+	CreateWithdrawal(ctx context.Context, withdrawal *Withdrawal) error
+
+	// FindWithdrawalByID 根据ID查询提现单
+	FindWithdrawalByID(ctx context.Context, id uint) (*Withdrawal, error)
+
+	// FindWithdrawals 按条件分页查询提现单，按创建时间倒序
+	FindWithdrawals(ctx context.Context, query WithdrawalQuery) (*WithdrawalPaginationResult, error)
+
+	// UpdateWithdrawal 仅当提现单仍待处理时写入处理结果，返回是否更新成功
+	UpdateWithdrawal(ctx context.Context, withdrawal *Withdrawal) (bool, error)
+
+	// Transaction 在事务中执行fn，fn内通过ctx调用的仓库方法共用同一个事务
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// PayoutAddressInput 设置收款地址的输入参数
+type PayoutAddressInput struct {
+	Address string `json:"address" binding:"required,eth_addr"`
+}
@@ -0,0 +1,94 @@
+package wallet
+
+import (
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// 提现单状态
+const (
+	WithdrawalStatusPending   = "pending"   // 等待管理员转账
+	WithdrawalStatusCompleted = "completed" // 已完成链上转账
+	WithdrawalStatusFailed    = "failed"    // 转账失败，金额已退回余额
+)
+
+// 提现单来源
+const (
+	WithdrawalSourceBalance = "balance" // 用户从余额发起提现
+	WithdrawalSourceRefund  = "refund"  // 订单退款直接转账，不经过余额
+)
+
+// 管理员处理提现单的方式
+const (
+	WithdrawalActionComplete = "complete"
+	WithdrawalActionFail     = "fail"
+)
+
+// Withdrawal 提现单，由管理员完成链上代币转账后记录交易哈希
+type Withdrawal struct {
+	ID            uint         `json:"id"`
+	UserID        uint         `json:"user_id"`
+	Amount        common.Money `json:"amount"`
+	ToAddress     string       `json:"to_address"`
+	Source        string       `json:"source"`
+	RefID         uint         `json:"ref_id,omitempty"` // 来源为退款时为退款单ID
+	Status        string       `json:"status"`
+	TxHash        string       `json:"tx_hash,omitempty"`
+	FailureReason string       `json:"failure_reason,omitempty"`
+	ProcessedBy   uint         `json:"processed_by,omitempty"` // 处理的管理员ID
+	ProcessedAt   *time.Time   `json:"processed_at,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// Complete 记录链上转账完成
+func (w *Withdrawal) Complete(txHash string, adminID uint, now time.Time) error {
+	if w.Status != WithdrawalStatusPending {
+		return apierror.NewInvalidStateTransitionError("提现单已处理", fmt.Sprintf("ID: %d, 状态: %s", w.ID, w.Status))
+	}
+	w.Status = WithdrawalStatusCompleted
+	w.TxHash = txHash
+	w.ProcessedBy = adminID
+	w.ProcessedAt = &now
+	return nil
+}
+
+// Fail 记录转账失败
+func (w *Withdrawal) Fail(reason string, adminID uint, now time.Time) error {
+	if w.Status != WithdrawalStatusPending {
+		return apierror.NewInvalidStateTransitionError("提现单已处理", fmt.Sprintf("ID: %d, 状态: %s", w.ID, w.Status))
+	}
+	w.Status = WithdrawalStatusFailed
+	w.FailureReason = reason
+	w.ProcessedBy = adminID
+	w.ProcessedAt = &now
+	return nil
+}
+
+// WithdrawalQuery 提现单查询条件
+type WithdrawalQuery struct {
+	UserID   uint
+	Status   string
+	Page     int
+	PageSize int
+}
+
+// WithdrawalPaginationResult 提现单分页结果
+type WithdrawalPaginationResult struct {
+	Total       int          `json:"total"`
+	Withdrawals []Withdrawal `json:"withdrawals"`
+}
+
+// WithdrawInput 用户从余额提现的输入参数，收款地址为空时使用账户的收款地址
+type WithdrawInput struct {
+	Amount    common.Money `json:"amount" binding:"required,gt=0"`
+	ToAddress string       `json:"to_address" binding:"omitempty,eth_addr"`
+}
+
+// ProcessWithdrawalInput 管理员处理提现单的输入参数
+type ProcessWithdrawalInput struct {
+	Action string `json:"action" binding:"required,oneof=complete fail"`
+	TxHash string `json:"tx_hash" binding:"required_if=Action complete,max=100"`
+	Reason string `json:"reason" binding:"required_if=Action fail,max=500"`
+}
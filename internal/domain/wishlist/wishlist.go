@@ -0,0 +1,53 @@
+package wishlist
+
+import (
+	"context"
+	"time"
+	"web3-ecommerce-app/internal/domain/product"
+)
+
+// Item 心愿单中的商品
+type Item struct {
+	ProductID uint             `json:"product_id"`
+	Product   *product.Product `json:"product,omitempty"` // 商品已删除时为空
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// StockAlert 到货提醒订阅
+// 每次到货最多通知一次，通知后订阅保留，商品再次售罄并到货时继续通知
+type StockAlert struct {
+	ProductID  uint             `json:"product_id"`
+	Product    *product.Product `json:"product,omitempty"`
+	NotifiedAt *time.Time       `json:"notified_at,omitempty"` // 最近一次通知时间
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// WishlistRepository 心愿单仓库接口
+type WishlistRepository interface {
+	// FindItems 按加入时间倒序查询用户的心愿单
+	FindItems(ctx context.Context, userID uint) ([]Item, error)
+
+	// AddItem 加入心愿单，已存在时不报错
+	AddItem(ctx context.Context, userID uint, productID uint) error
+
+	// RemoveItem 从心愿单移除，返回是否存在
+	RemoveItem(ctx context.Context, userID uint, productID uint) (bool, error)
+
+	// FindAlerts 按订阅时间倒序查询用户的到货提醒
+	FindAlerts(ctx context.Context, userID uint) ([]StockAlert, error)
+
+	// AddAlert 订阅到货提醒，已存在时不报错
+	AddAlert(ctx context.Context, userID uint, productID uint) error
+
+	// RemoveAlert 取消到货提醒，返回是否存在
+	RemoveAlert(ctx context.Context, userID uint, productID uint) (bool, error)
+
+	// ClaimAlerts 将商品需要通知的订阅标记为已通知并返回对应用户ID
+	// 在restockedAt之后已通知过的订阅不会被选中，重复处理同一次到货时不会重复通知
+	ClaimAlerts(ctx context.Context, productID uint, now time.Time, restockedAt time.Time) ([]uint, error)
+}
+
+// AddItemInput 加入心愿单或订阅到货提醒的输入参数
+type AddItemInput struct {
+	ProductID uint `json:"product_id" binding:"required"`
+}
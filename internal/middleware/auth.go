@@ -33,52 +33,79 @@ func JWT(jwtConfig *config.JWTConfig) gin.HandlerFunc {
 			return
 		}
 
-		// 检查Bearer前缀
-		parts := strings.SplitN(authHeader, " ", 2)
-		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": apierror.NewUnauthorizedError("认证格式错误", "Authorization头必须是Bearer格式"),
-			})
+		if authenticate(c, authHeader, jwtConfig) {
+			c.Next()
+		}
+	}
+}
+
+// OptionalJWT 可选认证中间件，游客也能访问的接口使用
+// 没有Authorization头时按游客处理，提供了token则必须有效
+func OptionalJWT(jwtConfig *config.JWTConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.Next()
 			return
 		}
 
-		// 解析token
-		tokenString := parts[1]
-		claims := &JWTClaims{}
+		if authenticate(c, authHeader, jwtConfig) {
+			c.Next()
+		}
+	}
+}
 
-		// 手动解析token，不检查过期时间
-		token, _ := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtConfig.Secret), nil
+// authenticate 验证token并将用户信息存入上下文，失败时写入错误响应并返回false
+func authenticate(c *gin.Context, authHeader string, jwtConfig *config.JWTConfig) bool {
+	// 检查Bearer前缀
+	parts := strings.SplitN(authHeader, " ", 2)
+	if !(len(parts) == 2 && parts[0] == "Bearer") {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("认证格式错误", "Authorization头必须是Bearer格式"),
 		})
+		return false
+	}
 
-		// 检查签名是否有效
-		if token == nil || !token.Valid && claims.ExpiresAt == 0 {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": apierror.NewUnauthorizedError("无效的token", "签名验证失败"),
-			})
-			return
+	// 解析token
+	tokenString := parts[1]
+	claims := &JWTClaims{}
+
+	// 跳过载荷校验，过期时间在下面按宽限期单独检查；签名和算法仍然必须有效
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
+		return []byte(jwtConfig.Secret), nil
+	})
 
-		// 添加5分钟的时间宽容度
-		now := time.Now().Unix()
-		gracePeriod := int64(300) // 5分钟 (秒)
+	// 检查签名是否有效
+	if err != nil || !token.Valid {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("无效的token", "签名验证失败"),
+		})
+		return false
+	}
 
-		if claims.ExpiresAt < now-gracePeriod {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": apierror.NewUnauthorizedError("无效的token", fmt.Sprintf("token已超过宽限期过期")),
-			})
-			return
-		}
+	// 添加5分钟的时间宽容度
+	now := time.Now().Unix()
+	gracePeriod := int64(300) // 5分钟 (秒)
 
-		// 将claims存入上下文
-		c.Set("claims", claims)
-		c.Set("user_id", claims.UserID)
-		c.Set("user_type", claims.UserType)
-		if claims.WalletAddr != "" {
-			c.Set("wallet_addr", claims.WalletAddr)
-		}
-		c.Next()
+	if claims.ExpiresAt < now-gracePeriod {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("无效的token", fmt.Sprintf("token已超过宽限期过期")),
+		})
+		return false
+	}
+
+	// 将claims存入上下文
+	c.Set("claims", claims)
+	c.Set("user_id", claims.UserID)
+	c.Set("user_type", claims.UserType)
+	if claims.WalletAddr != "" {
+		c.Set("wallet_addr", claims.WalletAddr)
 	}
+	return true
 }
 
 // GenerateJWT 生成JWT token
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"web3-ecommerce-app/internal/config"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtConfig := &config.JWTConfig{Secret: "secret", ExpireHours: 1}
+
+	sign := func(secret string, expiresAt int64) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, JWTClaims{
+			UserID:         7,
+			UserType:       "customer",
+			StandardClaims: jwt.StandardClaims{ExpiresAt: expiresAt},
+		})
+		signed, err := token.SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"有效token", sign("secret", now.Add(time.Hour).Unix()), true},
+		{"宽限期内过期", sign("secret", now.Add(-time.Minute).Unix()), true},
+		{"超过宽限期", sign("secret", now.Add(-time.Hour).Unix()), false},
+		{"签名错误且带过期时间", sign("other", now.Add(time.Hour).Unix()), false},
+		{"签名错误且无过期时间", sign("other", 0), false},
+		{"alg为none", func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodNone, JWTClaims{
+				UserID:         7,
+				StandardClaims: jwt.StandardClaims{ExpiresAt: now.Add(time.Hour).Unix()},
+			})
+			signed, _ := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return signed
+		}(), false},
+		{"格式错误", "not-a-token", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			if got := authenticate(c, "Bearer "+tt.token, jwtConfig); got != tt.ok {
+				t.Fatalf("authenticate() = %v, want %v", got, tt.ok)
+			}
+			if tt.ok {
+				if userID, _ := c.Get("user_id"); userID != uint(7) {
+					t.Fatalf("user_id = %v, want 7", userID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/platform/idempotency"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader 客户端传入幂等键的请求头
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 响应为重放的第一次请求结果时设置的响应头
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength 幂等键的最大长度
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodySize 带幂等键的请求体需要读入内存计算摘要，限制其大小
+	maxIdempotentBodySize = 1 << 20
+	// defaultIdempotencyLockTTL 未配置lock_ttl时处理中记录的锁定时长
+	defaultIdempotencyLockTTL = time.Minute
+	// idempotencyCompleteAttempts 保存响应失败时的尝试次数
+	idempotencyCompleteAttempts = 3
+)
+
+// idempotencyRetryDelay 两次保存响应之间的等待时间，测试中可以调小
+var idempotencyRetryDelay = 100 * time.Millisecond
+
+// Idempotency 幂等键中间件，需要在JWT之后使用
+// 写请求带有Idempotency-Key头时，同一用户用同一个key重试直接返回第一次请求的响应，
+// key相同但请求内容不同时返回409。第一次请求返回5xx时不保存结果，客户端可以用同一个key重试。
+// 没有该请求头、只读请求、未登录的请求和multipart文件上传不做处理。
+// 处理中的记录只锁定lock_ttl，响应保存后才延长到ttl，进程崩溃后客户端不必等待整个ttl才能重试；
+// 每个请求用随机令牌预留记录，处理超过lock_ttl后被重试请求重新预留时，两者不会覆盖或删除对方的记录
+func Idempotency(store idempotency.Store, cfg *config.IdempotencyConfig) gin.HandlerFunc {
+	ttl := cfg.TTL
+	lockTTL := cfg.LockTTL
+	if lockTTL <= 0 {
+		lockTTL = defaultIdempotencyLockTTL
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		userID, exists := c.Get("user_id")
+		if key == "" || !exists || isReadOnlyMethod(c.Request.Method) || isMultipart(c.Request) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": apierror.NewBadRequestError("无效的幂等键", fmt.Sprintf("%s最长%d个字符", IdempotencyKeyHeader, maxIdempotencyKeyLength)),
+			})
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": apierror.NewBadRequestError("读取请求失败", err.Error()),
+			})
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": apierror.NewBadRequestError("请求体过大", fmt.Sprintf("带%s的请求体最大%d字节", IdempotencyKeyHeader, maxIdempotentBodySize)),
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := fmt.Sprintf("idempotency:%d:%s", userID.(uint), key)
+		fingerprint := requestFingerprint(c.Request, body)
+		ctx := context.WithoutCancel(c.Request.Context())
+		owner, err := newOwnerToken()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+			})
+			return
+		}
+
+		existing, err := store.Reserve(ctx, storeKey, fingerprint, owner, lockTTL)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+			})
+			return
+		}
+		if existing != nil {
+			replay(c, existing, fingerprint)
+			return
+		}
+
+		release := func() {
+			if err := store.Release(ctx, storeKey, fingerprint, owner); err != nil {
+				log.Printf("释放幂等键 %s 失败: %v", storeKey, err)
+			}
+		}
+
+		// 处理过程中panic时释放key，使客户端可以重试
+		recorder := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		finished := false
+		defer func() {
+			if !finished {
+				release()
+			}
+		}()
+
+		c.Next()
+		finished = true
+
+		// 返回5xx时释放key，其它响应已经生效，不能再让重试重新执行
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			release()
+			return
+		}
+		record := &idempotency.Record{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := completeRecord(ctx, store, storeKey, owner, record, ttl); err != nil {
+			// 保留处理中的记录，lock_ttl内的重试返回409
+			log.Printf("保存幂等键 %s 的响应失败: %v", storeKey, err)
+		}
+	}
+}
+
+// completeRecord 保存响应，存储暂时不可用时重试；记录已不属于当前请求时放弃
+func completeRecord(ctx context.Context, store idempotency.Store, key string, owner string, record *idempotency.Record, ttl time.Duration) error {
+	var err error
+	for attempt := 0; attempt < idempotencyCompleteAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(idempotencyRetryDelay)
+		}
+		err = store.Complete(ctx, key, owner, record, ttl)
+		if err == nil || errors.Is(err, idempotency.ErrNotOwner) {
+			return err
+		}
+	}
+	return err
+}
+
+// newOwnerToken 生成预留幂等记录的随机令牌
+func newOwnerToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成幂等令牌失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// replay 处理重复的幂等键：内容一致且已完成时重放响应，否则返回409
+func replay(c *gin.Context, record *idempotency.Record, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"error": apierror.NewIdempotencyConflictError("幂等键已用于其他请求", "同一个Idempotency-Key只能用于内容相同的请求"),
+		})
+		return
+	}
+	if !record.Completed {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"error": apierror.NewIdempotencyConflictError("请求正在处理中", "请稍后使用同一个Idempotency-Key重试"),
+		})
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(record.Status, record.ContentType, record.Body)
+	c.Abort()
+}
+
+// requestFingerprint 计算请求方法、路径、查询参数和请求体的摘要
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method)
+	h.Write([]byte{0})
+	io.WriteString(h, r.URL.RequestURI())
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isMultipart 是否为multipart请求
+// 文件上传的请求体可能很大，不适合读入内存计算摘要，因此不做幂等处理
+func isMultipart(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/")
+}
+
+// isReadOnlyMethod 是否为不修改数据的请求方法
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// recordingWriter 在写出响应的同时保存响应体
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write 写出并保存响应体
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写出并保存响应体
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
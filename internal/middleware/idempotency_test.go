@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/platform/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ttlStore 记录每次调用使用的过期时长
+type ttlStore struct {
+	*idempotency.MemoryStore
+	reserveTTL  time.Duration
+	completeTTL time.Duration
+}
+
+func (s *ttlStore) Reserve(ctx context.Context, key string, fingerprint string, owner string, ttl time.Duration) (*idempotency.Record, error) {
+	s.reserveTTL = ttl
+	return s.MemoryStore.Reserve(ctx, key, fingerprint, owner, ttl)
+}
+
+func (s *ttlStore) Complete(ctx context.Context, key string, owner string, record *idempotency.Record, ttl time.Duration) error {
+	s.completeTTL = ttl
+	return s.MemoryStore.Complete(ctx, key, owner, record, ttl)
+}
+
+// failingCompleteStore 保存响应总是失败
+type failingCompleteStore struct {
+	*idempotency.MemoryStore
+	attempts int
+}
+
+func (s *failingCompleteStore) Complete(ctx context.Context, key string, owner string, record *idempotency.Record, ttl time.Duration) error {
+	s.attempts++
+	return errors.New("connection reset")
+}
+
+func newIdempotentRouter(store idempotency.Store, cfg *config.IdempotencyConfig, calls *int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", uint(1)) })
+	router.Use(Idempotency(store, cfg))
+	router.POST("/orders", func(c *gin.Context) {
+		n := atomic.AddInt64(calls, 1)
+		c.JSON(http.StatusCreated, gin.H{"call": n})
+	})
+	return router
+}
+
+func sendIdempotent(router *gin.Engine, key string, contentType string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplayAndConflict(t *testing.T) {
+	store := &ttlStore{MemoryStore: idempotency.NewMemoryStore()}
+	var calls int64
+	router := newIdempotentRouter(store, &config.IdempotencyConfig{TTL: 24 * time.Hour, LockTTL: 30 * time.Second}, &calls)
+
+	first := sendIdempotent(router, "k1", "application/json", `{"a":1}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d", first.Code)
+	}
+	// 处理中的记录只锁定lock_ttl，保存响应后延长到ttl
+	if store.reserveTTL != 30*time.Second || store.completeTTL != 24*time.Hour {
+		t.Fatalf("reserve ttl = %v, complete ttl = %v", store.reserveTTL, store.completeTTL)
+	}
+
+	replayed := sendIdempotent(router, "k1", "application/json", `{"a":1}`)
+	if replayed.Code != http.StatusCreated || replayed.Body.String() != first.Body.String() ||
+		replayed.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("replay = %d %q", replayed.Code, replayed.Body.String())
+	}
+
+	conflict := sendIdempotent(router, "k1", "application/json", `{"a":2}`)
+	if conflict.Code != http.StatusConflict {
+		t.Fatalf("conflict status = %d", conflict.Code)
+	}
+	if calls != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls)
+	}
+}
+
+func TestIdempotencyDefaultLockTTL(t *testing.T) {
+	store := &ttlStore{MemoryStore: idempotency.NewMemoryStore()}
+	var calls int64
+	router := newIdempotentRouter(store, &config.IdempotencyConfig{TTL: 24 * time.Hour}, &calls)
+
+	sendIdempotent(router, "k1", "application/json", `{}`)
+	if store.reserveTTL != defaultIdempotencyLockTTL {
+		t.Fatalf("reserve ttl = %v, want %v", store.reserveTTL, defaultIdempotencyLockTTL)
+	}
+}
+
+func TestIdempotencySkipsMultipartAndRejectsLargeBody(t *testing.T) {
+	store := &ttlStore{MemoryStore: idempotency.NewMemoryStore()}
+	var calls int64
+	router := newIdempotentRouter(store, &config.IdempotencyConfig{TTL: time.Hour}, &calls)
+
+	// multipart上传不读入内存，也不保存记录
+	for i := 0; i < 2; i++ {
+		w := sendIdempotent(router, "upload", "multipart/form-data; boundary=x", "--x--")
+		if w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Fatalf("multipart status = %d", w.Code)
+		}
+	}
+	if calls != 2 || store.reserveTTL != 0 {
+		t.Fatalf("multipart calls = %d, reserve ttl = %v", calls, store.reserveTTL)
+	}
+
+	w := sendIdempotent(router, "big", "application/json", strings.Repeat("x", maxIdempotentBodySize+1))
+	if w.Code != http.StatusRequestEntityTooLarge || calls != 2 {
+		t.Fatalf("large body status = %d, calls = %d", w.Code, calls)
+	}
+}
+
+func TestIdempotencyKeepsKeyWhenCompleteFails(t *testing.T) {
+	prev := idempotencyRetryDelay
+	idempotencyRetryDelay = time.Millisecond
+	t.Cleanup(func() { idempotencyRetryDelay = prev })
+
+	store := &failingCompleteStore{MemoryStore: idempotency.NewMemoryStore()}
+	var calls int64
+	router := newIdempotentRouter(store, &config.IdempotencyConfig{TTL: time.Hour, LockTTL: time.Minute}, &calls)
+
+	first := sendIdempotent(router, "k1", "application/json", `{}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d", first.Code)
+	}
+	if store.attempts != idempotencyCompleteAttempts {
+		t.Fatalf("complete attempts = %d, want %d", store.attempts, idempotencyCompleteAttempts)
+	}
+
+	// 第一次请求已经生效，保存响应失败时不能释放key让重试再执行一次
+	retry := sendIdempotent(router, "k1", "application/json", `{}`)
+	if retry.Code != http.StatusConflict || calls != 1 {
+		t.Fatalf("retry status = %d, handler calls = %d, want 409 and 1", retry.Code, calls)
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"web3-ecommerce-app/internal/domain/user"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MerchantRequired 验证用户是否为商家
+// 用户类型记录在JWT中，入驻申请审核通过后需要重新登录才能进入商家后台
+func MerchantRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 从上下文中获取用户类型
+		userType, exists := c.Get("user_type")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+			})
+			return
+		}
+
+		// 验证用户是否为商家
+		if userType.(string) != user.UserTypeMerchant {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": apierror.NewForbiddenError("权限不足", "需要商家权限，入驻申请审核通过后请重新登录"),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
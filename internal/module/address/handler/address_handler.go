@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"web3-ecommerce-app/internal/domain/address"
+	"web3-ecommerce-app/internal/module/address/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddressHTTPHandler 地址簿HTTP处理器
+type AddressHTTPHandler struct {
+	addressService service.AddressService
+}
+
+// NewAddressHTTPHandler 创建地址簿HTTP处理器
+func NewAddressHTTPHandler(addressService service.AddressService) *AddressHTTPHandler {
+	return &AddressHTTPHandler{
+		addressService: addressService,
+	}
+}
+
+// ListAddresses 获取当前用户的地址列表
+func (h *AddressHTTPHandler) ListAddresses(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	addresses, err := h.addressService.ListAddresses(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"addresses": addresses})
+}
+
+// GetAddress 获取当前用户的某个地址
+func (h *AddressHTTPHandler) GetAddress(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getAddressID(c)
+	if !ok {
+		return
+	}
+
+	addressEntity, err := h.addressService.GetAddress(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, addressEntity)
+}
+
+// CreateAddress 新增地址
+func (h *AddressHTTPHandler) CreateAddress(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var input address.AddressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	addressEntity, err := h.addressService.CreateAddress(c.Request.Context(), userID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, addressEntity)
+}
+
+// UpdateAddress 修改地址
+func (h *AddressHTTPHandler) UpdateAddress(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getAddressID(c)
+	if !ok {
+		return
+	}
+
+	var input address.AddressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	addressEntity, err := h.addressService.UpdateAddress(c.Request.Context(), userID, id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, addressEntity)
+}
+
+// DeleteAddress 删除地址
+func (h *AddressHTTPHandler) DeleteAddress(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getAddressID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.addressService.DeleteAddress(c.Request.Context(), userID, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "地址已删除"})
+}
+
+// SetDefaultAddress 设为默认地址
+func (h *AddressHTTPHandler) SetDefaultAddress(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getAddressID(c)
+	if !ok {
+		return
+	}
+
+	addressEntity, err := h.addressService.SetDefaultAddress(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, addressEntity)
+}
+
+// getAddressID 解析路径中的地址ID，失败时直接写入错误响应
+func (h *AddressHTTPHandler) getAddressID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的地址ID", err.Error()),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// getUserID 获取当前登录用户ID，失败时直接写入错误响应
+func (h *AddressHTTPHandler) getUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// handleError 处理错误
+func (h *AddressHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/address"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+)
+
+// AddressModel 是GORM收货地址模型
+type AddressModel struct {
+	ID         uint   `gorm:"primarykey"`
+	UserID     uint   `gorm:"not null;index:idx_user_id"`
+	Label      string `gorm:"type:varchar(30)"`
+	IsDefault  bool   `gorm:"not null;default:false"`
+	Recipient  string `gorm:"type:varchar(50);not null"`
+	Phone      string `gorm:"type:varchar(20);not null"`
+	Country    string `gorm:"type:char(2);not null"`
+	Province   string `gorm:"type:varchar(50)"`
+	City       string `gorm:"type:varchar(50);not null"`
+	District   string `gorm:"type:varchar(50)"`
+	Line1      string `gorm:"type:varchar(200);not null"`
+	Line2      string `gorm:"type:varchar(200)"`
+	PostalCode string `gorm:"type:varchar(20)"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName 指定表名
+func (AddressModel) TableName() string {
+	return "user_addresses"
+}
+
+// GormAddressRepository 是地址簿仓库的GORM实现
+type GormAddressRepository struct {
+	db *gorm.DB
+}
+
+// NewGormAddressRepository 创建一个新的GORM地址簿仓库
+func NewGormAddressRepository(db *gorm.DB) address.AddressRepository {
+	return &GormAddressRepository{db: db}
+}
+
+// addressToModel 将领域模型转换为GORM模型
+func addressToModel(a *address.Address) *AddressModel {
+	return &AddressModel{
+		ID:         a.ID,
+		UserID:     a.UserID,
+		Label:      a.Label,
+		IsDefault:  a.IsDefault,
+		Recipient:  a.Recipient,
+		Phone:      a.Phone,
+		Country:    a.Country,
+		Province:   a.Province,
+		City:       a.City,
+		District:   a.District,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		PostalCode: a.PostalCode,
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
+	}
+}
+
+// addressToDomain 将GORM模型转换为领域模型
+func addressToDomain(m *AddressModel) *address.Address {
+	return &address.Address{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Label:     m.Label,
+		IsDefault: m.IsDefault,
+		Address: order.Address{
+			Recipient:  m.Recipient,
+			Phone:      m.Phone,
+			Country:    m.Country,
+			Province:   m.Province,
+			City:       m.City,
+			District:   m.District,
+			Line1:      m.Line1,
+			Line2:      m.Line2,
+			PostalCode: m.PostalCode,
+		},
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// FindByUser 查询用户的全部地址
+func (r *GormAddressRepository) FindByUser(ctx context.Context, userID uint) ([]address.Address, error) {
+	var models []AddressModel
+	if err := database.Conn(ctx, r.db).Where("user_id = ?", userID).
+		Order("is_default DESC, id DESC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询地址列表错误: %w", err)
+	}
+
+	addresses := make([]address.Address, 0, len(models))
+	for i := range models {
+		addresses = append(addresses, *addressToDomain(&models[i]))
+	}
+	return addresses, nil
+}
+
+// FindByID 查询用户的某个地址
+func (r *GormAddressRepository) FindByID(ctx context.Context, userID uint, id uint) (*address.Address, error) {
+	var model AddressModel
+	if err := database.Conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("地址不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询地址错误: %w", err)
+	}
+	return addressToDomain(&model), nil
+}
+
+// FindDefault 查询用户的默认地址
+func (r *GormAddressRepository) FindDefault(ctx context.Context, userID uint) (*address.Address, error) {
+	var models []AddressModel
+	if err := database.Conn(ctx, r.db).Where("user_id = ? AND is_default = ?", userID, true).
+		Limit(1).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询默认地址错误: %w", err)
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+	return addressToDomain(&models[0]), nil
+}
+
+// Count 统计用户的地址数量
+func (r *GormAddressRepository) Count(ctx context.Context, userID uint) (int, error) {
+	var count int64
+	if err := database.Conn(ctx, r.db).Model(&AddressModel{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("统计地址数量错误: %w", err)
+	}
+	return int(count), nil
+}
+
+// Create 创建地址
+func (r *GormAddressRepository) Create(ctx context.Context, a *address.Address) error {
+	model := addressToModel(a)
+	err := database.Transaction(ctx, r.db, func(ctx context.Context) error {
+		tx := database.Conn(ctx, r.db)
+		if model.IsDefault {
+			if err := tx.Model(&AddressModel{}).Where("user_id = ? AND is_default = ?", a.UserID, true).
+				Update("is_default", false).Error; err != nil {
+				return fmt.Errorf("取消默认地址错误: %w", err)
+			}
+		}
+		if err := tx.Create(model).Error; err != nil {
+			return fmt.Errorf("创建地址错误: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*a = *addressToDomain(model)
+	return nil
+}
+
+// Update 更新地址内容和名称
+func (r *GormAddressRepository) Update(ctx context.Context, a *address.Address) error {
+	model := addressToModel(a)
+	result := database.Conn(ctx, r.db).Model(&AddressModel{}).
+		Where("id = ? AND user_id = ?", a.ID, a.UserID).
+		Select("label", "recipient", "phone", "country", "province", "city", "district", "line1", "line2", "postal_code", "updated_at").
+		Updates(model)
+	if result.Error != nil {
+		return fmt.Errorf("更新地址错误: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apierror.NewNotFoundError("地址不存在", fmt.Sprintf("ID: %d", a.ID))
+	}
+	return nil
+}
+
+// Delete 删除地址
+func (r *GormAddressRepository) Delete(ctx context.Context, userID uint, id uint) error {
+	return database.Transaction(ctx, r.db, func(ctx context.Context) error {
+		tx := database.Conn(ctx, r.db)
+
+		var model AddressModel
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&model).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return apierror.NewNotFoundError("地址不存在", fmt.Sprintf("ID: %d", id))
+			}
+			return fmt.Errorf("查询地址错误: %w", err)
+		}
+		if err := tx.Delete(&model).Error; err != nil {
+			return fmt.Errorf("删除地址错误: %w", err)
+		}
+		if !model.IsDefault {
+			return nil
+		}
+
+		// 删除默认地址后将最近创建的地址设为默认
+		var next AddressModel
+		result := tx.Where("user_id = ?", userID).Order("id DESC").Limit(1).Find(&next)
+		if result.Error != nil {
+			return fmt.Errorf("查询地址错误: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Model(&next).Update("is_default", true).Error; err != nil {
+			return fmt.Errorf("设置默认地址错误: %w", err)
+		}
+		return nil
+	})
+}
+
+// SetDefault 将地址设为默认
+func (r *GormAddressRepository) SetDefault(ctx context.Context, userID uint, id uint) error {
+	return database.Transaction(ctx, r.db, func(ctx context.Context) error {
+		tx := database.Conn(ctx, r.db)
+
+		result := tx.Model(&AddressModel{}).Where("id = ? AND user_id = ?", id, userID).Update("is_default", true)
+		if result.Error != nil {
+			return fmt.Errorf("设置默认地址错误: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			// 已经是默认地址时影响行数也为0，需要区分地址是否存在
+			var count int64
+			if err := tx.Model(&AddressModel{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error; err != nil {
+				return fmt.Errorf("查询地址错误: %w", err)
+			}
+			if count == 0 {
+				return apierror.NewNotFoundError("地址不存在", fmt.Sprintf("ID: %d", id))
+			}
+		}
+
+		if err := tx.Model(&AddressModel{}).Where("user_id = ? AND id <> ? AND is_default = ?", userID, id, true).
+			Update("is_default", false).Error; err != nil {
+			return fmt.Errorf("取消默认地址错误: %w", err)
+		}
+		return nil
+	})
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormAddressRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&AddressModel{})
+}
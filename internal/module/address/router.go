@@ -0,0 +1,38 @@
+package address
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/address/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册地址簿模块路由
+func RegisterRoutes(router *gin.Engine, handler *handler.AddressHTTPHandler, jwtConfig *config.JWTConfig) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 当前用户的收货地址(需要认证)
+	addressRoutes := v1.Group("/users/me/addresses")
+	addressRoutes.Use(middleware.JWT(jwtConfig))
+	{
+		// 获取地址列表
+		addressRoutes.GET("", handler.ListAddresses)
+
+		// 新增地址
+		addressRoutes.POST("", handler.CreateAddress)
+
+		// 获取地址详情
+		addressRoutes.GET("/:id", handler.GetAddress)
+
+		// 修改地址
+		addressRoutes.PUT("/:id", handler.UpdateAddress)
+
+		// 删除地址
+		addressRoutes.DELETE("/:id", handler.DeleteAddress)
+
+		// 设为默认地址
+		addressRoutes.POST("/:id/default", handler.SetDefaultAddress)
+	}
+}
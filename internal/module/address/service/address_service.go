@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"web3-ecommerce-app/internal/domain/address"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// maxAddresses 每个用户最多保存的地址数量
+const maxAddresses = 20
+
+// AddressService 地址簿服务接口
+type AddressService interface {
+	// ListAddresses 获取用户的全部地址，默认地址在前
+	ListAddresses(ctx context.Context, userID uint) ([]address.Address, error)
+
+	// GetAddress 获取用户的某个地址
+	GetAddress(ctx context.Context, userID uint, id uint) (*address.Address, error)
+
+	// GetDefaultAddress 获取用户的默认地址，没有地址时返回nil
+	GetDefaultAddress(ctx context.Context, userID uint) (*address.Address, error)
+
+	// CreateAddress 新增地址，第一个地址自动设为默认
+	CreateAddress(ctx context.Context, userID uint, input address.AddressInput) (*address.Address, error)
+
+	// UpdateAddress 修改地址，已下单的订单保留下单时的地址快照
+	UpdateAddress(ctx context.Context, userID uint, id uint, input address.AddressInput) (*address.Address, error)
+
+	// DeleteAddress 删除地址
+	DeleteAddress(ctx context.Context, userID uint, id uint) error
+
+	// SetDefaultAddress 设为默认地址
+	SetDefaultAddress(ctx context.Context, userID uint, id uint) (*address.Address, error)
+}
+
+// DefaultAddressService 默认地址簿服务实现
+type DefaultAddressService struct {
+	addressRepo address.AddressRepository
+}
+
+// NewAddressService 创建地址簿服务
+func NewAddressService(addressRepo address.AddressRepository) AddressService {
+	return &DefaultAddressService{
+		addressRepo: addressRepo,
+	}
+}
+
+// ListAddresses 获取用户的全部地址
+func (s *DefaultAddressService) ListAddresses(ctx context.Context, userID uint) ([]address.Address, error) {
+	return s.addressRepo.FindByUser(ctx, userID)
+}
+
+// GetAddress 获取用户的某个地址
+func (s *DefaultAddressService) GetAddress(ctx context.Context, userID uint, id uint) (*address.Address, error) {
+	return s.addressRepo.FindByID(ctx, userID, id)
+}
+
+// GetDefaultAddress 获取用户的默认地址
+func (s *DefaultAddressService) GetDefaultAddress(ctx context.Context, userID uint) (*address.Address, error) {
+	return s.addressRepo.FindDefault(ctx, userID)
+}
+
+// CreateAddress 新增地址
+func (s *DefaultAddressService) CreateAddress(ctx context.Context, userID uint, input address.AddressInput) (*address.Address, error) {
+	input.Address.Normalize()
+	if err := input.Address.Validate(); err != nil {
+		return nil, err
+	}
+
+	count, err := s.addressRepo.Count(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAddresses {
+		return nil, apierror.NewValidationError("地址数量已达上限", fmt.Sprintf("最多保存 %d 个地址", maxAddresses))
+	}
+
+	newAddress := &address.Address{
+		UserID:    userID,
+		Label:     strings.TrimSpace(input.Label),
+		IsDefault: input.IsDefault || count == 0,
+		Address:   input.Address,
+	}
+	if err := s.addressRepo.Create(ctx, newAddress); err != nil {
+		return nil, err
+	}
+	return newAddress, nil
+}
+
+// UpdateAddress 修改地址
+func (s *DefaultAddressService) UpdateAddress(ctx context.Context, userID uint, id uint, input address.AddressInput) (*address.Address, error) {
+	input.Address.Normalize()
+	if err := input.Address.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.addressRepo.FindByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	existing.Label = strings.TrimSpace(input.Label)
+	existing.Address = input.Address
+	if err := s.addressRepo.Update(ctx, existing); err != nil {
+		return nil, err
+	}
+
+	return s.addressRepo.FindByID(ctx, userID, id)
+}
+
+// DeleteAddress 删除地址，删除默认地址后最近新增的地址成为默认地址
+func (s *DefaultAddressService) DeleteAddress(ctx context.Context, userID uint, id uint) error {
+	return s.addressRepo.Delete(ctx, userID, id)
+}
+
+// SetDefaultAddress 设为默认地址
+func (s *DefaultAddressService) SetDefaultAddress(ctx context.Context, userID uint, id uint) (*address.Address, error) {
+	if err := s.addressRepo.SetDefault(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.addressRepo.FindByID(ctx, userID, id)
+}
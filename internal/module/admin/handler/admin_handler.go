@@ -1,10 +1,24 @@
 package handler
 
 import (
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 	"web3-ecommerce-app/internal/domain/admin"
+	"web3-ecommerce-app/internal/domain/giftcard"
+	"web3-ecommerce-app/internal/domain/merchant"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/promotion"
+	"web3-ecommerce-app/internal/domain/review"
+	"web3-ecommerce-app/internal/domain/shipping"
+	"web3-ecommerce-app/internal/domain/subscription"
+	"web3-ecommerce-app/internal/domain/tax"
+	"web3-ecommerce-app/internal/domain/wallet"
 	"web3-ecommerce-app/internal/module/admin/service"
+	productService "web3-ecommerce-app/internal/module/product/service"
 	"web3-ecommerce-app/pkg/apierror"
 
 	"github.com/gin-gonic/gin"
@@ -32,6 +46,36 @@ func getIDFromParam(c *gin.Context) (uint, error) {
 	return uint(id), nil
 }
 
+// getUintParam 从URL参数中获取指定名称的ID
+func getUintParam(c *gin.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		return 0, apierror.NewBadRequestError("无效的"+name, err.Error())
+	}
+	return uint(id), nil
+}
+
+// parseDateQuery 解析日期查询参数，支持 2006-01-02 和 RFC3339 格式，参数为空时返回零值
+// endOfDay为true时，只有日期的参数解析为次日零点，用作左闭右开区间的结束时间
+func parseDateQuery(c *gin.Context, name string, endOfDay bool) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, apierror.NewBadRequestError("无效的"+name, "日期格式应为 2006-01-02 或 RFC3339")
+	}
+	return t, nil
+}
+
 // handleError 处理错误
 func handleError(c *gin.Context, err error) {
 	// 检查是否是API错误
@@ -123,21 +167,21 @@ func (h *AdminHTTPHandler) DeleteUser(c *gin.Context) {
 // 产品管理
 // CreateProduct 创建产品
 func (h *AdminHTTPHandler) CreateProduct(c *gin.Context) {
-	var productData map[string]interface{}
-	if err := c.ShouldBindJSON(&productData); err != nil {
+	var input product.CreateProductInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
 		})
 		return
 	}
 
-	product, err := h.adminService.CreateProduct(c.Request.Context(), productData)
+	productEntity, err := h.adminService.CreateProduct(c.Request.Context(), input)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, product)
+	c.JSON(http.StatusCreated, productEntity)
 }
 
 // ListProducts 获取产品列表
@@ -176,13 +220,13 @@ func (h *AdminHTTPHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
-	product, err := h.adminService.GetProduct(c.Request.Context(), id)
+	productEntity, err := h.adminService.GetProduct(c.Request.Context(), id)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, product)
+	c.JSON(http.StatusOK, productEntity)
 }
 
 // UpdateProduct 更新产品
@@ -193,21 +237,21 @@ func (h *AdminHTTPHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	var productData map[string]interface{}
-	if err := c.ShouldBindJSON(&productData); err != nil {
+	var input product.UpdateProductInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
 		})
 		return
 	}
 
-	product, err := h.adminService.UpdateProduct(c.Request.Context(), id, productData)
+	productEntity, err := h.adminService.UpdateProduct(c.Request.Context(), id, input)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, product)
+	c.JSON(http.StatusOK, productEntity)
 }
 
 // DeleteProduct 删除产品
@@ -234,128 +278,266 @@ func (h *AdminHTTPHandler) UpdateProductStatus(c *gin.Context) {
 		return
 	}
 
-	var statusData map[string]string
-	if err := c.ShouldBindJSON(&statusData); err != nil {
+	var input product.ChangeStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
 		})
 		return
 	}
 
-	status, ok := statusData["status"]
-	if !ok || status == "" {
+	productEntity, err := h.adminService.UpdateProductStatus(c.Request.Context(), id, input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, productEntity)
+}
+
+// AdjustProductStock 调整产品库存
+func (h *AdminHTTPHandler) AdjustProductStock(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input product.AdjustStockInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": apierror.NewValidationError("无效的请求数据", "状态不能为空"),
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
 		})
 		return
 	}
 
-	if err := h.adminService.UpdateProductStatus(c.Request.Context(), id, status); err != nil {
+	productEntity, err := h.adminService.AdjustProductStock(c.Request.Context(), id, input.Delta)
+	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "产品状态更新成功"})
+	c.JSON(http.StatusOK, productEntity)
 }
 
-// 订单管理
-// ListOrders 获取订单列表
-func (h *AdminHTTPHandler) ListOrders(c *gin.Context) {
-	// 获取过滤参数
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	userID, _ := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 32)
-	status := c.DefaultQuery("status", "")
+// 产品图片管理
+// UploadProductImage 上传产品图片，使用multipart表单的file字段
+func (h *AdminHTTPHandler) UploadProductImage(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
 
-	// 时间参数可能需要更复杂的处理，这里简化处理
-	filter := admin.OrderFilter{
-		PaginationParam: admin.PaginationParam{
-			Page:     page,
-			PageSize: pageSize,
-		},
-		UserID: uint(userID),
-		Status: status,
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("读取上传文件失败", err.Error()),
+		})
+		return
 	}
+	defer file.Close()
 
-	result, err := h.adminService.ListOrders(c.Request.Context(), filter)
+	image, err := h.adminService.UploadProductImage(c.Request.Context(), id, file)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusCreated, image)
 }
 
-// GetOrder 获取订单详情
-func (h *AdminHTTPHandler) GetOrder(c *gin.Context) {
+// ListProductImages 获取产品图片列表
+func (h *AdminHTTPHandler) ListProductImages(c *gin.Context) {
 	id, err := getIDFromParam(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
 
-	order, err := h.adminService.GetOrder(c.Request.Context(), id)
+	images, err := h.adminService.ListProductImages(c.Request.Context(), id)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, order)
+	c.JSON(http.StatusOK, gin.H{"images": images})
 }
 
-// UpdateOrderStatus 更新订单状态
-func (h *AdminHTTPHandler) UpdateOrderStatus(c *gin.Context) {
+// ReorderProductImages 调整产品图片顺序
+func (h *AdminHTTPHandler) ReorderProductImages(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input product.ReorderImagesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	images, err := h.adminService.ReorderProductImages(c.Request.Context(), id, input.ImageIDs)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"images": images})
+}
+
+// SetPrimaryProductImage 设置产品主图
+func (h *AdminHTTPHandler) SetPrimaryProductImage(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+	imageID, err := getUintParam(c, "image_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	if err := h.adminService.SetPrimaryProductImage(c.Request.Context(), id, imageID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "主图设置成功"})
+}
+
+// DeleteProductImage 删除产品图片
+func (h *AdminHTTPHandler) DeleteProductImage(c *gin.Context) {
 	id, err := getIDFromParam(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
+	imageID, err := getUintParam(c, "image_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	if err := h.adminService.DeleteProductImage(c.Request.Context(), id, imageID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "图片删除成功"})
+}
+
+// 产品批量导入导出
+// ImportProducts 上传CSV或JSONL文件批量导入产品，返回后台任务
+// 查询参数format可选(csv/jsonl，默认按文件扩展名判断)，dry_run=true时只校验不写入
+func (h *AdminHTTPHandler) ImportProducts(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
 
-	var statusData map[string]string
-	if err := c.ShouldBindJSON(&statusData); err != nil {
+	format, err := productService.DetectCatalogFormat(c.Query("format"), fileHeader.Filename)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
 		})
 		return
 	}
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
 
-	status, ok := statusData["status"]
-	if !ok || status == "" {
+	file, err := fileHeader.Open()
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": apierror.NewValidationError("无效的请求数据", "状态不能为空"),
+			"error": apierror.NewBadRequestError("读取上传文件失败", err.Error()),
 		})
 		return
 	}
+	defer file.Close()
 
-	if err := h.adminService.UpdateOrderStatus(c.Request.Context(), id, status); err != nil {
+	job, err := h.adminService.ImportProducts(c.Request.Context(), format, dryRun, file)
+	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "订单状态更新成功"})
+	c.JSON(http.StatusAccepted, job)
 }
 
-// 支付管理
-// ListTransactions 获取交易列表
-func (h *AdminHTTPHandler) ListTransactions(c *gin.Context) {
-	// 获取过滤参数
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	userID, _ := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 32)
-	txType := c.DefaultQuery("type", "")
-	status := c.DefaultQuery("status", "")
+// GetProductImportJob 查询产品导入任务进度和逐行错误报告
+func (h *AdminHTTPHandler) GetProductImportJob(c *gin.Context) {
+	job, err := h.adminService.GetProductImportJob(c.Request.Context(), c.Param("job_id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
 
-	filter := admin.TransactionFilter{
-		PaginationParam: admin.PaginationParam{
-			Page:     page,
-			PageSize: pageSize,
-		},
-		UserID: uint(userID),
-		Type:   txType,
-		Status: status,
+	c.JSON(http.StatusOK, job)
+}
+
+// ExportProducts 以CSV或JSONL格式流式导出全部产品
+func (h *AdminHTTPHandler) ExportProducts(c *gin.Context) {
+	format, err := productService.DetectCatalogFormat(c.DefaultQuery("format", "csv"), "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
 	}
 
-	result, err := h.adminService.ListTransactions(c.Request.Context(), filter)
+	contentType := "text/csv; charset=utf-8"
+	if format == "jsonl" {
+		contentType = "application/x-ndjson"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="products-%s.%s"`, time.Now().Format("20060102"), format))
+	c.Status(http.StatusOK)
+
+	// 响应头已经发出，中途出错只能记录日志
+	if err := h.adminService.ExportProducts(c.Request.Context(), format, c.Writer); err != nil {
+		log.Printf("导出产品失败: %v", err)
+	}
+}
+
+// 数字商品管理
+// ImportLicenseKeys 上传文本文件导入激活码，使用multipart表单的file字段，每行一个激活码
+func (h *AdminHTTPHandler) ImportLicenseKeys(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("读取上传文件失败", err.Error()),
+		})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.adminService.ImportLicenseKeys(c.Request.Context(), id, file)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -364,20 +546,1431 @@ func (h *AdminHTTPHandler) ListTransactions(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-// ProcessWithdrawal 处理提现
-func (h *AdminHTTPHandler) ProcessWithdrawal(c *gin.Context) {
+// GetLicenseKeyStats 获取激活码池的使用情况
+func (h *AdminHTTPHandler) GetLicenseKeyStats(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	stats, err := h.adminService.GetLicenseKeyStats(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// UploadDigitalAsset 上传数字商品文件，使用multipart表单的file字段
+func (h *AdminHTTPHandler) UploadDigitalAsset(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("读取上传文件失败", err.Error()),
+		})
+		return
+	}
+	defer file.Close()
+
+	asset, err := h.adminService.UploadDigitalAsset(c.Request.Context(), id, fileHeader.Filename, file)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, asset)
+}
+
+// ListDigitalAssets 获取数字商品文件列表
+func (h *AdminHTTPHandler) ListDigitalAssets(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	assets, err := h.adminService.ListDigitalAssets(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"assets": assets})
+}
+
+// DeleteDigitalAsset 删除数字商品文件
+func (h *AdminHTTPHandler) DeleteDigitalAsset(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+	assetID, err := getUintParam(c, "asset_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	if err := h.adminService.DeleteDigitalAsset(c.Request.Context(), id, assetID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "文件删除成功"})
+}
+
+// 产品定价管理
+// CreateProductPriceSchedule 创建产品的定时调价，ends_at为空时为永久调价
+func (h *AdminHTTPHandler) CreateProductPriceSchedule(c *gin.Context) {
 	id, err := getIDFromParam(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
 
-	if err := h.adminService.ProcessWithdrawal(c.Request.Context(), id); err != nil {
+	var input product.CreatePriceScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	schedule, err := h.adminService.CreateProductPriceSchedule(c.Request.Context(), id, input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// ListProductPriceSchedules 获取产品的定时调价
+func (h *AdminHTTPHandler) ListProductPriceSchedules(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	schedules, err := h.adminService.ListProductPriceSchedules(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+// CancelProductPriceSchedule 取消产品的定时调价
+func (h *AdminHTTPHandler) CancelProductPriceSchedule(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+	scheduleID, err := getUintParam(c, "schedule_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	if err := h.adminService.CancelProductPriceSchedule(c.Request.Context(), id, scheduleID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "定时调价已取消"})
+}
+
+// GetProductPriceHistory 获取产品最近days天(默认30天)的价格历史
+func (h *AdminHTTPHandler) GetProductPriceHistory(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(product.LowestPriceDays)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的天数", err.Error()),
+		})
+		return
+	}
+
+	history, err := h.adminService.GetProductPriceHistory(c.Request.Context(), id, days)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+// 持币门槛管理
+// CreateProductGateRule 为产品添加持币门槛规则
+func (h *AdminHTTPHandler) CreateProductGateRule(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input product.CreateGateRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	rule, err := h.adminService.CreateProductGateRule(c.Request.Context(), id, input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// ListProductGateRules 获取产品的持币门槛规则
+func (h *AdminHTTPHandler) ListProductGateRules(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	rules, err := h.adminService.ListProductGateRules(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// DeleteProductGateRule 删除产品的持币门槛规则
+func (h *AdminHTTPHandler) DeleteProductGateRule(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+	ruleID, err := getUintParam(c, "rule_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	if err := h.adminService.DeleteProductGateRule(c.Request.Context(), id, ruleID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "持币规则删除成功"})
+}
+
+// 促销管理
+// CreatePromotion 创建促销
+func (h *AdminHTTPHandler) CreatePromotion(c *gin.Context) {
+	var input promotion.PromotionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	promotionEntity, err := h.adminService.CreatePromotion(c.Request.Context(), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, promotionEntity)
+}
+
+// ListPromotions 获取促销列表，可按启用状态和优惠码过滤
+func (h *AdminHTTPHandler) ListPromotions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	filter := admin.PromotionFilter{
+		PaginationParam: admin.PaginationParam{
+			Page:     page,
+			PageSize: pageSize,
+		},
+		Code: c.Query("code"),
+	}
+	if activeStr := c.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": apierror.NewBadRequestError("无效的启用状态", err.Error()),
+			})
+			return
+		}
+		filter.Active = &active
+	}
+
+	result, err := h.adminService.ListPromotions(c.Request.Context(), filter)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetPromotion 获取促销详情
+func (h *AdminHTTPHandler) GetPromotion(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	promotionEntity, err := h.adminService.GetPromotion(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, promotionEntity)
+}
+
+// UpdatePromotion 更新促销
+func (h *AdminHTTPHandler) UpdatePromotion(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input promotion.PromotionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	promotionEntity, err := h.adminService.UpdatePromotion(c.Request.Context(), id, input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, promotionEntity)
+}
+
+// DeletePromotion 删除促销
+func (h *AdminHTTPHandler) DeletePromotion(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	if err := h.adminService.DeletePromotion(c.Request.Context(), id); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "促销删除成功"})
+}
+
+// 评价审核
+// ListReviews 获取评价列表，status为空时返回待审核队列
+func (h *AdminHTTPHandler) ListReviews(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	productID, _ := strconv.ParseUint(c.DefaultQuery("product_id", "0"), 10, 32)
+
+	filter := admin.ReviewFilter{
+		PaginationParam: admin.PaginationParam{
+			Page:     page,
+			PageSize: pageSize,
+		},
+		ProductID: uint(productID),
+		Status:    c.DefaultQuery("status", ""),
+	}
+
+	result, err := h.adminService.ListReviews(c.Request.Context(), filter)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ModerateReview 审核通过或隐藏评价
+func (h *AdminHTTPHandler) ModerateReview(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input review.ModerateReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	reviewEntity, err := h.adminService.ModerateReview(c.Request.Context(), id, input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reviewEntity)
+}
+
+// 订单管理
+// ListOrders 获取订单列表
+func (h *AdminHTTPHandler) ListOrders(c *gin.Context) {
+	// 获取过滤参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	userID, _ := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 32)
+	status := c.DefaultQuery("status", "")
+
+	// 时间范围为左闭右开，只有日期的结束时间包含当天
+	startDate, err := parseDateQuery(c, "start_date", false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+	endDate, err := parseDateQuery(c, "end_date", true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	filter := admin.OrderFilter{
+		PaginationParam: admin.PaginationParam{
+			Page:     page,
+			PageSize: pageSize,
+		},
+		UserID:    uint(userID),
+		Status:    status,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	result, err := h.adminService.ListOrders(c.Request.Context(), filter)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetOrder 获取订单详情
+func (h *AdminHTTPHandler) GetOrder(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	order, err := h.adminService.GetOrder(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// GetOrderBySN 根据订单号获取订单详情
+func (h *AdminHTTPHandler) GetOrderBySN(c *gin.Context) {
+	orderEntity, err := h.adminService.GetOrderBySN(c.Request.Context(), c.Param("order_sn"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderEntity)
+}
+
+// UpdateOrderStatus 更新订单状态
+func (h *AdminHTTPHandler) UpdateOrderStatus(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input order.UpdateStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	// 管理后台路由经过JWT认证，user_id为当前管理员ID
+	adminID, _ := c.Get("user_id")
+	orderEntity, err := h.adminService.UpdateOrderStatus(c.Request.Context(), id, adminID.(uint), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderEntity)
+}
+
+// ConfirmOrderPayment 确认订单收款
+func (h *AdminHTTPHandler) ConfirmOrderPayment(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input order.ConfirmPaymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	orderEntity, err := h.adminService.ConfirmOrderPayment(c.Request.Context(), id, adminID.(uint), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderEntity)
+}
+
+// GetOrderTimeline 获取订单状态变更记录
+func (h *AdminHTTPHandler) GetOrderTimeline(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	events, err := h.adminService.GetOrderTimeline(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// ListLatePayments 获取逾期付款列表
+func (h *AdminHTTPHandler) ListLatePayments(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	result, err := h.adminService.ListLatePayments(c.Request.Context(), order.LatePaymentQuery{
+		Status:   c.DefaultQuery("status", ""),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ResolveLatePayment 处理逾期付款
+func (h *AdminHTTPHandler) ResolveLatePayment(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input order.ResolveLatePaymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	payment, err := h.adminService.ResolveLatePayment(c.Request.Context(), id, adminID.(uint), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// ListOrderShipments 获取订单的包裹
+func (h *AdminHTTPHandler) ListOrderShipments(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	shipments, err := h.adminService.ListOrderShipments(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shipments": shipments})
+}
+
+// CreateOrderShipment 为订单创建包裹，items为空时发出全部未发货的商品
+func (h *AdminHTTPHandler) CreateOrderShipment(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input order.CreateShipmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	shipment, err := h.adminService.CreateOrderShipment(c.Request.Context(), id, adminID.(uint), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, shipment)
+}
+
+// MarkShipmentDelivered 标记包裹已签收
+func (h *AdminHTTPHandler) MarkShipmentDelivered(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+	shipmentID, err := getUintParam(c, "shipment_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	shipment, err := h.adminService.MarkShipmentDelivered(c.Request.Context(), id, shipmentID, adminID.(uint))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shipment)
+}
+
+// ListOrderRefunds 获取订单的退款记录
+func (h *AdminHTTPHandler) ListOrderRefunds(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	refunds, err := h.adminService.ListOrderRefunds(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refunds": refunds})
+}
+
+// CreateOrderRefund 为订单退款，支持按订单行部分退款
+func (h *AdminHTTPHandler) CreateOrderRefund(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input order.CreateRefundInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	refund, err := h.adminService.CreateOrderRefund(c.Request.Context(), id, adminID.(uint), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, refund)
+}
+
+// 退货管理
+// ListReturns 获取退货申请列表
+func (h *AdminHTTPHandler) ListReturns(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	result, err := h.adminService.ListReturns(c.Request.Context(), order.ReturnQuery{
+		Status:   c.DefaultQuery("status", ""),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetReturn 获取退货申请详情
+func (h *AdminHTTPHandler) GetReturn(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	request, err := h.adminService.GetReturn(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// ReviewReturn 同意或拒绝退货申请
+func (h *AdminHTTPHandler) ReviewReturn(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input order.ReviewReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	request, err := h.adminService.ReviewReturn(c.Request.Context(), id, adminID.(uint), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// ReceiveReturn 确认收到退货，退回库存并退款
+func (h *AdminHTTPHandler) ReceiveReturn(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input order.ReceiveReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	request, err := h.adminService.ReceiveReturn(c.Request.Context(), id, adminID.(uint), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// ListOrderSubOrders 获取订单按商家拆分的子订单
+func (h *AdminHTTPHandler) ListOrderSubOrders(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	subOrders, err := h.adminService.ListOrderSubOrders(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sub_orders": subOrders})
+}
+
+// 商家管理
+// ListMerchants 获取商家列表，可按状态过滤和按名称搜索
+func (h *AdminHTTPHandler) ListMerchants(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	result, err := h.adminService.ListMerchants(c.Request.Context(), merchant.MerchantQuery{
+		Status:   c.DefaultQuery("status", ""),
+		Search:   c.DefaultQuery("search", ""),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetMerchant 获取商家详情
+func (h *AdminHTTPHandler) GetMerchant(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	m, err := h.adminService.GetMerchant(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// ReviewMerchant 审核入驻申请、暂停或恢复商家
+func (h *AdminHTTPHandler) ReviewMerchant(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input merchant.ReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	m, err := h.adminService.ReviewMerchant(c.Request.Context(), id, adminID.(uint), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// 订阅管理
+// ListSubscriptionPlans 获取订阅计划列表，可按商品和状态过滤
+func (h *AdminHTTPHandler) ListSubscriptionPlans(c *gin.Context) {
+	productID, _ := strconv.ParseUint(c.DefaultQuery("product_id", "0"), 10, 32)
+
+	plans, err := h.adminService.ListSubscriptionPlans(c.Request.Context(), subscription.PlanQuery{
+		ProductID: uint(productID),
+		Status:    c.DefaultQuery("status", ""),
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"plans": plans})
+}
+
+// CreateSubscriptionPlan 创建订阅计划
+func (h *AdminHTTPHandler) CreateSubscriptionPlan(c *gin.Context) {
+	var input subscription.CreatePlanInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	plan, err := h.adminService.CreateSubscriptionPlan(c.Request.Context(), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// UpdateSubscriptionPlan 更新订阅计划
+func (h *AdminHTTPHandler) UpdateSubscriptionPlan(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input subscription.UpdatePlanInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	plan, err := h.adminService.UpdateSubscriptionPlan(c.Request.Context(), id, input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// UpdateSubscriptionPlanStatus 上架或归档订阅计划
+func (h *AdminHTTPHandler) UpdateSubscriptionPlanStatus(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input subscription.ChangePlanStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	plan, err := h.adminService.ChangeSubscriptionPlanStatus(c.Request.Context(), id, input.Status)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// ListSubscriptions 获取订阅列表，可按用户、计划和状态过滤
+func (h *AdminHTTPHandler) ListSubscriptions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	userID, _ := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 32)
+	planID, _ := strconv.ParseUint(c.DefaultQuery("plan_id", "0"), 10, 32)
+
+	result, err := h.adminService.ListSubscriptions(c.Request.Context(), subscription.SubscriptionQuery{
+		UserID:   uint(userID),
+		PlanID:   uint(planID),
+		Status:   c.DefaultQuery("status", ""),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CancelSubscription 取消用户的订阅
+func (h *AdminHTTPHandler) CancelSubscription(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input subscription.CancelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	sub, err := h.adminService.CancelSubscription(c.Request.Context(), id, adminID.(uint), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// 礼品卡管理
+// ListGiftCards 获取礼品卡列表，可按状态、来源、购买用户和订单过滤
+func (h *AdminHTTPHandler) ListGiftCards(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	purchaserID, _ := strconv.ParseUint(c.DefaultQuery("purchaser_id", "0"), 10, 32)
+	orderID, _ := strconv.ParseUint(c.DefaultQuery("order_id", "0"), 10, 32)
+
+	result, err := h.adminService.ListGiftCards(c.Request.Context(), giftcard.GiftCardQuery{
+		Status:      c.DefaultQuery("status", ""),
+		Source:      c.DefaultQuery("source", ""),
+		PurchaserID: uint(purchaserID),
+		OrderID:     uint(orderID),
+		Page:        page,
+		PageSize:    pageSize,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// IssueGiftCards 发放礼品卡，响应中的兑换码不会再次返回
+func (h *AdminHTTPHandler) IssueGiftCards(c *gin.Context) {
+	var input giftcard.IssueInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	cards, err := h.adminService.IssueGiftCards(c.Request.Context(), adminID.(uint), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"gift_cards": cards})
+}
+
+// GetGiftCard 获取礼品卡详情
+func (h *AdminHTTPHandler) GetGiftCard(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	card, err := h.adminService.GetGiftCard(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+// ListGiftCardRedemptions 获取礼品卡的兑换记录
+func (h *AdminHTTPHandler) ListGiftCardRedemptions(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	redemptions, err := h.adminService.ListGiftCardRedemptions(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"redemptions": redemptions})
+}
+
+// UpdateGiftCard 修改礼品卡的过期时间和备注
+func (h *AdminHTTPHandler) UpdateGiftCard(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input giftcard.UpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	card, err := h.adminService.UpdateGiftCard(c.Request.Context(), id, input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+// UpdateGiftCardStatus 启用或停用礼品卡
+func (h *AdminHTTPHandler) UpdateGiftCardStatus(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input giftcard.ChangeStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	card, err := h.adminService.ChangeGiftCardStatus(c.Request.Context(), id, input.Status)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+// ListGiftCardDenominations 获取礼品卡商品的面额
+func (h *AdminHTTPHandler) ListGiftCardDenominations(c *gin.Context) {
+	denominations, err := h.adminService.ListGiftCardDenominations(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"denominations": denominations})
+}
+
+// SaveGiftCardDenomination 将数字商品设置为礼品卡商品
+func (h *AdminHTTPHandler) SaveGiftCardDenomination(c *gin.Context) {
+	productID, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input giftcard.SaveDenominationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	denomination, err := h.adminService.SaveGiftCardDenomination(c.Request.Context(), productID, input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, denomination)
+}
+
+// DeleteGiftCardDenomination 取消商品的礼品卡面额
+func (h *AdminHTTPHandler) DeleteGiftCardDenomination(c *gin.Context) {
+	productID, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	if err := h.adminService.DeleteGiftCardDenomination(c.Request.Context(), productID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "礼品卡面额已删除"})
+}
+
+// 配送管理
+// ListShippingZones 获取配送区域列表
+func (h *AdminHTTPHandler) ListShippingZones(c *gin.Context) {
+	zones, err := h.adminService.ListShippingZones(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"zones": zones})
+}
+
+// CreateShippingZone 创建配送区域
+func (h *AdminHTTPHandler) CreateShippingZone(c *gin.Context) {
+	var input shipping.ZoneInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	zone, err := h.adminService.CreateShippingZone(c.Request.Context(), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, zone)
+}
+
+// GetShippingZone 获取配送区域详情
+func (h *AdminHTTPHandler) GetShippingZone(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	zone, err := h.adminService.GetShippingZone(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, zone)
+}
+
+// UpdateShippingZone 更新配送区域，配送方式整体替换
+func (h *AdminHTTPHandler) UpdateShippingZone(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input shipping.ZoneInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	zone, err := h.adminService.UpdateShippingZone(c.Request.Context(), id, input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, zone)
+}
+
+// DeleteShippingZone 删除配送区域
+func (h *AdminHTTPHandler) DeleteShippingZone(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	if err := h.adminService.DeleteShippingZone(c.Request.Context(), id); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "配送区域删除成功"})
+}
+
+// 税率管理
+// ListTaxRates 获取税率列表
+func (h *AdminHTTPHandler) ListTaxRates(c *gin.Context) {
+	rates, err := h.adminService.ListTaxRates(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rates": rates})
+}
+
+// CreateTaxRate 创建税率
+func (h *AdminHTTPHandler) CreateTaxRate(c *gin.Context) {
+	var input tax.RateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	rate, err := h.adminService.CreateTaxRate(c.Request.Context(), input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+// GetTaxRate 获取税率详情
+func (h *AdminHTTPHandler) GetTaxRate(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	rate, err := h.adminService.GetTaxRate(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// UpdateTaxRate 更新税率，只影响之后创建的订单
+func (h *AdminHTTPHandler) UpdateTaxRate(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input tax.RateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	rate, err := h.adminService.UpdateTaxRate(c.Request.Context(), id, input)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// DeleteTaxRate 删除税率
+func (h *AdminHTTPHandler) DeleteTaxRate(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	if err := h.adminService.DeleteTaxRate(c.Request.Context(), id); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "税率删除成功"})
+}
+
+// 支付管理
+// ListTransactions 获取交易列表
+func (h *AdminHTTPHandler) ListTransactions(c *gin.Context) {
+	// 获取过滤参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	userID, _ := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 32)
+	txType := c.DefaultQuery("type", "")
+	status := c.DefaultQuery("status", "")
+
+	filter := admin.TransactionFilter{
+		PaginationParam: admin.PaginationParam{
+			Page:     page,
+			PageSize: pageSize,
+		},
+		UserID: uint(userID),
+		Type:   txType,
+		Status: status,
+	}
+
+	result, err := h.adminService.ListTransactions(c.Request.Context(), filter)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListWithdrawals 获取提现单列表
+func (h *AdminHTTPHandler) ListWithdrawals(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	userID, _ := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 32)
+
+	result, err := h.adminService.ListWithdrawals(c.Request.Context(), wallet.WithdrawalQuery{
+		UserID:   uint(userID),
+		Status:   c.DefaultQuery("status", ""),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ProcessWithdrawal 处理提现，记录转账交易哈希或标记失败
+func (h *AdminHTTPHandler) ProcessWithdrawal(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		return
+	}
+
+	var input wallet.ProcessWithdrawalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	withdrawal, err := h.adminService.ProcessWithdrawal(c.Request.Context(), id, adminID.(uint), input)
+	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "提现处理成功"})
+	c.JSON(http.StatusOK, withdrawal)
 }
 
 // 统计数据
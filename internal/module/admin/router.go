@@ -13,13 +13,15 @@ func RegisterRoutes(
 	router *gin.Engine,
 	adminHandler *handler.AdminHTTPHandler,
 	jwtConfig *config.JWTConfig,
+	idempotent gin.HandlerFunc,
 ) {
 	// 创建管理后台API路由组
 	adminRoutes := router.Group("/api/v1/admin")
 
-	// 管理后台需要JWT认证和管理员权限验证
+	// 管理后台需要JWT认证和管理员权限验证，写操作支持幂等键
 	adminRoutes.Use(middleware.JWT(jwtConfig))
 	adminRoutes.Use(middleware.AdminRequired())
+	adminRoutes.Use(idempotent)
 
 	// 用户管理
 	{
@@ -55,6 +57,111 @@ func RegisterRoutes(
 
 		// 更改产品状态
 		adminRoutes.PATCH("/products/:id/status", adminHandler.UpdateProductStatus)
+
+		// 调整产品库存
+		adminRoutes.PATCH("/products/:id/stock", adminHandler.AdjustProductStock)
+	}
+
+	// 产品批量导入导出
+	{
+		// 上传文件创建导入任务
+		adminRoutes.POST("/products/import", adminHandler.ImportProducts)
+
+		// 查询导入任务进度
+		adminRoutes.GET("/products/import/:job_id", adminHandler.GetProductImportJob)
+
+		// 导出产品
+		adminRoutes.GET("/products/export", adminHandler.ExportProducts)
+	}
+
+	// 产品图片管理
+	{
+		// 上传产品图片
+		adminRoutes.POST("/products/:id/images", adminHandler.UploadProductImage)
+
+		// 获取产品图片列表
+		adminRoutes.GET("/products/:id/images", adminHandler.ListProductImages)
+
+		// 调整图片顺序
+		adminRoutes.PUT("/products/:id/images/order", adminHandler.ReorderProductImages)
+
+		// 设置主图
+		adminRoutes.PATCH("/products/:id/images/:image_id/primary", adminHandler.SetPrimaryProductImage)
+
+		// 删除图片
+		adminRoutes.DELETE("/products/:id/images/:image_id", adminHandler.DeleteProductImage)
+	}
+
+	// 数字商品管理
+	{
+		// 导入激活码
+		adminRoutes.POST("/products/:id/license-keys", adminHandler.ImportLicenseKeys)
+
+		// 获取激活码池使用情况
+		adminRoutes.GET("/products/:id/license-keys/stats", adminHandler.GetLicenseKeyStats)
+
+		// 上传可下载文件
+		adminRoutes.POST("/products/:id/assets", adminHandler.UploadDigitalAsset)
+
+		// 获取可下载文件列表
+		adminRoutes.GET("/products/:id/assets", adminHandler.ListDigitalAssets)
+
+		// 删除可下载文件
+		adminRoutes.DELETE("/products/:id/assets/:asset_id", adminHandler.DeleteDigitalAsset)
+	}
+
+	// 产品定价管理
+	{
+		// 创建定时调价或限时促销
+		adminRoutes.POST("/products/:id/price-schedules", adminHandler.CreateProductPriceSchedule)
+
+		// 获取定时调价列表
+		adminRoutes.GET("/products/:id/price-schedules", adminHandler.ListProductPriceSchedules)
+
+		// 取消定时调价
+		adminRoutes.DELETE("/products/:id/price-schedules/:schedule_id", adminHandler.CancelProductPriceSchedule)
+
+		// 获取价格历史
+		adminRoutes.GET("/products/:id/price-history", adminHandler.GetProductPriceHistory)
+	}
+
+	// 持币门槛管理
+	{
+		// 添加持币门槛规则
+		adminRoutes.POST("/products/:id/gate-rules", adminHandler.CreateProductGateRule)
+
+		// 获取持币门槛规则
+		adminRoutes.GET("/products/:id/gate-rules", adminHandler.ListProductGateRules)
+
+		// 删除持币门槛规则
+		adminRoutes.DELETE("/products/:id/gate-rules/:rule_id", adminHandler.DeleteProductGateRule)
+	}
+
+	// 促销管理
+	{
+		// 创建促销
+		adminRoutes.POST("/promotions", adminHandler.CreatePromotion)
+
+		// 获取促销列表
+		adminRoutes.GET("/promotions", adminHandler.ListPromotions)
+
+		// 获取促销详情
+		adminRoutes.GET("/promotions/:id", adminHandler.GetPromotion)
+
+		// 更新促销
+		adminRoutes.PUT("/promotions/:id", adminHandler.UpdatePromotion)
+
+		// 删除促销
+		adminRoutes.DELETE("/promotions/:id", adminHandler.DeletePromotion)
+	}
+
+	// 评价审核
+	{
+		// 获取评价列表(默认为待审核队列)
+		adminRoutes.GET("/reviews", adminHandler.ListReviews)
+
+		// 审核通过或隐藏评价
+		adminRoutes.PATCH("/reviews/:id/status", adminHandler.ModerateReview)
 	}
 
 	// 订单管理
@@ -65,8 +172,155 @@ func RegisterRoutes(
 		// 获取单个订单详情
 		adminRoutes.GET("/orders/:id", adminHandler.GetOrder)
 
+		// 根据订单号获取订单详情
+		adminRoutes.GET("/orders/sn/:order_sn", adminHandler.GetOrderBySN)
+
+		// 获取订单状态变更记录
+		adminRoutes.GET("/orders/:id/timeline", adminHandler.GetOrderTimeline)
+
 		// 更新订单状态
 		adminRoutes.PATCH("/orders/:id/status", adminHandler.UpdateOrderStatus)
+
+		// 确认订单收款，记录链上交易哈希和实付金额
+		adminRoutes.POST("/orders/:id/payments", adminHandler.ConfirmOrderPayment)
+
+		// 获取订单关闭后才到账的付款
+		adminRoutes.GET("/late-payments", adminHandler.ListLatePayments)
+
+		// 重新激活订单或记录退款
+		adminRoutes.POST("/late-payments/:id/resolve", adminHandler.ResolveLatePayment)
+
+		// 获取订单的包裹
+		adminRoutes.GET("/orders/:id/shipments", adminHandler.ListOrderShipments)
+
+		// 创建包裹(支持部分发货)
+		adminRoutes.POST("/orders/:id/shipments", adminHandler.CreateOrderShipment)
+
+		// 标记包裹已签收
+		adminRoutes.POST("/orders/:id/shipments/:shipment_id/deliver", adminHandler.MarkShipmentDelivered)
+
+		// 获取订单的退款记录
+		adminRoutes.GET("/orders/:id/refunds", adminHandler.ListOrderRefunds)
+
+		// 退款(支持按订单行部分退款)
+		adminRoutes.POST("/orders/:id/refunds", adminHandler.CreateOrderRefund)
+
+		// 获取订单按商家拆分的子订单及结算情况
+		adminRoutes.GET("/orders/:id/sub-orders", adminHandler.ListOrderSubOrders)
+	}
+
+	// 商家管理
+	{
+		// 获取商家列表
+		adminRoutes.GET("/merchants", adminHandler.ListMerchants)
+
+		// 获取商家详情
+		adminRoutes.GET("/merchants/:id", adminHandler.GetMerchant)
+
+		// 审核入驻申请、暂停或恢复商家
+		adminRoutes.POST("/merchants/:id/review", adminHandler.ReviewMerchant)
+	}
+
+	// 订阅管理
+	{
+		// 获取订阅计划列表
+		adminRoutes.GET("/subscription-plans", adminHandler.ListSubscriptionPlans)
+
+		// 为数字商品创建订阅计划
+		adminRoutes.POST("/subscription-plans", adminHandler.CreateSubscriptionPlan)
+
+		// 更新订阅计划，价格修改从下一次续费开始生效
+		adminRoutes.PUT("/subscription-plans/:id", adminHandler.UpdateSubscriptionPlan)
+
+		// 上架或归档订阅计划
+		adminRoutes.PATCH("/subscription-plans/:id/status", adminHandler.UpdateSubscriptionPlanStatus)
+
+		// 获取订阅列表
+		adminRoutes.GET("/subscriptions", adminHandler.ListSubscriptions)
+
+		// 取消用户的订阅
+		adminRoutes.POST("/subscriptions/:id/cancel", adminHandler.CancelSubscription)
+	}
+
+	// 礼品卡管理
+	{
+		// 获取礼品卡列表
+		adminRoutes.GET("/gift-cards", adminHandler.ListGiftCards)
+
+		// 发放礼品卡，兑换码只在响应中返回一次
+		adminRoutes.POST("/gift-cards", adminHandler.IssueGiftCards)
+
+		// 获取礼品卡详情
+		adminRoutes.GET("/gift-cards/:id", adminHandler.GetGiftCard)
+
+		// 获取礼品卡的兑换记录
+		adminRoutes.GET("/gift-cards/:id/redemptions", adminHandler.ListGiftCardRedemptions)
+
+		// 修改礼品卡的过期时间和备注
+		adminRoutes.PUT("/gift-cards/:id", adminHandler.UpdateGiftCard)
+
+		// 启用或停用礼品卡
+		adminRoutes.PATCH("/gift-cards/:id/status", adminHandler.UpdateGiftCardStatus)
+
+		// 获取礼品卡商品的面额
+		adminRoutes.GET("/gift-card-denominations", adminHandler.ListGiftCardDenominations)
+
+		// 将数字商品设置为礼品卡商品，支付后按面额发放礼品卡
+		adminRoutes.PUT("/products/:id/gift-card", adminHandler.SaveGiftCardDenomination)
+
+		// 取消商品的礼品卡面额
+		adminRoutes.DELETE("/products/:id/gift-card", adminHandler.DeleteGiftCardDenomination)
+	}
+
+	// 退货管理
+	{
+		// 获取退货申请列表
+		adminRoutes.GET("/returns", adminHandler.ListReturns)
+
+		// 获取退货申请详情
+		adminRoutes.GET("/returns/:id", adminHandler.GetReturn)
+
+		// 同意或拒绝退货申请
+		adminRoutes.POST("/returns/:id/review", adminHandler.ReviewReturn)
+
+		// 确认收到退货并退款
+		adminRoutes.POST("/returns/:id/receive", adminHandler.ReceiveReturn)
+	}
+
+	// 配送管理
+	{
+		// 获取配送区域列表
+		adminRoutes.GET("/shipping/zones", adminHandler.ListShippingZones)
+
+		// 创建配送区域
+		adminRoutes.POST("/shipping/zones", adminHandler.CreateShippingZone)
+
+		// 获取配送区域详情
+		adminRoutes.GET("/shipping/zones/:id", adminHandler.GetShippingZone)
+
+		// 更新配送区域
+		adminRoutes.PUT("/shipping/zones/:id", adminHandler.UpdateShippingZone)
+
+		// 删除配送区域
+		adminRoutes.DELETE("/shipping/zones/:id", adminHandler.DeleteShippingZone)
+	}
+
+	// 税率管理
+	{
+		// 获取税率列表
+		adminRoutes.GET("/tax/rates", adminHandler.ListTaxRates)
+
+		// 创建税率
+		adminRoutes.POST("/tax/rates", adminHandler.CreateTaxRate)
+
+		// 获取税率详情
+		adminRoutes.GET("/tax/rates/:id", adminHandler.GetTaxRate)
+
+		// 更新税率
+		adminRoutes.PUT("/tax/rates/:id", adminHandler.UpdateTaxRate)
+
+		// 删除税率
+		adminRoutes.DELETE("/tax/rates/:id", adminHandler.DeleteTaxRate)
 	}
 
 	// 支付管理
@@ -74,6 +328,9 @@ func RegisterRoutes(
 		// 获取交易列表
 		adminRoutes.GET("/transactions", adminHandler.ListTransactions)
 
+		// 获取提现单列表
+		adminRoutes.GET("/withdrawals", adminHandler.ListWithdrawals)
+
 		// 处理提现请求
 		adminRoutes.POST("/withdrawals/:id/process", adminHandler.ProcessWithdrawal)
 	}
@@ -2,9 +2,32 @@ package service
 
 import (
 	"context"
+	"io"
 	"web3-ecommerce-app/internal/domain/admin"
+	"web3-ecommerce-app/internal/domain/digital"
+	"web3-ecommerce-app/internal/domain/giftcard"
+	"web3-ecommerce-app/internal/domain/merchant"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/promotion"
+	"web3-ecommerce-app/internal/domain/review"
+	"web3-ecommerce-app/internal/domain/shipping"
+	"web3-ecommerce-app/internal/domain/subscription"
+	"web3-ecommerce-app/internal/domain/tax"
 	"web3-ecommerce-app/internal/domain/user"
+	"web3-ecommerce-app/internal/domain/wallet"
+	digitalService "web3-ecommerce-app/internal/module/digital/service"
+	giftCardService "web3-ecommerce-app/internal/module/giftcard/service"
+	merchantService "web3-ecommerce-app/internal/module/merchant/service"
+	subscriptionService "web3-ecommerce-app/internal/module/subscription/service"
+	orderService "web3-ecommerce-app/internal/module/order/service"
+	productService "web3-ecommerce-app/internal/module/product/service"
+	promotionService "web3-ecommerce-app/internal/module/promotion/service"
+	reviewService "web3-ecommerce-app/internal/module/review/service"
+	shippingService "web3-ecommerce-app/internal/module/shipping/service"
+	taxService "web3-ecommerce-app/internal/module/tax/service"
 	userService "web3-ecommerce-app/internal/module/user/service"
+	walletService "web3-ecommerce-app/internal/module/wallet/service"
 )
 
 // AdminService 管理后台服务接口
@@ -16,21 +39,119 @@ type AdminService interface {
 	DeleteUser(ctx context.Context, id uint) error
 
 	// 产品管理
-	CreateProduct(ctx context.Context, productData map[string]interface{}) (interface{}, error)
-	ListProducts(ctx context.Context, filter admin.ProductFilter) (interface{}, error)
-	GetProduct(ctx context.Context, id uint) (interface{}, error)
-	UpdateProduct(ctx context.Context, id uint, productData map[string]interface{}) (interface{}, error)
+	CreateProduct(ctx context.Context, input product.CreateProductInput) (*product.Product, error)
+	ListProducts(ctx context.Context, filter admin.ProductFilter) (*product.ProductPaginationResult, error)
+	GetProduct(ctx context.Context, id uint) (*product.Product, error)
+	UpdateProduct(ctx context.Context, id uint, input product.UpdateProductInput) (*product.Product, error)
 	DeleteProduct(ctx context.Context, id uint) error
-	UpdateProductStatus(ctx context.Context, id uint, status string) error
+	UpdateProductStatus(ctx context.Context, id uint, input product.ChangeStatusInput) (*product.Product, error)
+	AdjustProductStock(ctx context.Context, id uint, delta int) (*product.Product, error)
+
+	// 产品图片管理
+	UploadProductImage(ctx context.Context, productID uint, body io.Reader) (*product.Image, error)
+	ListProductImages(ctx context.Context, productID uint) ([]product.Image, error)
+	ReorderProductImages(ctx context.Context, productID uint, imageIDs []uint) ([]product.Image, error)
+	SetPrimaryProductImage(ctx context.Context, productID uint, imageID uint) error
+	DeleteProductImage(ctx context.Context, productID uint, imageID uint) error
+
+	// 产品批量导入导出
+	ImportProducts(ctx context.Context, format string, dryRun bool, body io.Reader) (*product.ImportJob, error)
+	GetProductImportJob(ctx context.Context, id string) (*product.ImportJob, error)
+	ExportProducts(ctx context.Context, format string, w io.Writer) error
+
+	// 数字商品管理
+	ImportLicenseKeys(ctx context.Context, productID uint, body io.Reader) (*digital.ImportLicenseKeysResult, error)
+	GetLicenseKeyStats(ctx context.Context, productID uint) (*digital.LicenseKeyStats, error)
+	UploadDigitalAsset(ctx context.Context, productID uint, fileName string, body io.Reader) (*digital.Asset, error)
+	ListDigitalAssets(ctx context.Context, productID uint) ([]digital.Asset, error)
+	DeleteDigitalAsset(ctx context.Context, productID uint, assetID uint) error
+
+	// 产品定价管理
+	CreateProductPriceSchedule(ctx context.Context, productID uint, input product.CreatePriceScheduleInput) (*product.PriceSchedule, error)
+	ListProductPriceSchedules(ctx context.Context, productID uint) ([]product.PriceSchedule, error)
+	CancelProductPriceSchedule(ctx context.Context, productID uint, scheduleID uint) error
+	GetProductPriceHistory(ctx context.Context, productID uint, days int) ([]product.PricePoint, error)
+
+	// 持币门槛管理
+	CreateProductGateRule(ctx context.Context, productID uint, input product.CreateGateRuleInput) (*product.GateRule, error)
+	ListProductGateRules(ctx context.Context, productID uint) ([]product.GateRule, error)
+	DeleteProductGateRule(ctx context.Context, productID uint, ruleID uint) error
+
+	// 促销管理
+	CreatePromotion(ctx context.Context, input promotion.PromotionInput) (*promotion.Promotion, error)
+	ListPromotions(ctx context.Context, filter admin.PromotionFilter) (*promotion.PromotionPaginationResult, error)
+	GetPromotion(ctx context.Context, id uint) (*promotion.Promotion, error)
+	UpdatePromotion(ctx context.Context, id uint, input promotion.PromotionInput) (*promotion.Promotion, error)
+	DeletePromotion(ctx context.Context, id uint) error
+
+	// 评价审核
+	ListReviews(ctx context.Context, filter admin.ReviewFilter) (*review.ReviewPaginationResult, error)
+	ModerateReview(ctx context.Context, id uint, input review.ModerateReviewInput) (*review.Review, error)
 
 	// 订单管理
-	ListOrders(ctx context.Context, filter admin.OrderFilter) (interface{}, error)
-	GetOrder(ctx context.Context, id uint) (interface{}, error)
-	UpdateOrderStatus(ctx context.Context, id uint, status string) error
+	ListOrders(ctx context.Context, filter admin.OrderFilter) (*order.OrderPaginationResult, error)
+	GetOrder(ctx context.Context, id uint) (*order.Order, error)
+	GetOrderBySN(ctx context.Context, sn string) (*order.Order, error)
+	UpdateOrderStatus(ctx context.Context, id uint, adminID uint, input order.UpdateStatusInput) (*order.Order, error)
+	ConfirmOrderPayment(ctx context.Context, id uint, adminID uint, input order.ConfirmPaymentInput) (*order.Order, error)
+	GetOrderTimeline(ctx context.Context, id uint) ([]order.TimelineEvent, error)
+	ListLatePayments(ctx context.Context, query order.LatePaymentQuery) (*order.LatePaymentPaginationResult, error)
+	ResolveLatePayment(ctx context.Context, id uint, adminID uint, input order.ResolveLatePaymentInput) (*order.LatePayment, error)
+	ListOrderShipments(ctx context.Context, orderID uint) ([]order.Shipment, error)
+	CreateOrderShipment(ctx context.Context, orderID uint, adminID uint, input order.CreateShipmentInput) (*order.Shipment, error)
+	MarkShipmentDelivered(ctx context.Context, orderID uint, shipmentID uint, adminID uint) (*order.Shipment, error)
+	ListOrderRefunds(ctx context.Context, orderID uint) ([]order.Refund, error)
+	CreateOrderRefund(ctx context.Context, orderID uint, adminID uint, input order.CreateRefundInput) (*order.Refund, error)
+	ListOrderSubOrders(ctx context.Context, orderID uint) ([]order.SubOrder, error)
+
+	// 退货管理
+	ListReturns(ctx context.Context, query order.ReturnQuery) (*order.ReturnPaginationResult, error)
+	GetReturn(ctx context.Context, id uint) (*order.ReturnRequest, error)
+	ReviewReturn(ctx context.Context, id uint, adminID uint, input order.ReviewReturnInput) (*order.ReturnRequest, error)
+	ReceiveReturn(ctx context.Context, id uint, adminID uint, input order.ReceiveReturnInput) (*order.ReturnRequest, error)
+
+	// 商家管理
+	ListMerchants(ctx context.Context, query merchant.MerchantQuery) (*merchant.MerchantPaginationResult, error)
+	GetMerchant(ctx context.Context, id uint) (*merchant.Merchant, error)
+	ReviewMerchant(ctx context.Context, id uint, adminID uint, input merchant.ReviewInput) (*merchant.Merchant, error)
+
+	// 订阅管理
+	ListSubscriptionPlans(ctx context.Context, query subscription.PlanQuery) ([]subscription.Plan, error)
+	CreateSubscriptionPlan(ctx context.Context, input subscription.CreatePlanInput) (*subscription.Plan, error)
+	UpdateSubscriptionPlan(ctx context.Context, id uint, input subscription.UpdatePlanInput) (*subscription.Plan, error)
+	ChangeSubscriptionPlanStatus(ctx context.Context, id uint, status string) (*subscription.Plan, error)
+	ListSubscriptions(ctx context.Context, query subscription.SubscriptionQuery) (*subscription.SubscriptionPaginationResult, error)
+	CancelSubscription(ctx context.Context, id uint, adminID uint, input subscription.CancelInput) (*subscription.Subscription, error)
+
+	// 礼品卡管理
+	ListGiftCards(ctx context.Context, query giftcard.GiftCardQuery) (*giftcard.GiftCardPaginationResult, error)
+	IssueGiftCards(ctx context.Context, adminID uint, input giftcard.IssueInput) ([]giftcard.IssuedGiftCard, error)
+	GetGiftCard(ctx context.Context, id uint) (*giftcard.GiftCard, error)
+	ListGiftCardRedemptions(ctx context.Context, id uint) ([]giftcard.Redemption, error)
+	UpdateGiftCard(ctx context.Context, id uint, input giftcard.UpdateInput) (*giftcard.GiftCard, error)
+	ChangeGiftCardStatus(ctx context.Context, id uint, status string) (*giftcard.GiftCard, error)
+	ListGiftCardDenominations(ctx context.Context) ([]giftcard.Denomination, error)
+	SaveGiftCardDenomination(ctx context.Context, productID uint, input giftcard.SaveDenominationInput) (*giftcard.Denomination, error)
+	DeleteGiftCardDenomination(ctx context.Context, productID uint) error
+
+	// 配送管理
+	ListShippingZones(ctx context.Context) ([]shipping.Zone, error)
+	CreateShippingZone(ctx context.Context, input shipping.ZoneInput) (*shipping.Zone, error)
+	GetShippingZone(ctx context.Context, id uint) (*shipping.Zone, error)
+	UpdateShippingZone(ctx context.Context, id uint, input shipping.ZoneInput) (*shipping.Zone, error)
+	DeleteShippingZone(ctx context.Context, id uint) error
+
+	// 税率管理
+	ListTaxRates(ctx context.Context) ([]tax.Rate, error)
+	CreateTaxRate(ctx context.Context, input tax.RateInput) (*tax.Rate, error)
+	GetTaxRate(ctx context.Context, id uint) (*tax.Rate, error)
+	UpdateTaxRate(ctx context.Context, id uint, input tax.RateInput) (*tax.Rate, error)
+	DeleteTaxRate(ctx context.Context, id uint) error
 
 	// 支付管理
 	ListTransactions(ctx context.Context, filter admin.TransactionFilter) (interface{}, error)
-	ProcessWithdrawal(ctx context.Context, id uint) error
+	ListWithdrawals(ctx context.Context, query wallet.WithdrawalQuery) (*wallet.WithdrawalPaginationResult, error)
+	ProcessWithdrawal(ctx context.Context, id uint, adminID uint, input wallet.ProcessWithdrawalInput) (*wallet.Withdrawal, error)
 
 	// 统计数据
 	GetSystemOverview(ctx context.Context) (*admin.SystemOverview, error)
@@ -38,12 +159,25 @@ type AdminService interface {
 
 // DefaultAdminService 管理后台服务实现
 type DefaultAdminService struct {
-	adminRepository admin.AdminRepository
-	userRepository  user.UserRepository
-	userService     userService.UserService
+	adminRepository     admin.AdminRepository
+	userRepository      user.UserRepository
+	userService         userService.UserService
+	productService      productService.ProductService
+	imageService        productService.ImageService
+	catalogService      productService.CatalogService
+	priceService        productService.PriceService
+	gateService         productService.GateService
+	promotionService    promotionService.PromotionService
+	reviewService       reviewService.ReviewService
+	digitalService      digitalService.DigitalService
+	orderService        orderService.OrderService
+	shippingService     shippingService.ShippingService
+	walletService       walletService.WalletService
+	taxService          taxService.TaxService
+	merchantService     merchantService.MerchantService
+	subscriptionService subscriptionService.SubscriptionService
+	giftCardService     giftCardService.GiftCardService
 	// 以下为其他模块的服务，目前未实现
-	// productService  productService.ProductService
-	// orderService    orderService.OrderService
 	// paymentService  paymentService.PaymentService
 }
 
@@ -52,11 +186,41 @@ func NewAdminService(
 	adminRepository admin.AdminRepository,
 	userRepository user.UserRepository,
 	userService userService.UserService,
+	productService productService.ProductService,
+	imageService productService.ImageService,
+	catalogService productService.CatalogService,
+	priceService productService.PriceService,
+	gateService productService.GateService,
+	promotionService promotionService.PromotionService,
+	reviewService reviewService.ReviewService,
+	digitalService digitalService.DigitalService,
+	orderService orderService.OrderService,
+	shippingService shippingService.ShippingService,
+	walletService walletService.WalletService,
+	taxService taxService.TaxService,
+	merchantService merchantService.MerchantService,
+	subscriptionService subscriptionService.SubscriptionService,
+	giftCardService giftCardService.GiftCardService,
 ) AdminService {
 	return &DefaultAdminService{
-		adminRepository: adminRepository,
-		userRepository:  userRepository,
-		userService:     userService,
+		adminRepository:     adminRepository,
+		userRepository:      userRepository,
+		userService:         userService,
+		productService:      productService,
+		imageService:        imageService,
+		catalogService:      catalogService,
+		priceService:        priceService,
+		gateService:         gateService,
+		promotionService:    promotionService,
+		reviewService:       reviewService,
+		digitalService:      digitalService,
+		orderService:        orderService,
+		shippingService:     shippingService,
+		walletService:       walletService,
+		taxService:          taxService,
+		merchantService:     merchantService,
+		subscriptionService: subscriptionService,
+		giftCardService:     giftCardService,
 	}
 }
 
@@ -118,63 +282,446 @@ func (s *DefaultAdminService) DeleteUser(ctx context.Context, id uint) error {
 }
 
 // 以下方法是产品管理相关的接口实现
-// 由于产品服务尚未实现，这里只是提供接口定义，实际实现时需要注入产品服务
 
 // CreateProduct 创建产品
-func (s *DefaultAdminService) CreateProduct(ctx context.Context, productData map[string]interface{}) (interface{}, error) {
-	// TODO: 实现创建产品逻辑
-	return nil, nil
+func (s *DefaultAdminService) CreateProduct(ctx context.Context, input product.CreateProductInput) (*product.Product, error) {
+	return s.productService.CreateProduct(ctx, input)
 }
 
 // ListProducts 获取产品列表
-func (s *DefaultAdminService) ListProducts(ctx context.Context, filter admin.ProductFilter) (interface{}, error) {
-	// TODO: 实现获取产品列表逻辑
-	return nil, nil
+func (s *DefaultAdminService) ListProducts(ctx context.Context, filter admin.ProductFilter) (*product.ProductPaginationResult, error) {
+	query := product.ProductQuery{
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+		CategoryID: filter.CategoryID,
+		Search:     filter.Search,
+	}
+	if filter.Status != "" {
+		query.Statuses = []string{filter.Status}
+	}
+	return s.productService.ListProducts(ctx, query)
 }
 
 // GetProduct 获取产品详情
-func (s *DefaultAdminService) GetProduct(ctx context.Context, id uint) (interface{}, error) {
-	// TODO: 实现获取产品详情逻辑
-	return nil, nil
+func (s *DefaultAdminService) GetProduct(ctx context.Context, id uint) (*product.Product, error) {
+	return s.productService.GetProductByID(ctx, id)
 }
 
 // UpdateProduct 更新产品
-func (s *DefaultAdminService) UpdateProduct(ctx context.Context, id uint, productData map[string]interface{}) (interface{}, error) {
-	// TODO: 实现更新产品逻辑
-	return nil, nil
+func (s *DefaultAdminService) UpdateProduct(ctx context.Context, id uint, input product.UpdateProductInput) (*product.Product, error) {
+	return s.productService.UpdateProduct(ctx, id, input)
 }
 
 // DeleteProduct 删除产品
 func (s *DefaultAdminService) DeleteProduct(ctx context.Context, id uint) error {
-	// TODO: 实现删除产品逻辑
-	return nil
+	return s.productService.DeleteProduct(ctx, id)
 }
 
 // UpdateProductStatus 更新产品状态
-func (s *DefaultAdminService) UpdateProductStatus(ctx context.Context, id uint, status string) error {
-	// TODO: 实现更新产品状态逻辑
-	return nil
+func (s *DefaultAdminService) UpdateProductStatus(ctx context.Context, id uint, input product.ChangeStatusInput) (*product.Product, error) {
+	return s.productService.ChangeProductStatus(ctx, id, input)
 }
 
-// 以下方法是订单管理相关的接口实现
-// 由于订单服务尚未实现，这里只是提供接口定义，实际实现时需要注入订单服务
+// AdjustProductStock 调整产品库存
+func (s *DefaultAdminService) AdjustProductStock(ctx context.Context, id uint, delta int) (*product.Product, error) {
+	return s.productService.AdjustStock(ctx, id, delta)
+}
+
+// UploadProductImage 上传产品图片
+func (s *DefaultAdminService) UploadProductImage(ctx context.Context, productID uint, body io.Reader) (*product.Image, error) {
+	return s.imageService.UploadImage(ctx, productID, body)
+}
+
+// ListProductImages 获取产品图片列表
+func (s *DefaultAdminService) ListProductImages(ctx context.Context, productID uint) ([]product.Image, error) {
+	return s.imageService.ListImages(ctx, productID)
+}
+
+// ReorderProductImages 调整产品图片顺序
+func (s *DefaultAdminService) ReorderProductImages(ctx context.Context, productID uint, imageIDs []uint) ([]product.Image, error) {
+	return s.imageService.ReorderImages(ctx, productID, imageIDs)
+}
+
+// SetPrimaryProductImage 设置产品主图
+func (s *DefaultAdminService) SetPrimaryProductImage(ctx context.Context, productID uint, imageID uint) error {
+	return s.imageService.SetPrimaryImage(ctx, productID, imageID)
+}
+
+// DeleteProductImage 删除产品图片
+func (s *DefaultAdminService) DeleteProductImage(ctx context.Context, productID uint, imageID uint) error {
+	return s.imageService.DeleteImage(ctx, productID, imageID)
+}
+
+// ImportProducts 创建产品批量导入任务
+func (s *DefaultAdminService) ImportProducts(ctx context.Context, format string, dryRun bool, body io.Reader) (*product.ImportJob, error) {
+	return s.catalogService.StartImport(ctx, format, dryRun, body)
+}
+
+// GetProductImportJob 查询产品导入任务进度
+func (s *DefaultAdminService) GetProductImportJob(ctx context.Context, id string) (*product.ImportJob, error) {
+	return s.catalogService.GetImportJob(ctx, id)
+}
+
+// ExportProducts 导出全部产品
+func (s *DefaultAdminService) ExportProducts(ctx context.Context, format string, w io.Writer) error {
+	return s.catalogService.Export(ctx, format, w)
+}
+
+// ImportLicenseKeys 导入数字商品激活码
+func (s *DefaultAdminService) ImportLicenseKeys(ctx context.Context, productID uint, body io.Reader) (*digital.ImportLicenseKeysResult, error) {
+	return s.digitalService.ImportLicenseKeys(ctx, productID, body)
+}
+
+// GetLicenseKeyStats 查询激活码池的使用情况
+func (s *DefaultAdminService) GetLicenseKeyStats(ctx context.Context, productID uint) (*digital.LicenseKeyStats, error) {
+	return s.digitalService.GetLicenseKeyStats(ctx, productID)
+}
+
+// UploadDigitalAsset 上传数字商品的可下载文件
+func (s *DefaultAdminService) UploadDigitalAsset(ctx context.Context, productID uint, fileName string, body io.Reader) (*digital.Asset, error) {
+	return s.digitalService.UploadAsset(ctx, productID, fileName, body)
+}
+
+// ListDigitalAssets 获取数字商品的可下载文件列表
+func (s *DefaultAdminService) ListDigitalAssets(ctx context.Context, productID uint) ([]digital.Asset, error) {
+	return s.digitalService.ListAssets(ctx, productID)
+}
+
+// DeleteDigitalAsset 删除数字商品的可下载文件
+func (s *DefaultAdminService) DeleteDigitalAsset(ctx context.Context, productID uint, assetID uint) error {
+	return s.digitalService.DeleteAsset(ctx, productID, assetID)
+}
+
+// CreateProductPriceSchedule 创建产品的定时调价或限时促销
+func (s *DefaultAdminService) CreateProductPriceSchedule(ctx context.Context, productID uint, input product.CreatePriceScheduleInput) (*product.PriceSchedule, error) {
+	return s.priceService.CreateSchedule(ctx, productID, input)
+}
+
+// ListProductPriceSchedules 获取产品的定时调价
+func (s *DefaultAdminService) ListProductPriceSchedules(ctx context.Context, productID uint) ([]product.PriceSchedule, error) {
+	return s.priceService.ListSchedules(ctx, productID)
+}
+
+// CancelProductPriceSchedule 取消产品的定时调价
+func (s *DefaultAdminService) CancelProductPriceSchedule(ctx context.Context, productID uint, scheduleID uint) error {
+	return s.priceService.CancelSchedule(ctx, productID, scheduleID)
+}
+
+// GetProductPriceHistory 获取产品的价格历史
+func (s *DefaultAdminService) GetProductPriceHistory(ctx context.Context, productID uint, days int) ([]product.PricePoint, error) {
+	return s.priceService.GetPriceHistory(ctx, productID, days)
+}
+
+// CreateProductGateRule 为产品添加持币门槛规则
+func (s *DefaultAdminService) CreateProductGateRule(ctx context.Context, productID uint, input product.CreateGateRuleInput) (*product.GateRule, error) {
+	return s.gateService.CreateRule(ctx, productID, input)
+}
+
+// ListProductGateRules 获取产品的持币门槛规则
+func (s *DefaultAdminService) ListProductGateRules(ctx context.Context, productID uint) ([]product.GateRule, error) {
+	return s.gateService.ListRules(ctx, productID)
+}
+
+// DeleteProductGateRule 删除产品的持币门槛规则
+func (s *DefaultAdminService) DeleteProductGateRule(ctx context.Context, productID uint, ruleID uint) error {
+	return s.gateService.DeleteRule(ctx, productID, ruleID)
+}
+
+// CreatePromotion 创建促销
+func (s *DefaultAdminService) CreatePromotion(ctx context.Context, input promotion.PromotionInput) (*promotion.Promotion, error) {
+	return s.promotionService.CreatePromotion(ctx, input)
+}
+
+// ListPromotions 获取促销列表
+func (s *DefaultAdminService) ListPromotions(ctx context.Context, filter admin.PromotionFilter) (*promotion.PromotionPaginationResult, error) {
+	return s.promotionService.ListPromotions(ctx, promotion.PromotionQuery{
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		Active:   filter.Active,
+		Code:     filter.Code,
+	})
+}
+
+// GetPromotion 获取促销详情
+func (s *DefaultAdminService) GetPromotion(ctx context.Context, id uint) (*promotion.Promotion, error) {
+	return s.promotionService.GetPromotion(ctx, id)
+}
+
+// UpdatePromotion 更新促销
+func (s *DefaultAdminService) UpdatePromotion(ctx context.Context, id uint, input promotion.PromotionInput) (*promotion.Promotion, error) {
+	return s.promotionService.UpdatePromotion(ctx, id, input)
+}
+
+// DeletePromotion 删除促销
+func (s *DefaultAdminService) DeletePromotion(ctx context.Context, id uint) error {
+	return s.promotionService.DeletePromotion(ctx, id)
+}
+
+// ListReviews 获取评价列表，默认返回待审核队列
+func (s *DefaultAdminService) ListReviews(ctx context.Context, filter admin.ReviewFilter) (*review.ReviewPaginationResult, error) {
+	status := filter.Status
+	if status == "" {
+		status = review.ReviewStatusPending
+	}
+
+	return s.reviewService.ListReviews(ctx, review.ReviewQuery{
+		Page:      filter.Page,
+		PageSize:  filter.PageSize,
+		ProductID: filter.ProductID,
+		Status:    status,
+	})
+}
+
+// ModerateReview 审核评价
+func (s *DefaultAdminService) ModerateReview(ctx context.Context, id uint, input review.ModerateReviewInput) (*review.Review, error) {
+	return s.reviewService.ModerateReview(ctx, id, input)
+}
 
 // ListOrders 获取订单列表
-func (s *DefaultAdminService) ListOrders(ctx context.Context, filter admin.OrderFilter) (interface{}, error) {
-	// TODO: 实现获取订单列表逻辑
-	return nil, nil
+func (s *DefaultAdminService) ListOrders(ctx context.Context, filter admin.OrderFilter) (*order.OrderPaginationResult, error) {
+	return s.orderService.ListOrders(ctx, order.OrderQuery{
+		UserID:    filter.UserID,
+		Status:    filter.Status,
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+		Page:      filter.Page,
+		PageSize:  filter.PageSize,
+	})
 }
 
 // GetOrder 获取订单详情
-func (s *DefaultAdminService) GetOrder(ctx context.Context, id uint) (interface{}, error) {
-	// TODO: 实现获取订单详情逻辑
-	return nil, nil
+func (s *DefaultAdminService) GetOrder(ctx context.Context, id uint) (*order.Order, error) {
+	return s.orderService.GetOrderByID(ctx, id)
+}
+
+// GetOrderBySN 根据订单号获取订单详情
+func (s *DefaultAdminService) GetOrderBySN(ctx context.Context, sn string) (*order.Order, error) {
+	return s.orderService.GetOrderBySN(ctx, sn)
+}
+
+// UpdateOrderStatus 按订单状态机更新订单状态，并以管理员身份记录到订单时间线
+func (s *DefaultAdminService) UpdateOrderStatus(ctx context.Context, id uint, adminID uint, input order.UpdateStatusInput) (*order.Order, error) {
+	actor := order.Actor{Type: order.ActorAdmin, ID: adminID}
+	if input.Status == order.OrderStatusPaid {
+		payment := order.Payment{TxHash: input.TxHash, Amount: input.Amount, Note: input.Reason}
+		return s.orderService.MarkOrderAsPaid(ctx, id, actor, payment)
+	}
+	return s.orderService.UpdateStatus(ctx, id, input.Status, actor, input.Reason)
+}
+
+// ConfirmOrderPayment 以管理员身份确认订单收款，与链上支付确认走同一个入口
+func (s *DefaultAdminService) ConfirmOrderPayment(ctx context.Context, id uint, adminID uint, input order.ConfirmPaymentInput) (*order.Order, error) {
+	actor := order.Actor{Type: order.ActorAdmin, ID: adminID}
+	payment := order.Payment{TxHash: input.TxHash, Amount: input.Amount, Note: input.Note}
+	return s.orderService.MarkOrderAsPaid(ctx, id, actor, payment)
+}
+
+// GetOrderTimeline 获取订单状态变更记录
+func (s *DefaultAdminService) GetOrderTimeline(ctx context.Context, id uint) ([]order.TimelineEvent, error) {
+	return s.orderService.GetTimeline(ctx, id)
+}
+
+// ListLatePayments 获取订单关闭后才到账的付款列表
+func (s *DefaultAdminService) ListLatePayments(ctx context.Context, query order.LatePaymentQuery) (*order.LatePaymentPaginationResult, error) {
+	return s.orderService.ListLatePayments(ctx, query)
+}
+
+// ResolveLatePayment 处理逾期付款
+func (s *DefaultAdminService) ResolveLatePayment(ctx context.Context, id uint, adminID uint, input order.ResolveLatePaymentInput) (*order.LatePayment, error) {
+	return s.orderService.ResolveLatePayment(ctx, id, adminID, input)
+}
+
+// ListOrderShipments 获取订单的包裹
+func (s *DefaultAdminService) ListOrderShipments(ctx context.Context, orderID uint) ([]order.Shipment, error) {
+	return s.orderService.ListShipments(ctx, orderID)
+}
+
+// CreateOrderShipment 以管理员身份为订单创建包裹
+func (s *DefaultAdminService) CreateOrderShipment(ctx context.Context, orderID uint, adminID uint, input order.CreateShipmentInput) (*order.Shipment, error) {
+	actor := order.Actor{Type: order.ActorAdmin, ID: adminID}
+	return s.orderService.CreateShipment(ctx, orderID, actor, input)
+}
+
+// MarkShipmentDelivered 以管理员身份标记包裹已签收
+func (s *DefaultAdminService) MarkShipmentDelivered(ctx context.Context, orderID uint, shipmentID uint, adminID uint) (*order.Shipment, error) {
+	actor := order.Actor{Type: order.ActorAdmin, ID: adminID}
+	return s.orderService.MarkShipmentDelivered(ctx, orderID, shipmentID, actor)
+}
+
+// ListOrderRefunds 获取订单的退款
+func (s *DefaultAdminService) ListOrderRefunds(ctx context.Context, orderID uint) ([]order.Refund, error) {
+	return s.orderService.ListRefunds(ctx, orderID)
+}
+
+// CreateOrderRefund 以管理员身份为订单退款
+func (s *DefaultAdminService) CreateOrderRefund(ctx context.Context, orderID uint, adminID uint, input order.CreateRefundInput) (*order.Refund, error) {
+	actor := order.Actor{Type: order.ActorAdmin, ID: adminID}
+	return s.orderService.CreateRefund(ctx, orderID, actor, input)
+}
+
+// ListReturns 获取退货申请列表
+func (s *DefaultAdminService) ListReturns(ctx context.Context, query order.ReturnQuery) (*order.ReturnPaginationResult, error) {
+	return s.orderService.ListReturns(ctx, query)
+}
+
+// GetReturn 获取退货申请详情
+func (s *DefaultAdminService) GetReturn(ctx context.Context, id uint) (*order.ReturnRequest, error) {
+	return s.orderService.GetReturn(ctx, id)
+}
+
+// ReviewReturn 审核退货申请
+func (s *DefaultAdminService) ReviewReturn(ctx context.Context, id uint, adminID uint, input order.ReviewReturnInput) (*order.ReturnRequest, error) {
+	return s.orderService.ReviewReturn(ctx, id, adminID, input)
+}
+
+// ReceiveReturn 确认收到退货并退款
+func (s *DefaultAdminService) ReceiveReturn(ctx context.Context, id uint, adminID uint, input order.ReceiveReturnInput) (*order.ReturnRequest, error) {
+	return s.orderService.ReceiveReturn(ctx, id, adminID, input)
+}
+
+// ListOrderSubOrders 获取订单按商家拆分的子订单及结算情况
+func (s *DefaultAdminService) ListOrderSubOrders(ctx context.Context, orderID uint) ([]order.SubOrder, error) {
+	return s.orderService.ListSubOrders(ctx, orderID)
+}
+
+// ListMerchants 获取商家列表
+func (s *DefaultAdminService) ListMerchants(ctx context.Context, query merchant.MerchantQuery) (*merchant.MerchantPaginationResult, error) {
+	return s.merchantService.ListMerchants(ctx, query)
+}
+
+// GetMerchant 获取商家详情
+func (s *DefaultAdminService) GetMerchant(ctx context.Context, id uint) (*merchant.Merchant, error) {
+	return s.merchantService.GetMerchant(ctx, id)
+}
+
+// ReviewMerchant 审核入驻申请、暂停或恢复商家
+func (s *DefaultAdminService) ReviewMerchant(ctx context.Context, id uint, adminID uint, input merchant.ReviewInput) (*merchant.Merchant, error) {
+	return s.merchantService.ReviewMerchant(ctx, id, adminID, input)
+}
+
+// ListSubscriptionPlans 获取订阅计划列表
+func (s *DefaultAdminService) ListSubscriptionPlans(ctx context.Context, query subscription.PlanQuery) ([]subscription.Plan, error) {
+	return s.subscriptionService.ListPlans(ctx, query)
+}
+
+// CreateSubscriptionPlan 创建订阅计划
+func (s *DefaultAdminService) CreateSubscriptionPlan(ctx context.Context, input subscription.CreatePlanInput) (*subscription.Plan, error) {
+	return s.subscriptionService.CreatePlan(ctx, input)
+}
+
+// UpdateSubscriptionPlan 更新订阅计划
+func (s *DefaultAdminService) UpdateSubscriptionPlan(ctx context.Context, id uint, input subscription.UpdatePlanInput) (*subscription.Plan, error) {
+	return s.subscriptionService.UpdatePlan(ctx, id, input)
+}
+
+// ChangeSubscriptionPlanStatus 上架或归档订阅计划
+func (s *DefaultAdminService) ChangeSubscriptionPlanStatus(ctx context.Context, id uint, status string) (*subscription.Plan, error) {
+	return s.subscriptionService.ChangePlanStatus(ctx, id, status)
 }
 
-// UpdateOrderStatus 更新订单状态
-func (s *DefaultAdminService) UpdateOrderStatus(ctx context.Context, id uint, status string) error {
-	// TODO: 实现更新订单状态逻辑
-	return nil
+// ListSubscriptions 获取订阅列表
+func (s *DefaultAdminService) ListSubscriptions(ctx context.Context, query subscription.SubscriptionQuery) (*subscription.SubscriptionPaginationResult, error) {
+	return s.subscriptionService.ListSubscriptions(ctx, query)
+}
+
+// CancelSubscription 取消用户的订阅
+func (s *DefaultAdminService) CancelSubscription(ctx context.Context, id uint, adminID uint, input subscription.CancelInput) (*subscription.Subscription, error) {
+	return s.subscriptionService.CancelSubscription(ctx, id, adminID, input)
+}
+
+// ListGiftCards 获取礼品卡列表
+func (s *DefaultAdminService) ListGiftCards(ctx context.Context, query giftcard.GiftCardQuery) (*giftcard.GiftCardPaginationResult, error) {
+	return s.giftCardService.ListGiftCards(ctx, query)
+}
+
+// IssueGiftCards 发放礼品卡
+func (s *DefaultAdminService) IssueGiftCards(ctx context.Context, adminID uint, input giftcard.IssueInput) ([]giftcard.IssuedGiftCard, error) {
+	return s.giftCardService.IssueGiftCards(ctx, adminID, input)
+}
+
+// GetGiftCard 获取礼品卡详情
+func (s *DefaultAdminService) GetGiftCard(ctx context.Context, id uint) (*giftcard.GiftCard, error) {
+	return s.giftCardService.GetGiftCard(ctx, id)
+}
+
+// ListGiftCardRedemptions 获取礼品卡的兑换记录
+func (s *DefaultAdminService) ListGiftCardRedemptions(ctx context.Context, id uint) ([]giftcard.Redemption, error) {
+	return s.giftCardService.ListRedemptions(ctx, id)
+}
+
+// UpdateGiftCard 修改礼品卡的过期时间和备注
+func (s *DefaultAdminService) UpdateGiftCard(ctx context.Context, id uint, input giftcard.UpdateInput) (*giftcard.GiftCard, error) {
+	return s.giftCardService.UpdateGiftCard(ctx, id, input)
+}
+
+// ChangeGiftCardStatus 启用或停用礼品卡
+func (s *DefaultAdminService) ChangeGiftCardStatus(ctx context.Context, id uint, status string) (*giftcard.GiftCard, error) {
+	return s.giftCardService.ChangeStatus(ctx, id, status)
+}
+
+// ListGiftCardDenominations 获取礼品卡商品的面额
+func (s *DefaultAdminService) ListGiftCardDenominations(ctx context.Context) ([]giftcard.Denomination, error) {
+	return s.giftCardService.ListDenominations(ctx)
+}
+
+// SaveGiftCardDenomination 设置礼品卡商品的面额
+func (s *DefaultAdminService) SaveGiftCardDenomination(ctx context.Context, productID uint, input giftcard.SaveDenominationInput) (*giftcard.Denomination, error) {
+	return s.giftCardService.SaveDenomination(ctx, productID, input)
+}
+
+// DeleteGiftCardDenomination 取消商品的礼品卡面额
+func (s *DefaultAdminService) DeleteGiftCardDenomination(ctx context.Context, productID uint) error {
+	return s.giftCardService.DeleteDenomination(ctx, productID)
+}
+
+// ListShippingZones 获取配送区域列表
+func (s *DefaultAdminService) ListShippingZones(ctx context.Context) ([]shipping.Zone, error) {
+	return s.shippingService.ListZones(ctx)
+}
+
+// CreateShippingZone 创建配送区域
+func (s *DefaultAdminService) CreateShippingZone(ctx context.Context, input shipping.ZoneInput) (*shipping.Zone, error) {
+	return s.shippingService.CreateZone(ctx, input)
+}
+
+// GetShippingZone 获取配送区域详情
+func (s *DefaultAdminService) GetShippingZone(ctx context.Context, id uint) (*shipping.Zone, error) {
+	return s.shippingService.GetZone(ctx, id)
+}
+
+// UpdateShippingZone 更新配送区域
+func (s *DefaultAdminService) UpdateShippingZone(ctx context.Context, id uint, input shipping.ZoneInput) (*shipping.Zone, error) {
+	return s.shippingService.UpdateZone(ctx, id, input)
+}
+
+// DeleteShippingZone 删除配送区域
+func (s *DefaultAdminService) DeleteShippingZone(ctx context.Context, id uint) error {
+	return s.shippingService.DeleteZone(ctx, id)
+}
+
+// ListTaxRates 获取税率列表
+func (s *DefaultAdminService) ListTaxRates(ctx context.Context) ([]tax.Rate, error) {
+	return s.taxService.ListRates(ctx)
+}
+
+// CreateTaxRate 创建税率
+func (s *DefaultAdminService) CreateTaxRate(ctx context.Context, input tax.RateInput) (*tax.Rate, error) {
+	return s.taxService.CreateRate(ctx, input)
+}
+
+// GetTaxRate 获取税率详情
+func (s *DefaultAdminService) GetTaxRate(ctx context.Context, id uint) (*tax.Rate, error) {
+	return s.taxService.GetRate(ctx, id)
+}
+
+// UpdateTaxRate 更新税率
+func (s *DefaultAdminService) UpdateTaxRate(ctx context.Context, id uint, input tax.RateInput) (*tax.Rate, error) {
+	return s.taxService.UpdateRate(ctx, id, input)
+}
+
+// DeleteTaxRate 删除税率
+func (s *DefaultAdminService) DeleteTaxRate(ctx context.Context, id uint) error {
+	return s.taxService.DeleteRate(ctx, id)
 }
 
 // 以下方法是支付管理相关的接口实现
@@ -186,10 +733,14 @@ func (s *DefaultAdminService) ListTransactions(ctx context.Context, filter admin
 	return nil, nil
 }
 
-// ProcessWithdrawal 处理提现
-func (s *DefaultAdminService) ProcessWithdrawal(ctx context.Context, id uint) error {
-	// TODO: 实现处理提现逻辑
-	return nil
+// ListWithdrawals 获取提现单列表
+func (s *DefaultAdminService) ListWithdrawals(ctx context.Context, query wallet.WithdrawalQuery) (*wallet.WithdrawalPaginationResult, error) {
+	return s.walletService.ListWithdrawals(ctx, query)
+}
+
+// ProcessWithdrawal 记录提现的链上转账结果，转账失败时金额退回用户余额
+func (s *DefaultAdminService) ProcessWithdrawal(ctx context.Context, id uint, adminID uint, input wallet.ProcessWithdrawalInput) (*wallet.Withdrawal, error) {
+	return s.walletService.ProcessWithdrawal(ctx, id, adminID, input)
 }
 
 // GetSystemOverview 获取系统概览
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/cart"
+	"web3-ecommerce-app/internal/module/cart/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cartCookieName 保存游客购物车令牌的Cookie
+const cartCookieName = "cart_token"
+
+// CartHTTPHandler 购物车HTTP处理器
+type CartHTTPHandler struct {
+	cartService service.CartService
+	cfg         *config.CartConfig
+}
+
+// NewCartHTTPHandler 创建购物车HTTP处理器
+func NewCartHTTPHandler(cartService service.CartService, cfg *config.CartConfig) *CartHTTPHandler {
+	return &CartHTTPHandler{
+		cartService: cartService,
+		cfg:         cfg,
+	}
+}
+
+// GetCart 获取购物车
+func (h *CartHTTPHandler) GetCart(c *gin.Context) {
+	owner := h.resolveOwner(c)
+
+	view, err := h.cartService.GetCart(c.Request.Context(), owner)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// AddItem 将商品加入购物车
+func (h *CartHTTPHandler) AddItem(c *gin.Context) {
+	var input cart.SetItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	owner := h.resolveOwner(c)
+	view, err := h.cartService.AddItem(c.Request.Context(), owner, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	h.setGuestCookie(c, owner, view)
+
+	c.JSON(http.StatusOK, view)
+}
+
+// UpdateItem 修改购物车中商品的数量
+func (h *CartHTTPHandler) UpdateItem(c *gin.Context) {
+	productID, ok := h.getProductID(c)
+	if !ok {
+		return
+	}
+
+	var input cart.UpdateItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	owner := h.resolveOwner(c)
+	view, err := h.cartService.UpdateItem(c.Request.Context(), owner, productID, input.Quantity)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	h.setGuestCookie(c, owner, view)
+
+	c.JSON(http.StatusOK, view)
+}
+
+// RemoveItem 从购物车移除商品
+func (h *CartHTTPHandler) RemoveItem(c *gin.Context) {
+	productID, ok := h.getProductID(c)
+	if !ok {
+		return
+	}
+
+	owner := h.resolveOwner(c)
+	view, err := h.cartService.RemoveItem(c.Request.Context(), owner, productID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// ClearCart 清空购物车
+func (h *CartHTTPHandler) ClearCart(c *gin.Context) {
+	owner := h.resolveOwner(c)
+	if err := h.cartService.Clear(c.Request.Context(), owner); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "购物车已清空"})
+}
+
+// resolveOwner 确定当前请求的购物车
+// 登录用户的请求带有游客Cookie时，先把游客购物车合并到用户购物车再删除Cookie，
+// 因此登录后的第一次购物车请求就会完成合并
+func (h *CartHTTPHandler) resolveOwner(c *gin.Context) cart.Owner {
+	token, _ := c.Cookie(cartCookieName)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return cart.Owner{Token: token}
+	}
+
+	owner := cart.Owner{UserID: userID.(uint)}
+	if token != "" {
+		if err := h.cartService.Merge(c.Request.Context(), owner.UserID, token); err != nil {
+			// 合并失败时保留Cookie，下次请求重试
+			log.Printf("合并游客购物车到用户 %d 失败: %v", owner.UserID, err)
+		} else {
+			h.writeCookie(c, "", -1)
+		}
+	}
+	return owner
+}
+
+// setGuestCookie 写入游客购物车令牌，每次修改都会延长Cookie的有效期
+func (h *CartHTTPHandler) setGuestCookie(c *gin.Context, owner cart.Owner, view *cart.View) {
+	if !owner.IsGuest() || view.Token == "" {
+		return
+	}
+	h.writeCookie(c, view.Token, int(h.cfg.GuestTTL.Seconds()))
+}
+
+// writeCookie 写入或删除(maxAge为负数)游客购物车Cookie
+func (h *CartHTTPHandler) writeCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(cartCookieName, value, maxAge, "/", "", h.cfg.CookieSecure, true)
+}
+
+// getProductID 解析路径中的商品ID，失败时直接写入错误响应
+func (h *CartHTTPHandler) getProductID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("product_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的商品ID", err.Error()),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// handleError 处理错误
+func (h *CartHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/cart"
+	"web3-ecommerce-app/internal/domain/common"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CartModel 是GORM购物车模型
+// 用户购物车的Token为空，游客购物车的UserID为空，唯一索引允许多个NULL
+type CartModel struct {
+	ID        uint       `gorm:"primarykey"`
+	UserID    *uint      `gorm:"uniqueIndex:idx_user_id"`
+	Token     *string    `gorm:"type:varchar(64);uniqueIndex:idx_token"`
+	ExpiresAt *time.Time `gorm:"index:idx_expires_at"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName 指定表名
+func (CartModel) TableName() string {
+	return "carts"
+}
+
+// CartItemModel 是GORM购物车商品模型
+type CartItemModel struct {
+	ID        uint  `gorm:"primarykey"`
+	CartID    uint  `gorm:"not null;uniqueIndex:idx_cart_product,priority:1"`
+	ProductID uint  `gorm:"not null;uniqueIndex:idx_cart_product,priority:2"`
+	Quantity  int   `gorm:"not null"`
+	UnitPrice int64 `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName 指定表名
+func (CartItemModel) TableName() string {
+	return "cart_items"
+}
+
+// GormCartRepository 是购物车仓库的GORM实现
+type GormCartRepository struct {
+	db *gorm.DB
+}
+
+// NewGormCartRepository 创建一个新的GORM购物车仓库
+func NewGormCartRepository(db *gorm.DB) cart.CartRepository {
+	return &GormCartRepository{db: db}
+}
+
+// FindByUser 查询用户的购物车
+func (r *GormCartRepository) FindByUser(ctx context.Context, userID uint) (*cart.Cart, error) {
+	return r.findOne(ctx, r.db.WithContext(ctx).Where("user_id = ?", userID))
+}
+
+// FindByToken 查询未过期的游客购物车
+func (r *GormCartRepository) FindByToken(ctx context.Context, token string, now time.Time) (*cart.Cart, error) {
+	return r.findOne(ctx, r.db.WithContext(ctx).Where("token = ? AND expires_at > ?", token, now))
+}
+
+// Create 创建空购物车
+// 并发请求同时为用户创建购物车时，后到的请求得到已存在的购物车
+func (r *GormCartRepository) Create(ctx context.Context, c *cart.Cart) error {
+	model := CartModel{UserID: c.UserID, ExpiresAt: c.ExpiresAt}
+	if c.Token != "" {
+		model.Token = &c.Token
+	}
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&model)
+	if result.Error != nil {
+		return fmt.Errorf("创建购物车错误: %w", result.Error)
+	}
+	if result.RowsAffected == 0 && c.UserID != nil {
+		existing, err := r.FindByUser(ctx, *c.UserID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			*c = *existing
+			return nil
+		}
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("创建购物车错误: 购物车已存在")
+	}
+
+	c.ID = model.ID
+	c.Items = []cart.Item{}
+	c.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+// SetItem 设置商品数量和单价
+func (r *GormCartRepository) SetItem(ctx context.Context, cartID uint, item cart.Item, expiresAt *time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model := CartItemModel{
+			CartID:    cartID,
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: int64(item.UnitPrice),
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.AssignmentColumns([]string{"quantity", "unit_price", "updated_at"}),
+		}).Create(&model).Error; err != nil {
+			return fmt.Errorf("更新购物车商品错误: %w", err)
+		}
+		return touch(tx, cartID, expiresAt)
+	})
+}
+
+// RemoveItem 从购物车移除商品
+func (r *GormCartRepository) RemoveItem(ctx context.Context, cartID uint, productID uint) (bool, error) {
+	var removed bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("cart_id = ? AND product_id = ?", cartID, productID).Delete(&CartItemModel{})
+		if result.Error != nil {
+			return fmt.Errorf("移除购物车商品错误: %w", result.Error)
+		}
+		removed = result.RowsAffected > 0
+		return touch(tx, cartID, nil)
+	})
+	return removed, err
+}
+
+// Clear 清空购物车
+func (r *GormCartRepository) Clear(ctx context.Context, cartID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cart_id = ?", cartID).Delete(&CartItemModel{}).Error; err != nil {
+			return fmt.Errorf("清空购物车错误: %w", err)
+		}
+		return touch(tx, cartID, nil)
+	})
+}
+
+// UpdatePrices 更新商品单价
+func (r *GormCartRepository) UpdatePrices(ctx context.Context, cartID uint, prices map[uint]common.Money) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for productID, price := range prices {
+			if err := tx.Model(&CartItemModel{}).
+				Where("cart_id = ? AND product_id = ?", cartID, productID).
+				Update("unit_price", int64(price)).Error; err != nil {
+				return fmt.Errorf("更新购物车价格错误: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Merge 将游客购物车合并到用户购物车
+// 锁定游客购物车后再合并，同一个游客购物车被并发合并时只有第一次生效
+func (r *GormCartRepository) Merge(ctx context.Context, fromID uint, toID uint, maxQuantity int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var from CartModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&from, fromID).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("查询购物车错误: %w", err)
+		}
+
+		var fromItems []CartItemModel
+		if err := tx.Where("cart_id = ?", fromID).Find(&fromItems).Error; err != nil {
+			return fmt.Errorf("查询购物车商品错误: %w", err)
+		}
+
+		for _, item := range fromItems {
+			var existing CartItemModel
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("cart_id = ? AND product_id = ?", toID, item.ProductID).
+				First(&existing).Error
+			switch {
+			case err == gorm.ErrRecordNotFound:
+				merged := CartItemModel{
+					CartID:    toID,
+					ProductID: item.ProductID,
+					Quantity:  min(item.Quantity, maxQuantity),
+					UnitPrice: item.UnitPrice,
+				}
+				if err := tx.Create(&merged).Error; err != nil {
+					return fmt.Errorf("合并购物车错误: %w", err)
+				}
+			case err != nil:
+				return fmt.Errorf("查询购物车商品错误: %w", err)
+			default:
+				if err := tx.Model(&existing).
+					Update("quantity", min(existing.Quantity+item.Quantity, maxQuantity)).Error; err != nil {
+					return fmt.Errorf("合并购物车错误: %w", err)
+				}
+			}
+		}
+
+		if err := tx.Where("cart_id = ?", fromID).Delete(&CartItemModel{}).Error; err != nil {
+			return fmt.Errorf("删除游客购物车错误: %w", err)
+		}
+		if err := tx.Delete(&CartModel{}, fromID).Error; err != nil {
+			return fmt.Errorf("删除游客购物车错误: %w", err)
+		}
+		return touch(tx, toID, nil)
+	})
+}
+
+// DeleteExpired 删除已过期的游客购物车
+func (r *GormCartRepository) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	var ids []uint
+	if err := r.db.WithContext(ctx).Model(&CartModel{}).
+		Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+		Order("id").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("查询过期购物车错误: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cart_id IN ?", ids).Delete(&CartItemModel{}).Error; err != nil {
+			return fmt.Errorf("删除过期购物车错误: %w", err)
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&CartModel{}).Error; err != nil {
+			return fmt.Errorf("删除过期购物车错误: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormCartRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&CartModel{}, &CartItemModel{})
+}
+
+// findOne 按条件查询一个购物车及其商品
+func (r *GormCartRepository) findOne(ctx context.Context, query *gorm.DB) (*cart.Cart, error) {
+	var model CartModel
+	err := query.First(&model).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询购物车错误: %w", err)
+	}
+
+	var items []CartItemModel
+	if err := r.db.WithContext(ctx).Where("cart_id = ?", model.ID).Order("id").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("查询购物车商品错误: %w", err)
+	}
+
+	c := &cart.Cart{
+		ID:        model.ID,
+		UserID:    model.UserID,
+		ExpiresAt: model.ExpiresAt,
+		Items:     make([]cart.Item, 0, len(items)),
+		UpdatedAt: model.UpdatedAt,
+	}
+	if model.Token != nil {
+		c.Token = *model.Token
+	}
+	for _, item := range items {
+		c.Items = append(c.Items, cart.Item{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: common.Money(item.UnitPrice),
+			CreatedAt: item.CreatedAt,
+		})
+	}
+	return c, nil
+}
+
+// touch 更新购物车的修改时间，expiresAt不为空时同时延长有效期
+func touch(tx *gorm.DB, cartID uint, expiresAt *time.Time) error {
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	if expiresAt != nil {
+		updates["expires_at"] = *expiresAt
+	}
+	if err := tx.Model(&CartModel{}).Where("id = ?", cartID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("更新购物车错误: %w", err)
+	}
+	return nil
+}
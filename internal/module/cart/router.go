@@ -0,0 +1,35 @@
+package cart
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/cart/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册购物车模块路由
+func RegisterRoutes(router *gin.Engine, handler *handler.CartHTTPHandler, jwtConfig *config.JWTConfig) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 购物车(游客和登录用户都可以使用)
+	cartRoutes := v1.Group("/cart")
+	cartRoutes.Use(middleware.OptionalJWT(jwtConfig))
+	{
+		// 获取购物车
+		cartRoutes.GET("", handler.GetCart)
+
+		// 清空购物车
+		cartRoutes.DELETE("", handler.ClearCart)
+
+		// 加入购物车
+		cartRoutes.POST("/items", handler.AddItem)
+
+		// 修改商品数量
+		cartRoutes.PUT("/items/:product_id", handler.UpdateItem)
+
+		// 移出购物车
+		cartRoutes.DELETE("/items/:product_id", handler.RemoveItem)
+	}
+}
@@ -0,0 +1,416 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/cart"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/platform/cache"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// cleanupBatchSize 每次删除过期购物车的最大数量
+const cleanupBatchSize = 500
+
+// CartService 购物车服务接口
+type CartService interface {
+	// GetCart 按商品最新价格和库存获取购物车
+	GetCart(ctx context.Context, owner cart.Owner) (*cart.View, error)
+
+	// AddItem 将商品加入购物车，已在购物车中时增加数量
+	AddItem(ctx context.Context, owner cart.Owner, input cart.SetItemInput) (*cart.View, error)
+
+	// UpdateItem 修改购物车中商品的数量
+	UpdateItem(ctx context.Context, owner cart.Owner, productID uint, quantity int) (*cart.View, error)
+
+	// RemoveItem 从购物车移除商品
+	RemoveItem(ctx context.Context, owner cart.Owner, productID uint) (*cart.View, error)
+
+	// Clear 清空购物车
+	Clear(ctx context.Context, owner cart.Owner) error
+
+	// RemoveProducts 下单后从购物车移除已购买的商品
+	RemoveProducts(ctx context.Context, owner cart.Owner, productIDs []uint) error
+
+	// Merge 登录后将游客购物车合并到用户购物车
+	Merge(ctx context.Context, userID uint, token string) error
+
+	// CleanupExpired 删除过期的游客购物车，由定时任务调用
+	CleanupExpired(ctx context.Context) error
+}
+
+// DefaultCartService 默认购物车服务实现
+type DefaultCartService struct {
+	cartRepo    cart.CartRepository
+	productRepo product.ProductRepository
+	cache       cache.Cache
+	cfg         *config.CartConfig
+}
+
+// NewCartService 创建购物车服务
+func NewCartService(cartRepo cart.CartRepository, productRepo product.ProductRepository, cache cache.Cache, cfg *config.CartConfig) CartService {
+	return &DefaultCartService{
+		cartRepo:    cartRepo,
+		productRepo: productRepo,
+		cache:       cache,
+		cfg:         cfg,
+	}
+}
+
+// GetCart 获取购物车，没有购物车时返回空购物车
+func (s *DefaultCartService) GetCart(ctx context.Context, owner cart.Owner) (*cart.View, error) {
+	c, err := s.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return &cart.View{Items: []cart.Line{}}, nil
+	}
+	return s.view(ctx, owner, c)
+}
+
+// AddItem 将商品加入购物车
+func (s *DefaultCartService) AddItem(ctx context.Context, owner cart.Owner, input cart.SetItemInput) (*cart.View, error) {
+	c, err := s.loadOrCreate(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	// 游客购物车可能是新建的，后续读写使用新令牌
+	owner.Token = c.Token
+
+	quantity := input.Quantity
+	if item := findItem(c, input.ProductID); item != nil {
+		quantity += item.Quantity
+	}
+	return s.setItem(ctx, owner, c, input.ProductID, quantity)
+}
+
+// UpdateItem 修改购物车中商品的数量
+func (s *DefaultCartService) UpdateItem(ctx context.Context, owner cart.Owner, productID uint, quantity int) (*cart.View, error) {
+	c, err := s.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || findItem(c, productID) == nil {
+		return nil, apierror.NewNotFoundError("购物车中没有该商品", fmt.Sprintf("商品ID: %d", productID))
+	}
+	return s.setItem(ctx, owner, c, productID, quantity)
+}
+
+// RemoveItem 从购物车移除商品
+func (s *DefaultCartService) RemoveItem(ctx context.Context, owner cart.Owner, productID uint) (*cart.View, error) {
+	c, err := s.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, apierror.NewNotFoundError("购物车中没有该商品", fmt.Sprintf("商品ID: %d", productID))
+	}
+
+	ok, err := s.cartRepo.RemoveItem(ctx, c.ID, productID)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, owner)
+	if !ok {
+		return nil, apierror.NewNotFoundError("购物车中没有该商品", fmt.Sprintf("商品ID: %d", productID))
+	}
+	return s.GetCart(ctx, owner)
+}
+
+// Clear 清空购物车
+func (s *DefaultCartService) Clear(ctx context.Context, owner cart.Owner) error {
+	c, err := s.load(ctx, owner)
+	if err != nil || c == nil {
+		return err
+	}
+
+	if err := s.cartRepo.Clear(ctx, c.ID); err != nil {
+		return err
+	}
+	s.invalidate(ctx, owner)
+	return nil
+}
+
+// RemoveProducts 从购物车移除多个商品，不在购物车中的商品会被忽略
+func (s *DefaultCartService) RemoveProducts(ctx context.Context, owner cart.Owner, productIDs []uint) error {
+	c, err := s.load(ctx, owner)
+	if err != nil || c == nil {
+		return err
+	}
+
+	for _, productID := range productIDs {
+		if findItem(c, productID) == nil {
+			continue
+		}
+		if _, err := s.cartRepo.RemoveItem(ctx, c.ID, productID); err != nil {
+			s.invalidate(ctx, owner)
+			return err
+		}
+	}
+	s.invalidate(ctx, owner)
+	return nil
+}
+
+// Merge 将游客购物车合并到用户购物车
+// 两边都有的商品数量相加；合并后的价格、库存问题在查看购物车时提示
+func (s *DefaultCartService) Merge(ctx context.Context, userID uint, token string) error {
+	guest, err := s.cartRepo.FindByToken(ctx, token, time.Now())
+	if err != nil || guest == nil {
+		return err
+	}
+
+	userOwner := cart.Owner{UserID: userID}
+	userCart, err := s.loadOrCreate(ctx, userOwner)
+	if err != nil {
+		return err
+	}
+
+	if err := s.cartRepo.Merge(ctx, guest.ID, userCart.ID, s.cfg.MaxQuantity); err != nil {
+		return err
+	}
+	s.invalidate(ctx, userOwner, cart.Owner{Token: token})
+	return nil
+}
+
+// CleanupExpired 分批删除过期的游客购物车
+func (s *DefaultCartService) CleanupExpired(ctx context.Context) error {
+	total := 0
+	for {
+		n, err := s.cartRepo.DeleteExpired(ctx, time.Now(), cleanupBatchSize)
+		total += n
+		if err != nil {
+			return err
+		}
+		if n < cleanupBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		log.Printf("已删除 %d 个过期的游客购物车", total)
+	}
+	return nil
+}
+
+// setItem 校验商品和库存后写入数量，单价更新为当前售价
+func (s *DefaultCartService) setItem(ctx context.Context, owner cart.Owner, c *cart.Cart, productID uint, quantity int) (*cart.View, error) {
+	if findItem(c, productID) == nil && len(c.Items) >= s.cfg.MaxItems {
+		return nil, apierror.NewValidationError("购物车已满", fmt.Sprintf("最多添加 %d 种商品", s.cfg.MaxItems))
+	}
+	if quantity > s.cfg.MaxQuantity {
+		return nil, apierror.NewValidationError("超过购买数量上限", fmt.Sprintf("单个商品最多购买 %d 件", s.cfg.MaxQuantity))
+	}
+
+	productEntity, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !productEntity.IsVisible() {
+		return nil, apierror.NewNotFoundError("商品不存在", fmt.Sprintf("ID: %d", productID))
+	}
+	if available := productEntity.AvailableStock(); quantity > available {
+		return nil, apierror.NewInsufficientStockError("库存不足", fmt.Sprintf("商品 %d 可售库存 %d", productID, available))
+	}
+
+	err = s.cartRepo.SetItem(ctx, c.ID, cart.Item{
+		ProductID: productID,
+		Quantity:  quantity,
+		UnitPrice: productEntity.Price,
+	}, s.guestExpiry(owner))
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, owner)
+	return s.GetCart(ctx, owner)
+}
+
+// view 按商品最新信息计算购物车
+// 价格变化的商品在本次返回中带上原价格，随后记录新价格，同一次变化只提示一次
+func (s *DefaultCartService) view(ctx context.Context, owner cart.Owner, c *cart.Cart) (*cart.View, error) {
+	ids := make([]uint, 0, len(c.Items))
+	for _, item := range c.Items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*product.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	v := &cart.View{
+		Items:     make([]cart.Line, 0, len(c.Items)),
+		UpdatedAt: c.UpdatedAt,
+		Token:     c.Token,
+	}
+	changed := make(map[uint]common.Money)
+	for _, item := range c.Items {
+		line := cart.Line{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		}
+
+		p, ok := byID[item.ProductID]
+		switch {
+		case !ok || !p.IsVisible():
+			line.Issue = cart.IssueUnavailable
+		default:
+			line.Product = p
+			line.UnitPrice = p.Price
+			line.Available = p.AvailableStock()
+			if p.Price != item.UnitPrice {
+				previous := item.UnitPrice
+				line.PreviousPrice = &previous
+				changed[item.ProductID] = p.Price
+			}
+			if line.Available <= 0 {
+				line.Issue = cart.IssueOutOfStock
+			} else if item.Quantity > line.Available {
+				line.Issue = cart.IssueInsufficientStock
+			}
+		}
+
+		line.LineTotal = line.UnitPrice.Mul(line.Quantity)
+		if line.Issue == "" {
+			v.Subtotal += line.LineTotal
+			v.ItemCount += line.Quantity
+		}
+		v.Items = append(v.Items, line)
+	}
+
+	v.Checkout = len(v.Items) > 0
+	for _, line := range v.Items {
+		if line.Issue != "" {
+			v.Checkout = false
+		}
+	}
+
+	if len(changed) > 0 {
+		if err := s.cartRepo.UpdatePrices(ctx, c.ID, changed); err != nil {
+			log.Printf("更新购物车 %d 的价格失败: %v", c.ID, err)
+		} else {
+			s.invalidate(ctx, owner)
+		}
+	}
+	return v, nil
+}
+
+// load 读取购物车，优先读缓存，没有购物车时返回nil
+func (s *DefaultCartService) load(ctx context.Context, owner cart.Owner) (*cart.Cart, error) {
+	if owner.IsGuest() && owner.Token == "" {
+		return nil, nil
+	}
+
+	key := cacheKey(owner)
+	now := time.Now()
+	if data, ok, err := s.cache.Get(ctx, key); err != nil {
+		log.Printf("读取购物车缓存失败: %v", err)
+	} else if ok {
+		var c cart.Cart
+		if err := json.Unmarshal(data, &c); err == nil && (c.ExpiresAt == nil || c.ExpiresAt.After(now)) {
+			c.Token = owner.Token
+			return &c, nil
+		}
+	}
+
+	var c *cart.Cart
+	var err error
+	if owner.IsGuest() {
+		c, err = s.cartRepo.FindByToken(ctx, owner.Token, now)
+	} else {
+		c, err = s.cartRepo.FindByUser(ctx, owner.UserID)
+	}
+	if err != nil || c == nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(c); err == nil {
+		if err := s.cache.Set(ctx, key, data, s.cfg.CacheTTL); err != nil {
+			log.Printf("写入购物车缓存失败: %v", err)
+		}
+	}
+	return c, nil
+}
+
+// loadOrCreate 读取购物车，没有时创建
+// 游客的令牌不存在或已过期时生成新令牌，不沿用客户端提交的令牌
+func (s *DefaultCartService) loadOrCreate(ctx context.Context, owner cart.Owner) (*cart.Cart, error) {
+	c, err := s.load(ctx, owner)
+	if err != nil || c != nil {
+		return c, err
+	}
+
+	c = &cart.Cart{}
+	if owner.IsGuest() {
+		token, err := newToken()
+		if err != nil {
+			return nil, err
+		}
+		c.Token = token
+		c.ExpiresAt = s.guestExpiry(owner)
+	} else {
+		c.UserID = &owner.UserID
+	}
+	if err := s.cartRepo.Create(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// guestExpiry 游客购物车从现在起的过期时间，用户购物车不过期
+func (s *DefaultCartService) guestExpiry(owner cart.Owner) *time.Time {
+	if !owner.IsGuest() {
+		return nil
+	}
+	expiresAt := time.Now().Add(s.cfg.GuestTTL)
+	return &expiresAt
+}
+
+// invalidate 删除购物车缓存，失败时只记录日志，缓存会在过期后失效
+func (s *DefaultCartService) invalidate(ctx context.Context, owners ...cart.Owner) {
+	keys := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		keys = append(keys, cacheKey(owner))
+	}
+	if err := s.cache.Delete(ctx, keys...); err != nil {
+		log.Printf("删除购物车缓存失败: %v", err)
+	}
+}
+
+// cacheKey 购物车的缓存键
+func cacheKey(owner cart.Owner) string {
+	if owner.IsGuest() {
+		return "cart:token:" + owner.Token
+	}
+	return "cart:user:" + strconv.FormatUint(uint64(owner.UserID), 10)
+}
+
+// findItem 查找购物车中的商品
+func findItem(c *cart.Cart, productID uint) *cart.Item {
+	for i := range c.Items {
+		if c.Items[i].ProductID == productID {
+			return &c.Items[i]
+		}
+	}
+	return nil
+}
+
+// newToken 生成游客购物车令牌
+func newToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成购物车令牌失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
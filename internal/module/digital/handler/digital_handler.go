@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"web3-ecommerce-app/internal/module/digital/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DigitalHTTPHandler 数字商品发放HTTP处理器
+type DigitalHTTPHandler struct {
+	digitalService service.DigitalService
+}
+
+// NewDigitalHTTPHandler 创建数字商品发放HTTP处理器
+func NewDigitalHTTPHandler(digitalService service.DigitalService) *DigitalHTTPHandler {
+	return &DigitalHTTPHandler{
+		digitalService: digitalService,
+	}
+}
+
+// GetOrderDelivery 获取订单已发放的激活码和下载链接
+func (h *DigitalHTTPHandler) GetOrderDelivery(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+		})
+		return
+	}
+
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的订单ID", err.Error()),
+		})
+		return
+	}
+
+	delivery, err := h.digitalService.GetDelivery(c.Request.Context(), uint(orderID), userID.(uint))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Download 通过签名链接下载数字文件，链接本身即为凭证，不需要登录
+func (h *DigitalHTTPHandler) Download(c *gin.Context) {
+	grantID, err := strconv.ParseUint(c.Param("grant_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的下载链接", err.Error()),
+		})
+		return
+	}
+	assetID, err := strconv.ParseUint(c.Param("asset_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的下载链接", err.Error()),
+		})
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的下载链接", err.Error()),
+		})
+		return
+	}
+
+	asset, body, err := h.digitalService.Download(c.Request.Context(), uint(grantID), uint(assetID), expires, c.Query("signature"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer body.Close()
+
+	c.Header("Content-Type", asset.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(asset.Size, 10))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(asset.FileName)))
+	c.Header("Cache-Control", "private, no-store")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, body); err != nil {
+		log.Printf("发送数字文件 %d 失败: %v", asset.ID, err)
+	}
+}
+
+// handleError 处理错误
+func (h *DigitalHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
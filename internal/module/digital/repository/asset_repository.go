@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"web3-ecommerce-app/internal/domain/digital"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+)
+
+// AssetModel 是GORM数字文件模型
+type AssetModel struct {
+	gorm.Model
+	ProductID   uint   `gorm:"not null;index:idx_product_id"`
+	Key         string `gorm:"type:varchar(255);not null"`
+	FileName    string `gorm:"type:varchar(255);not null"`
+	ContentType string `gorm:"type:varchar(100);not null"`
+	Size        int64  `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (AssetModel) TableName() string {
+	return "digital_assets"
+}
+
+// GormAssetRepository 是数字文件仓库的GORM实现
+type GormAssetRepository struct {
+	db *gorm.DB
+}
+
+// NewGormAssetRepository 创建一个新的GORM数字文件仓库
+func NewGormAssetRepository(db *gorm.DB) digital.AssetRepository {
+	return &GormAssetRepository{db: db}
+}
+
+// assetToDomain 将GORM模型转换为领域模型
+func assetToDomain(m *AssetModel) *digital.Asset {
+	return &digital.Asset{
+		ID:          m.ID,
+		ProductID:   m.ProductID,
+		Key:         m.Key,
+		FileName:    m.FileName,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// FindByID 根据ID查找文件
+func (r *GormAssetRepository) FindByID(ctx context.Context, id uint) (*digital.Asset, error) {
+	var model AssetModel
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("文件不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询数字文件错误: %w", err)
+	}
+	return assetToDomain(&model), nil
+}
+
+// FindByProduct 查询商品的全部文件
+func (r *GormAssetRepository) FindByProduct(ctx context.Context, productID uint) ([]digital.Asset, error) {
+	var models []AssetModel
+	if err := database.Conn(ctx, r.db).Where("product_id = ?", productID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询数字文件错误: %w", err)
+	}
+
+	assets := make([]digital.Asset, 0, len(models))
+	for i := range models {
+		assets = append(assets, *assetToDomain(&models[i]))
+	}
+	return assets, nil
+}
+
+// Create 创建文件记录
+func (r *GormAssetRepository) Create(ctx context.Context, asset *digital.Asset) error {
+	model := &AssetModel{
+		ProductID:   asset.ProductID,
+		Key:         asset.Key,
+		FileName:    asset.FileName,
+		ContentType: asset.ContentType,
+		Size:        asset.Size,
+	}
+	if err := database.Conn(ctx, r.db).Create(model).Error; err != nil {
+		return fmt.Errorf("创建数字文件错误: %w", err)
+	}
+
+	// 更新领域模型
+	asset.ID = model.ID
+	asset.CreatedAt = model.CreatedAt
+
+	return nil
+}
+
+// Delete 删除文件记录
+func (r *GormAssetRepository) Delete(ctx context.Context, id uint) error {
+	if err := database.Conn(ctx, r.db).Delete(&AssetModel{}, id).Error; err != nil {
+		return fmt.Errorf("删除数字文件错误: %w", err)
+	}
+	return nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormAssetRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&AssetModel{})
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"web3-ecommerce-app/internal/domain/digital"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DownloadGrantModel 是GORM下载授权模型
+type DownloadGrantModel struct {
+	gorm.Model
+	OrderID      uint `gorm:"not null;uniqueIndex:idx_order_product,priority:1"`
+	UserID       uint `gorm:"not null;index:idx_user_id"`
+	ProductID    uint `gorm:"not null;uniqueIndex:idx_order_product,priority:2"`
+	Downloads    int  `gorm:"not null;default:0"`
+	MaxDownloads int  `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (DownloadGrantModel) TableName() string {
+	return "download_grants"
+}
+
+// GormDownloadGrantRepository 是下载授权仓库的GORM实现
+type GormDownloadGrantRepository struct {
+	db *gorm.DB
+}
+
+// NewGormDownloadGrantRepository 创建一个新的GORM下载授权仓库
+func NewGormDownloadGrantRepository(db *gorm.DB) digital.DownloadGrantRepository {
+	return &GormDownloadGrantRepository{db: db}
+}
+
+// downloadGrantToDomain 将GORM模型转换为领域模型
+func downloadGrantToDomain(m *DownloadGrantModel) *digital.DownloadGrant {
+	return &digital.DownloadGrant{
+		ID:           m.ID,
+		OrderID:      m.OrderID,
+		UserID:       m.UserID,
+		ProductID:    m.ProductID,
+		Downloads:    m.Downloads,
+		MaxDownloads: m.MaxDownloads,
+		CreatedAt:    m.CreatedAt,
+	}
+}
+
+// FindByID 根据ID查找下载授权
+func (r *GormDownloadGrantRepository) FindByID(ctx context.Context, id uint) (*digital.DownloadGrant, error) {
+	var model DownloadGrantModel
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("下载授权不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询下载授权错误: %w", err)
+	}
+	return downloadGrantToDomain(&model), nil
+}
+
+// FindByOrder 查询订单的全部下载授权
+func (r *GormDownloadGrantRepository) FindByOrder(ctx context.Context, orderID uint) ([]digital.DownloadGrant, error) {
+	var models []DownloadGrantModel
+	if err := database.Conn(ctx, r.db).Where("order_id = ?", orderID).Order("product_id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询下载授权错误: %w", err)
+	}
+
+	grants := make([]digital.DownloadGrant, 0, len(models))
+	for i := range models {
+		grants = append(grants, *downloadGrantToDomain(&models[i]))
+	}
+	return grants, nil
+}
+
+// CreateIfAbsent 创建下载授权，(order_id, product_id)冲突时保留已有记录
+func (r *GormDownloadGrantRepository) CreateIfAbsent(ctx context.Context, grant *digital.DownloadGrant) error {
+	model := &DownloadGrantModel{
+		OrderID:      grant.OrderID,
+		UserID:       grant.UserID,
+		ProductID:    grant.ProductID,
+		MaxDownloads: grant.MaxDownloads,
+	}
+	if err := database.Conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(model).Error; err != nil {
+		return fmt.Errorf("创建下载授权错误: %w", err)
+	}
+	return nil
+}
+
+// ConsumeDownload 通过条件更新消耗一次下载次数，保证并发下载不会超出限制
+func (r *GormDownloadGrantRepository) ConsumeDownload(ctx context.Context, id uint) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&DownloadGrantModel{}).
+		Where("id = ? AND downloads < max_downloads", id).
+		UpdateColumn("downloads", gorm.Expr("downloads + 1"))
+	if result.Error != nil {
+		return false, fmt.Errorf("更新下载次数错误: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Revoke 软删除下载授权，(order_id, product_id)唯一索引仍然保留该记录，重复发放不会重新创建
+func (r *GormDownloadGrantRepository) Revoke(ctx context.Context, orderID uint, productID uint) error {
+	if err := database.Conn(ctx, r.db).
+		Where("order_id = ? AND product_id = ?", orderID, productID).
+		Delete(&DownloadGrantModel{}).Error; err != nil {
+		return fmt.Errorf("撤销下载授权错误: %w", err)
+	}
+	return nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormDownloadGrantRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&DownloadGrantModel{})
+}
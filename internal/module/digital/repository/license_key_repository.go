@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/digital"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// importBatchSize 批量导入激活码时每条INSERT语句的行数
+const importBatchSize = 500
+
+// LicenseKeyModel 是GORM激活码模型
+type LicenseKeyModel struct {
+	ID         uint   `gorm:"primarykey"`
+	ProductID  uint   `gorm:"not null;uniqueIndex:idx_product_key,priority:1;index:idx_product_status,priority:1"`
+	Key        string `gorm:"type:varchar(255);not null;uniqueIndex:idx_product_key,priority:2"`
+	Status     string `gorm:"type:varchar(20);not null;default:'available';index:idx_product_status,priority:2"`
+	OrderID    uint   `gorm:"not null;default:0;index:idx_order_product,priority:1"`
+	UserID     uint   `gorm:"not null;default:0"`
+	AssignedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// TableName 指定表名
+func (LicenseKeyModel) TableName() string {
+	return "license_keys"
+}
+
+// GormLicenseKeyRepository 是激活码仓库的GORM实现
+type GormLicenseKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewGormLicenseKeyRepository 创建一个新的GORM激活码仓库
+func NewGormLicenseKeyRepository(db *gorm.DB) digital.LicenseKeyRepository {
+	return &GormLicenseKeyRepository{db: db}
+}
+
+// licenseKeyToDomain 将GORM模型转换为领域模型
+func licenseKeyToDomain(m *LicenseKeyModel) *digital.LicenseKey {
+	return &digital.LicenseKey{
+		ID:         m.ID,
+		ProductID:  m.ProductID,
+		Key:        m.Key,
+		Status:     m.Status,
+		OrderID:    m.OrderID,
+		UserID:     m.UserID,
+		AssignedAt: m.AssignedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// Import 批量导入激活码
+// 使用INSERT IGNORE跳过(product_id, key)唯一索引冲突的行
+func (r *GormLicenseKeyRepository) Import(ctx context.Context, productID uint, keys []string) (int, error) {
+	imported := 0
+	err := database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(keys); start += importBatchSize {
+			end := min(start+importBatchSize, len(keys))
+			models := make([]LicenseKeyModel, 0, end-start)
+			for _, key := range keys[start:end] {
+				models = append(models, LicenseKeyModel{
+					ProductID: productID,
+					Key:       key,
+					Status:    digital.LicenseKeyStatusAvailable,
+				})
+			}
+
+			result := tx.Clauses(clause.Insert{Modifier: "IGNORE"}).Create(&models)
+			if result.Error != nil {
+				return fmt.Errorf("导入激活码错误: %w", result.Error)
+			}
+			imported += int(result.RowsAffected)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return imported, nil
+}
+
+// Stats 统计商品激活码池的使用情况
+func (r *GormLicenseKeyRepository) Stats(ctx context.Context, productID uint) (*digital.LicenseKeyStats, error) {
+	var rows []struct {
+		Status string
+		Count  int
+	}
+	if err := database.Conn(ctx, r.db).Model(&LicenseKeyModel{}).
+		Select("status, COUNT(*) AS count").
+		Where("product_id = ?", productID).
+		Group("status").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("统计激活码错误: %w", err)
+	}
+
+	stats := &digital.LicenseKeyStats{}
+	for _, row := range rows {
+		switch row.Status {
+		case digital.LicenseKeyStatusAvailable:
+			stats.Available = row.Count
+		case digital.LicenseKeyStatusAssigned:
+			stats.Assigned = row.Count
+		case digital.LicenseKeyStatusRevoked:
+			stats.Revoked = row.Count
+		}
+	}
+	return stats, nil
+}
+
+// Assign 为订单分配激活码
+// 先锁定订单已分配的激活码以支持重试，再通过 UPDATE ... ORDER BY id LIMIT n
+// 一次性占用最早导入的可用激活码，并发分配时同一激活码只会被一个事务更新成功
+func (r *GormLicenseKeyRepository) Assign(ctx context.Context, orderID uint, userID uint, productID uint, quantity int) ([]digital.LicenseKey, error) {
+	var models []LicenseKeyModel
+	err := database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var assigned int64
+		if err := tx.Model(&LicenseKeyModel{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ? AND product_id = ?", orderID, productID).
+			Count(&assigned).Error; err != nil {
+			return fmt.Errorf("查询已分配激活码错误: %w", err)
+		}
+
+		if need := quantity - int(assigned); need > 0 {
+			result := tx.Model(&LicenseKeyModel{}).
+				Where("product_id = ? AND status = ?", productID, digital.LicenseKeyStatusAvailable).
+				Order("id").Limit(need).
+				Updates(map[string]interface{}{
+					"status":      digital.LicenseKeyStatusAssigned,
+					"order_id":    orderID,
+					"user_id":     userID,
+					"assigned_at": time.Now(),
+				})
+			if result.Error != nil {
+				return fmt.Errorf("分配激活码错误: %w", result.Error)
+			}
+			if int(result.RowsAffected) < need {
+				return apierror.NewInsufficientStockError("激活码不足", fmt.Sprintf("商品ID: %d, 需要数量: %d", productID, need))
+			}
+		}
+
+		if err := tx.Where("order_id = ? AND product_id = ?", orderID, productID).
+			Order("id").Find(&models).Error; err != nil {
+			return fmt.Errorf("查询已分配激活码错误: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]digital.LicenseKey, 0, len(models))
+	for i := range models {
+		keys = append(keys, *licenseKeyToDomain(&models[i]))
+	}
+	return keys, nil
+}
+
+// FindByOrder 查询订单已分配的激活码
+func (r *GormLicenseKeyRepository) FindByOrder(ctx context.Context, orderID uint) ([]digital.LicenseKey, error) {
+	var models []LicenseKeyModel
+	if err := database.Conn(ctx, r.db).Where("order_id = ?", orderID).Order("product_id, id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询已分配激活码错误: %w", err)
+	}
+
+	keys := make([]digital.LicenseKey, 0, len(models))
+	for i := range models {
+		keys = append(keys, *licenseKeyToDomain(&models[i]))
+	}
+	return keys, nil
+}
+
+// Revoke 吊销订单已分配的激活码，从最后分配的开始
+// 激活码已展示给买家，放回池中会被再次卖出，因此只标记为已吊销
+func (r *GormLicenseKeyRepository) Revoke(ctx context.Context, orderID uint, productID uint, quantity int) (int, error) {
+	result := database.Conn(ctx, r.db).Model(&LicenseKeyModel{}).
+		Where("order_id = ? AND product_id = ? AND status = ?", orderID, productID, digital.LicenseKeyStatusAssigned).
+		Order("id DESC").Limit(quantity).
+		Update("status", digital.LicenseKeyStatusRevoked)
+	if result.Error != nil {
+		return 0, fmt.Errorf("吊销激活码错误: %w", result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormLicenseKeyRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&LicenseKeyModel{})
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"web3-ecommerce-app/internal/domain/digital"
+	"web3-ecommerce-app/internal/platform/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newDigitalTestDB 创建SQLite测试库
+// SQLite会忽略UPDATE的LIMIT，测试中每次都分配整个激活码池；
+// SQLite的索引名在整个库内唯一，下载授权表与激活码表的索引同名，因此下载授权表手动创建
+func newDigitalTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "digital.db")
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := db.AutoMigrate(&LicenseKeyModel{}); err != nil {
+		t.Fatalf("migrate license keys: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE download_grants (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		created_at DATETIME,
+		updated_at DATETIME,
+		deleted_at DATETIME,
+		order_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		product_id INTEGER NOT NULL,
+		downloads INTEGER NOT NULL DEFAULT 0,
+		max_downloads INTEGER NOT NULL,
+		UNIQUE (order_id, product_id)
+	)`).Error; err != nil {
+		t.Fatalf("create download grants: %v", err)
+	}
+	return db
+}
+
+func seedLicenseKeys(t *testing.T, db *gorm.DB, productID uint, keys ...string) {
+	t.Helper()
+	models := make([]LicenseKeyModel, 0, len(keys))
+	for _, key := range keys {
+		models = append(models, LicenseKeyModel{ProductID: productID, Key: key, Status: digital.LicenseKeyStatusAvailable})
+	}
+	if err := db.Create(&models).Error; err != nil {
+		t.Fatalf("seed keys: %v", err)
+	}
+}
+
+// deliverInTx 模拟订单支付事务中的发放，fail不为空时事务回滚
+func deliverInTx(db *gorm.DB, orderID uint, fail error) error {
+	keyRepo := NewGormLicenseKeyRepository(db)
+	grantRepo := NewGormDownloadGrantRepository(db)
+	return database.Transaction(context.Background(), db, func(ctx context.Context) error {
+		if _, err := keyRepo.Assign(ctx, orderID, 7, 1, 2); err != nil {
+			return err
+		}
+		if err := grantRepo.CreateIfAbsent(ctx, &digital.DownloadGrant{OrderID: orderID, UserID: 7, ProductID: 1, MaxDownloads: 5}); err != nil {
+			return err
+		}
+		return fail
+	})
+}
+
+func TestDeliverJoinsPaymentTransaction(t *testing.T) {
+	db := newDigitalTestDB(t)
+	seedLicenseKeys(t, db, 1, "AAA", "BBB")
+	keyRepo := NewGormLicenseKeyRepository(db)
+	grantRepo := NewGormDownloadGrantRepository(db)
+	ctx := context.Background()
+
+	paymentErr := errors.New("订单状态已被修改")
+	if err := deliverInTx(db, 100, paymentErr); !errors.Is(err, paymentErr) {
+		t.Fatalf("deliver error = %v, want %v", err, paymentErr)
+	}
+
+	stats, err := keyRepo.Stats(ctx, 1)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Available != 2 || stats.Assigned != 0 {
+		t.Errorf("支付回滚后激活码应全部可用, got available=%d assigned=%d", stats.Available, stats.Assigned)
+	}
+	grants, err := grantRepo.FindByOrder(ctx, 100)
+	if err != nil {
+		t.Fatalf("find grants: %v", err)
+	}
+	if len(grants) != 0 {
+		t.Errorf("支付回滚后不应有下载授权, got %d", len(grants))
+	}
+
+	if err := deliverInTx(db, 100, nil); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	keys, err := keyRepo.FindByOrder(ctx, 100)
+	if err != nil {
+		t.Fatalf("find keys: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("支付提交后应分配2个激活码, got %d", len(keys))
+	}
+	grants, _ = grantRepo.FindByOrder(ctx, 100)
+	if len(grants) != 1 {
+		t.Errorf("支付提交后应创建下载授权, got %d", len(grants))
+	}
+}
+
+func TestRevokeOnRefund(t *testing.T) {
+	db := newDigitalTestDB(t)
+	seedLicenseKeys(t, db, 1, "AAA", "BBB")
+	keyRepo := NewGormLicenseKeyRepository(db)
+	grantRepo := NewGormDownloadGrantRepository(db)
+	ctx := context.Background()
+
+	if err := deliverInTx(db, 100, nil); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	grants, _ := grantRepo.FindByOrder(ctx, 100)
+	if len(grants) != 1 {
+		t.Fatalf("grants = %d, want 1", len(grants))
+	}
+
+	err := database.Transaction(ctx, db, func(ctx context.Context) error {
+		revoked, err := keyRepo.Revoke(ctx, 100, 1, 2)
+		if err != nil {
+			return err
+		}
+		if revoked != 2 {
+			t.Errorf("revoked = %d, want 2", revoked)
+		}
+		return grantRepo.Revoke(ctx, 100, 1)
+	})
+	if err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+
+	stats, err := keyRepo.Stats(ctx, 1)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Available != 0 || stats.Assigned != 0 || stats.Revoked != 2 {
+		t.Errorf("吊销的激活码不应放回池中, got %+v", stats)
+	}
+	if revoked, _ := keyRepo.Revoke(ctx, 100, 1, 2); revoked != 0 {
+		t.Errorf("重复吊销 = %d, want 0", revoked)
+	}
+
+	if _, err := grantRepo.FindByID(ctx, grants[0].ID); err == nil {
+		t.Error("撤销后下载授权仍可查询，已签发的链接仍能下载")
+	}
+	if ok, _ := grantRepo.ConsumeDownload(ctx, grants[0].ID); ok {
+		t.Error("撤销后仍能消耗下载次数")
+	}
+
+	// 重复发放不会重新创建已撤销的授权
+	if err := grantRepo.CreateIfAbsent(ctx, &digital.DownloadGrant{OrderID: 100, UserID: 7, ProductID: 1, MaxDownloads: 5}); err != nil {
+		t.Fatalf("create grant: %v", err)
+	}
+	if grants, _ := grantRepo.FindByOrder(ctx, 100); len(grants) != 0 {
+		t.Errorf("已撤销的授权被重新创建, got %d", len(grants))
+	}
+}
@@ -0,0 +1,22 @@
+package digital
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/digital/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册数字商品发放模块路由
+// 激活码和文件的管理接口由admin模块统一提供
+func RegisterRoutes(router *gin.Engine, handler *handler.DigitalHTTPHandler, jwtConfig *config.JWTConfig) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 获取订单的激活码和下载链接(需要认证)
+	v1.GET("/orders/:id/delivery", middleware.JWT(jwtConfig), handler.GetOrderDelivery)
+
+	// 通过签名链接下载文件(链接即凭证，不需要认证)
+	v1.GET("/downloads/:grant_id/:asset_id", handler.Download)
+}
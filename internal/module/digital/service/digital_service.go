@@ -0,0 +1,451 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/digital"
+	"web3-ecommerce-app/internal/domain/product"
+	productService "web3-ecommerce-app/internal/module/product/service"
+	"web3-ecommerce-app/internal/platform/blobstore"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+const (
+	// maxLicenseKeyFileSize 激活码导入文件的最大字节数
+	maxLicenseKeyFileSize = 5 << 20
+	// maxLicenseKeyLength 单个激活码的最大长度，与数据库列宽一致
+	maxLicenseKeyLength = 255
+)
+
+// DigitalService 数字商品发放服务接口
+type DigitalService interface {
+	// ImportLicenseKeys 导入激活码，每行一个，导入数量同步增加商品库存
+	ImportLicenseKeys(ctx context.Context, productID uint, body io.Reader) (*digital.ImportLicenseKeysResult, error)
+
+	// GetLicenseKeyStats 查询激活码池的使用情况
+	GetLicenseKeyStats(ctx context.Context, productID uint) (*digital.LicenseKeyStats, error)
+
+	// UploadAsset 上传可下载文件
+	UploadAsset(ctx context.Context, productID uint, fileName string, body io.Reader) (*digital.Asset, error)
+
+	// ListAssets 查询商品的可下载文件
+	ListAssets(ctx context.Context, productID uint) ([]digital.Asset, error)
+
+	// DeleteAsset 删除可下载文件
+	DeleteAsset(ctx context.Context, productID uint, assetID uint) error
+
+	// Deliver 在订单支付事务中发放数字商品，激活码不足时返回错误使支付回滚，重复调用不会重复发放
+	Deliver(ctx context.Context, orderID uint, userID uint, items []digital.DeliveryItem) error
+
+	// Revoke 在订单退款事务中吊销退款数量对应的激活码，订单行全部退款时撤销下载授权
+	Revoke(ctx context.Context, orderID uint, items []digital.RevokeItem) error
+
+	// GetDelivery 查询订单已发放的激活码，并生成新的下载链接
+	GetDelivery(ctx context.Context, orderID uint, userID uint) (*digital.Delivery, error)
+
+	// Download 校验签名链接并消耗一次下载次数，返回文件信息和内容
+	Download(ctx context.Context, grantID uint, assetID uint, expires int64, signature string) (*digital.Asset, io.ReadCloser, error)
+}
+
+// DefaultDigitalService 默认数字商品发放服务实现
+type DefaultDigitalService struct {
+	licenseKeyRepo digital.LicenseKeyRepository
+	assetRepo      digital.AssetRepository
+	grantRepo      digital.DownloadGrantRepository
+	productService productService.ProductService
+	store          blobstore.BlobStore
+	digitalConfig  *config.DigitalConfig
+}
+
+// NewDigitalService 创建数字商品发放服务，store必须是不对外公开的私有存储
+func NewDigitalService(
+	licenseKeyRepo digital.LicenseKeyRepository,
+	assetRepo digital.AssetRepository,
+	grantRepo digital.DownloadGrantRepository,
+	productService productService.ProductService,
+	store blobstore.BlobStore,
+	digitalConfig *config.DigitalConfig,
+) DigitalService {
+	return &DefaultDigitalService{
+		licenseKeyRepo: licenseKeyRepo,
+		assetRepo:      assetRepo,
+		grantRepo:      grantRepo,
+		productService: productService,
+		store:          store,
+		digitalConfig:  digitalConfig,
+	}
+}
+
+// ImportLicenseKeys 导入激活码
+// 激活码数量即可售数量，导入成功后按实际导入数量增加商品库存，库存预占因此能防止激活码超卖
+func (s *DefaultDigitalService) ImportLicenseKeys(ctx context.Context, productID uint, body io.Reader) (*digital.ImportLicenseKeysResult, error) {
+	if _, err := s.findDigitalProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxLicenseKeyFileSize+1))
+	if err != nil {
+		return nil, apierror.NewBadRequestError("读取导入文件失败", err.Error())
+	}
+	if len(data) > maxLicenseKeyFileSize {
+		return nil, apierror.NewValidationError("导入文件过大", fmt.Sprintf("最大允许 %d 字节", maxLicenseKeyFileSize))
+	}
+
+	keys, duplicates, err := parseLicenseKeys(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, apierror.NewValidationError("导入文件中没有激活码", "")
+	}
+
+	imported, err := s.licenseKeyRepo.Import(ctx, productID, keys)
+	if err != nil {
+		return nil, err
+	}
+	if imported > 0 {
+		if _, err := s.productService.AdjustStock(ctx, productID, imported); err != nil {
+			return nil, err
+		}
+	}
+
+	return &digital.ImportLicenseKeysResult{
+		Imported:   imported,
+		Duplicates: duplicates + len(keys) - imported,
+	}, nil
+}
+
+// GetLicenseKeyStats 查询激活码池的使用情况
+func (s *DefaultDigitalService) GetLicenseKeyStats(ctx context.Context, productID uint) (*digital.LicenseKeyStats, error) {
+	if _, err := s.findDigitalProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.licenseKeyRepo.Stats(ctx, productID)
+}
+
+// UploadAsset 将文件写入私有存储并创建文件记录
+func (s *DefaultDigitalService) UploadAsset(ctx context.Context, productID uint, fileName string, body io.Reader) (*digital.Asset, error) {
+	if _, err := s.findDigitalProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	fileName = filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	if fileName == "" || fileName == "." || fileName == "/" {
+		return nil, apierror.NewValidationError("无效的文件名", "")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, s.digitalConfig.MaxFileSize+1))
+	if err != nil {
+		return nil, apierror.NewBadRequestError("读取上传文件失败", err.Error())
+	}
+	if int64(len(data)) > s.digitalConfig.MaxFileSize {
+		return nil, apierror.NewValidationError("文件过大", fmt.Sprintf("最大允许 %d 字节", s.digitalConfig.MaxFileSize))
+	}
+	if len(data) == 0 {
+		return nil, apierror.NewValidationError("文件不能为空", "")
+	}
+
+	key, err := newAssetKey(productID)
+	if err != nil {
+		return nil, err
+	}
+	contentType := http.DetectContentType(data)
+	if err := s.store.Put(ctx, key, bytes.NewReader(data), contentType); err != nil {
+		return nil, fmt.Errorf("保存数字文件失败: %w", err)
+	}
+
+	asset := &digital.Asset{
+		ProductID:   productID,
+		Key:         key,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	if err := s.assetRepo.Create(ctx, asset); err != nil {
+		s.deleteBlob(ctx, key)
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+// ListAssets 查询商品的可下载文件
+func (s *DefaultDigitalService) ListAssets(ctx context.Context, productID uint) ([]digital.Asset, error) {
+	return s.assetRepo.FindByProduct(ctx, productID)
+}
+
+// DeleteAsset 删除文件记录和存储中的文件
+func (s *DefaultDigitalService) DeleteAsset(ctx context.Context, productID uint, assetID uint) error {
+	asset, err := s.assetRepo.FindByID(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if asset.ProductID != productID {
+		return apierror.NewNotFoundError("文件不存在", fmt.Sprintf("ID: %d", assetID))
+	}
+
+	if err := s.assetRepo.Delete(ctx, assetID); err != nil {
+		return err
+	}
+	s.deleteBlob(ctx, asset.Key)
+
+	return nil
+}
+
+// Deliver 为订单中的数字商品分配激活码并创建下载授权
+// 商品池中有激活码时按购买数量分配；商品有可下载文件时创建下载授权；
+// 写入通过ctx加入调用方的支付事务，与订单状态一起提交或回滚
+func (s *DefaultDigitalService) Deliver(ctx context.Context, orderID uint, userID uint, items []digital.DeliveryItem) error {
+	for _, item := range items {
+		productEntity, err := s.productService.GetProductByID(ctx, item.ProductID)
+		if err != nil {
+			return err
+		}
+		if !productEntity.IsDigital() {
+			continue
+		}
+
+		stats, err := s.licenseKeyRepo.Stats(ctx, item.ProductID)
+		if err != nil {
+			return err
+		}
+		if stats.Available+stats.Assigned > 0 {
+			if _, err := s.licenseKeyRepo.Assign(ctx, orderID, userID, item.ProductID, item.Quantity); err != nil {
+				return err
+			}
+		}
+
+		assets, err := s.assetRepo.FindByProduct(ctx, item.ProductID)
+		if err != nil {
+			return err
+		}
+		if len(assets) > 0 {
+			if err := s.grantRepo.CreateIfAbsent(ctx, &digital.DownloadGrant{
+				OrderID:      orderID,
+				UserID:       userID,
+				ProductID:    item.ProductID,
+				MaxDownloads: s.digitalConfig.DownloadLimit,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("订单 %d 的数字商品已发放", orderID)
+	return nil
+}
+
+// Revoke 收回订单退款部分的数字商品
+// 写入通过ctx加入调用方的退款事务，已吊销的激活码不再出现在发放详情中
+func (s *DefaultDigitalService) Revoke(ctx context.Context, orderID uint, items []digital.RevokeItem) error {
+	for _, item := range items {
+		if _, err := s.licenseKeyRepo.Revoke(ctx, orderID, item.ProductID, item.Quantity); err != nil {
+			return err
+		}
+		if item.Downloads {
+			if err := s.grantRepo.Revoke(ctx, orderID, item.ProductID); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("订单 %d 退款的数字商品已收回", orderID)
+	return nil
+}
+
+// GetDelivery 查询订单的发放详情，每次调用都会生成新的短期下载链接
+func (s *DefaultDigitalService) GetDelivery(ctx context.Context, orderID uint, userID uint) (*digital.Delivery, error) {
+	keys, err := s.licenseKeyRepo.FindByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := s.grantRepo.FindByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	byProduct := make(map[uint]*digital.DeliveredProduct)
+	var order []uint
+	entry := func(productID uint) *digital.DeliveredProduct {
+		if p, ok := byProduct[productID]; ok {
+			return p
+		}
+		p := &digital.DeliveredProduct{
+			ProductID:   productID,
+			LicenseKeys: []string{},
+			Downloads:   []digital.DownloadLink{},
+		}
+		byProduct[productID] = p
+		order = append(order, productID)
+		return p
+	}
+
+	for _, key := range keys {
+		if key.UserID != userID {
+			return nil, apierror.NewNotFoundError("订单不存在", fmt.Sprintf("ID: %d", orderID))
+		}
+		if key.Status == digital.LicenseKeyStatusRevoked {
+			continue
+		}
+		p := entry(key.ProductID)
+		p.LicenseKeys = append(p.LicenseKeys, key.Key)
+	}
+
+	expiresAt := time.Now().Add(s.digitalConfig.LinkTTL)
+	for _, grant := range grants {
+		if grant.UserID != userID {
+			return nil, apierror.NewNotFoundError("订单不存在", fmt.Sprintf("ID: %d", orderID))
+		}
+		assets, err := s.assetRepo.FindByProduct(ctx, grant.ProductID)
+		if err != nil {
+			return nil, err
+		}
+
+		p := entry(grant.ProductID)
+		p.RemainingDownloads = grant.RemainingDownloads()
+		for _, asset := range assets {
+			p.Downloads = append(p.Downloads, digital.DownloadLink{
+				AssetID:   asset.ID,
+				FileName:  asset.FileName,
+				Size:      asset.Size,
+				URL:       s.downloadURL(grant.ID, asset.ID, expiresAt),
+				ExpiresAt: expiresAt,
+			})
+		}
+	}
+
+	delivery := &digital.Delivery{OrderID: orderID, Products: make([]digital.DeliveredProduct, 0, len(order))}
+	for _, productID := range order {
+		delivery.Products = append(delivery.Products, *byProduct[productID])
+	}
+	return delivery, nil
+}
+
+// Download 校验下载链接的签名和有效期，消耗一次下载次数后打开文件
+func (s *DefaultDigitalService) Download(ctx context.Context, grantID uint, assetID uint, expires int64, signature string) (*digital.Asset, io.ReadCloser, error) {
+	expected := s.sign(grantID, assetID, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, nil, apierror.NewForbiddenError("下载链接无效", "")
+	}
+	if time.Now().Unix() > expires {
+		return nil, nil, apierror.NewForbiddenError("下载链接已过期", "请在订单详情中重新获取下载链接")
+	}
+
+	grant, err := s.grantRepo.FindByID(ctx, grantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	asset, err := s.assetRepo.FindByID(ctx, assetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if asset.ProductID != grant.ProductID {
+		return nil, nil, apierror.NewForbiddenError("下载链接无效", "")
+	}
+
+	ok, err := s.grantRepo.ConsumeDownload(ctx, grantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, apierror.NewForbiddenError("下载次数已用完", fmt.Sprintf("每个订单最多下载 %d 次", grant.MaxDownloads))
+	}
+
+	body, err := s.store.Get(ctx, asset.Key)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, nil, apierror.NewNotFoundError("文件不存在", fmt.Sprintf("ID: %d", assetID))
+		}
+		return nil, nil, fmt.Errorf("读取数字文件失败: %w", err)
+	}
+	return asset, body, nil
+}
+
+// findDigitalProduct 查找商品并校验是否为数字商品
+func (s *DefaultDigitalService) findDigitalProduct(ctx context.Context, productID uint) (*product.Product, error) {
+	productEntity, err := s.productService.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !productEntity.IsDigital() {
+		return nil, apierror.NewValidationError("只有数字商品才能管理激活码和下载文件", fmt.Sprintf("ID: %d", productID))
+	}
+	return productEntity, nil
+}
+
+// downloadURL 生成带签名的下载地址
+func (s *DefaultDigitalService) downloadURL(grantID uint, assetID uint, expiresAt time.Time) string {
+	expires := expiresAt.Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.sign(grantID, assetID, expires))
+	return fmt.Sprintf("/api/v1/downloads/%d/%d?%s", grantID, assetID, query.Encode())
+}
+
+// sign 计算下载链接的HMAC-SHA256签名
+func (s *DefaultDigitalService) sign(grantID uint, assetID uint, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(s.digitalConfig.SigningSecret))
+	fmt.Fprintf(mac, "%d:%d:%d", grantID, assetID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deleteBlob 尽力删除存储中的文件，失败只记录日志
+func (s *DefaultDigitalService) deleteBlob(ctx context.Context, key string) {
+	if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, blobstore.ErrNotFound) {
+		log.Printf("删除数字文件 %s 失败: %v", key, err)
+	}
+}
+
+// parseLicenseKeys 按行解析激活码，忽略空行并去除文件内的重复项
+func parseLicenseKeys(data []byte) ([]string, int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	seen := make(map[string]struct{})
+	var keys []string
+	duplicates := 0
+	line := 0
+	for scanner.Scan() {
+		line++
+		key := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if key == "" {
+			continue
+		}
+		if len(key) > maxLicenseKeyLength {
+			return nil, 0, apierror.NewValidationError("激活码过长", fmt.Sprintf("第 %d 行超过 %d 个字符", line, maxLicenseKeyLength))
+		}
+		if _, ok := seen[key]; ok {
+			duplicates++
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, apierror.NewBadRequestError("读取导入文件失败", err.Error())
+	}
+	return keys, duplicates, nil
+}
+
+// newAssetKey 生成随机的数字文件存储key
+func newAssetKey(productID uint) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成文件key失败: %w", err)
+	}
+	return fmt.Sprintf("digital/%d/%s", productID, hex.EncodeToString(buf)), nil
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"web3-ecommerce-app/internal/domain/giftcard"
+	"web3-ecommerce-app/internal/module/giftcard/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GiftCardHTTPHandler 礼品卡HTTP处理器
+type GiftCardHTTPHandler struct {
+	giftCardService service.GiftCardService
+}
+
+// NewGiftCardHTTPHandler 创建礼品卡HTTP处理器
+func NewGiftCardHTTPHandler(giftCardService service.GiftCardService) *GiftCardHTTPHandler {
+	return &GiftCardHTTPHandler{
+		giftCardService: giftCardService,
+	}
+}
+
+// ListGiftCards 分页获取当前用户购买的礼品卡，不包含兑换码
+func (h *GiftCardHTTPHandler) ListGiftCards(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	result, err := h.giftCardService.ListUserGiftCards(c.Request.Context(), userID, page, pageSize)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CheckBalance 凭兑换码查询礼品卡余额
+func (h *GiftCardHTTPHandler) CheckBalance(c *gin.Context) {
+	input, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	balance, err := h.giftCardService.CheckBalance(c.Request.Context(), input.Code)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// Redeem 将礼品卡的全部余额兑换到当前用户的购物金
+func (h *GiftCardHTTPHandler) Redeem(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	input, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.giftCardService.RedeemToStoreCredit(c.Request.Context(), userID, input.Code)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// bindCode 解析请求中的兑换码，失败时直接写入错误响应
+func (h *GiftCardHTTPHandler) bindCode(c *gin.Context) (*giftcard.CodeInput, bool) {
+	var input giftcard.CodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return nil, false
+	}
+	return &input, true
+}
+
+// getUserID 获取当前登录用户ID，失败时直接写入错误响应
+func (h *GiftCardHTTPHandler) getUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// handleError 处理错误
+func (h *GiftCardHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/giftcard"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GiftCardModel 是GORM礼品卡模型
+type GiftCardModel struct {
+	ID            uint   `gorm:"primarykey"`
+	CodeHash      string `gorm:"type:char(64);not null;uniqueIndex:idx_code_hash"`
+	Last4         string `gorm:"type:varchar(4);not null"`
+	InitialAmount int64  `gorm:"not null"`
+	Balance       int64  `gorm:"not null"`
+	Status        string `gorm:"type:varchar(20);not null;index:idx_status"`
+	Source        string `gorm:"type:varchar(20);not null"`
+	OrderID       uint   `gorm:"not null;default:0;index:idx_order_id"`
+	ProductID     uint   `gorm:"not null;default:0"`
+	PurchaserID   uint   `gorm:"not null;default:0;index:idx_purchaser_id"`
+	IssuedBy      uint   `gorm:"not null;default:0"`
+	Note          string `gorm:"type:varchar(500);not null;default:''"`
+	ExpiresAt     *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName 指定表名
+func (GiftCardModel) TableName() string {
+	return "gift_cards"
+}
+
+// RedemptionModel 是GORM礼品卡兑换记录模型
+type RedemptionModel struct {
+	ID           uint  `gorm:"primarykey"`
+	GiftCardID   uint  `gorm:"not null;index:idx_gift_card_id"`
+	UserID       uint  `gorm:"not null;index:idx_user_id"`
+	OrderID      uint  `gorm:"not null;default:0"`
+	Amount       int64 `gorm:"not null"`
+	BalanceAfter int64 `gorm:"not null"`
+	CreatedAt    time.Time
+}
+
+// TableName 指定表名
+func (RedemptionModel) TableName() string {
+	return "gift_card_redemptions"
+}
+
+// DenominationModel 是GORM礼品卡商品面额模型
+type DenominationModel struct {
+	ProductID uint  `gorm:"primarykey;autoIncrement:false"`
+	Amount    int64 `gorm:"not null"`
+	ValidDays int   `gorm:"not null;default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName 指定表名
+func (DenominationModel) TableName() string {
+	return "gift_card_denominations"
+}
+
+// GormGiftCardRepository 是礼品卡仓库的GORM实现
+type GormGiftCardRepository struct {
+	db *gorm.DB
+}
+
+// NewGormGiftCardRepository 创建一个新的GORM礼品卡仓库
+func NewGormGiftCardRepository(db *gorm.DB) giftcard.GiftCardRepository {
+	return &GormGiftCardRepository{db: db}
+}
+
+// giftCardToModel 将领域模型转换为GORM模型
+func giftCardToModel(g *giftcard.GiftCard) *GiftCardModel {
+	return &GiftCardModel{
+		ID:            g.ID,
+		CodeHash:      g.CodeHash,
+		Last4:         g.Last4,
+		InitialAmount: int64(g.InitialAmount),
+		Balance:       int64(g.Balance),
+		Status:        g.Status,
+		Source:        g.Source,
+		OrderID:       g.OrderID,
+		ProductID:     g.ProductID,
+		PurchaserID:   g.PurchaserID,
+		IssuedBy:      g.IssuedBy,
+		Note:          g.Note,
+		ExpiresAt:     g.ExpiresAt,
+		CreatedAt:     g.CreatedAt,
+		UpdatedAt:     g.UpdatedAt,
+	}
+}
+
+// giftCardToDomain 将GORM模型转换为领域模型
+func giftCardToDomain(m *GiftCardModel) *giftcard.GiftCard {
+	return &giftcard.GiftCard{
+		ID:            m.ID,
+		CodeHash:      m.CodeHash,
+		Last4:         m.Last4,
+		InitialAmount: common.Money(m.InitialAmount),
+		Balance:       common.Money(m.Balance),
+		Status:        m.Status,
+		Source:        m.Source,
+		OrderID:       m.OrderID,
+		ProductID:     m.ProductID,
+		PurchaserID:   m.PurchaserID,
+		IssuedBy:      m.IssuedBy,
+		Note:          m.Note,
+		ExpiresAt:     m.ExpiresAt,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+// denominationToDomain 将GORM模型转换为领域模型
+func denominationToDomain(m *DenominationModel) *giftcard.Denomination {
+	return &giftcard.Denomination{
+		ProductID: m.ProductID,
+		Amount:    common.Money(m.Amount),
+		ValidDays: m.ValidDays,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// Create 创建礼品卡
+func (r *GormGiftCardRepository) Create(ctx context.Context, card *giftcard.GiftCard) error {
+	model := giftCardToModel(card)
+	if err := database.Conn(ctx, r.db).Create(model).Error; err != nil {
+		return fmt.Errorf("创建礼品卡错误: %w", err)
+	}
+
+	*card = *giftCardToDomain(model)
+	return nil
+}
+
+// FindByID 根据ID查询礼品卡
+func (r *GormGiftCardRepository) FindByID(ctx context.Context, id uint) (*giftcard.GiftCard, error) {
+	var model GiftCardModel
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("礼品卡不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询礼品卡错误: %w", err)
+	}
+	return giftCardToDomain(&model), nil
+}
+
+// FindByCodeHash 根据兑换码哈希查询礼品卡
+func (r *GormGiftCardRepository) FindByCodeHash(ctx context.Context, codeHash string) (*giftcard.GiftCard, error) {
+	var model GiftCardModel
+	if err := database.Conn(ctx, r.db).Where("code_hash = ?", codeHash).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("礼品卡不存在", "兑换码无效")
+		}
+		return nil, fmt.Errorf("查询礼品卡错误: %w", err)
+	}
+	return giftCardToDomain(&model), nil
+}
+
+// LockByID 在事务中锁定礼品卡行并返回最新的礼品卡
+func (r *GormGiftCardRepository) LockByID(ctx context.Context, id uint) (*giftcard.GiftCard, error) {
+	var model GiftCardModel
+	if err := database.Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("礼品卡不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("锁定礼品卡错误: %w", err)
+	}
+	return giftCardToDomain(&model), nil
+}
+
+// LockByCodeHash 在事务中按兑换码哈希锁定礼品卡行并返回最新的礼品卡
+func (r *GormGiftCardRepository) LockByCodeHash(ctx context.Context, codeHash string) (*giftcard.GiftCard, error) {
+	var model GiftCardModel
+	if err := database.Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code_hash = ?", codeHash).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("礼品卡不存在", "兑换码无效")
+		}
+		return nil, fmt.Errorf("锁定礼品卡错误: %w", err)
+	}
+	return giftCardToDomain(&model), nil
+}
+
+// Find 按条件分页查询礼品卡
+func (r *GormGiftCardRepository) Find(ctx context.Context, query giftcard.GiftCardQuery) (*giftcard.GiftCardPaginationResult, error) {
+	db := database.Conn(ctx, r.db).Model(&GiftCardModel{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Source != "" {
+		db = db.Where("source = ?", query.Source)
+	}
+	if query.PurchaserID != 0 {
+		db = db.Where("purchaser_id = ?", query.PurchaserID)
+	}
+	if query.OrderID != 0 {
+		db = db.Where("order_id = ?", query.OrderID)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询礼品卡总数错误: %w", err)
+	}
+
+	var models []GiftCardModel
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order("created_at DESC").Order("id DESC").Offset(offset).Limit(query.PageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询礼品卡列表错误: %w", err)
+	}
+
+	cards := make([]giftcard.GiftCard, 0, len(models))
+	for i := range models {
+		cards = append(cards, *giftCardToDomain(&models[i]))
+	}
+	return &giftcard.GiftCardPaginationResult{Total: int(total), GiftCards: cards}, nil
+}
+
+// CountByOrder 统计订单已发放的礼品卡数量
+func (r *GormGiftCardRepository) CountByOrder(ctx context.Context, orderID uint) (int, error) {
+	var count int64
+	if err := database.Conn(ctx, r.db).Model(&GiftCardModel{}).Where("order_id = ?", orderID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("查询订单礼品卡错误: %w", err)
+	}
+	return int(count), nil
+}
+
+// LockByOrder 在事务中按ID顺序锁定订单购买的礼品卡
+func (r *GormGiftCardRepository) LockByOrder(ctx context.Context, orderID uint) ([]giftcard.GiftCard, error) {
+	var models []GiftCardModel
+	if err := database.Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ?", orderID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("锁定订单礼品卡错误: %w", err)
+	}
+
+	cards := make([]giftcard.GiftCard, 0, len(models))
+	for i := range models {
+		cards = append(cards, *giftCardToDomain(&models[i]))
+	}
+	return cards, nil
+}
+
+// Update 更新礼品卡
+func (r *GormGiftCardRepository) Update(ctx context.Context, card *giftcard.GiftCard) error {
+	model := giftCardToModel(card)
+	result := database.Conn(ctx, r.db).Model(&GiftCardModel{}).Where("id = ?", card.ID).
+		Select("balance", "status", "note", "expires_at", "updated_at").Updates(model)
+	if result.Error != nil {
+		return fmt.Errorf("更新礼品卡错误: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apierror.NewNotFoundError("礼品卡不存在", fmt.Sprintf("ID: %d", card.ID))
+	}
+	return nil
+}
+
+// CreateRedemption 记录兑换
+func (r *GormGiftCardRepository) CreateRedemption(ctx context.Context, redemption *giftcard.Redemption) error {
+	model := &RedemptionModel{
+		GiftCardID:   redemption.GiftCardID,
+		UserID:       redemption.UserID,
+		OrderID:      redemption.OrderID,
+		Amount:       int64(redemption.Amount),
+		BalanceAfter: int64(redemption.BalanceAfter),
+	}
+	if err := database.Conn(ctx, r.db).Create(model).Error; err != nil {
+		return fmt.Errorf("记录礼品卡兑换错误: %w", err)
+	}
+
+	redemption.ID = model.ID
+	redemption.CreatedAt = model.CreatedAt
+	return nil
+}
+
+// FindRedemptions 按时间顺序查询礼品卡的兑换记录
+func (r *GormGiftCardRepository) FindRedemptions(ctx context.Context, giftCardID uint) ([]giftcard.Redemption, error) {
+	var models []RedemptionModel
+	if err := database.Conn(ctx, r.db).Where("gift_card_id = ?", giftCardID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询礼品卡兑换记录错误: %w", err)
+	}
+
+	redemptions := make([]giftcard.Redemption, 0, len(models))
+	for _, m := range models {
+		redemptions = append(redemptions, giftcard.Redemption{
+			ID:           m.ID,
+			GiftCardID:   m.GiftCardID,
+			UserID:       m.UserID,
+			OrderID:      m.OrderID,
+			Amount:       common.Money(m.Amount),
+			BalanceAfter: common.Money(m.BalanceAfter),
+			CreatedAt:    m.CreatedAt,
+		})
+	}
+	return redemptions, nil
+}
+
+// FindDenomination 查询商品的礼品卡面额
+func (r *GormGiftCardRepository) FindDenomination(ctx context.Context, productID uint) (*giftcard.Denomination, error) {
+	var models []DenominationModel
+	if err := database.Conn(ctx, r.db).Where("product_id = ?", productID).Limit(1).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询礼品卡面额错误: %w", err)
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+	return denominationToDomain(&models[0]), nil
+}
+
+// FindDenominations 查询全部礼品卡商品的面额
+func (r *GormGiftCardRepository) FindDenominations(ctx context.Context) ([]giftcard.Denomination, error) {
+	var models []DenominationModel
+	if err := database.Conn(ctx, r.db).Order("amount ASC").Order("product_id ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询礼品卡面额列表错误: %w", err)
+	}
+
+	denominations := make([]giftcard.Denomination, 0, len(models))
+	for i := range models {
+		denominations = append(denominations, *denominationToDomain(&models[i]))
+	}
+	return denominations, nil
+}
+
+// SaveDenomination 创建或更新商品的礼品卡面额
+func (r *GormGiftCardRepository) SaveDenomination(ctx context.Context, denomination *giftcard.Denomination) error {
+	model := &DenominationModel{
+		ProductID: denomination.ProductID,
+		Amount:    int64(denomination.Amount),
+		ValidDays: denomination.ValidDays,
+	}
+	if err := database.Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"amount", "valid_days", "updated_at"}),
+	}).Create(model).Error; err != nil {
+		return fmt.Errorf("保存礼品卡面额错误: %w", err)
+	}
+	return nil
+}
+
+// DeleteDenomination 删除商品的礼品卡面额
+func (r *GormGiftCardRepository) DeleteDenomination(ctx context.Context, productID uint) error {
+	result := database.Conn(ctx, r.db).Where("product_id = ?", productID).Delete(&DenominationModel{})
+	if result.Error != nil {
+		return fmt.Errorf("删除礼品卡面额错误: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apierror.NewNotFoundError("礼品卡面额不存在", fmt.Sprintf("商品ID: %d", productID))
+	}
+	return nil
+}
+
+// Transaction 在事务中执行fn
+func (r *GormGiftCardRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.Transaction(ctx, r.db, fn)
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormGiftCardRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&GiftCardModel{}, &RedemptionModel{}, &DenominationModel{})
+}
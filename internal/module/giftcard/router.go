@@ -0,0 +1,30 @@
+package giftcard
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/giftcard/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册礼品卡模块路由
+// 礼品卡发放和面额管理由admin模块统一提供，下单时的礼品卡抵扣由订单模块处理
+func RegisterRoutes(router *gin.Engine, handler *handler.GiftCardHTTPHandler, jwtConfig *config.JWTConfig, idempotent gin.HandlerFunc) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 当前用户的礼品卡(需要认证，写操作支持幂等键)
+	giftCardRoutes := v1.Group("/users/me/gift-cards")
+	giftCardRoutes.Use(middleware.JWT(jwtConfig), idempotent)
+	{
+		// 获取购买的礼品卡
+		giftCardRoutes.GET("", handler.ListGiftCards)
+
+		// 凭兑换码查询余额，兑换码放在请求体中，避免出现在访问日志里
+		giftCardRoutes.POST("/balance", handler.CheckBalance)
+
+		// 将礼品卡余额兑换到购物金
+		giftCardRoutes.POST("/redeem", handler.Redeem)
+	}
+}
@@ -0,0 +1,453 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/giftcard"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/wallet"
+	walletService "web3-ecommerce-app/internal/module/wallet/service"
+	"web3-ecommerce-app/internal/platform/notify"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// NotificationTypeIssued 购买的礼品卡已发放，完整的兑换码在通知的Secrets中，以礼品卡ID为key
+const NotificationTypeIssued = "gift_card_issued"
+
+// GiftCardService 礼品卡服务接口
+type GiftCardService interface {
+	// IssueGiftCards 管理员发放礼品卡，完整的兑换码只在返回结果中出现一次
+	IssueGiftCards(ctx context.Context, adminID uint, input giftcard.IssueInput) ([]giftcard.IssuedGiftCard, error)
+
+	// ListGiftCards 按条件分页查询礼品卡
+	ListGiftCards(ctx context.Context, query giftcard.GiftCardQuery) (*giftcard.GiftCardPaginationResult, error)
+
+	// GetGiftCard 获取礼品卡
+	GetGiftCard(ctx context.Context, id uint) (*giftcard.GiftCard, error)
+
+	// ListRedemptions 获取礼品卡的兑换记录
+	ListRedemptions(ctx context.Context, id uint) ([]giftcard.Redemption, error)
+
+	// UpdateGiftCard 修改礼品卡的过期时间和备注
+	UpdateGiftCard(ctx context.Context, id uint, input giftcard.UpdateInput) (*giftcard.GiftCard, error)
+
+	// ChangeStatus 启用或停用礼品卡
+	ChangeStatus(ctx context.Context, id uint, status string) (*giftcard.GiftCard, error)
+
+	// ListDenominations 获取全部礼品卡商品的面额
+	ListDenominations(ctx context.Context) ([]giftcard.Denomination, error)
+
+	// SaveDenomination 将数字商品设置为礼品卡商品，用户购买并支付后按面额发放礼品卡
+	SaveDenomination(ctx context.Context, productID uint, input giftcard.SaveDenominationInput) (*giftcard.Denomination, error)
+
+	// DeleteDenomination 取消商品的礼品卡面额
+	DeleteDenomination(ctx context.Context, productID uint) error
+
+	// CheckBalance 凭兑换码查询礼品卡余额
+	CheckBalance(ctx context.Context, code string) (*giftcard.BalanceView, error)
+
+	// ListUserGiftCards 分页查询用户购买的礼品卡
+	ListUserGiftCards(ctx context.Context, userID uint, page, pageSize int) (*giftcard.GiftCardPaginationResult, error)
+
+	// RedeemToStoreCredit 将礼品卡的全部余额兑换到用户的购物金
+	RedeemToStoreCredit(ctx context.Context, userID uint, code string) (*wallet.Entry, error)
+
+	// RedeemForOrder 下单时从礼品卡兑换最多amount的金额到用户的购物金，返回实际兑换的金额
+	// 在事务中调用时加入该事务，调用方负责从购物金扣减订单的抵扣金额
+	RedeemForOrder(ctx context.Context, userID uint, code string, orderID uint, amount common.Money) (common.Money, error)
+
+	// HandleOrderPaid 处理订单支付事件，订单包含礼品卡商品时按购买数量发放礼品卡并通知买家
+	HandleOrderPaid(ctx context.Context, payload interface{}) error
+
+	// VoidForRefund 订单退款时作废退款数量对应的未使用礼品卡，已被兑换时返回错误阻止退款
+	// 在事务中调用时加入该事务
+	VoidForRefund(ctx context.Context, orderID uint, productID uint, quantity int) error
+}
+
+// DefaultGiftCardService 默认礼品卡服务实现
+type DefaultGiftCardService struct {
+	giftCardRepo  giftcard.GiftCardRepository
+	orderRepo     order.OrderRepository
+	productRepo   product.ProductRepository
+	walletService walletService.WalletService
+	notifier      notify.Notifier
+}
+
+// NewGiftCardService 创建礼品卡服务
+func NewGiftCardService(
+	giftCardRepo giftcard.GiftCardRepository,
+	orderRepo order.OrderRepository,
+	productRepo product.ProductRepository,
+	walletSvc walletService.WalletService,
+	notifier notify.Notifier,
+) GiftCardService {
+	return &DefaultGiftCardService{
+		giftCardRepo:  giftCardRepo,
+		orderRepo:     orderRepo,
+		productRepo:   productRepo,
+		walletService: walletSvc,
+		notifier:      notifier,
+	}
+}
+
+// IssueGiftCards 管理员发放礼品卡
+func (s *DefaultGiftCardService) IssueGiftCards(ctx context.Context, adminID uint, input giftcard.IssueInput) ([]giftcard.IssuedGiftCard, error) {
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, apierror.NewValidationError("过期时间必须晚于当前时间", input.ExpiresAt.Format(time.RFC3339))
+	}
+	quantity := input.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	issued := make([]giftcard.IssuedGiftCard, 0, quantity)
+	err := s.giftCardRepo.Transaction(ctx, func(ctx context.Context) error {
+		for i := 0; i < quantity; i++ {
+			card, err := s.issue(ctx, &giftcard.GiftCard{
+				InitialAmount: input.Amount,
+				Source:        giftcard.SourceAdmin,
+				IssuedBy:      adminID,
+				Note:          strings.TrimSpace(input.Note),
+				ExpiresAt:     input.ExpiresAt,
+			})
+			if err != nil {
+				return err
+			}
+			issued = append(issued, *card)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// ListGiftCards 按条件分页查询礼品卡
+func (s *DefaultGiftCardService) ListGiftCards(ctx context.Context, query giftcard.GiftCardQuery) (*giftcard.GiftCardPaginationResult, error) {
+	query.Page, query.PageSize = normalizePage(query.Page, query.PageSize)
+	return s.giftCardRepo.Find(ctx, query)
+}
+
+// GetGiftCard 获取礼品卡
+func (s *DefaultGiftCardService) GetGiftCard(ctx context.Context, id uint) (*giftcard.GiftCard, error) {
+	return s.giftCardRepo.FindByID(ctx, id)
+}
+
+// ListRedemptions 获取礼品卡的兑换记录
+func (s *DefaultGiftCardService) ListRedemptions(ctx context.Context, id uint) ([]giftcard.Redemption, error) {
+	if _, err := s.giftCardRepo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.giftCardRepo.FindRedemptions(ctx, id)
+}
+
+// UpdateGiftCard 修改礼品卡的过期时间和备注
+func (s *DefaultGiftCardService) UpdateGiftCard(ctx context.Context, id uint, input giftcard.UpdateInput) (*giftcard.GiftCard, error) {
+	return s.mutate(ctx, id, func(card *giftcard.GiftCard) error {
+		if input.ExpiresAt != nil {
+			card.ExpiresAt = input.ExpiresAt
+		}
+		if input.Note != nil {
+			card.Note = strings.TrimSpace(*input.Note)
+		}
+		return nil
+	})
+}
+
+// ChangeStatus 启用或停用礼品卡
+func (s *DefaultGiftCardService) ChangeStatus(ctx context.Context, id uint, status string) (*giftcard.GiftCard, error) {
+	return s.mutate(ctx, id, func(card *giftcard.GiftCard) error {
+		return card.ChangeStatus(status)
+	})
+}
+
+// ListDenominations 获取全部礼品卡商品的面额
+func (s *DefaultGiftCardService) ListDenominations(ctx context.Context) ([]giftcard.Denomination, error) {
+	return s.giftCardRepo.FindDenominations(ctx)
+}
+
+// SaveDenomination 设置礼品卡商品的面额
+// 礼品卡以兑换码交付，只能设置在数字商品上；面额修改只影响之后支付的订单
+func (s *DefaultGiftCardService) SaveDenomination(ctx context.Context, productID uint, input giftcard.SaveDenominationInput) (*giftcard.Denomination, error) {
+	p, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsDigital() {
+		return nil, apierror.NewValidationError("礼品卡只能设置在数字商品上", fmt.Sprintf("商品 %s 是实物商品", p.Name))
+	}
+
+	if err := s.giftCardRepo.SaveDenomination(ctx, &giftcard.Denomination{
+		ProductID: p.ID,
+		Amount:    input.Amount,
+		ValidDays: input.ValidDays,
+	}); err != nil {
+		return nil, err
+	}
+	return s.giftCardRepo.FindDenomination(ctx, p.ID)
+}
+
+// DeleteDenomination 取消商品的礼品卡面额
+func (s *DefaultGiftCardService) DeleteDenomination(ctx context.Context, productID uint) error {
+	return s.giftCardRepo.DeleteDenomination(ctx, productID)
+}
+
+// CheckBalance 凭兑换码查询礼品卡余额
+func (s *DefaultGiftCardService) CheckBalance(ctx context.Context, code string) (*giftcard.BalanceView, error) {
+	card, err := s.giftCardRepo.FindByCodeHash(ctx, giftcard.HashCode(code))
+	if err != nil {
+		return nil, err
+	}
+	return card.View(time.Now()), nil
+}
+
+// ListUserGiftCards 分页查询用户购买的礼品卡
+func (s *DefaultGiftCardService) ListUserGiftCards(ctx context.Context, userID uint, page, pageSize int) (*giftcard.GiftCardPaginationResult, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	return s.giftCardRepo.Find(ctx, giftcard.GiftCardQuery{PurchaserID: userID, Page: page, PageSize: pageSize})
+}
+
+// RedeemToStoreCredit 将礼品卡的全部余额兑换到购物金
+func (s *DefaultGiftCardService) RedeemToStoreCredit(ctx context.Context, userID uint, code string) (*wallet.Entry, error) {
+	var entry *wallet.Entry
+	err := s.giftCardRepo.Transaction(ctx, func(ctx context.Context) error {
+		card, err := s.giftCardRepo.LockByCodeHash(ctx, giftcard.HashCode(code))
+		if err != nil {
+			return err
+		}
+		entry, err = s.redeem(ctx, card, userID, 0, card.Balance)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RedeemForOrder 下单时从礼品卡兑换抵扣所需的金额
+func (s *DefaultGiftCardService) RedeemForOrder(ctx context.Context, userID uint, code string, orderID uint, amount common.Money) (common.Money, error) {
+	var entry *wallet.Entry
+	err := s.giftCardRepo.Transaction(ctx, func(ctx context.Context) error {
+		card, err := s.giftCardRepo.LockByCodeHash(ctx, giftcard.HashCode(code))
+		if err != nil {
+			return err
+		}
+		entry, err = s.redeem(ctx, card, userID, orderID, amount)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return entry.Amount, nil
+}
+
+// HandleOrderPaid 为已支付订单中的礼品卡商品发放礼品卡
+// 锁定订单后检查是否已发放，事件重复投递时不会重复发卡；有效期从支付时间开始计算；
+// 发放前已经退款的数量不再发放
+func (s *DefaultGiftCardService) HandleOrderPaid(ctx context.Context, payload interface{}) error {
+	event, ok := payload.(order.PaidEvent)
+	if !ok {
+		return fmt.Errorf("无效的订单支付事件: %T", payload)
+	}
+
+	var o *order.Order
+	var issued []giftcard.IssuedGiftCard
+	err := s.giftCardRepo.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		o, err = s.orderRepo.LockByID(ctx, event.OrderID)
+		if err != nil {
+			return err
+		}
+		count, err := s.giftCardRepo.CountByOrder(ctx, o.ID)
+		if err != nil || count > 0 {
+			return err
+		}
+		refunds, err := s.orderRepo.FindRefunds(ctx, o.ID)
+		if err != nil {
+			return err
+		}
+		remaining, _ := o.Refundable(refunds)
+
+		for _, item := range o.Items {
+			denomination, err := s.giftCardRepo.FindDenomination(ctx, item.ProductID)
+			if err != nil {
+				return err
+			}
+			if denomination == nil {
+				continue
+			}
+			for i := 0; i < remaining[item.ID].Quantity; i++ {
+				card, err := s.issue(ctx, &giftcard.GiftCard{
+					InitialAmount: denomination.Amount,
+					Source:        giftcard.SourceOrder,
+					OrderID:       o.ID,
+					ProductID:     item.ProductID,
+					PurchaserID:   o.UserID,
+					ExpiresAt:     denomination.ExpiresAt(event.PaidAt),
+				})
+				if err != nil {
+					return err
+				}
+				issued = append(issued, *card)
+			}
+		}
+		return nil
+	})
+	if err != nil || len(issued) == 0 {
+		return err
+	}
+
+	// 正文只包含尾号，完整的兑换码放在Secrets中，不会出现在通知正文和日志里
+	lines := make([]string, 0, len(issued))
+	codes := make(map[string]string, len(issued))
+	for _, card := range issued {
+		line := fmt.Sprintf("尾号 %s 面额 %s", card.Last4, card.InitialAmount)
+		if card.ExpiresAt != nil {
+			line += "，有效期至 " + card.ExpiresAt.Format("2006-01-02 15:04")
+		}
+		lines = append(lines, line)
+		codes[strconv.FormatUint(uint64(card.ID), 10)] = card.Code
+	}
+	if err := s.notifier.Send(ctx, notify.Message{
+		UserID: o.UserID,
+		Type:   NotificationTypeIssued,
+		Title:  "礼品卡已发放",
+		Body:   fmt.Sprintf("订单 %s 购买的礼品卡已发放，兑换码已单独发送，请妥善保管：\n%s", o.OrderSN, strings.Join(lines, "\n")),
+		Data: map[string]string{
+			"order_sn": o.OrderSN,
+			"count":    strconv.Itoa(len(issued)),
+		},
+		Secrets: codes,
+	}); err != nil {
+		log.Printf("发送礼品卡通知失败(用户 %d, 订单 %s): %v", o.UserID, o.OrderSN, err)
+	}
+	log.Printf("订单 %s 已发放 %d 张礼品卡", o.OrderSN, len(issued))
+	return nil
+}
+
+// VoidForRefund 作废订单退款数量对应的礼品卡
+// 订单的礼品卡尚未发放时不需要作废，发放时会扣除已退款的数量；
+// 未使用的礼品卡不足退款数量时说明已被兑换，退款需要先追回兑换的金额
+func (s *DefaultGiftCardService) VoidForRefund(ctx context.Context, orderID uint, productID uint, quantity int) error {
+	return s.giftCardRepo.Transaction(ctx, func(ctx context.Context) error {
+		cards, err := s.giftCardRepo.LockByOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		var candidates []giftcard.GiftCard
+		for _, card := range cards {
+			if card.ProductID == productID && card.Status != giftcard.StatusVoided {
+				candidates = append(candidates, card)
+			}
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+
+		voided := 0
+		for i := range candidates {
+			if voided == quantity {
+				break
+			}
+			card := &candidates[i]
+			if !card.IsUnused() {
+				continue
+			}
+			if err := card.Void(); err != nil {
+				return err
+			}
+			if err := s.giftCardRepo.Update(ctx, card); err != nil {
+				return err
+			}
+			voided++
+		}
+		if voided < quantity {
+			return apierror.NewInvalidStateTransitionError("礼品卡已被使用，不能退款",
+				fmt.Sprintf("订单ID: %d, 可作废 %d 张, 退款数量 %d", orderID, voided, quantity))
+		}
+
+		log.Printf("订单 %d 退款作废了 %d 张礼品卡", orderID, voided)
+		return nil
+	})
+}
+
+// issue 生成兑换码并创建礼品卡，兑换码只保存哈希
+func (s *DefaultGiftCardService) issue(ctx context.Context, card *giftcard.GiftCard) (*giftcard.IssuedGiftCard, error) {
+	code, err := giftcard.GenerateCode()
+	if err != nil {
+		return nil, err
+	}
+	card.CodeHash = giftcard.HashCode(code)
+	card.Last4 = giftcard.Last4(code)
+	card.Balance = card.InitialAmount
+	card.Status = giftcard.StatusActive
+	if err := s.giftCardRepo.Create(ctx, card); err != nil {
+		return nil, err
+	}
+	return &giftcard.IssuedGiftCard{GiftCard: *card, Code: code}, nil
+}
+
+// redeem 从已锁定的礼品卡兑换最多amount的金额，记录兑换并记入用户的购物金
+func (s *DefaultGiftCardService) redeem(ctx context.Context, card *giftcard.GiftCard, userID uint, orderID uint, amount common.Money) (*wallet.Entry, error) {
+	redeemed, err := card.Redeem(amount, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.giftCardRepo.Update(ctx, card); err != nil {
+		return nil, err
+	}
+	if err := s.giftCardRepo.CreateRedemption(ctx, &giftcard.Redemption{
+		GiftCardID:   card.ID,
+		UserID:       userID,
+		OrderID:      orderID,
+		Amount:       redeemed,
+		BalanceAfter: card.Balance,
+	}); err != nil {
+		return nil, err
+	}
+
+	note := fmt.Sprintf("礼品卡 尾号%s 兑换", card.Last4)
+	if orderID != 0 {
+		note = fmt.Sprintf("礼品卡 尾号%s 下单抵扣", card.Last4)
+	}
+	return s.walletService.CreditStoreCredit(ctx, userID, redeemed, wallet.EntryTypeGiftCard, wallet.RefTypeGiftCard, card.ID, note)
+}
+
+// mutate 在事务中锁定礼品卡，执行fn后写回
+func (s *DefaultGiftCardService) mutate(ctx context.Context, id uint, fn func(card *giftcard.GiftCard) error) (*giftcard.GiftCard, error) {
+	var card *giftcard.GiftCard
+	err := s.giftCardRepo.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		card, err = s.giftCardRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := fn(card); err != nil {
+			return err
+		}
+		return s.giftCardRepo.Update(ctx, card)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.giftCardRepo.FindByID(ctx, id)
+}
+
+// normalizePage 规范分页参数
+func normalizePage(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"web3-ecommerce-app/internal/domain/invoice"
+	"web3-ecommerce-app/internal/module/invoice/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InvoiceHTTPHandler 发票HTTP处理器
+type InvoiceHTTPHandler struct {
+	invoiceService service.InvoiceService
+}
+
+// NewInvoiceHTTPHandler 创建发票HTTP处理器
+func NewInvoiceHTTPHandler(invoiceService service.InvoiceService) *InvoiceHTTPHandler {
+	return &InvoiceHTTPHandler{
+		invoiceService: invoiceService,
+	}
+}
+
+// GetInvoice 下载订单发票PDF
+// 通过lang参数选择中文或英文模板，未指定时根据Accept-Language选择
+func (h *InvoiceHTTPHandler) GetInvoice(c *gin.Context) {
+	userID, orderID, ok := h.orderParams(c)
+	if !ok {
+		return
+	}
+
+	doc, body, err := h.invoiceService.GetInvoice(c.Request.Context(), userID, orderID, language(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	h.sendFile(c, doc, body)
+}
+
+// ListDocuments 获取订单的发票和红字发票列表
+func (h *InvoiceHTTPHandler) ListDocuments(c *gin.Context) {
+	userID, orderID, ok := h.orderParams(c)
+	if !ok {
+		return
+	}
+
+	documents, err := h.invoiceService.ListDocuments(c.Request.Context(), userID, orderID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"documents": documents})
+}
+
+// GetCreditNote 下载退款的红字发票PDF
+func (h *InvoiceHTTPHandler) GetCreditNote(c *gin.Context) {
+	userID, orderID, ok := h.orderParams(c)
+	if !ok {
+		return
+	}
+	refundID, err := strconv.ParseUint(c.Param("refund_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的退款ID", err.Error()),
+		})
+		return
+	}
+
+	doc, body, err := h.invoiceService.GetCreditNote(c.Request.Context(), userID, orderID, uint(refundID), language(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	h.sendFile(c, doc, body)
+}
+
+// orderParams 获取当前用户ID和路径中的订单ID，失败时已写入错误响应
+func (h *InvoiceHTTPHandler) orderParams(c *gin.Context) (uint, uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+		})
+		return 0, 0, false
+	}
+
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的订单ID", err.Error()),
+		})
+		return 0, 0, false
+	}
+	return userID.(uint), uint(orderID), true
+}
+
+// sendFile 以附件形式发送单据PDF
+func (h *InvoiceHTTPHandler) sendFile(c *gin.Context, doc *invoice.Document, body io.ReadCloser) {
+	defer body.Close()
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(doc.FileName())))
+	c.Header("Cache-Control", "private, no-store")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, body); err != nil {
+		log.Printf("发送单据 %s 失败: %v", doc.Number, err)
+	}
+}
+
+// language 获取请求的单据语言
+func language(c *gin.Context) string {
+	if lang := c.Query("lang"); lang != "" {
+		return lang
+	}
+	return c.GetHeader("Accept-Language")
+}
+
+// handleError 处理错误
+func (h *InvoiceHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
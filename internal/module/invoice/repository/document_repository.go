@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/invoice"
+	"web3-ecommerce-app/internal/platform/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DocumentModel 是GORM单据模型
+type DocumentModel struct {
+	ID        uint           `gorm:"primarykey"`
+	Type      string         `gorm:"type:varchar(20);not null;uniqueIndex:idx_order_document,priority:1"`
+	Number    string         `gorm:"type:varchar(40);not null;uniqueIndex:idx_number"`
+	OrderID   uint           `gorm:"not null;uniqueIndex:idx_order_document,priority:2"`
+	UserID    uint           `gorm:"not null;index:idx_user_id"`
+	RefundID  uint           `gorm:"not null;default:0;uniqueIndex:idx_order_document,priority:3"`
+	Reference string         `gorm:"type:varchar(40)"`
+	Amount    int64          `gorm:"not null"`
+	TaxTotal  int64          `gorm:"not null;default:0"`
+	Currency  string         `gorm:"type:varchar(20)"`
+	TxHash    string         `gorm:"type:varchar(100)"`
+	Seller    invoice.Seller `gorm:"type:text;serializer:json"`
+	IssuedAt  time.Time      `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (DocumentModel) TableName() string {
+	return "invoice_documents"
+}
+
+// SequenceModel 是GORM单据号序列模型，每个前缀每年一行
+type SequenceModel struct {
+	Series string `gorm:"type:varchar(40);primarykey"`
+	Value  int64  `gorm:"not null;default:0"`
+}
+
+// TableName 指定表名
+func (SequenceModel) TableName() string {
+	return "invoice_sequences"
+}
+
+// GormDocumentRepository 是单据仓库的GORM实现
+type GormDocumentRepository struct {
+	db *gorm.DB
+}
+
+// NewGormDocumentRepository 创建一个新的GORM单据仓库
+func NewGormDocumentRepository(db *gorm.DB) invoice.DocumentRepository {
+	return &GormDocumentRepository{db: db}
+}
+
+// documentToModel 将领域模型转换为GORM模型
+func documentToModel(d *invoice.Document) *DocumentModel {
+	return &DocumentModel{
+		ID:        d.ID,
+		Type:      d.Type,
+		Number:    d.Number,
+		OrderID:   d.OrderID,
+		UserID:    d.UserID,
+		RefundID:  d.RefundID,
+		Reference: d.Reference,
+		Amount:    int64(d.Amount),
+		TaxTotal:  int64(d.TaxTotal),
+		Currency:  d.Currency,
+		TxHash:    d.TxHash,
+		Seller:    d.Seller,
+		IssuedAt:  d.IssuedAt,
+	}
+}
+
+// documentToDomain 将GORM模型转换为领域模型
+func documentToDomain(m *DocumentModel) *invoice.Document {
+	return &invoice.Document{
+		ID:        m.ID,
+		Type:      m.Type,
+		Number:    m.Number,
+		OrderID:   m.OrderID,
+		UserID:    m.UserID,
+		RefundID:  m.RefundID,
+		Reference: m.Reference,
+		Amount:    common.Money(m.Amount),
+		TaxTotal:  common.Money(m.TaxTotal),
+		Currency:  m.Currency,
+		TxHash:    m.TxHash,
+		Seller:    m.Seller,
+		IssuedAt:  m.IssuedAt,
+	}
+}
+
+// FindIssued 查询订单已开具的单据
+func (r *GormDocumentRepository) FindIssued(ctx context.Context, docType string, orderID uint, refundID uint) (*invoice.Document, error) {
+	var models []DocumentModel
+	if err := database.Conn(ctx, r.db).
+		Where("type = ? AND order_id = ? AND refund_id = ?", docType, orderID, refundID).
+		Limit(1).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询单据错误: %w", err)
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+	return documentToDomain(&models[0]), nil
+}
+
+// FindByOrderID 查询订单的全部单据
+func (r *GormDocumentRepository) FindByOrderID(ctx context.Context, orderID uint) ([]invoice.Document, error) {
+	var models []DocumentModel
+	if err := database.Conn(ctx, r.db).Where("order_id = ?", orderID).
+		Order("id ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询订单单据错误: %w", err)
+	}
+
+	documents := make([]invoice.Document, 0, len(models))
+	for i := range models {
+		documents = append(documents, *documentToDomain(&models[i]))
+	}
+	return documents, nil
+}
+
+// Issue 分配单据号并保存单据
+// 锁定序列行使同一序列的开具串行执行，编号没有空缺，同一单据也不会重复开具
+func (r *GormDocumentRepository) Issue(ctx context.Context, doc *invoice.Document, prefix string) error {
+	return database.Transaction(ctx, r.db, func(ctx context.Context) error {
+		tx := database.Conn(ctx, r.db)
+
+		year := doc.IssuedAt.Year()
+		series := fmt.Sprintf("%s-%d", prefix, year)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&SequenceModel{Series: series}).Error; err != nil {
+			return fmt.Errorf("创建单据号序列错误: %w", err)
+		}
+		var sequence SequenceModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("series = ?", series).First(&sequence).Error; err != nil {
+			return fmt.Errorf("锁定单据号序列错误: %w", err)
+		}
+
+		existing, err := r.FindIssued(ctx, doc.Type, doc.OrderID, doc.RefundID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			*doc = *existing
+			return nil
+		}
+
+		sequence.Value++
+		if err := tx.Model(&SequenceModel{}).Where("series = ?", series).
+			Update("value", sequence.Value).Error; err != nil {
+			return fmt.Errorf("更新单据号序列错误: %w", err)
+		}
+
+		doc.Number = invoice.FormatNumber(prefix, year, sequence.Value)
+		model := documentToModel(doc)
+		if err := tx.Create(model).Error; err != nil {
+			return fmt.Errorf("保存单据错误: %w", err)
+		}
+		doc.ID = model.ID
+		return nil
+	})
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormDocumentRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&DocumentModel{}, &SequenceModel{})
+}
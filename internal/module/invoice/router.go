@@ -0,0 +1,29 @@
+package invoice
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/invoice/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册发票模块路由
+func RegisterRoutes(router *gin.Engine, handler *handler.InvoiceHTTPHandler, jwtConfig *config.JWTConfig) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 发票路由(需要认证)
+	invoiceRoutes := v1.Group("/orders/:id")
+	invoiceRoutes.Use(middleware.JWT(jwtConfig))
+	{
+		// 下载订单发票
+		invoiceRoutes.GET("/invoice", handler.GetInvoice)
+
+		// 获取订单的发票和红字发票列表
+		invoiceRoutes.GET("/documents", handler.ListDocuments)
+
+		// 下载退款的红字发票
+		invoiceRoutes.GET("/credit-notes/:refund_id", handler.GetCreditNote)
+	}
+}
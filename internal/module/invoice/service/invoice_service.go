@@ -0,0 +1,231 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/invoice"
+	"web3-ecommerce-app/internal/domain/order"
+	orderService "web3-ecommerce-app/internal/module/order/service"
+	"web3-ecommerce-app/internal/platform/blobstore"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+const (
+	// defaultInvoicePrefix 未配置时的发票号前缀
+	defaultInvoicePrefix = "INV"
+	// defaultCreditNotePrefix 未配置时的红字发票号前缀
+	defaultCreditNotePrefix = "CN"
+	// pdfContentType PDF文件的MIME类型
+	pdfContentType = "application/pdf"
+)
+
+// InvoiceService 发票服务接口
+// 单据在首次查询时开具，开具后的单据号、金额和PDF文件都不再改变
+type InvoiceService interface {
+	// GetInvoice 获取用户自己订单的发票，订单支付后才能开具
+	GetInvoice(ctx context.Context, userID uint, orderID uint, language string) (*invoice.Document, io.ReadCloser, error)
+
+	// ListDocuments 获取用户自己订单的发票和红字发票，同时为尚未开具的退款开具红字发票
+	ListDocuments(ctx context.Context, userID uint, orderID uint) ([]invoice.Document, error)
+
+	// GetCreditNote 获取用户自己订单某次退款的红字发票
+	GetCreditNote(ctx context.Context, userID uint, orderID uint, refundID uint, language string) (*invoice.Document, io.ReadCloser, error)
+}
+
+// DefaultInvoiceService 默认发票服务实现
+type DefaultInvoiceService struct {
+	documentRepo invoice.DocumentRepository
+	orderService orderService.OrderService
+	store        blobstore.BlobStore
+	config       *config.InvoiceConfig
+}
+
+// NewInvoiceService 创建发票服务
+// store应为私有存储，PDF文件只能通过接口下载
+func NewInvoiceService(
+	documentRepo invoice.DocumentRepository,
+	orderService orderService.OrderService,
+	store blobstore.BlobStore,
+	config *config.InvoiceConfig,
+) InvoiceService {
+	return &DefaultInvoiceService{
+		documentRepo: documentRepo,
+		orderService: orderService,
+		store:        store,
+		config:       config,
+	}
+}
+
+// GetInvoice 获取订单发票，未开具时先开具
+func (s *DefaultInvoiceService) GetInvoice(ctx context.Context, userID uint, orderID uint, language string) (*invoice.Document, io.ReadCloser, error) {
+	o, err := s.orderService.GetUserOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := s.issueInvoice(ctx, o)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := s.file(ctx, doc, invoice.NormalizeLanguage(language), func(language string) []byte {
+		return renderInvoice(doc, o, language)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, body, nil
+}
+
+// ListDocuments 获取订单的全部单据
+func (s *DefaultInvoiceService) ListDocuments(ctx context.Context, userID uint, orderID uint) ([]invoice.Document, error) {
+	o, err := s.orderService.GetUserOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := s.issueInvoice(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	refunds, err := s.orderService.ListUserRefunds(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range refunds {
+		if _, err := s.issueCreditNote(ctx, o, inv, &refunds[i]); err != nil {
+			return nil, err
+		}
+	}
+	return s.documentRepo.FindByOrderID(ctx, orderID)
+}
+
+// GetCreditNote 获取退款的红字发票，未开具时先开具
+func (s *DefaultInvoiceService) GetCreditNote(ctx context.Context, userID uint, orderID uint, refundID uint, language string) (*invoice.Document, io.ReadCloser, error) {
+	o, err := s.orderService.GetUserOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	refunds, err := s.orderService.ListUserRefunds(ctx, userID, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var refund *order.Refund
+	for i := range refunds {
+		if refunds[i].ID == refundID {
+			refund = &refunds[i]
+			break
+		}
+	}
+	if refund == nil {
+		return nil, nil, apierror.NewNotFoundError("退款不存在", fmt.Sprintf("ID: %d", refundID))
+	}
+
+	inv, err := s.issueInvoice(ctx, o)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := s.issueCreditNote(ctx, o, inv, refund)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := s.file(ctx, doc, invoice.NormalizeLanguage(language), func(language string) []byte {
+		return renderCreditNote(doc, o, refund, language)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, body, nil
+}
+
+// issueInvoice 开具订单发票，已开具时返回已有的发票
+func (s *DefaultInvoiceService) issueInvoice(ctx context.Context, o *order.Order) (*invoice.Document, error) {
+	if o.PaidAt == nil {
+		return nil, apierror.NewBadRequestError("订单尚未支付", "订单支付后才能开具发票")
+	}
+
+	existing, err := s.documentRepo.FindIssued(ctx, invoice.TypeInvoice, o.ID, 0)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	doc := &invoice.Document{
+		Type:     invoice.TypeInvoice,
+		OrderID:  o.ID,
+		UserID:   o.UserID,
+		Amount:   o.TotalPrice,
+		TaxTotal: o.TaxTotal,
+		Currency: s.config.Currency,
+		TxHash:   o.PaymentTxHash,
+		Seller:   s.seller(),
+		IssuedAt: time.Now(),
+	}
+	if err := s.documentRepo.Issue(ctx, doc, prefixOrDefault(s.config.InvoicePrefix, defaultInvoicePrefix)); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// issueCreditNote 开具退款的红字发票，已开具时返回已有的红字发票
+func (s *DefaultInvoiceService) issueCreditNote(ctx context.Context, o *order.Order, inv *invoice.Document, refund *order.Refund) (*invoice.Document, error) {
+	existing, err := s.documentRepo.FindIssued(ctx, invoice.TypeCreditNote, o.ID, refund.ID)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	doc := &invoice.Document{
+		Type:      invoice.TypeCreditNote,
+		OrderID:   o.ID,
+		UserID:    o.UserID,
+		RefundID:  refund.ID,
+		Reference: inv.Number,
+		Amount:    refund.Amount,
+		TaxTotal:  o.RefundTax(refund),
+		Currency:  inv.Currency,
+		TxHash:    inv.TxHash,
+		Seller:    inv.Seller,
+		IssuedAt:  time.Now(),
+	}
+	if err := s.documentRepo.Issue(ctx, doc, prefixOrDefault(s.config.CreditNotePrefix, defaultCreditNotePrefix)); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// file 读取单据的PDF文件，该语言的文件不存在时生成并保存
+// 单据内容开具后不再改变，同一单据同一语言总是生成相同的文件
+func (s *DefaultInvoiceService) file(ctx context.Context, doc *invoice.Document, language string, render func(language string) []byte) (io.ReadCloser, error) {
+	key := doc.FileKey(language)
+	body, err := s.store.Get(ctx, key)
+	if err == nil {
+		return body, nil
+	}
+	if !errors.Is(err, blobstore.ErrNotFound) {
+		return nil, fmt.Errorf("读取单据文件失败: %w", err)
+	}
+
+	content := render(language)
+	if err := s.store.Put(ctx, key, bytes.NewReader(content), pdfContentType); err != nil {
+		return nil, fmt.Errorf("保存单据文件失败: %w", err)
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// seller 当前配置的销售方信息
+func (s *DefaultInvoiceService) seller() invoice.Seller {
+	return invoice.Seller{
+		Name:    s.config.SellerName,
+		Address: s.config.SellerAddress,
+		TaxID:   s.config.SellerTaxID,
+	}
+}
+
+// prefixOrDefault 返回配置的单据号前缀，未配置时使用默认前缀
+func prefixOrDefault(prefix, fallback string) string {
+	if prefix == "" {
+		return fallback
+	}
+	return prefix
+}
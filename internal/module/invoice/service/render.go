@@ -0,0 +1,439 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/invoice"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/domain/tax"
+	"web3-ecommerce-app/pkg/pdf"
+)
+
+// labels 单据模板中的文字
+type labels struct {
+	Invoice        string
+	CreditNote     string
+	IssuedAt       string
+	OrderSN        string
+	PaidAt         string
+	TxHash         string
+	NoTxHash       string
+	Reference      string
+	Reason         string
+	Seller         string
+	Buyer          string
+	TaxID          string
+	UserID         string
+	Item           string
+	Quantity       string
+	UnitPrice      string
+	TaxRate        string
+	Tax            string
+	Amount         string
+	ShippingLine   string
+	Subtotal       string
+	Discount       string
+	Shipping       string
+	TaxTotal       string
+	Total          string
+	RefundTotal    string
+	TaxIncluded    string
+	AmountsPaid    string
+	Page           string
+	Continued      string
+	DateFormat     string
+	DateTimeFormat string
+}
+
+// templates 按语言区分的单据模板
+var templates = map[string]labels{
+	invoice.LanguageZH: {
+		Invoice:        "发票",
+		CreditNote:     "红字发票",
+		IssuedAt:       "开具日期",
+		OrderSN:        "订单号",
+		PaidAt:         "支付时间",
+		TxHash:         "链上交易哈希",
+		NoTxHash:       "管理员确认支付，无链上交易",
+		Reference:      "原发票号",
+		Reason:         "退款原因",
+		Seller:         "销售方",
+		Buyer:          "购买方",
+		TaxID:          "税号",
+		UserID:         "用户ID",
+		Item:           "商品",
+		Quantity:       "数量",
+		UnitPrice:      "单价",
+		TaxRate:        "税率",
+		Tax:            "税额",
+		Amount:         "金额",
+		ShippingLine:   "运费",
+		Subtotal:       "商品合计",
+		Discount:       "优惠",
+		Shipping:       "运费",
+		TaxTotal:       "税额合计",
+		Total:          "应付金额",
+		RefundTotal:    "退款金额",
+		TaxIncluded:    "价格已包含税额。",
+		AmountsPaid:    "金额为实际退还的金额，已包含税额。",
+		Page:           "第 %d 页",
+		Continued:      "续上页",
+		DateFormat:     "2006年01月02日",
+		DateTimeFormat: "2006-01-02 15:04:05 MST",
+	},
+	invoice.LanguageEN: {
+		Invoice:        "INVOICE",
+		CreditNote:     "CREDIT NOTE",
+		IssuedAt:       "Date of issue",
+		OrderSN:        "Order",
+		PaidAt:         "Paid at",
+		TxHash:         "Transaction hash",
+		NoTxHash:       "Payment confirmed manually, no on-chain transaction",
+		Reference:      "Original invoice",
+		Reason:         "Reason",
+		Seller:         "Seller",
+		Buyer:          "Bill to",
+		TaxID:          "Tax ID",
+		UserID:         "Customer ID",
+		Item:           "Item",
+		Quantity:       "Qty",
+		UnitPrice:      "Unit price",
+		TaxRate:        "Tax rate",
+		Tax:            "Tax",
+		Amount:         "Amount",
+		ShippingLine:   "Shipping",
+		Subtotal:       "Subtotal",
+		Discount:       "Discount",
+		Shipping:       "Shipping",
+		TaxTotal:       "Tax",
+		Total:          "Total due",
+		RefundTotal:    "Total refunded",
+		TaxIncluded:    "Prices include tax.",
+		AmountsPaid:    "Amounts are as refunded and include tax.",
+		Page:           "Page %d",
+		Continued:      "Continued",
+		DateFormat:     "January 2, 2006",
+		DateTimeFormat: "2006-01-02 15:04:05 MST",
+	},
+}
+
+// 版面尺寸，单位为点
+const (
+	marginLeft   = 50.0
+	marginRight  = pdf.PageWidth - 50
+	marginBottom = pdf.PageHeight - 70
+	rowHeight    = 18.0
+)
+
+// 商品表格各列的右边界，商品名称列从左边距开始
+const (
+	colQuantity  = 300.0
+	colUnitPrice = 370.0
+	colTaxRate   = 425.0
+	colTax       = 480.0
+	colAmount    = marginRight
+)
+
+var (
+	styleTitle   = pdf.Style{Size: 20, Bold: true}
+	styleHeading = pdf.Style{Size: 10, Bold: true}
+	styleText    = pdf.Style{Size: 9}
+	styleSmall   = pdf.Style{Size: 8}
+)
+
+// tableRow 商品表格中的一行
+type tableRow struct {
+	Name      string
+	Quantity  string
+	UnitPrice string
+	TaxRate   string
+	Tax       string
+	Amount    string
+}
+
+// totalRow 合计区域中的一行
+type totalRow struct {
+	Label string
+	Value string
+	Bold  bool
+}
+
+// writer 逐行排版单据，内容超出一页时自动换页
+type writer struct {
+	doc    *pdf.Document
+	page   *pdf.Page
+	pages  int
+	y      float64
+	labels labels
+	number string
+}
+
+// newWriter 创建排版器并添加第一页
+func newWriter(labels labels, number string) *writer {
+	w := &writer{doc: pdf.New(), labels: labels, number: number}
+	w.newPage()
+	return w
+}
+
+// newPage 添加一页并绘制页脚
+func (w *writer) newPage() {
+	w.page = w.doc.AddPage()
+	w.pages++
+	w.y = 60
+	w.page.Line(marginLeft, pdf.PageHeight-50, marginRight, pdf.PageHeight-50, 0.5)
+	w.page.Text(marginLeft, pdf.PageHeight-36, styleSmall, w.number)
+	w.page.Text(marginRight, pdf.PageHeight-36, pdf.Style{Size: 8, Align: pdf.AlignRight}, fmt.Sprintf(w.labels.Page, w.pages))
+	if w.pages > 1 {
+		w.page.Text(marginLeft, w.y, styleSmall, w.labels.Continued)
+		w.y += rowHeight
+	}
+}
+
+// ensure 剩余空间不足height时换页，返回是否换了页
+func (w *writer) ensure(height float64) bool {
+	if w.y+height <= marginBottom {
+		return false
+	}
+	w.newPage()
+	return true
+}
+
+// field 绘制"标签: 值"形式的一行，值过长时截断
+func (w *writer) field(label, value string) {
+	w.ensure(rowHeight)
+	w.page.Text(marginLeft, w.y, styleHeading, label)
+	w.page.Text(marginLeft+110, w.y, styleText, pdf.Truncate(value, styleText, marginRight-marginLeft-110))
+	w.y += rowHeight - 4
+}
+
+// header 绘制标题、单据号和订单信息
+func (w *writer) header(title string, doc *invoice.Document, o *order.Order) {
+	w.page.Text(marginLeft, w.y+10, styleTitle, title)
+	w.page.Text(marginRight, w.y+10, pdf.Style{Size: 12, Bold: true, Align: pdf.AlignRight}, doc.Number)
+	w.y += 40
+
+	w.field(w.labels.IssuedAt, doc.IssuedAt.Format(w.labels.DateFormat))
+	w.field(w.labels.OrderSN, o.OrderSN)
+	if o.PaidAt != nil {
+		w.field(w.labels.PaidAt, o.PaidAt.Format(w.labels.DateTimeFormat))
+	}
+	if doc.TxHash != "" {
+		w.field(w.labels.TxHash, doc.TxHash)
+	} else {
+		w.field(w.labels.TxHash, w.labels.NoTxHash)
+	}
+}
+
+// parties 并排绘制销售方和购买方
+func (w *writer) parties(seller invoice.Seller, o *order.Order) {
+	sellerLines := []string{seller.Name}
+	if seller.Address != "" {
+		sellerLines = append(sellerLines, strings.Split(seller.Address, "\n")...)
+	}
+	if seller.TaxID != "" {
+		sellerLines = append(sellerLines, w.labels.TaxID+": "+seller.TaxID)
+	}
+
+	var buyerLines []string
+	if addr := o.ShippingAddress; addr != nil {
+		buyerLines = append(buyerLines, addr.Recipient, addr.Line1)
+		if addr.Line2 != "" {
+			buyerLines = append(buyerLines, addr.Line2)
+		}
+		buyerLines = append(buyerLines,
+			joinNonEmpty(", ", addr.District, addr.City, addr.Province),
+			joinNonEmpty(" ", addr.Country, addr.PostalCode))
+	}
+	buyerLines = append(buyerLines, fmt.Sprintf("%s: %d", w.labels.UserID, o.UserID))
+
+	rows := len(sellerLines)
+	if len(buyerLines) > rows {
+		rows = len(buyerLines)
+	}
+	w.y += 10
+	w.ensure(rowHeight * float64(rows+1))
+
+	half := (marginRight - marginLeft) / 2
+	w.page.Text(marginLeft, w.y, styleHeading, w.labels.Seller)
+	w.page.Text(marginLeft+half, w.y, styleHeading, w.labels.Buyer)
+	w.y += rowHeight - 4
+	for i := 0; i < rows; i++ {
+		if i < len(sellerLines) {
+			w.page.Text(marginLeft, w.y, styleText, pdf.Truncate(sellerLines[i], styleText, half-10))
+		}
+		if i < len(buyerLines) {
+			w.page.Text(marginLeft+half, w.y, styleText, pdf.Truncate(buyerLines[i], styleText, half))
+		}
+		w.y += rowHeight - 6
+	}
+	w.y += 16
+}
+
+// tableHeader 绘制商品表格的表头
+func (w *writer) tableHeader() {
+	w.page.Text(marginLeft, w.y, styleHeading, w.labels.Item)
+	w.row(styleHeading, tableRow{
+		Quantity:  w.labels.Quantity,
+		UnitPrice: w.labels.UnitPrice,
+		TaxRate:   w.labels.TaxRate,
+		Tax:       w.labels.Tax,
+		Amount:    w.labels.Amount,
+	})
+	w.page.Line(marginLeft, w.y+5, marginRight, w.y+5, 0.8)
+	w.y += rowHeight
+}
+
+// row 绘制表格一行中右对齐的各列
+func (w *writer) row(style pdf.Style, r tableRow) {
+	style.Align = pdf.AlignRight
+	w.page.Text(colQuantity, w.y, style, r.Quantity)
+	w.page.Text(colUnitPrice, w.y, style, r.UnitPrice)
+	w.page.Text(colTaxRate, w.y, style, r.TaxRate)
+	w.page.Text(colTax, w.y, style, r.Tax)
+	w.page.Text(colAmount, w.y, style, r.Amount)
+}
+
+// table 绘制商品表格，换页时重复表头
+func (w *writer) table(rows []tableRow) {
+	w.ensure(rowHeight * 2)
+	w.tableHeader()
+	for _, r := range rows {
+		if w.ensure(rowHeight) {
+			w.tableHeader()
+		}
+		w.page.Text(marginLeft, w.y, styleText, pdf.Truncate(r.Name, styleText, colQuantity-marginLeft-40))
+		w.row(styleText, r)
+		w.y += rowHeight
+	}
+	w.page.Line(marginLeft, w.y-rowHeight+5, marginRight, w.y-rowHeight+5, 0.5)
+	w.y += 6
+}
+
+// totals 在右侧绘制合计区域
+func (w *writer) totals(rows []totalRow) {
+	w.ensure(rowHeight * float64(len(rows)))
+	for _, r := range rows {
+		style := styleText
+		if r.Bold {
+			style = styleHeading
+			w.page.Line(colTaxRate-40, w.y-12, marginRight, w.y-12, 0.5)
+		}
+		w.page.Text(colTaxRate-40, w.y, style, r.Label)
+		style.Align = pdf.AlignRight
+		w.page.Text(colAmount, w.y, style, r.Value)
+		w.y += rowHeight
+	}
+}
+
+// note 绘制说明文字
+func (w *writer) note(s string) {
+	w.ensure(rowHeight)
+	w.y += 6
+	w.page.Text(marginLeft, w.y, styleSmall, pdf.Truncate(s, styleSmall, marginRight-marginLeft))
+	w.y += rowHeight - 6
+}
+
+// renderInvoice 生成发票PDF
+func renderInvoice(doc *invoice.Document, o *order.Order, language string) []byte {
+	l := templates[language]
+	w := newWriter(l, doc.Number)
+	w.header(l.Invoice, doc, o)
+	w.parties(doc.Seller, o)
+
+	rows := make([]tableRow, 0, len(o.Items)+1)
+	for _, item := range o.Items {
+		rows = append(rows, tableRow{
+			Name:      item.ProductName,
+			Quantity:  strconv.Itoa(item.Quantity),
+			UnitPrice: item.Price.String(),
+			TaxRate:   tax.FormatRate(item.TaxRate),
+			Tax:       item.TaxAmount.String(),
+			Amount:    item.Total.String(),
+		})
+	}
+	w.table(rows)
+
+	totals := []totalRow{{Label: l.Subtotal, Value: o.Subtotal.String()}}
+	if o.DiscountTotal > 0 {
+		totals = append(totals, totalRow{Label: l.Discount, Value: (-o.DiscountTotal).String()})
+	}
+	if o.ShippingAddress != nil {
+		totals = append(totals, totalRow{Label: l.Shipping, Value: o.ShippingFee.String()})
+	}
+	totals = append(totals,
+		totalRow{Label: l.TaxTotal, Value: doc.TaxTotal.String()},
+		totalRow{Label: l.Total, Value: money(doc.Amount, doc.Currency), Bold: true},
+	)
+	w.totals(totals)
+	if o.TaxInclusive {
+		w.note(l.TaxIncluded)
+	}
+	return w.doc.Bytes()
+}
+
+// renderCreditNote 生成红字发票PDF，列出退款涉及的订单行和运费
+func renderCreditNote(doc *invoice.Document, o *order.Order, refund *order.Refund, language string) []byte {
+	l := templates[language]
+	w := newWriter(l, doc.Number)
+	w.header(l.CreditNote, doc, o)
+	w.field(l.Reference, doc.Reference)
+	if refund.Reason != "" {
+		w.field(l.Reason, refund.Reason)
+	}
+	w.parties(doc.Seller, o)
+
+	items := make(map[uint]*order.OrderItem, len(o.Items))
+	for i := range o.Items {
+		items[o.Items[i].ID] = &o.Items[i]
+	}
+	rows := make([]tableRow, 0, len(refund.Items)+1)
+	for _, refunded := range refund.Items {
+		row := tableRow{
+			Quantity: strconv.Itoa(refunded.Quantity),
+			Amount:   (-refunded.Amount).String(),
+		}
+		if item, ok := items[refunded.OrderItemID]; ok {
+			row.Name = item.ProductName
+			row.UnitPrice = item.Price.String()
+			row.TaxRate = tax.FormatRate(item.TaxRate)
+			row.Tax = (-o.RefundTax(&order.Refund{Items: []order.RefundItem{refunded}})).String()
+		}
+		rows = append(rows, row)
+	}
+	if refund.ShippingAmount > 0 {
+		rows = append(rows, tableRow{
+			Name:   l.ShippingLine,
+			Tax:    (-o.RefundTax(&order.Refund{ShippingAmount: refund.ShippingAmount})).String(),
+			Amount: (-refund.ShippingAmount).String(),
+		})
+	}
+	w.table(rows)
+
+	w.totals([]totalRow{
+		{Label: l.TaxTotal, Value: (-doc.TaxTotal).String()},
+		{Label: l.RefundTotal, Value: money(-doc.Amount, doc.Currency), Bold: true},
+	})
+	w.note(l.AmountsPaid)
+	return w.doc.Bytes()
+}
+
+// money 格式化带币种的金额
+func money(amount common.Money, currency string) string {
+	if currency == "" {
+		return amount.String()
+	}
+	return amount.String() + " " + currency
+}
+
+// joinNonEmpty 用sep连接非空的字符串
+func joinNonEmpty(sep string, parts ...string) string {
+	values := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != "" {
+			values = append(values, p)
+		}
+	}
+	return strings.Join(values, sep)
+}
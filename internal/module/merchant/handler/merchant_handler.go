@@ -0,0 +1,431 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"web3-ecommerce-app/internal/domain/merchant"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/module/merchant/service"
+	orderService "web3-ecommerce-app/internal/module/order/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MerchantHTTPHandler 商家HTTP处理器，提供店铺页面、入驻申请和商家后台接口
+type MerchantHTTPHandler struct {
+	merchantService service.MerchantService
+	orderService    orderService.OrderService
+}
+
+// NewMerchantHTTPHandler 创建商家HTTP处理器
+func NewMerchantHTTPHandler(merchantService service.MerchantService, orderService orderService.OrderService) *MerchantHTTPHandler {
+	return &MerchantHTTPHandler{
+		merchantService: merchantService,
+		orderService:    orderService,
+	}
+}
+
+// GetStorefront 获取店铺信息
+func (h *MerchantHTTPHandler) GetStorefront(c *gin.Context) {
+	m, err := h.merchantService.GetStorefront(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// ListStorefrontProducts 获取店铺的商品列表
+func (h *MerchantHTTPHandler) ListStorefrontProducts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	categoryID, _ := strconv.ParseUint(c.DefaultQuery("category_id", "0"), 10, 32)
+
+	result, err := h.merchantService.ListStorefrontProducts(c.Request.Context(), c.Param("slug"), product.ProductQuery{
+		Page:       page,
+		PageSize:   pageSize,
+		CategoryID: uint(categoryID),
+		Search:     c.DefaultQuery("search", ""),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Apply 申请入驻
+func (h *MerchantHTTPHandler) Apply(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var input merchant.ApplyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	m, err := h.merchantService.Apply(c.Request.Context(), userID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, m)
+}
+
+// GetApplication 获取当前用户的入驻申请及审核结果
+func (h *MerchantHTTPHandler) GetApplication(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	m, err := h.merchantService.GetApplication(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// GetProfile 获取当前商家的店铺资料
+func (h *MerchantHTTPHandler) GetProfile(c *gin.Context) {
+	m, ok := h.currentMerchant(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// UpdateProfile 修改店铺资料
+func (h *MerchantHTTPHandler) UpdateProfile(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var input merchant.UpdateProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	m, err := h.merchantService.UpdateProfile(c.Request.Context(), userID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// ListProducts 获取当前商家的商品列表，可按状态过滤
+func (h *MerchantHTTPHandler) ListProducts(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	query := product.ProductQuery{
+		Page:     page,
+		PageSize: pageSize,
+		Search:   c.DefaultQuery("search", ""),
+	}
+	if status := c.DefaultQuery("status", ""); status != "" {
+		query.Statuses = []string{status}
+	}
+
+	result, err := h.merchantService.ListProducts(c.Request.Context(), userID, query)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateProduct 创建商品
+func (h *MerchantHTTPHandler) CreateProduct(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var input product.CreateProductInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	productEntity, err := h.merchantService.CreateProduct(c.Request.Context(), userID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, productEntity)
+}
+
+// GetProduct 获取商品详情
+func (h *MerchantHTTPHandler) GetProduct(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getID(c, "无效的商品ID")
+	if !ok {
+		return
+	}
+
+	productEntity, err := h.merchantService.GetProduct(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, productEntity)
+}
+
+// UpdateProduct 更新商品
+func (h *MerchantHTTPHandler) UpdateProduct(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getID(c, "无效的商品ID")
+	if !ok {
+		return
+	}
+
+	var input product.UpdateProductInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	productEntity, err := h.merchantService.UpdateProduct(c.Request.Context(), userID, id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, productEntity)
+}
+
+// UpdateProductStatus 修改商品状态
+func (h *MerchantHTTPHandler) UpdateProductStatus(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getID(c, "无效的商品ID")
+	if !ok {
+		return
+	}
+
+	var input product.ChangeStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	productEntity, err := h.merchantService.ChangeProductStatus(c.Request.Context(), userID, id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, productEntity)
+}
+
+// AdjustProductStock 调整商品库存
+func (h *MerchantHTTPHandler) AdjustProductStock(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getID(c, "无效的商品ID")
+	if !ok {
+		return
+	}
+
+	var input product.AdjustStockInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	productEntity, err := h.merchantService.AdjustStock(c.Request.Context(), userID, id, input.Delta)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, productEntity)
+}
+
+// ListOrders 获取当前商家的子订单，可按子订单状态过滤
+func (h *MerchantHTTPHandler) ListOrders(c *gin.Context) {
+	m, ok := h.currentMerchant(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	result, err := h.orderService.ListMerchantSubOrders(c.Request.Context(), m.ID, order.SubOrderQuery{
+		Status:   c.DefaultQuery("status", ""),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetOrder 获取子订单详情，包括收货地址和该商家的订单行
+func (h *MerchantHTTPHandler) GetOrder(c *gin.Context) {
+	m, ok := h.currentMerchant(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getID(c, "无效的子订单ID")
+	if !ok {
+		return
+	}
+
+	subOrder, err := h.orderService.GetMerchantSubOrder(c.Request.Context(), m.ID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subOrder)
+}
+
+// CreateShipment 为子订单发货
+func (h *MerchantHTTPHandler) CreateShipment(c *gin.Context) {
+	m, ok := h.currentMerchant(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getID(c, "无效的子订单ID")
+	if !ok {
+		return
+	}
+
+	var input order.CreateShipmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	shipment, err := h.orderService.CreateMerchantShipment(c.Request.Context(), m.ID, id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, shipment)
+}
+
+// RequireActiveMerchant 商家后台中间件，每个请求都重新检查商家状态
+// 商家被暂停后JWT中的用户类型不会变化，只靠MerchantRequired无法拦截
+func (h *MerchantHTTPHandler) RequireActiveMerchant(c *gin.Context) {
+	m, ok := h.loadMerchant(c)
+	if !ok {
+		c.Abort()
+		return
+	}
+	c.Set("merchant", m)
+	c.Next()
+}
+
+// currentMerchant 获取当前用户的商家，失败时直接写入错误响应
+func (h *MerchantHTTPHandler) currentMerchant(c *gin.Context) (*merchant.Merchant, bool) {
+	if m, exists := c.Get("merchant"); exists {
+		return m.(*merchant.Merchant), true
+	}
+	return h.loadMerchant(c)
+}
+
+// loadMerchant 查询当前用户营业中的商家，失败时直接写入错误响应
+func (h *MerchantHTTPHandler) loadMerchant(c *gin.Context) (*merchant.Merchant, bool) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return nil, false
+	}
+	m, err := h.merchantService.CurrentMerchant(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return nil, false
+	}
+	return m, true
+}
+
+// getID 解析路径中的ID，失败时直接写入错误响应
+func (h *MerchantHTTPHandler) getID(c *gin.Context, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError(message, err.Error()),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// getUserID 获取当前登录用户ID，失败时直接写入错误响应
+func (h *MerchantHTTPHandler) getUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// handleError 处理错误
+func (h *MerchantHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
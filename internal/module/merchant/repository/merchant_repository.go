@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/merchant"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MerchantModel 是GORM商家模型
+type MerchantModel struct {
+	ID             uint   `gorm:"primarykey"`
+	OwnerID        uint   `gorm:"not null;uniqueIndex:idx_owner_id"`
+	Name           string `gorm:"type:varchar(100);not null"`
+	Slug           string `gorm:"type:varchar(50);not null;uniqueIndex:idx_slug"`
+	Description    string `gorm:"type:text"`
+	LogoURL        string `gorm:"type:varchar(500)"`
+	Status         string `gorm:"type:varchar(20);not null;default:'pending';index:idx_status"`
+	CommissionRate int    `gorm:"not null;default:0"`
+	ReviewNote     string `gorm:"type:varchar(500)"`
+	ReviewedBy     uint
+	ReviewedAt     *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TableName 指定表名
+func (MerchantModel) TableName() string {
+	return "merchants"
+}
+
+// GormMerchantRepository 是商家仓库的GORM实现
+type GormMerchantRepository struct {
+	db *gorm.DB
+}
+
+// NewGormMerchantRepository 创建一个新的GORM商家仓库
+func NewGormMerchantRepository(db *gorm.DB) merchant.MerchantRepository {
+	return &GormMerchantRepository{db: db}
+}
+
+// merchantToModel 将领域模型转换为GORM模型
+func merchantToModel(m *merchant.Merchant) *MerchantModel {
+	return &MerchantModel{
+		ID:             m.ID,
+		OwnerID:        m.OwnerID,
+		Name:           m.Name,
+		Slug:           m.Slug,
+		Description:    m.Description,
+		LogoURL:        m.LogoURL,
+		Status:         m.Status,
+		CommissionRate: m.CommissionRate,
+		ReviewNote:     m.ReviewNote,
+		ReviewedBy:     m.ReviewedBy,
+		ReviewedAt:     m.ReviewedAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// merchantToDomain 将GORM模型转换为领域模型
+func merchantToDomain(m *MerchantModel) *merchant.Merchant {
+	return &merchant.Merchant{
+		ID:             m.ID,
+		OwnerID:        m.OwnerID,
+		Name:           m.Name,
+		Slug:           m.Slug,
+		Description:    m.Description,
+		LogoURL:        m.LogoURL,
+		Status:         m.Status,
+		CommissionRate: m.CommissionRate,
+		ReviewNote:     m.ReviewNote,
+		ReviewedBy:     m.ReviewedBy,
+		ReviewedAt:     m.ReviewedAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// FindByID 根据ID查询商家
+func (r *GormMerchantRepository) FindByID(ctx context.Context, id uint) (*merchant.Merchant, error) {
+	var model MerchantModel
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("商家不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询商家错误: %w", err)
+	}
+	return merchantToDomain(&model), nil
+}
+
+// FindBySlug 根据店铺地址查询商家
+func (r *GormMerchantRepository) FindBySlug(ctx context.Context, slug string) (*merchant.Merchant, error) {
+	var model MerchantModel
+	if err := database.Conn(ctx, r.db).Where("slug = ?", slug).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("店铺不存在", fmt.Sprintf("店铺地址: %s", slug))
+		}
+		return nil, fmt.Errorf("查询商家错误: %w", err)
+	}
+	return merchantToDomain(&model), nil
+}
+
+// FindByOwnerID 查询用户拥有的商家
+func (r *GormMerchantRepository) FindByOwnerID(ctx context.Context, ownerID uint) (*merchant.Merchant, error) {
+	var models []MerchantModel
+	if err := database.Conn(ctx, r.db).Where("owner_id = ?", ownerID).Limit(1).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询商家错误: %w", err)
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+	return merchantToDomain(&models[0]), nil
+}
+
+// Find 按条件分页查询商家
+func (r *GormMerchantRepository) Find(ctx context.Context, query merchant.MerchantQuery) (*merchant.MerchantPaginationResult, error) {
+	db := database.Conn(ctx, r.db).Model(&MerchantModel{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Search != "" {
+		like := "%" + query.Search + "%"
+		db = db.Where("name LIKE ? OR slug LIKE ?", like, like)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询商家总数错误: %w", err)
+	}
+
+	var models []MerchantModel
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order("id DESC").Offset(offset).Limit(query.PageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询商家列表错误: %w", err)
+	}
+
+	merchants := make([]merchant.Merchant, 0, len(models))
+	for i := range models {
+		merchants = append(merchants, *merchantToDomain(&models[i]))
+	}
+	return &merchant.MerchantPaginationResult{Total: int(total), Merchants: merchants}, nil
+}
+
+// Create 创建商家
+func (r *GormMerchantRepository) Create(ctx context.Context, m *merchant.Merchant) error {
+	model := merchantToModel(m)
+	conn := database.Conn(ctx, r.db)
+	if err := conn.Create(model).Error; err != nil {
+		if conn.Where("slug = ?", m.Slug).First(&MerchantModel{}).Error == nil {
+			return apierror.NewDuplicateEntityError("店铺地址已被使用", m.Slug)
+		}
+		if conn.Where("owner_id = ?", m.OwnerID).First(&MerchantModel{}).Error == nil {
+			return apierror.NewDuplicateEntityError("已经申请过入驻", fmt.Sprintf("用户ID: %d", m.OwnerID))
+		}
+		return fmt.Errorf("创建商家错误: %w", err)
+	}
+
+	*m = *merchantToDomain(model)
+	return nil
+}
+
+// Update 更新商家
+func (r *GormMerchantRepository) Update(ctx context.Context, m *merchant.Merchant) error {
+	model := merchantToModel(m)
+	result := database.Conn(ctx, r.db).Model(&MerchantModel{}).Where("id = ?", m.ID).
+		Select("name", "slug", "description", "logo_url", "status", "commission_rate",
+			"review_note", "reviewed_by", "reviewed_at", "updated_at").
+		Updates(model)
+	if result.Error != nil {
+		if database.Conn(ctx, r.db).Where("slug = ? AND id <> ?", m.Slug, m.ID).First(&MerchantModel{}).Error == nil {
+			return apierror.NewDuplicateEntityError("店铺地址已被使用", m.Slug)
+		}
+		return fmt.Errorf("更新商家错误: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apierror.NewNotFoundError("商家不存在", fmt.Sprintf("ID: %d", m.ID))
+	}
+	return nil
+}
+
+// LockByID 在事务中锁定商家行并返回最新的商家
+func (r *GormMerchantRepository) LockByID(ctx context.Context, id uint) (*merchant.Merchant, error) {
+	var model MerchantModel
+	if err := database.Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("商家不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("锁定商家错误: %w", err)
+	}
+	return merchantToDomain(&model), nil
+}
+
+// Transaction 在事务中执行fn
+func (r *GormMerchantRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.Transaction(ctx, r.db, fn)
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormMerchantRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&MerchantModel{})
+}
@@ -0,0 +1,59 @@
+package merchant
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/merchant/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册商家模块路由
+// 入驻审核和商家管理接口由admin模块统一提供
+func RegisterRoutes(router *gin.Engine, handler *handler.MerchantHTTPHandler, jwtConfig *config.JWTConfig, idempotent gin.HandlerFunc) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 入驻申请(需要认证)
+	applicationRoutes := v1.Group("/merchants")
+	applicationRoutes.Use(middleware.JWT(jwtConfig), idempotent)
+	{
+		// 申请入驻，被拒绝后可以重新申请
+		applicationRoutes.POST("/apply", handler.Apply)
+
+		// 获取自己的入驻申请及审核结果
+		applicationRoutes.GET("/application", handler.GetApplication)
+	}
+
+	// 店铺页面(公开)
+	storefrontRoutes := v1.Group("/merchants")
+	{
+		// 获取店铺信息
+		storefrontRoutes.GET("/:slug", handler.GetStorefront)
+
+		// 获取店铺的商品列表
+		storefrontRoutes.GET("/:slug/products", handler.ListStorefrontProducts)
+	}
+
+	// 商家后台(需要认证、商家权限且商家营业中，写操作支持幂等键)
+	dashboardRoutes := v1.Group("/merchant")
+	dashboardRoutes.Use(middleware.JWT(jwtConfig), middleware.MerchantRequired(), handler.RequireActiveMerchant, idempotent)
+	{
+		// 店铺资料
+		dashboardRoutes.GET("/profile", handler.GetProfile)
+		dashboardRoutes.PUT("/profile", handler.UpdateProfile)
+
+		// 商品管理，只能管理自己的商品
+		dashboardRoutes.GET("/products", handler.ListProducts)
+		dashboardRoutes.POST("/products", handler.CreateProduct)
+		dashboardRoutes.GET("/products/:id", handler.GetProduct)
+		dashboardRoutes.PUT("/products/:id", handler.UpdateProduct)
+		dashboardRoutes.PATCH("/products/:id/status", handler.UpdateProductStatus)
+		dashboardRoutes.PATCH("/products/:id/stock", handler.AdjustProductStock)
+
+		// 子订单和发货
+		dashboardRoutes.GET("/orders", handler.ListOrders)
+		dashboardRoutes.GET("/orders/:id", handler.GetOrder)
+		dashboardRoutes.POST("/orders/:id/shipments", handler.CreateShipment)
+	}
+}
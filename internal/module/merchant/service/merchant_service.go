@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/merchant"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/user"
+	productService "web3-ecommerce-app/internal/module/product/service"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// MerchantService 商家服务接口
+type MerchantService interface {
+	// Apply 用户申请入驻，每个用户只能拥有一个商家，被拒绝后可以修改资料重新申请
+	Apply(ctx context.Context, userID uint, input merchant.ApplyInput) (*merchant.Merchant, error)
+
+	// GetApplication 获取用户的入驻申请及审核结果
+	GetApplication(ctx context.Context, userID uint) (*merchant.Merchant, error)
+
+	// GetStorefront 根据店铺地址获取营业中的店铺
+	GetStorefront(ctx context.Context, slug string) (*merchant.Merchant, error)
+
+	// ListStorefrontProducts 分页查询店铺对外可见的商品
+	ListStorefrontProducts(ctx context.Context, slug string, query product.ProductQuery) (*product.ProductPaginationResult, error)
+
+	// CurrentMerchant 获取用户拥有的营业中的商家，待审核、被拒绝和被暂停的商家不能进入商家后台
+	CurrentMerchant(ctx context.Context, userID uint) (*merchant.Merchant, error)
+
+	// UpdateProfile 商家修改店铺资料
+	UpdateProfile(ctx context.Context, userID uint, input merchant.UpdateProfileInput) (*merchant.Merchant, error)
+
+	// ListProducts 分页查询商家自己的商品
+	ListProducts(ctx context.Context, userID uint, query product.ProductQuery) (*product.ProductPaginationResult, error)
+
+	// CreateProduct 商家创建商品，商品归属于当前商家
+	CreateProduct(ctx context.Context, userID uint, input product.CreateProductInput) (*product.Product, error)
+
+	// GetProduct 获取商家自己的商品，其他商家和平台自营的商品视为不存在
+	GetProduct(ctx context.Context, userID uint, id uint) (*product.Product, error)
+
+	// UpdateProduct 更新商家自己的商品
+	UpdateProduct(ctx context.Context, userID uint, id uint, input product.UpdateProductInput) (*product.Product, error)
+
+	// ChangeProductStatus 修改商家自己的商品状态
+	ChangeProductStatus(ctx context.Context, userID uint, id uint, input product.ChangeStatusInput) (*product.Product, error)
+
+	// AdjustStock 调整商家自己的商品库存
+	AdjustStock(ctx context.Context, userID uint, id uint, delta int) (*product.Product, error)
+
+	// ListMerchants 管理员分页查询商家
+	ListMerchants(ctx context.Context, query merchant.MerchantQuery) (*merchant.MerchantPaginationResult, error)
+
+	// GetMerchant 管理员获取商家
+	GetMerchant(ctx context.Context, id uint) (*merchant.Merchant, error)
+
+	// ReviewMerchant 管理员审核入驻申请、暂停或恢复商家，可同时调整佣金比例
+	ReviewMerchant(ctx context.Context, id uint, adminID uint, input merchant.ReviewInput) (*merchant.Merchant, error)
+}
+
+// DefaultMerchantService 默认商家服务实现
+type DefaultMerchantService struct {
+	merchantRepo   merchant.MerchantRepository
+	userRepo       user.UserRepository
+	productService productService.ProductService
+	config         *config.MerchantConfig
+}
+
+// NewMerchantService 创建商家服务
+func NewMerchantService(
+	merchantRepo merchant.MerchantRepository,
+	userRepo user.UserRepository,
+	productSvc productService.ProductService,
+	config *config.MerchantConfig,
+) MerchantService {
+	return &DefaultMerchantService{
+		merchantRepo:   merchantRepo,
+		userRepo:       userRepo,
+		productService: productSvc,
+		config:         config,
+	}
+}
+
+// Apply 申请入驻
+func (s *DefaultMerchantService) Apply(ctx context.Context, userID uint, input merchant.ApplyInput) (*merchant.Merchant, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.merchantRepo.FindByOwnerID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Status != merchant.MerchantStatusRejected {
+		return nil, apierror.NewDuplicateEntityError("已经申请过入驻", fmt.Sprintf("当前状态: %s", existing.Status))
+	}
+
+	if existing != nil {
+		existing.Name = input.Name
+		existing.Slug = input.Slug
+		existing.Description = input.Description
+		existing.LogoURL = strings.TrimSpace(input.LogoURL)
+		existing.Status = merchant.MerchantStatusPending
+		existing.ReviewNote = ""
+		existing.UpdatedAt = time.Now()
+		if err := s.merchantRepo.Update(ctx, existing); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+
+	m := &merchant.Merchant{
+		OwnerID:        userID,
+		Name:           input.Name,
+		Slug:           input.Slug,
+		Description:    input.Description,
+		LogoURL:        strings.TrimSpace(input.LogoURL),
+		Status:         merchant.MerchantStatusPending,
+		CommissionRate: s.config.CommissionRate,
+	}
+	if err := s.merchantRepo.Create(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GetApplication 获取用户的入驻申请
+func (s *DefaultMerchantService) GetApplication(ctx context.Context, userID uint) (*merchant.Merchant, error) {
+	m, err := s.merchantRepo.FindByOwnerID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, apierror.NewNotFoundError("尚未申请入驻", fmt.Sprintf("用户ID: %d", userID))
+	}
+	return m, nil
+}
+
+// GetStorefront 获取店铺，未营业的店铺视为不存在
+func (s *DefaultMerchantService) GetStorefront(ctx context.Context, slug string) (*merchant.Merchant, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	m, err := s.merchantRepo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !m.IsActive() {
+		return nil, apierror.NewNotFoundError("店铺不存在", fmt.Sprintf("店铺地址: %s", slug))
+	}
+	return m, nil
+}
+
+// ListStorefrontProducts 查询店铺的商品，只返回对外可见的商品
+func (s *DefaultMerchantService) ListStorefrontProducts(ctx context.Context, slug string, query product.ProductQuery) (*product.ProductPaginationResult, error) {
+	m, err := s.GetStorefront(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	query.MerchantID = m.ID
+	query.Statuses = product.VisibleProductStatuses
+	return s.productService.ListProducts(ctx, query)
+}
+
+// CurrentMerchant 获取用户拥有的营业中的商家
+// 商家被暂停后用户的JWT仍然是商家类型，因此每个商家后台请求都要重新检查商家状态
+func (s *DefaultMerchantService) CurrentMerchant(ctx context.Context, userID uint) (*merchant.Merchant, error) {
+	m, err := s.merchantRepo.FindByOwnerID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || (m.Status != merchant.MerchantStatusActive && m.Status != merchant.MerchantStatusSuspended) {
+		return nil, apierror.NewForbiddenError("权限不足", "入驻申请尚未通过审核")
+	}
+	if !m.IsActive() {
+		return nil, apierror.NewForbiddenError("商家已被暂停", "暂停期间不能使用商家后台")
+	}
+	return m, nil
+}
+
+// UpdateProfile 修改店铺资料
+func (s *DefaultMerchantService) UpdateProfile(ctx context.Context, userID uint, input merchant.UpdateProfileInput) (*merchant.Merchant, error) {
+	m, err := s.CurrentMerchant(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		m.Name = strings.TrimSpace(*input.Name)
+		if m.Name == "" {
+			return nil, apierror.NewValidationError("店铺名称不能为空", "")
+		}
+	}
+	if input.Description != nil {
+		m.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.LogoURL != nil {
+		m.LogoURL = strings.TrimSpace(*input.LogoURL)
+	}
+	m.UpdatedAt = time.Now()
+
+	if err := s.merchantRepo.Update(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ListProducts 查询商家自己的商品，包括未上架的商品
+func (s *DefaultMerchantService) ListProducts(ctx context.Context, userID uint, query product.ProductQuery) (*product.ProductPaginationResult, error) {
+	m, err := s.CurrentMerchant(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	query.MerchantID = m.ID
+	return s.productService.ListProducts(ctx, query)
+}
+
+// CreateProduct 创建商品
+func (s *DefaultMerchantService) CreateProduct(ctx context.Context, userID uint, input product.CreateProductInput) (*product.Product, error) {
+	m, err := s.CurrentMerchant(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	input.MerchantID = m.ID
+	return s.productService.CreateProduct(ctx, input)
+}
+
+// GetProduct 获取商家自己的商品
+func (s *DefaultMerchantService) GetProduct(ctx context.Context, userID uint, id uint) (*product.Product, error) {
+	m, err := s.CurrentMerchant(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ownedProduct(ctx, m, id)
+}
+
+// UpdateProduct 更新商品
+func (s *DefaultMerchantService) UpdateProduct(ctx context.Context, userID uint, id uint, input product.UpdateProductInput) (*product.Product, error) {
+	if _, err := s.activeProduct(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.productService.UpdateProduct(ctx, id, input)
+}
+
+// ChangeProductStatus 修改商品状态
+func (s *DefaultMerchantService) ChangeProductStatus(ctx context.Context, userID uint, id uint, input product.ChangeStatusInput) (*product.Product, error) {
+	if _, err := s.activeProduct(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.productService.ChangeProductStatus(ctx, id, input)
+}
+
+// AdjustStock 调整库存
+func (s *DefaultMerchantService) AdjustStock(ctx context.Context, userID uint, id uint, delta int) (*product.Product, error) {
+	if _, err := s.activeProduct(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.productService.AdjustStock(ctx, id, delta)
+}
+
+// ListMerchants 分页查询商家
+func (s *DefaultMerchantService) ListMerchants(ctx context.Context, query merchant.MerchantQuery) (*merchant.MerchantPaginationResult, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	query.Search = strings.TrimSpace(query.Search)
+	return s.merchantRepo.Find(ctx, query)
+}
+
+// GetMerchant 获取商家
+func (s *DefaultMerchantService) GetMerchant(ctx context.Context, id uint) (*merchant.Merchant, error) {
+	return s.merchantRepo.FindByID(ctx, id)
+}
+
+// ReviewMerchant 审核商家
+// 审核通过时将所有者设为商家用户，用户类型和商家状态在同一个事务中更新
+func (s *DefaultMerchantService) ReviewMerchant(ctx context.Context, id uint, adminID uint, input merchant.ReviewInput) (*merchant.Merchant, error) {
+	if input.CommissionRate != nil {
+		if err := merchant.ValidateCommissionRate(*input.CommissionRate); err != nil {
+			return nil, err
+		}
+	}
+
+	var reviewed *merchant.Merchant
+	err := s.merchantRepo.Transaction(ctx, func(ctx context.Context) error {
+		m, err := s.merchantRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := m.Review(input.Status, input.Note, adminID, now); err != nil {
+			return err
+		}
+		if input.CommissionRate != nil {
+			m.CommissionRate = *input.CommissionRate
+		}
+
+		if m.IsActive() {
+			owner, err := s.userRepo.FindByID(ctx, m.OwnerID)
+			if err != nil {
+				return err
+			}
+			// 管理员保留原有的用户类型
+			if owner.UserType == user.UserTypeRegular {
+				owner.UserType = user.UserTypeMerchant
+				if err := s.userRepo.Update(ctx, owner); err != nil {
+					return err
+				}
+			}
+		}
+
+		m.UpdatedAt = now
+		if err := s.merchantRepo.Update(ctx, m); err != nil {
+			return err
+		}
+		reviewed = m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reviewed, nil
+}
+
+// activeProduct 校验用户的商家营业中且拥有该商品
+func (s *DefaultMerchantService) activeProduct(ctx context.Context, userID uint, id uint) (*product.Product, error) {
+	m, err := s.CurrentMerchant(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ownedProduct(ctx, m, id)
+}
+
+// ownedProduct 获取商家拥有的商品，其他商家和平台自营的商品视为不存在
+func (s *DefaultMerchantService) ownedProduct(ctx context.Context, m *merchant.Merchant, id uint) (*product.Product, error) {
+	p, err := s.productService.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.MerchantID != m.ID {
+		return nil, apierror.NewNotFoundError("商品不存在", fmt.Sprintf("ID: %d", id))
+	}
+	return p, nil
+}
@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/module/order/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderHTTPHandler 订单HTTP处理器
+type OrderHTTPHandler struct {
+	orderService service.OrderService
+}
+
+// NewOrderHTTPHandler 创建订单HTTP处理器
+func NewOrderHTTPHandler(orderService service.OrderService) *OrderHTTPHandler {
+	return &OrderHTTPHandler{
+		orderService: orderService,
+	}
+}
+
+// CreateOrder 下单
+func (h *OrderHTTPHandler) CreateOrder(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var input order.CreateOrderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	orderEntity, err := h.orderService.CreateOrder(c.Request.Context(), userID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, orderEntity)
+}
+
+// ListOrders 获取当前用户的订单列表
+func (h *OrderHTTPHandler) ListOrders(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	result, err := h.orderService.ListUserOrders(c.Request.Context(), userID, order.OrderQuery{
+		Status:   c.DefaultQuery("status", ""),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetOrder 获取当前用户的订单详情
+func (h *OrderHTTPHandler) GetOrder(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getOrderID(c)
+	if !ok {
+		return
+	}
+
+	orderEntity, err := h.orderService.GetUserOrder(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderEntity)
+}
+
+// GetOrderBySN 根据订单号获取当前用户的订单详情
+func (h *OrderHTTPHandler) GetOrderBySN(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	orderEntity, err := h.orderService.GetUserOrderBySN(c.Request.Context(), userID, c.Param("order_sn"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderEntity)
+}
+
+// CancelOrder 取消当前用户的待支付订单
+func (h *OrderHTTPHandler) CancelOrder(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getOrderID(c)
+	if !ok {
+		return
+	}
+
+	// 取消原因是可选的，没有请求体时直接取消
+	var input order.CancelOrderInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+			})
+			return
+		}
+	}
+
+	orderEntity, err := h.orderService.CancelUserOrder(c.Request.Context(), userID, id, input.Reason)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderEntity)
+}
+
+// GetTimeline 获取当前用户订单的状态变更记录
+func (h *OrderHTTPHandler) GetTimeline(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getOrderID(c)
+	if !ok {
+		return
+	}
+
+	events, err := h.orderService.GetUserTimeline(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// ListShipments 获取当前用户订单的包裹及物流单号
+func (h *OrderHTTPHandler) ListShipments(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getOrderID(c)
+	if !ok {
+		return
+	}
+
+	shipments, err := h.orderService.ListUserShipments(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shipments": shipments})
+}
+
+// CreateReturn 为订单申请退货
+func (h *OrderHTTPHandler) CreateReturn(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getOrderID(c)
+	if !ok {
+		return
+	}
+
+	var input order.CreateReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	request, err := h.orderService.CreateReturn(c.Request.Context(), userID, id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, request)
+}
+
+// ListReturns 获取订单的退货申请
+func (h *OrderHTTPHandler) ListReturns(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getOrderID(c)
+	if !ok {
+		return
+	}
+
+	requests, err := h.orderService.ListUserReturns(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"returns": requests})
+}
+
+// ListRefunds 获取订单的退款记录
+func (h *OrderHTTPHandler) ListRefunds(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getOrderID(c)
+	if !ok {
+		return
+	}
+
+	refunds, err := h.orderService.ListUserRefunds(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refunds": refunds})
+}
+
+// getOrderID 解析路径中的订单ID，失败时直接写入错误响应
+func (h *OrderHTTPHandler) getOrderID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的订单ID", err.Error()),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// getUserID 获取当前登录用户ID，失败时直接写入错误响应
+func (h *OrderHTTPHandler) getUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// handleError 处理错误
+func (h *OrderHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
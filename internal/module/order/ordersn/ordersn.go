@@ -0,0 +1,94 @@
+package ordersn
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/order"
+)
+
+const (
+	// defaultDateLayout 默认的日期前缀格式，年月日各两位
+	defaultDateLayout = "060102"
+	// maxSNLength 订单号的最大长度，与orders表order_sn列的varchar(32)一致
+	maxSNLength = 32
+)
+
+// shopCodePattern 店铺代码只能包含大写字母和数字，保证订单号可以直接放在URL中
+var shopCodePattern = regexp.MustCompile(`^[A-Z0-9]{0,8}$`)
+
+// NewGenerator 根据配置创建订单号生成器
+func NewGenerator(cfg *config.OrderSNConfig, segmentRepo order.SNSegmentRepository) (order.SNGenerator, error) {
+	switch cfg.Sequence {
+	case "", "snowflake":
+		return NewSnowflakeGenerator(cfg)
+	case "segment":
+		return NewSegmentGenerator(cfg, segmentRepo)
+	default:
+		return nil, fmt.Errorf("unsupported order sn sequence: %s", cfg.Sequence)
+	}
+}
+
+// formatter 拼接订单号：店铺代码 + 日期 + 定长序列号 + 校验位
+type formatter struct {
+	shopCode   string
+	dateLayout string
+	location   *time.Location
+}
+
+// newFormatter 根据配置创建订单号格式，width为序列号的位数
+// 店铺代码、日期、序列号和校验位的总长度不能超过订单号列的长度
+func newFormatter(cfg *config.OrderSNConfig, width int) (*formatter, error) {
+	if !shopCodePattern.MatchString(cfg.ShopCode) {
+		return nil, fmt.Errorf("invalid order sn shop code: %q", cfg.ShopCode)
+	}
+
+	layout := cfg.DateLayout
+	if layout == "" {
+		layout = defaultDateLayout
+	}
+	// 日期部分必须全部是数字，否则订单号中会出现月份名称等字符
+	sample := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC).Format(layout)
+	if len(sample) == 0 || len(sample) > 14 {
+		return nil, fmt.Errorf("invalid order sn date layout: %q", layout)
+	}
+	for _, c := range sample {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("invalid order sn date layout: %q", layout)
+		}
+	}
+	if length := len(cfg.ShopCode) + len(sample) + width + 1; length > maxSNLength {
+		return nil, fmt.Errorf("order sn would be %d characters, exceeding %d: shorten shop_code or date_layout", length, maxSNLength)
+	}
+
+	location := time.Local
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid order sn timezone: %w", err)
+		}
+		location = loc
+	}
+
+	return &formatter{shopCode: cfg.ShopCode, dateLayout: layout, location: location}, nil
+}
+
+// distinguishesDays 判断日期格式是否能区分不同的日期
+// 分别变化日、月、年后格式化结果都必须不同，缺少任意一项时不同日期会得到相同的日期部分
+func (f *formatter) distinguishesDays() bool {
+	base := time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC)
+	sample := base.Format(f.dateLayout)
+	for _, t := range []time.Time{base.AddDate(0, 0, 1), base.AddDate(0, 1, 0), base.AddDate(1, 0, 0)} {
+		if t.Format(f.dateLayout) == sample {
+			return false
+		}
+	}
+	return true
+}
+
+// format 生成订单号，序列号不足width位时左侧补0
+func (f *formatter) format(t time.Time, sequence uint64, width int) string {
+	body := fmt.Sprintf("%s%s%0*d", f.shopCode, t.In(f.location).Format(f.dateLayout), width, sequence)
+	return body + string(order.CheckDigit(body))
+}
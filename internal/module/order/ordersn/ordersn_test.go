@@ -0,0 +1,83 @@
+package ordersn
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/order"
+)
+
+func TestNewSnowflakeGeneratorValidatesConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.OrderSNConfig
+		wantErr string
+	}{
+		{name: "默认配置", cfg: config.OrderSNConfig{ShopCode: "W3"}},
+		{name: "最长店铺代码和最长日期", cfg: config.OrderSNConfig{ShopCode: "ABCDEFGH", DateLayout: "20060102150405"}, wantErr: "exceeding 32"},
+		{name: "最长店铺代码和年月日", cfg: config.OrderSNConfig{ShopCode: "ABCDEFGH", DateLayout: "20060102"}},
+		{name: "长度超过订单号列", cfg: config.OrderSNConfig{ShopCode: "ABCDEFGH", DateLayout: "200601021504"}, wantErr: "exceeding 32"},
+		{name: "31位", cfg: config.OrderSNConfig{ShopCode: "ABCDEFGH", DateLayout: "2006010215"}},
+		{name: "日期缺少日", cfg: config.OrderSNConfig{DateLayout: "0601"}, wantErr: "year, month and day"},
+		{name: "日期缺少年", cfg: config.OrderSNConfig{DateLayout: "0102"}, wantErr: "year, month and day"},
+		{name: "年份和一年中的第几天", cfg: config.OrderSNConfig{DateLayout: "06002"}},
+		{name: "日期包含非数字", cfg: config.OrderSNConfig{DateLayout: "Jan02"}, wantErr: "invalid order sn date layout"},
+		{name: "店铺代码包含小写字母", cfg: config.OrderSNConfig{ShopCode: "w3"}, wantErr: "invalid order sn shop code"},
+		{name: "节点号超出范围", cfg: config.OrderSNConfig{NodeID: maxNodeID + 1}, wantErr: "node id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Timezone = "UTC"
+			_, err := NewSnowflakeGenerator(&cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("NewSnowflakeGenerator() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewSnowflakeGenerator() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSegmentGeneratorAllowsLayoutWithoutDay(t *testing.T) {
+	// 号段模式的序列号全局递增，日期部分只用于阅读
+	if _, err := NewSegmentGenerator(&config.OrderSNConfig{DateLayout: "0601"}, nil); err != nil {
+		t.Fatalf("NewSegmentGenerator() error = %v", err)
+	}
+	if _, err := NewSegmentGenerator(&config.OrderSNConfig{ShopCode: "ABCDEFGH", DateLayout: "20060102150405"}, nil); err == nil {
+		t.Fatal("NewSegmentGenerator() accepted an order sn longer than 32 characters")
+	}
+}
+
+func TestSnowflakeGeneratorUniqueAcrossDays(t *testing.T) {
+	g, err := NewSnowflakeGenerator(&config.OrderSNConfig{ShopCode: "ABCDEFGH", DateLayout: "20060102", Timezone: "UTC", NodeID: maxNodeID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	seen := make(map[string]bool)
+	start := time.Date(2026, time.January, 31, 23, 59, 59, 0, time.UTC)
+	for _, now := range []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 1, 0), start.AddDate(1, 0, 0)} {
+		for i := 0; i < 3; i++ {
+			sn, err := g.Next(ctx, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sn) > maxSNLength || !order.ValidOrderSN(sn) {
+				t.Fatalf("invalid order sn %q (length %d)", sn, len(sn))
+			}
+			if seen[sn] {
+				t.Fatalf("duplicate order sn %q", sn)
+			}
+			seen[sn] = true
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package ordersn
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/order"
+)
+
+const (
+	// defaultSegmentStep 默认每次申请的号段长度
+	defaultSegmentStep = 1000
+	// segmentWidth 序列号的十进制位数，超过后订单号自动变长
+	segmentWidth = 10
+)
+
+// SegmentGenerator 号段模式的订单号生成器，序列号全局单调递增
+// 每个实例每次从数据库原子地申请一段连续序号，在本地依次发放，用完后再申请下一段。
+// 不同实例的号段互不重叠，单个实例内严格递增，实例之间大致按时间递增；
+// 实例重启时未用完的号段作废，序列号会出现空洞
+type SegmentGenerator struct {
+	mu          sync.Mutex
+	formatter   *formatter
+	segmentRepo order.SNSegmentRepository
+	step        int
+	next        uint64
+	end         uint64
+}
+
+// NewSegmentGenerator 创建号段模式的订单号生成器
+func NewSegmentGenerator(cfg *config.OrderSNConfig, segmentRepo order.SNSegmentRepository) (*SegmentGenerator, error) {
+	step := cfg.SegmentStep
+	if step == 0 {
+		step = defaultSegmentStep
+	}
+	if step < 0 {
+		return nil, fmt.Errorf("invalid order sn segment step: %d", step)
+	}
+	f, err := newFormatter(cfg, segmentWidth)
+	if err != nil {
+		return nil, err
+	}
+	return &SegmentGenerator{formatter: f, segmentRepo: segmentRepo, step: step}, nil
+}
+
+// Next 生成订单号，本地号段用完时先申请新的号段
+func (g *SegmentGenerator) Next(ctx context.Context, now time.Time) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.next >= g.end {
+		start, err := g.segmentRepo.Allocate(ctx, g.step)
+		if err != nil {
+			return "", err
+		}
+		g.next = start
+		g.end = start + uint64(g.step)
+	}
+
+	sequence := g.next
+	g.next++
+	return g.formatter.format(now, sequence, segmentWidth), nil
+}
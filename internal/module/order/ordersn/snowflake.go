@@ -0,0 +1,70 @@
+package ordersn
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"web3-ecommerce-app/internal/config"
+)
+
+const (
+	nodeBits     = 10
+	sequenceBits = 12
+	maxNodeID    = 1<<nodeBits - 1
+	maxSequence  = 1<<sequenceBits - 1
+	// snowflakeWidth 序列号的十进制位数
+	// 当天的秒数最多17位二进制(夏令时切换的25小时也不超过)，加上节点号和秒内序号共39位，最多12位十进制
+	snowflakeWidth = 12
+)
+
+// SnowflakeGenerator 类Snowflake的订单号生成器
+// 序列号由当天的秒数、节点号和秒内序号组成，只要每个实例配置不同的节点号就不会重复，
+// 不依赖数据库或分布式锁。同一秒内的序号用完或时钟回拨时借用之后的秒，保证实例内单调递增
+type SnowflakeGenerator struct {
+	mu         sync.Mutex
+	formatter  *formatter
+	nodeID     uint64
+	lastSecond int64
+	sequence   uint64
+}
+
+// NewSnowflakeGenerator 创建类Snowflake的订单号生成器
+func NewSnowflakeGenerator(cfg *config.OrderSNConfig) (*SnowflakeGenerator, error) {
+	if cfg.NodeID < 0 || cfg.NodeID > maxNodeID {
+		return nil, fmt.Errorf("order sn node id must be between 0 and %d", maxNodeID)
+	}
+	f, err := newFormatter(cfg, snowflakeWidth)
+	if err != nil {
+		return nil, err
+	}
+	// 序列号只包含当天的秒数，日期部分必须精确到日，否则不同日期的同一时刻会生成相同的订单号
+	if !f.distinguishesDays() {
+		return nil, fmt.Errorf("order sn date layout %q must include year, month and day in snowflake mode", f.dateLayout)
+	}
+	return &SnowflakeGenerator{formatter: f, nodeID: uint64(cfg.NodeID)}, nil
+}
+
+// Next 生成订单号
+func (g *SnowflakeGenerator) Next(ctx context.Context, now time.Time) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if second := now.Unix(); second > g.lastSecond {
+		g.lastSecond = second
+		g.sequence = 0
+	} else if g.sequence < maxSequence {
+		g.sequence++
+	} else {
+		g.lastSecond++
+		g.sequence = 0
+	}
+
+	// 日期和当天秒数都按借用后的时间计算，跨过零点时日期随之变化
+	t := time.Unix(g.lastSecond, 0).In(g.formatter.location)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	secondOfDay := uint64(g.lastSecond - midnight.Unix())
+
+	sequence := secondOfDay<<(nodeBits+sequenceBits) | g.nodeID<<sequenceBits | g.sequence
+	return g.formatter.format(t, sequence, snowflakeWidth), nil
+}
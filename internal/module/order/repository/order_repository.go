@@ -0,0 +1,1076 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrderModel 是GORM订单模型
+type OrderModel struct {
+	ID               uint           `gorm:"primarykey"`
+	UserID           uint           `gorm:"not null;index:idx_user_created,priority:1"`
+	OrderSN          string         `gorm:"type:varchar(32);not null;uniqueIndex:idx_order_sn"`
+	Status           string         `gorm:"type:varchar(20);not null;index:idx_status"`
+	Subtotal         int64          `gorm:"not null"`
+	DiscountTotal    int64          `gorm:"not null;default:0"`
+	ShippingFee      int64          `gorm:"not null;default:0"`
+	ShippingTax      int64          `gorm:"not null;default:0"`
+	TaxTotal         int64          `gorm:"not null;default:0"`
+	TaxInclusive     bool           `gorm:"not null;default:false"`
+	TotalPrice       int64          `gorm:"not null"`
+	BalancePaid      int64          `gorm:"not null;default:0"`
+	StoreCreditPaid  int64          `gorm:"not null;default:0"`
+	ShippingAddress  *order.Address `gorm:"type:text;serializer:json"`
+	ShippingMethodID uint           `gorm:"not null;default:0"`
+	ShippingMethod   string         `gorm:"type:varchar(50)"`
+	PromotionCodes   []string       `gorm:"type:text;serializer:json"`
+	PaymentTxHash    string         `gorm:"type:varchar(100)"`
+	Note             string         `gorm:"type:varchar(500)"`
+	ExpiresAt        time.Time      `gorm:"not null"`
+	PaidAt           *time.Time
+	ShippedAt        *time.Time
+	DeliveredAt      *time.Time
+	CompletedAt      *time.Time
+	CancelledAt      *time.Time
+	RefundedAt       *time.Time
+	Items            []OrderItemModel `gorm:"foreignKey:OrderID"`
+	CreatedAt        time.Time        `gorm:"index:idx_user_created,priority:2;index:idx_created_at"`
+	UpdatedAt        time.Time
+}
+
+// TableName 指定表名
+func (OrderModel) TableName() string {
+	return "orders"
+}
+
+// OrderItemModel 是GORM订单行模型
+type OrderItemModel struct {
+	ID          uint   `gorm:"primarykey"`
+	OrderID     uint   `gorm:"not null;index:idx_order_id"`
+	ProductID   uint   `gorm:"not null;index:idx_product_id"`
+	ProductName string `gorm:"type:varchar(200);not null"`
+	SKU         string `gorm:"type:varchar(64);not null"`
+	ProductType string `gorm:"type:varchar(20);not null"`
+	MerchantID  uint   `gorm:"not null;default:0"`
+	Price       int64  `gorm:"not null"`
+	Quantity    int    `gorm:"not null"`
+	Discount    int64  `gorm:"not null;default:0"`
+	Total       int64  `gorm:"not null"`
+	TaxClass    string `gorm:"type:varchar(30);not null;default:'standard'"`
+	TaxName     string `gorm:"type:varchar(50)"`
+	TaxRate     int    `gorm:"not null;default:0"`
+	TaxAmount   int64  `gorm:"not null;default:0"`
+}
+
+// TableName 指定表名
+func (OrderItemModel) TableName() string {
+	return "order_items"
+}
+
+// TimelineModel 是GORM订单时间线模型
+type TimelineModel struct {
+	ID         uint   `gorm:"primarykey"`
+	OrderID    uint   `gorm:"not null;index:idx_order_id"`
+	FromStatus string `gorm:"type:varchar(20);not null;default:''"`
+	ToStatus   string `gorm:"type:varchar(20);not null"`
+	ActorType  string `gorm:"type:varchar(20);not null"`
+	ActorID    uint   `gorm:"not null;default:0"`
+	Reason     string `gorm:"type:varchar(500)"`
+	CreatedAt  time.Time
+}
+
+// TableName 指定表名
+func (TimelineModel) TableName() string {
+	return "order_timeline"
+}
+
+// LatePaymentModel 是GORM逾期付款模型
+type LatePaymentModel struct {
+	ID         uint   `gorm:"primarykey"`
+	OrderID    uint   `gorm:"not null;index:idx_order_id"`
+	UserID     uint   `gorm:"not null"`
+	TxHash     string `gorm:"type:varchar(100)"`
+	Amount     int64  `gorm:"not null"`
+	Status     string `gorm:"type:varchar(20);not null;index:idx_status_created,priority:1"`
+	Note       string `gorm:"type:varchar(500)"`
+	ResolvedBy uint   `gorm:"not null;default:0"`
+	ResolvedAt *time.Time
+	CreatedAt  time.Time `gorm:"index:idx_status_created,priority:2"`
+}
+
+// TableName 指定表名
+func (LatePaymentModel) TableName() string {
+	return "order_late_payments"
+}
+
+// ShipmentModel 是GORM包裹模型
+type ShipmentModel struct {
+	ID             uint                `gorm:"primarykey"`
+	OrderID        uint                `gorm:"not null;index:idx_order_id"`
+	Carrier        string              `gorm:"type:varchar(50);not null"`
+	TrackingNumber string              `gorm:"type:varchar(100);not null;index:idx_tracking_number"`
+	Status         string              `gorm:"type:varchar(20);not null"`
+	Items          []ShipmentItemModel `gorm:"foreignKey:ShipmentID"`
+	ShippedAt      time.Time           `gorm:"not null"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
+
+// TableName 指定表名
+func (ShipmentModel) TableName() string {
+	return "order_shipments"
+}
+
+// ShipmentItemModel 是GORM包裹商品模型
+type ShipmentItemModel struct {
+	ID          uint `gorm:"primarykey"`
+	ShipmentID  uint `gorm:"not null;index:idx_shipment_id"`
+	OrderItemID uint `gorm:"not null"`
+	ProductID   uint `gorm:"not null"`
+	Quantity    int  `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (ShipmentItemModel) TableName() string {
+	return "order_shipment_items"
+}
+
+// RefundModel 是GORM退款模型
+type RefundModel struct {
+	ID             uint               `gorm:"primarykey"`
+	OrderID        uint               `gorm:"not null;index:idx_order_id"`
+	UserID         uint               `gorm:"not null"`
+	ReturnID       uint               `gorm:"not null;default:0"`
+	Items          []order.RefundItem `gorm:"type:text;serializer:json"`
+	ShippingAmount int64              `gorm:"not null;default:0"`
+	Amount         int64              `gorm:"not null"`
+	Method         string             `gorm:"type:varchar(20);not null"`
+	StoreCredit    int64              `gorm:"not null;default:0"`
+	WithdrawalID   uint               `gorm:"not null;default:0"`
+	Restocked      bool               `gorm:"not null;default:false"`
+	Reason         string             `gorm:"type:varchar(500)"`
+	ActorType      string             `gorm:"type:varchar(20);not null"`
+	ActorID        uint               `gorm:"not null;default:0"`
+	CreatedAt      time.Time
+}
+
+// TableName 指定表名
+func (RefundModel) TableName() string {
+	return "order_refunds"
+}
+
+// ReturnModel 是GORM退货申请模型
+type ReturnModel struct {
+	ID           uint               `gorm:"primarykey"`
+	OrderID      uint               `gorm:"not null;index:idx_order_id"`
+	UserID       uint               `gorm:"not null"`
+	Status       string             `gorm:"type:varchar(20);not null;index:idx_status_created,priority:1"`
+	Reason       string             `gorm:"type:varchar(30);not null"`
+	Description  string             `gorm:"type:varchar(1000)"`
+	RefundMethod string             `gorm:"type:varchar(20);not null"`
+	Items        []order.ReturnItem `gorm:"type:text;serializer:json"`
+	ReviewNote   string             `gorm:"type:varchar(500)"`
+	ReviewedBy   uint               `gorm:"not null;default:0"`
+	ReviewedAt   *time.Time
+	ReceivedAt   *time.Time
+	RefundID     uint      `gorm:"not null;default:0"`
+	CreatedAt    time.Time `gorm:"index:idx_status_created,priority:2"`
+	UpdatedAt    time.Time
+}
+
+// TableName 指定表名
+func (ReturnModel) TableName() string {
+	return "order_returns"
+}
+
+// SubOrderModel 是GORM子订单模型
+type SubOrderModel struct {
+	ID             uint   `gorm:"primarykey"`
+	OrderID        uint   `gorm:"not null;index:idx_order_id"`
+	MerchantID     uint   `gorm:"not null;index:idx_merchant_created,priority:1"`
+	Status         string `gorm:"type:varchar(20);not null"`
+	ItemsTotal     int64  `gorm:"not null"`
+	ShippingAmount int64  `gorm:"not null;default:0"`
+	Amount         int64  `gorm:"not null"`
+	CommissionRate int    `gorm:"not null;default:0"`
+	Commission     int64  `gorm:"not null;default:0"`
+	SettledAmount  int64  `gorm:"not null;default:0"`
+	SettledAt      *time.Time
+	CreatedAt      time.Time `gorm:"index:idx_merchant_created,priority:2"`
+	UpdatedAt      time.Time
+}
+
+// TableName 指定表名
+func (SubOrderModel) TableName() string {
+	return "order_sub_orders"
+}
+
+// GormOrderRepository 是订单仓库的GORM实现
+type GormOrderRepository struct {
+	db *gorm.DB
+}
+
+// NewGormOrderRepository 创建一个新的GORM订单仓库
+func NewGormOrderRepository(db *gorm.DB) order.OrderRepository {
+	return &GormOrderRepository{db: db}
+}
+
+// orderToModel 将领域模型转换为GORM模型
+func orderToModel(o *order.Order) *OrderModel {
+	items := make([]OrderItemModel, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, OrderItemModel{
+			ID:          item.ID,
+			OrderID:     item.OrderID,
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			SKU:         item.SKU,
+			ProductType: item.ProductType,
+			MerchantID:  item.MerchantID,
+			Price:       int64(item.Price),
+			Quantity:    item.Quantity,
+			Discount:    int64(item.Discount),
+			Total:       int64(item.Total),
+			TaxClass:    item.TaxClass,
+			TaxName:     item.TaxName,
+			TaxRate:     item.TaxRate,
+			TaxAmount:   int64(item.TaxAmount),
+		})
+	}
+
+	return &OrderModel{
+		ID:               o.ID,
+		UserID:           o.UserID,
+		OrderSN:          o.OrderSN,
+		Status:           o.Status,
+		Subtotal:         int64(o.Subtotal),
+		DiscountTotal:    int64(o.DiscountTotal),
+		ShippingFee:      int64(o.ShippingFee),
+		ShippingTax:      int64(o.ShippingTax),
+		TaxTotal:         int64(o.TaxTotal),
+		TaxInclusive:     o.TaxInclusive,
+		TotalPrice:       int64(o.TotalPrice),
+		BalancePaid:      int64(o.BalancePaid),
+		StoreCreditPaid:  int64(o.StoreCreditPaid),
+		ShippingAddress:  o.ShippingAddress,
+		ShippingMethodID: o.ShippingMethodID,
+		ShippingMethod:   o.ShippingMethod,
+		PromotionCodes:   o.PromotionCodes,
+		PaymentTxHash:    o.PaymentTxHash,
+		Note:             o.Note,
+		ExpiresAt:        o.ExpiresAt,
+		PaidAt:           o.PaidAt,
+		ShippedAt:        o.ShippedAt,
+		DeliveredAt:      o.DeliveredAt,
+		CompletedAt:      o.CompletedAt,
+		CancelledAt:      o.CancelledAt,
+		RefundedAt:       o.RefundedAt,
+		Items:            items,
+		CreatedAt:        o.CreatedAt,
+		UpdatedAt:        o.UpdatedAt,
+	}
+}
+
+// orderToDomain 将GORM模型转换为领域模型
+func orderToDomain(m *OrderModel) *order.Order {
+	items := make([]order.OrderItem, 0, len(m.Items))
+	for _, item := range m.Items {
+		items = append(items, order.OrderItem{
+			ID:          item.ID,
+			OrderID:     item.OrderID,
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			SKU:         item.SKU,
+			ProductType: item.ProductType,
+			MerchantID:  item.MerchantID,
+			Price:       common.Money(item.Price),
+			Quantity:    item.Quantity,
+			Discount:    common.Money(item.Discount),
+			Total:       common.Money(item.Total),
+			TaxClass:    item.TaxClass,
+			TaxName:     item.TaxName,
+			TaxRate:     item.TaxRate,
+			TaxAmount:   common.Money(item.TaxAmount),
+		})
+	}
+
+	codes := m.PromotionCodes
+	if codes == nil {
+		codes = []string{}
+	}
+
+	return &order.Order{
+		ID:               m.ID,
+		UserID:           m.UserID,
+		OrderSN:          m.OrderSN,
+		Status:           m.Status,
+		Items:            items,
+		Subtotal:         common.Money(m.Subtotal),
+		DiscountTotal:    common.Money(m.DiscountTotal),
+		ShippingFee:      common.Money(m.ShippingFee),
+		ShippingTax:      common.Money(m.ShippingTax),
+		TaxTotal:         common.Money(m.TaxTotal),
+		TaxInclusive:     m.TaxInclusive,
+		TotalPrice:       common.Money(m.TotalPrice),
+		BalancePaid:      common.Money(m.BalancePaid),
+		StoreCreditPaid:  common.Money(m.StoreCreditPaid),
+		ShippingAddress:  m.ShippingAddress,
+		ShippingMethodID: m.ShippingMethodID,
+		ShippingMethod:   m.ShippingMethod,
+		PromotionCodes:   codes,
+		PaymentTxHash:    m.PaymentTxHash,
+		Note:             m.Note,
+		ExpiresAt:        m.ExpiresAt,
+		PaidAt:           m.PaidAt,
+		ShippedAt:        m.ShippedAt,
+		DeliveredAt:      m.DeliveredAt,
+		CompletedAt:      m.CompletedAt,
+		CancelledAt:      m.CancelledAt,
+		RefundedAt:       m.RefundedAt,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+	}
+}
+
+// orderItems 订单行按ID排序，与下单时的顺序一致
+func orderItems(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+// FindByID 根据ID查询订单
+func (r *GormOrderRepository) FindByID(ctx context.Context, id uint) (*order.Order, error) {
+	var model OrderModel
+	if err := database.Conn(ctx, r.db).Preload("Items", orderItems).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("订单不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询订单错误: %w", err)
+	}
+	return orderToDomain(&model), nil
+}
+
+// FindBySN 根据订单号查询订单
+func (r *GormOrderRepository) FindBySN(ctx context.Context, sn string) (*order.Order, error) {
+	var model OrderModel
+	if err := database.Conn(ctx, r.db).Preload("Items", orderItems).Where("order_sn = ?", sn).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("订单不存在", fmt.Sprintf("订单号: %s", sn))
+		}
+		return nil, fmt.Errorf("查询订单错误: %w", err)
+	}
+	return orderToDomain(&model), nil
+}
+
+// Find 按条件分页查询订单
+func (r *GormOrderRepository) Find(ctx context.Context, query order.OrderQuery) (*order.OrderPaginationResult, error) {
+	var models []OrderModel
+	var total int64
+
+	db := database.Conn(ctx, r.db).Model(&OrderModel{})
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if !query.StartDate.IsZero() {
+		db = db.Where("created_at >= ?", query.StartDate)
+	}
+	if !query.EndDate.IsZero() {
+		db = db.Where("created_at < ?", query.EndDate)
+	}
+
+	// 查询总数
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询订单总数错误: %w", err)
+	}
+
+	// 分页查询
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Preload("Items", orderItems).Order("created_at DESC, id DESC").
+		Offset(offset).Limit(query.PageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询订单列表错误: %w", err)
+	}
+
+	orders := make([]order.Order, 0, len(models))
+	for i := range models {
+		orders = append(orders, *orderToDomain(&models[i]))
+	}
+
+	return &order.OrderPaginationResult{
+		Total:  int(total),
+		Orders: orders,
+	}, nil
+}
+
+// Create 创建订单及订单行
+func (r *GormOrderRepository) Create(ctx context.Context, o *order.Order) error {
+	model := orderToModel(o)
+	if err := database.Conn(ctx, r.db).Create(model).Error; err != nil {
+		return fmt.Errorf("创建订单错误: %w", err)
+	}
+
+	*o = *orderToDomain(model)
+	return nil
+}
+
+// UpdateStatus 仅当订单仍处于from状态时更新状态
+func (r *GormOrderRepository) UpdateStatus(ctx context.Context, o *order.Order, from string) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&OrderModel{}).
+		Where("id = ? AND status = ?", o.ID, from).
+		Updates(map[string]interface{}{
+			"status":          o.Status,
+			"payment_tx_hash": o.PaymentTxHash,
+			"paid_at":         o.PaidAt,
+			"shipped_at":      o.ShippedAt,
+			"delivered_at":    o.DeliveredAt,
+			"completed_at":    o.CompletedAt,
+			"cancelled_at":    o.CancelledAt,
+			"refunded_at":     o.RefundedAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("更新订单状态错误: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindExpired 查询超过支付截止时间的待支付订单
+func (r *GormOrderRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]order.Order, error) {
+	var models []OrderModel
+	if err := database.Conn(ctx, r.db).Preload("Items", orderItems).
+		Where("status = ? AND expires_at <= ?", order.OrderStatusPendingPayment, now).
+		Order("expires_at").Limit(limit).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询超时订单错误: %w", err)
+	}
+
+	orders := make([]order.Order, 0, len(models))
+	for i := range models {
+		orders = append(orders, *orderToDomain(&models[i]))
+	}
+	return orders, nil
+}
+
+// HasPurchased 判断用户是否有包含该商品的已支付订单
+func (r *GormOrderRepository) HasPurchased(ctx context.Context, userID uint, productID uint) (bool, error) {
+	var count int64
+	if err := database.Conn(ctx, r.db).Model(&OrderItemModel{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND orders.status IN ? AND order_items.product_id = ?", userID, order.PurchasedStatuses, productID).
+		Limit(1).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("查询购买记录错误: %w", err)
+	}
+	return count > 0, nil
+}
+
+// AppendTimeline 记录一条状态变更
+func (r *GormOrderRepository) AppendTimeline(ctx context.Context, event *order.TimelineEvent) error {
+	model := TimelineModel{
+		OrderID:    event.OrderID,
+		FromStatus: event.FromStatus,
+		ToStatus:   event.ToStatus,
+		ActorType:  event.Actor.Type,
+		ActorID:    event.Actor.ID,
+		Reason:     event.Reason,
+		CreatedAt:  event.CreatedAt,
+	}
+	if err := database.Conn(ctx, r.db).Create(&model).Error; err != nil {
+		return fmt.Errorf("记录订单时间线错误: %w", err)
+	}
+
+	event.ID = model.ID
+	event.CreatedAt = model.CreatedAt
+	return nil
+}
+
+// FindTimeline 按时间顺序查询订单的状态变更记录
+func (r *GormOrderRepository) FindTimeline(ctx context.Context, orderID uint) ([]order.TimelineEvent, error) {
+	var models []TimelineModel
+	if err := database.Conn(ctx, r.db).Where("order_id = ?", orderID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询订单时间线错误: %w", err)
+	}
+
+	events := make([]order.TimelineEvent, 0, len(models))
+	for _, m := range models {
+		events = append(events, order.TimelineEvent{
+			ID:         m.ID,
+			OrderID:    m.OrderID,
+			FromStatus: m.FromStatus,
+			ToStatus:   m.ToStatus,
+			Actor:      order.Actor{Type: m.ActorType, ID: m.ActorID},
+			Reason:     m.Reason,
+			CreatedAt:  m.CreatedAt,
+		})
+	}
+	return events, nil
+}
+
+// latePaymentToDomain 将GORM模型转换为领域模型
+func latePaymentToDomain(m *LatePaymentModel) *order.LatePayment {
+	return &order.LatePayment{
+		ID:         m.ID,
+		OrderID:    m.OrderID,
+		UserID:     m.UserID,
+		TxHash:     m.TxHash,
+		Amount:     common.Money(m.Amount),
+		Status:     m.Status,
+		Note:       m.Note,
+		ResolvedBy: m.ResolvedBy,
+		ResolvedAt: m.ResolvedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// CreateLatePayment 记录逾期付款
+func (r *GormOrderRepository) CreateLatePayment(ctx context.Context, p *order.LatePayment) error {
+	model := LatePaymentModel{
+		OrderID: p.OrderID,
+		UserID:  p.UserID,
+		TxHash:  p.TxHash,
+		Amount:  int64(p.Amount),
+		Status:  p.Status,
+		Note:    p.Note,
+	}
+	if err := database.Conn(ctx, r.db).Create(&model).Error; err != nil {
+		return fmt.Errorf("记录逾期付款错误: %w", err)
+	}
+
+	*p = *latePaymentToDomain(&model)
+	return nil
+}
+
+// FindLatePaymentByID 根据ID查询逾期付款
+func (r *GormOrderRepository) FindLatePaymentByID(ctx context.Context, id uint) (*order.LatePayment, error) {
+	var model LatePaymentModel
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("逾期付款记录不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询逾期付款错误: %w", err)
+	}
+	return latePaymentToDomain(&model), nil
+}
+
+// FindLatePayments 按条件分页查询逾期付款
+func (r *GormOrderRepository) FindLatePayments(ctx context.Context, query order.LatePaymentQuery) (*order.LatePaymentPaginationResult, error) {
+	var models []LatePaymentModel
+	var total int64
+
+	db := database.Conn(ctx, r.db).Model(&LatePaymentModel{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询逾期付款总数错误: %w", err)
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order("created_at DESC, id DESC").Offset(offset).Limit(query.PageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询逾期付款列表错误: %w", err)
+	}
+
+	payments := make([]order.LatePayment, 0, len(models))
+	for i := range models {
+		payments = append(payments, *latePaymentToDomain(&models[i]))
+	}
+
+	return &order.LatePaymentPaginationResult{
+		Total:        int(total),
+		LatePayments: payments,
+	}, nil
+}
+
+// ResolveLatePayment 仅当逾期付款仍待处理时写入处理结果
+func (r *GormOrderRepository) ResolveLatePayment(ctx context.Context, p *order.LatePayment) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&LatePaymentModel{}).
+		Where("id = ? AND status = ?", p.ID, order.LatePaymentStatusPending).
+		Updates(map[string]interface{}{
+			"status":      p.Status,
+			"note":        p.Note,
+			"resolved_by": p.ResolvedBy,
+			"resolved_at": p.ResolvedAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("更新逾期付款错误: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// LockByID 在事务中锁定订单行并返回最新的订单
+func (r *GormOrderRepository) LockByID(ctx context.Context, id uint) (*order.Order, error) {
+	var model OrderModel
+	if err := database.Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Items", orderItems).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("订单不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("锁定订单错误: %w", err)
+	}
+	return orderToDomain(&model), nil
+}
+
+// shipmentToDomain 将GORM模型转换为领域模型
+func shipmentToDomain(m *ShipmentModel) *order.Shipment {
+	items := make([]order.ShipmentItem, 0, len(m.Items))
+	for _, item := range m.Items {
+		items = append(items, order.ShipmentItem{
+			OrderItemID: item.OrderItemID,
+			ProductID:   item.ProductID,
+			Quantity:    item.Quantity,
+		})
+	}
+	return &order.Shipment{
+		ID:             m.ID,
+		OrderID:        m.OrderID,
+		Carrier:        m.Carrier,
+		TrackingNumber: m.TrackingNumber,
+		Status:         m.Status,
+		Items:          items,
+		ShippedAt:      m.ShippedAt,
+		DeliveredAt:    m.DeliveredAt,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+// CreateShipment 创建包裹及包裹商品
+func (r *GormOrderRepository) CreateShipment(ctx context.Context, s *order.Shipment) error {
+	items := make([]ShipmentItemModel, 0, len(s.Items))
+	for _, item := range s.Items {
+		items = append(items, ShipmentItemModel{
+			OrderItemID: item.OrderItemID,
+			ProductID:   item.ProductID,
+			Quantity:    item.Quantity,
+		})
+	}
+	model := ShipmentModel{
+		OrderID:        s.OrderID,
+		Carrier:        s.Carrier,
+		TrackingNumber: s.TrackingNumber,
+		Status:         s.Status,
+		Items:          items,
+		ShippedAt:      s.ShippedAt,
+	}
+	if err := database.Conn(ctx, r.db).Create(&model).Error; err != nil {
+		return fmt.Errorf("创建包裹错误: %w", err)
+	}
+
+	*s = *shipmentToDomain(&model)
+	return nil
+}
+
+// FindShipments 按创建顺序查询订单的包裹
+func (r *GormOrderRepository) FindShipments(ctx context.Context, orderID uint) ([]order.Shipment, error) {
+	var models []ShipmentModel
+	if err := database.Conn(ctx, r.db).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("order_id = ?", orderID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询包裹错误: %w", err)
+	}
+
+	shipments := make([]order.Shipment, 0, len(models))
+	for i := range models {
+		shipments = append(shipments, *shipmentToDomain(&models[i]))
+	}
+	return shipments, nil
+}
+
+// MarkShipmentDelivered 仅当包裹仍未签收时标记为已签收
+func (r *GormOrderRepository) MarkShipmentDelivered(ctx context.Context, s *order.Shipment) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&ShipmentModel{}).
+		Where("id = ? AND status = ?", s.ID, order.ShipmentStatusShipped).
+		Updates(map[string]interface{}{
+			"status":       s.Status,
+			"delivered_at": s.DeliveredAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("更新包裹状态错误: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// refundToDomain 将GORM模型转换为领域模型
+func refundToDomain(m *RefundModel) *order.Refund {
+	items := m.Items
+	if items == nil {
+		items = []order.RefundItem{}
+	}
+	return &order.Refund{
+		ID:             m.ID,
+		OrderID:        m.OrderID,
+		UserID:         m.UserID,
+		ReturnID:       m.ReturnID,
+		Items:          items,
+		ShippingAmount: common.Money(m.ShippingAmount),
+		Amount:         common.Money(m.Amount),
+		Method:         m.Method,
+		StoreCredit:    common.Money(m.StoreCredit),
+		WithdrawalID:   m.WithdrawalID,
+		Restocked:      m.Restocked,
+		Reason:         m.Reason,
+		Actor:          order.Actor{Type: m.ActorType, ID: m.ActorID},
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+// CreateRefund 记录退款
+func (r *GormOrderRepository) CreateRefund(ctx context.Context, refund *order.Refund) error {
+	model := RefundModel{
+		OrderID:        refund.OrderID,
+		UserID:         refund.UserID,
+		ReturnID:       refund.ReturnID,
+		Items:          refund.Items,
+		ShippingAmount: int64(refund.ShippingAmount),
+		Amount:         int64(refund.Amount),
+		Method:         refund.Method,
+		StoreCredit:    int64(refund.StoreCredit),
+		WithdrawalID:   refund.WithdrawalID,
+		Restocked:      refund.Restocked,
+		Reason:         refund.Reason,
+		ActorType:      refund.Actor.Type,
+		ActorID:        refund.Actor.ID,
+	}
+	if err := database.Conn(ctx, r.db).Create(&model).Error; err != nil {
+		return fmt.Errorf("记录退款错误: %w", err)
+	}
+
+	*refund = *refundToDomain(&model)
+	return nil
+}
+
+// SetRefundWithdrawal 关联转账退款的提现单
+func (r *GormOrderRepository) SetRefundWithdrawal(ctx context.Context, refundID uint, withdrawalID uint) error {
+	if err := database.Conn(ctx, r.db).Model(&RefundModel{}).Where("id = ?", refundID).
+		Update("withdrawal_id", withdrawalID).Error; err != nil {
+		return fmt.Errorf("更新退款错误: %w", err)
+	}
+	return nil
+}
+
+// SetBalancePaid 记录订单用购物金和余额抵扣的金额
+func (r *GormOrderRepository) SetBalancePaid(ctx context.Context, orderID uint, amount common.Money, storeCredit common.Money) error {
+	if err := database.Conn(ctx, r.db).Model(&OrderModel{}).Where("id = ?", orderID).
+		Updates(map[string]interface{}{
+			"balance_paid":      int64(amount),
+			"store_credit_paid": int64(storeCredit),
+		}).Error; err != nil {
+		return fmt.Errorf("更新订单抵扣金额错误: %w", err)
+	}
+	return nil
+}
+
+// FindRefunds 按创建顺序查询订单的退款
+func (r *GormOrderRepository) FindRefunds(ctx context.Context, orderID uint) ([]order.Refund, error) {
+	var models []RefundModel
+	if err := database.Conn(ctx, r.db).Where("order_id = ?", orderID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询退款错误: %w", err)
+	}
+
+	refunds := make([]order.Refund, 0, len(models))
+	for i := range models {
+		refunds = append(refunds, *refundToDomain(&models[i]))
+	}
+	return refunds, nil
+}
+
+// returnToDomain 将GORM模型转换为领域模型
+func returnToDomain(m *ReturnModel) *order.ReturnRequest {
+	items := m.Items
+	if items == nil {
+		items = []order.ReturnItem{}
+	}
+	return &order.ReturnRequest{
+		ID:           m.ID,
+		OrderID:      m.OrderID,
+		UserID:       m.UserID,
+		Status:       m.Status,
+		Reason:       m.Reason,
+		Description:  m.Description,
+		RefundMethod: m.RefundMethod,
+		Items:        items,
+		ReviewNote:   m.ReviewNote,
+		ReviewedBy:   m.ReviewedBy,
+		ReviewedAt:   m.ReviewedAt,
+		ReceivedAt:   m.ReceivedAt,
+		RefundID:     m.RefundID,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
+
+// CreateReturn 创建退货申请
+func (r *GormOrderRepository) CreateReturn(ctx context.Context, request *order.ReturnRequest) error {
+	model := ReturnModel{
+		OrderID:      request.OrderID,
+		UserID:       request.UserID,
+		Status:       request.Status,
+		Reason:       request.Reason,
+		Description:  request.Description,
+		RefundMethod: request.RefundMethod,
+		Items:        request.Items,
+	}
+	if err := database.Conn(ctx, r.db).Create(&model).Error; err != nil {
+		return fmt.Errorf("创建退货申请错误: %w", err)
+	}
+
+	*request = *returnToDomain(&model)
+	return nil
+}
+
+// FindReturnByID 根据ID查询退货申请
+func (r *GormOrderRepository) FindReturnByID(ctx context.Context, id uint) (*order.ReturnRequest, error) {
+	var model ReturnModel
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("退货申请不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询退货申请错误: %w", err)
+	}
+	return returnToDomain(&model), nil
+}
+
+// FindReturnsByOrder 按创建顺序查询订单的退货申请
+func (r *GormOrderRepository) FindReturnsByOrder(ctx context.Context, orderID uint) ([]order.ReturnRequest, error) {
+	var models []ReturnModel
+	if err := database.Conn(ctx, r.db).Where("order_id = ?", orderID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询退货申请错误: %w", err)
+	}
+
+	requests := make([]order.ReturnRequest, 0, len(models))
+	for i := range models {
+		requests = append(requests, *returnToDomain(&models[i]))
+	}
+	return requests, nil
+}
+
+// FindReturns 按条件分页查询退货申请
+func (r *GormOrderRepository) FindReturns(ctx context.Context, query order.ReturnQuery) (*order.ReturnPaginationResult, error) {
+	var models []ReturnModel
+	var total int64
+
+	db := database.Conn(ctx, r.db).Model(&ReturnModel{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询退货申请总数错误: %w", err)
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order("created_at DESC, id DESC").Offset(offset).Limit(query.PageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询退货申请列表错误: %w", err)
+	}
+
+	requests := make([]order.ReturnRequest, 0, len(models))
+	for i := range models {
+		requests = append(requests, *returnToDomain(&models[i]))
+	}
+
+	return &order.ReturnPaginationResult{
+		Total:   int(total),
+		Returns: requests,
+	}, nil
+}
+
+// UpdateReturn 仅当退货申请仍处于from状态时写入状态和处理结果
+func (r *GormOrderRepository) UpdateReturn(ctx context.Context, request *order.ReturnRequest, from string) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&ReturnModel{}).
+		Where("id = ? AND status = ?", request.ID, from).
+		Updates(map[string]interface{}{
+			"status":      request.Status,
+			"review_note": request.ReviewNote,
+			"reviewed_by": request.ReviewedBy,
+			"reviewed_at": request.ReviewedAt,
+			"received_at": request.ReceivedAt,
+			"refund_id":   request.RefundID,
+			"updated_at":  time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("更新退货申请错误: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// subOrderToDomain 将GORM模型转换为领域模型
+func subOrderToDomain(m *SubOrderModel) *order.SubOrder {
+	return &order.SubOrder{
+		ID:             m.ID,
+		OrderID:        m.OrderID,
+		MerchantID:     m.MerchantID,
+		Status:         m.Status,
+		ItemsTotal:     common.Money(m.ItemsTotal),
+		ShippingAmount: common.Money(m.ShippingAmount),
+		Amount:         common.Money(m.Amount),
+		CommissionRate: m.CommissionRate,
+		Commission:     common.Money(m.Commission),
+		SettledAmount:  common.Money(m.SettledAmount),
+		SettledAt:      m.SettledAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// CreateSubOrders 创建订单的子订单
+func (r *GormOrderRepository) CreateSubOrders(ctx context.Context, subOrders []order.SubOrder) error {
+	if len(subOrders) == 0 {
+		return nil
+	}
+
+	models := make([]SubOrderModel, 0, len(subOrders))
+	for _, s := range subOrders {
+		models = append(models, SubOrderModel{
+			OrderID:        s.OrderID,
+			MerchantID:     s.MerchantID,
+			Status:         s.Status,
+			ItemsTotal:     int64(s.ItemsTotal),
+			ShippingAmount: int64(s.ShippingAmount),
+			Amount:         int64(s.Amount),
+			CommissionRate: s.CommissionRate,
+		})
+	}
+	if err := database.Conn(ctx, r.db).Create(&models).Error; err != nil {
+		return fmt.Errorf("创建子订单错误: %w", err)
+	}
+
+	for i := range models {
+		subOrders[i] = *subOrderToDomain(&models[i])
+	}
+	return nil
+}
+
+// FindSubOrders 按拆分顺序查询订单的子订单
+func (r *GormOrderRepository) FindSubOrders(ctx context.Context, orderID uint) ([]order.SubOrder, error) {
+	var models []SubOrderModel
+	if err := database.Conn(ctx, r.db).Where("order_id = ?", orderID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询子订单错误: %w", err)
+	}
+
+	subOrders := make([]order.SubOrder, 0, len(models))
+	for i := range models {
+		subOrders = append(subOrders, *subOrderToDomain(&models[i]))
+	}
+	return subOrders, nil
+}
+
+// FindSubOrderByID 根据ID查询子订单，并填充订单信息和该商家的订单行
+func (r *GormOrderRepository) FindSubOrderByID(ctx context.Context, id uint) (*order.SubOrder, error) {
+	var model SubOrderModel
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("子订单不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询子订单错误: %w", err)
+	}
+
+	subOrders := []order.SubOrder{*subOrderToDomain(&model)}
+	if err := r.fillSubOrders(ctx, subOrders); err != nil {
+		return nil, err
+	}
+	return &subOrders[0], nil
+}
+
+// FindMerchantSubOrders 按条件分页查询商家的子订单，并填充订单信息和该商家的订单行
+func (r *GormOrderRepository) FindMerchantSubOrders(ctx context.Context, query order.SubOrderQuery) (*order.SubOrderPaginationResult, error) {
+	var models []SubOrderModel
+	var total int64
+
+	db := database.Conn(ctx, r.db).Model(&SubOrderModel{}).Where("merchant_id = ?", query.MerchantID)
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询子订单总数错误: %w", err)
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order("created_at DESC, id DESC").Offset(offset).Limit(query.PageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询子订单列表错误: %w", err)
+	}
+
+	subOrders := make([]order.SubOrder, 0, len(models))
+	for i := range models {
+		subOrders = append(subOrders, *subOrderToDomain(&models[i]))
+	}
+	if err := r.fillSubOrders(ctx, subOrders); err != nil {
+		return nil, err
+	}
+
+	return &order.SubOrderPaginationResult{
+		Total:     int(total),
+		SubOrders: subOrders,
+	}, nil
+}
+
+// fillSubOrders 填充子订单所属订单的订单号、状态和收货地址，以及属于该商家的订单行
+func (r *GormOrderRepository) fillSubOrders(ctx context.Context, subOrders []order.SubOrder) error {
+	if len(subOrders) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(subOrders))
+	for _, s := range subOrders {
+		ids = append(ids, s.OrderID)
+	}
+	var models []OrderModel
+	if err := database.Conn(ctx, r.db).Preload("Items", orderItems).Where("id IN ?", ids).Find(&models).Error; err != nil {
+		return fmt.Errorf("查询子订单的订单错误: %w", err)
+	}
+	orders := make(map[uint]*order.Order, len(models))
+	for i := range models {
+		orders[models[i].ID] = orderToDomain(&models[i])
+	}
+
+	for i := range subOrders {
+		s := &subOrders[i]
+		o, ok := orders[s.OrderID]
+		if !ok {
+			continue
+		}
+		s.OrderSN = o.OrderSN
+		s.OrderStatus = o.Status
+		s.ShippingAddress = o.ShippingAddress
+		s.Items = make([]order.OrderItem, 0, len(o.Items))
+		for _, item := range o.Items {
+			if item.MerchantID == s.MerchantID {
+				s.Items = append(s.Items, item)
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateSubOrder 仅当子订单仍处于from状态时写入状态和结算结果
+func (r *GormOrderRepository) UpdateSubOrder(ctx context.Context, subOrder *order.SubOrder, from string) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&SubOrderModel{}).
+		Where("id = ? AND status = ?", subOrder.ID, from).
+		Updates(map[string]interface{}{
+			"status":         subOrder.Status,
+			"commission":     int64(subOrder.Commission),
+			"settled_amount": int64(subOrder.SettledAmount),
+			"settled_at":     subOrder.SettledAt,
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("更新子订单错误: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Transaction 在事务中执行fn
+func (r *GormOrderRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.Transaction(ctx, r.db, fn)
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormOrderRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&OrderModel{}, &OrderItemModel{}, &TimelineModel{}, &LatePaymentModel{}, &ShipmentModel{}, &ShipmentItemModel{}, &RefundModel{}, &ReturnModel{}, &SubOrderModel{})
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/order"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// orderSNSegment 订单号号段的名称
+const orderSNSegment = "order_sn"
+
+// SNSegmentModel 是GORM订单号号段模型，NextValue为下一个未分配的序号
+type SNSegmentModel struct {
+	Name      string `gorm:"primarykey;type:varchar(32)"`
+	NextValue uint64 `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// TableName 指定表名
+func (SNSegmentModel) TableName() string {
+	return "order_sn_segments"
+}
+
+// GormSNSegmentRepository 是订单号号段仓库的GORM实现
+type GormSNSegmentRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSNSegmentRepository 创建一个新的GORM订单号号段仓库
+func NewGormSNSegmentRepository(db *gorm.DB) order.SNSegmentRepository {
+	return &GormSNSegmentRepository{db: db}
+}
+
+// Allocate 申请一段连续序号
+// 不使用ctx中携带的事务：号段需要立即提交，下单事务回滚时已申请的序号作废即可，
+// 否则其它实例申请号段时要等待整个下单事务结束
+func (r *GormSNSegmentRepository) Allocate(ctx context.Context, size int) (uint64, error) {
+	if size <= 0 {
+		return 0, fmt.Errorf("号段长度无效: %d", size)
+	}
+
+	var start uint64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&SNSegmentModel{}).Where("name = ?", orderSNSegment).
+			UpdateColumn("next_value", gorm.Expr("next_value + ?", size))
+		if result.Error != nil {
+			return fmt.Errorf("申请订单号号段错误: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("订单号号段未初始化，请先执行数据库迁移")
+		}
+
+		var model SNSegmentModel
+		if err := tx.Where("name = ?", orderSNSegment).First(&model).Error; err != nil {
+			return fmt.Errorf("查询订单号号段错误: %w", err)
+		}
+		start = model.NextValue - uint64(size)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return start, nil
+}
+
+// AutoMigrate 自动迁移数据库表结构，并初始化号段，序号从1开始
+func (r *GormSNSegmentRepository) AutoMigrate() error {
+	if err := r.db.AutoMigrate(&SNSegmentModel{}); err != nil {
+		return err
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&SNSegmentModel{Name: orderSNSegment, NextValue: 1}).Error
+}
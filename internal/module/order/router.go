@@ -0,0 +1,49 @@
+package order
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/order/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册订单模块路由
+// 管理后台查看和处理订单的接口由admin模块统一提供
+func RegisterRoutes(router *gin.Engine, handler *handler.OrderHTTPHandler, jwtConfig *config.JWTConfig, idempotent gin.HandlerFunc) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 订单路由(需要认证，写操作支持幂等键)
+	orderRoutes := v1.Group("/orders")
+	orderRoutes.Use(middleware.JWT(jwtConfig), idempotent)
+	{
+		// 下单
+		orderRoutes.POST("", handler.CreateOrder)
+
+		// 获取自己的订单列表
+		orderRoutes.GET("", handler.ListOrders)
+
+		// 获取订单详情
+		orderRoutes.GET("/:id", handler.GetOrder)
+
+		// 根据订单号获取订单详情
+		orderRoutes.GET("/sn/:order_sn", handler.GetOrderBySN)
+
+		// 获取订单状态变更记录
+		orderRoutes.GET("/:id/timeline", handler.GetTimeline)
+
+		// 获取订单的包裹
+		orderRoutes.GET("/:id/shipments", handler.ListShipments)
+
+		// 申请退货和查看退货申请
+		orderRoutes.POST("/:id/returns", handler.CreateReturn)
+		orderRoutes.GET("/:id/returns", handler.ListReturns)
+
+		// 获取订单的退款记录
+		orderRoutes.GET("/:id/refunds", handler.ListRefunds)
+
+		// 取消待支付订单
+		orderRoutes.POST("/:id/cancel", handler.CancelOrder)
+	}
+}
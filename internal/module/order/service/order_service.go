@@ -0,0 +1,1815 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/cart"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/digital"
+	"web3-ecommerce-app/internal/domain/merchant"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/promotion"
+	"web3-ecommerce-app/internal/domain/shipping"
+	"web3-ecommerce-app/internal/domain/tax"
+	"web3-ecommerce-app/internal/domain/wallet"
+	addressService "web3-ecommerce-app/internal/module/address/service"
+	cartService "web3-ecommerce-app/internal/module/cart/service"
+	digitalService "web3-ecommerce-app/internal/module/digital/service"
+	giftCardService "web3-ecommerce-app/internal/module/giftcard/service"
+	productService "web3-ecommerce-app/internal/module/product/service"
+	promotionService "web3-ecommerce-app/internal/module/promotion/service"
+	shippingService "web3-ecommerce-app/internal/module/shipping/service"
+	walletService "web3-ecommerce-app/internal/module/wallet/service"
+	"web3-ecommerce-app/internal/platform/eventbus"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// expireBatchSize 每次关闭超时订单的最大数量
+const expireBatchSize = 100
+
+// OrderService 订单服务接口
+type OrderService interface {
+	// CreateOrder 校验商品、价格和库存后下单，订单、库存预占、促销使用记录和余额抵扣在同一事务中写入
+	// 礼品卡和余额抵扣全部应付金额时订单直接标记为已支付
+	CreateOrder(ctx context.Context, userID uint, input order.CreateOrderInput) (*order.Order, error)
+
+	// CreateSubscriptionOrder 生成订阅的首期或续费订单，调用方在事务中调用时与订阅的更新一起提交
+	CreateSubscriptionOrder(ctx context.Context, input order.CreateSubscriptionOrderInput) (*order.Order, error)
+
+	// GetOrderByID 根据ID获取订单
+	GetOrderByID(ctx context.Context, id uint) (*order.Order, error)
+
+	// GetOrderBySN 根据订单号获取订单
+	GetOrderBySN(ctx context.Context, sn string) (*order.Order, error)
+
+	// GetUserOrderBySN 根据订单号获取用户自己的订单
+	GetUserOrderBySN(ctx context.Context, userID uint, sn string) (*order.Order, error)
+
+	// GetUserOrder 获取用户自己的订单
+	GetUserOrder(ctx context.Context, userID uint, id uint) (*order.Order, error)
+
+	// ListUserOrders 分页查询用户的订单
+	ListUserOrders(ctx context.Context, userID uint, query order.OrderQuery) (*order.OrderPaginationResult, error)
+
+	// ListOrders 按条件分页查询全部订单
+	ListOrders(ctx context.Context, query order.OrderQuery) (*order.OrderPaginationResult, error)
+
+	// GetTimeline 获取订单的状态变更记录
+	GetTimeline(ctx context.Context, id uint) ([]order.TimelineEvent, error)
+
+	// GetUserTimeline 获取用户自己订单的状态变更记录
+	GetUserTimeline(ctx context.Context, userID uint, id uint) ([]order.TimelineEvent, error)
+
+	// CancelUserOrder 用户取消自己的待支付订单
+	CancelUserOrder(ctx context.Context, userID uint, id uint, reason string) (*order.Order, error)
+
+	// UpdateStatus 按状态机将订单切换到目标状态，执行对应的库存和优惠处理并记录时间线
+	UpdateStatus(ctx context.Context, id uint, status string, actor order.Actor, reason string) (*order.Order, error)
+
+	// MarkOrderAsPaid 支付确认后标记订单为已支付，扣减库存并发放数字商品
+	// 订单已关闭时付款记录为逾期付款，等待管理员处理
+	MarkOrderAsPaid(ctx context.Context, id uint, actor order.Actor, payment order.Payment) (*order.Order, error)
+
+	// ExpireUnpaidOrders 关闭超过支付时间的订单，由定时任务调用
+	ExpireUnpaidOrders(ctx context.Context) error
+
+	// ListLatePayments 分页查询逾期付款
+	ListLatePayments(ctx context.Context, query order.LatePaymentQuery) (*order.LatePaymentPaginationResult, error)
+
+	// ResolveLatePayment 管理员处理逾期付款：重新激活订单或记录为已退款
+	ResolveLatePayment(ctx context.Context, id uint, adminID uint, input order.ResolveLatePaymentInput) (*order.LatePayment, error)
+
+	// CreateShipment 为已支付的订单创建包裹，可以只发出部分商品
+	// 首个包裹发出时订单进入备货中，全部实物商品发出后订单变为已发货
+	CreateShipment(ctx context.Context, orderID uint, actor order.Actor, input order.CreateShipmentInput) (*order.Shipment, error)
+
+	// MarkShipmentDelivered 标记包裹已签收，订单已全部发货且全部包裹签收后订单变为已签收
+	MarkShipmentDelivered(ctx context.Context, orderID uint, shipmentID uint, actor order.Actor) (*order.Shipment, error)
+
+	// ListShipments 获取订单的包裹
+	ListShipments(ctx context.Context, orderID uint) ([]order.Shipment, error)
+
+	// ListUserShipments 获取用户自己订单的包裹
+	ListUserShipments(ctx context.Context, userID uint, orderID uint) ([]order.Shipment, error)
+
+	// CreateRefund 管理员直接退款，可以按订单行部分退款，金额退到余额或转账到用户的收款地址
+	// 累计退款达到订单应付金额时订单变为已退款
+	CreateRefund(ctx context.Context, orderID uint, actor order.Actor, input order.CreateRefundInput) (*order.Refund, error)
+
+	// ListRefunds 获取订单的退款
+	ListRefunds(ctx context.Context, orderID uint) ([]order.Refund, error)
+
+	// ListUserRefunds 获取用户自己订单的退款
+	ListUserRefunds(ctx context.Context, userID uint, orderID uint) ([]order.Refund, error)
+
+	// CreateReturn 买家为已发货的订单申请退货
+	CreateReturn(ctx context.Context, userID uint, orderID uint, input order.CreateReturnInput) (*order.ReturnRequest, error)
+
+	// ListUserReturns 获取用户自己订单的退货申请
+	ListUserReturns(ctx context.Context, userID uint, orderID uint) ([]order.ReturnRequest, error)
+
+	// ListReturns 按条件分页查询全部退货申请
+	ListReturns(ctx context.Context, query order.ReturnQuery) (*order.ReturnPaginationResult, error)
+
+	// GetReturn 获取退货申请
+	GetReturn(ctx context.Context, id uint) (*order.ReturnRequest, error)
+
+	// ReviewReturn 管理员同意或拒绝退货申请，只有数字商品的申请同意后直接退款
+	ReviewReturn(ctx context.Context, id uint, adminID uint, input order.ReviewReturnInput) (*order.ReturnRequest, error)
+
+	// ReceiveReturn 管理员确认收到退货，退回库存并按申请的数量退款
+	ReceiveReturn(ctx context.Context, id uint, adminID uint, input order.ReceiveReturnInput) (*order.ReturnRequest, error)
+
+	// HasPurchased 判断用户是否有包含该商品的已支付订单
+	HasPurchased(ctx context.Context, userID uint, productID uint) (bool, error)
+
+	// ListSubOrders 获取订单按商家拆分的子订单
+	ListSubOrders(ctx context.Context, orderID uint) ([]order.SubOrder, error)
+
+	// ListMerchantSubOrders 分页查询商家的子订单
+	ListMerchantSubOrders(ctx context.Context, merchantID uint, query order.SubOrderQuery) (*order.SubOrderPaginationResult, error)
+
+	// GetMerchantSubOrder 获取商家自己的子订单，其他商家的子订单视为不存在
+	GetMerchantSubOrder(ctx context.Context, merchantID uint, id uint) (*order.SubOrder, error)
+
+	// CreateMerchantShipment 商家为自己的子订单发货，只能发出该商家的商品
+	CreateMerchantShipment(ctx context.Context, merchantID uint, subOrderID uint, input order.CreateShipmentInput) (*order.Shipment, error)
+}
+
+// DefaultOrderService 默认订单服务实现
+type DefaultOrderService struct {
+	orderRepo        order.OrderRepository
+	productRepo      product.ProductRepository
+	inventoryRepo    product.InventoryRepository
+	merchantRepo     merchant.MerchantRepository
+	productService   productService.ProductService
+	gateService      productService.GateService
+	promotionService promotionService.PromotionService
+	digitalService   digitalService.DigitalService
+	cartService      cartService.CartService
+	addressService   addressService.AddressService
+	shippingService  shippingService.ShippingService
+	walletService    walletService.WalletService
+	giftCardService  giftCardService.GiftCardService
+	taxCalculator    tax.Calculator
+	snGenerator      order.SNGenerator
+	bus              *eventbus.Bus
+	paymentConfig    *config.PaymentConfig
+}
+
+// NewOrderService 创建订单服务
+func NewOrderService(
+	orderRepo order.OrderRepository,
+	productRepo product.ProductRepository,
+	inventoryRepo product.InventoryRepository,
+	merchantRepo merchant.MerchantRepository,
+	productSvc productService.ProductService,
+	gateSvc productService.GateService,
+	promotionSvc promotionService.PromotionService,
+	digitalSvc digitalService.DigitalService,
+	cartSvc cartService.CartService,
+	addressSvc addressService.AddressService,
+	shippingSvc shippingService.ShippingService,
+	walletSvc walletService.WalletService,
+	giftCardSvc giftCardService.GiftCardService,
+	taxCalculator tax.Calculator,
+	snGenerator order.SNGenerator,
+	bus *eventbus.Bus,
+	paymentConfig *config.PaymentConfig,
+) OrderService {
+	return &DefaultOrderService{
+		orderRepo:        orderRepo,
+		productRepo:      productRepo,
+		inventoryRepo:    inventoryRepo,
+		merchantRepo:     merchantRepo,
+		productService:   productSvc,
+		gateService:      gateSvc,
+		promotionService: promotionSvc,
+		digitalService:   digitalSvc,
+		cartService:      cartSvc,
+		addressService:   addressSvc,
+		shippingService:  shippingSvc,
+		walletService:    walletSvc,
+		giftCardService:  giftCardSvc,
+		taxCalculator:    taxCalculator,
+		snGenerator:      snGenerator,
+		bus:              bus,
+		paymentConfig:    paymentConfig,
+	}
+}
+
+// CreateOrder 下单
+// 价格按持币价格和促销实时计算，与用户看到的单价不一致时拒绝下单；
+// 库存在事务中通过条件更新预占，并发下单不会超卖
+func (s *DefaultOrderService) CreateOrder(ctx context.Context, userID uint, input order.CreateOrderInput) (*order.Order, error) {
+	lines, err := mergeOrderLines(input.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.ProductID)
+	}
+	products, err := s.productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*product.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	now := time.Now()
+	orderSN, err := s.snGenerator.Next(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	newOrder := &order.Order{
+		UserID:         userID,
+		OrderSN:        orderSN,
+		Status:         order.OrderStatusPendingPayment,
+		Items:          make([]order.OrderItem, 0, len(lines)),
+		PromotionCodes: []string{},
+		Note:           strings.TrimSpace(input.Note),
+		ExpiresAt:      now.Add(s.paymentConfig.PaymentWindow),
+	}
+	basket := promotion.Basket{UserID: userID, Codes: input.Codes}
+	parcel := shipping.QuoteRequest{}
+	reservations := make([]product.ReservationItem, 0, len(lines))
+	rates := make(map[uint]int)
+
+	for _, line := range lines {
+		p, ok := byID[line.ProductID]
+		if !ok || !p.IsVisible() {
+			return nil, apierror.NewNotFoundError("商品不存在或已下架", fmt.Sprintf("ID: %d", line.ProductID))
+		}
+		if available := p.AvailableStock(); line.Quantity > available {
+			return nil, apierror.NewInsufficientStockError("库存不足", fmt.Sprintf("商品 %s 可售库存 %d", p.Name, available))
+		}
+		if err := s.checkMerchant(ctx, p, rates); err != nil {
+			return nil, err
+		}
+
+		// 下单时总是实时查询链上持仓
+		gate, err := s.gateService.CheckPurchase(ctx, userID, p.ID, true)
+		if err != nil {
+			return nil, err
+		}
+		if gate.Price != *line.Price {
+			return nil, apierror.NewValidationError("商品价格已变化，请确认后重新下单",
+				fmt.Sprintf("商品 %s 当前价格 %s", p.Name, gate.Price))
+		}
+
+		newOrder.Items = append(newOrder.Items, order.OrderItem{
+			ProductID:   p.ID,
+			ProductName: p.Name,
+			SKU:         p.SKU,
+			ProductType: p.Type,
+			MerchantID:  p.MerchantID,
+			Price:       gate.Price,
+			Quantity:    line.Quantity,
+			TaxClass:    tax.NormalizeClass(p.TaxClass),
+		})
+		basket.Lines = append(basket.Lines, promotion.BasketLine{
+			ProductID:  p.ID,
+			CategoryID: p.CategoryID,
+			Quantity:   line.Quantity,
+			UnitPrice:  gate.Price,
+		})
+		reservations = append(reservations, product.ReservationItem{ProductID: p.ID, Quantity: line.Quantity})
+		if !p.IsDigital() {
+			parcel.Subtotal += gate.Price.Mul(line.Quantity)
+			parcel.Weight += p.Weight * line.Quantity
+		}
+	}
+
+	if newOrder.HasPhysicalItems() {
+		shippingAddress, err := s.resolveShippingAddress(ctx, userID, input)
+		if err != nil {
+			return nil, err
+		}
+		newOrder.ShippingAddress = shippingAddress
+
+		// 运费按实物商品的原价合计判断免运费门槛，运费减免由促销计算
+		if input.ShippingMethodID == 0 {
+			return nil, apierror.NewValidationError("请选择配送方式", "订单包含需要发货的商品")
+		}
+		parcel.Country = shippingAddress.Country
+		quote, err := s.shippingService.QuoteMethod(ctx, input.ShippingMethodID, parcel)
+		if err != nil {
+			return nil, err
+		}
+		newOrder.ShippingMethodID = quote.MethodID
+		newOrder.ShippingMethod = quote.Name
+		basket.ShippingFee = quote.Fee
+	}
+
+	evaluation, err := s.promotionService.Evaluate(ctx, basket)
+	if err != nil {
+		return nil, err
+	}
+	if len(evaluation.Rejected) > 0 {
+		rejected := evaluation.Rejected[0]
+		return nil, apierror.NewValidationError("优惠码不可用", fmt.Sprintf("%s: %s", rejected.Code, rejected.Reason))
+	}
+	applyEvaluation(newOrder, evaluation)
+
+	// 税额按优惠后的实付金额计算，标价不含税时计入应付金额
+	taxResult, err := s.taxCalculator.Calculate(ctx, newOrder.TaxRequest())
+	if err != nil {
+		return nil, err
+	}
+	newOrder.ApplyTax(taxResult)
+
+	err = s.orderRepo.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.orderRepo.Create(ctx, newOrder); err != nil {
+			return err
+		}
+		if err := s.orderRepo.AppendTimeline(ctx, &order.TimelineEvent{
+			OrderID:  newOrder.ID,
+			ToStatus: newOrder.Status,
+			Actor:    order.Actor{Type: order.ActorUser, ID: userID},
+		}); err != nil {
+			return err
+		}
+		if err := s.orderRepo.CreateSubOrders(ctx, newOrder.SplitByMerchant(rates)); err != nil {
+			return err
+		}
+		if _, err := s.inventoryRepo.Reserve(ctx, newOrder.ID, reservations, newOrder.ExpiresAt); err != nil {
+			return err
+		}
+		if err := s.promotionService.Redeem(ctx, newOrder.ID, userID, evaluation); err != nil {
+			return err
+		}
+		return s.applyCredit(ctx, newOrder, input)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 以下操作在事务提交后进行，失败只记录日志
+	s.syncStockStatus(ctx, newOrder)
+	if err := s.cartService.RemoveProducts(ctx, cart.Owner{UserID: userID}, ids); err != nil {
+		log.Printf("从用户 %d 的购物车移除已下单商品失败: %v", userID, err)
+	}
+
+	// 全额抵扣或优惠后金额为0的订单不需要链上支付，支付失败时订单保持待支付，超时后关闭并退回抵扣的余额
+	if newOrder.AmountDue() == 0 {
+		actor := order.Actor{Type: order.ActorUser, ID: userID}
+		reason := "订单金额为0，无需支付"
+		if newOrder.BalancePaid > 0 {
+			reason = "礼品卡和余额全额抵扣"
+		}
+		paid, err := s.markAsPaid(ctx, newOrder.ID, actor, reason, "")
+		if err != nil {
+			log.Printf("订单 %s 无需支付，标记支付失败: %v", newOrder.OrderSN, err)
+			return newOrder, nil
+		}
+		return paid, nil
+	}
+
+	return newOrder, nil
+}
+
+// CreateSubscriptionOrder 生成订阅订单
+// 订阅计划只能绑定数字商品，订单不需要收货地址和配送方式，支付后按数字商品发放
+func (s *DefaultOrderService) CreateSubscriptionOrder(ctx context.Context, input order.CreateSubscriptionOrderInput) (*order.Order, error) {
+	p, err := s.productRepo.FindByID(ctx, input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsDigital() {
+		return nil, apierror.NewValidationError("订阅商品必须是数字商品", fmt.Sprintf("商品 %s", p.Name))
+	}
+	rates := make(map[uint]int)
+	if err := s.checkMerchant(ctx, p, rates); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	orderSN, err := s.snGenerator.Next(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := input.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(s.paymentConfig.PaymentWindow)
+	}
+	newOrder := &order.Order{
+		UserID:  input.UserID,
+		OrderSN: orderSN,
+		Status:  order.OrderStatusPendingPayment,
+		Items: []order.OrderItem{{
+			ProductID:   p.ID,
+			ProductName: p.Name,
+			SKU:         p.SKU,
+			ProductType: p.Type,
+			MerchantID:  p.MerchantID,
+			Price:       input.Price,
+			Quantity:    1,
+			TaxClass:    tax.NormalizeClass(p.TaxClass),
+		}},
+		PromotionCodes: []string{},
+		Note:           strings.TrimSpace(input.Note),
+		ExpiresAt:      expiresAt,
+	}
+	newOrder.CalculateTotal(0)
+
+	taxResult, err := s.taxCalculator.Calculate(ctx, newOrder.TaxRequest())
+	if err != nil {
+		return nil, err
+	}
+	newOrder.ApplyTax(taxResult)
+
+	err = s.orderRepo.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.orderRepo.Create(ctx, newOrder); err != nil {
+			return err
+		}
+		if err := s.orderRepo.AppendTimeline(ctx, &order.TimelineEvent{
+			OrderID:  newOrder.ID,
+			ToStatus: newOrder.Status,
+			Actor:    order.SystemActor,
+			Reason:   newOrder.Note,
+		}); err != nil {
+			return err
+		}
+		return s.orderRepo.CreateSubOrders(ctx, newOrder.SplitByMerchant(rates))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newOrder, nil
+}
+
+// GetOrderByID 根据ID获取订单
+func (s *DefaultOrderService) GetOrderByID(ctx context.Context, id uint) (*order.Order, error) {
+	return s.orderRepo.FindByID(ctx, id)
+}
+
+// GetOrderBySN 根据订单号获取订单，校验位不正确时直接返回错误，不查询数据库
+func (s *DefaultOrderService) GetOrderBySN(ctx context.Context, sn string) (*order.Order, error) {
+	sn = strings.ToUpper(strings.TrimSpace(sn))
+	if !order.ValidOrderSN(sn) {
+		return nil, apierror.NewValidationError("无效的订单号", "请检查订单号是否输入正确")
+	}
+	return s.orderRepo.FindBySN(ctx, sn)
+}
+
+// GetUserOrderBySN 根据订单号获取用户自己的订单，其他用户的订单视为不存在
+func (s *DefaultOrderService) GetUserOrderBySN(ctx context.Context, userID uint, sn string) (*order.Order, error) {
+	orderEntity, err := s.GetOrderBySN(ctx, sn)
+	if err != nil {
+		return nil, err
+	}
+	if orderEntity.UserID != userID {
+		return nil, apierror.NewNotFoundError("订单不存在", fmt.Sprintf("订单号: %s", sn))
+	}
+	return orderEntity, nil
+}
+
+// GetUserOrder 获取用户自己的订单，其他用户的订单视为不存在
+func (s *DefaultOrderService) GetUserOrder(ctx context.Context, userID uint, id uint) (*order.Order, error) {
+	orderEntity, err := s.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if orderEntity.UserID != userID {
+		return nil, apierror.NewNotFoundError("订单不存在", fmt.Sprintf("ID: %d", id))
+	}
+	return orderEntity, nil
+}
+
+// ListUserOrders 分页查询用户的订单
+func (s *DefaultOrderService) ListUserOrders(ctx context.Context, userID uint, query order.OrderQuery) (*order.OrderPaginationResult, error) {
+	query.UserID = userID
+	return s.ListOrders(ctx, query)
+}
+
+// ListOrders 按条件分页查询订单
+func (s *DefaultOrderService) ListOrders(ctx context.Context, query order.OrderQuery) (*order.OrderPaginationResult, error) {
+	// 设置默认分页参数
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	if !query.StartDate.IsZero() && !query.EndDate.IsZero() && !query.StartDate.Before(query.EndDate) {
+		return nil, apierror.NewValidationError("无效的时间范围", "开始时间必须早于结束时间")
+	}
+
+	return s.orderRepo.Find(ctx, query)
+}
+
+// GetTimeline 获取订单的状态变更记录
+func (s *DefaultOrderService) GetTimeline(ctx context.Context, id uint) ([]order.TimelineEvent, error) {
+	if _, err := s.orderRepo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.FindTimeline(ctx, id)
+}
+
+// GetUserTimeline 获取用户自己订单的状态变更记录
+func (s *DefaultOrderService) GetUserTimeline(ctx context.Context, userID uint, id uint) ([]order.TimelineEvent, error) {
+	if _, err := s.GetUserOrder(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.FindTimeline(ctx, id)
+}
+
+// CancelUserOrder 用户取消自己的待支付订单
+func (s *DefaultOrderService) CancelUserOrder(ctx context.Context, userID uint, id uint, reason string) (*order.Order, error) {
+	if _, err := s.GetUserOrder(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.UpdateStatus(ctx, id, order.OrderStatusCancelled, order.Actor{Type: order.ActorUser, ID: userID}, reason)
+}
+
+// UpdateStatus 按目标状态调用对应的状态转换
+func (s *DefaultOrderService) UpdateStatus(ctx context.Context, id uint, status string, actor order.Actor, reason string) (*order.Order, error) {
+	switch status {
+	case order.OrderStatusPaid:
+		return s.MarkOrderAsPaid(ctx, id, actor, order.Payment{Note: reason})
+	case order.OrderStatusProcessing:
+		return s.transition(ctx, id, actor, reason, (*order.Order).StartProcessing, nil)
+	case order.OrderStatusShipped:
+		return s.transition(ctx, id, actor, reason, (*order.Order).MarkAsShipped, nil)
+	case order.OrderStatusDelivered:
+		return s.transition(ctx, id, actor, reason, (*order.Order).MarkAsDelivered, nil)
+	case order.OrderStatusCompleted:
+		return s.transition(ctx, id, actor, reason, (*order.Order).MarkAsCompleted, s.settleSubOrders)
+	case order.OrderStatusCancelled:
+		return s.close(ctx, id, actor, reason, (*order.Order).Cancel)
+	case order.OrderStatusExpired:
+		return s.close(ctx, id, actor, reason, (*order.Order).Expire)
+	case order.OrderStatusRefunded:
+		return s.transition(ctx, id, actor, reason, (*order.Order).MarkAsRefunded, s.cancelSubOrders)
+	default:
+		return nil, apierror.NewValidationError("无效的订单状态", status)
+	}
+}
+
+// MarkOrderAsPaid 标记订单为已支付，链上支付确认和管理员确认收款都经过这里
+// 订单已超时关闭或被取消，或者预占已释放且库存不足以重新扣减时，付款记录为逾期付款，避免款项丢失
+func (s *DefaultOrderService) MarkOrderAsPaid(ctx context.Context, id uint, actor order.Actor, payment order.Payment) (*order.Order, error) {
+	orderEntity, err := s.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if orderEntity.IsClosed() {
+		return nil, s.recordLatePayment(ctx, orderEntity, payment)
+	}
+	if payment.Amount > 0 && payment.Amount < orderEntity.AmountDue() {
+		return nil, apierror.NewValidationError("付款金额不足",
+			fmt.Sprintf("应付: %s, 实付: %s", orderEntity.AmountDue(), payment.Amount))
+	}
+
+	reasons := make([]string, 0, 3)
+	if payment.TxHash != "" {
+		reasons = append(reasons, "链上交易 "+payment.TxHash)
+	}
+	if note := strings.TrimSpace(payment.Note); note != "" {
+		reasons = append(reasons, note)
+	}
+
+	// 下单后转走代币的用户不能再以持有者身份完成购买：
+	// 款项已到账时关闭订单并记录为逾期付款，由管理员退款或确认后重新激活；没有到账凭据时只返回错误，订单保持待支付
+	// 链上节点不可用时放行，下单时已实时验证过门槛，款项到账后不应因无法查询而卡住订单
+	if err := s.checkHolderEligibility(ctx, orderEntity); err != nil {
+		switch {
+		case !hasErrorCode(err, apierror.ErrorCodeForbidden):
+			log.Printf("订单 %s 支付确认时无法验证持币门槛，按下单时的验证结果确认支付: %v", orderEntity.OrderSN, err)
+			reasons = append(reasons, "支付确认时未能验证持币门槛")
+		case !payment.Received():
+			return nil, err
+		default:
+			log.Printf("订单 %s 支付确认时持币门槛验证未通过: %v", orderEntity.OrderSN, err)
+			closed, closeErr := s.close(ctx, id, order.SystemActor, "支付确认时持币门槛验证未通过", (*order.Order).Cancel)
+			if closeErr != nil {
+				return nil, closeErr
+			}
+			return nil, s.recordLatePayment(ctx, closed, payment)
+		}
+	}
+
+	reason := strings.Join(reasons, "，")
+	paid, err := s.markAsPaid(ctx, id, actor, reason, payment.TxHash)
+	if err == nil {
+		return paid, nil
+	}
+
+	// 确认支付期间订单可能刚被定时任务关闭
+	latest, findErr := s.orderRepo.FindByID(ctx, id)
+	if findErr == nil && (latest.IsClosed() || hasErrorCode(err, apierror.ErrorCodeInsufficientStock)) {
+		return nil, s.recordLatePayment(ctx, latest, payment)
+	}
+	return nil, err
+}
+
+// ExpireUnpaidOrders 分批关闭超过支付时间的订单，归还库存预占和促销使用次数
+func (s *DefaultOrderService) ExpireUnpaidOrders(ctx context.Context) error {
+	total := 0
+	for {
+		orders, err := s.orderRepo.FindExpired(ctx, time.Now(), expireBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range orders {
+			_, err := s.close(ctx, orders[i].ID, order.SystemActor, "超过支付时间未支付", (*order.Order).Expire)
+			if err != nil {
+				// 订单已被支付或取消时跳过，下一批不会再查到该订单
+				if hasErrorCode(err, apierror.ErrorCodeInvalidTransition) {
+					continue
+				}
+				return err
+			}
+			total++
+		}
+
+		if len(orders) < expireBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("已关闭 %d 个超时未支付的订单", total)
+	}
+	return nil
+}
+
+// ListLatePayments 分页查询逾期付款
+func (s *DefaultOrderService) ListLatePayments(ctx context.Context, query order.LatePaymentQuery) (*order.LatePaymentPaginationResult, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	return s.orderRepo.FindLatePayments(ctx, query)
+}
+
+// ResolveLatePayment 处理逾期付款
+// 重新激活时订单恢复为已支付并重新扣减库存，促销使用次数在订单关闭时已归还，不再重新占用；
+// 退款需要管理员在链上完成转账，这里只记录处理结果
+func (s *DefaultOrderService) ResolveLatePayment(ctx context.Context, id uint, adminID uint, input order.ResolveLatePaymentInput) (*order.LatePayment, error) {
+	payment, err := s.orderRepo.FindLatePaymentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != order.LatePaymentStatusPending {
+		return nil, apierror.NewInvalidStateTransitionError("逾期付款已处理", fmt.Sprintf("ID: %d, 状态: %s", id, payment.Status))
+	}
+
+	note := strings.TrimSpace(input.Note)
+	if input.Action == order.LatePaymentActionRefund {
+		payment.Resolve(order.LatePaymentStatusRefunded, adminID, note, time.Now())
+		if err := s.resolveLatePayment(ctx, payment); err != nil {
+			return nil, err
+		}
+		return payment, nil
+	}
+
+	payment.Resolve(order.LatePaymentStatusReactivated, adminID, note, time.Now())
+	actor := order.Actor{Type: order.ActorAdmin, ID: adminID}
+	orderEntity, err := s.transition(ctx, payment.OrderID, actor, "收到逾期付款，重新激活订单", func(o *order.Order, now time.Time) error {
+		o.PaymentTxHash = payment.TxHash
+		return o.Reactivate(now)
+	}, func(ctx context.Context, o *order.Order) error {
+		if err := s.fulfillPayment(ctx, o); err != nil {
+			return err
+		}
+		if err := s.chargeCredit(ctx, o); err != nil {
+			return err
+		}
+		return s.resolveLatePayment(ctx, payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.afterPaid(ctx, orderEntity)
+	return payment, nil
+}
+
+// CreateShipment 创建包裹
+// 锁定订单后计算未发货数量，并发为同一订单发货时不会重复发出同一件商品
+func (s *DefaultOrderService) CreateShipment(ctx context.Context, orderID uint, actor order.Actor, input order.CreateShipmentInput) (*order.Shipment, error) {
+	return s.createShipment(ctx, orderID, actor, input, nil)
+}
+
+// createShipment 创建包裹，owns不为空时只能发出满足条件的订单行
+func (s *DefaultOrderService) createShipment(
+	ctx context.Context,
+	orderID uint,
+	actor order.Actor,
+	input order.CreateShipmentInput,
+	owns func(item *order.OrderItem) bool,
+) (*order.Shipment, error) {
+	var shipment *order.Shipment
+	err := s.orderRepo.Transaction(ctx, func(ctx context.Context) error {
+		orderEntity, err := s.orderRepo.LockByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if orderEntity.Status != order.OrderStatusPaid && orderEntity.Status != order.OrderStatusProcessing {
+			return apierror.NewInvalidStateTransitionError("订单当前状态不能发货", fmt.Sprintf("订单号: %s, 状态: %s", orderEntity.OrderSN, orderEntity.Status))
+		}
+
+		shipments, err := s.orderRepo.FindShipments(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		remaining := orderEntity.UnshippedQuantities(shipments)
+		if owns != nil {
+			for _, item := range orderEntity.Items {
+				if !owns(&item) {
+					delete(remaining, item.ID)
+				}
+			}
+		}
+		items, err := shipmentItems(orderEntity, remaining, input.Items)
+		if err != nil {
+			return err
+		}
+
+		shipment = &order.Shipment{
+			OrderID:        orderID,
+			Carrier:        strings.TrimSpace(input.Carrier),
+			TrackingNumber: strings.TrimSpace(input.TrackingNumber),
+			Status:         order.ShipmentStatusShipped,
+			Items:          items,
+			ShippedAt:      time.Now(),
+		}
+		if err := s.orderRepo.CreateShipment(ctx, shipment); err != nil {
+			return err
+		}
+
+		reason := fmt.Sprintf("%s %s", shipment.Carrier, shipment.TrackingNumber)
+		if orderEntity.Status == order.OrderStatusPaid {
+			if _, err := s.transition(ctx, orderID, actor, reason, (*order.Order).StartProcessing, nil); err != nil {
+				return err
+			}
+		}
+		if len(orderEntity.UnshippedQuantities(append(shipments, *shipment))) == 0 {
+			if _, err := s.transition(ctx, orderID, actor, reason, (*order.Order).MarkAsShipped, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
+
+// MarkShipmentDelivered 标记包裹已签收
+func (s *DefaultOrderService) MarkShipmentDelivered(ctx context.Context, orderID uint, shipmentID uint, actor order.Actor) (*order.Shipment, error) {
+	var shipment *order.Shipment
+	err := s.orderRepo.Transaction(ctx, func(ctx context.Context) error {
+		orderEntity, err := s.orderRepo.LockByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		shipments, err := s.orderRepo.FindShipments(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		for i := range shipments {
+			if shipments[i].ID == shipmentID {
+				shipment = &shipments[i]
+			}
+		}
+		if shipment == nil {
+			return apierror.NewNotFoundError("包裹不存在", fmt.Sprintf("ID: %d", shipmentID))
+		}
+
+		if err := shipment.MarkAsDelivered(time.Now()); err != nil {
+			return err
+		}
+		ok, err := s.orderRepo.MarkShipmentDelivered(ctx, shipment)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apierror.NewInvalidStateTransitionError("包裹已签收", fmt.Sprintf("ID: %d", shipmentID))
+		}
+
+		// 订单为已发货说明全部商品都已发出，部分发货时等剩余商品发出并签收后再变更
+		if orderEntity.Status == order.OrderStatusShipped && order.AllDelivered(shipments) {
+			if _, err := s.transition(ctx, orderID, actor, "全部包裹已签收", (*order.Order).MarkAsDelivered, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
+
+// ListShipments 获取订单的包裹
+func (s *DefaultOrderService) ListShipments(ctx context.Context, orderID uint) ([]order.Shipment, error) {
+	if _, err := s.orderRepo.FindByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.FindShipments(ctx, orderID)
+}
+
+// ListUserShipments 获取用户自己订单的包裹
+func (s *DefaultOrderService) ListUserShipments(ctx context.Context, userID uint, orderID uint) ([]order.Shipment, error) {
+	if _, err := s.GetUserOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.FindShipments(ctx, orderID)
+}
+
+// CreateRefund 管理员直接退款
+func (s *DefaultOrderService) CreateRefund(ctx context.Context, orderID uint, actor order.Actor, input order.CreateRefundInput) (*order.Refund, error) {
+	var orderEntity *order.Order
+	var refund *order.Refund
+	err := s.orderRepo.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		orderEntity, err = s.orderRepo.LockByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		refund, err = s.issueRefund(ctx, orderEntity, actor, 0, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if refund.Restocked {
+		s.syncStockStatus(ctx, orderEntity)
+	}
+	return refund, nil
+}
+
+// ListRefunds 获取订单的退款
+func (s *DefaultOrderService) ListRefunds(ctx context.Context, orderID uint) ([]order.Refund, error) {
+	if _, err := s.orderRepo.FindByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.FindRefunds(ctx, orderID)
+}
+
+// ListUserRefunds 获取用户自己订单的退款
+func (s *DefaultOrderService) ListUserRefunds(ctx context.Context, userID uint, orderID uint) ([]order.Refund, error) {
+	if _, err := s.GetUserOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.FindRefunds(ctx, orderID)
+}
+
+// CreateReturn 买家申请退货
+// 可申请的数量为购买数量减去已退款和处理中的退货申请的数量
+func (s *DefaultOrderService) CreateReturn(ctx context.Context, userID uint, orderID uint, input order.CreateReturnInput) (*order.ReturnRequest, error) {
+	if _, err := s.GetUserOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+
+	refundMethod := input.RefundMethod
+	if refundMethod == "" {
+		refundMethod = order.RefundMethodBalance
+	}
+	if refundMethod == order.RefundMethodTransfer {
+		account, err := s.walletService.GetAccount(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if account.PayoutAddress == "" {
+			return nil, apierror.NewValidationError("请先设置收款地址", "退款转账需要收款地址")
+		}
+	}
+
+	request := &order.ReturnRequest{
+		OrderID:      orderID,
+		UserID:       userID,
+		Status:       order.ReturnStatusRequested,
+		Reason:       input.Reason,
+		Description:  strings.TrimSpace(input.Description),
+		RefundMethod: refundMethod,
+		Items:        make([]order.ReturnItem, 0, len(input.Items)),
+	}
+	err := s.orderRepo.Transaction(ctx, func(ctx context.Context) error {
+		orderEntity, err := s.orderRepo.LockByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		switch orderEntity.Status {
+		case order.OrderStatusShipped, order.OrderStatusDelivered, order.OrderStatusCompleted:
+		default:
+			return apierror.NewInvalidStateTransitionError("订单当前状态不能申请退货", fmt.Sprintf("订单号: %s, 状态: %s", orderEntity.OrderSN, orderEntity.Status))
+		}
+
+		refunds, err := s.orderRepo.FindRefunds(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		requests, err := s.orderRepo.FindReturnsByOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		remaining, _ := orderEntity.Refundable(refunds)
+		for _, r := range requests {
+			if !r.IsOpen() {
+				continue
+			}
+			for _, item := range r.Items {
+				left := remaining[item.OrderItemID]
+				left.Quantity -= item.Quantity
+				remaining[item.OrderItemID] = left
+			}
+		}
+
+		byID := orderItemsByID(orderEntity)
+		for _, in := range input.Items {
+			item, ok := byID[in.OrderItemID]
+			if !ok {
+				return apierror.NewValidationError("订单行不存在", fmt.Sprintf("订单行ID: %d", in.OrderItemID))
+			}
+			left := remaining[item.ID]
+			if in.Quantity > left.Quantity {
+				return apierror.NewValidationError("退货数量超过可退数量", fmt.Sprintf("%s 可退数量 %d", item.ProductName, max(left.Quantity, 0)))
+			}
+			left.Quantity -= in.Quantity
+			remaining[item.ID] = left
+			request.Items = append(request.Items, order.ReturnItem{OrderItemID: item.ID, ProductID: item.ProductID, Quantity: in.Quantity})
+		}
+		return s.orderRepo.CreateReturn(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ListUserReturns 获取用户自己订单的退货申请
+func (s *DefaultOrderService) ListUserReturns(ctx context.Context, userID uint, orderID uint) ([]order.ReturnRequest, error) {
+	if _, err := s.GetUserOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.FindReturnsByOrder(ctx, orderID)
+}
+
+// ListReturns 按条件分页查询退货申请
+func (s *DefaultOrderService) ListReturns(ctx context.Context, query order.ReturnQuery) (*order.ReturnPaginationResult, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	return s.orderRepo.FindReturns(ctx, query)
+}
+
+// GetReturn 获取退货申请
+func (s *DefaultOrderService) GetReturn(ctx context.Context, id uint) (*order.ReturnRequest, error) {
+	return s.orderRepo.FindReturnByID(ctx, id)
+}
+
+// ReviewReturn 审核退货申请
+// 只有数字商品的申请没有需要寄回的商品，同意后在同一事务中直接退款
+func (s *DefaultOrderService) ReviewReturn(ctx context.Context, id uint, adminID uint, input order.ReviewReturnInput) (*order.ReturnRequest, error) {
+	request, err := s.orderRepo.FindReturnByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	orderEntity, err := s.orderRepo.FindByID(ctx, request.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := request.Status
+	note := strings.TrimSpace(input.Note)
+	if err := request.Review(input.Action, adminID, note, time.Now()); err != nil {
+		return nil, err
+	}
+
+	actor := order.Actor{Type: order.ActorAdmin, ID: adminID}
+	err = s.orderRepo.Transaction(ctx, func(ctx context.Context) error {
+		ok, err := s.orderRepo.UpdateReturn(ctx, request, from)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apierror.NewInvalidStateTransitionError("退货申请已审核", fmt.Sprintf("ID: %d", id))
+		}
+		if request.Status == order.ReturnStatusApproved && !returnsPhysicalItems(orderEntity, request) {
+			return s.receiveReturn(ctx, request, actor, false, note)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ReceiveReturn 确认收到退货并退款
+func (s *DefaultOrderService) ReceiveReturn(ctx context.Context, id uint, adminID uint, input order.ReceiveReturnInput) (*order.ReturnRequest, error) {
+	request, err := s.orderRepo.FindReturnByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	restock := input.Restock == nil || *input.Restock
+
+	actor := order.Actor{Type: order.ActorAdmin, ID: adminID}
+	err = s.orderRepo.Transaction(ctx, func(ctx context.Context) error {
+		return s.receiveReturn(ctx, request, actor, restock, strings.TrimSpace(input.Note))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if restock {
+		if orderEntity, err := s.orderRepo.FindByID(ctx, request.OrderID); err == nil {
+			s.syncStockStatus(ctx, orderEntity)
+		}
+	}
+	return request, nil
+}
+
+// HasPurchased 判断用户是否有包含该商品的已支付订单
+func (s *DefaultOrderService) HasPurchased(ctx context.Context, userID uint, productID uint) (bool, error) {
+	return s.orderRepo.HasPurchased(ctx, userID, productID)
+}
+
+// ListSubOrders 获取订单的子订单
+func (s *DefaultOrderService) ListSubOrders(ctx context.Context, orderID uint) ([]order.SubOrder, error) {
+	if _, err := s.orderRepo.FindByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.FindSubOrders(ctx, orderID)
+}
+
+// ListMerchantSubOrders 分页查询商家的子订单
+func (s *DefaultOrderService) ListMerchantSubOrders(ctx context.Context, merchantID uint, query order.SubOrderQuery) (*order.SubOrderPaginationResult, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	query.MerchantID = merchantID
+	return s.orderRepo.FindMerchantSubOrders(ctx, query)
+}
+
+// GetMerchantSubOrder 获取商家自己的子订单
+func (s *DefaultOrderService) GetMerchantSubOrder(ctx context.Context, merchantID uint, id uint) (*order.SubOrder, error) {
+	subOrder, err := s.orderRepo.FindSubOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if subOrder.MerchantID != merchantID {
+		return nil, apierror.NewNotFoundError("子订单不存在", fmt.Sprintf("ID: %d", id))
+	}
+	return subOrder, nil
+}
+
+// CreateMerchantShipment 商家为子订单发货
+// 包裹记录在订单上，与平台发货共用未发货数量的计算，订单的全部实物商品发出后订单变为已发货
+func (s *DefaultOrderService) CreateMerchantShipment(ctx context.Context, merchantID uint, subOrderID uint, input order.CreateShipmentInput) (*order.Shipment, error) {
+	subOrder, err := s.GetMerchantSubOrder(ctx, merchantID, subOrderID)
+	if err != nil {
+		return nil, err
+	}
+	actor := order.Actor{Type: order.ActorMerchant, ID: merchantID}
+	return s.createShipment(ctx, subOrder.OrderID, actor, input, func(item *order.OrderItem) bool {
+		return item.MerchantID == merchantID
+	})
+}
+
+// markAsPaid 标记订单为已支付，订单状态和库存扣减在同一事务中提交
+func (s *DefaultOrderService) markAsPaid(ctx context.Context, id uint, actor order.Actor, reason string, txHash string) (*order.Order, error) {
+	orderEntity, err := s.transition(ctx, id, actor, reason, func(o *order.Order, now time.Time) error {
+		o.PaymentTxHash = txHash
+		return o.MarkAsPaid(now)
+	}, s.fulfillPayment)
+	if err != nil {
+		return nil, err
+	}
+	return s.afterPaid(ctx, orderEntity), nil
+}
+
+// afterPaid 支付事务提交后同步商品状态、发布支付事件，数字商品已在支付事务中发放，只有数字商品的订单直接完成
+// 这些操作失败只记录日志，返回最新的订单
+func (s *DefaultOrderService) afterPaid(ctx context.Context, orderEntity *order.Order) *order.Order {
+	// 预占可能在支付时重新创建，可售库存会发生变化
+	s.syncStockStatus(ctx, orderEntity)
+
+	paidAt := time.Now()
+	if orderEntity.PaidAt != nil {
+		paidAt = *orderEntity.PaidAt
+	}
+	s.bus.Publish(ctx, order.EventOrderPaid, order.PaidEvent{
+		OrderID: orderEntity.ID,
+		UserID:  orderEntity.UserID,
+		PaidAt:  paidAt,
+	})
+
+	if !orderEntity.HasPhysicalItems() {
+		completed, err := s.transition(ctx, orderEntity.ID, order.SystemActor, "数字商品已发放", (*order.Order).MarkAsCompleted, s.settleSubOrders)
+		if err != nil {
+			log.Printf("完成订单 %d 失败: %v", orderEntity.ID, err)
+			return orderEntity
+		}
+		return completed
+	}
+	return orderEntity
+}
+
+// fulfillPayment 在支付事务中扣减库存并发放数字商品
+// 激活码不足时返回库存不足错误，订单保持未支付，付款按库存不足记录为逾期付款
+func (s *DefaultOrderService) fulfillPayment(ctx context.Context, o *order.Order) error {
+	if err := s.confirmInventory(ctx, o); err != nil {
+		return err
+	}
+
+	var items []digital.DeliveryItem
+	for _, item := range o.Items {
+		if item.ProductType == product.ProductTypeDigital {
+			items = append(items, digital.DeliveryItem{ProductID: item.ProductID, Quantity: item.Quantity})
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return s.digitalService.Deliver(ctx, o.ID, o.UserID, items)
+}
+
+// checkHolderEligibility 实时查询链上持仓，重新验证订单中每个商品的持币门槛和持有者价格
+// 商品已被删除时跳过验证
+func (s *DefaultOrderService) checkHolderEligibility(ctx context.Context, o *order.Order) error {
+	for _, item := range o.Items {
+		gate, err := s.gateService.CheckPurchase(ctx, o.UserID, item.ProductID, true)
+		if err != nil {
+			if hasErrorCode(err, apierror.ErrorCodeNotFound) {
+				continue
+			}
+			return err
+		}
+		if !gate.Honors(item.Price) {
+			return apierror.NewForbiddenError("持有者专属价格已失效",
+				fmt.Sprintf("商品 %s 当前价格 %s", item.ProductName, gate.Price))
+		}
+	}
+	return nil
+}
+
+// confirmInventory 将订单的库存预占转为实际扣减
+// 支付截止时间刚过时预占可能已被定时任务释放，此时按订单商品重新预占后再扣减
+func (s *DefaultOrderService) confirmInventory(ctx context.Context, o *order.Order) error {
+	reservations, err := s.inventoryRepo.FindByOrder(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+
+	var released []product.ReservationItem
+	for _, r := range reservations {
+		if r.Status == product.ReservationStatusReleased {
+			released = append(released, product.ReservationItem{ProductID: r.ProductID, Quantity: r.Quantity})
+		}
+	}
+	if len(released) > 0 {
+		if _, err := s.inventoryRepo.Reserve(ctx, o.ID, released, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return s.inventoryRepo.ConfirmByOrder(ctx, o.ID)
+}
+
+// recordLatePayment 记录已关闭订单收到的付款，并返回说明情况的错误
+func (s *DefaultOrderService) recordLatePayment(ctx context.Context, o *order.Order, payment order.Payment) error {
+	amount := payment.Amount
+	if amount == 0 {
+		amount = o.AmountDue()
+	}
+
+	latePayment := &order.LatePayment{
+		OrderID: o.ID,
+		UserID:  o.UserID,
+		TxHash:  payment.TxHash,
+		Amount:  amount,
+		Status:  order.LatePaymentStatusPending,
+	}
+	if err := s.orderRepo.CreateLatePayment(ctx, latePayment); err != nil {
+		return err
+	}
+	log.Printf("订单 %s 已关闭，收到的付款已记录为逾期付款 %d", o.OrderSN, latePayment.ID)
+
+	return apierror.NewInvalidStateTransitionError("订单已关闭，付款已记录，等待人工处理", fmt.Sprintf("订单号: %s", o.OrderSN))
+}
+
+// resolveLatePayment 写入逾期付款的处理结果，并发处理同一笔付款时只有一个请求成功
+func (s *DefaultOrderService) resolveLatePayment(ctx context.Context, payment *order.LatePayment) error {
+	ok, err := s.orderRepo.ResolveLatePayment(ctx, payment)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apierror.NewInvalidStateTransitionError("逾期付款已处理", fmt.Sprintf("ID: %d", payment.ID))
+	}
+	return nil
+}
+
+// close 关闭待支付订单，归还库存预占和促销使用次数
+func (s *DefaultOrderService) close(ctx context.Context, id uint, actor order.Actor, reason string, apply func(o *order.Order, now time.Time) error) (*order.Order, error) {
+	orderEntity, err := s.transition(ctx, id, actor, reason, apply, func(ctx context.Context, o *order.Order) error {
+		if err := s.inventoryRepo.ReleaseByOrder(ctx, o.ID); err != nil {
+			return err
+		}
+		if err := s.cancelSubOrders(ctx, o); err != nil {
+			return err
+		}
+		if err := s.returnCredit(ctx, o); err != nil {
+			return err
+		}
+		return s.promotionService.Release(ctx, o.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.syncStockStatus(ctx, orderEntity)
+	return orderEntity, nil
+}
+
+// transition 在事务中执行订单状态转换、记录时间线并执行附带的操作
+// 状态使用条件更新，并发修改同一订单时只有一个请求成功
+func (s *DefaultOrderService) transition(
+	ctx context.Context,
+	id uint,
+	actor order.Actor,
+	reason string,
+	apply func(o *order.Order, now time.Time) error,
+	effect func(ctx context.Context, o *order.Order) error,
+) (*order.Order, error) {
+	orderEntity, err := s.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	from := orderEntity.Status
+	now := time.Now()
+	if err := apply(orderEntity, now); err != nil {
+		return nil, err
+	}
+
+	err = s.orderRepo.Transaction(ctx, func(ctx context.Context) error {
+		ok, err := s.orderRepo.UpdateStatus(ctx, orderEntity, from)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apierror.NewInvalidStateTransitionError("订单状态已被修改，请刷新后重试", fmt.Sprintf("ID: %d", id))
+		}
+		if err := s.orderRepo.AppendTimeline(ctx, &order.TimelineEvent{
+			OrderID:    orderEntity.ID,
+			FromStatus: from,
+			ToStatus:   orderEntity.Status,
+			Actor:      actor,
+			Reason:     strings.TrimSpace(reason),
+			CreatedAt:  now,
+		}); err != nil {
+			return err
+		}
+		if effect != nil {
+			return effect(ctx, orderEntity)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orderEntity, nil
+}
+
+// syncStockStatus 库存预占变化后同步订单商品的上架/缺货状态
+// 同步失败不影响订单，商品状态由定时任务兜底修正
+func (s *DefaultOrderService) syncStockStatus(ctx context.Context, o *order.Order) {
+	ids := make([]uint, 0, len(o.Items))
+	for _, item := range o.Items {
+		ids = append(ids, item.ProductID)
+	}
+	if err := s.productService.SyncStockStatus(ctx, ids...); err != nil {
+		log.Printf("同步订单 %d 的商品库存状态失败: %v", o.ID, err)
+	}
+}
+
+// resolveShippingAddress 确定订单的收货地址：优先使用指定的地址簿地址，其次是直接填写的地址，最后是默认地址
+// 返回的地址是独立的副本，写入订单后修改地址簿不会影响订单
+func (s *DefaultOrderService) resolveShippingAddress(ctx context.Context, userID uint, input order.CreateOrderInput) (*order.Address, error) {
+	if input.AddressID != 0 {
+		saved, err := s.addressService.GetAddress(ctx, userID, input.AddressID)
+		if err != nil {
+			return nil, err
+		}
+		return saved.Snapshot(), nil
+	}
+
+	if input.ShippingAddress != nil {
+		shippingAddress := *input.ShippingAddress
+		shippingAddress.Normalize()
+		if err := shippingAddress.Validate(); err != nil {
+			return nil, err
+		}
+		return &shippingAddress, nil
+	}
+
+	saved, err := s.addressService.GetDefaultAddress(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		return nil, apierror.NewValidationError("请填写收货地址", "订单包含需要发货的商品")
+	}
+	return saved.Snapshot(), nil
+}
+
+// receiveReturn 在事务中按退货申请的数量退款，并将申请标记为已退款
+func (s *DefaultOrderService) receiveReturn(ctx context.Context, request *order.ReturnRequest, actor order.Actor, restock bool, note string) error {
+	orderEntity, err := s.orderRepo.LockByID(ctx, request.OrderID)
+	if err != nil {
+		return err
+	}
+
+	input := order.CreateRefundInput{
+		Items:   make([]order.CreateRefundItemInput, 0, len(request.Items)),
+		Method:  request.RefundMethod,
+		Restock: restock,
+		Reason:  note,
+	}
+	if input.Reason == "" {
+		input.Reason = fmt.Sprintf("退货申请 %d", request.ID)
+	}
+	for _, item := range request.Items {
+		input.Items = append(input.Items, order.CreateRefundItemInput{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+	}
+	refund, err := s.issueRefund(ctx, orderEntity, actor, request.ID, input)
+	if err != nil {
+		return err
+	}
+
+	if err := request.MarkAsRefunded(refund.ID, time.Now()); err != nil {
+		return err
+	}
+	ok, err := s.orderRepo.UpdateReturn(ctx, request, order.ReturnStatusApproved)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apierror.NewInvalidStateTransitionError("退货申请已处理", fmt.Sprintf("ID: %d", request.ID))
+	}
+	return nil
+}
+
+// issueRefund 在事务中校验可退数量和金额，记录退款、退回库存并支付退款，累计全额退款后订单变为已退款
+// 调用前必须已通过LockByID锁定订单，并发退款不会超过订单实付金额
+func (s *DefaultOrderService) issueRefund(ctx context.Context, o *order.Order, actor order.Actor, returnID uint, input order.CreateRefundInput) (*order.Refund, error) {
+	if !o.CanRefund() {
+		return nil, apierror.NewInvalidStateTransitionError("订单当前状态不能退款", fmt.Sprintf("订单号: %s, 状态: %s", o.OrderSN, o.Status))
+	}
+
+	refunds, err := s.orderRepo.FindRefunds(ctx, o.ID)
+	if err != nil {
+		return nil, err
+	}
+	remaining, shippingRemaining := o.Refundable(refunds)
+	if input.ShippingAmount > shippingRemaining {
+		return nil, apierror.NewValidationError("退还运费超过可退运费", fmt.Sprintf("可退运费 %s", shippingRemaining))
+	}
+
+	refund := &order.Refund{
+		OrderID:        o.ID,
+		UserID:         o.UserID,
+		ReturnID:       returnID,
+		Items:          make([]order.RefundItem, 0, len(input.Items)),
+		ShippingAmount: input.ShippingAmount,
+		Amount:         input.ShippingAmount,
+		Method:         input.Method,
+		Restocked:      input.Restock,
+		Reason:         strings.TrimSpace(input.Reason),
+		Actor:          actor,
+	}
+	byID := orderItemsByID(o)
+	seen := make(map[uint]bool, len(input.Items))
+	for _, in := range input.Items {
+		item, ok := byID[in.OrderItemID]
+		if !ok {
+			return nil, apierror.NewValidationError("订单行不存在", fmt.Sprintf("订单行ID: %d", in.OrderItemID))
+		}
+		if seen[item.ID] {
+			return nil, apierror.NewValidationError("订单行重复", fmt.Sprintf("订单行ID: %d", item.ID))
+		}
+		seen[item.ID] = true
+
+		amount, err := o.ProratedRefund(item, remaining[item.ID], in.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		if in.Amount != nil {
+			if *in.Amount > remaining[item.ID].Amount {
+				return nil, apierror.NewValidationError("退款金额超过可退金额", fmt.Sprintf("%s 可退金额 %s", item.ProductName, remaining[item.ID].Amount))
+			}
+			amount = *in.Amount
+		}
+		refund.Items = append(refund.Items, order.RefundItem{OrderItemID: item.ID, ProductID: item.ProductID, Quantity: in.Quantity, Amount: amount})
+		refund.Amount += amount
+	}
+	if refund.Amount <= 0 {
+		return nil, apierror.NewValidationError("退款金额必须大于0", fmt.Sprintf("订单号: %s", o.OrderSN))
+	}
+	_, refund.StoreCredit = o.SplitRefund(refunds, refund.Amount)
+
+	if err := s.orderRepo.CreateRefund(ctx, refund); err != nil {
+		return nil, err
+	}
+	if err := s.reverseSettlements(ctx, o, append(refunds, *refund)); err != nil {
+		return nil, err
+	}
+	if refund.Restocked {
+		for _, item := range refund.Items {
+			if byID[item.OrderItemID].ProductType == product.ProductTypeDigital {
+				continue
+			}
+			if err := s.productRepo.UpdateStock(ctx, item.ProductID, item.Quantity); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.revokeDigital(ctx, o, byID, remaining, refund); err != nil {
+		return nil, err
+	}
+	if err := s.voidGiftCards(ctx, o, byID, refund); err != nil {
+		return nil, err
+	}
+
+	// 转账退款最多退回链上实付金额，其余现金部分退回站内余额，购物金抵扣的部分退回购物金
+	note := fmt.Sprintf("订单 %s 退款", o.OrderSN)
+	toBalance := refund.Amount - refund.StoreCredit
+	if refund.Method == order.RefundMethodTransfer {
+		transfer := min(toBalance, o.TransferableRefund(refunds))
+		if transfer > 0 {
+			withdrawal, err := s.walletService.CreatePayout(ctx, o.UserID, transfer, "", wallet.WithdrawalSourceRefund, refund.ID)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.orderRepo.SetRefundWithdrawal(ctx, refund.ID, withdrawal.ID); err != nil {
+				return nil, err
+			}
+			refund.WithdrawalID = withdrawal.ID
+		}
+		toBalance -= transfer
+	}
+	if toBalance > 0 {
+		if _, err := s.walletService.Credit(ctx, o.UserID, toBalance, wallet.EntryTypeRefund, wallet.RefTypeRefund, refund.ID, note); err != nil {
+			return nil, err
+		}
+	}
+	if refund.StoreCredit > 0 {
+		if _, err := s.walletService.CreditStoreCredit(ctx, o.UserID, refund.StoreCredit, wallet.EntryTypeRefund, wallet.RefTypeRefund, refund.ID, note); err != nil {
+			return nil, err
+		}
+	}
+
+	if order.RefundedTotal(refunds)+refund.Amount >= o.TotalPrice {
+		if _, err := s.transition(ctx, o.ID, actor, "全额退款", (*order.Order).MarkAsRefunded, s.cancelSubOrders); err != nil {
+			return nil, err
+		}
+	}
+	return refund, nil
+}
+
+// revokeDigital 吊销退款数量对应的激活码，数字商品订单行全部退款时撤销下载授权
+func (s *DefaultOrderService) revokeDigital(ctx context.Context, o *order.Order, byID map[uint]*order.OrderItem, remaining map[uint]order.Refundable, refund *order.Refund) error {
+	var items []digital.RevokeItem
+	for _, item := range refund.Items {
+		if byID[item.OrderItemID].ProductType != product.ProductTypeDigital {
+			continue
+		}
+		items = append(items, digital.RevokeItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Downloads: item.Quantity >= remaining[item.OrderItemID].Quantity,
+		})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return s.digitalService.Revoke(ctx, o.ID, items)
+}
+
+// voidGiftCards 作废退款数量对应的礼品卡，礼品卡已被兑换时整个退款回滚
+func (s *DefaultOrderService) voidGiftCards(ctx context.Context, o *order.Order, byID map[uint]*order.OrderItem, refund *order.Refund) error {
+	for _, item := range refund.Items {
+		if byID[item.OrderItemID].ProductType != product.ProductTypeDigital {
+			continue
+		}
+		if err := s.giftCardService.VoidForRefund(ctx, o.ID, item.ProductID, item.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyCredit 用礼品卡、购物金和站内余额抵扣订单的应付金额
+// 礼品卡只兑换抵扣所需的部分，兑换的金额先记入购物金再随订单扣减；使用余额时先用购物金，再用可以提现的余额
+func (s *DefaultOrderService) applyCredit(ctx context.Context, o *order.Order, input order.CreateOrderInput) error {
+	due := o.AmountDue()
+	if due <= 0 || (input.GiftCardCode == "" && !input.UseBalance) {
+		return nil
+	}
+
+	storeCredit, balance := common.Money(0), common.Money(0)
+	if input.GiftCardCode != "" {
+		redeemed, err := s.giftCardService.RedeemForOrder(ctx, o.UserID, input.GiftCardCode, o.ID, due)
+		if err != nil {
+			return err
+		}
+		storeCredit = redeemed
+	}
+	if input.UseBalance {
+		// 购物金已包含刚兑换的礼品卡金额
+		account, err := s.walletService.GetAccount(ctx, o.UserID)
+		if err != nil {
+			return err
+		}
+		storeCredit = min(account.StoreCredit, due)
+		balance = min(account.Balance, due-storeCredit)
+	}
+	if storeCredit+balance <= 0 {
+		return nil
+	}
+
+	o.BalancePaid = storeCredit + balance
+	o.StoreCreditPaid = storeCredit
+	if err := s.debitCredit(ctx, o, fmt.Sprintf("订单 %s 抵扣", o.OrderSN)); err != nil {
+		return err
+	}
+	return s.orderRepo.SetBalancePaid(ctx, o.ID, o.BalancePaid, o.StoreCreditPaid)
+}
+
+// returnCredit 订单关闭时退回抵扣的金额，购物金的部分退回购物金，礼品卡抵扣的部分同样退回购物金而不是礼品卡
+func (s *DefaultOrderService) returnCredit(ctx context.Context, o *order.Order) error {
+	note := fmt.Sprintf("订单 %s 关闭，退回抵扣金额", o.OrderSN)
+	if o.StoreCreditPaid > 0 {
+		if _, err := s.walletService.CreditStoreCredit(ctx, o.UserID, o.StoreCreditPaid, wallet.EntryTypeOrderPaymentReturn, wallet.RefTypeOrder, o.ID, note); err != nil {
+			return err
+		}
+	}
+	if balance := o.BalancePaid - o.StoreCreditPaid; balance > 0 {
+		if _, err := s.walletService.Credit(ctx, o.UserID, balance, wallet.EntryTypeOrderPaymentReturn, wallet.RefTypeOrder, o.ID, note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chargeCredit 重新激活已关闭的订单时再次扣减抵扣金额，购物金或余额不足时不能重新激活
+func (s *DefaultOrderService) chargeCredit(ctx context.Context, o *order.Order) error {
+	return s.debitCredit(ctx, o, fmt.Sprintf("订单 %s 重新激活，扣回抵扣金额", o.OrderSN))
+}
+
+// debitCredit 按订单记录的抵扣金额扣减购物金和站内余额
+func (s *DefaultOrderService) debitCredit(ctx context.Context, o *order.Order, note string) error {
+	if o.StoreCreditPaid > 0 {
+		if _, err := s.walletService.DebitStoreCredit(ctx, o.UserID, o.StoreCreditPaid, wallet.EntryTypeOrderPayment, wallet.RefTypeOrder, o.ID, note); err != nil {
+			return err
+		}
+	}
+	if balance := o.BalancePaid - o.StoreCreditPaid; balance > 0 {
+		if _, err := s.walletService.Debit(ctx, o.UserID, balance, wallet.EntryTypeOrderPayment, wallet.RefTypeOrder, o.ID, note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkMerchant 校验商家商品的商家仍在营业，并记录商家当前的佣金比例，平台自营商品不需要校验
+func (s *DefaultOrderService) checkMerchant(ctx context.Context, p *product.Product, rates map[uint]int) error {
+	if p.MerchantID == 0 {
+		return nil
+	}
+	if _, ok := rates[p.MerchantID]; ok {
+		return nil
+	}
+	m, err := s.merchantRepo.FindByID(ctx, p.MerchantID)
+	if err != nil {
+		return err
+	}
+	if !m.IsActive() {
+		return apierror.NewValidationError("商家已停止营业", fmt.Sprintf("商品 %s 暂时不能购买", p.Name))
+	}
+	rates[p.MerchantID] = m.CommissionRate
+	return nil
+}
+
+// settleSubOrders 订单完成时在同一事务中结算子订单，商家子订单扣除退款和佣金后的金额计入商家所有者的余额
+func (s *DefaultOrderService) settleSubOrders(ctx context.Context, o *order.Order) error {
+	subOrders, err := s.orderRepo.FindSubOrders(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	refunds, err := s.orderRepo.FindRefunds(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range subOrders {
+		subOrder := &subOrders[i]
+		if subOrder.Status != order.SubOrderStatusPending {
+			continue
+		}
+		subOrder.Settle(subOrder.Refunded(o, refunds), now)
+		if err := s.updateSubOrder(ctx, subOrder, order.SubOrderStatusPending); err != nil {
+			return err
+		}
+		if subOrder.MerchantID == 0 || subOrder.SettledAmount <= 0 {
+			continue
+		}
+
+		m, err := s.merchantRepo.FindByID(ctx, subOrder.MerchantID)
+		if err != nil {
+			return err
+		}
+		note := fmt.Sprintf("订单 %s 结算", o.OrderSN)
+		if _, err := s.walletService.Credit(ctx, m.OwnerID, subOrder.SettledAmount, wallet.EntryTypeSettlement, wallet.RefTypeSubOrder, subOrder.ID, note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseSettlements 已结算的订单发生退款时重新计算商家子订单的结算金额，并从商家余额冲回多结算的部分
+// 商家余额不足以冲回时退款失败，需要商家补足余额后重试
+func (s *DefaultOrderService) reverseSettlements(ctx context.Context, o *order.Order, refunds []order.Refund) error {
+	subOrders, err := s.orderRepo.FindSubOrders(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range subOrders {
+		subOrder := &subOrders[i]
+		if subOrder.Status != order.SubOrderStatusSettled || subOrder.MerchantID == 0 {
+			continue
+		}
+		reversal := subOrder.Resettle(subOrder.Refunded(o, refunds))
+		if reversal <= 0 {
+			continue
+		}
+		if err := s.updateSubOrder(ctx, subOrder, order.SubOrderStatusSettled); err != nil {
+			return err
+		}
+
+		m, err := s.merchantRepo.FindByID(ctx, subOrder.MerchantID)
+		if err != nil {
+			return err
+		}
+		note := fmt.Sprintf("订单 %s 退款冲回结算", o.OrderSN)
+		if _, err := s.walletService.Debit(ctx, m.OwnerID, reversal, wallet.EntryTypeSettlementReversal, wallet.RefTypeSubOrder, subOrder.ID, note); err != nil {
+			if hasErrorCode(err, apierror.ErrorCodeValidationFailed) {
+				return apierror.NewValidationError("商家余额不足以冲回结算款", fmt.Sprintf("商家 %s 需冲回 %s", m.Name, reversal))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelSubOrders 订单关闭或在结算前全额退款时取消未结算的子订单
+func (s *DefaultOrderService) cancelSubOrders(ctx context.Context, o *order.Order) error {
+	subOrders, err := s.orderRepo.FindSubOrders(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	for i := range subOrders {
+		subOrder := &subOrders[i]
+		if subOrder.Status != order.SubOrderStatusPending {
+			continue
+		}
+		subOrder.Status = order.SubOrderStatusCancelled
+		if err := s.updateSubOrder(ctx, subOrder, order.SubOrderStatusPending); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateSubOrder 以原状态为条件更新子订单
+func (s *DefaultOrderService) updateSubOrder(ctx context.Context, subOrder *order.SubOrder, from string) error {
+	ok, err := s.orderRepo.UpdateSubOrder(ctx, subOrder, from)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apierror.NewInvalidStateTransitionError("子订单状态已被修改，请刷新后重试", fmt.Sprintf("ID: %d", subOrder.ID))
+	}
+	return nil
+}
+
+// orderItemsByID 按订单行ID索引订单行
+func orderItemsByID(o *order.Order) map[uint]*order.OrderItem {
+	byID := make(map[uint]*order.OrderItem, len(o.Items))
+	for i := range o.Items {
+		byID[o.Items[i].ID] = &o.Items[i]
+	}
+	return byID
+}
+
+// returnsPhysicalItems 退货申请是否包含需要寄回的实物商品
+func returnsPhysicalItems(o *order.Order, request *order.ReturnRequest) bool {
+	byID := orderItemsByID(o)
+	for _, item := range request.Items {
+		if orderItem, ok := byID[item.OrderItemID]; ok && orderItem.ProductType != product.ProductTypeDigital {
+			return true
+		}
+	}
+	return false
+}
+
+// shipmentItems 根据未发货数量确定包裹中的商品，未指定商品时发出全部未发货的商品
+func shipmentItems(o *order.Order, remaining map[uint]int, inputs []order.CreateShipmentItemInput) ([]order.ShipmentItem, error) {
+	if len(remaining) == 0 {
+		return nil, apierror.NewValidationError("没有需要发货的商品", fmt.Sprintf("订单号: %s", o.OrderSN))
+	}
+
+	requested := make(map[uint]int, len(inputs))
+	for _, input := range inputs {
+		requested[input.OrderItemID] += input.Quantity
+	}
+	for id, quantity := range requested {
+		if quantity > remaining[id] {
+			return nil, apierror.NewValidationError("发货数量超过未发货数量", fmt.Sprintf("订单行 %d 未发货数量 %d", id, remaining[id]))
+		}
+	}
+
+	// 按订单行顺序生成包裹商品
+	items := make([]order.ShipmentItem, 0, len(remaining))
+	for _, item := range o.Items {
+		quantity := remaining[item.ID]
+		if len(inputs) > 0 {
+			quantity = requested[item.ID]
+		}
+		if quantity > 0 {
+			items = append(items, order.ShipmentItem{OrderItemID: item.ID, ProductID: item.ProductID, Quantity: quantity})
+		}
+	}
+	return items, nil
+}
+
+// hasErrorCode 判断是否为指定错误码的API错误
+func hasErrorCode(err error, code apierror.ErrorCode) bool {
+	apiErr, ok := err.(*apierror.APIError)
+	return ok && apiErr.Code == code
+}
+
+// applyEvaluation 将促销计算结果写入订单行和订单金额
+func applyEvaluation(o *order.Order, evaluation *promotion.Evaluation) {
+	discounts := make(map[uint]common.Money, len(evaluation.Lines))
+	for _, line := range evaluation.Lines {
+		discounts[line.ProductID] = line.Discount
+	}
+	for i := range o.Items {
+		o.Items[i].Discount = discounts[o.Items[i].ProductID]
+	}
+	for _, applied := range evaluation.Applied {
+		if applied.Code != "" {
+			o.PromotionCodes = append(o.PromotionCodes, applied.Code)
+		}
+	}
+
+	o.ShippingFee = evaluation.ShippingFee
+	o.CalculateTotal(evaluation.ShippingDiscount)
+}
+
+// mergeOrderLines 合并同一商品的多行，同一商品的单价必须一致
+func mergeOrderLines(items []order.CreateOrderItemInput) ([]order.CreateOrderItemInput, error) {
+	merged := make([]order.CreateOrderItemInput, 0, len(items))
+	index := make(map[uint]int, len(items))
+	for _, item := range items {
+		i, ok := index[item.ProductID]
+		if !ok {
+			index[item.ProductID] = len(merged)
+			merged = append(merged, item)
+			continue
+		}
+		if *merged[i].Price != *item.Price {
+			return nil, apierror.NewValidationError("同一商品的单价不一致", fmt.Sprintf("商品ID: %d", item.ProductID))
+		}
+		merged[i].Quantity += item.Quantity
+	}
+	return merged, nil
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/cart"
+	"web3-ecommerce-app/internal/domain/digital"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/promotion"
+	"web3-ecommerce-app/internal/domain/tax"
+	cartService "web3-ecommerce-app/internal/module/cart/service"
+	digitalService "web3-ecommerce-app/internal/module/digital/service"
+	productService "web3-ecommerce-app/internal/module/product/service"
+	promotionService "web3-ecommerce-app/internal/module/promotion/service"
+	"web3-ecommerce-app/internal/platform/eventbus"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// memOrderRepo 内存订单仓库，只实现测试流程用到的方法
+type memOrderRepo struct {
+	order.OrderRepository
+	orders       map[uint]*order.Order
+	timeline     []order.TimelineEvent
+	latePayments []order.LatePayment
+}
+
+func newMemOrderRepo(orders ...*order.Order) *memOrderRepo {
+	r := &memOrderRepo{orders: make(map[uint]*order.Order)}
+	for _, o := range orders {
+		r.orders[o.ID] = o
+	}
+	return r
+}
+
+func (r *memOrderRepo) FindByID(ctx context.Context, id uint) (*order.Order, error) {
+	o, ok := r.orders[id]
+	if !ok {
+		return nil, apierror.NewNotFoundError("订单不存在", "")
+	}
+	copied := *o
+	return &copied, nil
+}
+
+func (r *memOrderRepo) Create(ctx context.Context, o *order.Order) error {
+	o.ID = uint(len(r.orders) + 1)
+	copied := *o
+	r.orders[o.ID] = &copied
+	return nil
+}
+
+func (r *memOrderRepo) CreateSubOrders(ctx context.Context, subOrders []order.SubOrder) error {
+	return nil
+}
+
+func (r *memOrderRepo) UpdateStatus(ctx context.Context, o *order.Order, from string) (bool, error) {
+	if r.orders[o.ID].Status != from {
+		return false, nil
+	}
+	copied := *o
+	r.orders[o.ID] = &copied
+	return true, nil
+}
+
+func (r *memOrderRepo) AppendTimeline(ctx context.Context, event *order.TimelineEvent) error {
+	r.timeline = append(r.timeline, *event)
+	return nil
+}
+
+func (r *memOrderRepo) CreateLatePayment(ctx context.Context, payment *order.LatePayment) error {
+	r.latePayments = append(r.latePayments, *payment)
+	return nil
+}
+
+func (r *memOrderRepo) FindSubOrders(ctx context.Context, orderID uint) ([]order.SubOrder, error) {
+	return nil, nil
+}
+
+func (r *memOrderRepo) FindRefunds(ctx context.Context, orderID uint) ([]order.Refund, error) {
+	return nil, nil
+}
+
+func (r *memOrderRepo) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// memInventoryRepo 没有预占记录的库存仓库
+type memInventoryRepo struct {
+	product.InventoryRepository
+}
+
+func (r *memInventoryRepo) FindByOrder(ctx context.Context, orderID uint) ([]product.Reservation, error) {
+	return nil, nil
+}
+
+func (r *memInventoryRepo) ConfirmByOrder(ctx context.Context, orderID uint) error {
+	return nil
+}
+
+func (r *memInventoryRepo) Reserve(ctx context.Context, orderID uint, items []product.ReservationItem, expiresAt time.Time) ([]product.Reservation, error) {
+	return nil, nil
+}
+
+func (r *memInventoryRepo) ReleaseByOrder(ctx context.Context, orderID uint) error {
+	return nil
+}
+
+// stubPromotionService 按给定的促销计算优惠
+type stubPromotionService struct {
+	promotionService.PromotionService
+	promotions []promotion.Promotion
+}
+
+func (s *stubPromotionService) Evaluate(ctx context.Context, basket promotion.Basket) (*promotion.Evaluation, error) {
+	return promotion.Evaluate(basket, s.promotions, nil), nil
+}
+
+func (s *stubPromotionService) Redeem(ctx context.Context, orderID uint, userID uint, evaluation *promotion.Evaluation) error {
+	return nil
+}
+
+func (s *stubPromotionService) Release(ctx context.Context, orderID uint) error {
+	return nil
+}
+
+type stubProductService struct {
+	productService.ProductService
+}
+
+func (s *stubProductService) SyncStockStatus(ctx context.Context, ids ...uint) error {
+	return nil
+}
+
+type memProductRepo struct {
+	product.ProductRepository
+	products []product.Product
+}
+
+func (r *memProductRepo) FindByIDs(ctx context.Context, ids []uint) ([]product.Product, error) {
+	return r.products, nil
+}
+
+type stubCartService struct {
+	cartService.CartService
+}
+
+func (s *stubCartService) RemoveProducts(ctx context.Context, owner cart.Owner, productIDs []uint) error {
+	return nil
+}
+
+type stubDigitalService struct {
+	digitalService.DigitalService
+	delivered []uint
+}
+
+func (s *stubDigitalService) Deliver(ctx context.Context, orderID uint, userID uint, items []digital.DeliveryItem) error {
+	s.delivered = append(s.delivered, orderID)
+	return nil
+}
+
+// noTax 不计税
+type noTax struct{}
+
+func (noTax) Calculate(ctx context.Context, req tax.Request) (*tax.Result, error) {
+	return &tax.Result{Lines: []tax.LineTax{}}, nil
+}
+
+type fixedSN string
+
+func (sn fixedSN) Next(ctx context.Context, now time.Time) (string, error) {
+	return string(sn), nil
+}
+
+// stubGateService 按商品返回固定的门槛验证结果
+type stubGateService struct {
+	productService.GateService
+	result *product.GateResult
+	err    error
+}
+
+func (s *stubGateService) CheckPurchase(ctx context.Context, userID uint, productID uint, live bool) (*product.GateResult, error) {
+	return s.result, s.err
+}
+
+func TestMarkOrderAsPaidRechecksHolderPrice(t *testing.T) {
+	// 下单时以持有者价格800购买，标价1000
+	pendingOrder := func() *order.Order {
+		return &order.Order{
+			ID:         1,
+			UserID:     7,
+			OrderSN:    "SN001",
+			Status:     order.OrderStatusPendingPayment,
+			TotalPrice: 800,
+			Items: []order.OrderItem{{
+				ProductID:   3,
+				ProductType: product.ProductTypePhysical,
+				Price:       800,
+				Quantity:    1,
+			}},
+		}
+	}
+	lostHolder := &product.GateResult{Allowed: true, Price: 1000, Rules: []product.GateRuleCheck{{
+		GateRule: product.GateRule{Effect: product.GateEffectPrice, HolderPrice: 800},
+	}}}
+
+	tests := []struct {
+		name       string
+		gate       *stubGateService
+		payment    order.Payment
+		wantStatus string
+		wantCode   apierror.ErrorCode
+		wantLate   int
+	}{
+		{
+			name:       "仅修改状态且代币已转走",
+			gate:       &stubGateService{result: lostHolder},
+			payment:    order.Payment{Note: "线下收款"},
+			wantStatus: order.OrderStatusPendingPayment,
+			wantCode:   apierror.ErrorCodeForbidden,
+		},
+		{
+			name:       "链上已付款但代币已转走",
+			gate:       &stubGateService{result: lostHolder},
+			payment:    order.Payment{TxHash: "0xabc"},
+			wantStatus: order.OrderStatusCancelled,
+			wantCode:   apierror.ErrorCodeInvalidTransition,
+			wantLate:   1,
+		},
+		{
+			name:       "链上节点不可用",
+			gate:       &stubGateService{err: errors.New("dial tcp: connection refused")},
+			payment:    order.Payment{TxHash: "0xabc"},
+			wantStatus: order.OrderStatusPaid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemOrderRepo(pendingOrder())
+			svc := &DefaultOrderService{
+				orderRepo:        repo,
+				inventoryRepo:    &memInventoryRepo{},
+				productService:   &stubProductService{},
+				gateService:      tt.gate,
+				promotionService: &stubPromotionService{},
+				bus:              eventbus.New(),
+			}
+
+			_, err := svc.MarkOrderAsPaid(context.Background(), 1, order.Actor{Type: order.ActorAdmin, ID: 1}, tt.payment)
+			if tt.wantCode == "" && err != nil {
+				t.Fatalf("MarkOrderAsPaid: %v", err)
+			}
+			if tt.wantCode != "" && !hasErrorCode(err, tt.wantCode) {
+				t.Fatalf("error = %v, want code %s", err, tt.wantCode)
+			}
+			if got := repo.orders[1].Status; got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+			if len(repo.latePayments) != tt.wantLate {
+				t.Errorf("late payments = %d, want %d", len(repo.latePayments), tt.wantLate)
+			}
+		})
+	}
+}
+
+func TestCreateOrderWithZeroTotalIsPaid(t *testing.T) {
+	ebook := product.Product{
+		ID:     3,
+		Name:   "电子书",
+		Type:   product.ProductTypeDigital,
+		Status: product.ProductStatusPublished,
+		Price:  1000,
+		Stock:  10,
+	}
+	free := ebook
+	free.ID, free.Price = 4, 0
+	fullDiscount := promotion.Promotion{ID: 1, Name: "全额折扣", Type: promotion.PromotionTypePercentage, Percent: 100, Active: true}
+
+	tests := []struct {
+		name       string
+		product    product.Product
+		promotions []promotion.Promotion
+		wantStatus string
+	}{
+		{name: "100%优惠", product: ebook, promotions: []promotion.Promotion{fullDiscount}, wantStatus: order.OrderStatusCompleted},
+		{name: "0元商品", product: free, wantStatus: order.OrderStatusCompleted},
+		{name: "需要支付", product: ebook, wantStatus: order.OrderStatusPendingPayment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemOrderRepo()
+			digitalSvc := &stubDigitalService{}
+			svc := &DefaultOrderService{
+				orderRepo:        repo,
+				productRepo:      &memProductRepo{products: []product.Product{tt.product}},
+				inventoryRepo:    &memInventoryRepo{},
+				productService:   &stubProductService{},
+				gateService:      &stubGateService{result: &product.GateResult{Allowed: true, Price: tt.product.Price}},
+				promotionService: &stubPromotionService{promotions: tt.promotions},
+				digitalService:   digitalSvc,
+				cartService:      &stubCartService{},
+				taxCalculator:    noTax{},
+				snGenerator:      fixedSN("SN001"),
+				bus:              eventbus.New(),
+				paymentConfig:    &config.PaymentConfig{PaymentWindow: 30 * time.Minute},
+			}
+
+			price := tt.product.Price
+			created, err := svc.CreateOrder(context.Background(), 7, order.CreateOrderInput{
+				Items: []order.CreateOrderItemInput{{ProductID: tt.product.ID, Quantity: 1, Price: &price}},
+			})
+			if err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+			if got := repo.orders[created.ID].Status; got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+			wantDelivered := tt.wantStatus != order.OrderStatusPendingPayment
+			if delivered := len(digitalSvc.delivered) > 0; delivered != wantDelivered {
+				t.Errorf("delivered = %v, want %v", delivered, wantDelivered)
+			}
+		})
+	}
+}
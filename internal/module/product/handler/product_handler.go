@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/module/product/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProductHTTPHandler 商品HTTP处理器
+type ProductHTTPHandler struct {
+	productService service.ProductService
+	gateService    service.GateService
+	priceService   service.PriceService
+}
+
+// NewProductHTTPHandler 创建商品HTTP处理器
+func NewProductHTTPHandler(productService service.ProductService, gateService service.GateService, priceService service.PriceService) *ProductHTTPHandler {
+	return &ProductHTTPHandler{
+		productService: productService,
+		gateService:    gateService,
+		priceService:   priceService,
+	}
+}
+
+// ListProducts 获取对外可见的商品列表
+func (h *ProductHTTPHandler) ListProducts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	categoryID, _ := strconv.ParseUint(c.DefaultQuery("category_id", "0"), 10, 32)
+
+	result, err := h.productService.ListProducts(c.Request.Context(), product.ProductQuery{
+		Page:       page,
+		PageSize:   pageSize,
+		CategoryID: uint(categoryID),
+		Statuses:   product.VisibleProductStatuses,
+		Search:     c.DefaultQuery("search", ""),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetProduct 获取对外可见的商品详情
+func (h *ProductHTTPHandler) GetProduct(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的商品ID", err.Error()),
+		})
+		return
+	}
+
+	productEntity, err := h.productService.GetProductByID(c.Request.Context(), uint(id))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	// 未上架的商品对外不可见，缺货商品仍然展示
+	if !productEntity.IsVisible() {
+		h.handleError(c, apierror.NewNotFoundError("商品不存在", idStr))
+		return
+	}
+
+	c.JSON(http.StatusOK, productEntity)
+}
+
+// GetLowestPrice 获取商品当前售价生效前days天(默认30天)内的最低售价，用于展示降价前的参考价格
+func (h *ProductHTTPHandler) GetLowestPrice(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的商品ID", err.Error()),
+		})
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(product.LowestPriceDays)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的天数", err.Error()),
+		})
+		return
+	}
+
+	productEntity, err := h.productService.GetProductByID(c.Request.Context(), uint(id))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if !productEntity.IsVisible() {
+		h.handleError(c, apierror.NewNotFoundError("商品不存在", idStr))
+		return
+	}
+
+	result, err := h.priceService.GetLowestPrice(c.Request.Context(), uint(id), days)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CheckGate 验证当前用户绑定的钱包是否满足商品的持币门槛，并返回适用的价格
+func (h *ProductHTTPHandler) CheckGate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的商品ID", err.Error()),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+		})
+		return
+	}
+
+	productEntity, err := h.productService.GetProductByID(c.Request.Context(), uint(id))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if !productEntity.IsVisible() {
+		h.handleError(c, apierror.NewNotFoundError("商品不存在", idStr))
+		return
+	}
+
+	result, err := h.gateService.Evaluate(c.Request.Context(), userID.(uint), uint(id))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// handleError 处理错误
+func (h *ProductHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
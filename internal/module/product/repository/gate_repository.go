@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+)
+
+// GateRuleModel 是GORM持币门槛规则模型
+type GateRuleModel struct {
+	gorm.Model
+	ProductID   uint   `gorm:"not null;index:idx_product_id"`
+	Effect      string `gorm:"type:varchar(20);not null"`
+	ChainID     int64  `gorm:"not null"`
+	Standard    string `gorm:"type:varchar(20);not null"`
+	Contract    string `gorm:"type:varchar(42);not null"`
+	TokenIDMin  string `gorm:"type:varchar(78)"` // uint256的十进制表示最多78位
+	TokenIDMax  string `gorm:"type:varchar(78)"`
+	MinBalance  string `gorm:"type:varchar(78);not null"`
+	HolderPrice int64  `gorm:"not null;default:0"`
+}
+
+// TableName 指定表名
+func (GateRuleModel) TableName() string {
+	return "product_gate_rules"
+}
+
+// GormGateRuleRepository 是持币门槛规则仓库的GORM实现
+type GormGateRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewGormGateRuleRepository 创建一个新的GORM持币门槛规则仓库
+func NewGormGateRuleRepository(db *gorm.DB) product.GateRuleRepository {
+	return &GormGateRuleRepository{db: db}
+}
+
+// gateRuleToDomain 将GORM模型转换为领域模型
+func gateRuleToDomain(m *GateRuleModel) *product.GateRule {
+	return &product.GateRule{
+		ID:          m.ID,
+		ProductID:   m.ProductID,
+		Effect:      m.Effect,
+		ChainID:     m.ChainID,
+		Standard:    m.Standard,
+		Contract:    m.Contract,
+		TokenIDMin:  m.TokenIDMin,
+		TokenIDMax:  m.TokenIDMax,
+		MinBalance:  m.MinBalance,
+		HolderPrice: common.Money(m.HolderPrice),
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// FindByID 根据ID查找规则
+func (r *GormGateRuleRepository) FindByID(ctx context.Context, id uint) (*product.GateRule, error) {
+	var model GateRuleModel
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("持币规则不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询持币规则错误: %w", err)
+	}
+	return gateRuleToDomain(&model), nil
+}
+
+// FindByProduct 查询商品的全部规则
+func (r *GormGateRuleRepository) FindByProduct(ctx context.Context, productID uint) ([]product.GateRule, error) {
+	var models []GateRuleModel
+	if err := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询持币规则错误: %w", err)
+	}
+
+	rules := make([]product.GateRule, 0, len(models))
+	for i := range models {
+		rules = append(rules, *gateRuleToDomain(&models[i]))
+	}
+	return rules, nil
+}
+
+// Create 创建规则
+func (r *GormGateRuleRepository) Create(ctx context.Context, rule *product.GateRule) error {
+	model := &GateRuleModel{
+		ProductID:   rule.ProductID,
+		Effect:      rule.Effect,
+		ChainID:     rule.ChainID,
+		Standard:    rule.Standard,
+		Contract:    rule.Contract,
+		TokenIDMin:  rule.TokenIDMin,
+		TokenIDMax:  rule.TokenIDMax,
+		MinBalance:  rule.MinBalance,
+		HolderPrice: int64(rule.HolderPrice),
+	}
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("创建持币规则错误: %w", err)
+	}
+
+	// 更新领域模型
+	rule.ID = model.ID
+	rule.CreatedAt = model.CreatedAt
+
+	return nil
+}
+
+// Delete 删除规则
+func (r *GormGateRuleRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&GateRuleModel{}, id).Error; err != nil {
+		return fmt.Errorf("删除持币规则错误: %w", err)
+	}
+	return nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormGateRuleRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&GateRuleModel{})
+}
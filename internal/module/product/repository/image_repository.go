@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+)
+
+// ImageModel 是GORM商品图片模型
+type ImageModel struct {
+	gorm.Model
+	ProductID   uint             `gorm:"not null;index:idx_product_id"`
+	Key         string           `gorm:"type:varchar(255);not null"`
+	URL         string           `gorm:"type:varchar(500);not null"`
+	ContentType string           `gorm:"type:varchar(50);not null"`
+	Width       int              `gorm:"not null"`
+	Height      int              `gorm:"not null"`
+	Size        int64            `gorm:"not null"`
+	SortOrder   int              `gorm:"not null;default:0"`
+	IsPrimary   bool             `gorm:"not null;default:false"`
+	Thumbnails  []ThumbnailModel `gorm:"type:text;serializer:json"`
+}
+
+// ThumbnailModel 缩略图信息，以JSON形式存储在图片记录中
+type ThumbnailModel struct {
+	Key    string `json:"key"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// TableName 指定表名
+func (ImageModel) TableName() string {
+	return "product_images"
+}
+
+// GormImageRepository 是商品图片仓库的GORM实现
+type GormImageRepository struct {
+	db *gorm.DB
+}
+
+// NewGormImageRepository 创建一个新的GORM商品图片仓库
+func NewGormImageRepository(db *gorm.DB) product.ImageRepository {
+	return &GormImageRepository{db: db}
+}
+
+// imageToModel 将领域模型转换为GORM模型
+func imageToModel(i *product.Image) *ImageModel {
+	thumbnails := make([]ThumbnailModel, 0, len(i.Thumbnails))
+	for _, t := range i.Thumbnails {
+		thumbnails = append(thumbnails, ThumbnailModel{Key: t.Key, URL: t.URL, Width: t.Width, Height: t.Height})
+	}
+	return &ImageModel{
+		Model: gorm.Model{
+			ID:        i.ID,
+			CreatedAt: i.CreatedAt,
+		},
+		ProductID:   i.ProductID,
+		Key:         i.Key,
+		URL:         i.URL,
+		ContentType: i.ContentType,
+		Width:       i.Width,
+		Height:      i.Height,
+		Size:        i.Size,
+		SortOrder:   i.SortOrder,
+		IsPrimary:   i.IsPrimary,
+		Thumbnails:  thumbnails,
+	}
+}
+
+// imageToDomain 将GORM模型转换为领域模型
+func imageToDomain(m *ImageModel) *product.Image {
+	thumbnails := make([]product.Thumbnail, 0, len(m.Thumbnails))
+	for _, t := range m.Thumbnails {
+		thumbnails = append(thumbnails, product.Thumbnail{Key: t.Key, URL: t.URL, Width: t.Width, Height: t.Height})
+	}
+	return &product.Image{
+		ID:          m.ID,
+		ProductID:   m.ProductID,
+		Key:         m.Key,
+		URL:         m.URL,
+		ContentType: m.ContentType,
+		Width:       m.Width,
+		Height:      m.Height,
+		Size:        m.Size,
+		SortOrder:   m.SortOrder,
+		IsPrimary:   m.IsPrimary,
+		Thumbnails:  thumbnails,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// orderImages 图片的默认排序
+func orderImages(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order, id")
+}
+
+// FindByID 根据ID查找图片
+func (r *GormImageRepository) FindByID(ctx context.Context, id uint) (*product.Image, error) {
+	var model ImageModel
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("图片不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询图片错误: %w", err)
+	}
+	return imageToDomain(&model), nil
+}
+
+// FindByProduct 查询商品的全部图片
+func (r *GormImageRepository) FindByProduct(ctx context.Context, productID uint) ([]product.Image, error) {
+	var models []ImageModel
+	if err := r.db.WithContext(ctx).Scopes(orderImages).Where("product_id = ?", productID).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询图片错误: %w", err)
+	}
+
+	images := make([]product.Image, 0, len(models))
+	for i := range models {
+		images = append(images, *imageToDomain(&models[i]))
+	}
+	return images, nil
+}
+
+// Create 创建图片
+func (r *GormImageRepository) Create(ctx context.Context, i *product.Image) error {
+	model := imageToModel(i)
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("创建图片错误: %w", err)
+	}
+
+	// 更新领域模型
+	i.ID = model.ID
+	i.CreatedAt = model.CreatedAt
+
+	return nil
+}
+
+// Delete 删除图片
+func (r *GormImageRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&ImageModel{}, id).Error; err != nil {
+		return fmt.Errorf("删除图片错误: %w", err)
+	}
+	return nil
+}
+
+// Reorder 按给定的图片ID顺序重新排序
+func (r *GormImageRepository) Reorder(ctx context.Context, productID uint, imageIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&ImageModel{}).Where("product_id = ? AND id IN ?", productID, imageIDs).Count(&count).Error; err != nil {
+			return fmt.Errorf("查询图片错误: %w", err)
+		}
+		if int(count) != len(imageIDs) {
+			return apierror.NewValidationError("图片排序失败", "图片ID重复或不属于该商品")
+		}
+
+		for i, id := range imageIDs {
+			if err := tx.Model(&ImageModel{}).Where("id = ?", id).Update("sort_order", i).Error; err != nil {
+				return fmt.Errorf("更新图片排序错误: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// SetPrimary 设置商品主图
+func (r *GormImageRepository) SetPrimary(ctx context.Context, productID uint, imageID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&ImageModel{}).Where("product_id = ? AND is_primary = ?", productID, true).
+			Update("is_primary", false).Error; err != nil {
+			return fmt.Errorf("更新主图错误: %w", err)
+		}
+
+		result := tx.Model(&ImageModel{}).Where("id = ? AND product_id = ?", imageID, productID).Update("is_primary", true)
+		if result.Error != nil {
+			return fmt.Errorf("更新主图错误: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apierror.NewNotFoundError("图片不存在", fmt.Sprintf("商品ID: %d, 图片ID: %d", productID, imageID))
+		}
+		return nil
+	})
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormImageRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&ImageModel{})
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+)
+
+// ReservationModel 是GORM库存预占模型
+type ReservationModel struct {
+	gorm.Model
+	OrderID   uint      `gorm:"not null;index:idx_order_id"`
+	ProductID uint      `gorm:"not null;index:idx_product_id"`
+	Quantity  int       `gorm:"not null"`
+	Status    string    `gorm:"type:varchar(20);not null;index:idx_status_expires_at,priority:1"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_status_expires_at,priority:2"`
+}
+
+// TableName 指定表名
+func (ReservationModel) TableName() string {
+	return "inventory_reservations"
+}
+
+// GormInventoryRepository 是库存预占仓库的GORM实现
+type GormInventoryRepository struct {
+	db *gorm.DB
+}
+
+// NewGormInventoryRepository 创建一个新的GORM库存预占仓库
+func NewGormInventoryRepository(db *gorm.DB) product.InventoryRepository {
+	return &GormInventoryRepository{db: db}
+}
+
+// reservationToDomain 将GORM模型转换为领域模型
+func reservationToDomain(m *ReservationModel) *product.Reservation {
+	return &product.Reservation{
+		ID:        m.ID,
+		OrderID:   m.OrderID,
+		ProductID: m.ProductID,
+		Quantity:  m.Quantity,
+		Status:    m.Status,
+		ExpiresAt: m.ExpiresAt,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// mergeReservationItems 合并同一商品的数量并按商品ID排序
+// 固定的加锁顺序可以避免并发事务之间的死锁
+func mergeReservationItems(items []product.ReservationItem) []product.ReservationItem {
+	quantities := make(map[uint]int, len(items))
+	for _, item := range items {
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	merged := make([]product.ReservationItem, 0, len(quantities))
+	for productID, quantity := range quantities {
+		merged = append(merged, product.ReservationItem{ProductID: productID, Quantity: quantity})
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].ProductID < merged[j].ProductID
+	})
+	return merged
+}
+
+// Reserve 为订单预占库存
+// 通过 UPDATE ... WHERE stock - reserved >= ? 的条件更新保证并发下不会超卖；
+// ctx携带事务时与订单写入在同一个事务中提交
+func (r *GormInventoryRepository) Reserve(ctx context.Context, orderID uint, items []product.ReservationItem, expiresAt time.Time) ([]product.Reservation, error) {
+	merged := mergeReservationItems(items)
+	reservations := make([]product.Reservation, 0, len(merged))
+
+	err := database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, item := range merged {
+			if item.Quantity <= 0 {
+				return apierror.NewValidationError("预占数量无效", fmt.Sprintf("商品ID: %d, 数量: %d", item.ProductID, item.Quantity))
+			}
+
+			result := tx.Model(&ProductModel{}).
+				Where("id = ? AND stock - reserved >= ?", item.ProductID, item.Quantity).
+				UpdateColumn("reserved", gorm.Expr("reserved + ?", item.Quantity))
+			if result.Error != nil {
+				return fmt.Errorf("预占库存错误: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				if err := tx.First(&ProductModel{}, item.ProductID).Error; err == gorm.ErrRecordNotFound {
+					return apierror.NewNotFoundError("商品不存在", fmt.Sprintf("ID: %d", item.ProductID))
+				}
+				return apierror.NewInsufficientStockError("库存不足", fmt.Sprintf("商品ID: %d, 需要数量: %d", item.ProductID, item.Quantity))
+			}
+
+			model := ReservationModel{
+				OrderID:   orderID,
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+				Status:    product.ReservationStatusActive,
+				ExpiresAt: expiresAt,
+			}
+			if err := tx.Create(&model).Error; err != nil {
+				return fmt.Errorf("创建库存预占错误: %w", err)
+			}
+			reservations = append(reservations, *reservationToDomain(&model))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// ConfirmByOrder 将订单的有效预占转为实际库存扣减
+func (r *GormInventoryRepository) ConfirmByOrder(ctx context.Context, orderID uint) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var models []ReservationModel
+		if err := tx.Where("order_id = ? AND status = ?", orderID, product.ReservationStatusActive).
+			Order("product_id").Find(&models).Error; err != nil {
+			return fmt.Errorf("查询库存预占错误: %w", err)
+		}
+
+		for i := range models {
+			if err := transitionReservation(tx, &models[i], product.ReservationStatusConfirmed); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReleaseByOrder 释放订单的有效预占
+func (r *GormInventoryRepository) ReleaseByOrder(ctx context.Context, orderID uint) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var models []ReservationModel
+		if err := tx.Where("order_id = ? AND status = ?", orderID, product.ReservationStatusActive).
+			Order("product_id").Find(&models).Error; err != nil {
+			return fmt.Errorf("查询库存预占错误: %w", err)
+		}
+
+		for i := range models {
+			if err := transitionReservation(tx, &models[i], product.ReservationStatusReleased); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReleaseExpired 释放过期的预占
+// 每条预占在独立事务中释放，单条失败不影响其它记录
+func (r *GormInventoryRepository) ReleaseExpired(ctx context.Context, now time.Time, limit int) ([]product.Reservation, error) {
+	var models []ReservationModel
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", product.ReservationStatusActive, now).
+		Order("expires_at").Limit(limit).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询过期库存预占错误: %w", err)
+	}
+
+	released := make([]product.Reservation, 0, len(models))
+	for i := range models {
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return transitionReservation(tx, &models[i], product.ReservationStatusReleased)
+		})
+		if err != nil {
+			return released, err
+		}
+		released = append(released, *reservationToDomain(&models[i]))
+	}
+	return released, nil
+}
+
+// FindByOrder 查询订单的全部预占记录
+func (r *GormInventoryRepository) FindByOrder(ctx context.Context, orderID uint) ([]product.Reservation, error) {
+	var models []ReservationModel
+	if err := database.Conn(ctx, r.db).Where("order_id = ?", orderID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询库存预占错误: %w", err)
+	}
+
+	reservations := make([]product.Reservation, 0, len(models))
+	for i := range models {
+		reservations = append(reservations, *reservationToDomain(&models[i]))
+	}
+	return reservations, nil
+}
+
+// transitionReservation 将一条有效预占转为确认或释放状态，并同步商品库存
+// 预占状态的条件更新保证同一条预占只会被处理一次
+func transitionReservation(tx *gorm.DB, m *ReservationModel, status string) error {
+	result := tx.Model(&ReservationModel{}).
+		Where("id = ? AND status = ?", m.ID, product.ReservationStatusActive).
+		Update("status", status)
+	if result.Error != nil {
+		return fmt.Errorf("更新库存预占状态错误: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// 已被其它流程处理
+		return nil
+	}
+
+	columns := map[string]interface{}{
+		"reserved": gorm.Expr("reserved - ?", m.Quantity),
+	}
+	if status == product.ReservationStatusConfirmed {
+		columns["stock"] = gorm.Expr("stock - ?", m.Quantity)
+	}
+	if err := tx.Model(&ProductModel{}).Unscoped().Where("id = ?", m.ProductID).UpdateColumns(columns).Error; err != nil {
+		return fmt.Errorf("更新商品库存错误: %w", err)
+	}
+
+	m.Status = status
+	return nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormInventoryRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&ReservationModel{})
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// inventoryTestMySQLDSN 指向专用的MySQL测试库时，库存测试在MySQL上运行，
+// 例如 root:root@tcp(127.0.0.1:3306)/shop_test?parseTime=True；测试会重建商品和预占表
+const inventoryTestMySQLDSN = "INVENTORY_TEST_MYSQL_DSN"
+
+// newInventoryTestDB 创建库存测试库，未配置MySQL时使用SQLite
+// SQLite同一时间只允许一个写事务，并发测试只在MySQL上才能覆盖行锁和事务隔离；
+// 商品模型的FULLTEXT索引是MySQL专有语法，因此商品表只建预占用到的列
+func newInventoryTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	config := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	idColumn := "id INTEGER PRIMARY KEY AUTOINCREMENT"
+
+	var dialector gorm.Dialector
+	if dsn := os.Getenv(inventoryTestMySQLDSN); dsn != "" {
+		dialector = mysql.Open(dsn)
+		idColumn = "id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY"
+	} else {
+		dialector = sqlite.Open(filepath.Join(t.TempDir(), "inventory.db") + "?_busy_timeout=10000&_journal_mode=WAL")
+	}
+	db, err := gorm.Open(dialector, config)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	for _, table := range []string{"inventory_reservations", "products"} {
+		if err := db.Exec("DROP TABLE IF EXISTS " + table).Error; err != nil {
+			t.Fatalf("drop %s: %v", table, err)
+		}
+	}
+	if err := db.Exec(`CREATE TABLE products (
+		` + idColumn + `,
+		created_at DATETIME,
+		updated_at DATETIME,
+		deleted_at DATETIME,
+		sku VARCHAR(64) NOT NULL,
+		name VARCHAR(200) NOT NULL,
+		stock INTEGER NOT NULL DEFAULT 0,
+		reserved INTEGER NOT NULL DEFAULT 0
+	)`).Error; err != nil {
+		t.Fatalf("create products: %v", err)
+	}
+	if err := NewGormInventoryRepository(db).(*GormInventoryRepository).AutoMigrate(); err != nil {
+		t.Fatalf("migrate reservations: %v", err)
+	}
+	return db
+}
+
+// requireMySQL 跳过需要真实并发写入的测试
+func requireMySQL(t *testing.T) {
+	t.Helper()
+	if os.Getenv(inventoryTestMySQLDSN) == "" {
+		t.Skipf("SQLite串行化写事务，设置 %s 后在MySQL上运行", inventoryTestMySQLDSN)
+	}
+}
+
+func createStressProduct(t *testing.T, db *gorm.DB, sku string, stock int) uint {
+	t.Helper()
+	if err := db.Exec("INSERT INTO products (sku, name, stock, reserved) VALUES (?, ?, ?, 0)", sku, sku, stock).Error; err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	var id uint
+	db.Raw("SELECT id FROM products WHERE sku = ?", sku).Scan(&id)
+	return id
+}
+
+func productStock(t *testing.T, db *gorm.DB, id uint) (stock int, reserved int) {
+	t.Helper()
+	row := db.Raw("SELECT stock, reserved FROM products WHERE id = ?", id).Row()
+	if err := row.Scan(&stock, &reserved); err != nil {
+		t.Fatalf("load product: %v", err)
+	}
+	return stock, reserved
+}
+
+// hammerReserve 让workers个订单同时各预占一件商品，返回成功和库存不足的订单数
+func hammerReserve(t *testing.T, workers int, reserve func(orderID uint) error) (succeeded, rejected int64) {
+	t.Helper()
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(orderID uint) {
+			defer wg.Done()
+			<-start
+			err := reserve(orderID)
+
+			var apiErr *apierror.APIError
+			switch {
+			case err == nil:
+				atomic.AddInt64(&succeeded, 1)
+			case errors.As(err, &apiErr) && apiErr.Code == apierror.ErrorCodeInsufficientStock:
+				atomic.AddInt64(&rejected, 1)
+			default:
+				t.Errorf("order %d: unexpected error: %v", orderID, err)
+			}
+		}(uint(i + 1))
+	}
+	close(start)
+	wg.Wait()
+	return succeeded, rejected
+}
+
+func TestInventoryReserveConcurrentNoOversell(t *testing.T) {
+	db := newInventoryTestDB(t)
+	repo := NewGormInventoryRepository(db)
+	ctx := context.Background()
+
+	const stock, workers = 50, 200
+	productID := createStressProduct(t, db, "STRESS", stock)
+
+	expiresAt := time.Now().Add(time.Hour)
+	succeeded, rejected := hammerReserve(t, workers, func(orderID uint) error {
+		_, err := repo.Reserve(ctx, orderID, []product.ReservationItem{{ProductID: productID, Quantity: 1}}, expiresAt)
+		return err
+	})
+
+	if succeeded != stock || rejected != workers-stock {
+		t.Fatalf("succeeded = %d, rejected = %d, want %d and %d", succeeded, rejected, stock, workers-stock)
+	}
+	if _, reserved := productStock(t, db, productID); reserved != stock {
+		t.Fatalf("reserved = %d, want %d", reserved, stock)
+	}
+	var count int64
+	db.Model(&ReservationModel{}).Where("product_id = ?", productID).Count(&count)
+	if count != stock {
+		t.Fatalf("reservation rows = %d, want %d", count, stock)
+	}
+}
+
+// TestInventoryHammerDetectsUnguardedReserve 确认并发测试的断言能发现超卖：
+// 先查询可用库存、再不带 stock - reserved >= ? 条件增加预占的写法必须超卖
+func TestInventoryHammerDetectsUnguardedReserve(t *testing.T) {
+	db := newInventoryTestDB(t)
+	ctx := context.Background()
+
+	const stock, workers = 50, 200
+	productID := createStressProduct(t, db, "UNGUARDED", stock)
+
+	// 所有请求读到可用库存后才写入，固定成并发下最坏的交错，避免结果依赖调度
+	var read sync.WaitGroup
+	read.Add(workers)
+	succeeded, _ := hammerReserve(t, workers, func(orderID uint) error {
+		var available int
+		err := db.WithContext(ctx).Raw("SELECT stock - reserved FROM products WHERE id = ?", productID).Scan(&available).Error
+		read.Done()
+		read.Wait()
+		if err != nil {
+			return err
+		}
+		if available < 1 {
+			return apierror.NewInsufficientStockError("库存不足", "")
+		}
+		return db.WithContext(ctx).Model(&ProductModel{}).Where("id = ?", productID).
+			UpdateColumn("reserved", gorm.Expr("reserved + ?", 1)).Error
+	})
+
+	if _, reserved := productStock(t, db, productID); succeeded <= stock || reserved <= stock {
+		t.Fatalf("succeeded = %d, reserved = %d without the conditional update, want both above %d", succeeded, reserved, stock)
+	}
+}
+
+func TestInventoryReserveConfirmReleaseConcurrent(t *testing.T) {
+	// 确认和释放先查询预占再更新，SQLite的延迟事务在升级为写事务时直接返回database is locked
+	requireMySQL(t)
+	db := newInventoryTestDB(t)
+	repo := NewGormInventoryRepository(db)
+	ctx := context.Background()
+
+	// 两个商品交叉出现在订单中，同时检验多商品预占的原子性
+	const stock, workers = 30, 120
+	first := createStressProduct(t, db, "A", stock)
+	second := createStressProduct(t, db, "B", stock)
+
+	var confirmed int64
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(orderID uint) {
+			defer wg.Done()
+			<-start
+			items := []product.ReservationItem{{ProductID: second, Quantity: 1}, {ProductID: first, Quantity: 1}}
+			if orderID%2 == 0 {
+				items[0], items[1] = items[1], items[0]
+			}
+			if _, err := repo.Reserve(ctx, orderID, items, time.Now().Add(time.Hour)); err != nil {
+				return
+			}
+
+			// 一半订单支付，一半订单取消释放库存给后面的请求
+			if orderID%2 == 0 {
+				if err := repo.ConfirmByOrder(ctx, orderID); err != nil {
+					t.Errorf("confirm order %d: %v", orderID, err)
+					return
+				}
+				atomic.AddInt64(&confirmed, 1)
+				return
+			}
+			if err := repo.ReleaseByOrder(ctx, orderID); err != nil {
+				t.Errorf("release order %d: %v", orderID, err)
+			}
+		}(uint(i + 1))
+	}
+	close(start)
+	wg.Wait()
+
+	for _, id := range []uint{first, second} {
+		left, reserved := productStock(t, db, id)
+		if reserved != 0 {
+			t.Fatalf("product %d reserved = %d, want 0", id, reserved)
+		}
+		if left < 0 || int64(left) != stock-confirmed {
+			t.Fatalf("product %d stock = %d, want %d", id, left, stock-confirmed)
+		}
+	}
+	if confirmed > stock {
+		t.Fatalf("confirmed = %d exceeds stock %d", confirmed, stock)
+	}
+}
+
+func TestInventoryReserveRollsBackPartialOrder(t *testing.T) {
+	db := newInventoryTestDB(t)
+	repo := NewGormInventoryRepository(db)
+	ctx := context.Background()
+
+	plenty := createStressProduct(t, db, "PLENTY", 10)
+	scarce := createStressProduct(t, db, "SCARCE", 1)
+
+	_, err := repo.Reserve(ctx, 1, []product.ReservationItem{
+		{ProductID: plenty, Quantity: 2},
+		{ProductID: scarce, Quantity: 2},
+	}, time.Now().Add(time.Hour))
+	var apiErr *apierror.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != apierror.ErrorCodeInsufficientStock {
+		t.Fatalf("Reserve() error = %v, want insufficient stock", err)
+	}
+
+	// 第二个商品库存不足时，第一个商品的预占也必须回滚
+	if _, reserved := productStock(t, db, plenty); reserved != 0 {
+		t.Fatalf("reserved = %d after rollback, want 0", reserved)
+	}
+	reservations, err := repo.FindByOrder(ctx, 1)
+	if err != nil || len(reservations) != 0 {
+		t.Fatalf("FindByOrder() = %v, %v, want none", reservations, err)
+	}
+}
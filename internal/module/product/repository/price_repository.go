@@ -0,0 +1,362 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PriceScheduleModel 是GORM定时调价模型
+type PriceScheduleModel struct {
+	gorm.Model
+	ProductID uint       `gorm:"not null;index:idx_product_id"`
+	Price     int64      `gorm:"not null"`
+	StartsAt  time.Time  `gorm:"not null;index:idx_status_starts_at,priority:2"`
+	EndsAt    *time.Time `gorm:"index:idx_status_ends_at,priority:2"`
+	Status    string     `gorm:"type:varchar(20);not null;index:idx_status_starts_at,priority:1;index:idx_status_ends_at,priority:1"`
+	Note      string     `gorm:"type:varchar(200)"`
+}
+
+// TableName 指定表名
+func (PriceScheduleModel) TableName() string {
+	return "product_price_schedules"
+}
+
+// PriceHistoryModel 是GORM价格历史模型，每次售价变化追加一条
+type PriceHistoryModel struct {
+	ID        uint      `gorm:"primaryKey"`
+	ProductID uint      `gorm:"not null;index:idx_product_changed_at,priority:1"`
+	Price     int64     `gorm:"not null"`
+	ChangedAt time.Time `gorm:"not null;index:idx_product_changed_at,priority:2"`
+}
+
+// TableName 指定表名
+func (PriceHistoryModel) TableName() string {
+	return "product_price_history"
+}
+
+// GormPriceRepository 是商品价格仓库的GORM实现
+type GormPriceRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPriceRepository 创建一个新的GORM商品价格仓库
+func NewGormPriceRepository(db *gorm.DB) product.PriceRepository {
+	return &GormPriceRepository{db: db}
+}
+
+// scheduleToDomain 将GORM模型转换为领域模型
+func scheduleToDomain(m *PriceScheduleModel) *product.PriceSchedule {
+	return &product.PriceSchedule{
+		ID:        m.ID,
+		ProductID: m.ProductID,
+		Price:     common.Money(m.Price),
+		StartsAt:  m.StartsAt,
+		EndsAt:    m.EndsAt,
+		Status:    m.Status,
+		Note:      m.Note,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// FindScheduleByID 根据ID查找定时调价
+func (r *GormPriceRepository) FindScheduleByID(ctx context.Context, id uint) (*product.PriceSchedule, error) {
+	var model PriceScheduleModel
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("定时调价不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询定时调价错误: %w", err)
+	}
+	return scheduleToDomain(&model), nil
+}
+
+// FindSchedules 按生效时间查询商品的全部定时调价
+func (r *GormPriceRepository) FindSchedules(ctx context.Context, productID uint) ([]product.PriceSchedule, error) {
+	var models []PriceScheduleModel
+	if err := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("starts_at, id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询定时调价错误: %w", err)
+	}
+
+	schedules := make([]product.PriceSchedule, 0, len(models))
+	for i := range models {
+		schedules = append(schedules, *scheduleToDomain(&models[i]))
+	}
+	return schedules, nil
+}
+
+// CreateSchedule 创建定时调价
+// 锁定商品行后检查重叠，保证并发创建的促销不会互相重叠
+func (r *GormPriceRepository) CreateSchedule(ctx context.Context, s *product.PriceSchedule) error {
+	model := &PriceScheduleModel{
+		ProductID: s.ProductID,
+		Price:     int64(s.Price),
+		StartsAt:  s.StartsAt,
+		EndsAt:    s.EndsAt,
+		Status:    product.PriceScheduleStatusPending,
+		Note:      s.Note,
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockProductPricing(tx, s.ProductID); err != nil {
+			return err
+		}
+
+		if s.IsSale() {
+			var overlapping int64
+			if err := tx.Model(&PriceScheduleModel{}).
+				Where("product_id = ? AND status IN ? AND ends_at IS NOT NULL", s.ProductID,
+					[]string{product.PriceScheduleStatusPending, product.PriceScheduleStatusActive}).
+				Where("starts_at < ? AND ends_at > ?", *s.EndsAt, s.StartsAt).
+				Count(&overlapping).Error; err != nil {
+				return fmt.Errorf("查询定时调价错误: %w", err)
+			}
+			if overlapping > 0 {
+				return apierror.NewValidationError("促销时间段重叠", "同一商品的限时促销时间段不能重叠")
+			}
+		}
+
+		if err := tx.Create(model).Error; err != nil {
+			return fmt.Errorf("创建定时调价错误: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 更新领域模型
+	s.ID = model.ID
+	s.Status = model.Status
+	s.CreatedAt = model.CreatedAt
+
+	return nil
+}
+
+// CancelSchedule 取消定时调价，进行中的促销会立即结束并恢复常规价格
+func (r *GormPriceRepository) CancelSchedule(ctx context.Context, id uint, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		schedule, err := lockSchedule(tx, id)
+		if err != nil {
+			return err
+		}
+
+		switch schedule.Status {
+		case product.PriceScheduleStatusPending:
+		case product.PriceScheduleStatusActive:
+			if _, err := updatePricing(tx, schedule.ProductID, now, (*product.Product).EndSale); err != nil {
+				return err
+			}
+		default:
+			return apierror.NewInvalidStateTransitionError("定时调价已结束，无法取消", fmt.Sprintf("ID: %d, 状态: %s", id, schedule.Status))
+		}
+
+		return setScheduleStatus(tx, id, product.PriceScheduleStatusCancelled)
+	})
+}
+
+// FindDueSchedules 查询需要结束的促销和需要生效的调价
+// 先结束到期的促销，再按生效时间执行调价，保证相邻的促销按时间顺序切换
+func (r *GormPriceRepository) FindDueSchedules(ctx context.Context, now time.Time, limit int) ([]product.PriceSchedule, error) {
+	var ending []PriceScheduleModel
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND ends_at <= ?", product.PriceScheduleStatusActive, now).
+		Order("ends_at, id").Limit(limit).Find(&ending).Error; err != nil {
+		return nil, fmt.Errorf("查询到期促销错误: %w", err)
+	}
+
+	var starting []PriceScheduleModel
+	if remaining := limit - len(ending); remaining > 0 {
+		if err := r.db.WithContext(ctx).
+			Where("status = ? AND starts_at <= ?", product.PriceScheduleStatusPending, now).
+			Order("starts_at, id").Limit(remaining).Find(&starting).Error; err != nil {
+			return nil, fmt.Errorf("查询到期调价错误: %w", err)
+		}
+	}
+
+	schedules := make([]product.PriceSchedule, 0, len(ending)+len(starting))
+	for i := range ending {
+		schedules = append(schedules, *scheduleToDomain(&ending[i]))
+	}
+	for i := range starting {
+		schedules = append(schedules, *scheduleToDomain(&starting[i]))
+	}
+	return schedules, nil
+}
+
+// ApplySchedule 执行到期的定时调价
+// 重新读取并锁定调价记录，已被其它流程处理或尚未到期的记录直接跳过
+func (r *GormPriceRepository) ApplySchedule(ctx context.Context, id uint, now time.Time) (bool, error) {
+	changed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		schedule, err := lockSchedule(tx, id)
+		if err != nil {
+			return err
+		}
+
+		// 商品已删除时取消调价，避免每次执行都失败
+		if err := tx.Select("id").First(&ProductModel{}, schedule.ProductID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return setScheduleStatus(tx, id, product.PriceScheduleStatusCancelled)
+			}
+			return fmt.Errorf("查询商品错误: %w", err)
+		}
+
+		switch {
+		case schedule.Status == product.PriceScheduleStatusActive && !schedule.EndsAt.After(now):
+			changed, err = updatePricing(tx, schedule.ProductID, now, (*product.Product).EndSale)
+			if err != nil {
+				return err
+			}
+			return setScheduleStatus(tx, id, product.PriceScheduleStatusCompleted)
+
+		case schedule.Status == product.PriceScheduleStatusPending && !schedule.StartsAt.After(now):
+			// 服务停机期间整个促销时间段都已过去，不再调整价格
+			if schedule.IsSale() && !schedule.EndsAt.After(now) {
+				return setScheduleStatus(tx, id, product.PriceScheduleStatusCompleted)
+			}
+
+			if schedule.IsSale() {
+				changed, err = updatePricing(tx, schedule.ProductID, now, func(p *product.Product) {
+					p.StartSale(schedule.Price)
+				})
+				if err != nil {
+					return err
+				}
+				return setScheduleStatus(tx, id, product.PriceScheduleStatusActive)
+			}
+
+			changed, err = updatePricing(tx, schedule.ProductID, now, func(p *product.Product) {
+				p.SetRegularPrice(schedule.Price)
+			})
+			if err != nil {
+				return err
+			}
+			return setScheduleStatus(tx, id, product.PriceScheduleStatusCompleted)
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// SetRegularPrice 修改商品的常规价格，ctx携带事务时加入该事务
+func (r *GormPriceRepository) SetRegularPrice(ctx context.Context, productID uint, price common.Money, now time.Time) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		_, err := updatePricing(tx, productID, now, func(p *product.Product) {
+			p.SetRegularPrice(price)
+		})
+		return err
+	})
+}
+
+// FindHistory 查询since之后的售价变化，第一条为since时刻生效的售价
+func (r *GormPriceRepository) FindHistory(ctx context.Context, productID uint, since time.Time) ([]product.PricePoint, error) {
+	var models []PriceHistoryModel
+
+	var effective PriceHistoryModel
+	err := r.db.WithContext(ctx).Where("product_id = ? AND changed_at <= ?", productID, since).
+		Order("changed_at DESC, id DESC").First(&effective).Error
+	switch err {
+	case nil:
+		models = append(models, effective)
+	case gorm.ErrRecordNotFound:
+	default:
+		return nil, fmt.Errorf("查询价格历史错误: %w", err)
+	}
+
+	var changes []PriceHistoryModel
+	if err := r.db.WithContext(ctx).Where("product_id = ? AND changed_at > ?", productID, since).
+		Order("changed_at, id").Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("查询价格历史错误: %w", err)
+	}
+	models = append(models, changes...)
+
+	points := make([]product.PricePoint, 0, len(models))
+	for _, m := range models {
+		points = append(points, product.PricePoint{Price: common.Money(m.Price), ChangedAt: m.ChangedAt})
+	}
+	return points, nil
+}
+
+// lockSchedule 在事务中锁定并读取定时调价
+func lockSchedule(tx *gorm.DB, id uint) (*product.PriceSchedule, error) {
+	var model PriceScheduleModel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("定时调价不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询定时调价错误: %w", err)
+	}
+	return scheduleToDomain(&model), nil
+}
+
+// setScheduleStatus 更新定时调价状态
+func setScheduleStatus(tx *gorm.DB, id uint, status string) error {
+	if err := tx.Model(&PriceScheduleModel{}).Where("id = ?", id).Update("status", status).Error; err != nil {
+		return fmt.Errorf("更新定时调价状态错误: %w", err)
+	}
+	return nil
+}
+
+// lockProductPricing 在事务中锁定商品行并读取价格字段
+func lockProductPricing(tx *gorm.DB, productID uint) (*product.Product, error) {
+	var model ProductModel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "price", "compare_at_price").First(&model, productID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("商品不存在", fmt.Sprintf("ID: %d", productID))
+		}
+		return nil, fmt.Errorf("查询商品错误: %w", err)
+	}
+	return &product.Product{
+		ID:             model.ID,
+		Price:          common.Money(model.Price),
+		CompareAtPrice: moneyPtrToDomain(model.CompareAtPrice),
+	}, nil
+}
+
+// updatePricing 锁定商品后修改价格字段，售价变化时追加价格历史
+func updatePricing(tx *gorm.DB, productID uint, now time.Time, change func(p *product.Product)) (bool, error) {
+	p, err := lockProductPricing(tx, productID)
+	if err != nil {
+		return false, err
+	}
+
+	before := p.Price
+	change(p)
+
+	if err := tx.Model(&ProductModel{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"price":            int64(p.Price),
+		"compare_at_price": moneyPtrToModel(p.CompareAtPrice),
+	}).Error; err != nil {
+		return false, fmt.Errorf("更新商品价格错误: %w", err)
+	}
+
+	if p.Price == before {
+		return false, nil
+	}
+	if err := appendPriceHistory(tx, productID, int64(p.Price), now); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// appendPriceHistory 追加一条价格历史
+func appendPriceHistory(tx *gorm.DB, productID uint, price int64, changedAt time.Time) error {
+	if err := tx.Create(&PriceHistoryModel{ProductID: productID, Price: price, ChangedAt: changedAt}).Error; err != nil {
+		return fmt.Errorf("记录价格历史错误: %w", err)
+	}
+	return nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormPriceRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&PriceScheduleModel{}, &PriceHistoryModel{})
+}
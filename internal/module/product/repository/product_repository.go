@@ -0,0 +1,406 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProductModel 是GORM商品模型
+type ProductModel struct {
+	gorm.Model
+	SKU            string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_sku"`
+	Name           string     `gorm:"type:varchar(200);not null;index:idx_ft_name,class:FULLTEXT,option:WITH PARSER ngram"`
+	Description    string     `gorm:"type:text;index:idx_ft_description,class:FULLTEXT,option:WITH PARSER ngram"`
+	Type           string     `gorm:"type:varchar(20);not null;default:'physical'"`
+	Price          int64      `gorm:"not null;default:0"`
+	CompareAtPrice *int64     // 限时促销期间的常规价格，为空表示没有进行中的促销
+	Stock          int        `gorm:"not null;default:0"`
+	Reserved       int        `gorm:"not null;default:0"`
+	Weight         int        `gorm:"not null;default:0"`
+	TaxClass       string     `gorm:"type:varchar(30);not null;default:'standard'"`
+	Status         string     `gorm:"type:varchar(20);not null;default:'draft';index:idx_status"`
+	CategoryID     uint       `gorm:"index:idx_category_id"`
+	MerchantID     uint       `gorm:"not null;default:0;index:idx_merchant_id"`
+	PublishAt      *time.Time `gorm:"index:idx_publish_at"`
+	UnpublishAt    *time.Time `gorm:"index:idx_unpublish_at"`
+
+	// 评分汇总，由评价仓库在评价变更的事务中重新计算
+	RatingCount int `gorm:"not null;default:0"`
+	RatingSum   int `gorm:"not null;default:0"`
+	Rating1     int `gorm:"column:rating_1;not null;default:0"`
+	Rating2     int `gorm:"column:rating_2;not null;default:0"`
+	Rating3     int `gorm:"column:rating_3;not null;default:0"`
+	Rating4     int `gorm:"column:rating_4;not null;default:0"`
+	Rating5     int `gorm:"column:rating_5;not null;default:0"`
+
+	Images []ImageModel `gorm:"foreignKey:ProductID"`
+}
+
+// TableName 指定表名
+func (ProductModel) TableName() string {
+	return "products"
+}
+
+// GormProductRepository 是商品仓库的GORM实现
+type GormProductRepository struct {
+	db *gorm.DB
+}
+
+// NewGormProductRepository 创建一个新的GORM商品仓库
+func NewGormProductRepository(db *gorm.DB) product.ProductRepository {
+	return &GormProductRepository{db: db}
+}
+
+// productToModel 将领域模型转换为GORM模型
+func productToModel(p *product.Product) *ProductModel {
+	return &ProductModel{
+		Model: gorm.Model{
+			ID:        p.ID,
+			CreatedAt: p.CreatedAt,
+			UpdatedAt: p.UpdatedAt,
+		},
+		SKU:            p.SKU,
+		Name:           p.Name,
+		Description:    p.Description,
+		Type:           p.Type,
+		Price:          int64(p.Price),
+		CompareAtPrice: moneyPtrToModel(p.CompareAtPrice),
+		Stock:          p.Stock,
+		Reserved:       p.Reserved,
+		Weight:         p.Weight,
+		TaxClass:       p.TaxClass,
+		Status:         p.Status,
+		CategoryID:     p.CategoryID,
+		MerchantID:     p.MerchantID,
+		PublishAt:      p.PublishAt,
+		UnpublishAt:    p.UnpublishAt,
+	}
+}
+
+// productToDomain 将GORM模型转换为领域模型
+func productToDomain(m *ProductModel) *product.Product {
+	images := make([]product.Image, 0, len(m.Images))
+	for i := range m.Images {
+		images = append(images, *imageToDomain(&m.Images[i]))
+	}
+
+	return &product.Product{
+		ID:             m.ID,
+		SKU:            m.SKU,
+		Name:           m.Name,
+		Description:    m.Description,
+		Type:           m.Type,
+		Price:          common.Money(m.Price),
+		CompareAtPrice: moneyPtrToDomain(m.CompareAtPrice),
+		Stock:          m.Stock,
+		Reserved:       m.Reserved,
+		Weight:         m.Weight,
+		TaxClass:       m.TaxClass,
+		Status:         m.Status,
+		CategoryID:     m.CategoryID,
+		MerchantID:     m.MerchantID,
+		PublishAt:      m.PublishAt,
+		UnpublishAt:    m.UnpublishAt,
+		Rating:         ratingToDomain(m),
+		Images:         images,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// moneyPtrToModel 转换可空金额
+func moneyPtrToModel(m *common.Money) *int64 {
+	if m == nil {
+		return nil
+	}
+	v := int64(*m)
+	return &v
+}
+
+// moneyPtrToDomain 转换可空金额
+func moneyPtrToDomain(v *int64) *common.Money {
+	if v == nil {
+		return nil
+	}
+	m := common.Money(*v)
+	return &m
+}
+
+// ratingToDomain 根据评分汇总列计算平均分和分布
+func ratingToDomain(m *ProductModel) product.Rating {
+	rating := product.Rating{
+		Count: m.RatingCount,
+		Distribution: map[int]int{
+			1: m.Rating1,
+			2: m.Rating2,
+			3: m.Rating3,
+			4: m.Rating4,
+			5: m.Rating5,
+		},
+	}
+	if m.RatingCount > 0 {
+		rating.Average = math.Round(float64(m.RatingSum)/float64(m.RatingCount)*100) / 100
+	}
+	return rating
+}
+
+// FindByID 根据ID查找商品
+func (r *GormProductRepository) FindByID(ctx context.Context, id uint) (*product.Product, error) {
+	var model ProductModel
+	if err := r.db.WithContext(ctx).Preload("Images", orderImages).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("商品不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询商品错误: %w", err)
+	}
+	return productToDomain(&model), nil
+}
+
+// FindBySKU 根据SKU查找商品
+func (r *GormProductRepository) FindBySKU(ctx context.Context, sku string) (*product.Product, error) {
+	var model ProductModel
+	if err := r.db.WithContext(ctx).Preload("Images", orderImages).Where("sku = ?", sku).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("商品不存在", fmt.Sprintf("SKU: %s", sku))
+		}
+		return nil, fmt.Errorf("查询商品错误: %w", err)
+	}
+	return productToDomain(&model), nil
+}
+
+// FindByIDs 按给定ID的顺序批量查找商品
+func (r *GormProductRepository) FindByIDs(ctx context.Context, ids []uint) ([]product.Product, error) {
+	if len(ids) == 0 {
+		return []product.Product{}, nil
+	}
+
+	var models []ProductModel
+	if err := r.db.WithContext(ctx).Preload("Images", orderImages).Where("id IN ?", ids).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询商品列表错误: %w", err)
+	}
+
+	byID := make(map[uint]*ProductModel, len(models))
+	for i := range models {
+		byID[models[i].ID] = &models[i]
+	}
+
+	products := make([]product.Product, 0, len(models))
+	for _, id := range ids {
+		if m, ok := byID[id]; ok {
+			products = append(products, *productToDomain(m))
+		}
+	}
+	return products, nil
+}
+
+// Find 按条件分页查询商品
+func (r *GormProductRepository) Find(ctx context.Context, query product.ProductQuery) (*product.ProductPaginationResult, error) {
+	var models []ProductModel
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&ProductModel{})
+	if query.CategoryID != 0 {
+		db = db.Where("category_id = ?", query.CategoryID)
+	}
+	if query.MerchantID != 0 {
+		db = db.Where("merchant_id = ?", query.MerchantID)
+	}
+	if len(query.Statuses) > 0 {
+		db = db.Where("status IN ?", query.Statuses)
+	}
+
+	// 查询总数
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询商品总数错误: %w", err)
+	}
+
+	// 分页查询
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Preload("Images", orderImages).Order("id DESC").Offset(offset).Limit(query.PageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询商品列表错误: %w", err)
+	}
+
+	products := make([]product.Product, 0, len(models))
+	for i := range models {
+		products = append(products, *productToDomain(&models[i]))
+	}
+
+	return &product.ProductPaginationResult{
+		Total:    int(total),
+		Products: products,
+	}, nil
+}
+
+// Create 创建商品，并以初始售价开始记录价格历史
+func (r *GormProductRepository) Create(ctx context.Context, p *product.Product) error {
+	model := productToModel(p)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(model).Error; err != nil {
+			return err
+		}
+		return appendPriceHistory(tx, model.ID, model.Price, model.CreatedAt)
+	})
+	if err != nil {
+		if r.db.WithContext(ctx).Where("sku = ?", p.SKU).First(&ProductModel{}).Error == nil {
+			return apierror.NewDuplicateEntityError("SKU已存在", p.SKU)
+		}
+		return fmt.Errorf("创建商品错误: %w", err)
+	}
+
+	// 更新领域模型
+	p.ID = model.ID
+	p.CreatedAt = model.CreatedAt
+	p.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// Update 更新商品
+// 价格只能通过价格仓库修改，库存字段只能通过UpdateStock和库存预占修改，
+// 状态和定时上下架时间只能通过UpdateStatus修改，评分汇总由评价仓库维护，
+// 这里忽略它们，避免覆盖并发写入的结果
+func (r *GormProductRepository) Update(ctx context.Context, p *product.Product) error {
+	model := productToModel(p)
+	if err := database.Conn(ctx, r.db).Omit(
+		"price", "compare_at_price", "stock", "reserved", "status", "publish_at", "unpublish_at",
+		"rating_count", "rating_sum", "rating_1", "rating_2", "rating_3", "rating_4", "rating_5",
+	).Save(model).Error; err != nil {
+		return fmt.Errorf("更新商品错误: %w", err)
+	}
+
+	// 更新领域模型
+	p.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// Delete 删除商品
+func (r *GormProductRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&ProductModel{}, id).Error; err != nil {
+		return fmt.Errorf("删除商品错误: %w", err)
+	}
+	return nil
+}
+
+// UpdateStock 原子地增减实际库存
+// 使用条件更新保证扣减后的库存不低于已预占数量，在事务中调用时加入该事务
+func (r *GormProductRepository) UpdateStock(ctx context.Context, id uint, delta int) error {
+	result := database.Conn(ctx, r.db).Model(&ProductModel{}).
+		Where("id = ? AND stock + ? >= reserved", id, delta).
+		UpdateColumn("stock", gorm.Expr("stock + ?", delta))
+	if result.Error != nil {
+		return fmt.Errorf("更新库存错误: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return apierror.NewInsufficientStockError("库存不足", fmt.Sprintf("商品ID: %d, 调整数量: %d", id, delta))
+	}
+	return nil
+}
+
+// UpdateStatus 以原状态为条件更新商品状态和定时上下架时间
+func (r *GormProductRepository) UpdateStatus(ctx context.Context, p *product.Product, from string) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&ProductModel{}).
+		Where("id = ? AND status = ?", p.ID, from).
+		Updates(map[string]interface{}{
+			"status":       p.Status,
+			"publish_at":   p.PublishAt,
+			"unpublish_at": p.UnpublishAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("更新商品状态错误: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SyncStockStatus 根据可售库存在上架和缺货状态之间切换
+// 先找出状态与库存不一致的商品，再逐个条件更新，期间库存再次变化的商品会被跳过，
+// 由引起变化的流程再次同步；ids为空时检查全部商品
+func (r *GormProductRepository) SyncStockStatus(ctx context.Context, ids []uint) ([]uint, error) {
+	const outOfStock = "status = ? AND stock - reserved <= 0"
+	const inStock = "status = ? AND stock - reserved > 0"
+
+	db := r.db.WithContext(ctx).Model(&ProductModel{}).
+		Where(r.db.Where(outOfStock, product.ProductStatusPublished).Or(inStock, product.ProductStatusOutOfStock))
+	if len(ids) > 0 {
+		db = db.Where("id IN ?", ids)
+	}
+
+	var candidates []uint
+	if err := db.Order("id").Pluck("id", &candidates).Error; err != nil {
+		return nil, fmt.Errorf("查询库存状态不一致的商品错误: %w", err)
+	}
+
+	changed := make([]uint, 0, len(candidates))
+	for _, id := range candidates {
+		result := r.db.WithContext(ctx).Model(&ProductModel{}).
+			Where("id = ?", id).
+			Where(r.db.Where(outOfStock, product.ProductStatusPublished).Or(inStock, product.ProductStatusOutOfStock)).
+			Update("status", gorm.Expr("CASE WHEN stock - reserved > 0 THEN ? ELSE ? END",
+				product.ProductStatusPublished, product.ProductStatusOutOfStock))
+		if result.Error != nil {
+			return changed, fmt.Errorf("更新商品库存状态错误: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			changed = append(changed, id)
+		}
+	}
+	return changed, nil
+}
+
+// LockByID 在事务中锁定商品行并返回最新的商品
+func (r *GormProductRepository) LockByID(ctx context.Context, id uint) (*product.Product, error) {
+	var model ProductModel
+	if err := database.Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("商品不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("锁定商品错误: %w", err)
+	}
+	return productToDomain(&model), nil
+}
+
+// Transaction 在事务中执行fn
+func (r *GormProductRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.Transaction(ctx, r.db, fn)
+}
+
+// FindDueForPublish 查询定时上架时间已到的商品
+func (r *GormProductRepository) FindDueForPublish(ctx context.Context, now time.Time, limit int) ([]product.Product, error) {
+	return r.findDue(ctx, r.db.Where("status = ? AND publish_at <= ?", product.ProductStatusScheduled, now), "publish_at", limit)
+}
+
+// FindDueForUnpublish 查询定时下架时间已到且仍在售的商品
+func (r *GormProductRepository) FindDueForUnpublish(ctx context.Context, now time.Time, limit int) ([]product.Product, error) {
+	return r.findDue(ctx, r.db.Where("status IN ? AND unpublish_at <= ?", product.VisibleProductStatuses, now), "unpublish_at", limit)
+}
+
+// findDue 按到期时间顺序查询满足条件的商品
+func (r *GormProductRepository) findDue(ctx context.Context, cond *gorm.DB, order string, limit int) ([]product.Product, error) {
+	var models []ProductModel
+	if err := r.db.WithContext(ctx).Where(cond).Order(order).Limit(limit).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询定时上下架商品错误: %w", err)
+	}
+
+	products := make([]product.Product, 0, len(models))
+	for i := range models {
+		products = append(products, *productToDomain(&models[i]))
+	}
+	return products, nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormProductRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&ProductModel{})
+}
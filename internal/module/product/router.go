@@ -0,0 +1,32 @@
+package product
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/product/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册商品模块路由
+// 商品的管理接口由admin模块统一提供
+func RegisterRoutes(router *gin.Engine, handler *handler.ProductHTTPHandler, jwtConfig *config.JWTConfig) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 商品公共查询路由(不需要认证)
+	productRoutes := v1.Group("/products")
+	{
+		// 获取商品列表
+		productRoutes.GET("", handler.ListProducts)
+
+		// 获取商品详情
+		productRoutes.GET("/:id", handler.GetProduct)
+
+		// 获取最近30天的最低售价
+		productRoutes.GET("/:id/lowest-price", handler.GetLowestPrice)
+
+		// 验证当前用户是否满足持币门槛(需要认证)
+		productRoutes.GET("/:id/gate", middleware.JWT(jwtConfig), handler.CheckGate)
+	}
+}
@@ -0,0 +1,202 @@
+package search
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"web3-ecommerce-app/internal/domain/product"
+)
+
+// 字段权重，商品名称命中比描述命中更相关
+const (
+	nameBoost        = 3.0
+	descriptionBoost = 1.0
+	// typoPenalty 拼写纠错命中的得分折扣
+	typoPenalty = 0.5
+)
+
+// posting 记录索引词在某个商品各字段中出现的次数
+type posting struct {
+	nameTF        int
+	descriptionTF int
+}
+
+// document 记录商品的过滤字段和包含的索引词，用于过滤和删除
+type document struct {
+	categoryID uint
+	merchantID uint
+	status     string
+	terms      []string
+}
+
+// MemoryIndex 是纯Go实现的内存倒排索引，适用于测试和小规模部署
+type MemoryIndex struct {
+	mu        sync.RWMutex
+	postings  map[string]map[uint]*posting
+	documents map[uint]*document
+}
+
+// NewMemoryIndex 创建内存倒排索引
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		postings:  make(map[string]map[uint]*posting),
+		documents: make(map[uint]*document),
+	}
+}
+
+// Index 新增或更新商品的索引
+func (idx *MemoryIndex) Index(ctx context.Context, p *product.Product) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(p.ID)
+
+	postings := make(map[string]*posting)
+	for _, term := range Tokenize(p.Name) {
+		if postings[term] == nil {
+			postings[term] = &posting{}
+		}
+		postings[term].nameTF++
+	}
+	for _, term := range Tokenize(p.Description) {
+		if postings[term] == nil {
+			postings[term] = &posting{}
+		}
+		postings[term].descriptionTF++
+	}
+
+	doc := &document{
+		categoryID: p.CategoryID,
+		merchantID: p.MerchantID,
+		status:     p.Status,
+		terms:      make([]string, 0, len(postings)),
+	}
+	for term, post := range postings {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[uint]*posting)
+		}
+		idx.postings[term][p.ID] = post
+		doc.terms = append(doc.terms, term)
+	}
+	idx.documents[p.ID] = doc
+
+	return nil
+}
+
+// Remove 从索引中删除商品
+func (idx *MemoryIndex) Remove(ctx context.Context, productID uint) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(productID)
+	return nil
+}
+
+// removeLocked 删除商品的全部倒排记录，调用方需持有写锁
+func (idx *MemoryIndex) removeLocked(productID uint) {
+	doc, ok := idx.documents[productID]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(idx.postings[term], productID)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.documents, productID)
+}
+
+// Search 按TF-IDF相关度搜索商品
+// 查询词之间是"或"关系，命中的词越多、越集中在名称中，排名越靠前；
+// 索引中不存在的英文词会尝试匹配编辑距离相近的词
+func (idx *MemoryIndex) Search(ctx context.Context, query product.SearchQuery) (*product.SearchResult, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	total := float64(len(idx.documents))
+	scores := make(map[uint]float64)
+
+	for _, token := range uniqueTokens(Tokenize(query.Text)) {
+		for term, weight := range idx.expandLocked(token) {
+			docs := idx.postings[term]
+			idf := math.Log(1 + total/float64(len(docs)))
+			for id, post := range docs {
+				doc := idx.documents[id]
+				if query.CategoryID != 0 && doc.categoryID != query.CategoryID {
+					continue
+				}
+				if query.MerchantID != 0 && doc.merchantID != query.MerchantID {
+					continue
+				}
+				if len(query.Statuses) > 0 && !slices.Contains(query.Statuses, doc.status) {
+					continue
+				}
+				tf := nameBoost*float64(post.nameTF) + descriptionBoost*float64(post.descriptionTF)
+				scores[id] += weight * idf * (1 + math.Log(tf))
+			}
+		}
+	}
+
+	ids := make([]uint, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+
+	result := &product.SearchResult{Total: len(ids), ProductIDs: []uint{}}
+	offset := (query.Page - 1) * query.PageSize
+	if offset < len(ids) {
+		end := min(offset+query.PageSize, len(ids))
+		result.ProductIDs = ids[offset:end]
+	}
+	return result, nil
+}
+
+// expandLocked 返回查询词对应的索引词及其权重
+// 精确命中权重为1；英文词没有精确命中时，按编辑距离查找相近的词并降低权重
+func (idx *MemoryIndex) expandLocked(token string) map[string]float64 {
+	if _, ok := idx.postings[token]; ok {
+		return map[string]float64{token: 1}
+	}
+	if isHanToken(token) {
+		return nil
+	}
+
+	maxDistance := maxTypos(token)
+	if maxDistance == 0 {
+		return nil
+	}
+
+	expanded := make(map[string]float64)
+	for term := range idx.postings {
+		if isHanToken(term) {
+			continue
+		}
+		if d := editDistance(token, term, maxDistance); d <= maxDistance {
+			expanded[term] = math.Pow(typoPenalty, float64(d))
+		}
+	}
+	return expanded
+}
+
+// uniqueTokens 去除重复的查询词
+func uniqueTokens(tokens []string) []string {
+	seen := make(map[string]struct{}, len(tokens))
+	unique := tokens[:0]
+	for _, t := range tokens {
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		unique = append(unique, t)
+	}
+	return unique
+}
@@ -0,0 +1,129 @@
+package search
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"web3-ecommerce-app/internal/domain/product"
+)
+
+func newTestIndex(t *testing.T, products ...*product.Product) *MemoryIndex {
+	t.Helper()
+	idx := NewMemoryIndex()
+	for _, p := range products {
+		if p.Status == "" {
+			p.Status = "active"
+		}
+		if err := idx.Index(context.Background(), p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return idx
+}
+
+func search(t *testing.T, idx *MemoryIndex, query product.SearchQuery) []uint {
+	t.Helper()
+	if query.Page == 0 {
+		query.Page, query.PageSize = 1, 10
+	}
+	result, err := idx.Search(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.ProductIDs
+}
+
+func TestMemoryIndexSearch(t *testing.T) {
+	idx := newTestIndex(t,
+		&product.Product{ID: 1, Name: "Ledger Nano X", Description: "Bluetooth hardware wallet", CategoryID: 1},
+		&product.Product{ID: 2, Name: "Hardware Wallet Case", Description: "Leather case", CategoryID: 2},
+		&product.Product{ID: 3, Name: "硬件钱包", Description: "支持多链的冷钱包", CategoryID: 1, MerchantID: 9},
+		&product.Product{ID: 4, Name: "Sticker", Description: "wallet sticker", CategoryID: 2, Status: "inactive"},
+	)
+
+	tests := []struct {
+		name  string
+		query product.SearchQuery
+		want  []uint
+	}{
+		{"名称命中排在描述命中之前", product.SearchQuery{Text: "wallet"}, []uint{2, 4, 1}},
+		{"按分类过滤", product.SearchQuery{Text: "wallet", CategoryID: 1}, []uint{1}},
+		{"按状态过滤", product.SearchQuery{Text: "wallet", Statuses: []string{"active"}}, []uint{2, 1}},
+		{"按商家过滤", product.SearchQuery{Text: "钱包", MerchantID: 9}, []uint{3}},
+		{"中文双字命中", product.SearchQuery{Text: "冷钱包"}, []uint{3}},
+		{"命中更多查询词的排名更高", product.SearchQuery{Text: "ledger wallet", Statuses: []string{"active"}}, []uint{1, 2}},
+		{"拼写纠错", product.SearchQuery{Text: "ledgre"}, []uint{1}},
+		{"短词不做纠错", product.SearchQuery{Text: "nan"}, []uint{}},
+		{"没有命中", product.SearchQuery{Text: "monitor"}, []uint{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := search(t, idx, tt.query); !slices.Equal(got, tt.want) {
+				t.Fatalf("Search(%+v) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryIndexExactMatchOutranksTypo(t *testing.T) {
+	idx := newTestIndex(t,
+		&product.Product{ID: 1, Name: "ledger"},
+		&product.Product{ID: 2, Name: "ledgers"},
+	)
+	if got := search(t, idx, product.SearchQuery{Text: "ledger"}); !slices.Equal(got, []uint{1}) {
+		t.Fatalf("Search(ledger) = %v, want exact match only", got)
+	}
+	if got := search(t, idx, product.SearchQuery{Text: "ledgerz"}); !slices.Equal(got, []uint{2, 1}) {
+		t.Fatalf("Search(ledgerz) = %v, want both typo matches", got)
+	}
+}
+
+func TestMemoryIndexReindexAndRemove(t *testing.T) {
+	ctx := context.Background()
+	p := &product.Product{ID: 1, Name: "Trezor", Status: "active"}
+	idx := newTestIndex(t, p)
+
+	p.Name = "Keystone"
+	if err := idx.Index(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if got := search(t, idx, product.SearchQuery{Text: "trezor"}); len(got) != 0 {
+		t.Fatalf("old name still indexed: %v", got)
+	}
+	if got := search(t, idx, product.SearchQuery{Text: "keystone"}); !slices.Equal(got, []uint{1}) {
+		t.Fatalf("Search(keystone) = %v", got)
+	}
+
+	if err := idx.Remove(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := search(t, idx, product.SearchQuery{Text: "keystone"}); len(got) != 0 {
+		t.Fatalf("removed product still indexed: %v", got)
+	}
+	if len(idx.postings) != 0 || len(idx.documents) != 0 {
+		t.Fatalf("index not empty after remove: %d postings, %d documents", len(idx.postings), len(idx.documents))
+	}
+}
+
+func TestMemoryIndexPagination(t *testing.T) {
+	var products []*product.Product
+	for id := uint(1); id <= 5; id++ {
+		products = append(products, &product.Product{ID: id, Name: "wallet"})
+	}
+	idx := newTestIndex(t, products...)
+
+	result, err := idx.Search(context.Background(), product.SearchQuery{Text: "wallet", Page: 2, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 得分相同时按ID倒序
+	if result.Total != 5 || !slices.Equal(result.ProductIDs, []uint{3, 2}) {
+		t.Fatalf("page 2 = %+v", result)
+	}
+
+	result, _ = idx.Search(context.Background(), product.SearchQuery{Text: "wallet", Page: 4, PageSize: 2})
+	if result.Total != 5 || len(result.ProductIDs) != 0 {
+		t.Fatalf("page past end = %+v", result)
+	}
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/module/product/repository"
+
+	"gorm.io/gorm"
+)
+
+// MySQLIndex 是基于MySQL FULLTEXT索引的商品搜索实现
+// name和description上分别建有使用ngram解析器的全文索引，既能处理中文，
+// ngram切分也让部分拼写错误的英文词仍能按重叠的字符片段命中；
+// 全文索引由MySQL在写入时自动维护，因此Index和Remove无需额外操作
+type MySQLIndex struct {
+	db *gorm.DB
+}
+
+// NewMySQLIndex 创建MySQL全文搜索索引
+func NewMySQLIndex(db *gorm.DB) *MySQLIndex {
+	return &MySQLIndex{db: db}
+}
+
+// Index MySQL全文索引随商品表自动更新
+func (idx *MySQLIndex) Index(ctx context.Context, p *product.Product) error {
+	return nil
+}
+
+// Remove MySQL全文索引随商品表自动更新
+func (idx *MySQLIndex) Remove(ctx context.Context, productID uint) error {
+	return nil
+}
+
+// Search 使用自然语言模式全文检索，名称的相关度按权重放大后与描述相关度相加
+func (idx *MySQLIndex) Search(ctx context.Context, query product.SearchQuery) (*product.SearchResult, error) {
+	const nameMatch = "MATCH(name) AGAINST(? IN NATURAL LANGUAGE MODE)"
+	const descriptionMatch = "MATCH(description) AGAINST(? IN NATURAL LANGUAGE MODE)"
+
+	filtered := func() *gorm.DB {
+		db := idx.db.WithContext(ctx).Model(&repository.ProductModel{}).
+			Where(nameMatch+" OR "+descriptionMatch, query.Text, query.Text)
+		if query.CategoryID != 0 {
+			db = db.Where("category_id = ?", query.CategoryID)
+		}
+		if query.MerchantID != 0 {
+			db = db.Where("merchant_id = ?", query.MerchantID)
+		}
+		if len(query.Statuses) > 0 {
+			db = db.Where("status IN ?", query.Statuses)
+		}
+		return db
+	}
+
+	var total int64
+	if err := filtered().Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("搜索商品错误: %w", err)
+	}
+
+	var rows []struct {
+		ID    uint
+		Score float64
+	}
+	offset := (query.Page - 1) * query.PageSize
+	if err := filtered().
+		Select("id, ("+nameMatch+" * ? + "+descriptionMatch+" * ?) AS score", query.Text, nameBoost, query.Text, descriptionBoost).
+		Order("score DESC, id DESC").
+		Offset(offset).Limit(query.PageSize).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("搜索商品错误: %w", err)
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	return &product.SearchResult{Total: int(total), ProductIDs: ids}, nil
+}
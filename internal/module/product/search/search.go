@@ -0,0 +1,21 @@
+package search
+
+import (
+	"fmt"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/product"
+
+	"gorm.io/gorm"
+)
+
+// NewSearchIndex 根据配置创建商品搜索索引
+func NewSearchIndex(cfg *config.SearchConfig, db *gorm.DB) (product.SearchIndex, error) {
+	switch cfg.Driver {
+	case "", "mysql":
+		return NewMySQLIndex(db), nil
+	case "memory":
+		return NewMemoryIndex(), nil
+	default:
+		return nil, fmt.Errorf("unsupported search driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,50 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Ledger Nano X", []string{"ledger", "nano", "x"}},
+		{"USB-C 2.0", []string{"usb", "c", "2", "0"}},
+		{"硬件钱包", []string{"硬", "硬件", "件", "件钱", "钱", "钱包", "包"}},
+		{"NFT数字藏品", []string{"nft", "数", "数字", "字", "字藏", "藏", "藏品", "品"}},
+		{"冷钱包 Pro", []string{"冷", "冷钱", "钱", "钱包", "包", "pro"}},
+		{"  ,. ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Tokenize(tt.text); !slices.Equal(got, tt.want) {
+				t.Fatalf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"wallet", "wallet", 2, 0},
+		{"walet", "wallet", 2, 1},
+		{"wallte", "wallet", 2, 1}, // 相邻字符交换
+		{"wlalte", "wallet", 2, 2},
+		{"ledger", "wallet", 2, 3}, // 超过max时返回max+1
+		{"a", "abcdef", 2, 3},      // 长度差超过max
+		{"钱包", "钱袋", 1, 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, tt.max); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.max, got, tt.want)
+		}
+	}
+}
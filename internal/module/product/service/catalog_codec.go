@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"web3-ecommerce-app/internal/domain/product"
+)
+
+// parsedRow 解析后的一行数据，Err不为空表示该行无法解析
+type parsedRow struct {
+	Line int
+	Row  product.CatalogRow
+	Err  error
+}
+
+// DetectCatalogFormat 确定导入导出的文件格式，未显式指定时根据文件扩展名判断
+func DetectCatalogFormat(format string, filename string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".csv":
+			format = product.CatalogFormatCSV
+		case ".jsonl", ".ndjson":
+			format = product.CatalogFormatJSONL
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case product.CatalogFormatCSV:
+		return product.CatalogFormatCSV, nil
+	case product.CatalogFormatJSONL:
+		return product.CatalogFormatJSONL, nil
+	default:
+		return "", fmt.Errorf("不支持的文件格式: %q", format)
+	}
+}
+
+// parseCatalog 按格式解析整个文件
+// 单行的格式错误记录在对应行上，只有文件整体无法解析时才返回错误
+func parseCatalog(format string, data []byte) ([]parsedRow, error) {
+	switch format {
+	case product.CatalogFormatCSV:
+		return parseCatalogCSV(data)
+	case product.CatalogFormatJSONL:
+		return parseCatalogJSONL(data)
+	default:
+		return nil, fmt.Errorf("不支持的文件格式: %q", format)
+	}
+}
+
+// parseCatalogCSV 解析CSV文件，第一行必须是表头，列的顺序不限
+func parseCatalogCSV(data []byte) ([]parsedRow, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取表头失败: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Excel导出的UTF-8文件带有BOM
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"sku", "name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("缺少必需的列: %s", required)
+		}
+	}
+
+	var rows []parsedRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rows = append(rows, parsedRow{Line: parseErr.StartLine, Err: parseErr.Err})
+				continue
+			}
+			return nil, fmt.Errorf("读取CSV失败: %w", err)
+		}
+
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := parsedRow{Line: line}
+		row.Row = product.CatalogRow{
+			SKU:         get("sku"),
+			Name:        get("name"),
+			Description: get("description"),
+			Price:       json.Number(get("price")),
+			Status:      get("status"),
+		}
+		if v := get("stock"); v != "" {
+			stock, err := strconv.Atoi(v)
+			if err != nil {
+				row.Err = fmt.Errorf("库存必须是整数: %s", v)
+			}
+			row.Row.Stock = &stock
+		}
+		if v := get("category_id"); v != "" && row.Err == nil {
+			categoryID, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				row.Err = fmt.Errorf("分类ID必须是正整数: %s", v)
+			}
+			row.Row.CategoryID = uint(categoryID)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseCatalogJSONL 解析JSON Lines文件，每行一个JSON对象，空行会被忽略
+func parseCatalogJSONL(data []byte) ([]parsedRow, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []parsedRow
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		row := parsedRow{Line: line}
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row.Row); err != nil {
+			row.Err = fmt.Errorf("无效的JSON: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取JSONL失败: %w", err)
+	}
+	return rows, nil
+}
+
+// catalogWriter 按格式逐行写出商品目录
+type catalogWriter interface {
+	Write(row product.CatalogRow) error
+	Flush() error
+}
+
+// newCatalogWriter 创建指定格式的目录写入器
+func newCatalogWriter(format string, w io.Writer) (catalogWriter, error) {
+	switch format {
+	case product.CatalogFormatCSV:
+		cw := &csvCatalogWriter{w: csv.NewWriter(w)}
+		if err := cw.w.Write(product.CatalogColumns); err != nil {
+			return nil, err
+		}
+		return cw, nil
+	case product.CatalogFormatJSONL:
+		return &jsonlCatalogWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("不支持的文件格式: %q", format)
+	}
+}
+
+// csvCatalogWriter 写出CSV格式的商品目录
+type csvCatalogWriter struct {
+	w *csv.Writer
+}
+
+// Write 写出一行
+func (cw *csvCatalogWriter) Write(row product.CatalogRow) error {
+	stock := ""
+	if row.Stock != nil {
+		stock = strconv.Itoa(*row.Stock)
+	}
+	return cw.w.Write([]string{
+		row.SKU,
+		row.Name,
+		row.Description,
+		row.Price.String(),
+		stock,
+		strconv.FormatUint(uint64(row.CategoryID), 10),
+		row.Status,
+	})
+}
+
+// Flush 刷新缓冲区
+func (cw *csvCatalogWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonlCatalogWriter 写出JSON Lines格式的商品目录
+type jsonlCatalogWriter struct {
+	encoder *json.Encoder
+}
+
+// Write 写出一行
+func (jw *jsonlCatalogWriter) Write(row product.CatalogRow) error {
+	return jw.encoder.Encode(row)
+}
+
+// Flush JSON编码器没有缓冲区
+func (jw *jsonlCatalogWriter) Flush() error {
+	return nil
+}
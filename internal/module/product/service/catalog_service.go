@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+const (
+	// maxImportFileSize 导入文件的最大字节数
+	maxImportFileSize = 50 << 20
+	// maxReportedErrors 每个导入任务最多记录的行错误数，失败总数仍然准确统计
+	maxReportedErrors = 1000
+	// importJobRetention 已结束的导入任务保留时长
+	importJobRetention = 24 * time.Hour
+	// exportPageSize 导出时每次读取的商品数量
+	exportPageSize = 500
+)
+
+// CatalogService 商品目录批量导入导出服务接口
+type CatalogService interface {
+	// StartImport 创建后台导入任务，dryRun为true时只校验不写入
+	StartImport(ctx context.Context, format string, dryRun bool, body io.Reader) (*product.ImportJob, error)
+
+	// GetImportJob 查询导入任务进度
+	GetImportJob(ctx context.Context, id string) (*product.ImportJob, error)
+
+	// Export 以流的方式导出全部商品
+	Export(ctx context.Context, format string, w io.Writer) error
+}
+
+// DefaultCatalogService 默认商品目录服务实现
+// 导入任务保存在进程内存中，只能在接收上传的实例上查询进度
+type DefaultCatalogService struct {
+	productService ProductService
+	productRepo    product.ProductRepository
+
+	mu   sync.Mutex
+	jobs map[string]*product.ImportJob
+}
+
+// NewCatalogService 创建商品目录服务
+func NewCatalogService(productService ProductService, productRepo product.ProductRepository) CatalogService {
+	return &DefaultCatalogService{
+		productService: productService,
+		productRepo:    productRepo,
+		jobs:           make(map[string]*product.ImportJob),
+	}
+}
+
+// StartImport 读取上传的文件并在后台执行导入
+func (s *DefaultCatalogService) StartImport(ctx context.Context, format string, dryRun bool, body io.Reader) (*product.ImportJob, error) {
+	if format != product.CatalogFormatCSV && format != product.CatalogFormatJSONL {
+		return nil, apierror.NewValidationError("不支持的文件格式", format)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxImportFileSize+1))
+	if err != nil {
+		return nil, apierror.NewBadRequestError("读取导入文件失败", err.Error())
+	}
+	if len(data) > maxImportFileSize {
+		return nil, apierror.NewValidationError("导入文件过大", fmt.Sprintf("最大允许 %d 字节", maxImportFileSize))
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+	job := &product.ImportJob{
+		ID:        id,
+		Format:    format,
+		DryRun:    dryRun,
+		Status:    product.ImportJobStatusPending,
+		Errors:    []product.ImportRowError{},
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	s.purgeExpiredJobsLocked()
+	s.jobs[id] = job
+	snapshot := snapshotJob(job)
+	s.mu.Unlock()
+
+	// 请求结束后任务仍需继续执行，因此不使用请求的ctx
+	go s.runImport(context.Background(), job, data)
+
+	return snapshot, nil
+}
+
+// GetImportJob 查询导入任务进度
+func (s *DefaultCatalogService) GetImportJob(ctx context.Context, id string) (*product.ImportJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, apierror.NewNotFoundError("导入任务不存在", id)
+	}
+	return snapshotJob(job), nil
+}
+
+// Export 分页读取商品并逐行写出
+func (s *DefaultCatalogService) Export(ctx context.Context, format string, w io.Writer) error {
+	writer, err := newCatalogWriter(format, w)
+	if err != nil {
+		return apierror.NewValidationError("不支持的文件格式", format)
+	}
+
+	for page := 1; ; page++ {
+		result, err := s.productRepo.Find(ctx, product.ProductQuery{Page: page, PageSize: exportPageSize})
+		if err != nil {
+			return err
+		}
+		for i := range result.Products {
+			p := &result.Products[i]
+			stock := p.Stock
+			if err := writer.Write(product.CatalogRow{
+				SKU:         p.SKU,
+				Name:        p.Name,
+				Description: p.Description,
+				Price:       json.Number(p.RegularPrice().String()), // 导出常规价格，避免促销期间导出再导入时覆盖常规价格
+				Stock:       &stock,
+				CategoryID:  p.CategoryID,
+				Status:      p.Status,
+			}); err != nil {
+				return fmt.Errorf("写出商品失败: %w", err)
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return fmt.Errorf("写出商品失败: %w", err)
+		}
+		if len(result.Products) < exportPageSize {
+			return nil
+		}
+	}
+}
+
+// runImport 执行导入任务
+func (s *DefaultCatalogService) runImport(ctx context.Context, job *product.ImportJob, data []byte) {
+	s.updateJob(job, func(j *product.ImportJob) {
+		j.Status = product.ImportJobStatusRunning
+	})
+
+	rows, err := parseCatalog(job.Format, data)
+	if err != nil {
+		s.finishJob(job, err)
+		return
+	}
+	s.updateJob(job, func(j *product.ImportJob) {
+		j.TotalRows = len(rows)
+	})
+
+	seen := make(map[string]int, len(rows))
+	for _, row := range rows {
+		created, err := s.importRow(ctx, job.DryRun, row, seen)
+		s.updateJob(job, func(j *product.ImportJob) {
+			j.ProcessedRows++
+			switch {
+			case err != nil:
+				j.Failed++
+				if len(j.Errors) < maxReportedErrors {
+					j.Errors = append(j.Errors, product.ImportRowError{
+						Row:     row.Line,
+						SKU:     row.Row.SKU,
+						Message: errorMessage(err),
+					})
+				}
+			case created:
+				j.Created++
+			default:
+				j.Updated++
+			}
+		})
+	}
+
+	s.finishJob(job, nil)
+}
+
+// importRow 校验并写入一行，按SKU存在与否决定创建或更新
+// 返回值created表示该行是新建商品
+func (s *DefaultCatalogService) importRow(ctx context.Context, dryRun bool, row parsedRow, seen map[string]int) (bool, error) {
+	if row.Err != nil {
+		return false, row.Err
+	}
+
+	price, err := validateCatalogRow(&row.Row)
+	if err != nil {
+		return false, err
+	}
+	if line, ok := seen[row.Row.SKU]; ok {
+		return false, fmt.Errorf("SKU与第 %d 行重复", line)
+	}
+	seen[row.Row.SKU] = row.Line
+
+	existing, err := s.productRepo.FindBySKU(ctx, row.Row.SKU)
+	if err != nil {
+		var apiErr *apierror.APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != apierror.ErrorCodeNotFound {
+			return false, err
+		}
+		existing = nil
+	}
+
+	status, err := importStatus(row.Row.Status, existing)
+	if err != nil {
+		return false, err
+	}
+
+	if existing == nil {
+		if dryRun {
+			return true, nil
+		}
+		return true, s.createFromRow(ctx, &row.Row, price, status)
+	}
+
+	if dryRun {
+		if row.Row.Stock != nil && *row.Row.Stock < existing.Reserved {
+			return false, fmt.Errorf("库存不能低于已预占数量 %d", existing.Reserved)
+		}
+		return false, nil
+	}
+	return false, s.updateFromRow(ctx, existing, &row.Row, price, status)
+}
+
+// importStatus 计算导入行需要切换到的状态，返回空字符串表示不修改状态
+// 缺货由库存自动决定，导入时视同上架；导出文件中的scheduled状态原样导入时不做修改，
+// 但不能通过导入设置定时上架，因为文件中没有上架时间
+func importStatus(status string, existing *product.Product) (string, error) {
+	if status == "" {
+		return "", nil
+	}
+	if status == product.ProductStatusOutOfStock {
+		status = product.ProductStatusPublished
+	}
+
+	current := product.Product{Status: product.ProductStatusDraft}
+	if existing != nil {
+		current = *existing
+	}
+	if status == current.Status || (status == product.ProductStatusPublished && current.IsVisible()) {
+		return "", nil
+	}
+	if status == product.ProductStatusScheduled {
+		return "", fmt.Errorf("不支持通过导入设置定时上架，请在后台设置上架时间")
+	}
+	if !current.CanTransitionTo(status) {
+		return "", fmt.Errorf("商品状态不能从 %s 变更为 %s", current.Status, status)
+	}
+	return status, nil
+}
+
+// createFromRow 按导入行创建商品
+func (s *DefaultCatalogService) createFromRow(ctx context.Context, row *product.CatalogRow, price common.Money, status string) error {
+	stock := 0
+	if row.Stock != nil {
+		stock = *row.Stock
+	}
+
+	created, err := s.productService.CreateProduct(ctx, product.CreateProductInput{
+		SKU:         row.SKU,
+		Name:        row.Name,
+		Description: row.Description,
+		Price:       price,
+		Stock:       stock,
+		CategoryID:  row.CategoryID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if status != "" {
+		_, err = s.productService.ChangeProductStatus(ctx, created.ID, product.ChangeStatusInput{Status: status})
+	}
+	return err
+}
+
+// updateFromRow 按导入行更新已有商品，整行在一个事务中写入
+func (s *DefaultCatalogService) updateFromRow(ctx context.Context, existing *product.Product, row *product.CatalogRow, price common.Money, status string) error {
+	_, err := s.productService.ApplyImport(ctx, existing.ID, product.ImportUpdateInput{
+		UpdateProductInput: product.UpdateProductInput{
+			Name:        &row.Name,
+			Description: &row.Description,
+			Price:       &price,
+			CategoryID:  &row.CategoryID,
+		},
+		Stock:  row.Stock,
+		Status: status,
+	})
+	return err
+}
+
+// validateCatalogRow 校验导入行的字段，返回解析后的价格
+func validateCatalogRow(row *product.CatalogRow) (common.Money, error) {
+	switch {
+	case row.SKU == "":
+		return 0, fmt.Errorf("SKU不能为空")
+	case len(row.SKU) > 64:
+		return 0, fmt.Errorf("SKU不能超过64个字符")
+	case row.Name == "":
+		return 0, fmt.Errorf("名称不能为空")
+	case len([]rune(row.Name)) > 200:
+		return 0, fmt.Errorf("名称不能超过200个字符")
+	case row.Stock != nil && *row.Stock < 0:
+		return 0, fmt.Errorf("库存不能为负数")
+	case row.Status != "" && !product.IsValidProductStatus(row.Status):
+		return 0, fmt.Errorf("无效的商品状态: %s", row.Status)
+	}
+
+	price, err := common.ParseMoney(row.Price.String())
+	if err != nil {
+		return 0, err
+	}
+	if price < 0 {
+		return 0, fmt.Errorf("价格不能为负数")
+	}
+	return price, nil
+}
+
+// updateJob 在锁内修改任务状态
+func (s *DefaultCatalogService) updateJob(job *product.ImportJob, update func(j *product.ImportJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update(job)
+}
+
+// finishJob 结束任务，err不为空表示任务整体失败
+func (s *DefaultCatalogService) finishJob(job *product.ImportJob, err error) {
+	s.updateJob(job, func(j *product.ImportJob) {
+		now := time.Now()
+		j.FinishedAt = &now
+		if err != nil {
+			j.Status = product.ImportJobStatusFailed
+			j.Error = err.Error()
+			return
+		}
+		j.Status = product.ImportJobStatusCompleted
+	})
+
+	if err != nil {
+		log.Printf("商品导入任务 %s 失败: %v", job.ID, err)
+	}
+}
+
+// purgeExpiredJobsLocked 清理结束超过保留时长的任务，调用方需持有锁
+func (s *DefaultCatalogService) purgeExpiredJobsLocked() {
+	cutoff := time.Now().Add(-importJobRetention)
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// snapshotJob 复制任务状态，避免调用方读取时与后台任务并发修改
+func snapshotJob(job *product.ImportJob) *product.ImportJob {
+	snapshot := *job
+	snapshot.Errors = make([]product.ImportRowError, len(job.Errors))
+	copy(snapshot.Errors, job.Errors)
+	return &snapshot
+}
+
+// errorMessage 将错误转换为面向用户的说明
+func errorMessage(err error) string {
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Detail != "" {
+			return apiErr.Message + ": " + apiErr.Detail
+		}
+		return apiErr.Message
+	}
+	return err.Error()
+}
+
+// newJobID 生成随机的任务ID
+func newJobID() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成任务ID失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
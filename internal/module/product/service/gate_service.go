@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/user"
+	"web3-ecommerce-app/internal/platform/chain"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// GateService 商品持币门槛服务接口
+type GateService interface {
+	// CreateRule 为商品添加持币门槛规则
+	CreateRule(ctx context.Context, productID uint, input product.CreateGateRuleInput) (*product.GateRule, error)
+
+	// ListRules 获取商品的持币门槛规则
+	ListRules(ctx context.Context, productID uint) ([]product.GateRule, error)
+
+	// DeleteRule 删除商品的持币门槛规则
+	DeleteRule(ctx context.Context, productID uint, ruleID uint) error
+
+	// Evaluate 使用短期缓存的链上数据验证用户绑定的钱包，用于商品页展示
+	Evaluate(ctx context.Context, userID uint, productID uint) (*product.GateResult, error)
+
+	// CheckPurchase 验证用户是否可以购买商品并返回适用的价格，不满足门槛时返回错误
+	// live为true时绕过缓存直接查询链上状态，下单支付时必须使用
+	CheckPurchase(ctx context.Context, userID uint, productID uint, live bool) (*product.GateResult, error)
+}
+
+// DefaultGateService 默认商品持币门槛服务实现
+type DefaultGateService struct {
+	gateRepo    product.GateRuleRepository
+	productRepo product.ProductRepository
+	userRepo    user.UserRepository
+	reader      chain.Reader
+	cached      chain.Reader
+}
+
+// NewGateService 创建商品持币门槛服务，链上查询结果缓存cacheTTL时长
+func NewGateService(gateRepo product.GateRuleRepository, productRepo product.ProductRepository, userRepo user.UserRepository, reader chain.Reader, cacheTTL time.Duration) GateService {
+	return &DefaultGateService{
+		gateRepo:    gateRepo,
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		reader:      reader,
+		cached:      chain.NewCachedReader(reader, cacheTTL),
+	}
+}
+
+// CreateRule 为商品添加持币门槛规则
+func (s *DefaultGateService) CreateRule(ctx context.Context, productID uint, input product.CreateGateRuleInput) (*product.GateRule, error) {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	rule := &product.GateRule{
+		ProductID:  productID,
+		Effect:     input.Effect,
+		ChainID:    input.ChainID,
+		Standard:   input.Standard,
+		Contract:   chain.NormalizeAddress(input.Contract),
+		TokenIDMin: input.TokenIDMin,
+		TokenIDMax: input.TokenIDMax,
+		MinBalance: input.MinBalance,
+	}
+	if rule.MinBalance == "" {
+		rule.MinBalance = "1"
+	}
+	if input.Effect == product.GateEffectPrice && input.HolderPrice != nil {
+		rule.HolderPrice = *input.HolderPrice
+	}
+
+	if err := validateGateRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.gateRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// validateGateRule 校验代币ID范围和持有数量
+func validateGateRule(rule *product.GateRule) error {
+	minBalance, ok := new(big.Int).SetString(rule.MinBalance, 10)
+	if !ok {
+		return apierror.NewValidationError("无效的持有数量", rule.MinBalance)
+	}
+	// 持有数量不大于0时任何钱包都满足规则
+	if minBalance.Sign() <= 0 {
+		return apierror.NewValidationError("无效的持有数量", "持有数量必须大于0")
+	}
+	rule.MinBalance = minBalance.String()
+
+	// 只设置一端时视为单个代币
+	if rule.TokenIDMin == "" {
+		rule.TokenIDMin = rule.TokenIDMax
+	}
+	if rule.TokenIDMax == "" {
+		rule.TokenIDMax = rule.TokenIDMin
+	}
+
+	switch {
+	case rule.Standard == product.TokenStandardERC20 && rule.HasTokenRange():
+		return apierror.NewValidationError("无效的持币规则", "ERC-20代币没有代币ID")
+	case rule.Standard == product.TokenStandardERC1155 && !rule.HasTokenRange():
+		return apierror.NewValidationError("无效的持币规则", "ERC-1155规则必须指定代币ID")
+	case !rule.HasTokenRange():
+		return nil
+	}
+
+	minID, maxID, err := tokenRange(rule)
+	if err != nil {
+		return apierror.NewValidationError("无效的代币ID", err.Error())
+	}
+	if minID.Cmp(maxID) > 0 {
+		return apierror.NewValidationError("无效的代币ID", "起始代币ID不能大于结束代币ID")
+	}
+	span := new(big.Int).Sub(maxID, minID)
+	if span.Cmp(big.NewInt(product.MaxGateTokenRange-1)) > 0 {
+		return apierror.NewValidationError("代币ID范围过大", fmt.Sprintf("最多 %d 个代币", product.MaxGateTokenRange))
+	}
+	return nil
+}
+
+// tokenRange 解析规则的代币ID范围
+func tokenRange(rule *product.GateRule) (*big.Int, *big.Int, error) {
+	minID, ok := new(big.Int).SetString(rule.TokenIDMin, 10)
+	if !ok {
+		return nil, nil, fmt.Errorf("无效的代币ID: %s", rule.TokenIDMin)
+	}
+	maxID, ok := new(big.Int).SetString(rule.TokenIDMax, 10)
+	if !ok {
+		return nil, nil, fmt.Errorf("无效的代币ID: %s", rule.TokenIDMax)
+	}
+	return minID, maxID, nil
+}
+
+// ListRules 获取商品的持币门槛规则
+func (s *DefaultGateService) ListRules(ctx context.Context, productID uint) ([]product.GateRule, error) {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.gateRepo.FindByProduct(ctx, productID)
+}
+
+// DeleteRule 删除商品的持币门槛规则
+func (s *DefaultGateService) DeleteRule(ctx context.Context, productID uint, ruleID uint) error {
+	rule, err := s.gateRepo.FindByID(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+	if rule.ProductID != productID {
+		return apierror.NewNotFoundError("持币规则不存在", fmt.Sprintf("商品ID: %d, 规则ID: %d", productID, ruleID))
+	}
+	return s.gateRepo.Delete(ctx, ruleID)
+}
+
+// Evaluate 使用缓存的链上数据验证用户是否满足商品的持币门槛
+func (s *DefaultGateService) Evaluate(ctx context.Context, userID uint, productID uint) (*product.GateResult, error) {
+	return s.evaluate(ctx, userID, productID, s.cached)
+}
+
+// CheckPurchase 验证用户是否可以购买商品
+func (s *DefaultGateService) CheckPurchase(ctx context.Context, userID uint, productID uint, live bool) (*product.GateResult, error) {
+	reader := s.cached
+	if live {
+		reader = s.reader
+	}
+
+	result, err := s.evaluate(ctx, userID, productID, reader)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Allowed {
+		if !result.WalletLinked {
+			return nil, apierror.NewForbiddenError("该商品仅限持有者购买", "请先绑定钱包")
+		}
+		return nil, apierror.NewForbiddenError("该商品仅限持有者购买", "绑定的钱包未持有指定代币")
+	}
+	return result, nil
+}
+
+// evaluate 逐条验证商品的持币规则
+// 没有access规则的商品对所有人开放，price规则只影响价格
+func (s *DefaultGateService) evaluate(ctx context.Context, userID uint, productID uint, reader chain.Reader) (*product.GateResult, error) {
+	productEntity, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.gateRepo.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	userEntity, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet := userEntity.WalletAddr
+	result := &product.GateResult{
+		ProductID:    productID,
+		WalletLinked: chain.IsAddress(wallet),
+		Price:        productEntity.Price,
+		Rules:        make([]product.GateRuleCheck, 0, len(rules)),
+	}
+
+	anyAccess := false
+	for _, rule := range rules {
+		satisfied := false
+		if result.WalletLinked {
+			satisfied, err = s.holds(ctx, reader, &rule, wallet)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result.Rules = append(result.Rules, product.GateRuleCheck{GateRule: rule, Satisfied: satisfied})
+
+		switch rule.Effect {
+		case product.GateEffectAccess:
+			result.Gated = true
+			anyAccess = anyAccess || satisfied
+		case product.GateEffectPrice:
+			if satisfied && rule.HolderPrice < result.Price {
+				result.Price = rule.HolderPrice
+			}
+		}
+	}
+	result.Allowed = !result.Gated || anyAccess
+
+	return result, nil
+}
+
+// holds 判断钱包是否满足规则要求的持有数量
+func (s *DefaultGateService) holds(ctx context.Context, reader chain.Reader, rule *product.GateRule, wallet string) (bool, error) {
+	minBalance, ok := new(big.Int).SetString(rule.MinBalance, 10)
+	if !ok {
+		return false, fmt.Errorf("持币规则 %d 的持有数量无效: %s", rule.ID, rule.MinBalance)
+	}
+	if minBalance.Sign() <= 0 {
+		return false, fmt.Errorf("持币规则 %d 的持有数量必须大于0: %s", rule.ID, rule.MinBalance)
+	}
+
+	if !rule.HasTokenRange() {
+		balance, err := reader.BalanceOf(ctx, rule.ChainID, rule.Contract, wallet)
+		if err != nil {
+			if errors.Is(err, chain.ErrExecutionReverted) {
+				return false, nil
+			}
+			return false, fmt.Errorf("查询链上持仓失败: %w", err)
+		}
+		return balance.Cmp(minBalance) >= 0, nil
+	}
+
+	minID, maxID, err := tokenRange(rule)
+	if err != nil {
+		return false, err
+	}
+
+	// 逐个查询范围内的代币，持有数量达标后立即返回
+	held := new(big.Int)
+	for id := minID; id.Cmp(maxID) <= 0; id = new(big.Int).Add(id, big.NewInt(1)) {
+		switch rule.Standard {
+		case product.TokenStandardERC721:
+			owner, err := reader.OwnerOf(ctx, rule.ChainID, rule.Contract, id)
+			if errors.Is(err, chain.ErrExecutionReverted) {
+				continue
+			}
+			if err != nil {
+				return false, fmt.Errorf("查询链上持仓失败: %w", err)
+			}
+			if chain.NormalizeAddress(owner) == chain.NormalizeAddress(wallet) {
+				held.Add(held, big.NewInt(1))
+			}
+		case product.TokenStandardERC1155:
+			balance, err := reader.BalanceOfToken(ctx, rule.ChainID, rule.Contract, wallet, id)
+			if errors.Is(err, chain.ErrExecutionReverted) {
+				continue
+			}
+			if err != nil {
+				return false, fmt.Errorf("查询链上持仓失败: %w", err)
+			}
+			held.Add(held, balance)
+		}
+		if held.Cmp(minBalance) >= 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/user"
+	"web3-ecommerce-app/internal/platform/chain"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+const (
+	testWallet   = "0x1111111111111111111111111111111111111111"
+	otherWallet  = "0x2222222222222222222222222222222222222222"
+	testContract = "0xAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	testChainID  = int64(1)
+)
+
+// gateProductRepo 只实现门槛验证用到的FindByID
+type gateProductRepo struct {
+	product.ProductRepository
+	products map[uint]*product.Product
+}
+
+func (r *gateProductRepo) FindByID(ctx context.Context, id uint) (*product.Product, error) {
+	if p, ok := r.products[id]; ok {
+		return p, nil
+	}
+	return nil, apierror.NewNotFoundError("商品不存在", "")
+}
+
+type gateUserRepo struct {
+	user.UserRepository
+	users map[uint]*user.User
+}
+
+func (r *gateUserRepo) FindByID(ctx context.Context, id uint) (*user.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, apierror.NewNotFoundError("用户不存在", "")
+}
+
+type gateRuleRepo struct {
+	rules []product.GateRule
+}
+
+func (r *gateRuleRepo) FindByID(ctx context.Context, id uint) (*product.GateRule, error) {
+	for i := range r.rules {
+		if r.rules[i].ID == id {
+			return &r.rules[i], nil
+		}
+	}
+	return nil, apierror.NewNotFoundError("持币规则不存在", "")
+}
+
+func (r *gateRuleRepo) FindByProduct(ctx context.Context, productID uint) ([]product.GateRule, error) {
+	var rules []product.GateRule
+	for _, rule := range r.rules {
+		if rule.ProductID == productID {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (r *gateRuleRepo) Create(ctx context.Context, rule *product.GateRule) error {
+	rule.ID = uint(len(r.rules) + 1)
+	r.rules = append(r.rules, *rule)
+	return nil
+}
+
+func (r *gateRuleRepo) Delete(ctx context.Context, id uint) error {
+	return nil
+}
+
+func newTestGateService(rules []product.GateRule, wallet string, reader chain.Reader) GateService {
+	for i := range rules {
+		rules[i].ID = uint(i + 1)
+		rules[i].ProductID = 1
+		rules[i].ChainID = testChainID
+		rules[i].Contract = chain.NormalizeAddress(testContract)
+		if rules[i].MinBalance == "" {
+			rules[i].MinBalance = "1"
+		}
+	}
+	return NewGateService(
+		&gateRuleRepo{rules: rules},
+		&gateProductRepo{products: map[uint]*product.Product{1: {ID: 1, Price: common.Money(1000)}}},
+		&gateUserRepo{users: map[uint]*user.User{1: {ID: 1, WalletAddr: wallet}}},
+		reader,
+		time.Minute,
+	)
+}
+
+func TestGateCheckPurchase(t *testing.T) {
+	erc20 := func(effect string, minBalance string, holderPrice common.Money) product.GateRule {
+		return product.GateRule{Effect: effect, Standard: product.TokenStandardERC20, MinBalance: minBalance, HolderPrice: holderPrice}
+	}
+
+	tests := []struct {
+		name      string
+		rules     []product.GateRule
+		wallet    string
+		setup     func(f *chain.FakeReader)
+		wantErr   string
+		wantPrice common.Money
+		wantGated bool
+	}{
+		{
+			name:      "没有规则的商品对所有人开放",
+			wallet:    "",
+			wantPrice: 1000,
+		},
+		{
+			name:    "未绑定钱包不能购买限定商品",
+			rules:   []product.GateRule{erc20(product.GateEffectAccess, "1", 0)},
+			wallet:  "",
+			wantErr: "请先绑定钱包",
+		},
+		{
+			name:   "ERC-20余额达到门槛",
+			rules:  []product.GateRule{erc20(product.GateEffectAccess, "100", 0)},
+			wallet: testWallet,
+			setup: func(f *chain.FakeReader) {
+				f.SetBalance(testChainID, testContract, testWallet, big.NewInt(100))
+			},
+			wantPrice: 1000,
+			wantGated: true,
+		},
+		{
+			name:   "ERC-20余额不足",
+			rules:  []product.GateRule{erc20(product.GateEffectAccess, "100", 0)},
+			wallet: testWallet,
+			setup: func(f *chain.FakeReader) {
+				f.SetBalance(testChainID, testContract, testWallet, big.NewInt(99))
+			},
+			wantErr: "未持有指定代币",
+		},
+		{
+			name: "满足任意一条access规则即可",
+			rules: []product.GateRule{
+				erc20(product.GateEffectAccess, "100", 0),
+				{Effect: product.GateEffectAccess, Standard: product.TokenStandardERC721, TokenIDMin: "5", TokenIDMax: "5"},
+			},
+			wallet: testWallet,
+			setup: func(f *chain.FakeReader) {
+				f.SetOwner(testChainID, testContract, big.NewInt(5), testWallet)
+			},
+			wantPrice: 1000,
+			wantGated: true,
+		},
+		{
+			name: "满足多条price规则时取最低价",
+			rules: []product.GateRule{
+				erc20(product.GateEffectPrice, "1", 800),
+				erc20(product.GateEffectPrice, "10", 600),
+				erc20(product.GateEffectPrice, "1000", 100),
+			},
+			wallet: testWallet,
+			setup: func(f *chain.FakeReader) {
+				f.SetBalance(testChainID, testContract, testWallet, big.NewInt(10))
+			},
+			wantPrice: 600,
+		},
+		{
+			name:      "专属价格高于原价时不生效",
+			rules:     []product.GateRule{erc20(product.GateEffectPrice, "1", 1200)},
+			wallet:    testWallet,
+			setup:     func(f *chain.FakeReader) { f.SetBalance(testChainID, testContract, testWallet, big.NewInt(1)) },
+			wantPrice: 1000,
+		},
+		{
+			name:      "不满足price规则按原价购买",
+			rules:     []product.GateRule{erc20(product.GateEffectPrice, "1", 500)},
+			wallet:    testWallet,
+			wantPrice: 1000,
+		},
+		{
+			name:   "ERC-721范围内持有数量不足",
+			rules:  []product.GateRule{{Effect: product.GateEffectAccess, Standard: product.TokenStandardERC721, TokenIDMin: "1", TokenIDMax: "3", MinBalance: "2"}},
+			wallet: testWallet,
+			setup: func(f *chain.FakeReader) {
+				// 代币2不存在，代币3属于其他钱包
+				f.SetOwner(testChainID, testContract, big.NewInt(1), testWallet)
+				f.SetOwner(testChainID, testContract, big.NewInt(3), otherWallet)
+			},
+			wantErr: "未持有指定代币",
+		},
+		{
+			name:   "ERC-721所有者地址大小写不敏感",
+			rules:  []product.GateRule{{Effect: product.GateEffectAccess, Standard: product.TokenStandardERC721, TokenIDMin: "1", TokenIDMax: "3", MinBalance: "2"}},
+			wallet: testWallet,
+			setup: func(f *chain.FakeReader) {
+				f.SetOwner(testChainID, testContract, big.NewInt(1), testWallet)
+				f.SetOwner(testChainID, testContract, big.NewInt(3), "0x"+strings.ToUpper(testWallet[2:]))
+			},
+			wantPrice: 1000,
+			wantGated: true,
+		},
+		{
+			name:   "ERC-1155累加范围内各代币的数量",
+			rules:  []product.GateRule{{Effect: product.GateEffectAccess, Standard: product.TokenStandardERC1155, TokenIDMin: "10", TokenIDMax: "12", MinBalance: "5"}},
+			wallet: testWallet,
+			setup: func(f *chain.FakeReader) {
+				f.SetTokenBalance(testChainID, testContract, testWallet, big.NewInt(10), big.NewInt(2))
+				f.SetTokenBalance(testChainID, testContract, testWallet, big.NewInt(12), big.NewInt(3))
+			},
+			wantPrice: 1000,
+			wantGated: true,
+		},
+		{
+			name:   "其他链上的持仓不计入",
+			rules:  []product.GateRule{erc20(product.GateEffectAccess, "1", 0)},
+			wallet: testWallet,
+			setup: func(f *chain.FakeReader) {
+				f.SetBalance(testChainID+1, testContract, testWallet, big.NewInt(1))
+			},
+			wantErr: "未持有指定代币",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := chain.NewFakeReader()
+			if tt.setup != nil {
+				tt.setup(reader)
+			}
+			s := newTestGateService(tt.rules, tt.wallet, reader)
+
+			result, err := s.CheckPurchase(context.Background(), 1, 1, true)
+			if tt.wantErr != "" {
+				var apiErr *apierror.APIError
+				if !errors.As(err, &apiErr) || apiErr.Code != apierror.ErrorCodeForbidden || !strings.Contains(apiErr.Detail, tt.wantErr) {
+					t.Fatalf("CheckPurchase() error = %v, want forbidden %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckPurchase() error = %v", err)
+			}
+			if result.Price != tt.wantPrice || result.Gated != tt.wantGated || !result.Allowed {
+				t.Fatalf("CheckPurchase() = price %d gated %v allowed %v, want price %d gated %v",
+					result.Price, result.Gated, result.Allowed, tt.wantPrice, tt.wantGated)
+			}
+			if len(result.Rules) != len(tt.rules) {
+				t.Fatalf("len(Rules) = %d, want %d", len(result.Rules), len(tt.rules))
+			}
+		})
+	}
+}
+
+func TestGateLiveCheckBypassesCache(t *testing.T) {
+	reader := chain.NewFakeReader()
+	reader.SetBalance(testChainID, testContract, testWallet, big.NewInt(1))
+	s := newTestGateService([]product.GateRule{
+		{Effect: product.GateEffectAccess, Standard: product.TokenStandardERC20},
+	}, testWallet, reader)
+	ctx := context.Background()
+
+	if result, err := s.Evaluate(ctx, 1, 1); err != nil || !result.Allowed {
+		t.Fatalf("Evaluate() = %+v, %v", result, err)
+	}
+
+	// 用户转走代币后，缓存的结果仍然允许购买，但实时验证必须拒绝
+	reader.SetBalance(testChainID, testContract, testWallet, big.NewInt(0))
+	if result, err := s.Evaluate(ctx, 1, 1); err != nil || !result.Allowed {
+		t.Fatalf("cached Evaluate() = %+v, %v", result, err)
+	}
+	if _, err := s.CheckPurchase(ctx, 1, 1, false); err != nil {
+		t.Fatalf("cached CheckPurchase() error = %v", err)
+	}
+	if _, err := s.CheckPurchase(ctx, 1, 1, true); err == nil {
+		t.Fatal("live CheckPurchase() allowed a wallet that no longer holds the token")
+	}
+}
+
+func TestValidateGateRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    product.GateRule
+		wantErr bool
+		wantMin string
+		wantMax string
+		wantBal string
+	}{
+		{name: "ERC-20不限代币ID", rule: product.GateRule{Standard: product.TokenStandardERC20, MinBalance: "1"}},
+		{name: "ERC-20不能指定代币ID", rule: product.GateRule{Standard: product.TokenStandardERC20, MinBalance: "1", TokenIDMin: "1"}, wantErr: true},
+		{name: "ERC-1155必须指定代币ID", rule: product.GateRule{Standard: product.TokenStandardERC1155, MinBalance: "1"}, wantErr: true},
+		{name: "只设置结束ID视为单个代币", rule: product.GateRule{Standard: product.TokenStandardERC721, MinBalance: "1", TokenIDMax: "7"}, wantMin: "7", wantMax: "7"},
+		{name: "起始ID大于结束ID", rule: product.GateRule{Standard: product.TokenStandardERC721, MinBalance: "1", TokenIDMin: "9", TokenIDMax: "8"}, wantErr: true},
+		{name: "范围恰好为上限", rule: product.GateRule{Standard: product.TokenStandardERC721, MinBalance: "1", TokenIDMin: "1", TokenIDMax: "100"}, wantMin: "1", wantMax: "100"},
+		{name: "范围超过上限", rule: product.GateRule{Standard: product.TokenStandardERC721, MinBalance: "1", TokenIDMin: "1", TokenIDMax: "101"}, wantErr: true},
+		{name: "持有数量为0", rule: product.GateRule{Standard: product.TokenStandardERC20, MinBalance: "0"}, wantErr: true},
+		{name: "持有数量不是整数", rule: product.GateRule{Standard: product.TokenStandardERC20, MinBalance: "1.5"}, wantErr: true},
+		{name: "持有数量为负数", rule: product.GateRule{Standard: product.TokenStandardERC20, MinBalance: "-1"}, wantErr: true},
+		{name: "持有数量为00", rule: product.GateRule{Standard: product.TokenStandardERC20, MinBalance: "00"}, wantErr: true},
+		{name: "持有数量为+0", rule: product.GateRule{Standard: product.TokenStandardERC20, MinBalance: "+0"}, wantErr: true},
+		{name: "持有数量保存为规范形式", rule: product.GateRule{Standard: product.TokenStandardERC20, MinBalance: "+007"}, wantBal: "7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			err := validateGateRule(&rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateGateRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (rule.TokenIDMin != tt.wantMin || rule.TokenIDMax != tt.wantMax) {
+				t.Fatalf("range = %q-%q, want %q-%q", rule.TokenIDMin, rule.TokenIDMax, tt.wantMin, tt.wantMax)
+			}
+			if tt.wantBal != "" && rule.MinBalance != tt.wantBal {
+				t.Fatalf("min balance = %q, want %q", rule.MinBalance, tt.wantBal)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/platform/blobstore"
+	"web3-ecommerce-app/pkg/apierror"
+	"web3-ecommerce-app/pkg/imaging"
+)
+
+// ImageService 商品图片服务接口
+type ImageService interface {
+	// UploadImage 上传商品图片并生成缩略图
+	UploadImage(ctx context.Context, productID uint, body io.Reader) (*product.Image, error)
+
+	// ListImages 获取商品的全部图片
+	ListImages(ctx context.Context, productID uint) ([]product.Image, error)
+
+	// ReorderImages 调整商品图片顺序
+	ReorderImages(ctx context.Context, productID uint, imageIDs []uint) ([]product.Image, error)
+
+	// SetPrimaryImage 设置商品主图
+	SetPrimaryImage(ctx context.Context, productID uint, imageID uint) error
+
+	// DeleteImage 删除商品图片及其缩略图
+	DeleteImage(ctx context.Context, productID uint, imageID uint) error
+}
+
+// DefaultImageService 默认商品图片服务实现
+type DefaultImageService struct {
+	productRepo product.ProductRepository
+	imageRepo   product.ImageRepository
+	store       blobstore.BlobStore
+	imageConfig *config.ImageConfig
+}
+
+// NewImageService 创建商品图片服务
+func NewImageService(productRepo product.ProductRepository, imageRepo product.ImageRepository, store blobstore.BlobStore, imageConfig *config.ImageConfig) ImageService {
+	return &DefaultImageService{
+		productRepo: productRepo,
+		imageRepo:   imageRepo,
+		store:       store,
+		imageConfig: imageConfig,
+	}
+}
+
+// UploadImage 上传商品图片
+// 图片类型通过文件内容嗅探确定，原图和各尺寸缩略图都写入对象存储
+func (s *DefaultImageService) UploadImage(ctx context.Context, productID uint, body io.Reader) (*product.Image, error) {
+	productEntity, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 多读一个字节用于判断是否超出大小限制
+	data, err := io.ReadAll(io.LimitReader(body, s.imageConfig.MaxSize+1))
+	if err != nil {
+		return nil, apierror.NewBadRequestError("读取图片失败", err.Error())
+	}
+	if int64(len(data)) > s.imageConfig.MaxSize {
+		return nil, apierror.NewValidationError("图片过大", fmt.Sprintf("最大允许 %d 字节", s.imageConfig.MaxSize))
+	}
+
+	mime, err := imaging.DetectMIME(data)
+	if err != nil {
+		return nil, apierror.NewValidationError("不支持的图片格式", err.Error())
+	}
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return nil, apierror.NewValidationError("无效的图片", err.Error())
+	}
+
+	baseKey, err := newImageKey(productID)
+	if err != nil {
+		return nil, err
+	}
+	key := baseKey + imaging.Extension(mime)
+	if err := s.store.Put(ctx, key, bytes.NewReader(data), mime); err != nil {
+		return nil, fmt.Errorf("保存图片失败: %w", err)
+	}
+	uploadedKeys := []string{key}
+
+	// 按配置的宽度生成缩略图，原图比缩略图还小时不生成
+	thumbnails := make([]product.Thumbnail, 0, len(s.imageConfig.ThumbnailWidths))
+	for _, width := range s.imageConfig.ThumbnailWidths {
+		if width <= 0 || width >= img.Bounds().Dx() {
+			continue
+		}
+		resized := imaging.Resize(img, width)
+		encoded, thumbMIME, err := imaging.Encode(resized, mime)
+		if err != nil {
+			s.cleanup(ctx, uploadedKeys)
+			return nil, fmt.Errorf("生成缩略图失败: %w", err)
+		}
+
+		thumbKey := fmt.Sprintf("%s_w%d%s", baseKey, width, imaging.Extension(thumbMIME))
+		if err := s.store.Put(ctx, thumbKey, bytes.NewReader(encoded), thumbMIME); err != nil {
+			s.cleanup(ctx, uploadedKeys)
+			return nil, fmt.Errorf("保存缩略图失败: %w", err)
+		}
+		uploadedKeys = append(uploadedKeys, thumbKey)
+
+		thumbnails = append(thumbnails, product.Thumbnail{
+			Key:    thumbKey,
+			URL:    s.store.URL(thumbKey),
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+		})
+	}
+
+	// 新图片排在最后，商品的第一张图片自动成为主图
+	image := &product.Image{
+		ProductID:   productID,
+		Key:         key,
+		URL:         s.store.URL(key),
+		ContentType: mime,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		Size:        int64(len(data)),
+		SortOrder:   len(productEntity.Images),
+		IsPrimary:   len(productEntity.Images) == 0,
+		Thumbnails:  thumbnails,
+	}
+	if err := s.imageRepo.Create(ctx, image); err != nil {
+		s.cleanup(ctx, uploadedKeys)
+		return nil, err
+	}
+
+	return image, nil
+}
+
+// ListImages 获取商品的全部图片
+func (s *DefaultImageService) ListImages(ctx context.Context, productID uint) ([]product.Image, error) {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.imageRepo.FindByProduct(ctx, productID)
+}
+
+// ReorderImages 调整商品图片顺序
+func (s *DefaultImageService) ReorderImages(ctx context.Context, productID uint, imageIDs []uint) ([]product.Image, error) {
+	if err := s.imageRepo.Reorder(ctx, productID, imageIDs); err != nil {
+		return nil, err
+	}
+	return s.imageRepo.FindByProduct(ctx, productID)
+}
+
+// SetPrimaryImage 设置商品主图
+func (s *DefaultImageService) SetPrimaryImage(ctx context.Context, productID uint, imageID uint) error {
+	return s.imageRepo.SetPrimary(ctx, productID, imageID)
+}
+
+// DeleteImage 删除商品图片
+// 删除的是主图时，由排在最前的图片接替
+func (s *DefaultImageService) DeleteImage(ctx context.Context, productID uint, imageID uint) error {
+	image, err := s.imageRepo.FindByID(ctx, imageID)
+	if err != nil {
+		return err
+	}
+	if image.ProductID != productID {
+		return apierror.NewNotFoundError("图片不存在", fmt.Sprintf("商品ID: %d, 图片ID: %d", productID, imageID))
+	}
+
+	if err := s.imageRepo.Delete(ctx, imageID); err != nil {
+		return err
+	}
+
+	keys := []string{image.Key}
+	for _, t := range image.Thumbnails {
+		keys = append(keys, t.Key)
+	}
+	s.cleanup(ctx, keys)
+
+	if image.IsPrimary {
+		remaining, err := s.imageRepo.FindByProduct(ctx, productID)
+		if err != nil {
+			return err
+		}
+		if len(remaining) > 0 {
+			return s.imageRepo.SetPrimary(ctx, productID, remaining[0].ID)
+		}
+	}
+	return nil
+}
+
+// cleanup 尽力删除对象存储中的文件，失败只记录日志
+func (s *DefaultImageService) cleanup(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, blobstore.ErrNotFound) {
+			log.Printf("删除图片文件 %s 失败: %v", key, err)
+		}
+	}
+}
+
+// newImageKey 生成随机的图片存储key(不含扩展名)
+func newImageKey(productID uint) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成图片key失败: %w", err)
+	}
+	return fmt.Sprintf("products/%d/%s", productID, hex.EncodeToString(buf)), nil
+}
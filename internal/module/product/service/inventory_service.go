@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// releaseBatchSize 每次释放过期预占的最大数量
+const releaseBatchSize = 100
+
+// InventoryService 库存预占服务接口
+type InventoryService interface {
+	// Reserve 下单时为订单预占库存，预占有效期与支付窗口一致
+	Reserve(ctx context.Context, orderID uint, items []product.ReservationItem) ([]product.Reservation, error)
+
+	// Confirm 支付确认后将预占转为实际扣减
+	Confirm(ctx context.Context, orderID uint) error
+
+	// Release 订单取消或超时后释放预占
+	Release(ctx context.Context, orderID uint) error
+
+	// ReleaseExpired 释放所有已过期的预占，由定时任务调用
+	ReleaseExpired(ctx context.Context) error
+}
+
+// DefaultInventoryService 默认库存预占服务实现
+type DefaultInventoryService struct {
+	inventoryRepo  product.InventoryRepository
+	productService ProductService
+	paymentConfig  *config.PaymentConfig
+}
+
+// NewInventoryService 创建库存预占服务
+func NewInventoryService(inventoryRepo product.InventoryRepository, productService ProductService, paymentConfig *config.PaymentConfig) InventoryService {
+	return &DefaultInventoryService{
+		inventoryRepo:  inventoryRepo,
+		productService: productService,
+		paymentConfig:  paymentConfig,
+	}
+}
+
+// Reserve 为订单预占库存
+func (s *DefaultInventoryService) Reserve(ctx context.Context, orderID uint, items []product.ReservationItem) ([]product.Reservation, error) {
+	if len(items) == 0 {
+		return nil, apierror.NewValidationError("预占库存失败", "商品列表不能为空")
+	}
+
+	expiresAt := time.Now().Add(s.paymentConfig.PaymentWindow)
+	reservations, err := s.inventoryRepo.Reserve(ctx, orderID, items, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	s.syncStockStatus(ctx, reservations)
+
+	return reservations, nil
+}
+
+// Confirm 将订单的预占转为实际扣减
+// 实际库存和预占数量同时减少，可售库存不变，因此无需同步商品状态
+func (s *DefaultInventoryService) Confirm(ctx context.Context, orderID uint) error {
+	return s.inventoryRepo.ConfirmByOrder(ctx, orderID)
+}
+
+// Release 释放订单的预占
+func (s *DefaultInventoryService) Release(ctx context.Context, orderID uint) error {
+	if err := s.inventoryRepo.ReleaseByOrder(ctx, orderID); err != nil {
+		return err
+	}
+
+	reservations, err := s.inventoryRepo.FindByOrder(ctx, orderID)
+	if err != nil {
+		log.Printf("查询订单 %d 的库存预占失败: %v", orderID, err)
+		return nil
+	}
+	s.syncStockStatus(ctx, reservations)
+
+	return nil
+}
+
+// ReleaseExpired 分批释放已过期的预占
+func (s *DefaultInventoryService) ReleaseExpired(ctx context.Context) error {
+	total := 0
+	for {
+		released, err := s.inventoryRepo.ReleaseExpired(ctx, time.Now(), releaseBatchSize)
+		total += len(released)
+		s.syncStockStatus(ctx, released)
+		if err != nil {
+			return err
+		}
+		if len(released) < releaseBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("已释放 %d 条过期库存预占", total)
+	}
+	return nil
+}
+
+// syncStockStatus 预占变化后同步相关商品的上架/缺货状态
+// 同步失败不影响预占本身，商品状态由定时任务兜底修正
+func (s *DefaultInventoryService) syncStockStatus(ctx context.Context, reservations []product.Reservation) {
+	if len(reservations) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(reservations))
+	for _, r := range reservations {
+		ids = append(ids, r.ProductID)
+	}
+	if err := s.productService.SyncStockStatus(ctx, ids...); err != nil {
+		log.Printf("同步商品库存状态失败: %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// priceScheduleBatchSize 每次处理到期调价的最大数量
+const priceScheduleBatchSize = 100
+
+// maxPriceHistoryDays 价格历史查询的最大天数
+const maxPriceHistoryDays = 365
+
+// PriceService 商品价格服务接口
+type PriceService interface {
+	// CreateSchedule 创建定时调价或限时促销，生效时间已到的立即执行
+	CreateSchedule(ctx context.Context, productID uint, input product.CreatePriceScheduleInput) (*product.PriceSchedule, error)
+
+	// ListSchedules 获取商品的定时调价
+	ListSchedules(ctx context.Context, productID uint) ([]product.PriceSchedule, error)
+
+	// CancelSchedule 取消定时调价，进行中的促销立即结束
+	CancelSchedule(ctx context.Context, productID uint, scheduleID uint) error
+
+	// GetPriceHistory 获取最近days天的售价变化
+	GetPriceHistory(ctx context.Context, productID uint, days int) ([]product.PricePoint, error)
+
+	// GetLowestPrice 获取当前售价生效前days天内的最低售价
+	GetLowestPrice(ctx context.Context, productID uint, days int) (*product.LowestPrice, error)
+
+	// RunScheduledPriceChanges 执行到期的定时调价，由定时任务调用
+	RunScheduledPriceChanges(ctx context.Context) error
+}
+
+// DefaultPriceService 默认商品价格服务实现
+type DefaultPriceService struct {
+	productRepo product.ProductRepository
+	priceRepo   product.PriceRepository
+}
+
+// NewPriceService 创建商品价格服务
+func NewPriceService(productRepo product.ProductRepository, priceRepo product.PriceRepository) PriceService {
+	return &DefaultPriceService{
+		productRepo: productRepo,
+		priceRepo:   priceRepo,
+	}
+}
+
+// CreateSchedule 创建定时调价或限时促销
+func (s *DefaultPriceService) CreateSchedule(ctx context.Context, productID uint, input product.CreatePriceScheduleInput) (*product.PriceSchedule, error) {
+	productEntity, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if input.EndsAt != nil {
+		if !input.EndsAt.After(now) {
+			return nil, apierror.NewValidationError("无效的促销时间", "结束时间必须晚于当前时间")
+		}
+		if input.Price >= productEntity.RegularPrice() {
+			return nil, apierror.NewValidationError("无效的促销价格", fmt.Sprintf("促销价必须低于常规价格 %s", productEntity.RegularPrice()))
+		}
+	}
+
+	schedule := &product.PriceSchedule{
+		ProductID: productID,
+		Price:     input.Price,
+		StartsAt:  input.StartsAt,
+		EndsAt:    input.EndsAt,
+		Note:      input.Note,
+	}
+	if err := s.priceRepo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	if !schedule.StartsAt.After(now) {
+		if _, err := s.priceRepo.ApplySchedule(ctx, schedule.ID, now); err != nil {
+			return nil, err
+		}
+		return s.priceRepo.FindScheduleByID(ctx, schedule.ID)
+	}
+	return schedule, nil
+}
+
+// ListSchedules 获取商品的定时调价
+func (s *DefaultPriceService) ListSchedules(ctx context.Context, productID uint) ([]product.PriceSchedule, error) {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.priceRepo.FindSchedules(ctx, productID)
+}
+
+// CancelSchedule 取消定时调价
+func (s *DefaultPriceService) CancelSchedule(ctx context.Context, productID uint, scheduleID uint) error {
+	schedule, err := s.priceRepo.FindScheduleByID(ctx, scheduleID)
+	if err != nil {
+		return err
+	}
+	if schedule.ProductID != productID {
+		return apierror.NewNotFoundError("定时调价不存在", fmt.Sprintf("商品ID: %d, 调价ID: %d", productID, scheduleID))
+	}
+	return s.priceRepo.CancelSchedule(ctx, scheduleID, time.Now())
+}
+
+// GetPriceHistory 获取最近days天的售价变化，第一条为统计开始时生效的售价
+func (s *DefaultPriceService) GetPriceHistory(ctx context.Context, productID uint, days int) ([]product.PricePoint, error) {
+	if err := validatePriceDays(days); err != nil {
+		return nil, err
+	}
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.priceRepo.FindHistory(ctx, productID, time.Now().AddDate(0, 0, -days))
+}
+
+// GetLowestPrice 获取当前售价生效前days天内的最低售价
+// 统计区间截止到当前售价的生效时间，不包含当前售价本身，促销期间返回的是促销开始前的最低价；
+// 统计开始前已生效的售价同样计入，没有更早价格的商品以当前售价为准
+func (s *DefaultPriceService) GetLowestPrice(ctx context.Context, productID uint, days int) (*product.LowestPrice, error) {
+	if err := validatePriceDays(days); err != nil {
+		return nil, err
+	}
+	productEntity, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 最后一条价格历史即当前售价的生效时间
+	now := time.Now()
+	recent, err := s.priceRepo.FindHistory(ctx, productID, now)
+	if err != nil {
+		return nil, err
+	}
+	until := now
+	if len(recent) > 0 {
+		until = recent[len(recent)-1].ChangedAt
+	}
+
+	since := until.AddDate(0, 0, -days)
+	points, err := s.priceRepo.FindHistory(ctx, productID, since)
+	if err != nil {
+		return nil, err
+	}
+	lowest, ok := product.LowestPriceBefore(points, until)
+	if !ok {
+		lowest = productEntity.Price
+	}
+
+	return &product.LowestPrice{
+		ProductID:      productID,
+		Price:          productEntity.Price,
+		CompareAtPrice: productEntity.CompareAtPrice,
+		LowestPrice:    lowest,
+		Days:           days,
+		Since:          since,
+		Until:          until,
+	}, nil
+}
+
+// validatePriceDays 校验统计天数
+func validatePriceDays(days int) error {
+	if days <= 0 || days > maxPriceHistoryDays {
+		return apierror.NewValidationError("无效的天数", fmt.Sprintf("天数必须在1到%d之间", maxPriceHistoryDays))
+	}
+	return nil
+}
+
+// RunScheduledPriceChanges 分批执行到期的定时调价
+func (s *DefaultPriceService) RunScheduledPriceChanges(ctx context.Context) error {
+	applied := 0
+	for {
+		now := time.Now()
+		schedules, err := s.priceRepo.FindDueSchedules(ctx, now, priceScheduleBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, schedule := range schedules {
+			changed, err := s.priceRepo.ApplySchedule(ctx, schedule.ID, now)
+			if err != nil {
+				return err
+			}
+			if changed {
+				applied++
+			}
+		}
+
+		if len(schedules) < priceScheduleBatchSize {
+			break
+		}
+	}
+
+	if applied > 0 {
+		log.Printf("定时调价修改了 %d 次商品售价", applied)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/product"
+)
+
+// historyPriceRepo 只实现FindHistory，按仓库的语义返回since时生效的售价和之后的变化
+type historyPriceRepo struct {
+	product.PriceRepository
+	points []product.PricePoint
+}
+
+func (r *historyPriceRepo) FindHistory(ctx context.Context, productID uint, since time.Time) ([]product.PricePoint, error) {
+	var result []product.PricePoint
+	for i, point := range r.points {
+		if point.ChangedAt.After(since) {
+			result = append(result, point)
+			continue
+		}
+		if i+1 == len(r.points) || r.points[i+1].ChangedAt.After(since) {
+			result = append(result, point)
+		}
+	}
+	return result, nil
+}
+
+func TestGetLowestPrice(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+	compareAt := func(price common.Money) *common.Money { return &price }
+
+	tests := []struct {
+		name      string
+		product   product.Product
+		points    []product.PricePoint
+		want      common.Money
+		wantUntil time.Time
+	}{
+		{
+			name:    "促销期间返回促销开始前的最低价",
+			product: product.Product{ID: 1, Price: 800, CompareAtPrice: compareAt(1000)},
+			points: []product.PricePoint{
+				{Price: 1000, ChangedAt: daysAgo(90)},
+				{Price: 800, ChangedAt: daysAgo(2)},
+			},
+			want:      1000,
+			wantUntil: daysAgo(2),
+		},
+		{
+			name:    "统计区间截止到降价时间",
+			product: product.Product{ID: 1, Price: 700, CompareAtPrice: compareAt(1000)},
+			points: []product.PricePoint{
+				{Price: 1000, ChangedAt: daysAgo(90)},
+				// 短暂的低价已不在最近30天内，但在降价前30天内，仍应计入
+				{Price: 900, ChangedAt: daysAgo(32)},
+				{Price: 1000, ChangedAt: daysAgo(31)},
+				{Price: 700, ChangedAt: daysAgo(5)},
+			},
+			want:      900,
+			wantUntil: daysAgo(5),
+		},
+		{
+			name:    "降价前30天之外的低价不计入",
+			product: product.Product{ID: 1, Price: 800, CompareAtPrice: compareAt(1000)},
+			points: []product.PricePoint{
+				{Price: 500, ChangedAt: daysAgo(100)},
+				{Price: 1000, ChangedAt: daysAgo(60)},
+				{Price: 800, ChangedAt: daysAgo(1)},
+			},
+			want:      1000,
+			wantUntil: daysAgo(1),
+		},
+		{
+			name:      "只有一个售价",
+			product:   product.Product{ID: 1, Price: 1000},
+			points:    []product.PricePoint{{Price: 1000, ChangedAt: daysAgo(10)}},
+			want:      1000,
+			wantUntil: daysAgo(10),
+		},
+		{
+			name:      "没有价格历史",
+			product:   product.Product{ID: 1, Price: 1000},
+			want:      1000,
+			wantUntil: now,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewPriceService(
+				&gateProductRepo{products: map[uint]*product.Product{1: &tt.product}},
+				&historyPriceRepo{points: tt.points},
+			)
+
+			result, err := svc.GetLowestPrice(context.Background(), 1, product.LowestPriceDays)
+			if err != nil {
+				t.Fatalf("GetLowestPrice: %v", err)
+			}
+			if result.LowestPrice != tt.want {
+				t.Errorf("lowest price = %s, want %s", result.LowestPrice, tt.want)
+			}
+			if result.Until.Sub(tt.wantUntil).Abs() > time.Second {
+				t.Errorf("until = %s, want %s", result.Until, tt.wantUntil)
+			}
+		})
+	}
+}
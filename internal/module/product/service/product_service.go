@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/tax"
+	"web3-ecommerce-app/internal/platform/eventbus"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// lifecycleBatchSize 每次处理定时上下架商品的最大数量
+const lifecycleBatchSize = 100
+
+// ProductService 商品服务接口
+type ProductService interface {
+	// CreateProduct 创建商品
+	CreateProduct(ctx context.Context, input product.CreateProductInput) (*product.Product, error)
+
+	// UpdateProduct 更新商品
+	UpdateProduct(ctx context.Context, id uint, input product.UpdateProductInput) (*product.Product, error)
+
+	// GetProductByID 根据ID获取商品
+	GetProductByID(ctx context.Context, id uint) (*product.Product, error)
+
+	// ListProducts 分页查询商品
+	ListProducts(ctx context.Context, query product.ProductQuery) (*product.ProductPaginationResult, error)
+
+	// DeleteProduct 删除商品
+	DeleteProduct(ctx context.Context, id uint) error
+
+	// ChangeProductStatus 按状态机修改商品状态
+	ChangeProductStatus(ctx context.Context, id uint, input product.ChangeStatusInput) (*product.Product, error)
+
+	// AdjustStock 增减商品实际库存
+	AdjustStock(ctx context.Context, id uint, delta int) (*product.Product, error)
+
+	// ApplyImport 在一个事务中按导入行更新商品，任一步失败时商品保持不变
+	ApplyImport(ctx context.Context, id uint, input product.ImportUpdateInput) (*product.Product, error)
+
+	// SyncStockStatus 根据可售库存切换商品的上架/缺货状态，不传ID时检查全部商品
+	SyncStockStatus(ctx context.Context, ids ...uint) error
+
+	// RunScheduledTransitions 执行已到期的定时上下架，由定时任务调用
+	RunScheduledTransitions(ctx context.Context) error
+
+	// RebuildSearchIndex 将全部商品重新写入搜索索引
+	RebuildSearchIndex(ctx context.Context) error
+}
+
+// DefaultProductService 默认商品服务实现
+type DefaultProductService struct {
+	productRepo product.ProductRepository
+	priceRepo   product.PriceRepository
+	searchIndex product.SearchIndex
+	bus         *eventbus.Bus
+}
+
+// NewProductService 创建商品服务
+func NewProductService(productRepo product.ProductRepository, priceRepo product.PriceRepository, searchIndex product.SearchIndex, bus *eventbus.Bus) ProductService {
+	return &DefaultProductService{
+		productRepo: productRepo,
+		priceRepo:   priceRepo,
+		searchIndex: searchIndex,
+		bus:         bus,
+	}
+}
+
+// CreateProduct 创建商品
+func (s *DefaultProductService) CreateProduct(ctx context.Context, input product.CreateProductInput) (*product.Product, error) {
+	productType := input.Type
+	if productType == "" {
+		productType = product.ProductTypePhysical
+	}
+
+	taxClass := tax.NormalizeClass(input.TaxClass)
+	if err := tax.ValidateProductClass(taxClass); err != nil {
+		return nil, err
+	}
+
+	newProduct := &product.Product{
+		SKU:         input.SKU,
+		Name:        input.Name,
+		Description: input.Description,
+		Type:        productType,
+		Price:       input.Price,
+		Stock:       input.Stock,
+		Weight:      input.Weight,
+		TaxClass:    taxClass,
+		Status:      product.ProductStatusDraft,
+		CategoryID:  input.CategoryID,
+		MerchantID:  input.MerchantID,
+	}
+
+	if err := s.productRepo.Create(ctx, newProduct); err != nil {
+		return nil, err
+	}
+	s.reindex(ctx, newProduct)
+
+	return newProduct, nil
+}
+
+// UpdateProduct 更新商品
+// 价格修改的是常规价格，限时促销期间售价不变，促销结束后恢复为新的常规价格
+func (s *DefaultProductService) UpdateProduct(ctx context.Context, id uint, input product.UpdateProductInput) (*product.Product, error) {
+	productEntity, err := s.productRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.update(ctx, productEntity, input); err != nil {
+		return nil, err
+	}
+	s.reindex(ctx, productEntity)
+
+	return productEntity, nil
+}
+
+// ApplyImport 在一个事务中按导入行更新商品的基础信息、价格、库存和状态
+// 库存差值按锁定后的最新库存计算，任一步失败时商品保持不变
+func (s *DefaultProductService) ApplyImport(ctx context.Context, id uint, input product.ImportUpdateInput) (*product.Product, error) {
+	err := s.productRepo.Transaction(ctx, func(ctx context.Context) error {
+		productEntity, err := s.productRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if input.Stock != nil && *input.Stock < productEntity.Reserved {
+			return apierror.NewValidationError("库存不能低于已预占数量", fmt.Sprintf("商品ID: %d, 已预占: %d", id, productEntity.Reserved))
+		}
+		from, stock := productEntity.Status, productEntity.Stock
+		if input.Status != "" {
+			if err := productEntity.TransitionTo(product.ChangeStatusInput{Status: input.Status}, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		if err := s.update(ctx, productEntity, input.UpdateProductInput); err != nil {
+			return err
+		}
+		if input.Stock != nil && *input.Stock != stock {
+			if err := s.productRepo.UpdateStock(ctx, id, *input.Stock-stock); err != nil {
+				return err
+			}
+		}
+		if input.Status != "" {
+			ok, err := s.productRepo.UpdateStatus(ctx, productEntity, from)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return apierror.NewInvalidStateTransitionError("商品状态已被修改，请刷新后重试", fmt.Sprintf("ID: %d", id))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 到货事件和搜索索引在事务提交后处理
+	return s.syncAndReload(ctx, id)
+}
+
+// update 将输入的字段写入商品，价格变化时修改常规价格
+func (s *DefaultProductService) update(ctx context.Context, productEntity *product.Product, input product.UpdateProductInput) error {
+	if input.Name != nil {
+		productEntity.Name = *input.Name
+	}
+	if input.Description != nil {
+		productEntity.Description = *input.Description
+	}
+	if input.Type != nil {
+		productEntity.Type = *input.Type
+	}
+	if input.Weight != nil {
+		productEntity.Weight = *input.Weight
+	}
+	if input.TaxClass != nil {
+		taxClass := tax.NormalizeClass(*input.TaxClass)
+		if err := tax.ValidateProductClass(taxClass); err != nil {
+			return err
+		}
+		productEntity.TaxClass = taxClass
+	}
+	if input.CategoryID != nil {
+		productEntity.CategoryID = *input.CategoryID
+	}
+
+	if err := s.productRepo.Update(ctx, productEntity); err != nil {
+		return err
+	}
+
+	if input.Price != nil && *input.Price != productEntity.RegularPrice() {
+		if err := s.priceRepo.SetRegularPrice(ctx, productEntity.ID, *input.Price, time.Now()); err != nil {
+			return err
+		}
+		productEntity.SetRegularPrice(*input.Price)
+	}
+	return nil
+}
+
+// GetProductByID 根据ID获取商品
+func (s *DefaultProductService) GetProductByID(ctx context.Context, id uint) (*product.Product, error) {
+	return s.productRepo.FindByID(ctx, id)
+}
+
+// ListProducts 分页查询商品
+func (s *DefaultProductService) ListProducts(ctx context.Context, query product.ProductQuery) (*product.ProductPaginationResult, error) {
+	// 设置默认分页参数
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+
+	if query.Search == "" {
+		return s.productRepo.Find(ctx, query)
+	}
+
+	// 带关键词的查询走搜索索引，按相关度排序
+	result, err := s.searchIndex.Search(ctx, product.SearchQuery{
+		Text:       query.Search,
+		CategoryID: query.CategoryID,
+		MerchantID: query.MerchantID,
+		Statuses:   query.Statuses,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, result.ProductIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &product.ProductPaginationResult{
+		Total:    result.Total,
+		Products: products,
+	}, nil
+}
+
+// DeleteProduct 删除商品
+func (s *DefaultProductService) DeleteProduct(ctx context.Context, id uint) error {
+	if err := s.productRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.searchIndex.Remove(ctx, id); err != nil {
+		log.Printf("从搜索索引删除商品 %d 失败: %v", id, err)
+	}
+	return nil
+}
+
+// ChangeProductStatus 按状态机修改商品状态
+func (s *DefaultProductService) ChangeProductStatus(ctx context.Context, id uint, input product.ChangeStatusInput) (*product.Product, error) {
+	productEntity, err := s.productRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	from := productEntity.Status
+	if err := productEntity.TransitionTo(input, time.Now()); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.productRepo.UpdateStatus(ctx, productEntity, from)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apierror.NewInvalidStateTransitionError("商品状态已被修改，请刷新后重试", fmt.Sprintf("ID: %d", id))
+	}
+
+	// 判断缺货时读取的库存可能已经变化，上架后按最新库存再同步一次
+	if productEntity.IsVisible() {
+		return s.syncAndReload(ctx, id)
+	}
+	s.reindex(ctx, productEntity)
+
+	return productEntity, nil
+}
+
+// AdjustStock 增减商品实际库存
+func (s *DefaultProductService) AdjustStock(ctx context.Context, id uint, delta int) (*product.Product, error) {
+	if err := s.productRepo.UpdateStock(ctx, id, delta); err != nil {
+		return nil, err
+	}
+
+	return s.syncAndReload(ctx, id)
+}
+
+// SyncStockStatus 根据可售库存切换商品的上架/缺货状态，并更新发生变化的商品的搜索索引
+// 从缺货恢复为上架的商品发布到货事件
+func (s *DefaultProductService) SyncStockStatus(ctx context.Context, ids ...uint) error {
+	now := time.Now()
+	changed, err := s.productRepo.SyncStockStatus(ctx, ids)
+	if len(changed) > 0 {
+		products, findErr := s.productRepo.FindByIDs(ctx, changed)
+		if findErr != nil {
+			log.Printf("更新商品搜索索引失败: %v", findErr)
+		}
+		for i := range products {
+			p := &products[i]
+			s.reindex(ctx, p)
+			if p.Status == product.ProductStatusPublished {
+				s.bus.Publish(ctx, product.EventProductRestocked, product.RestockedEvent{
+					ProductID:   p.ID,
+					Name:        p.Name,
+					Available:   p.AvailableStock(),
+					RestockedAt: now,
+				})
+			}
+		}
+	}
+	return err
+}
+
+// RunScheduledTransitions 执行已到期的定时上架和定时下架，并修正与库存不一致的商品状态
+func (s *DefaultProductService) RunScheduledTransitions(ctx context.Context) error {
+	published, err := s.runDue(ctx, s.productRepo.FindDueForPublish, (*product.Product).Publish)
+	if err != nil {
+		return err
+	}
+	unpublished, err := s.runDue(ctx, s.productRepo.FindDueForUnpublish, (*product.Product).Unpublish)
+	if err != nil {
+		return err
+	}
+	if published > 0 || unpublished > 0 {
+		log.Printf("定时上架 %d 个商品，定时下架 %d 个商品", published, unpublished)
+	}
+
+	// 库存变化后同步状态失败时留下的不一致在这里修正
+	return s.SyncStockStatus(ctx)
+}
+
+// runDue 分批查询到期商品并执行状态转换，返回成功转换的数量
+func (s *DefaultProductService) runDue(
+	ctx context.Context,
+	findDue func(ctx context.Context, now time.Time, limit int) ([]product.Product, error),
+	transition func(p *product.Product) error,
+) (int, error) {
+	total := 0
+	for {
+		products, err := findDue(ctx, time.Now(), lifecycleBatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		for i := range products {
+			p := &products[i]
+			from := p.Status
+			if err := transition(p); err != nil {
+				return total, err
+			}
+			// 状态已被其它流程修改时跳过，下一批不会再查到该商品
+			ok, err := s.productRepo.UpdateStatus(ctx, p, from)
+			if err != nil {
+				return total, err
+			}
+			if ok {
+				total++
+				s.reindex(ctx, p)
+			}
+		}
+
+		if len(products) < lifecycleBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// syncAndReload 同步单个商品的库存状态后重新读取
+func (s *DefaultProductService) syncAndReload(ctx context.Context, id uint) (*product.Product, error) {
+	if err := s.SyncStockStatus(ctx, id); err != nil {
+		log.Printf("同步商品 %d 的库存状态失败: %v", id, err)
+	}
+
+	productEntity, err := s.productRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.reindex(ctx, productEntity)
+
+	return productEntity, nil
+}
+
+// RebuildSearchIndex 分页读取全部商品并写入搜索索引
+func (s *DefaultProductService) RebuildSearchIndex(ctx context.Context) error {
+	const pageSize = 200
+	for page := 1; ; page++ {
+		result, err := s.productRepo.Find(ctx, product.ProductQuery{Page: page, PageSize: pageSize})
+		if err != nil {
+			return err
+		}
+		for i := range result.Products {
+			if err := s.searchIndex.Index(ctx, &result.Products[i]); err != nil {
+				return err
+			}
+		}
+		if len(result.Products) < pageSize {
+			return nil
+		}
+	}
+}
+
+// reindex 商品变更后增量更新搜索索引
+// 索引失败不影响商品本身的写入，只记录日志，可通过RebuildSearchIndex修复
+func (s *DefaultProductService) reindex(ctx context.Context, p *product.Product) {
+	if err := s.searchIndex.Index(ctx, p); err != nil {
+		log.Printf("更新商品 %d 的搜索索引失败: %v", p.ID, err)
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/platform/eventbus"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// txProductRepo 内存商品仓库，fn返回错误时Transaction恢复事务开始时的数据
+type txProductRepo struct {
+	product.ProductRepository
+	products map[uint]product.Product
+	conflict bool // UpdateStatus时商品状态已被其它流程修改
+}
+
+func (r *txProductRepo) FindByID(ctx context.Context, id uint) (*product.Product, error) {
+	p, ok := r.products[id]
+	if !ok {
+		return nil, apierror.NewNotFoundError("商品不存在", "")
+	}
+	return &p, nil
+}
+
+func (r *txProductRepo) LockByID(ctx context.Context, id uint) (*product.Product, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *txProductRepo) Update(ctx context.Context, p *product.Product) error {
+	stored := r.products[p.ID]
+	stored.Name, stored.Description, stored.CategoryID = p.Name, p.Description, p.CategoryID
+	r.products[p.ID] = stored
+	return nil
+}
+
+func (r *txProductRepo) UpdateStock(ctx context.Context, id uint, delta int) error {
+	stored := r.products[id]
+	if stored.Stock+delta < stored.Reserved {
+		return apierror.NewInsufficientStockError("库存不足", "")
+	}
+	stored.Stock += delta
+	r.products[id] = stored
+	return nil
+}
+
+func (r *txProductRepo) UpdateStatus(ctx context.Context, p *product.Product, from string) (bool, error) {
+	stored := r.products[p.ID]
+	if r.conflict || stored.Status != from {
+		return false, nil
+	}
+	stored.Status = p.Status
+	r.products[p.ID] = stored
+	return true, nil
+}
+
+func (r *txProductRepo) SyncStockStatus(ctx context.Context, ids []uint) ([]uint, error) {
+	return nil, nil
+}
+
+func (r *txProductRepo) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := maps.Clone(r.products)
+	err := fn(ctx)
+	if err != nil {
+		r.products = snapshot
+	}
+	return err
+}
+
+// txPriceRepo 修改txProductRepo中商品的常规价格
+type txPriceRepo struct {
+	product.PriceRepository
+	products *txProductRepo
+}
+
+func (r *txPriceRepo) SetRegularPrice(ctx context.Context, productID uint, price common.Money, now time.Time) error {
+	stored := r.products.products[productID]
+	stored.SetRegularPrice(price)
+	r.products.products[productID] = stored
+	return nil
+}
+
+type nopSearchIndex struct {
+	product.SearchIndex
+}
+
+func (nopSearchIndex) Index(ctx context.Context, p *product.Product) error {
+	return nil
+}
+
+func TestApplyImport(t *testing.T) {
+	name := "新名称"
+	price := common.Money(1200)
+	stock := func(n int) *int { return &n }
+	row := func(stock *int, status string) product.ImportUpdateInput {
+		return product.ImportUpdateInput{
+			UpdateProductInput: product.UpdateProductInput{Name: &name, Price: &price},
+			Stock:              stock,
+			Status:             status,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		input    product.ImportUpdateInput
+		conflict bool
+		wantCode apierror.ErrorCode
+		want     product.Product
+	}{
+		{
+			name:  "整行写入",
+			input: row(stock(5), product.ProductStatusPublished),
+			want:  product.Product{Name: name, Price: 1200, Stock: 5, Status: product.ProductStatusPublished},
+		},
+		{
+			name:     "库存低于已预占数量",
+			input:    row(stock(1), product.ProductStatusPublished),
+			wantCode: apierror.ErrorCodeValidationFailed,
+		},
+		{
+			name:     "状态不允许变更",
+			input:    row(stock(5), product.ProductStatusScheduled),
+			wantCode: apierror.ErrorCodeValidationFailed,
+		},
+		{
+			name:     "状态被并发修改时整行回滚",
+			input:    row(stock(5), product.ProductStatusPublished),
+			conflict: true,
+			wantCode: apierror.ErrorCodeInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := product.Product{ID: 1, Name: "旧名称", Price: 1000, Stock: 10, Reserved: 2, Status: product.ProductStatusDraft}
+			repo := &txProductRepo{products: map[uint]product.Product{1: original}, conflict: tt.conflict}
+			svc := NewProductService(repo, &txPriceRepo{products: repo}, nopSearchIndex{}, eventbus.New())
+
+			_, err := svc.ApplyImport(context.Background(), 1, tt.input)
+			want := tt.want
+			if tt.wantCode != "" {
+				var apiErr *apierror.APIError
+				if !errors.As(err, &apiErr) || apiErr.Code != tt.wantCode {
+					t.Fatalf("error = %v, want code %s", err, tt.wantCode)
+				}
+				want = original
+			} else if err != nil {
+				t.Fatalf("ApplyImport: %v", err)
+			}
+
+			got := repo.products[1]
+			if got.Name != want.Name || got.Price != want.Price || got.Stock != want.Stock || got.Status != want.Status {
+				t.Errorf("product = %s %s stock %d %s, want %s %s stock %d %s",
+					got.Name, got.Price, got.Stock, got.Status, want.Name, want.Price, want.Stock, want.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"web3-ecommerce-app/internal/domain/promotion"
+	"web3-ecommerce-app/internal/module/promotion/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionHTTPHandler 促销HTTP处理器
+type PromotionHTTPHandler struct {
+	promotionService service.PromotionService
+}
+
+// NewPromotionHTTPHandler 创建促销HTTP处理器
+func NewPromotionHTTPHandler(promotionService service.PromotionService) *PromotionHTTPHandler {
+	return &PromotionHTTPHandler{
+		promotionService: promotionService,
+	}
+}
+
+// PreviewPromotions 按当前售价预览商品和优惠码可享受的优惠及每行的减免明细
+func (h *PromotionHTTPHandler) PreviewPromotions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+		})
+		return
+	}
+
+	var input promotion.PreviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	evaluation, err := h.promotionService.Preview(c.Request.Context(), userID.(uint), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, evaluation)
+}
+
+// handleError 处理错误
+func (h *PromotionHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
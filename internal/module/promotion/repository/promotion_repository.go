@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/promotion"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PromotionModel 是GORM促销模型
+// 自动促销的优惠码为NULL，不占用唯一索引
+type PromotionModel struct {
+	gorm.Model
+	Name         string     `gorm:"type:varchar(100);not null"`
+	Code         *string    `gorm:"type:varchar(50);uniqueIndex:idx_code"`
+	Type         string     `gorm:"type:varchar(20);not null"`
+	Percent      int        `gorm:"not null;default:0"`
+	Amount       int64      `gorm:"not null;default:0"`
+	BuyQuantity  int        `gorm:"not null;default:0"`
+	GetQuantity  int        `gorm:"not null;default:0"`
+	MinSubtotal  int64      `gorm:"not null;default:0"`
+	ProductIDs   []uint     `gorm:"type:text;serializer:json"`
+	CategoryIDs  []uint     `gorm:"type:text;serializer:json"`
+	StartsAt     *time.Time `gorm:"index:idx_starts_at"`
+	EndsAt       *time.Time `gorm:"index:idx_ends_at"`
+	UsageLimit   int        `gorm:"not null;default:0"`
+	PerUserLimit int        `gorm:"not null;default:0"`
+	UsedCount    int        `gorm:"not null;default:0"`
+	Exclusive    bool       `gorm:"not null;default:false"`
+	Priority     int        `gorm:"not null;default:0"`
+	Active       bool       `gorm:"not null;default:false;index:idx_active"`
+}
+
+// TableName 指定表名
+func (PromotionModel) TableName() string {
+	return "promotions"
+}
+
+// RedemptionModel 是GORM促销使用记录模型
+type RedemptionModel struct {
+	ID          uint  `gorm:"primarykey"`
+	PromotionID uint  `gorm:"not null;uniqueIndex:idx_order_promotion,priority:2;index:idx_promotion_user,priority:1"`
+	UserID      uint  `gorm:"not null;index:idx_promotion_user,priority:2"`
+	OrderID     uint  `gorm:"not null;uniqueIndex:idx_order_promotion,priority:1"`
+	Amount      int64 `gorm:"not null"`
+	CreatedAt   time.Time
+}
+
+// TableName 指定表名
+func (RedemptionModel) TableName() string {
+	return "promotion_redemptions"
+}
+
+// GormPromotionRepository 是促销仓库的GORM实现
+type GormPromotionRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPromotionRepository 创建一个新的GORM促销仓库
+func NewGormPromotionRepository(db *gorm.DB) promotion.PromotionRepository {
+	return &GormPromotionRepository{db: db}
+}
+
+// promotionToModel 将领域模型转换为GORM模型
+func promotionToModel(p *promotion.Promotion) *PromotionModel {
+	var code *string
+	if p.Code != "" {
+		code = &p.Code
+	}
+	return &PromotionModel{
+		Model: gorm.Model{
+			ID:        p.ID,
+			CreatedAt: p.CreatedAt,
+			UpdatedAt: p.UpdatedAt,
+		},
+		Name:         p.Name,
+		Code:         code,
+		Type:         p.Type,
+		Percent:      p.Percent,
+		Amount:       int64(p.Amount),
+		BuyQuantity:  p.BuyQuantity,
+		GetQuantity:  p.GetQuantity,
+		MinSubtotal:  int64(p.MinSubtotal),
+		ProductIDs:   p.ProductIDs,
+		CategoryIDs:  p.CategoryIDs,
+		StartsAt:     p.StartsAt,
+		EndsAt:       p.EndsAt,
+		UsageLimit:   p.UsageLimit,
+		PerUserLimit: p.PerUserLimit,
+		UsedCount:    p.UsedCount,
+		Exclusive:    p.Exclusive,
+		Priority:     p.Priority,
+		Active:       p.Active,
+	}
+}
+
+// promotionToDomain 将GORM模型转换为领域模型
+func promotionToDomain(m *PromotionModel) *promotion.Promotion {
+	p := &promotion.Promotion{
+		ID:           m.ID,
+		Name:         m.Name,
+		Type:         m.Type,
+		Percent:      m.Percent,
+		Amount:       common.Money(m.Amount),
+		BuyQuantity:  m.BuyQuantity,
+		GetQuantity:  m.GetQuantity,
+		MinSubtotal:  common.Money(m.MinSubtotal),
+		ProductIDs:   m.ProductIDs,
+		CategoryIDs:  m.CategoryIDs,
+		StartsAt:     m.StartsAt,
+		EndsAt:       m.EndsAt,
+		UsageLimit:   m.UsageLimit,
+		PerUserLimit: m.PerUserLimit,
+		UsedCount:    m.UsedCount,
+		Exclusive:    m.Exclusive,
+		Priority:     m.Priority,
+		Active:       m.Active,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+	if m.Code != nil {
+		p.Code = *m.Code
+	}
+	if p.ProductIDs == nil {
+		p.ProductIDs = []uint{}
+	}
+	if p.CategoryIDs == nil {
+		p.CategoryIDs = []uint{}
+	}
+	return p
+}
+
+// FindByID 根据ID查找促销
+func (r *GormPromotionRepository) FindByID(ctx context.Context, id uint) (*promotion.Promotion, error) {
+	var model PromotionModel
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("促销不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询促销错误: %w", err)
+	}
+	return promotionToDomain(&model), nil
+}
+
+// FindByCode 根据优惠码查找促销
+func (r *GormPromotionRepository) FindByCode(ctx context.Context, code string) (*promotion.Promotion, error) {
+	var model PromotionModel
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("优惠码不存在", code)
+		}
+		return nil, fmt.Errorf("查询促销错误: %w", err)
+	}
+	return promotionToDomain(&model), nil
+}
+
+// FindAutomatic 查询当前有效期内已启用的自动促销
+func (r *GormPromotionRepository) FindAutomatic(ctx context.Context, now time.Time) ([]promotion.Promotion, error) {
+	var models []PromotionModel
+	if err := r.db.WithContext(ctx).
+		Where("code IS NULL AND active = ?", true).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询自动促销错误: %w", err)
+	}
+
+	promotions := make([]promotion.Promotion, 0, len(models))
+	for i := range models {
+		promotions = append(promotions, *promotionToDomain(&models[i]))
+	}
+	return promotions, nil
+}
+
+// Find 按条件分页查询促销
+func (r *GormPromotionRepository) Find(ctx context.Context, query promotion.PromotionQuery) (*promotion.PromotionPaginationResult, error) {
+	var models []PromotionModel
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&PromotionModel{})
+	if query.Active != nil {
+		db = db.Where("active = ?", *query.Active)
+	}
+	if query.Code != "" {
+		db = db.Where("code = ?", query.Code)
+	}
+
+	// 查询总数
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询促销总数错误: %w", err)
+	}
+
+	// 分页查询
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order("id DESC").Offset(offset).Limit(query.PageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询促销列表错误: %w", err)
+	}
+
+	promotions := make([]promotion.Promotion, 0, len(models))
+	for i := range models {
+		promotions = append(promotions, *promotionToDomain(&models[i]))
+	}
+
+	return &promotion.PromotionPaginationResult{
+		Total:      int(total),
+		Promotions: promotions,
+	}, nil
+}
+
+// Create 创建促销
+func (r *GormPromotionRepository) Create(ctx context.Context, p *promotion.Promotion) error {
+	model := promotionToModel(p)
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		if p.Code != "" && r.db.WithContext(ctx).Where("code = ?", p.Code).First(&PromotionModel{}).Error == nil {
+			return apierror.NewDuplicateEntityError("优惠码已存在", p.Code)
+		}
+		return fmt.Errorf("创建促销错误: %w", err)
+	}
+
+	// 更新领域模型
+	p.ID = model.ID
+	p.CreatedAt = model.CreatedAt
+	p.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// Update 更新促销
+// 使用次数只能通过Redeem和ReleaseOrder修改，这里忽略它，避免覆盖并发的使用记录
+func (r *GormPromotionRepository) Update(ctx context.Context, p *promotion.Promotion) error {
+	model := promotionToModel(p)
+	if err := r.db.WithContext(ctx).Omit("used_count").Save(model).Error; err != nil {
+		if p.Code != "" && r.db.WithContext(ctx).Where("code = ? AND id <> ?", p.Code, p.ID).First(&PromotionModel{}).Error == nil {
+			return apierror.NewDuplicateEntityError("优惠码已存在", p.Code)
+		}
+		return fmt.Errorf("更新促销错误: %w", err)
+	}
+
+	// 更新领域模型
+	p.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// Delete 删除促销
+// 使用记录仍然引用该促销，因此只做软删除，同时释放优惠码以便重新使用
+func (r *GormPromotionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&PromotionModel{}).Where("id = ?", id).Update("code", nil).Error; err != nil {
+			return fmt.Errorf("删除促销错误: %w", err)
+		}
+		if err := tx.Delete(&PromotionModel{}, id).Error; err != nil {
+			return fmt.Errorf("删除促销错误: %w", err)
+		}
+		return nil
+	})
+}
+
+// CountUserRedemptions 统计用户使用各促销的次数
+func (r *GormPromotionRepository) CountUserRedemptions(ctx context.Context, userID uint, promotionIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int, len(promotionIDs))
+	if len(promotionIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		PromotionID uint
+		Count       int
+	}
+	if err := r.db.WithContext(ctx).Model(&RedemptionModel{}).
+		Select("promotion_id, COUNT(*) AS count").
+		Where("user_id = ? AND promotion_id IN ?", userID, promotionIDs).
+		Group("promotion_id").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询促销使用记录错误: %w", err)
+	}
+	for _, row := range rows {
+		counts[row.PromotionID] = row.Count
+	}
+	return counts, nil
+}
+
+// Redeem 记录订单使用的促销并增加使用次数
+// 按促销ID顺序加锁，避免并发下单时死锁；ctx携带事务时与订单写入在同一个事务中提交
+func (r *GormPromotionRepository) Redeem(ctx context.Context, redemptions []promotion.Redemption) error {
+	sorted := slices.Clone(redemptions)
+	slices.SortFunc(sorted, func(a, b promotion.Redemption) int {
+		return cmp.Compare(a.PromotionID, b.PromotionID)
+	})
+
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, redemption := range sorted {
+			var model PromotionModel
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, redemption.PromotionID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return apierror.NewValidationError("促销已失效", fmt.Sprintf("促销ID: %d", redemption.PromotionID))
+				}
+				return fmt.Errorf("查询促销错误: %w", err)
+			}
+			if model.UsageLimit > 0 && model.UsedCount >= model.UsageLimit {
+				return apierror.NewValidationError("促销已达到使用次数上限", model.Name)
+			}
+
+			if model.PerUserLimit > 0 {
+				var used int64
+				if err := tx.Model(&RedemptionModel{}).
+					Where("promotion_id = ? AND user_id = ?", redemption.PromotionID, redemption.UserID).
+					Count(&used).Error; err != nil {
+					return fmt.Errorf("查询促销使用记录错误: %w", err)
+				}
+				if int(used) >= model.PerUserLimit {
+					return apierror.NewValidationError("已达到该促销的个人使用次数上限", model.Name)
+				}
+			}
+
+			if err := tx.Create(&RedemptionModel{
+				PromotionID: redemption.PromotionID,
+				UserID:      redemption.UserID,
+				OrderID:     redemption.OrderID,
+				Amount:      int64(redemption.Amount),
+			}).Error; err != nil {
+				return fmt.Errorf("记录促销使用错误: %w", err)
+			}
+			if err := tx.Model(&PromotionModel{}).Where("id = ?", redemption.PromotionID).
+				UpdateColumn("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+				return fmt.Errorf("更新促销使用次数错误: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ReleaseOrder 删除订单的促销使用记录并归还使用次数
+func (r *GormPromotionRepository) ReleaseOrder(ctx context.Context, orderID uint) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var models []RedemptionModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).
+			Order("promotion_id").Find(&models).Error; err != nil {
+			return fmt.Errorf("查询促销使用记录错误: %w", err)
+		}
+
+		for _, m := range models {
+			if err := tx.Model(&PromotionModel{}).Where("id = ? AND used_count > 0", m.PromotionID).
+				UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error; err != nil {
+				return fmt.Errorf("更新促销使用次数错误: %w", err)
+			}
+		}
+		if len(models) > 0 {
+			if err := tx.Where("order_id = ?", orderID).Delete(&RedemptionModel{}).Error; err != nil {
+				return fmt.Errorf("删除促销使用记录错误: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormPromotionRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&PromotionModel{}, &RedemptionModel{})
+}
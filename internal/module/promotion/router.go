@@ -0,0 +1,24 @@
+package promotion
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/promotion/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册促销模块路由
+// 促销的管理接口由admin模块统一提供
+func RegisterRoutes(router *gin.Engine, handler *handler.PromotionHTTPHandler, jwtConfig *config.JWTConfig) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 促销路由(需要认证，个人使用次数按用户计算)
+	promotionRoutes := v1.Group("/promotions")
+	promotionRoutes.Use(middleware.JWT(jwtConfig))
+	{
+		// 预览优惠
+		promotionRoutes.POST("/preview", handler.PreviewPromotions)
+	}
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/promotion"
+	productService "web3-ecommerce-app/internal/module/product/service"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// PromotionService 促销服务接口
+type PromotionService interface {
+	// CreatePromotion 创建促销
+	CreatePromotion(ctx context.Context, input promotion.PromotionInput) (*promotion.Promotion, error)
+
+	// UpdatePromotion 更新促销
+	UpdatePromotion(ctx context.Context, id uint, input promotion.PromotionInput) (*promotion.Promotion, error)
+
+	// GetPromotion 获取促销详情
+	GetPromotion(ctx context.Context, id uint) (*promotion.Promotion, error)
+
+	// ListPromotions 分页查询促销
+	ListPromotions(ctx context.Context, query promotion.PromotionQuery) (*promotion.PromotionPaginationResult, error)
+
+	// DeletePromotion 删除促销
+	DeletePromotion(ctx context.Context, id uint) error
+
+	// Evaluate 计算购物篮可享受的优惠，结算时由订单模块调用
+	Evaluate(ctx context.Context, basket promotion.Basket) (*promotion.Evaluation, error)
+
+	// Preview 按用户结算时的售价预览优惠
+	Preview(ctx context.Context, userID uint, input promotion.PreviewInput) (*promotion.Evaluation, error)
+
+	// Redeem 下单时记录生效的促销，超出使用次数时返回错误
+	Redeem(ctx context.Context, orderID uint, userID uint, evaluation *promotion.Evaluation) error
+
+	// Release 订单取消后归还促销使用次数
+	Release(ctx context.Context, orderID uint) error
+}
+
+// DefaultPromotionService 默认促销服务实现
+type DefaultPromotionService struct {
+	promotionRepo promotion.PromotionRepository
+	productRepo   product.ProductRepository
+	gateService   productService.GateService
+}
+
+// NewPromotionService 创建促销服务
+func NewPromotionService(promotionRepo promotion.PromotionRepository, productRepo product.ProductRepository, gateService productService.GateService) PromotionService {
+	return &DefaultPromotionService{
+		promotionRepo: promotionRepo,
+		productRepo:   productRepo,
+		gateService:   gateService,
+	}
+}
+
+// CreatePromotion 创建促销
+func (s *DefaultPromotionService) CreatePromotion(ctx context.Context, input promotion.PromotionInput) (*promotion.Promotion, error) {
+	p := &promotion.Promotion{}
+	if err := applyPromotionInput(p, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotionRepo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UpdatePromotion 更新促销，已产生的使用次数保持不变
+func (s *DefaultPromotionService) UpdatePromotion(ctx context.Context, id uint, input promotion.PromotionInput) (*promotion.Promotion, error) {
+	p, err := s.promotionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPromotionInput(p, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotionRepo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// applyPromotionInput 校验输入并写入促销，不同类型需要的参数不同
+func applyPromotionInput(p *promotion.Promotion, input promotion.PromotionInput) error {
+	switch input.Type {
+	case promotion.PromotionTypePercentage:
+		if input.Percent <= 0 {
+			return apierror.NewValidationError("无效的促销", "折扣比例必须在1到100之间")
+		}
+	case promotion.PromotionTypeFixedAmount:
+		if input.Amount <= 0 {
+			return apierror.NewValidationError("无效的促销", "减免金额必须大于0")
+		}
+	case promotion.PromotionTypeBuyXGetY:
+		if input.BuyQuantity <= 0 || input.GetQuantity <= 0 {
+			return apierror.NewValidationError("无效的促销", "购买数量和优惠数量必须大于0")
+		}
+		// 未指定折扣比例时优惠的商品免费
+		if input.Percent == 0 {
+			input.Percent = 100
+		}
+	}
+	if input.StartsAt != nil && input.EndsAt != nil && !input.EndsAt.After(*input.StartsAt) {
+		return apierror.NewValidationError("无效的有效期", "结束时间必须晚于开始时间")
+	}
+
+	p.Name = input.Name
+	p.Code = promotion.NormalizeCode(input.Code)
+	p.Type = input.Type
+	p.Percent = input.Percent
+	p.Amount = input.Amount
+	p.BuyQuantity = input.BuyQuantity
+	p.GetQuantity = input.GetQuantity
+	p.MinSubtotal = input.MinSubtotal
+	p.ProductIDs = input.ProductIDs
+	p.CategoryIDs = input.CategoryIDs
+	p.StartsAt = input.StartsAt
+	p.EndsAt = input.EndsAt
+	p.UsageLimit = input.UsageLimit
+	p.PerUserLimit = input.PerUserLimit
+	p.Exclusive = input.Exclusive
+	p.Priority = input.Priority
+	p.Active = input.Active
+
+	// 不使用的参数清零，避免影响计算
+	if p.Type != promotion.PromotionTypePercentage && p.Type != promotion.PromotionTypeBuyXGetY {
+		p.Percent = 0
+	}
+	if p.Type != promotion.PromotionTypeFixedAmount {
+		p.Amount = 0
+	}
+	if p.Type != promotion.PromotionTypeBuyXGetY {
+		p.BuyQuantity, p.GetQuantity = 0, 0
+	}
+	if p.ProductIDs == nil {
+		p.ProductIDs = []uint{}
+	}
+	if p.CategoryIDs == nil {
+		p.CategoryIDs = []uint{}
+	}
+	return nil
+}
+
+// GetPromotion 获取促销详情
+func (s *DefaultPromotionService) GetPromotion(ctx context.Context, id uint) (*promotion.Promotion, error) {
+	return s.promotionRepo.FindByID(ctx, id)
+}
+
+// ListPromotions 分页查询促销
+func (s *DefaultPromotionService) ListPromotions(ctx context.Context, query promotion.PromotionQuery) (*promotion.PromotionPaginationResult, error) {
+	// 设置默认分页参数
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	query.Code = promotion.NormalizeCode(query.Code)
+
+	return s.promotionRepo.Find(ctx, query)
+}
+
+// DeletePromotion 删除促销
+func (s *DefaultPromotionService) DeletePromotion(ctx context.Context, id uint) error {
+	if _, err := s.promotionRepo.FindByID(ctx, id); err != nil {
+		return err
+	}
+	return s.promotionRepo.Delete(ctx, id)
+}
+
+// Evaluate 计算购物篮可享受的优惠
+// 自动促销不满足条件时直接忽略，优惠码不可用时在结果中说明原因
+func (s *DefaultPromotionService) Evaluate(ctx context.Context, basket promotion.Basket) (*promotion.Evaluation, error) {
+	now := time.Now()
+
+	candidates, err := s.promotionRepo.FindAutomatic(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var rejected []promotion.RejectedCode
+	seen := make(map[string]bool, len(basket.Codes))
+	for _, raw := range basket.Codes {
+		code := promotion.NormalizeCode(raw)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		p, err := s.promotionRepo.FindByCode(ctx, code)
+		if err != nil {
+			if apiErr, ok := err.(*apierror.APIError); ok && apiErr.Code == apierror.ErrorCodeNotFound {
+				rejected = append(rejected, promotion.RejectedCode{Code: code, Reason: "优惠码不存在"})
+				continue
+			}
+			return nil, err
+		}
+
+		switch {
+		case !p.Active:
+			rejected = append(rejected, promotion.RejectedCode{Code: code, Reason: "优惠码未启用"})
+		case !p.IsWithinWindow(now):
+			rejected = append(rejected, promotion.RejectedCode{Code: code, Reason: "优惠码不在有效期内"})
+		case p.IsExhausted():
+			rejected = append(rejected, promotion.RejectedCode{Code: code, Reason: "优惠码已被领完"})
+		default:
+			candidates = append(candidates, *p)
+		}
+	}
+
+	// 按用户已使用次数过滤
+	ids := make([]uint, 0, len(candidates))
+	for _, p := range candidates {
+		if p.PerUserLimit > 0 {
+			ids = append(ids, p.ID)
+		}
+	}
+	used, err := s.promotionRepo.CountUserRedemptions(ctx, basket.UserID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	eligible := make([]promotion.Promotion, 0, len(candidates))
+	for _, p := range candidates {
+		if p.IsExhausted() {
+			continue
+		}
+		if p.PerUserLimit > 0 && used[p.ID] >= p.PerUserLimit {
+			if p.Code != "" {
+				rejected = append(rejected, promotion.RejectedCode{Code: p.Code, Reason: "已达到个人使用次数上限"})
+			}
+			continue
+		}
+		eligible = append(eligible, p)
+	}
+
+	return promotion.Evaluate(basket, eligible, rejected), nil
+}
+
+// Preview 按结算时的规则确定售价和库存后预览优惠，运费在下单时确定，预览中按0计算
+func (s *DefaultPromotionService) Preview(ctx context.Context, userID uint, input promotion.PreviewInput) (*promotion.Evaluation, error) {
+	// 合并同一商品的多行
+	quantities := make(map[uint]int, len(input.Items))
+	ids := make([]uint, 0, len(input.Items))
+	for _, item := range input.Items {
+		if _, ok := quantities[item.ProductID]; !ok {
+			ids = append(ids, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	basket := promotion.Basket{UserID: userID, Codes: input.Codes}
+	for _, p := range products {
+		if !p.IsVisible() {
+			continue
+		}
+		if available := p.AvailableStock(); quantities[p.ID] > available {
+			return nil, apierror.NewInsufficientStockError("库存不足", fmt.Sprintf("商品 %s 可售库存 %d", p.Name, available))
+		}
+
+		// 与下单时一样实时查询链上持仓，持有者按专属价格计算
+		gate, err := s.gateService.CheckPurchase(ctx, userID, p.ID, true)
+		if err != nil {
+			return nil, err
+		}
+		basket.Lines = append(basket.Lines, promotion.BasketLine{
+			ProductID:  p.ID,
+			CategoryID: p.CategoryID,
+			Quantity:   quantities[p.ID],
+			UnitPrice:  gate.Price,
+		})
+	}
+	if len(basket.Lines) != len(ids) {
+		return nil, apierror.NewValidationError("商品不存在或已下架", fmt.Sprintf("有效商品 %d 个，请求 %d 个", len(basket.Lines), len(ids)))
+	}
+
+	return s.Evaluate(ctx, basket)
+}
+
+// Redeem 下单时记录生效的促销
+func (s *DefaultPromotionService) Redeem(ctx context.Context, orderID uint, userID uint, evaluation *promotion.Evaluation) error {
+	if len(evaluation.Applied) == 0 {
+		return nil
+	}
+
+	redemptions := make([]promotion.Redemption, 0, len(evaluation.Applied))
+	for _, applied := range evaluation.Applied {
+		redemptions = append(redemptions, promotion.Redemption{
+			PromotionID: applied.PromotionID,
+			UserID:      userID,
+			OrderID:     orderID,
+			Amount:      applied.Discount + applied.ShippingDiscount,
+		})
+	}
+	return s.promotionRepo.Redeem(ctx, redemptions)
+}
+
+// Release 订单取消后归还促销使用次数
+func (s *DefaultPromotionService) Release(ctx context.Context, orderID uint) error {
+	return s.promotionRepo.ReleaseOrder(ctx, orderID)
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"web3-ecommerce-app/internal/domain/review"
+	"web3-ecommerce-app/internal/module/review/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewHTTPHandler 商品评价HTTP处理器
+type ReviewHTTPHandler struct {
+	reviewService service.ReviewService
+}
+
+// NewReviewHTTPHandler 创建商品评价HTTP处理器
+func NewReviewHTTPHandler(reviewService service.ReviewService) *ReviewHTTPHandler {
+	return &ReviewHTTPHandler{
+		reviewService: reviewService,
+	}
+}
+
+// ListProductReviews 获取商品的评价列表
+func (h *ReviewHTTPHandler) ListProductReviews(c *gin.Context) {
+	productID, ok := h.getID(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	rating, _ := strconv.Atoi(c.DefaultQuery("rating", "0"))
+
+	result, err := h.reviewService.ListProductReviews(c.Request.Context(), review.ReviewQuery{
+		Page:      page,
+		PageSize:  pageSize,
+		ProductID: productID,
+		Rating:    rating,
+		Sort:      c.DefaultQuery("sort", review.ReviewSortNewest),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateReview 评价商品
+func (h *ReviewHTTPHandler) CreateReview(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	productID, ok := h.getID(c)
+	if !ok {
+		return
+	}
+
+	var input review.CreateReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	reviewEntity, err := h.reviewService.CreateReview(c.Request.Context(), userID, productID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reviewEntity)
+}
+
+// UpdateReview 修改自己的评价
+func (h *ReviewHTTPHandler) UpdateReview(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getID(c)
+	if !ok {
+		return
+	}
+
+	var input review.UpdateReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	reviewEntity, err := h.reviewService.UpdateReview(c.Request.Context(), userID, id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reviewEntity)
+}
+
+// DeleteReview 删除自己的评价
+func (h *ReviewHTTPHandler) DeleteReview(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.reviewService.DeleteReview(c.Request.Context(), userID, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "评价删除成功"})
+}
+
+// VoteHelpful 标记评价有帮助
+func (h *ReviewHTTPHandler) VoteHelpful(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.reviewService.VoteHelpful(c.Request.Context(), userID, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "投票成功"})
+}
+
+// UnvoteHelpful 撤销有帮助标记
+func (h *ReviewHTTPHandler) UnvoteHelpful(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.reviewService.UnvoteHelpful(c.Request.Context(), userID, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已撤销投票"})
+}
+
+// getID 从路径参数中获取ID，失败时直接写入错误响应
+func (h *ReviewHTTPHandler) getID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的ID", err.Error()),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// getUserID 从JWT中获取当前用户ID，失败时直接写入错误响应
+func (h *ReviewHTTPHandler) getUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// handleError 处理错误
+func (h *ReviewHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/review"
+	productRepo "web3-ecommerce-app/internal/module/product/repository"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReviewModel 是GORM评价模型
+// 评价删除时直接物理删除，避免软删除的记录占用(product_id, user_id)唯一索引
+type ReviewModel struct {
+	gorm.Model
+	ProductID      uint   `gorm:"not null;uniqueIndex:idx_product_user,priority:1;index:idx_product_status,priority:1"`
+	UserID         uint   `gorm:"not null;uniqueIndex:idx_product_user,priority:2;index:idx_user_id"`
+	Rating         int    `gorm:"not null"`
+	Title          string `gorm:"type:varchar(100)"`
+	Content        string `gorm:"type:text;not null"`
+	Status         string `gorm:"type:varchar(20);not null;default:'pending';index:idx_product_status,priority:2;index:idx_status"`
+	HelpfulCount   int    `gorm:"not null;default:0"`
+	ModerationNote string `gorm:"type:varchar(500)"`
+}
+
+// TableName 指定表名
+func (ReviewModel) TableName() string {
+	return "product_reviews"
+}
+
+// ReviewVoteModel 是GORM评价投票模型，每个用户对每条评价只能投一票
+type ReviewVoteModel struct {
+	ID        uint `gorm:"primarykey"`
+	ReviewID  uint `gorm:"not null;uniqueIndex:idx_review_user,priority:1"`
+	UserID    uint `gorm:"not null;uniqueIndex:idx_review_user,priority:2"`
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (ReviewVoteModel) TableName() string {
+	return "product_review_votes"
+}
+
+// GormReviewRepository 是评价仓库的GORM实现
+type GormReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewGormReviewRepository 创建一个新的GORM评价仓库
+func NewGormReviewRepository(db *gorm.DB) review.ReviewRepository {
+	return &GormReviewRepository{db: db}
+}
+
+// reviewToModel 将领域模型转换为GORM模型
+func reviewToModel(r *review.Review) *ReviewModel {
+	return &ReviewModel{
+		Model: gorm.Model{
+			ID:        r.ID,
+			CreatedAt: r.CreatedAt,
+			UpdatedAt: r.UpdatedAt,
+		},
+		ProductID:      r.ProductID,
+		UserID:         r.UserID,
+		Rating:         r.Rating,
+		Title:          r.Title,
+		Content:        r.Content,
+		Status:         r.Status,
+		HelpfulCount:   r.HelpfulCount,
+		ModerationNote: r.ModerationNote,
+	}
+}
+
+// reviewToDomain 将GORM模型转换为领域模型
+func reviewToDomain(m *ReviewModel) *review.Review {
+	return &review.Review{
+		ID:             m.ID,
+		ProductID:      m.ProductID,
+		UserID:         m.UserID,
+		Rating:         m.Rating,
+		Title:          m.Title,
+		Content:        m.Content,
+		Status:         m.Status,
+		HelpfulCount:   m.HelpfulCount,
+		ModerationNote: m.ModerationNote,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// FindByID 根据ID查找评价
+func (r *GormReviewRepository) FindByID(ctx context.Context, id uint) (*review.Review, error) {
+	var model ReviewModel
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("评价不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询评价错误: %w", err)
+	}
+	return reviewToDomain(&model), nil
+}
+
+// Find 按条件分页查询评价
+func (r *GormReviewRepository) Find(ctx context.Context, query review.ReviewQuery) (*review.ReviewPaginationResult, error) {
+	var models []ReviewModel
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&ReviewModel{})
+	if query.ProductID != 0 {
+		db = db.Where("product_id = ?", query.ProductID)
+	}
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Rating != 0 {
+		db = db.Where("rating = ?", query.Rating)
+	}
+
+	// 查询总数
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询评价总数错误: %w", err)
+	}
+
+	order := "id DESC"
+	if query.Sort == review.ReviewSortHelpful {
+		order = "helpful_count DESC, id DESC"
+	}
+
+	// 分页查询
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order(order).Offset(offset).Limit(query.PageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询评价列表错误: %w", err)
+	}
+
+	reviews := make([]review.Review, 0, len(models))
+	for i := range models {
+		reviews = append(reviews, *reviewToDomain(&models[i]))
+	}
+
+	return &review.ReviewPaginationResult{
+		Total:   int(total),
+		Reviews: reviews,
+	}, nil
+}
+
+// Create 创建评价
+func (r *GormReviewRepository) Create(ctx context.Context, rv *review.Review) error {
+	model := reviewToModel(rv)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProduct(tx, rv.ProductID); err != nil {
+			return err
+		}
+		if err := tx.Create(model).Error; err != nil {
+			if tx.Where("product_id = ? AND user_id = ?", rv.ProductID, rv.UserID).First(&ReviewModel{}).Error == nil {
+				return apierror.NewDuplicateEntityError("已经评价过该商品", fmt.Sprintf("商品ID: %d", rv.ProductID))
+			}
+			return fmt.Errorf("创建评价错误: %w", err)
+		}
+		return refreshProductRating(tx, rv.ProductID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 更新领域模型
+	rv.ID = model.ID
+	rv.CreatedAt = model.CreatedAt
+	rv.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// Update 更新评价内容和状态，有帮助的票数只能通过投票修改
+func (r *GormReviewRepository) Update(ctx context.Context, rv *review.Review) error {
+	model := reviewToModel(rv)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProduct(tx, rv.ProductID); err != nil {
+			return err
+		}
+		if err := tx.Omit("product_id", "user_id", "helpful_count").Save(model).Error; err != nil {
+			return fmt.Errorf("更新评价错误: %w", err)
+		}
+		return refreshProductRating(tx, rv.ProductID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 更新领域模型
+	rv.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// Delete 删除评价及其投票
+func (r *GormReviewRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model ReviewModel
+		if err := tx.First(&model, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return apierror.NewNotFoundError("评价不存在", fmt.Sprintf("ID: %d", id))
+			}
+			return fmt.Errorf("查询评价错误: %w", err)
+		}
+
+		if err := lockProduct(tx, model.ProductID); err != nil {
+			return err
+		}
+		if err := tx.Where("review_id = ?", id).Delete(&ReviewVoteModel{}).Error; err != nil {
+			return fmt.Errorf("删除评价投票错误: %w", err)
+		}
+		if err := tx.Unscoped().Delete(&ReviewModel{}, id).Error; err != nil {
+			return fmt.Errorf("删除评价错误: %w", err)
+		}
+		return refreshProductRating(tx, model.ProductID)
+	})
+}
+
+// AddHelpfulVote 记录有帮助投票并增加评价的票数
+func (r *GormReviewRepository) AddHelpfulVote(ctx context.Context, reviewID uint, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		vote := ReviewVoteModel{ReviewID: reviewID, UserID: userID}
+		if err := tx.Create(&vote).Error; err != nil {
+			if tx.Where("review_id = ? AND user_id = ?", reviewID, userID).First(&ReviewVoteModel{}).Error == nil {
+				return apierror.NewDuplicateEntityError("已经投过票", fmt.Sprintf("评价ID: %d", reviewID))
+			}
+			return fmt.Errorf("创建评价投票错误: %w", err)
+		}
+
+		if err := tx.Model(&ReviewModel{}).Where("id = ?", reviewID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error; err != nil {
+			return fmt.Errorf("更新评价票数错误: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveHelpfulVote 撤销有帮助投票，未投过票时不做任何修改
+func (r *GormReviewRepository) RemoveHelpfulVote(ctx context.Context, reviewID uint, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("review_id = ? AND user_id = ?", reviewID, userID).Delete(&ReviewVoteModel{})
+		if result.Error != nil {
+			return fmt.Errorf("删除评价投票错误: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&ReviewModel{}).Where("id = ? AND helpful_count > 0", reviewID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count - 1")).Error; err != nil {
+			return fmt.Errorf("更新评价票数错误: %w", err)
+		}
+		return nil
+	})
+}
+
+// lockProduct 锁定商品行，使同一商品的评价写入串行执行，保证评分汇总不会互相覆盖
+func lockProduct(tx *gorm.DB, productID uint) error {
+	var model productRepo.ProductModel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model, productID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return apierror.NewNotFoundError("商品不存在", fmt.Sprintf("ID: %d", productID))
+		}
+		return fmt.Errorf("锁定商品错误: %w", err)
+	}
+	return nil
+}
+
+// refreshProductRating 根据审核通过的评价重新计算商品的评分汇总
+// 使用共享锁读取，保证读到其它已提交事务的最新数据
+func refreshProductRating(tx *gorm.DB, productID uint) error {
+	var rows []struct {
+		Rating int
+		Count  int
+	}
+	if err := tx.Model(&ReviewModel{}).Clauses(clause.Locking{Strength: "SHARE"}).
+		Select("rating, COUNT(*) AS count").
+		Where("product_id = ? AND status = ?", productID, review.ReviewStatusApproved).
+		Group("rating").Scan(&rows).Error; err != nil {
+		return fmt.Errorf("统计商品评分错误: %w", err)
+	}
+
+	columns := map[string]interface{}{
+		"rating_count": 0,
+		"rating_sum":   0,
+		"rating_1":     0,
+		"rating_2":     0,
+		"rating_3":     0,
+		"rating_4":     0,
+		"rating_5":     0,
+	}
+	count, sum := 0, 0
+	for _, row := range rows {
+		count += row.Count
+		sum += row.Rating * row.Count
+		columns[fmt.Sprintf("rating_%d", row.Rating)] = row.Count
+	}
+	columns["rating_count"] = count
+	columns["rating_sum"] = sum
+
+	if err := tx.Model(&productRepo.ProductModel{}).Where("id = ?", productID).UpdateColumns(columns).Error; err != nil {
+		return fmt.Errorf("更新商品评分错误: %w", err)
+	}
+	return nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormReviewRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&ReviewModel{}, &ReviewVoteModel{})
+}
@@ -0,0 +1,44 @@
+package review
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/review/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册商品评价模块路由
+// 评价的审核接口由admin模块统一提供
+func RegisterRoutes(router *gin.Engine, handler *handler.ReviewHTTPHandler, jwtConfig *config.JWTConfig) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+	auth := middleware.JWT(jwtConfig)
+
+	// 商品评价路由
+	productRoutes := v1.Group("/products")
+	{
+		// 获取商品评价列表(不需要认证)
+		productRoutes.GET("/:id/reviews", handler.ListProductReviews)
+
+		// 评价已购买的商品
+		productRoutes.POST("/:id/reviews", auth, handler.CreateReview)
+	}
+
+	// 评价管理路由(需要认证)
+	reviewRoutes := v1.Group("/reviews")
+	reviewRoutes.Use(auth)
+	{
+		// 修改自己的评价
+		reviewRoutes.PUT("/:id", handler.UpdateReview)
+
+		// 删除自己的评价
+		reviewRoutes.DELETE("/:id", handler.DeleteReview)
+
+		// 标记评价有帮助
+		reviewRoutes.POST("/:id/helpful", handler.VoteHelpful)
+
+		// 撤销有帮助标记
+		reviewRoutes.DELETE("/:id/helpful", handler.UnvoteHelpful)
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/review"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// ReviewService 商品评价服务接口
+type ReviewService interface {
+	// CreateReview 买家评价已购买的商品
+	CreateReview(ctx context.Context, userID uint, productID uint, input review.CreateReviewInput) (*review.Review, error)
+
+	// UpdateReview 作者修改自己的评价，修改后需要重新审核
+	UpdateReview(ctx context.Context, userID uint, id uint, input review.UpdateReviewInput) (*review.Review, error)
+
+	// DeleteReview 作者删除自己的评价
+	DeleteReview(ctx context.Context, userID uint, id uint) error
+
+	// ListProductReviews 查询商品审核通过的评价
+	ListProductReviews(ctx context.Context, query review.ReviewQuery) (*review.ReviewPaginationResult, error)
+
+	// VoteHelpful 标记评价有帮助
+	VoteHelpful(ctx context.Context, userID uint, id uint) error
+
+	// UnvoteHelpful 撤销有帮助标记
+	UnvoteHelpful(ctx context.Context, userID uint, id uint) error
+
+	// ListReviews 管理员按条件查询评价，默认返回待审核队列
+	ListReviews(ctx context.Context, query review.ReviewQuery) (*review.ReviewPaginationResult, error)
+
+	// ModerateReview 管理员审核通过或隐藏评价
+	ModerateReview(ctx context.Context, id uint, input review.ModerateReviewInput) (*review.Review, error)
+}
+
+// DefaultReviewService 默认商品评价服务实现
+type DefaultReviewService struct {
+	reviewRepo       review.ReviewRepository
+	productRepo      product.ProductRepository
+	purchaseVerifier review.PurchaseVerifier
+}
+
+// NewReviewService 创建商品评价服务
+func NewReviewService(reviewRepo review.ReviewRepository, productRepo product.ProductRepository, purchaseVerifier review.PurchaseVerifier) ReviewService {
+	return &DefaultReviewService{
+		reviewRepo:       reviewRepo,
+		productRepo:      productRepo,
+		purchaseVerifier: purchaseVerifier,
+	}
+}
+
+// CreateReview 校验购买记录后创建待审核的评价
+func (s *DefaultReviewService) CreateReview(ctx context.Context, userID uint, productID uint, input review.CreateReviewInput) (*review.Review, error) {
+	productEntity, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !productEntity.IsVisible() {
+		return nil, apierror.NewNotFoundError("商品不存在", "")
+	}
+
+	if err := s.verifyPurchase(ctx, userID, productID); err != nil {
+		return nil, err
+	}
+
+	newReview := &review.Review{
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    input.Rating,
+		Title:     input.Title,
+		Content:   input.Content,
+		Status:    review.ReviewStatusPending,
+	}
+	if err := s.reviewRepo.Create(ctx, newReview); err != nil {
+		return nil, err
+	}
+
+	return newReview, nil
+}
+
+// UpdateReview 修改评价内容，并重新进入审核队列
+func (s *DefaultReviewService) UpdateReview(ctx context.Context, userID uint, id uint, input review.UpdateReviewInput) (*review.Review, error) {
+	reviewEntity, err := s.findOwnReview(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 订单退款等情况下购买资格可能已失效
+	if err := s.verifyPurchase(ctx, userID, reviewEntity.ProductID); err != nil {
+		return nil, err
+	}
+
+	if input.Rating != nil {
+		reviewEntity.Rating = *input.Rating
+	}
+	if input.Title != nil {
+		reviewEntity.Title = *input.Title
+	}
+	if input.Content != nil {
+		reviewEntity.Content = *input.Content
+	}
+	reviewEntity.Status = review.ReviewStatusPending
+	reviewEntity.ModerationNote = ""
+
+	if err := s.reviewRepo.Update(ctx, reviewEntity); err != nil {
+		return nil, err
+	}
+
+	return reviewEntity, nil
+}
+
+// DeleteReview 删除自己的评价
+func (s *DefaultReviewService) DeleteReview(ctx context.Context, userID uint, id uint) error {
+	if _, err := s.findOwnReview(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.reviewRepo.Delete(ctx, id)
+}
+
+// ListProductReviews 查询商品审核通过的评价
+func (s *DefaultReviewService) ListProductReviews(ctx context.Context, query review.ReviewQuery) (*review.ReviewPaginationResult, error) {
+	query.Status = review.ReviewStatusApproved
+	query.UserID = 0
+	return s.ListReviews(ctx, query)
+}
+
+// VoteHelpful 标记评价有帮助，不能给自己的评价投票
+func (s *DefaultReviewService) VoteHelpful(ctx context.Context, userID uint, id uint) error {
+	reviewEntity, err := s.reviewRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if reviewEntity.Status != review.ReviewStatusApproved {
+		return apierror.NewNotFoundError("评价不存在", "")
+	}
+	if reviewEntity.UserID == userID {
+		return apierror.NewForbiddenError("不能给自己的评价投票", "")
+	}
+
+	return s.reviewRepo.AddHelpfulVote(ctx, id, userID)
+}
+
+// UnvoteHelpful 撤销有帮助标记
+func (s *DefaultReviewService) UnvoteHelpful(ctx context.Context, userID uint, id uint) error {
+	if _, err := s.reviewRepo.FindByID(ctx, id); err != nil {
+		return err
+	}
+	return s.reviewRepo.RemoveHelpfulVote(ctx, id, userID)
+}
+
+// ListReviews 按条件分页查询评价
+func (s *DefaultReviewService) ListReviews(ctx context.Context, query review.ReviewQuery) (*review.ReviewPaginationResult, error) {
+	// 设置默认分页参数
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+
+	return s.reviewRepo.Find(ctx, query)
+}
+
+// ModerateReview 审核评价，审核结果变化会同步更新商品评分
+func (s *DefaultReviewService) ModerateReview(ctx context.Context, id uint, input review.ModerateReviewInput) (*review.Review, error) {
+	if !review.IsValidModerationStatus(input.Status) {
+		return nil, apierror.NewValidationError("无效的审核状态", input.Status)
+	}
+
+	reviewEntity, err := s.reviewRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewEntity.Status = input.Status
+	reviewEntity.ModerationNote = input.Note
+	if err := s.reviewRepo.Update(ctx, reviewEntity); err != nil {
+		return nil, err
+	}
+
+	return reviewEntity, nil
+}
+
+// verifyPurchase 校验用户是否有包含该商品的已支付订单
+func (s *DefaultReviewService) verifyPurchase(ctx context.Context, userID uint, productID uint) error {
+	purchased, err := s.purchaseVerifier.HasPurchased(ctx, userID, productID)
+	if err != nil {
+		return err
+	}
+	if !purchased {
+		return apierror.NewForbiddenError("只有购买过该商品的用户才能评价", "")
+	}
+	return nil
+}
+
+// findOwnReview 查找评价并校验是否属于当前用户
+func (s *DefaultReviewService) findOwnReview(ctx context.Context, userID uint, id uint) (*review.Review, error) {
+	reviewEntity, err := s.reviewRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reviewEntity.UserID != userID {
+		// 不暴露其它用户评价的存在
+		return nil, apierror.NewNotFoundError("评价不存在", "")
+	}
+	return reviewEntity, nil
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"web3-ecommerce-app/internal/domain/shipping"
+	"web3-ecommerce-app/internal/module/shipping/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShippingHTTPHandler 配送HTTP处理器
+type ShippingHTTPHandler struct {
+	shippingService service.ShippingService
+}
+
+// NewShippingHTTPHandler 创建配送HTTP处理器
+func NewShippingHTTPHandler(shippingService service.ShippingService) *ShippingHTTPHandler {
+	return &ShippingHTTPHandler{
+		shippingService: shippingService,
+	}
+}
+
+// QuoteShipping 查询商品配送到指定国家的可选配送方式及运费
+func (h *ShippingHTTPHandler) QuoteShipping(c *gin.Context) {
+	var input shipping.QuoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	quotes, err := h.shippingService.QuoteItems(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotes": quotes})
+}
+
+// handleError 处理错误
+func (h *ShippingHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/shipping"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+)
+
+// ZoneModel 是GORM配送区域模型
+type ZoneModel struct {
+	ID        uint          `gorm:"primarykey"`
+	Name      string        `gorm:"type:varchar(50);not null"`
+	Countries []string      `gorm:"type:text;serializer:json"`
+	Methods   []MethodModel `gorm:"foreignKey:ZoneID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName 指定表名
+func (ZoneModel) TableName() string {
+	return "shipping_zones"
+}
+
+// MethodModel 是GORM配送方式模型
+type MethodModel struct {
+	ID          uint                  `gorm:"primarykey"`
+	ZoneID      uint                  `gorm:"not null;index:idx_zone_id"`
+	Name        string                `gorm:"type:varchar(50);not null"`
+	RateType    string                `gorm:"type:varchar(20);not null"`
+	FlatRate    int64                 `gorm:"not null;default:0"`
+	WeightRates []shipping.WeightRate `gorm:"type:text;serializer:json"`
+	FreeOver    *int64
+	MinDays     int  `gorm:"not null;default:0"`
+	MaxDays     int  `gorm:"not null;default:0"`
+	Active      bool `gorm:"not null;default:true"`
+}
+
+// TableName 指定表名
+func (MethodModel) TableName() string {
+	return "shipping_methods"
+}
+
+// GormZoneRepository 是配送区域仓库的GORM实现
+type GormZoneRepository struct {
+	db *gorm.DB
+}
+
+// NewGormZoneRepository 创建一个新的GORM配送区域仓库
+func NewGormZoneRepository(db *gorm.DB) shipping.ZoneRepository {
+	return &GormZoneRepository{db: db}
+}
+
+// methodToModel 将领域模型转换为GORM模型
+func methodToModel(m *shipping.Method) MethodModel {
+	model := MethodModel{
+		ID:          m.ID,
+		ZoneID:      m.ZoneID,
+		Name:        m.Name,
+		RateType:    m.RateType,
+		FlatRate:    int64(m.FlatRate),
+		WeightRates: m.WeightRates,
+		MinDays:     m.MinDays,
+		MaxDays:     m.MaxDays,
+		Active:      m.Active,
+	}
+	if m.FreeOver != nil {
+		v := int64(*m.FreeOver)
+		model.FreeOver = &v
+	}
+	return model
+}
+
+// methodToDomain 将GORM模型转换为领域模型
+func methodToDomain(m *MethodModel) shipping.Method {
+	method := shipping.Method{
+		ID:          m.ID,
+		ZoneID:      m.ZoneID,
+		Name:        m.Name,
+		RateType:    m.RateType,
+		FlatRate:    common.Money(m.FlatRate),
+		WeightRates: m.WeightRates,
+		MinDays:     m.MinDays,
+		MaxDays:     m.MaxDays,
+		Active:      m.Active,
+	}
+	if m.FreeOver != nil {
+		v := common.Money(*m.FreeOver)
+		method.FreeOver = &v
+	}
+	return method
+}
+
+// zoneToModel 将领域模型转换为GORM模型
+func zoneToModel(z *shipping.Zone) *ZoneModel {
+	methods := make([]MethodModel, 0, len(z.Methods))
+	for i := range z.Methods {
+		methods = append(methods, methodToModel(&z.Methods[i]))
+	}
+	return &ZoneModel{
+		ID:        z.ID,
+		Name:      z.Name,
+		Countries: z.Countries,
+		Methods:   methods,
+		CreatedAt: z.CreatedAt,
+		UpdatedAt: z.UpdatedAt,
+	}
+}
+
+// zoneToDomain 将GORM模型转换为领域模型
+func zoneToDomain(m *ZoneModel) *shipping.Zone {
+	methods := make([]shipping.Method, 0, len(m.Methods))
+	for i := range m.Methods {
+		methods = append(methods, methodToDomain(&m.Methods[i]))
+	}
+	return &shipping.Zone{
+		ID:        m.ID,
+		Name:      m.Name,
+		Countries: m.Countries,
+		Methods:   methods,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// FindAll 查询全部配送区域及其配送方式
+func (r *GormZoneRepository) FindAll(ctx context.Context) ([]shipping.Zone, error) {
+	var models []ZoneModel
+	if err := database.Conn(ctx, r.db).Preload("Methods", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Order("id ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询配送区域列表错误: %w", err)
+	}
+
+	zones := make([]shipping.Zone, 0, len(models))
+	for i := range models {
+		zones = append(zones, *zoneToDomain(&models[i]))
+	}
+	return zones, nil
+}
+
+// FindByID 根据ID查询配送区域
+func (r *GormZoneRepository) FindByID(ctx context.Context, id uint) (*shipping.Zone, error) {
+	var model ZoneModel
+	if err := database.Conn(ctx, r.db).Preload("Methods", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("配送区域不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询配送区域错误: %w", err)
+	}
+	return zoneToDomain(&model), nil
+}
+
+// Create 创建配送区域及其配送方式
+func (r *GormZoneRepository) Create(ctx context.Context, zone *shipping.Zone) error {
+	model := zoneToModel(zone)
+	if err := database.Conn(ctx, r.db).Create(model).Error; err != nil {
+		return fmt.Errorf("创建配送区域错误: %w", err)
+	}
+
+	*zone = *zoneToDomain(model)
+	return nil
+}
+
+// Update 更新配送区域，配送方式整体替换
+// 订单中保存了配送方式名称的快照，删除旧的配送方式不影响已有订单
+func (r *GormZoneRepository) Update(ctx context.Context, zone *shipping.Zone) error {
+	model := zoneToModel(zone)
+	err := database.Transaction(ctx, r.db, func(ctx context.Context) error {
+		tx := database.Conn(ctx, r.db)
+
+		result := tx.Model(&ZoneModel{}).Where("id = ?", zone.ID).
+			Select("name", "countries", "updated_at").Updates(model)
+		if result.Error != nil {
+			return fmt.Errorf("更新配送区域错误: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apierror.NewNotFoundError("配送区域不存在", fmt.Sprintf("ID: %d", zone.ID))
+		}
+
+		if err := tx.Where("zone_id = ?", zone.ID).Delete(&MethodModel{}).Error; err != nil {
+			return fmt.Errorf("删除配送方式错误: %w", err)
+		}
+		for i := range model.Methods {
+			model.Methods[i].ID = 0
+			model.Methods[i].ZoneID = zone.ID
+		}
+		if len(model.Methods) > 0 {
+			if err := tx.Create(&model.Methods).Error; err != nil {
+				return fmt.Errorf("创建配送方式错误: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	updated, err := r.FindByID(ctx, zone.ID)
+	if err != nil {
+		return err
+	}
+	*zone = *updated
+	return nil
+}
+
+// Delete 删除配送区域及其配送方式
+func (r *GormZoneRepository) Delete(ctx context.Context, id uint) error {
+	return database.Transaction(ctx, r.db, func(ctx context.Context) error {
+		tx := database.Conn(ctx, r.db)
+
+		result := tx.Delete(&ZoneModel{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("删除配送区域错误: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apierror.NewNotFoundError("配送区域不存在", fmt.Sprintf("ID: %d", id))
+		}
+		if err := tx.Where("zone_id = ?", id).Delete(&MethodModel{}).Error; err != nil {
+			return fmt.Errorf("删除配送方式错误: %w", err)
+		}
+		return nil
+	})
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormZoneRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&ZoneModel{}, &MethodModel{})
+}
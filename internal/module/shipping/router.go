@@ -0,0 +1,21 @@
+package shipping
+
+import (
+	"web3-ecommerce-app/internal/module/shipping/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册配送模块路由
+// 配送区域的管理接口由admin模块统一提供
+func RegisterRoutes(router *gin.Engine, handler *handler.ShippingHTTPHandler) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 配送路由(公开，游客结算时也需要查询运费)
+	shippingRoutes := v1.Group("/shipping")
+	{
+		// 查询运费
+		shippingRoutes.POST("/quotes", handler.QuoteShipping)
+	}
+}
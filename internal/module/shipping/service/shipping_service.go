@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/shipping"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// ShippingService 配送服务接口
+type ShippingService interface {
+	// ListZones 获取全部配送区域
+	ListZones(ctx context.Context) ([]shipping.Zone, error)
+
+	// GetZone 获取配送区域
+	GetZone(ctx context.Context, id uint) (*shipping.Zone, error)
+
+	// CreateZone 创建配送区域，国家不能与其它区域重复
+	CreateZone(ctx context.Context, input shipping.ZoneInput) (*shipping.Zone, error)
+
+	// UpdateZone 更新配送区域，配送方式整体替换
+	UpdateZone(ctx context.Context, id uint, input shipping.ZoneInput) (*shipping.Zone, error)
+
+	// DeleteZone 删除配送区域
+	DeleteZone(ctx context.Context, id uint) error
+
+	// Quote 查询收货国家可用的配送方式及运费，超重的配送方式不返回
+	Quote(ctx context.Context, req shipping.QuoteRequest) ([]shipping.Quote, error)
+
+	// QuoteMethod 计算指定配送方式的运费，配送方式不适用于收货国家时返回错误
+	QuoteMethod(ctx context.Context, methodID uint, req shipping.QuoteRequest) (*shipping.Quote, error)
+
+	// QuoteItems 按商品的当前售价和重量估算运费，用于结算页展示
+	QuoteItems(ctx context.Context, input shipping.QuoteInput) ([]shipping.Quote, error)
+}
+
+// DefaultShippingService 默认配送服务实现
+type DefaultShippingService struct {
+	zoneRepo    shipping.ZoneRepository
+	productRepo product.ProductRepository
+}
+
+// NewShippingService 创建配送服务
+func NewShippingService(zoneRepo shipping.ZoneRepository, productRepo product.ProductRepository) ShippingService {
+	return &DefaultShippingService{
+		zoneRepo:    zoneRepo,
+		productRepo: productRepo,
+	}
+}
+
+// ListZones 获取全部配送区域
+func (s *DefaultShippingService) ListZones(ctx context.Context) ([]shipping.Zone, error) {
+	return s.zoneRepo.FindAll(ctx)
+}
+
+// GetZone 获取配送区域
+func (s *DefaultShippingService) GetZone(ctx context.Context, id uint) (*shipping.Zone, error) {
+	return s.zoneRepo.FindByID(ctx, id)
+}
+
+// CreateZone 创建配送区域
+func (s *DefaultShippingService) CreateZone(ctx context.Context, input shipping.ZoneInput) (*shipping.Zone, error) {
+	zone, err := s.buildZone(ctx, 0, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.zoneRepo.Create(ctx, zone); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+// UpdateZone 更新配送区域
+func (s *DefaultShippingService) UpdateZone(ctx context.Context, id uint, input shipping.ZoneInput) (*shipping.Zone, error) {
+	if _, err := s.zoneRepo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	zone, err := s.buildZone(ctx, id, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.zoneRepo.Update(ctx, zone); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+// DeleteZone 删除配送区域
+func (s *DefaultShippingService) DeleteZone(ctx context.Context, id uint) error {
+	return s.zoneRepo.Delete(ctx, id)
+}
+
+// Quote 查询收货国家可用的配送方式及运费
+func (s *DefaultShippingService) Quote(ctx context.Context, req shipping.QuoteRequest) ([]shipping.Quote, error) {
+	zone, err := s.findZone(ctx, req.Country)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make([]shipping.Quote, 0, len(zone.Methods))
+	for i := range zone.Methods {
+		method := &zone.Methods[i]
+		if !method.Active {
+			continue
+		}
+		quote, err := shipping.NewQuote(method, req)
+		if err != nil {
+			continue
+		}
+		quotes = append(quotes, *quote)
+	}
+	if len(quotes) == 0 {
+		return nil, apierror.NewValidationError("没有可用的配送方式", fmt.Sprintf("国家: %s，重量: %d 克", req.Country, req.Weight))
+	}
+	return quotes, nil
+}
+
+// QuoteMethod 计算指定配送方式的运费
+func (s *DefaultShippingService) QuoteMethod(ctx context.Context, methodID uint, req shipping.QuoteRequest) (*shipping.Quote, error) {
+	zone, err := s.findZone(ctx, req.Country)
+	if err != nil {
+		return nil, err
+	}
+	for i := range zone.Methods {
+		method := &zone.Methods[i]
+		if method.ID == methodID && method.Active {
+			return shipping.NewQuote(method, req)
+		}
+	}
+	return nil, apierror.NewValidationError("配送方式不可用", fmt.Sprintf("配送方式 %d 不支持配送到 %s", methodID, req.Country))
+}
+
+// QuoteItems 按商品的当前售价和重量估算运费
+// 持有代币的用户下单时可能享受折扣价，实际运费以下单时计算的为准
+func (s *DefaultShippingService) QuoteItems(ctx context.Context, input shipping.QuoteInput) ([]shipping.Quote, error) {
+	ids := make([]uint, 0, len(input.Items))
+	for _, item := range input.Items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*product.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	req := shipping.QuoteRequest{Country: strings.ToUpper(strings.TrimSpace(input.Country))}
+	physical := false
+	for _, item := range input.Items {
+		p, ok := byID[item.ProductID]
+		if !ok || !p.IsVisible() {
+			return nil, apierror.NewNotFoundError("商品不存在或已下架", fmt.Sprintf("ID: %d", item.ProductID))
+		}
+		if p.IsDigital() {
+			continue
+		}
+		physical = true
+		req.Subtotal += p.Price.Mul(item.Quantity)
+		req.Weight += p.Weight * item.Quantity
+	}
+	if !physical {
+		// 数字商品无需配送
+		return []shipping.Quote{}, nil
+	}
+	return s.Quote(ctx, req)
+}
+
+// findZone 查找收货国家所属的配送区域，没有专门区域时使用兜底区域
+func (s *DefaultShippingService) findZone(ctx context.Context, country string) (*shipping.Zone, error) {
+	zones, err := s.zoneRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var fallback *shipping.Zone
+	for i := range zones {
+		if zones[i].Covers(country) {
+			return &zones[i], nil
+		}
+		if fallback == nil && zones[i].IsRestOfWorld() {
+			fallback = &zones[i]
+		}
+	}
+	if fallback == nil {
+		return nil, apierror.NewValidationError("该地区暂不支持配送", fmt.Sprintf("国家: %s", country))
+	}
+	return fallback, nil
+}
+
+// buildZone 校验输入并构造配送区域，id为0表示新建
+func (s *DefaultShippingService) buildZone(ctx context.Context, id uint, input shipping.ZoneInput) (*shipping.Zone, error) {
+	countries := shipping.NormalizeCountries(input.Countries)
+	for _, c := range countries {
+		if c != shipping.RestOfWorld && (len(c) != 2 || c[0] < 'A' || c[0] > 'Z' || c[1] < 'A' || c[1] > 'Z') {
+			return nil, apierror.NewValidationError("无效的国家代码", fmt.Sprintf("%q 不是ISO 3166-1二字码", c))
+		}
+	}
+
+	// 一个国家只能属于一个区域，否则运费取决于区域的查询顺序
+	zones, err := s.zoneRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, z := range zones {
+		if z.ID == id {
+			continue
+		}
+		for _, c := range countries {
+			if z.Covers(c) {
+				return nil, apierror.NewDuplicateEntityError("国家已属于其它配送区域", fmt.Sprintf("%s 已属于区域 %s", c, z.Name))
+			}
+		}
+	}
+
+	zone := &shipping.Zone{
+		ID:        id,
+		Name:      strings.TrimSpace(input.Name),
+		Countries: countries,
+		Methods:   make([]shipping.Method, 0, len(input.Methods)),
+	}
+	for _, m := range input.Methods {
+		method := shipping.Method{
+			ZoneID:   id,
+			Name:     strings.TrimSpace(m.Name),
+			RateType: m.RateType,
+			FreeOver: m.FreeOver,
+			MinDays:  m.MinDays,
+			MaxDays:  m.MaxDays,
+			Active:   m.Active == nil || *m.Active,
+		}
+		if m.RateType == shipping.RateTypeWeight {
+			method.WeightRates = append([]shipping.WeightRate(nil), m.WeightRates...)
+			sort.Slice(method.WeightRates, func(i, j int) bool {
+				return method.WeightRates[i].MaxWeight < method.WeightRates[j].MaxWeight
+			})
+		} else {
+			method.FlatRate = m.FlatRate
+		}
+		if err := method.Validate(); err != nil {
+			return nil, err
+		}
+		zone.Methods = append(zone.Methods, method)
+	}
+	return zone, nil
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"web3-ecommerce-app/internal/domain/subscription"
+	"web3-ecommerce-app/internal/module/subscription/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubscriptionHTTPHandler 订阅HTTP处理器
+type SubscriptionHTTPHandler struct {
+	subscriptionService service.SubscriptionService
+}
+
+// NewSubscriptionHTTPHandler 创建订阅HTTP处理器
+func NewSubscriptionHTTPHandler(subscriptionService service.SubscriptionService) *SubscriptionHTTPHandler {
+	return &SubscriptionHTTPHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+// ListPlans 获取可订阅的计划，可按商品过滤
+func (h *SubscriptionHTTPHandler) ListPlans(c *gin.Context) {
+	productID, _ := strconv.ParseUint(c.DefaultQuery("product_id", "0"), 10, 32)
+
+	plans, err := h.subscriptionService.ListPlans(c.Request.Context(), subscription.PlanQuery{
+		ProductID: uint(productID),
+		Status:    subscription.PlanStatusActive,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"plans": plans})
+}
+
+// ListSubscriptions 获取当前用户的订阅
+func (h *SubscriptionHTTPHandler) ListSubscriptions(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	subscriptions, err := h.subscriptionService.ListUserSubscriptions(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subscriptions})
+}
+
+// Subscribe 订阅计划
+func (h *SubscriptionHTTPHandler) Subscribe(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var input subscription.SubscribeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	sub, err := h.subscriptionService.Subscribe(c.Request.Context(), userID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+// GetSubscription 获取当前用户的某个订阅
+func (h *SubscriptionHTTPHandler) GetSubscription(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.GetUserSubscription(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// CancelSubscription 取消订阅，默认到当前周期结束后取消
+func (h *SubscriptionHTTPHandler) CancelSubscription(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	var input subscription.CancelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	sub, err := h.subscriptionService.CancelUserSubscription(c.Request.Context(), userID, id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// ResumeSubscription 撤销到期取消
+func (h *SubscriptionHTTPHandler) ResumeSubscription(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.getSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.ResumeUserSubscription(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// getSubscriptionID 解析路径中的订阅ID，失败时直接写入错误响应
+func (h *SubscriptionHTTPHandler) getSubscriptionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的订阅ID", err.Error()),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// getUserID 获取当前登录用户ID，失败时直接写入错误响应
+func (h *SubscriptionHTTPHandler) getUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// handleError 处理错误
+func (h *SubscriptionHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
@@ -0,0 +1,351 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/subscription"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PlanModel 是GORM订阅计划模型
+type PlanModel struct {
+	ID            uint   `gorm:"primarykey"`
+	ProductID     uint   `gorm:"not null;index:idx_product_id"`
+	Name          string `gorm:"type:varchar(100);not null"`
+	Description   string `gorm:"type:text"`
+	Interval      string `gorm:"type:varchar(10);not null"`
+	IntervalCount int    `gorm:"not null;default:1"`
+	Price         int64  `gorm:"not null"`
+	TrialDays     int    `gorm:"not null;default:0"`
+	Status        string `gorm:"type:varchar(20);not null;index:idx_status"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName 指定表名
+func (PlanModel) TableName() string {
+	return "subscription_plans"
+}
+
+// SubscriptionModel 是GORM订阅模型
+type SubscriptionModel struct {
+	ID                 uint   `gorm:"primarykey"`
+	UserID             uint   `gorm:"not null;index:idx_user_plan,priority:1"`
+	PlanID             uint   `gorm:"not null;index:idx_user_plan,priority:2"`
+	ProductID          uint   `gorm:"not null"`
+	Status             string `gorm:"type:varchar(20);not null;index:idx_status_period_end,priority:1"`
+	TrialEndsAt        *time.Time
+	AnchorAt           *time.Time
+	Periods            int `gorm:"not null;default:0"`
+	CurrentPeriodStart *time.Time
+	CurrentPeriodEnd   *time.Time `gorm:"index:idx_status_period_end,priority:2"`
+	CancelAtPeriodEnd  bool       `gorm:"not null;default:false"`
+	PendingOrderID     uint       `gorm:"not null;default:0;index:idx_pending_order_id"`
+	PaymentDueAt       *time.Time
+	DunningCount       int `gorm:"not null;default:0"`
+	LastDunningAt      *time.Time
+	CancelledAt        *time.Time
+	CancelReason       string `gorm:"type:varchar(500);not null;default:''"`
+	CancelledBy        string `gorm:"type:varchar(20);not null;default:''"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// TableName 指定表名
+func (SubscriptionModel) TableName() string {
+	return "subscriptions"
+}
+
+// GormSubscriptionRepository 是订阅仓库的GORM实现
+type GormSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSubscriptionRepository 创建一个新的GORM订阅仓库
+func NewGormSubscriptionRepository(db *gorm.DB) subscription.SubscriptionRepository {
+	return &GormSubscriptionRepository{db: db}
+}
+
+// planToModel 将领域模型转换为GORM模型
+func planToModel(p *subscription.Plan) *PlanModel {
+	return &PlanModel{
+		ID:            p.ID,
+		ProductID:     p.ProductID,
+		Name:          p.Name,
+		Description:   p.Description,
+		Interval:      p.Interval,
+		IntervalCount: p.IntervalCount,
+		Price:         int64(p.Price),
+		TrialDays:     p.TrialDays,
+		Status:        p.Status,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
+
+// planToDomain 将GORM模型转换为领域模型
+func planToDomain(m *PlanModel) *subscription.Plan {
+	return &subscription.Plan{
+		ID:            m.ID,
+		ProductID:     m.ProductID,
+		Name:          m.Name,
+		Description:   m.Description,
+		Interval:      m.Interval,
+		IntervalCount: m.IntervalCount,
+		Price:         common.Money(m.Price),
+		TrialDays:     m.TrialDays,
+		Status:        m.Status,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+// subscriptionToModel 将领域模型转换为GORM模型
+func subscriptionToModel(s *subscription.Subscription) *SubscriptionModel {
+	return &SubscriptionModel{
+		ID:                 s.ID,
+		UserID:             s.UserID,
+		PlanID:             s.PlanID,
+		ProductID:          s.ProductID,
+		Status:             s.Status,
+		TrialEndsAt:        s.TrialEndsAt,
+		AnchorAt:           s.AnchorAt,
+		Periods:            s.Periods,
+		CurrentPeriodStart: s.CurrentPeriodStart,
+		CurrentPeriodEnd:   s.CurrentPeriodEnd,
+		CancelAtPeriodEnd:  s.CancelAtPeriodEnd,
+		PendingOrderID:     s.PendingOrderID,
+		PaymentDueAt:       s.PaymentDueAt,
+		DunningCount:       s.DunningCount,
+		LastDunningAt:      s.LastDunningAt,
+		CancelledAt:        s.CancelledAt,
+		CancelReason:       s.CancelReason,
+		CancelledBy:        s.CancelledBy,
+		CreatedAt:          s.CreatedAt,
+		UpdatedAt:          s.UpdatedAt,
+	}
+}
+
+// subscriptionToDomain 将GORM模型转换为领域模型
+func subscriptionToDomain(m *SubscriptionModel) *subscription.Subscription {
+	return &subscription.Subscription{
+		ID:                 m.ID,
+		UserID:             m.UserID,
+		PlanID:             m.PlanID,
+		ProductID:          m.ProductID,
+		Status:             m.Status,
+		TrialEndsAt:        m.TrialEndsAt,
+		AnchorAt:           m.AnchorAt,
+		Periods:            m.Periods,
+		CurrentPeriodStart: m.CurrentPeriodStart,
+		CurrentPeriodEnd:   m.CurrentPeriodEnd,
+		CancelAtPeriodEnd:  m.CancelAtPeriodEnd,
+		PendingOrderID:     m.PendingOrderID,
+		PaymentDueAt:       m.PaymentDueAt,
+		DunningCount:       m.DunningCount,
+		LastDunningAt:      m.LastDunningAt,
+		CancelledAt:        m.CancelledAt,
+		CancelReason:       m.CancelReason,
+		CancelledBy:        m.CancelledBy,
+		CreatedAt:          m.CreatedAt,
+		UpdatedAt:          m.UpdatedAt,
+	}
+}
+
+// subscriptionsToDomain 批量转换为领域模型
+func subscriptionsToDomain(models []SubscriptionModel) []subscription.Subscription {
+	subscriptions := make([]subscription.Subscription, 0, len(models))
+	for i := range models {
+		subscriptions = append(subscriptions, *subscriptionToDomain(&models[i]))
+	}
+	return subscriptions
+}
+
+// FindPlanByID 根据ID查询订阅计划
+func (r *GormSubscriptionRepository) FindPlanByID(ctx context.Context, id uint) (*subscription.Plan, error) {
+	var model PlanModel
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("订阅计划不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询订阅计划错误: %w", err)
+	}
+	return planToDomain(&model), nil
+}
+
+// FindPlans 按条件查询订阅计划
+func (r *GormSubscriptionRepository) FindPlans(ctx context.Context, query subscription.PlanQuery) ([]subscription.Plan, error) {
+	db := database.Conn(ctx, r.db).Model(&PlanModel{})
+	if query.ProductID != 0 {
+		db = db.Where("product_id = ?", query.ProductID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var models []PlanModel
+	if err := db.Order("price ASC").Order("id ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询订阅计划列表错误: %w", err)
+	}
+
+	plans := make([]subscription.Plan, 0, len(models))
+	for i := range models {
+		plans = append(plans, *planToDomain(&models[i]))
+	}
+	return plans, nil
+}
+
+// CreatePlan 创建订阅计划
+func (r *GormSubscriptionRepository) CreatePlan(ctx context.Context, plan *subscription.Plan) error {
+	model := planToModel(plan)
+	if err := database.Conn(ctx, r.db).Create(model).Error; err != nil {
+		return fmt.Errorf("创建订阅计划错误: %w", err)
+	}
+
+	*plan = *planToDomain(model)
+	return nil
+}
+
+// UpdatePlan 更新订阅计划
+func (r *GormSubscriptionRepository) UpdatePlan(ctx context.Context, plan *subscription.Plan) error {
+	model := planToModel(plan)
+	result := database.Conn(ctx, r.db).Model(&PlanModel{}).Where("id = ?", plan.ID).
+		Select("name", "description", "price", "trial_days", "status", "updated_at").Updates(model)
+	if result.Error != nil {
+		return fmt.Errorf("更新订阅计划错误: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apierror.NewNotFoundError("订阅计划不存在", fmt.Sprintf("ID: %d", plan.ID))
+	}
+	return nil
+}
+
+// FindByID 根据ID查询订阅
+func (r *GormSubscriptionRepository) FindByID(ctx context.Context, id uint) (*subscription.Subscription, error) {
+	var model SubscriptionModel
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("订阅不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询订阅错误: %w", err)
+	}
+	return subscriptionToDomain(&model), nil
+}
+
+// LockByID 在事务中锁定订阅行并返回最新的订阅
+func (r *GormSubscriptionRepository) LockByID(ctx context.Context, id uint) (*subscription.Subscription, error) {
+	var model SubscriptionModel
+	if err := database.Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("订阅不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("锁定订阅错误: %w", err)
+	}
+	return subscriptionToDomain(&model), nil
+}
+
+// FindByUser 查询用户的全部订阅
+func (r *GormSubscriptionRepository) FindByUser(ctx context.Context, userID uint) ([]subscription.Subscription, error) {
+	var models []SubscriptionModel
+	if err := database.Conn(ctx, r.db).Where("user_id = ?", userID).
+		Order("created_at DESC").Order("id DESC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询用户订阅错误: %w", err)
+	}
+	return subscriptionsToDomain(models), nil
+}
+
+// FindByPendingOrder 查询等待该订单付款的订阅
+func (r *GormSubscriptionRepository) FindByPendingOrder(ctx context.Context, orderID uint) (*subscription.Subscription, error) {
+	var models []SubscriptionModel
+	if err := database.Conn(ctx, r.db).Where("pending_order_id = ?", orderID).Limit(1).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询订阅错误: %w", err)
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+	return subscriptionToDomain(&models[0]), nil
+}
+
+// Find 按条件分页查询订阅
+func (r *GormSubscriptionRepository) Find(ctx context.Context, query subscription.SubscriptionQuery) (*subscription.SubscriptionPaginationResult, error) {
+	db := database.Conn(ctx, r.db).Model(&SubscriptionModel{})
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.PlanID != 0 {
+		db = db.Where("plan_id = ?", query.PlanID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询订阅总数错误: %w", err)
+	}
+
+	var models []SubscriptionModel
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order("created_at DESC").Order("id DESC").Offset(offset).Limit(query.PageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询订阅列表错误: %w", err)
+	}
+
+	return &subscription.SubscriptionPaginationResult{Total: int(total), Subscriptions: subscriptionsToDomain(models)}, nil
+}
+
+// FindDue 查询需要续费任务处理的订阅
+func (r *GormSubscriptionRepository) FindDue(ctx context.Context, renewBefore time.Time, afterID uint, limit int) ([]subscription.Subscription, error) {
+	var models []SubscriptionModel
+	if err := database.Conn(ctx, r.db).Where("id > ?", afterID).
+		Where(r.db.Where("status IN ?", []string{subscription.StatusIncomplete, subscription.StatusPastDue}).
+			Or("status IN ? AND current_period_end <= ?",
+				[]string{subscription.StatusTrialing, subscription.StatusActive}, renewBefore)).
+		Order("id ASC").Limit(limit).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询到期订阅错误: %w", err)
+	}
+	return subscriptionsToDomain(models), nil
+}
+
+// Create 创建订阅
+func (r *GormSubscriptionRepository) Create(ctx context.Context, s *subscription.Subscription) error {
+	model := subscriptionToModel(s)
+	if err := database.Conn(ctx, r.db).Create(model).Error; err != nil {
+		return fmt.Errorf("创建订阅错误: %w", err)
+	}
+
+	*s = *subscriptionToDomain(model)
+	return nil
+}
+
+// Update 更新订阅
+func (r *GormSubscriptionRepository) Update(ctx context.Context, s *subscription.Subscription) error {
+	model := subscriptionToModel(s)
+	result := database.Conn(ctx, r.db).Model(&SubscriptionModel{}).Where("id = ?", s.ID).
+		Select("status", "trial_ends_at", "anchor_at", "periods", "current_period_start", "current_period_end",
+			"cancel_at_period_end", "pending_order_id", "payment_due_at", "dunning_count", "last_dunning_at",
+			"cancelled_at", "cancel_reason", "cancelled_by", "updated_at").
+		Updates(model)
+	if result.Error != nil {
+		return fmt.Errorf("更新订阅错误: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apierror.NewNotFoundError("订阅不存在", fmt.Sprintf("ID: %d", s.ID))
+	}
+	return nil
+}
+
+// Transaction 在事务中执行fn
+func (r *GormSubscriptionRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.Transaction(ctx, r.db, fn)
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormSubscriptionRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&PlanModel{}, &SubscriptionModel{})
+}
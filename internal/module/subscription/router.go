@@ -0,0 +1,39 @@
+package subscription
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/subscription/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册订阅模块路由
+// 订阅计划管理由admin模块统一提供
+func RegisterRoutes(router *gin.Engine, handler *handler.SubscriptionHTTPHandler, jwtConfig *config.JWTConfig, idempotent gin.HandlerFunc) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 可订阅的计划(公开)
+	v1.GET("/subscription-plans", handler.ListPlans)
+
+	// 当前用户的订阅(需要认证，写操作支持幂等键)
+	subscriptionRoutes := v1.Group("/users/me/subscriptions")
+	subscriptionRoutes.Use(middleware.JWT(jwtConfig), idempotent)
+	{
+		// 获取订阅列表
+		subscriptionRoutes.GET("", handler.ListSubscriptions)
+
+		// 订阅计划，没有试用期时返回的订阅带有待支付的首期订单
+		subscriptionRoutes.POST("", handler.Subscribe)
+
+		// 获取订阅详情
+		subscriptionRoutes.GET("/:id", handler.GetSubscription)
+
+		// 取消订阅
+		subscriptionRoutes.POST("/:id/cancel", handler.CancelSubscription)
+
+		// 撤销到期取消
+		subscriptionRoutes.POST("/:id/resume", handler.ResumeSubscription)
+	}
+}
@@ -0,0 +1,567 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/subscription"
+	orderService "web3-ecommerce-app/internal/module/order/service"
+	"web3-ecommerce-app/internal/platform/notify"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// 订阅通知类型
+const (
+	NotificationTypeRenewalCreated = "subscription_renewal_created" // 续费订单已生成
+	NotificationTypePastDue        = "subscription_past_due"        // 宽限期内的催缴
+	NotificationTypeRenewed        = "subscription_renewed"         // 续费成功
+	NotificationTypeCancelled      = "subscription_cancelled"       // 到期、逾期未付或被管理员取消
+)
+
+// billingBatchSize 续费任务每批处理的订阅数量
+const billingBatchSize = 100
+
+// timeLayout 通知中的时间格式
+const timeLayout = "2006-01-02 15:04"
+
+// SubscriptionService 订阅服务接口
+type SubscriptionService interface {
+	// ListPlans 按条件查询订阅计划
+	ListPlans(ctx context.Context, query subscription.PlanQuery) ([]subscription.Plan, error)
+
+	// GetPlan 获取订阅计划
+	GetPlan(ctx context.Context, id uint) (*subscription.Plan, error)
+
+	// CreatePlan 为数字商品创建订阅计划
+	CreatePlan(ctx context.Context, input subscription.CreatePlanInput) (*subscription.Plan, error)
+
+	// UpdatePlan 更新订阅计划，价格修改从下一次续费开始生效
+	UpdatePlan(ctx context.Context, id uint, input subscription.UpdatePlanInput) (*subscription.Plan, error)
+
+	// ChangePlanStatus 上架或归档订阅计划，归档后不再接受新订阅
+	ChangePlanStatus(ctx context.Context, id uint, status string) (*subscription.Plan, error)
+
+	// Subscribe 用户订阅计划，有试用期且未试用过时直接开始试用，否则生成首期订单
+	Subscribe(ctx context.Context, userID uint, input subscription.SubscribeInput) (*subscription.Subscription, error)
+
+	// ListUserSubscriptions 获取用户的全部订阅
+	ListUserSubscriptions(ctx context.Context, userID uint) ([]subscription.Subscription, error)
+
+	// GetUserSubscription 获取用户自己的订阅
+	GetUserSubscription(ctx context.Context, userID uint, id uint) (*subscription.Subscription, error)
+
+	// CancelUserSubscription 用户取消自己的订阅
+	CancelUserSubscription(ctx context.Context, userID uint, id uint, input subscription.CancelInput) (*subscription.Subscription, error)
+
+	// ResumeUserSubscription 用户在周期结束前撤销到期取消
+	ResumeUserSubscription(ctx context.Context, userID uint, id uint) (*subscription.Subscription, error)
+
+	// ListSubscriptions 按条件分页查询全部订阅
+	ListSubscriptions(ctx context.Context, query subscription.SubscriptionQuery) (*subscription.SubscriptionPaginationResult, error)
+
+	// CancelSubscription 管理员取消订阅
+	CancelSubscription(ctx context.Context, id uint, adminID uint, input subscription.CancelInput) (*subscription.Subscription, error)
+
+	// HandleOrderPaid 处理订单支付事件，首期或续费订单付款后订阅进入下一个周期
+	HandleOrderPaid(ctx context.Context, payload interface{}) error
+
+	// RunBilling 生成续费订单、发送催缴通知并取消到期或逾期未付的订阅，由定时任务调用
+	RunBilling(ctx context.Context) error
+}
+
+// DefaultSubscriptionService 默认订阅服务实现
+type DefaultSubscriptionService struct {
+	subscriptionRepo subscription.SubscriptionRepository
+	productRepo      product.ProductRepository
+	orderService     orderService.OrderService
+	notifier         notify.Notifier
+	cfg              *config.SubscriptionConfig
+}
+
+// NewSubscriptionService 创建订阅服务
+func NewSubscriptionService(
+	subscriptionRepo subscription.SubscriptionRepository,
+	productRepo product.ProductRepository,
+	orderSvc orderService.OrderService,
+	notifier notify.Notifier,
+	cfg *config.SubscriptionConfig,
+) SubscriptionService {
+	return &DefaultSubscriptionService{
+		subscriptionRepo: subscriptionRepo,
+		productRepo:      productRepo,
+		orderService:     orderSvc,
+		notifier:         notifier,
+		cfg:              cfg,
+	}
+}
+
+// ListPlans 按条件查询订阅计划
+func (s *DefaultSubscriptionService) ListPlans(ctx context.Context, query subscription.PlanQuery) ([]subscription.Plan, error) {
+	return s.subscriptionRepo.FindPlans(ctx, query)
+}
+
+// GetPlan 获取订阅计划
+func (s *DefaultSubscriptionService) GetPlan(ctx context.Context, id uint) (*subscription.Plan, error) {
+	return s.subscriptionRepo.FindPlanByID(ctx, id)
+}
+
+// CreatePlan 创建订阅计划
+// 订阅订单没有收货地址，只能为数字商品创建计划
+func (s *DefaultSubscriptionService) CreatePlan(ctx context.Context, input subscription.CreatePlanInput) (*subscription.Plan, error) {
+	p, err := s.productRepo.FindByID(ctx, input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsDigital() {
+		return nil, apierror.NewValidationError("订阅计划只能绑定数字商品", fmt.Sprintf("商品 %s 是实物商品", p.Name))
+	}
+
+	intervalCount := input.IntervalCount
+	if intervalCount == 0 {
+		intervalCount = 1
+	}
+	plan := &subscription.Plan{
+		ProductID:     p.ID,
+		Name:          strings.TrimSpace(input.Name),
+		Description:   strings.TrimSpace(input.Description),
+		Interval:      input.Interval,
+		IntervalCount: intervalCount,
+		Price:         input.Price,
+		TrialDays:     input.TrialDays,
+		Status:        subscription.PlanStatusActive,
+	}
+	if plan.Name == "" {
+		return nil, apierror.NewValidationError("计划名称不能为空", "")
+	}
+	if err := s.subscriptionRepo.CreatePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// UpdatePlan 更新订阅计划
+func (s *DefaultSubscriptionService) UpdatePlan(ctx context.Context, id uint, input subscription.UpdatePlanInput) (*subscription.Plan, error) {
+	plan, err := s.subscriptionRepo.FindPlanByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		plan.Name = strings.TrimSpace(*input.Name)
+		if plan.Name == "" {
+			return nil, apierror.NewValidationError("计划名称不能为空", "")
+		}
+	}
+	if input.Description != nil {
+		plan.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Price != nil {
+		plan.Price = *input.Price
+	}
+	if input.TrialDays != nil {
+		plan.TrialDays = *input.TrialDays
+	}
+
+	if err := s.subscriptionRepo.UpdatePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+	return s.subscriptionRepo.FindPlanByID(ctx, id)
+}
+
+// ChangePlanStatus 修改订阅计划状态
+func (s *DefaultSubscriptionService) ChangePlanStatus(ctx context.Context, id uint, status string) (*subscription.Plan, error) {
+	plan, err := s.subscriptionRepo.FindPlanByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := plan.ChangeStatus(status); err != nil {
+		return nil, err
+	}
+	if err := s.subscriptionRepo.UpdatePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+	return s.subscriptionRepo.FindPlanByID(ctx, id)
+}
+
+// Subscribe 订阅计划
+// 同一计划同时只能有一个未取消的订阅；每个用户每个计划只能试用一次，再次订阅时直接生成首期订单
+func (s *DefaultSubscriptionService) Subscribe(ctx context.Context, userID uint, input subscription.SubscribeInput) (*subscription.Subscription, error) {
+	plan, err := s.subscriptionRepo.FindPlanByID(ctx, input.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.IsActive() {
+		return nil, apierror.NewValidationError("订阅计划已停止订阅", fmt.Sprintf("ID: %d", plan.ID))
+	}
+	p, err := s.productRepo.FindByID(ctx, plan.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsVisible() {
+		return nil, apierror.NewNotFoundError("商品不存在或已下架", fmt.Sprintf("ID: %d", p.ID))
+	}
+
+	existing, err := s.subscriptionRepo.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	subscribedBefore := false
+	for _, sub := range existing {
+		if sub.PlanID != plan.ID {
+			continue
+		}
+		if !sub.IsCancelled() {
+			return nil, apierror.NewDuplicateEntityError("已订阅该计划", fmt.Sprintf("订阅ID: %d", sub.ID))
+		}
+		subscribedBefore = true
+	}
+
+	newSub := &subscription.Subscription{
+		UserID:    userID,
+		PlanID:    plan.ID,
+		ProductID: plan.ProductID,
+		Status:    subscription.StatusIncomplete,
+	}
+	err = s.subscriptionRepo.Transaction(ctx, func(ctx context.Context) error {
+		if plan.TrialDays > 0 && !subscribedBefore {
+			newSub.StartTrial(plan, time.Now())
+			return s.subscriptionRepo.Create(ctx, newSub)
+		}
+
+		if err := s.subscriptionRepo.Create(ctx, newSub); err != nil {
+			return err
+		}
+		o, err := s.orderService.CreateSubscriptionOrder(ctx, order.CreateSubscriptionOrderInput{
+			UserID:    userID,
+			ProductID: plan.ProductID,
+			Price:     plan.Price,
+			Note:      renewalNote(plan, 1),
+		})
+		if err != nil {
+			return err
+		}
+		newSub.AwaitPayment(o.ID, o.ExpiresAt)
+		return s.subscriptionRepo.Update(ctx, newSub)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newSub, nil
+}
+
+// ListUserSubscriptions 获取用户的全部订阅
+func (s *DefaultSubscriptionService) ListUserSubscriptions(ctx context.Context, userID uint) ([]subscription.Subscription, error) {
+	return s.subscriptionRepo.FindByUser(ctx, userID)
+}
+
+// GetUserSubscription 获取用户自己的订阅，其他用户的订阅视为不存在
+func (s *DefaultSubscriptionService) GetUserSubscription(ctx context.Context, userID uint, id uint) (*subscription.Subscription, error) {
+	sub, err := s.subscriptionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub.UserID != userID {
+		return nil, apierror.NewNotFoundError("订阅不存在", fmt.Sprintf("ID: %d", id))
+	}
+	return sub, nil
+}
+
+// CancelUserSubscription 用户取消自己的订阅
+func (s *DefaultSubscriptionService) CancelUserSubscription(ctx context.Context, userID uint, id uint, input subscription.CancelInput) (*subscription.Subscription, error) {
+	if _, err := s.GetUserSubscription(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.cancel(ctx, id, subscription.CancelledByUser, order.Actor{Type: order.ActorUser, ID: userID}, input)
+}
+
+// ResumeUserSubscription 撤销到期取消，续费任务会在周期结束前重新生成续费订单
+func (s *DefaultSubscriptionService) ResumeUserSubscription(ctx context.Context, userID uint, id uint) (*subscription.Subscription, error) {
+	if _, err := s.GetUserSubscription(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.mutate(ctx, id, func(ctx context.Context, sub *subscription.Subscription) error {
+		return sub.Resume(time.Now())
+	})
+}
+
+// ListSubscriptions 按条件分页查询全部订阅
+func (s *DefaultSubscriptionService) ListSubscriptions(ctx context.Context, query subscription.SubscriptionQuery) (*subscription.SubscriptionPaginationResult, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	return s.subscriptionRepo.Find(ctx, query)
+}
+
+// CancelSubscription 管理员取消订阅
+func (s *DefaultSubscriptionService) CancelSubscription(ctx context.Context, id uint, adminID uint, input subscription.CancelInput) (*subscription.Subscription, error) {
+	return s.cancel(ctx, id, subscription.CancelledByAdmin, order.Actor{Type: order.ActorAdmin, ID: adminID}, input)
+}
+
+// HandleOrderPaid 处理订单支付事件
+// 订阅已取消时待支付订单已被关闭，不会再收到该订单的支付事件；逾期付款重新激活的订单也不会恢复已取消的订阅
+// 续费任务会对已付款的待支付订单补做续费，两者在订阅锁内执行，先到的一方清除待支付订单后另一方不再处理
+func (s *DefaultSubscriptionService) HandleOrderPaid(ctx context.Context, payload interface{}) error {
+	event, ok := payload.(order.PaidEvent)
+	if !ok {
+		return fmt.Errorf("无效的订单支付事件: %T", payload)
+	}
+
+	sub, err := s.subscriptionRepo.FindByPendingOrder(ctx, event.OrderID)
+	if err != nil || sub == nil {
+		return err
+	}
+	plan, err := s.subscriptionRepo.FindPlanByID(ctx, sub.PlanID)
+	if err != nil {
+		return err
+	}
+
+	renewed := false
+	sub, err = s.mutate(ctx, sub.ID, func(ctx context.Context, sub *subscription.Subscription) error {
+		// 加锁前订阅可能已被取消或已生成新的待支付订单
+		if sub.PendingOrderID != event.OrderID {
+			return nil
+		}
+		renewed = true
+		return sub.Renew(plan, event.PaidAt)
+	})
+	if err != nil || !renewed {
+		return err
+	}
+
+	s.send(ctx, sub, NotificationTypeRenewed, "订阅已生效",
+		fmt.Sprintf("%s 已生效，当前周期至 %s", plan.Name, sub.CurrentPeriodEnd.Format(timeLayout)), "")
+	return nil
+}
+
+// RunBilling 分批处理到期的订阅，单个订阅处理失败只记录日志，下次执行时重试
+func (s *DefaultSubscriptionService) RunBilling(ctx context.Context) error {
+	now := time.Now()
+	renewBefore := now.Add(s.cfg.RenewAhead)
+	plans := make(map[uint]*subscription.Plan)
+
+	afterID := uint(0)
+	for {
+		subs, err := s.subscriptionRepo.FindDue(ctx, renewBefore, afterID, billingBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range subs {
+			afterID = subs[i].ID
+			plan, ok := plans[subs[i].PlanID]
+			if !ok {
+				plan, err = s.subscriptionRepo.FindPlanByID(ctx, subs[i].PlanID)
+				if err != nil {
+					return err
+				}
+				plans[plan.ID] = plan
+			}
+			if err := s.bill(ctx, subs[i].ID, plan, now); err != nil {
+				log.Printf("处理订阅 %d 的续费失败: %v", subs[i].ID, err)
+			}
+		}
+
+		if len(subs) < billingBatchSize {
+			return nil
+		}
+	}
+}
+
+// bill 锁定订阅后按当前状态执行一步续费流程，事务提交后发送通知
+// 待支付订单被用户取消或超时关闭时清除，在宽限期结束前重新生成续费订单；
+// 待支付订单已付款但订阅尚未续费时先完成续费，不依赖支付事件
+func (s *DefaultSubscriptionService) bill(ctx context.Context, id uint, plan *subscription.Plan, now time.Time) error {
+	type notice struct{ kind, title, body, orderSN string }
+	var notices []notice
+
+	sub, err := s.mutate(ctx, id, func(ctx context.Context, sub *subscription.Subscription) error {
+		var pending *order.Order
+		if sub.PendingOrderID != 0 {
+			o, err := s.orderService.GetOrderByID(ctx, sub.PendingOrderID)
+			if err != nil {
+				return err
+			}
+			switch {
+			case o.IsClosed():
+				sub.ClearPayment()
+			case o.PaidAt != nil:
+				// 支付事件是异步投递的，丢失或处理失败时按订单的付款时间补做续费
+				if err := sub.Renew(plan, *o.PaidAt); err != nil {
+					return err
+				}
+				notices = append(notices, notice{NotificationTypeRenewed, "订阅已生效",
+					fmt.Sprintf("%s 已生效，当前周期至 %s", plan.Name, sub.CurrentPeriodEnd.Format(timeLayout)), ""})
+			default:
+				pending = o
+			}
+		}
+
+		switch sub.Status {
+		case subscription.StatusIncomplete:
+			if pending == nil || !now.Before(*sub.PaymentDueAt) {
+				if err := s.closePendingOrder(ctx, sub, order.SystemActor, "订阅首期订单未按时支付"); err != nil {
+					return err
+				}
+				notices = append(notices, notice{NotificationTypeCancelled, "订阅已取消",
+					fmt.Sprintf("%s 的首期订单未按时支付，订阅已取消", plan.Name), ""})
+				return sub.Cancel(subscription.CancelledBySystem, "首期订单未按时支付", now)
+			}
+			return nil
+		case subscription.StatusTrialing, subscription.StatusActive:
+			if sub.CancelAtPeriodEnd {
+				if now.Before(*sub.CurrentPeriodEnd) {
+					return nil
+				}
+				notices = append(notices, notice{NotificationTypeCancelled, "订阅已到期",
+					fmt.Sprintf("%s 已于 %s 到期，订阅已按您的设置取消", plan.Name, sub.CurrentPeriodEnd.Format(timeLayout)), ""})
+				return sub.Cancel(sub.CancelledBy, sub.CancelReason, *sub.CurrentPeriodEnd)
+			}
+		case subscription.StatusPastDue:
+			if !now.Before(sub.GraceEndsAt(s.cfg.GracePeriod)) {
+				if err := s.closePendingOrder(ctx, sub, order.SystemActor, "订阅宽限期内未支付续费"); err != nil {
+					return err
+				}
+				notices = append(notices, notice{NotificationTypeCancelled, "订阅已取消",
+					fmt.Sprintf("%s 在宽限期内未支付续费，订阅已取消", plan.Name), ""})
+				return sub.Cancel(subscription.CancelledBySystem, "宽限期内未支付续费", now)
+			}
+		}
+
+		dueAt := sub.GraceEndsAt(s.cfg.GracePeriod)
+		if sub.NeedsRenewalOrder(now, s.cfg.RenewAhead) {
+			o, err := s.orderService.CreateSubscriptionOrder(ctx, order.CreateSubscriptionOrderInput{
+				UserID:    sub.UserID,
+				ProductID: sub.ProductID,
+				Price:     plan.Price,
+				ExpiresAt: dueAt,
+				Note:      renewalNote(plan, sub.Periods+1),
+			})
+			if err != nil {
+				return err
+			}
+			sub.AwaitPayment(o.ID, o.ExpiresAt)
+			pending = o
+			notices = append(notices, notice{NotificationTypeRenewalCreated, "订阅续费订单已生成",
+				fmt.Sprintf("%s 将于 %s 到期，请在 %s 前支付续费订单 %s",
+					plan.Name, sub.CurrentPeriodEnd.Format(timeLayout), dueAt.Format(timeLayout), o.OrderSN), o.OrderSN})
+		}
+
+		if sub.Status != subscription.StatusPastDue && !now.Before(*sub.CurrentPeriodEnd) {
+			if err := sub.MarkPastDue(); err != nil {
+				return err
+			}
+		}
+		if pending != nil && sub.DunningDue(now, s.cfg.DunningInterval) {
+			sub.RecordDunning(now)
+			notices = append(notices, notice{NotificationTypePastDue, "订阅续费未支付",
+				fmt.Sprintf("%s 已于 %s 到期，续费订单 %s 尚未支付，请在 %s 前支付，逾期订阅将被取消",
+					plan.Name, sub.CurrentPeriodEnd.Format(timeLayout), pending.OrderSN, dueAt.Format(timeLayout)), pending.OrderSN})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, n := range notices {
+		s.send(ctx, sub, n.kind, n.title, n.body, n.orderSN)
+	}
+	return nil
+}
+
+// cancel 取消订阅并关闭待支付订单
+// 不要求立即取消时，试用中或生效中的订阅到当前周期结束后取消
+func (s *DefaultSubscriptionService) cancel(ctx context.Context, id uint, by string, actor order.Actor, input subscription.CancelInput) (*subscription.Subscription, error) {
+	return s.mutate(ctx, id, func(ctx context.Context, sub *subscription.Subscription) error {
+		atPeriodEnd := !input.Immediately && (sub.Status == subscription.StatusTrialing || sub.Status == subscription.StatusActive)
+		if atPeriodEnd {
+			if err := sub.ScheduleCancel(by, input.Reason); err != nil {
+				return err
+			}
+		} else if err := sub.Cancel(by, input.Reason, time.Now()); err != nil {
+			return err
+		}
+		return s.closePendingOrder(ctx, sub, actor, "订阅已取消")
+	})
+}
+
+// closePendingOrder 取消订阅的待支付订单
+// 订单已被关闭时忽略；订单刚刚付款时返回错误，由支付事件或下一次续费任务先完成续费
+func (s *DefaultSubscriptionService) closePendingOrder(ctx context.Context, sub *subscription.Subscription, actor order.Actor, reason string) error {
+	if sub.PendingOrderID == 0 {
+		return nil
+	}
+
+	_, err := s.orderService.UpdateStatus(ctx, sub.PendingOrderID, order.OrderStatusCancelled, actor, reason)
+	if err != nil {
+		apiErr, ok := err.(*apierror.APIError)
+		if !ok || apiErr.Code != apierror.ErrorCodeInvalidTransition {
+			return err
+		}
+		o, findErr := s.orderService.GetOrderByID(ctx, sub.PendingOrderID)
+		if findErr != nil {
+			return findErr
+		}
+		if !o.IsClosed() {
+			return apierror.NewInvalidStateTransitionError("订阅的待支付订单已付款，请刷新后重试", fmt.Sprintf("订单号: %s", o.OrderSN))
+		}
+	}
+	sub.ClearPayment()
+	return nil
+}
+
+// mutate 锁定订阅后执行fn并保存，续费任务、支付事件和用户操作修改同一订阅时依次执行
+func (s *DefaultSubscriptionService) mutate(ctx context.Context, id uint, fn func(ctx context.Context, sub *subscription.Subscription) error) (*subscription.Subscription, error) {
+	var result *subscription.Subscription
+	err := s.subscriptionRepo.Transaction(ctx, func(ctx context.Context) error {
+		sub, err := s.subscriptionRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := fn(ctx, sub); err != nil {
+			return err
+		}
+		if err := s.subscriptionRepo.Update(ctx, sub); err != nil {
+			return err
+		}
+		result = sub
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// send 发送订阅通知，失败只记录日志
+func (s *DefaultSubscriptionService) send(ctx context.Context, sub *subscription.Subscription, kind, title, body, orderSN string) {
+	data := map[string]string{
+		"subscription_id": strconv.FormatUint(uint64(sub.ID), 10),
+	}
+	if orderSN != "" {
+		data["order_sn"] = orderSN
+	}
+	if err := s.notifier.Send(ctx, notify.Message{
+		UserID: sub.UserID,
+		Type:   kind,
+		Title:  title,
+		Body:   body,
+		Data:   data,
+	}); err != nil {
+		log.Printf("发送订阅通知失败(用户 %d, 订阅 %d): %v", sub.UserID, sub.ID, err)
+	}
+}
+
+// renewalNote 订阅订单的备注，同时记录在订单时间线中
+func renewalNote(plan *subscription.Plan, period int) string {
+	return fmt.Sprintf("订阅 %s 第%d期", plan.Name, period)
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/order"
+	"web3-ecommerce-app/internal/domain/subscription"
+	orderService "web3-ecommerce-app/internal/module/order/service"
+	"web3-ecommerce-app/internal/platform/notify"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// billingSubscriptionRepo 只实现续费流程用到的方法
+type billingSubscriptionRepo struct {
+	subscription.SubscriptionRepository
+	plan *subscription.Plan
+	subs map[uint]*subscription.Subscription
+}
+
+func (r *billingSubscriptionRepo) LockByID(ctx context.Context, id uint) (*subscription.Subscription, error) {
+	if sub, ok := r.subs[id]; ok {
+		copied := *sub
+		return &copied, nil
+	}
+	return nil, apierror.NewNotFoundError("订阅不存在", "")
+}
+
+func (r *billingSubscriptionRepo) FindByPendingOrder(ctx context.Context, orderID uint) (*subscription.Subscription, error) {
+	for _, sub := range r.subs {
+		if sub.PendingOrderID == orderID {
+			copied := *sub
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *billingSubscriptionRepo) FindPlanByID(ctx context.Context, id uint) (*subscription.Plan, error) {
+	return r.plan, nil
+}
+
+func (r *billingSubscriptionRepo) Update(ctx context.Context, sub *subscription.Subscription) error {
+	copied := *sub
+	r.subs[sub.ID] = &copied
+	return nil
+}
+
+func (r *billingSubscriptionRepo) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// billingOrderService 只实现续费流程用到的方法，记录被取消和新建的订单
+type billingOrderService struct {
+	orderService.OrderService
+	orders    map[uint]*order.Order
+	cancelled []uint
+	created   int
+}
+
+func (s *billingOrderService) GetOrderByID(ctx context.Context, id uint) (*order.Order, error) {
+	if o, ok := s.orders[id]; ok {
+		return o, nil
+	}
+	return nil, apierror.NewNotFoundError("订单不存在", "")
+}
+
+func (s *billingOrderService) UpdateStatus(ctx context.Context, id uint, status string, actor order.Actor, reason string) (*order.Order, error) {
+	o := s.orders[id]
+	if o.Status != order.OrderStatusPendingPayment {
+		return nil, apierror.NewInvalidStateTransitionError("订单状态不允许该操作", o.Status)
+	}
+	s.cancelled = append(s.cancelled, id)
+	o.Status = status
+	return o, nil
+}
+
+func (s *billingOrderService) CreateSubscriptionOrder(ctx context.Context, input order.CreateSubscriptionOrderInput) (*order.Order, error) {
+	s.created++
+	o := &order.Order{ID: uint(1000 + s.created), Status: order.OrderStatusPendingPayment, ExpiresAt: input.ExpiresAt}
+	s.orders[o.ID] = o
+	return o, nil
+}
+
+type recordingNotifier struct {
+	types []string
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, msg notify.Message) error {
+	n.types = append(n.types, msg.Type)
+	return nil
+}
+
+func TestBillRenewsWhenPaymentEventWasLost(t *testing.T) {
+	plan := &subscription.Plan{ID: 1, Name: "月度会员", Interval: subscription.IntervalMonth, IntervalCount: 1, Price: 1000}
+	anchor := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := plan.PeriodEnd(anchor, 1)
+	paidAt := periodEnd.Add(-time.Hour)
+	dueAt := periodEnd.Add(72 * time.Hour)
+	now := periodEnd.Add(2 * time.Hour)
+
+	newService := func(orderStatus string) (*DefaultSubscriptionService, *billingSubscriptionRepo, *billingOrderService, *recordingNotifier) {
+		repo := &billingSubscriptionRepo{plan: plan, subs: map[uint]*subscription.Subscription{
+			1: {
+				ID:                 1,
+				PlanID:             plan.ID,
+				Status:             subscription.StatusActive,
+				AnchorAt:           &anchor,
+				Periods:            1,
+				CurrentPeriodStart: &anchor,
+				CurrentPeriodEnd:   &periodEnd,
+				PendingOrderID:     500,
+				PaymentDueAt:       &dueAt,
+			},
+		}}
+		pending := &order.Order{ID: 500, OrderSN: "SN500", Status: orderStatus}
+		if orderStatus != order.OrderStatusPendingPayment {
+			pending.PaidAt = &paidAt
+		}
+		orders := &billingOrderService{orders: map[uint]*order.Order{500: pending}}
+		notifier := &recordingNotifier{}
+		svc := &DefaultSubscriptionService{
+			subscriptionRepo: repo,
+			orderService:     orders,
+			notifier:         notifier,
+			cfg:              &config.SubscriptionConfig{RenewAhead: 24 * time.Hour, GracePeriod: 72 * time.Hour, DunningInterval: 24 * time.Hour},
+		}
+		return svc, repo, orders, notifier
+	}
+
+	t.Run("待支付订单已付款", func(t *testing.T) {
+		svc, repo, orders, notifier := newService(order.OrderStatusPaid)
+		if err := svc.bill(context.Background(), 1, plan, now); err != nil {
+			t.Fatalf("bill: %v", err)
+		}
+
+		sub := repo.subs[1]
+		if sub.Status != subscription.StatusActive || sub.Periods != 2 {
+			t.Errorf("status = %s periods = %d, want active 2", sub.Status, sub.Periods)
+		}
+		if want := plan.PeriodEnd(anchor, 2); !sub.CurrentPeriodEnd.Equal(want) {
+			t.Errorf("period end = %s, want %s", sub.CurrentPeriodEnd, want)
+		}
+		if sub.PendingOrderID != 0 {
+			t.Errorf("pending order = %d, want 0", sub.PendingOrderID)
+		}
+		if len(orders.cancelled) != 0 || orders.created != 0 {
+			t.Errorf("已付款的订单被取消或重复生成续费订单: cancelled=%v created=%d", orders.cancelled, orders.created)
+		}
+		if len(notifier.types) != 1 || notifier.types[0] != NotificationTypeRenewed {
+			t.Errorf("notifications = %v, want [%s]", notifier.types, NotificationTypeRenewed)
+		}
+
+		// 之后到达的支付事件不会再续一期
+		if err := svc.HandleOrderPaid(context.Background(), order.PaidEvent{OrderID: 500, PaidAt: paidAt}); err != nil {
+			t.Fatalf("HandleOrderPaid: %v", err)
+		}
+		if repo.subs[1].Periods != 2 {
+			t.Errorf("periods = %d after late event, want 2", repo.subs[1].Periods)
+		}
+	})
+
+	t.Run("待支付订单仍未付款", func(t *testing.T) {
+		svc, repo, orders, notifier := newService(order.OrderStatusPendingPayment)
+		if err := svc.bill(context.Background(), 1, plan, now); err != nil {
+			t.Fatalf("bill: %v", err)
+		}
+
+		sub := repo.subs[1]
+		if sub.Status != subscription.StatusPastDue || sub.PendingOrderID != 500 {
+			t.Errorf("status = %s pending = %d, want past_due 500", sub.Status, sub.PendingOrderID)
+		}
+		if len(orders.cancelled) != 0 {
+			t.Errorf("宽限期内的待支付订单被取消: %v", orders.cancelled)
+		}
+		if len(notifier.types) != 1 || notifier.types[0] != NotificationTypePastDue {
+			t.Errorf("notifications = %v, want [%s]", notifier.types, NotificationTypePastDue)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/tax"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+)
+
+// RateModel 是GORM税率模型
+type RateModel struct {
+	ID        uint   `gorm:"primarykey"`
+	Country   string `gorm:"type:varchar(2);not null;uniqueIndex:idx_location_class,priority:1"`
+	Region    string `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_location_class,priority:2"`
+	TaxClass  string `gorm:"type:varchar(30);not null;uniqueIndex:idx_location_class,priority:3"`
+	Name      string `gorm:"type:varchar(50);not null"`
+	Rate      int    `gorm:"not null;default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName 指定表名
+func (RateModel) TableName() string {
+	return "tax_rates"
+}
+
+// GormRateRepository 是税率仓库的GORM实现
+type GormRateRepository struct {
+	db *gorm.DB
+}
+
+// NewGormRateRepository 创建一个新的GORM税率仓库
+func NewGormRateRepository(db *gorm.DB) tax.RateRepository {
+	return &GormRateRepository{db: db}
+}
+
+// rateToModel 将领域模型转换为GORM模型
+func rateToModel(r *tax.Rate) *RateModel {
+	return &RateModel{
+		ID:        r.ID,
+		Country:   r.Country,
+		Region:    r.Region,
+		TaxClass:  r.TaxClass,
+		Name:      r.Name,
+		Rate:      r.Rate,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// rateToDomain 将GORM模型转换为领域模型
+func rateToDomain(m *RateModel) *tax.Rate {
+	return &tax.Rate{
+		ID:        m.ID,
+		Country:   m.Country,
+		Region:    m.Region,
+		TaxClass:  m.TaxClass,
+		Name:      m.Name,
+		Rate:      m.Rate,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// ratesToDomain 批量转换为领域模型
+func ratesToDomain(models []RateModel) []tax.Rate {
+	rates := make([]tax.Rate, 0, len(models))
+	for i := range models {
+		rates = append(rates, *rateToDomain(&models[i]))
+	}
+	return rates
+}
+
+// FindAll 查询全部税率
+func (r *GormRateRepository) FindAll(ctx context.Context) ([]tax.Rate, error) {
+	var models []RateModel
+	if err := database.Conn(ctx, r.db).
+		Order("country ASC").Order("region ASC").Order("tax_class ASC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询税率列表错误: %w", err)
+	}
+	return ratesToDomain(models), nil
+}
+
+// FindByCountry 查询适用于该国家的税率
+func (r *GormRateRepository) FindByCountry(ctx context.Context, country string) ([]tax.Rate, error) {
+	var models []RateModel
+	if err := database.Conn(ctx, r.db).Where("country IN ?", []string{country, tax.AnyCountry}).
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询税率错误: %w", err)
+	}
+	return ratesToDomain(models), nil
+}
+
+// FindByID 根据ID查询税率
+func (r *GormRateRepository) FindByID(ctx context.Context, id uint) (*tax.Rate, error) {
+	var model RateModel
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("税率不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询税率错误: %w", err)
+	}
+	return rateToDomain(&model), nil
+}
+
+// Create 创建税率
+func (r *GormRateRepository) Create(ctx context.Context, rate *tax.Rate) error {
+	model := rateToModel(rate)
+	if err := database.Conn(ctx, r.db).Create(model).Error; err != nil {
+		return fmt.Errorf("创建税率错误: %w", err)
+	}
+
+	*rate = *rateToDomain(model)
+	return nil
+}
+
+// Update 更新税率
+func (r *GormRateRepository) Update(ctx context.Context, rate *tax.Rate) error {
+	model := rateToModel(rate)
+	result := database.Conn(ctx, r.db).Model(&RateModel{}).Where("id = ?", rate.ID).
+		Select("country", "region", "tax_class", "name", "rate", "updated_at").Updates(model)
+	if result.Error != nil {
+		return fmt.Errorf("更新税率错误: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apierror.NewNotFoundError("税率不存在", fmt.Sprintf("ID: %d", rate.ID))
+	}
+	return nil
+}
+
+// Delete 删除税率
+func (r *GormRateRepository) Delete(ctx context.Context, id uint) error {
+	result := database.Conn(ctx, r.db).Delete(&RateModel{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除税率错误: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apierror.NewNotFoundError("税率不存在", fmt.Sprintf("ID: %d", id))
+	}
+	return nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormRateRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&RateModel{})
+}
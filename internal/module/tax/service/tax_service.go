@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/tax"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// TaxService 税费服务接口
+// 实现了tax.Calculator，按后台维护的税率表计税
+type TaxService interface {
+	tax.Calculator
+
+	// ListRates 获取全部税率
+	ListRates(ctx context.Context) ([]tax.Rate, error)
+
+	// GetRate 获取税率
+	GetRate(ctx context.Context, id uint) (*tax.Rate, error)
+
+	// CreateRate 创建税率，同一国家、地区和税类只能有一个税率
+	CreateRate(ctx context.Context, input tax.RateInput) (*tax.Rate, error)
+
+	// UpdateRate 更新税率，已下单的订单保留下单时的税额
+	UpdateRate(ctx context.Context, id uint, input tax.RateInput) (*tax.Rate, error)
+
+	// DeleteRate 删除税率
+	DeleteRate(ctx context.Context, id uint) error
+}
+
+// DefaultTaxService 默认税费服务实现
+type DefaultTaxService struct {
+	rateRepo tax.RateRepository
+	config   *config.TaxConfig
+}
+
+// NewTaxService 创建税费服务
+func NewTaxService(rateRepo tax.RateRepository, config *config.TaxConfig) TaxService {
+	return &DefaultTaxService{
+		rateRepo: rateRepo,
+		config:   config,
+	}
+}
+
+// Calculate 按收货国家和地区匹配每一行税类的税率并计算税额
+func (s *DefaultTaxService) Calculate(ctx context.Context, req tax.Request) (*tax.Result, error) {
+	rates, err := s.rateRepo.FindByCountry(ctx, req.Country)
+	if err != nil {
+		return nil, err
+	}
+
+	inclusive := s.config.Mode == tax.ModeInclusive
+	result := &tax.Result{
+		Inclusive: inclusive,
+		Lines:     make([]tax.LineTax, 0, len(req.Lines)),
+	}
+	for _, line := range req.Lines {
+		computed := lineTax(rates, req, tax.NormalizeClass(line.TaxClass), line.Amount, inclusive)
+		computed.Key = line.Key
+		result.Lines = append(result.Lines, computed)
+		result.Total += computed.Amount
+	}
+	result.Shipping = lineTax(rates, req, tax.ClassShipping, req.Shipping, inclusive)
+	result.Total += result.Shipping.Amount
+	return result, nil
+}
+
+// ListRates 获取全部税率
+func (s *DefaultTaxService) ListRates(ctx context.Context) ([]tax.Rate, error) {
+	return s.rateRepo.FindAll(ctx)
+}
+
+// GetRate 获取税率
+func (s *DefaultTaxService) GetRate(ctx context.Context, id uint) (*tax.Rate, error) {
+	return s.rateRepo.FindByID(ctx, id)
+}
+
+// CreateRate 创建税率
+func (s *DefaultTaxService) CreateRate(ctx context.Context, input tax.RateInput) (*tax.Rate, error) {
+	rate, err := s.buildRate(ctx, 0, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rateRepo.Create(ctx, rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// UpdateRate 更新税率
+func (s *DefaultTaxService) UpdateRate(ctx context.Context, id uint, input tax.RateInput) (*tax.Rate, error) {
+	if _, err := s.rateRepo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	rate, err := s.buildRate(ctx, id, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rateRepo.Update(ctx, rate); err != nil {
+		return nil, err
+	}
+	return s.rateRepo.FindByID(ctx, id)
+}
+
+// DeleteRate 删除税率
+func (s *DefaultTaxService) DeleteRate(ctx context.Context, id uint) error {
+	return s.rateRepo.Delete(ctx, id)
+}
+
+// buildRate 校验输入并构建税率，同一国家、地区和税类不能重复
+func (s *DefaultTaxService) buildRate(ctx context.Context, id uint, input tax.RateInput) (*tax.Rate, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	rates, err := s.rateRepo.FindByCountry(ctx, input.Country)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rates {
+		if r.ID != id && r.Country == input.Country && strings.EqualFold(r.Region, input.Region) && r.TaxClass == input.TaxClass {
+			return nil, apierror.NewDuplicateEntityError("税率已存在", fmt.Sprintf("%s %s %s", r.Country, r.Region, r.TaxClass))
+		}
+	}
+
+	return &tax.Rate{
+		ID:       id,
+		Country:  input.Country,
+		Region:   input.Region,
+		TaxClass: input.TaxClass,
+		Name:     input.Name,
+		Rate:     input.Rate,
+	}, nil
+}
+
+// lineTax 匹配税率并计算一行的税额，没有适用的税率时税额为0
+func lineTax(rates []tax.Rate, req tax.Request, taxClass string, amount common.Money, inclusive bool) tax.LineTax {
+	rate := tax.Match(rates, req.Country, req.Region, taxClass)
+	if rate == nil {
+		return tax.LineTax{}
+	}
+	return tax.LineTax{
+		Name:   rate.Name,
+		Rate:   rate.Rate,
+		Amount: tax.Amount(amount, rate.Rate, inclusive),
+	}
+}
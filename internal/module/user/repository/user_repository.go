@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"web3-ecommerce-app/internal/domain/user"
+	"web3-ecommerce-app/internal/platform/database"
 	"web3-ecommerce-app/pkg/apierror"
 
 	"gorm.io/gorm"
@@ -68,7 +69,7 @@ func modelToDomain(m *UserModel) *user.User {
 // FindByID 根据ID查找用户
 func (r *GormUserRepository) FindByID(ctx context.Context, id uint) (*user.User, error) {
 	var model UserModel
-	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, apierror.NewNotFoundError("用户不存在", fmt.Sprintf("ID: %d", id))
 		}
@@ -80,7 +81,7 @@ func (r *GormUserRepository) FindByID(ctx context.Context, id uint) (*user.User,
 // FindByEmail 根据Email查找用户
 func (r *GormUserRepository) FindByEmail(ctx context.Context, email string) (*user.User, error) {
 	var model UserModel
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&model).Error; err != nil {
+	if err := database.Conn(ctx, r.db).Where("email = ?", email).First(&model).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, apierror.NewNotFoundError("用户不存在", fmt.Sprintf("Email: %s", email))
 		}
@@ -92,7 +93,7 @@ func (r *GormUserRepository) FindByEmail(ctx context.Context, email string) (*us
 // FindByWalletAddr 根据钱包地址查找用户
 func (r *GormUserRepository) FindByWalletAddr(ctx context.Context, walletAddr string) (*user.User, error) {
 	var model UserModel
-	if err := r.db.WithContext(ctx).Where("wallet_addr = ?", walletAddr).First(&model).Error; err != nil {
+	if err := database.Conn(ctx, r.db).Where("wallet_addr = ?", walletAddr).First(&model).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, apierror.NewNotFoundError("用户不存在", fmt.Sprintf("钱包地址: %s", walletAddr))
 		}
@@ -104,14 +105,14 @@ func (r *GormUserRepository) FindByWalletAddr(ctx context.Context, walletAddr st
 // Create 创建用户
 func (r *GormUserRepository) Create(ctx context.Context, u *user.User) error {
 	model := domainToModel(u)
-	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
-		if r.db.WithContext(ctx).Where("email = ?", u.Email).First(&UserModel{}).Error == nil {
+	if err := database.Conn(ctx, r.db).Create(model).Error; err != nil {
+		if database.Conn(ctx, r.db).Where("email = ?", u.Email).First(&UserModel{}).Error == nil {
 			return apierror.NewDuplicateEntityError("邮箱已被使用", u.Email)
 		}
-		if u.WalletAddr != "" && r.db.WithContext(ctx).Where("wallet_addr = ?", u.WalletAddr).First(&UserModel{}).Error == nil {
+		if u.WalletAddr != "" && database.Conn(ctx, r.db).Where("wallet_addr = ?", u.WalletAddr).First(&UserModel{}).Error == nil {
 			return apierror.NewDuplicateEntityError("钱包地址已被绑定", u.WalletAddr)
 		}
-		if r.db.WithContext(ctx).Where("username = ?", u.Username).First(&UserModel{}).Error == nil {
+		if database.Conn(ctx, r.db).Where("username = ?", u.Username).First(&UserModel{}).Error == nil {
 			return apierror.NewDuplicateEntityError("用户名已被使用", u.Username)
 		}
 		return fmt.Errorf("创建用户错误: %w", err)
@@ -128,7 +129,7 @@ func (r *GormUserRepository) Create(ctx context.Context, u *user.User) error {
 // Update 更新用户
 func (r *GormUserRepository) Update(ctx context.Context, u *user.User) error {
 	model := domainToModel(u)
-	if err := r.db.WithContext(ctx).Save(model).Error; err != nil {
+	if err := database.Conn(ctx, r.db).Save(model).Error; err != nil {
 		return fmt.Errorf("更新用户错误: %w", err)
 	}
 
@@ -140,7 +141,7 @@ func (r *GormUserRepository) Update(ctx context.Context, u *user.User) error {
 
 // Delete 删除用户
 func (r *GormUserRepository) Delete(ctx context.Context, id uint) error {
-	if err := r.db.WithContext(ctx).Delete(&UserModel{}, id).Error; err != nil {
+	if err := database.Conn(ctx, r.db).Delete(&UserModel{}, id).Error; err != nil {
 		return fmt.Errorf("删除用户错误: %w", err)
 	}
 	return nil
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/wallet"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AccountModel 是GORM余额账户模型
+type AccountModel struct {
+	UserID        uint   `gorm:"primarykey;autoIncrement:false"`
+	Balance       int64  `gorm:"not null;default:0"`
+	StoreCredit   int64  `gorm:"not null;default:0"`
+	PayoutAddress string `gorm:"type:varchar(42)"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName 指定表名
+func (AccountModel) TableName() string {
+	return "wallet_accounts"
+}
+
+// EntryModel 是GORM余额流水模型
+type EntryModel struct {
+	ID           uint      `gorm:"primarykey"`
+	UserID       uint      `gorm:"not null;index:idx_user_created,priority:1"`
+	Funds        string    `gorm:"type:varchar(20);not null;default:'balance'"`
+	Type         string    `gorm:"type:varchar(30);not null"`
+	Amount       int64     `gorm:"not null"`
+	BalanceAfter int64     `gorm:"not null"`
+	RefType      string    `gorm:"type:varchar(30);index:idx_ref,priority:1"`
+	RefID        uint      `gorm:"not null;default:0;index:idx_ref,priority:2"`
+	Note         string    `gorm:"type:varchar(500)"`
+	CreatedAt    time.Time `gorm:"index:idx_user_created,priority:2"`
+}
+
+// TableName 指定表名
+func (EntryModel) TableName() string {
+	return "wallet_entries"
+}
+
+// WithdrawalModel 是GORM提现单模型
+type WithdrawalModel struct {
+	ID            uint   `gorm:"primarykey"`
+	UserID        uint   `gorm:"not null;index:idx_user_id"`
+	Amount        int64  `gorm:"not null"`
+	ToAddress     string `gorm:"type:varchar(42);not null"`
+	Source        string `gorm:"type:varchar(20);not null"`
+	RefID         uint   `gorm:"not null;default:0"`
+	Status        string `gorm:"type:varchar(20);not null;index:idx_status_created,priority:1"`
+	TxHash        string `gorm:"type:varchar(100)"`
+	FailureReason string `gorm:"type:varchar(500)"`
+	ProcessedBy   uint   `gorm:"not null;default:0"`
+	ProcessedAt   *time.Time
+	CreatedAt     time.Time `gorm:"index:idx_status_created,priority:2"`
+}
+
+// TableName 指定表名
+func (WithdrawalModel) TableName() string {
+	return "wallet_withdrawals"
+}
+
+// GormWalletRepository 是余额仓库的GORM实现
+type GormWalletRepository struct {
+	db *gorm.DB
+}
+
+// NewGormWalletRepository 创建一个新的GORM余额仓库
+func NewGormWalletRepository(db *gorm.DB) wallet.WalletRepository {
+	return &GormWalletRepository{db: db}
+}
+
+// FindAccount 查询用户的余额账户
+func (r *GormWalletRepository) FindAccount(ctx context.Context, userID uint) (*wallet.Account, error) {
+	var models []AccountModel
+	if err := database.Conn(ctx, r.db).Where("user_id = ?", userID).Limit(1).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询余额账户错误: %w", err)
+	}
+	if len(models) == 0 {
+		return &wallet.Account{UserID: userID}, nil
+	}
+	return &wallet.Account{
+		UserID:        models[0].UserID,
+		Balance:       common.Money(models[0].Balance),
+		StoreCredit:   common.Money(models[0].StoreCredit),
+		PayoutAddress: models[0].PayoutAddress,
+		UpdatedAt:     models[0].UpdatedAt,
+	}, nil
+}
+
+// SetPayoutAddress 设置收款地址
+func (r *GormWalletRepository) SetPayoutAddress(ctx context.Context, userID uint, address string) error {
+	model := AccountModel{UserID: userID, PayoutAddress: address}
+	if err := database.Conn(ctx, r.db).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"payout_address", "updated_at"}),
+	}).Create(&model).Error; err != nil {
+		return fmt.Errorf("设置收款地址错误: %w", err)
+	}
+	return nil
+}
+
+// AddEntry 原子地变更余额或购物金并记录流水
+func (r *GormWalletRepository) AddEntry(ctx context.Context, entry *wallet.Entry) error {
+	column, name := "balance", "余额"
+	if entry.Funds == wallet.FundsStoreCredit {
+		column, name = "store_credit", "购物金"
+	} else {
+		entry.Funds = wallet.FundsBalance
+	}
+
+	return database.Transaction(ctx, r.db, func(ctx context.Context) error {
+		tx := database.Conn(ctx, r.db)
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&AccountModel{UserID: entry.UserID}).Error; err != nil {
+			return fmt.Errorf("创建余额账户错误: %w", err)
+		}
+
+		result := tx.Model(&AccountModel{}).
+			Where("user_id = ? AND "+column+" + ? >= 0", entry.UserID, int64(entry.Amount)).
+			Updates(map[string]interface{}{
+				column:       gorm.Expr(column+" + ?", int64(entry.Amount)),
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("更新%s错误: %w", name, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apierror.NewValidationError(name+"不足", fmt.Sprintf("需要 %s", -entry.Amount))
+		}
+
+		var account AccountModel
+		if err := tx.Where("user_id = ?", entry.UserID).First(&account).Error; err != nil {
+			return fmt.Errorf("查询余额账户错误: %w", err)
+		}
+		after := account.Balance
+		if entry.Funds == wallet.FundsStoreCredit {
+			after = account.StoreCredit
+		}
+
+		model := EntryModel{
+			UserID:       entry.UserID,
+			Funds:        entry.Funds,
+			Type:         entry.Type,
+			Amount:       int64(entry.Amount),
+			BalanceAfter: after,
+			RefType:      entry.RefType,
+			RefID:        entry.RefID,
+			Note:         entry.Note,
+		}
+		if err := tx.Create(&model).Error; err != nil {
+			return fmt.Errorf("记录余额流水错误: %w", err)
+		}
+
+		entry.ID = model.ID
+		entry.BalanceAfter = common.Money(model.BalanceAfter)
+		entry.CreatedAt = model.CreatedAt
+		return nil
+	})
+}
+
+// FindEntries 按时间倒序分页查询用户的余额流水
+func (r *GormWalletRepository) FindEntries(ctx context.Context, userID uint, page, pageSize int) (*wallet.EntryPaginationResult, error) {
+	var models []EntryModel
+	var total int64
+
+	db := database.Conn(ctx, r.db).Model(&EntryModel{}).Where("user_id = ?", userID)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询余额流水总数错误: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := db.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询余额流水错误: %w", err)
+	}
+
+	entries := make([]wallet.Entry, 0, len(models))
+	for _, m := range models {
+		entries = append(entries, wallet.Entry{
+			ID:           m.ID,
+			UserID:       m.UserID,
+			Funds:        m.Funds,
+			Type:         m.Type,
+			Amount:       common.Money(m.Amount),
+			BalanceAfter: common.Money(m.BalanceAfter),
+			RefType:      m.RefType,
+			RefID:        m.RefID,
+			Note:         m.Note,
+			CreatedAt:    m.CreatedAt,
+		})
+	}
+
+	return &wallet.EntryPaginationResult{
+		Total:   int(total),
+		Entries: entries,
+	}, nil
+}
+
+// withdrawalToDomain 将GORM模型转换为领域模型
+func withdrawalToDomain(m *WithdrawalModel) *wallet.Withdrawal {
+	return &wallet.Withdrawal{
+		ID:            m.ID,
+		UserID:        m.UserID,
+		Amount:        common.Money(m.Amount),
+		ToAddress:     m.ToAddress,
+		Source:        m.Source,
+		RefID:         m.RefID,
+		Status:        m.Status,
+		TxHash:        m.TxHash,
+		FailureReason: m.FailureReason,
+		ProcessedBy:   m.ProcessedBy,
+		ProcessedAt:   m.ProcessedAt,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+// CreateWithdrawal 创建提现单
+func (r *GormWalletRepository) CreateWithdrawal(ctx context.Context, w *wallet.Withdrawal) error {
+	model := WithdrawalModel{
+		UserID:    w.UserID,
+		Amount:    int64(w.Amount),
+		ToAddress: w.ToAddress,
+		Source:    w.Source,
+		RefID:     w.RefID,
+		Status:    w.Status,
+	}
+	if err := database.Conn(ctx, r.db).Create(&model).Error; err != nil {
+		return fmt.Errorf("创建提现单错误: %w", err)
+	}
+
+	*w = *withdrawalToDomain(&model)
+	return nil
+}
+
+// FindWithdrawalByID 根据ID查询提现单
+func (r *GormWalletRepository) FindWithdrawalByID(ctx context.Context, id uint) (*wallet.Withdrawal, error) {
+	var model WithdrawalModel
+	if err := database.Conn(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierror.NewNotFoundError("提现单不存在", fmt.Sprintf("ID: %d", id))
+		}
+		return nil, fmt.Errorf("查询提现单错误: %w", err)
+	}
+	return withdrawalToDomain(&model), nil
+}
+
+// FindWithdrawals 按条件分页查询提现单
+func (r *GormWalletRepository) FindWithdrawals(ctx context.Context, query wallet.WithdrawalQuery) (*wallet.WithdrawalPaginationResult, error) {
+	var models []WithdrawalModel
+	var total int64
+
+	db := database.Conn(ctx, r.db).Model(&WithdrawalModel{})
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("查询提现单总数错误: %w", err)
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order("created_at DESC, id DESC").Offset(offset).Limit(query.PageSize).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询提现单列表错误: %w", err)
+	}
+
+	withdrawals := make([]wallet.Withdrawal, 0, len(models))
+	for i := range models {
+		withdrawals = append(withdrawals, *withdrawalToDomain(&models[i]))
+	}
+
+	return &wallet.WithdrawalPaginationResult{
+		Total:       int(total),
+		Withdrawals: withdrawals,
+	}, nil
+}
+
+// UpdateWithdrawal 仅当提现单仍待处理时写入处理结果
+func (r *GormWalletRepository) UpdateWithdrawal(ctx context.Context, w *wallet.Withdrawal) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&WithdrawalModel{}).
+		Where("id = ? AND status = ?", w.ID, wallet.WithdrawalStatusPending).
+		Updates(map[string]interface{}{
+			"status":         w.Status,
+			"tx_hash":        w.TxHash,
+			"failure_reason": w.FailureReason,
+			"processed_by":   w.ProcessedBy,
+			"processed_at":   w.ProcessedAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("更新提现单错误: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Transaction 在事务中执行fn
+func (r *GormWalletRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.Transaction(ctx, r.db, fn)
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormWalletRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&AccountModel{}, &EntryModel{}, &WithdrawalModel{})
+}
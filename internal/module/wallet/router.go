@@ -0,0 +1,36 @@
+package wallet
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/wallet/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册余额模块路由
+// 提现单的处理接口由admin模块统一提供
+func RegisterRoutes(router *gin.Engine, handler *handler.WalletHTTPHandler, jwtConfig *config.JWTConfig, idempotent gin.HandlerFunc) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 当前用户的余额(需要认证，写操作支持幂等键)
+	walletRoutes := v1.Group("/wallet")
+	walletRoutes.Use(middleware.JWT(jwtConfig), idempotent)
+	{
+		// 获取余额和收款地址
+		walletRoutes.GET("", handler.GetAccount)
+
+		// 设置收款地址
+		walletRoutes.PUT("/payout-address", handler.SetPayoutAddress)
+
+		// 获取余额流水
+		walletRoutes.GET("/entries", handler.ListEntries)
+
+		// 发起提现
+		walletRoutes.POST("/withdrawals", handler.Withdraw)
+
+		// 获取提现单
+		walletRoutes.GET("/withdrawals", handler.ListWithdrawals)
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"web3-ecommerce-app/internal/domain/common"
+	"web3-ecommerce-app/internal/domain/wallet"
+	"web3-ecommerce-app/internal/platform/chain"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// WalletService 站内余额和提现服务接口
+type WalletService interface {
+	// GetAccount 获取用户的余额账户
+	GetAccount(ctx context.Context, userID uint) (*wallet.Account, error)
+
+	// SetPayoutAddress 设置提现和退款转账的收款地址
+	SetPayoutAddress(ctx context.Context, userID uint, input wallet.PayoutAddressInput) (*wallet.Account, error)
+
+	// ListEntries 分页查询用户的余额流水
+	ListEntries(ctx context.Context, userID uint, page, pageSize int) (*wallet.EntryPaginationResult, error)
+
+	// Credit 增加用户余额并记录流水，在事务中调用时加入该事务
+	Credit(ctx context.Context, userID uint, amount common.Money, entryType string, refType string, refID uint, note string) (*wallet.Entry, error)
+
+	// Debit 扣减用户余额并记录流水，余额不足时返回错误，在事务中调用时加入该事务
+	Debit(ctx context.Context, userID uint, amount common.Money, entryType string, refType string, refID uint, note string) (*wallet.Entry, error)
+
+	// CreditStoreCredit 增加用户的购物金并记录流水，在事务中调用时加入该事务
+	CreditStoreCredit(ctx context.Context, userID uint, amount common.Money, entryType string, refType string, refID uint, note string) (*wallet.Entry, error)
+
+	// DebitStoreCredit 扣减用户的购物金并记录流水，购物金不足时返回错误，在事务中调用时加入该事务
+	DebitStoreCredit(ctx context.Context, userID uint, amount common.Money, entryType string, refType string, refID uint, note string) (*wallet.Entry, error)
+
+	// Withdraw 从余额发起提现，余额在提现单创建时扣减，购物金不能提现
+	Withdraw(ctx context.Context, userID uint, input wallet.WithdrawInput) (*wallet.Withdrawal, error)
+
+	// CreatePayout 创建不经过余额的转账提现单，如订单退款，toAddress为空时使用账户的收款地址
+	CreatePayout(ctx context.Context, userID uint, amount common.Money, toAddress string, source string, refID uint) (*wallet.Withdrawal, error)
+
+	// ListUserWithdrawals 分页查询用户的提现单
+	ListUserWithdrawals(ctx context.Context, userID uint, page, pageSize int) (*wallet.WithdrawalPaginationResult, error)
+
+	// ListWithdrawals 按条件分页查询全部提现单
+	ListWithdrawals(ctx context.Context, query wallet.WithdrawalQuery) (*wallet.WithdrawalPaginationResult, error)
+
+	// ProcessWithdrawal 管理员记录提现单的转账结果，转账失败时金额退回用户余额
+	ProcessWithdrawal(ctx context.Context, id uint, adminID uint, input wallet.ProcessWithdrawalInput) (*wallet.Withdrawal, error)
+}
+
+// DefaultWalletService 默认余额服务实现
+type DefaultWalletService struct {
+	walletRepo wallet.WalletRepository
+}
+
+// NewWalletService 创建余额服务
+func NewWalletService(walletRepo wallet.WalletRepository) WalletService {
+	return &DefaultWalletService{
+		walletRepo: walletRepo,
+	}
+}
+
+// GetAccount 获取用户的余额账户
+func (s *DefaultWalletService) GetAccount(ctx context.Context, userID uint) (*wallet.Account, error) {
+	return s.walletRepo.FindAccount(ctx, userID)
+}
+
+// SetPayoutAddress 设置收款地址
+func (s *DefaultWalletService) SetPayoutAddress(ctx context.Context, userID uint, input wallet.PayoutAddressInput) (*wallet.Account, error) {
+	if err := s.walletRepo.SetPayoutAddress(ctx, userID, chain.NormalizeAddress(input.Address)); err != nil {
+		return nil, err
+	}
+	return s.walletRepo.FindAccount(ctx, userID)
+}
+
+// ListEntries 分页查询用户的余额流水
+func (s *DefaultWalletService) ListEntries(ctx context.Context, userID uint, page, pageSize int) (*wallet.EntryPaginationResult, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	return s.walletRepo.FindEntries(ctx, userID, page, pageSize)
+}
+
+// Credit 增加用户余额
+func (s *DefaultWalletService) Credit(ctx context.Context, userID uint, amount common.Money, entryType string, refType string, refID uint, note string) (*wallet.Entry, error) {
+	return s.credit(ctx, wallet.FundsBalance, userID, amount, entryType, refType, refID, note)
+}
+
+// Debit 扣减余额
+func (s *DefaultWalletService) Debit(ctx context.Context, userID uint, amount common.Money, entryType string, refType string, refID uint, note string) (*wallet.Entry, error) {
+	return s.debit(ctx, wallet.FundsBalance, userID, amount, entryType, refType, refID, note)
+}
+
+// CreditStoreCredit 增加用户的购物金
+func (s *DefaultWalletService) CreditStoreCredit(ctx context.Context, userID uint, amount common.Money, entryType string, refType string, refID uint, note string) (*wallet.Entry, error) {
+	return s.credit(ctx, wallet.FundsStoreCredit, userID, amount, entryType, refType, refID, note)
+}
+
+// DebitStoreCredit 扣减用户的购物金
+func (s *DefaultWalletService) DebitStoreCredit(ctx context.Context, userID uint, amount common.Money, entryType string, refType string, refID uint, note string) (*wallet.Entry, error) {
+	return s.debit(ctx, wallet.FundsStoreCredit, userID, amount, entryType, refType, refID, note)
+}
+
+// Withdraw 从余额发起提现，提现单和余额扣减在同一事务中写入
+func (s *DefaultWalletService) Withdraw(ctx context.Context, userID uint, input wallet.WithdrawInput) (*wallet.Withdrawal, error) {
+	toAddress, err := s.payoutAddress(ctx, userID, input.ToAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawal := &wallet.Withdrawal{
+		UserID:    userID,
+		Amount:    input.Amount,
+		ToAddress: toAddress,
+		Source:    wallet.WithdrawalSourceBalance,
+		Status:    wallet.WithdrawalStatusPending,
+	}
+	err = s.walletRepo.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.walletRepo.CreateWithdrawal(ctx, withdrawal); err != nil {
+			return err
+		}
+		return s.walletRepo.AddEntry(ctx, &wallet.Entry{
+			UserID:  userID,
+			Funds:   wallet.FundsBalance,
+			Type:    wallet.EntryTypeWithdrawal,
+			Amount:  -input.Amount,
+			RefType: wallet.RefTypeWithdrawal,
+			RefID:   withdrawal.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withdrawal, nil
+}
+
+// CreatePayout 创建不经过余额的转账提现单
+func (s *DefaultWalletService) CreatePayout(ctx context.Context, userID uint, amount common.Money, toAddress string, source string, refID uint) (*wallet.Withdrawal, error) {
+	if amount <= 0 {
+		return nil, apierror.NewValidationError("无效的金额", fmt.Sprintf("转账金额必须大于0: %s", amount))
+	}
+	toAddress, err := s.payoutAddress(ctx, userID, toAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawal := &wallet.Withdrawal{
+		UserID:    userID,
+		Amount:    amount,
+		ToAddress: toAddress,
+		Source:    source,
+		RefID:     refID,
+		Status:    wallet.WithdrawalStatusPending,
+	}
+	if err := s.walletRepo.CreateWithdrawal(ctx, withdrawal); err != nil {
+		return nil, err
+	}
+	return withdrawal, nil
+}
+
+// ListUserWithdrawals 分页查询用户的提现单
+func (s *DefaultWalletService) ListUserWithdrawals(ctx context.Context, userID uint, page, pageSize int) (*wallet.WithdrawalPaginationResult, error) {
+	return s.ListWithdrawals(ctx, wallet.WithdrawalQuery{UserID: userID, Page: page, PageSize: pageSize})
+}
+
+// ListWithdrawals 按条件分页查询提现单
+func (s *DefaultWalletService) ListWithdrawals(ctx context.Context, query wallet.WithdrawalQuery) (*wallet.WithdrawalPaginationResult, error) {
+	query.Page, query.PageSize = normalizePage(query.Page, query.PageSize)
+	return s.walletRepo.FindWithdrawals(ctx, query)
+}
+
+// ProcessWithdrawal 记录提现单的转账结果
+// 链上转账由管理员在外部钱包完成，这里只记录交易哈希；转账失败时不论来源都将金额退回余额，避免款项丢失
+func (s *DefaultWalletService) ProcessWithdrawal(ctx context.Context, id uint, adminID uint, input wallet.ProcessWithdrawalInput) (*wallet.Withdrawal, error) {
+	withdrawal, err := s.walletRepo.FindWithdrawalByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if input.Action == wallet.WithdrawalActionComplete {
+		err = withdrawal.Complete(strings.TrimSpace(input.TxHash), adminID, now)
+	} else {
+		err = withdrawal.Fail(strings.TrimSpace(input.Reason), adminID, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.walletRepo.Transaction(ctx, func(ctx context.Context) error {
+		ok, err := s.walletRepo.UpdateWithdrawal(ctx, withdrawal)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apierror.NewInvalidStateTransitionError("提现单已处理", fmt.Sprintf("ID: %d", id))
+		}
+		if withdrawal.Status != wallet.WithdrawalStatusFailed {
+			return nil
+		}
+		return s.walletRepo.AddEntry(ctx, &wallet.Entry{
+			UserID:  withdrawal.UserID,
+			Funds:   wallet.FundsBalance,
+			Type:    wallet.EntryTypeWithdrawalReversal,
+			Amount:  withdrawal.Amount,
+			RefType: wallet.RefTypeWithdrawal,
+			RefID:   withdrawal.ID,
+			Note:    withdrawal.FailureReason,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withdrawal, nil
+}
+
+// credit 向指定的资金类型入账
+func (s *DefaultWalletService) credit(ctx context.Context, funds string, userID uint, amount common.Money, entryType string, refType string, refID uint, note string) (*wallet.Entry, error) {
+	if amount <= 0 {
+		return nil, apierror.NewValidationError("无效的金额", fmt.Sprintf("入账金额必须大于0: %s", amount))
+	}
+	entry := &wallet.Entry{
+		UserID:  userID,
+		Funds:   funds,
+		Type:    entryType,
+		Amount:  amount,
+		RefType: refType,
+		RefID:   refID,
+		Note:    note,
+	}
+	if err := s.walletRepo.AddEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// debit 从指定的资金类型扣减
+func (s *DefaultWalletService) debit(ctx context.Context, funds string, userID uint, amount common.Money, entryType string, refType string, refID uint, note string) (*wallet.Entry, error) {
+	if amount <= 0 {
+		return nil, apierror.NewValidationError("无效的金额", fmt.Sprintf("扣减金额必须大于0: %s", amount))
+	}
+	entry := &wallet.Entry{
+		UserID:  userID,
+		Funds:   funds,
+		Type:    entryType,
+		Amount:  -amount,
+		RefType: refType,
+		RefID:   refID,
+		Note:    note,
+	}
+	if err := s.walletRepo.AddEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// payoutAddress 确定收款地址，未指定时使用账户的收款地址
+func (s *DefaultWalletService) payoutAddress(ctx context.Context, userID uint, toAddress string) (string, error) {
+	if toAddress != "" {
+		if !chain.IsAddress(toAddress) {
+			return "", apierror.NewValidationError("无效的收款地址", toAddress)
+		}
+		return chain.NormalizeAddress(toAddress), nil
+	}
+
+	account, err := s.walletRepo.FindAccount(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if account.PayoutAddress == "" {
+		return "", apierror.NewValidationError("请先设置收款地址", "转账需要收款地址")
+	}
+	return account.PayoutAddress, nil
+}
+
+// normalizePage 设置默认分页参数
+func normalizePage(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"web3-ecommerce-app/internal/domain/wallet"
+	"web3-ecommerce-app/internal/module/wallet/repository"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testPayoutAddress = "0x1111111111111111111111111111111111111111"
+
+func newTestWalletService(t *testing.T) WalletService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "wallet.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	repo := repository.NewGormWalletRepository(db)
+	if err := repo.(*repository.GormWalletRepository).AutoMigrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return NewWalletService(repo)
+}
+
+func TestStoreCreditIsNotWithdrawable(t *testing.T) {
+	s := newTestWalletService(t)
+	ctx := context.Background()
+
+	entry, err := s.CreditStoreCredit(ctx, 7, 10000, wallet.EntryTypeGiftCard, wallet.RefTypeGiftCard, 1, "礼品卡兑换")
+	if err != nil {
+		t.Fatalf("CreditStoreCredit: %v", err)
+	}
+	if entry.Funds != wallet.FundsStoreCredit || entry.BalanceAfter != 10000 {
+		t.Errorf("entry = %+v, want store credit 100.00", entry)
+	}
+	if _, err := s.Credit(ctx, 7, 2000, wallet.EntryTypeRefund, wallet.RefTypeRefund, 1, "退款"); err != nil {
+		t.Fatalf("Credit: %v", err)
+	}
+
+	account, err := s.GetAccount(ctx, 7)
+	if err != nil {
+		t.Fatalf("GetAccount: %v", err)
+	}
+	if account.Balance != 2000 || account.StoreCredit != 10000 {
+		t.Fatalf("account = %+v, want balance 20.00 and store credit 100.00", account)
+	}
+
+	// 提现只能使用可以提现的余额
+	if _, err := s.Withdraw(ctx, 7, wallet.WithdrawInput{Amount: 5000, ToAddress: testPayoutAddress}); err == nil {
+		t.Error("购物金被提现")
+	}
+	if _, err := s.Withdraw(ctx, 7, wallet.WithdrawInput{Amount: 2000, ToAddress: testPayoutAddress}); err != nil {
+		t.Errorf("Withdraw: %v", err)
+	}
+
+	// 购物金可以下单抵扣
+	if _, err := s.DebitStoreCredit(ctx, 7, 6000, wallet.EntryTypeOrderPayment, wallet.RefTypeOrder, 1, "订单抵扣"); err != nil {
+		t.Fatalf("DebitStoreCredit: %v", err)
+	}
+	if _, err := s.DebitStoreCredit(ctx, 7, 5000, wallet.EntryTypeOrderPayment, wallet.RefTypeOrder, 2, "订单抵扣"); err == nil {
+		t.Error("购物金不足时仍然扣减成功")
+	}
+
+	account, _ = s.GetAccount(ctx, 7)
+	if account.Balance != 0 || account.StoreCredit != 4000 {
+		t.Errorf("account = %+v, want balance 0 and store credit 40.00", account)
+	}
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"web3-ecommerce-app/internal/domain/wishlist"
+	"web3-ecommerce-app/internal/module/wishlist/service"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WishlistHTTPHandler 心愿单HTTP处理器
+type WishlistHTTPHandler struct {
+	wishlistService service.WishlistService
+}
+
+// NewWishlistHTTPHandler 创建心愿单HTTP处理器
+func NewWishlistHTTPHandler(wishlistService service.WishlistService) *WishlistHTTPHandler {
+	return &WishlistHTTPHandler{
+		wishlistService: wishlistService,
+	}
+}
+
+// ListWishlist 获取当前用户的心愿单
+func (h *WishlistHTTPHandler) ListWishlist(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.wishlistService.ListItems(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// AddToWishlist 将商品加入心愿单
+func (h *WishlistHTTPHandler) AddToWishlist(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var input wishlist.AddItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	if err := h.wishlistService.AddItem(c.Request.Context(), userID, input.ProductID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已加入心愿单"})
+}
+
+// RemoveFromWishlist 从心愿单移除商品
+func (h *WishlistHTTPHandler) RemoveFromWishlist(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	productID, ok := h.getProductID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.wishlistService.RemoveItem(c.Request.Context(), userID, productID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已从心愿单移除"})
+}
+
+// ListStockAlerts 获取当前用户的到货提醒
+func (h *WishlistHTTPHandler) ListStockAlerts(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.wishlistService.ListAlerts(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"alerts": alerts})
+}
+
+// SubscribeStockAlert 订阅商品的到货提醒
+func (h *WishlistHTTPHandler) SubscribeStockAlert(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var input wishlist.AddItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewValidationError("无效的请求数据", err.Error()),
+		})
+		return
+	}
+
+	if err := h.wishlistService.SubscribeAlert(c.Request.Context(), userID, input.ProductID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已订阅到货提醒"})
+}
+
+// UnsubscribeStockAlert 取消商品的到货提醒
+func (h *WishlistHTTPHandler) UnsubscribeStockAlert(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	productID, ok := h.getProductID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.wishlistService.UnsubscribeAlert(c.Request.Context(), userID, productID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已取消到货提醒"})
+}
+
+// getProductID 解析路径中的商品ID，失败时直接写入错误响应
+func (h *WishlistHTTPHandler) getProductID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("product_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": apierror.NewBadRequestError("无效的商品ID", err.Error()),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// getUserID 获取当前登录用户ID，失败时直接写入错误响应
+func (h *WishlistHTTPHandler) getUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": apierror.NewUnauthorizedError("未授权", "请先登录"),
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// handleError 处理错误
+func (h *WishlistHTTPHandler) handleError(c *gin.Context, err error) {
+	// 检查是否是API错误
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+		return
+	}
+
+	// 默认为内部服务器错误
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": apierror.NewInternalServerError("服务器内部错误", err.Error()),
+	})
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"web3-ecommerce-app/internal/domain/wishlist"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WishlistItemModel 是GORM心愿单模型
+type WishlistItemModel struct {
+	ID        uint `gorm:"primarykey"`
+	UserID    uint `gorm:"not null;uniqueIndex:idx_user_product,priority:1"`
+	ProductID uint `gorm:"not null;uniqueIndex:idx_user_product,priority:2;index:idx_product_id"`
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (WishlistItemModel) TableName() string {
+	return "wishlist_items"
+}
+
+// StockAlertModel 是GORM到货提醒模型
+// ClaimToken记录最近一次认领通知的批次，用于在条件更新后找回被认领的订阅
+type StockAlertModel struct {
+	ID         uint `gorm:"primarykey"`
+	UserID     uint `gorm:"not null;uniqueIndex:idx_user_product,priority:1"`
+	ProductID  uint `gorm:"not null;uniqueIndex:idx_user_product,priority:2;index:idx_product_id"`
+	NotifiedAt *time.Time
+	ClaimToken string `gorm:"type:varchar(32);index:idx_claim_token"`
+	CreatedAt  time.Time
+}
+
+// TableName 指定表名
+func (StockAlertModel) TableName() string {
+	return "stock_alerts"
+}
+
+// GormWishlistRepository 是心愿单仓库的GORM实现
+type GormWishlistRepository struct {
+	db *gorm.DB
+}
+
+// NewGormWishlistRepository 创建一个新的GORM心愿单仓库
+func NewGormWishlistRepository(db *gorm.DB) wishlist.WishlistRepository {
+	return &GormWishlistRepository{db: db}
+}
+
+// FindItems 按加入时间倒序查询用户的心愿单
+func (r *GormWishlistRepository) FindItems(ctx context.Context, userID uint) ([]wishlist.Item, error) {
+	var models []WishlistItemModel
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询心愿单错误: %w", err)
+	}
+
+	items := make([]wishlist.Item, 0, len(models))
+	for _, m := range models {
+		items = append(items, wishlist.Item{ProductID: m.ProductID, CreatedAt: m.CreatedAt})
+	}
+	return items, nil
+}
+
+// AddItem 加入心愿单
+func (r *GormWishlistRepository) AddItem(ctx context.Context, userID uint, productID uint) error {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&WishlistItemModel{UserID: userID, ProductID: productID}).Error; err != nil {
+		return fmt.Errorf("加入心愿单错误: %w", err)
+	}
+	return nil
+}
+
+// RemoveItem 从心愿单移除
+func (r *GormWishlistRepository) RemoveItem(ctx context.Context, userID uint, productID uint) (bool, error) {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", userID, productID).Delete(&WishlistItemModel{})
+	if result.Error != nil {
+		return false, fmt.Errorf("移除心愿单商品错误: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindAlerts 按订阅时间倒序查询用户的到货提醒
+func (r *GormWishlistRepository) FindAlerts(ctx context.Context, userID uint) ([]wishlist.StockAlert, error) {
+	var models []StockAlertModel
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询到货提醒错误: %w", err)
+	}
+
+	alerts := make([]wishlist.StockAlert, 0, len(models))
+	for _, m := range models {
+		alerts = append(alerts, wishlist.StockAlert{ProductID: m.ProductID, NotifiedAt: m.NotifiedAt, CreatedAt: m.CreatedAt})
+	}
+	return alerts, nil
+}
+
+// AddAlert 订阅到货提醒
+func (r *GormWishlistRepository) AddAlert(ctx context.Context, userID uint, productID uint) error {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&StockAlertModel{UserID: userID, ProductID: productID}).Error; err != nil {
+		return fmt.Errorf("订阅到货提醒错误: %w", err)
+	}
+	return nil
+}
+
+// RemoveAlert 取消到货提醒
+func (r *GormWishlistRepository) RemoveAlert(ctx context.Context, userID uint, productID uint) (bool, error) {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", userID, productID).Delete(&StockAlertModel{})
+	if result.Error != nil {
+		return false, fmt.Errorf("取消到货提醒错误: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ClaimAlerts 认领商品本次到货需要通知的订阅
+// 先用一次条件更新写入本批次的标记，再按标记查询，并发处理同一次到货事件时每个订阅只会被认领一次
+func (r *GormWishlistRepository) ClaimAlerts(ctx context.Context, productID uint, now time.Time, restockedAt time.Time) ([]uint, error) {
+	token := strconv.FormatInt(now.UnixNano(), 36) + strconv.FormatUint(uint64(productID), 36)
+
+	if err := r.db.WithContext(ctx).Model(&StockAlertModel{}).
+		Where("product_id = ? AND (notified_at IS NULL OR notified_at < ?)", productID, restockedAt).
+		Updates(map[string]interface{}{"notified_at": now, "claim_token": token}).Error; err != nil {
+		return nil, fmt.Errorf("更新到货提醒错误: %w", err)
+	}
+
+	var userIDs []uint
+	if err := r.db.WithContext(ctx).Model(&StockAlertModel{}).
+		Where("claim_token = ?", token).Order("id").Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, fmt.Errorf("查询到货提醒错误: %w", err)
+	}
+	return userIDs, nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (r *GormWishlistRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&WishlistItemModel{}, &StockAlertModel{})
+}
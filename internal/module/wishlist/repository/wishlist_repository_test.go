@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newWishlistTestDB 创建SQLite测试库
+// SQLite的索引名在整个库内唯一，心愿单表与到货提醒表的索引同名，因此只建到货提醒表
+func newWishlistTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "wishlist.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := db.AutoMigrate(&StockAlertModel{}); err != nil {
+		t.Fatalf("migrate stock alerts: %v", err)
+	}
+	return db
+}
+
+func TestClaimAlertsOncePerRestock(t *testing.T) {
+	repo := NewGormWishlistRepository(newWishlistTestDB(t))
+	ctx := context.Background()
+
+	for _, userID := range []uint{1, 2} {
+		if err := repo.AddAlert(ctx, userID, 9); err != nil {
+			t.Fatalf("AddAlert: %v", err)
+		}
+	}
+
+	first := time.Now().Add(-2 * time.Hour)
+	second := first.Add(time.Hour)
+	claims := []struct {
+		name        string
+		now         time.Time
+		restockedAt time.Time
+		want        []uint
+	}{
+		{name: "第一次到货", now: first.Add(time.Second), restockedAt: first, want: []uint{1, 2}},
+		{name: "重复处理同一次到货", now: first.Add(2 * time.Second), restockedAt: first, want: nil},
+		{name: "一小时后再次到货", now: second.Add(time.Second), restockedAt: second, want: []uint{1, 2}},
+	}
+
+	// 按顺序认领，每一步依赖前一步写入的通知时间
+	for _, claim := range claims {
+		got, err := repo.ClaimAlerts(ctx, 9, claim.now, claim.restockedAt)
+		if err != nil {
+			t.Fatalf("%s: ClaimAlerts: %v", claim.name, err)
+		}
+		if !slices.Equal(got, claim.want) {
+			t.Errorf("%s: claimed %v, want %v", claim.name, got, claim.want)
+		}
+	}
+}
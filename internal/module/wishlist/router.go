@@ -0,0 +1,38 @@
+package wishlist
+
+import (
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/middleware"
+	"web3-ecommerce-app/internal/module/wishlist/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册心愿单模块路由
+func RegisterRoutes(router *gin.Engine, handler *handler.WishlistHTTPHandler, jwtConfig *config.JWTConfig) {
+	// 创建v1版本API路由组
+	v1 := router.Group("/api/v1")
+
+	// 当前用户的心愿单和到货提醒(需要认证)
+	meRoutes := v1.Group("/users/me")
+	meRoutes.Use(middleware.JWT(jwtConfig))
+	{
+		// 获取心愿单
+		meRoutes.GET("/wishlist", handler.ListWishlist)
+
+		// 加入心愿单
+		meRoutes.POST("/wishlist", handler.AddToWishlist)
+
+		// 移出心愿单
+		meRoutes.DELETE("/wishlist/:product_id", handler.RemoveFromWishlist)
+
+		// 获取到货提醒
+		meRoutes.GET("/stock-alerts", handler.ListStockAlerts)
+
+		// 订阅到货提醒
+		meRoutes.POST("/stock-alerts", handler.SubscribeStockAlert)
+
+		// 取消到货提醒
+		meRoutes.DELETE("/stock-alerts/:product_id", handler.UnsubscribeStockAlert)
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"web3-ecommerce-app/internal/domain/product"
+	"web3-ecommerce-app/internal/domain/wishlist"
+	"web3-ecommerce-app/internal/platform/notify"
+	"web3-ecommerce-app/pkg/apierror"
+)
+
+// NotificationTypeBackInStock 到货通知类型
+const NotificationTypeBackInStock = "back_in_stock"
+
+// WishlistService 心愿单服务接口
+type WishlistService interface {
+	// ListItems 获取用户的心愿单
+	ListItems(ctx context.Context, userID uint) ([]wishlist.Item, error)
+
+	// AddItem 将商品加入心愿单
+	AddItem(ctx context.Context, userID uint, productID uint) error
+
+	// RemoveItem 从心愿单移除商品
+	RemoveItem(ctx context.Context, userID uint, productID uint) error
+
+	// ListAlerts 获取用户的到货提醒
+	ListAlerts(ctx context.Context, userID uint) ([]wishlist.StockAlert, error)
+
+	// SubscribeAlert 订阅商品的到货提醒
+	SubscribeAlert(ctx context.Context, userID uint, productID uint) error
+
+	// UnsubscribeAlert 取消商品的到货提醒
+	UnsubscribeAlert(ctx context.Context, userID uint, productID uint) error
+
+	// HandleRestock 处理商品到货事件，通知订阅的用户
+	HandleRestock(ctx context.Context, payload interface{}) error
+}
+
+// DefaultWishlistService 默认心愿单服务实现
+type DefaultWishlistService struct {
+	wishlistRepo wishlist.WishlistRepository
+	productRepo  product.ProductRepository
+	notifier     notify.Notifier
+}
+
+// NewWishlistService 创建心愿单服务
+func NewWishlistService(wishlistRepo wishlist.WishlistRepository, productRepo product.ProductRepository, notifier notify.Notifier) WishlistService {
+	return &DefaultWishlistService{
+		wishlistRepo: wishlistRepo,
+		productRepo:  productRepo,
+		notifier:     notifier,
+	}
+}
+
+// ListItems 获取用户的心愿单，附带商品的最新信息
+func (s *DefaultWishlistService) ListItems(ctx context.Context, userID uint) ([]wishlist.Item, error) {
+	items, err := s.wishlistRepo.FindItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.visibleProducts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Product = products[items[i].ProductID]
+	}
+	return items, nil
+}
+
+// AddItem 将对外可见的商品加入心愿单
+func (s *DefaultWishlistService) AddItem(ctx context.Context, userID uint, productID uint) error {
+	if err := s.checkVisible(ctx, productID); err != nil {
+		return err
+	}
+	return s.wishlistRepo.AddItem(ctx, userID, productID)
+}
+
+// RemoveItem 从心愿单移除商品
+func (s *DefaultWishlistService) RemoveItem(ctx context.Context, userID uint, productID uint) error {
+	ok, err := s.wishlistRepo.RemoveItem(ctx, userID, productID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apierror.NewNotFoundError("心愿单中没有该商品", fmt.Sprintf("商品ID: %d", productID))
+	}
+	return nil
+}
+
+// ListAlerts 获取用户的到货提醒，附带商品的最新信息
+func (s *DefaultWishlistService) ListAlerts(ctx context.Context, userID uint) ([]wishlist.StockAlert, error) {
+	alerts, err := s.wishlistRepo.FindAlerts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(alerts))
+	for _, alert := range alerts {
+		ids = append(ids, alert.ProductID)
+	}
+	products, err := s.visibleProducts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range alerts {
+		alerts[i].Product = products[alerts[i].ProductID]
+	}
+	return alerts, nil
+}
+
+// SubscribeAlert 订阅到货提醒，只能订阅已上架的商品
+func (s *DefaultWishlistService) SubscribeAlert(ctx context.Context, userID uint, productID uint) error {
+	if err := s.checkVisible(ctx, productID); err != nil {
+		return err
+	}
+	return s.wishlistRepo.AddAlert(ctx, userID, productID)
+}
+
+// UnsubscribeAlert 取消到货提醒
+func (s *DefaultWishlistService) UnsubscribeAlert(ctx context.Context, userID uint, productID uint) error {
+	ok, err := s.wishlistRepo.RemoveAlert(ctx, userID, productID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apierror.NewNotFoundError("没有订阅该商品的到货提醒", fmt.Sprintf("商品ID: %d", productID))
+	}
+	return nil
+}
+
+// HandleRestock 处理商品到货事件
+// 先认领订阅再发送通知，每次到货每个用户只会收到一次；发送失败只记录日志，不会重试
+func (s *DefaultWishlistService) HandleRestock(ctx context.Context, payload interface{}) error {
+	event, ok := payload.(product.RestockedEvent)
+	if !ok {
+		return fmt.Errorf("无效的到货事件: %T", payload)
+	}
+
+	userIDs, err := s.wishlistRepo.ClaimAlerts(ctx, event.ProductID, time.Now(), event.RestockedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if err := s.notifier.Send(ctx, notify.Message{
+			UserID: userID,
+			Type:   NotificationTypeBackInStock,
+			Title:  "您关注的商品已到货",
+			Body:   fmt.Sprintf("%s 已到货，库存有限，欢迎选购", event.Name),
+			Data: map[string]string{
+				"product_id": strconv.FormatUint(uint64(event.ProductID), 10),
+			},
+		}); err != nil {
+			log.Printf("发送到货通知失败(用户 %d, 商品 %d): %v", userID, event.ProductID, err)
+		}
+	}
+	if len(userIDs) > 0 {
+		log.Printf("商品 %d 到货，已通知 %d 位用户", event.ProductID, len(userIDs))
+	}
+	return nil
+}
+
+// checkVisible 检查商品存在且对外可见
+func (s *DefaultWishlistService) checkVisible(ctx context.Context, productID uint) error {
+	productEntity, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if !productEntity.IsVisible() {
+		return apierror.NewNotFoundError("商品不存在", fmt.Sprintf("ID: %d", productID))
+	}
+	return nil
+}
+
+// visibleProducts 批量查询对外可见的商品，已删除或下架的商品不返回
+func (s *DefaultWishlistService) visibleProducts(ctx context.Context, ids []uint) (map[uint]*product.Product, error) {
+	products, err := s.productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*product.Product, len(products))
+	for i := range products {
+		if products[i].IsVisible() {
+			byID[products[i].ID] = &products[i]
+		}
+	}
+	return byID, nil
+}
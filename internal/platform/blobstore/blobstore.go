@@ -0,0 +1,39 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"web3-ecommerce-app/internal/config"
+)
+
+// ErrNotFound 表示对象不存在
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore 对象存储接口，屏蔽本地文件系统与S3兼容存储的差异
+type BlobStore interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+
+	// Get 读取对象，不存在时返回ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+
+	// URL 返回对象的公开访问地址
+	URL(key string) string
+}
+
+// New 根据配置创建对象存储
+func New(cfg *config.StorageConfig) (BlobStore, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStore(cfg.LocalDir, cfg.PublicURL)
+	case "s3":
+		return NewS3Store(&cfg.S3, cfg.PublicURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"time"
+	"web3-ecommerce-app/internal/config"
+)
+
+// Cache 键值缓存接口
+// 缓存只用于加速读取，调用方应将错误视为未命中并回源到数据库
+type Cache interface {
+	// Get 读取缓存，不存在时返回false
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set 写入缓存，ttl为0表示不过期
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete 删除缓存，不存在的键会被忽略
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// New 根据配置创建缓存实现，未启用Redis时返回不缓存任何内容的实现
+func New(cfg *config.RedisConfig) Cache {
+	if !cfg.Enabled {
+		return NoopCache{}
+	}
+	return NewRedisCache(cfg)
+}
+
+// NoopCache 不缓存任何内容的实现，所有读取都未命中
+type NoopCache struct{}
+
+// Get 总是未命中
+func (NoopCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+// Set 不做任何事
+func (NoopCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+// Delete 不做任何事
+func (NoopCache) Delete(ctx context.Context, keys ...string) error {
+	return nil
+}
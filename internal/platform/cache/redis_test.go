@@ -0,0 +1,330 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"web3-ecommerce-app/internal/config"
+)
+
+func TestRedisConnEncodesCommand(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	// 参数中的CRLF和非ASCII字符必须按字节长度原样发送
+	want := "*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$7\r\na\r\nb中\r\n"
+	received := make(chan string, 1)
+	go func() {
+		buf := make([]byte, len(want))
+		if _, err := io.ReadFull(server, buf); err != nil {
+			received <- err.Error()
+			return
+		}
+		received <- string(buf)
+		server.Write([]byte("+OK\r\n"))
+	}()
+
+	c := &redisConn{conn: client, rd: bufio.NewReader(client)}
+	reply, err := c.exec(context.Background(), time.Second, "SET", "key", "a\r\nb中")
+	if err != nil {
+		t.Fatalf("exec() error = %v", err)
+	}
+	if got := <-received; got != want {
+		t.Fatalf("wire = %q, want %q", got, want)
+	}
+	if reply != "OK" {
+		t.Fatalf("reply = %v, want OK", reply)
+	}
+}
+
+func TestRedisConnReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "简单字符串", input: "+OK\r\n", want: "OK"},
+		{name: "整数", input: ":42\r\n", want: int64(42)},
+		{name: "负整数", input: ":-1\r\n", want: int64(-1)},
+		{name: "批量字符串", input: "$5\r\nhello\r\n", want: []byte("hello")},
+		{name: "包含CRLF的批量字符串", input: "$4\r\na\r\nb\r\n", want: []byte("a\r\nb")},
+		{name: "空批量字符串", input: "$0\r\n\r\n", want: []byte{}},
+		{name: "空值", input: "$-1\r\n", want: nil},
+		{name: "错误回复", input: "-ERR wrong type\r\n", wantErr: "redis: ERR wrong type"},
+		{name: "缺少CR", input: "+OK\n", wantErr: "malformed line"},
+		{name: "空行", input: "\r\n", wantErr: "empty reply"},
+		{name: "无效长度", input: "$x\r\n", wantErr: "invalid bulk length"},
+		{name: "不支持的类型", input: "*1\r\n", wantErr: "unsupported reply"},
+		{name: "数据不完整", input: "$5\r\nhi\r\n", wantErr: io.ErrUnexpectedEOF.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &redisConn{rd: bufio.NewReader(strings.NewReader(tt.input))}
+			got, err := c.readReply()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readReply() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readReply() error = %v", err)
+			}
+			if b, ok := tt.want.([]byte); ok {
+				if gotBytes, ok := got.([]byte); !ok || !bytes.Equal(gotBytes, b) {
+					t.Fatalf("readReply() = %#v, want %#v", got, tt.want)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Fatalf("readReply() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedisCacheAgainstFakeServer(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	host, port, _ := net.SplitHostPort(server.addr)
+	portNum, _ := strconv.Atoi(port)
+	r := NewRedisCache(&config.RedisConfig{Host: host, Port: portNum, Password: "secret", DB: 2})
+	ctx := context.Background()
+
+	if _, found, err := r.Get(ctx, "missing"); err != nil || found {
+		t.Fatalf("Get(missing) = %v, %v", found, err)
+	}
+	if err := r.Set(ctx, "k", []byte("v1"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if value, found, err := r.Get(ctx, "k"); err != nil || !found || string(value) != "v1" {
+		t.Fatalf("Get(k) = %q, %v, %v", value, found, err)
+	}
+	if ok, err := r.SetNX(ctx, "k", []byte("v2"), time.Minute); err != nil || ok {
+		t.Fatalf("SetNX(existing) = %v, %v", ok, err)
+	}
+	if ok, err := r.SetNX(ctx, "n", []byte("v2"), 0); err != nil || !ok {
+		t.Fatalf("SetNX(new) = %v, %v", ok, err)
+	}
+
+	// 错误回复不应导致连接被丢弃
+	if _, err := r.do(ctx, "BOOM"); !errors.As(err, new(redisError)) {
+		t.Fatalf("do(BOOM) error = %v, want redisError", err)
+	}
+	if err := r.Delete(ctx, "k", "n"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, found, _ := r.Get(ctx, "k"); found {
+		t.Fatal("Get(k) found after Delete")
+	}
+
+	commands := server.log()
+	if len(commands) < 2 || commands[0] != "AUTH secret" || commands[1] != "SELECT 2" {
+		t.Fatalf("handshake = %v, want AUTH then SELECT", commands)
+	}
+	if !containsCommand(commands, "SET k v1 PX 60000") || !containsCommand(commands, "SET n v2 NX") {
+		t.Fatalf("commands = %v, missing SET arguments", commands)
+	}
+	if server.connections() != 1 {
+		t.Fatalf("connections = %d, want 1", server.connections())
+	}
+}
+
+func containsCommand(commands []string, want string) bool {
+	for _, command := range commands {
+		if command == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRedisCacheCompareAndSet(t *testing.T) {
+	server := newFakeRedis(t, "")
+	host, port, _ := net.SplitHostPort(server.addr)
+	portNum, _ := strconv.Atoi(port)
+	r := NewRedisCache(&config.RedisConfig{Host: host, Port: portNum})
+	ctx := context.Background()
+
+	if err := r.Set(ctx, "k", []byte("a"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if ok, err := r.CompareAndSet(ctx, "k", []byte("b"), []byte("c"), time.Minute); err != nil || ok {
+		t.Fatalf("CompareAndSet(mismatch) = %v, %v", ok, err)
+	}
+	if ok, err := r.CompareAndSet(ctx, "k", []byte("a"), []byte("c"), time.Minute); err != nil || !ok {
+		t.Fatalf("CompareAndSet(match) = %v, %v", ok, err)
+	}
+	if ok, err := r.CompareAndDelete(ctx, "k", []byte("a")); err != nil || ok {
+		t.Fatalf("CompareAndDelete(mismatch) = %v, %v", ok, err)
+	}
+	if value, _, _ := r.Get(ctx, "k"); string(value) != "c" {
+		t.Fatalf("Get(k) = %q, want c", value)
+	}
+	if ok, err := r.CompareAndDelete(ctx, "k", []byte("c")); err != nil || !ok {
+		t.Fatalf("CompareAndDelete(match) = %v, %v", ok, err)
+	}
+	if _, found, _ := r.Get(ctx, "k"); found {
+		t.Fatal("Get(k) found after CompareAndDelete")
+	}
+}
+
+// fakeRedis 只支持测试用到的命令的Redis服务端
+type fakeRedis struct {
+	addr     string
+	password string
+
+	mu       sync.Mutex
+	data     map[string]string
+	commands []string
+	conns    int
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on loopback: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &fakeRedis{addr: ln.Addr().String(), password: password, data: map[string]string{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeRedis) log() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *fakeRedis) connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conns
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	authed := s.password == ""
+	for {
+		args, err := readCommand(rd)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, strings.Join(args, " "))
+		reply := s.handle(args, &authed)
+		s.mu.Unlock()
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedis) handle(args []string, authed *bool) string {
+	switch cmd := strings.ToUpper(args[0]); {
+	case cmd == "AUTH":
+		if args[1] != s.password {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		*authed = true
+		return "+OK\r\n"
+	case !*authed:
+		return "-NOAUTH Authentication required.\r\n"
+	case cmd == "SELECT":
+		return "+OK\r\n"
+	case cmd == "GET":
+		value, ok := s.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+	case cmd == "SET":
+		for _, opt := range args[3:] {
+			if strings.ToUpper(opt) == "NX" {
+				if _, ok := s.data[args[1]]; ok {
+					return "$-1\r\n"
+				}
+			}
+		}
+		s.data[args[1]] = args[2]
+		return "+OK\r\n"
+	case cmd == "EVAL" && (args[1] == compareAndSetScript || args[1] == compareAndDeleteScript):
+		key, expected := args[3], args[4]
+		if value, ok := s.data[key]; !ok || value != expected {
+			return ":0\r\n"
+		}
+		if args[1] == compareAndSetScript {
+			s.data[key] = args[5]
+		} else {
+			delete(s.data, key)
+		}
+		return ":1\r\n"
+	case cmd == "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.data[key]; ok {
+				delete(s.data, key)
+				deleted++
+			}
+		}
+		return ":" + strconv.Itoa(deleted) + "\r\n"
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+// readCommand 解析客户端发送的RESP数组
+func readCommand(rd *bufio.Reader) ([]string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, errors.New("expected array")
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		header, err := rd.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, size+2)
+		if _, err := io.ReadFull(rd, value); err != nil {
+			return nil, err
+		}
+		args[i] = string(value[:size])
+	}
+	return args, nil
+}
@@ -0,0 +1,110 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries 缓存条目超过该数量时清理过期条目
+const maxCacheEntries = 10000
+
+// cacheEntry 缓存的查询结果，合约回滚也会被缓存
+type cacheEntry struct {
+	balance   *big.Int
+	owner     string
+	err       error
+	expiresAt time.Time
+}
+
+// CachedReader 为链上查询结果提供短期缓存，避免每次浏览商品都请求节点
+// 网络错误不会被缓存
+type CachedReader struct {
+	reader Reader
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCachedReader 创建带缓存的链上查询实现
+func NewCachedReader(reader Reader, ttl time.Duration) *CachedReader {
+	return &CachedReader{
+		reader:  reader,
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// BalanceOf 查询ERC-20余额或ERC-721持有数量
+func (c *CachedReader) BalanceOf(ctx context.Context, chainID int64, contract string, owner string) (*big.Int, error) {
+	key := fmt.Sprintf("balance:%s", fakeKey(chainID, contract, owner, nil))
+	entry, err := c.load(key, func() (cacheEntry, error) {
+		balance, err := c.reader.BalanceOf(ctx, chainID, contract, owner)
+		return cacheEntry{balance: balance}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(entry.balance), nil
+}
+
+// OwnerOf 查询ERC-721代币的所有者
+func (c *CachedReader) OwnerOf(ctx context.Context, chainID int64, contract string, tokenID *big.Int) (string, error) {
+	key := fmt.Sprintf("owner:%s", fakeKey(chainID, contract, "", tokenID))
+	entry, err := c.load(key, func() (cacheEntry, error) {
+		owner, err := c.reader.OwnerOf(ctx, chainID, contract, tokenID)
+		return cacheEntry{owner: owner}, err
+	})
+	if err != nil {
+		return "", err
+	}
+	return entry.owner, nil
+}
+
+// BalanceOfToken 查询ERC-1155指定代币的持有数量
+func (c *CachedReader) BalanceOfToken(ctx context.Context, chainID int64, contract string, owner string, tokenID *big.Int) (*big.Int, error) {
+	key := fmt.Sprintf("token:%s", fakeKey(chainID, contract, owner, tokenID))
+	entry, err := c.load(key, func() (cacheEntry, error) {
+		balance, err := c.reader.BalanceOfToken(ctx, chainID, contract, owner, tokenID)
+		return cacheEntry{balance: balance}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(entry.balance), nil
+}
+
+// load 返回未过期的缓存结果，否则执行查询并缓存
+func (c *CachedReader) load(key string, fetch func() (cacheEntry, error)) (cacheEntry, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry, entry.err
+	}
+
+	entry, err := fetch()
+	if err != nil && err != ErrExecutionReverted {
+		return entry, err
+	}
+	entry.err = err
+	entry.expiresAt = now.Add(c.ttl)
+
+	c.mu.Lock()
+	if len(c.entries) >= maxCacheEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	return entry, err
+}
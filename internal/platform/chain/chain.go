@@ -0,0 +1,63 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"web3-ecommerce-app/internal/config"
+)
+
+// ErrExecutionReverted 表示合约调用被回滚，例如查询不存在的NFT的所有者
+var ErrExecutionReverted = errors.New("execution reverted")
+
+// Reader 链上只读查询接口，屏蔽JSON-RPC节点与测试用假实现的差异
+// 地址参数均为0x开头的十六进制字符串，大小写不敏感
+type Reader interface {
+	// BalanceOf 查询ERC-20代币余额或ERC-721持有数量
+	BalanceOf(ctx context.Context, chainID int64, contract string, owner string) (*big.Int, error)
+
+	// OwnerOf 查询ERC-721代币的所有者，代币不存在时返回ErrExecutionReverted
+	OwnerOf(ctx context.Context, chainID int64, contract string, tokenID *big.Int) (string, error)
+
+	// BalanceOfToken 查询ERC-1155指定代币的持有数量
+	BalanceOfToken(ctx context.Context, chainID int64, contract string, owner string, tokenID *big.Int) (*big.Int, error)
+}
+
+var addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// IsAddress 判断是否为合法的以太坊地址
+func IsAddress(s string) bool {
+	return addressPattern.MatchString(s)
+}
+
+// NormalizeAddress 将地址统一为小写，便于比较和作为缓存key
+func NormalizeAddress(s string) string {
+	return strings.ToLower(s)
+}
+
+// New 根据配置创建链上查询实现
+func New(cfg *config.Web3Config) (Reader, error) {
+	switch cfg.ChainReader {
+	case "", "rpc":
+		endpoints := make(map[int64]string, len(cfg.RPCURLs)+1)
+		if cfg.RPCURL != "" {
+			endpoints[int64(cfg.ChainID)] = cfg.RPCURL
+		}
+		for chainID, url := range cfg.RPCURLs {
+			id, err := strconv.ParseInt(chainID, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid chain id in rpc_urls: %q", chainID)
+			}
+			endpoints[id] = url
+		}
+		return NewRPCReader(endpoints), nil
+	case "fake":
+		return NewFakeReader(), nil
+	default:
+		return nil, fmt.Errorf("unsupported chain reader: %s", cfg.ChainReader)
+	}
+}
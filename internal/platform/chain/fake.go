@@ -0,0 +1,81 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// FakeReader 内存中的链上状态，用于测试和本地开发
+type FakeReader struct {
+	mu            sync.RWMutex
+	balances      map[string]*big.Int
+	owners        map[string]string
+	tokenBalances map[string]*big.Int
+}
+
+// NewFakeReader 创建空的内存链上状态
+func NewFakeReader() *FakeReader {
+	return &FakeReader{
+		balances:      make(map[string]*big.Int),
+		owners:        make(map[string]string),
+		tokenBalances: make(map[string]*big.Int),
+	}
+}
+
+// SetBalance 设置ERC-20余额或ERC-721持有数量
+func (f *FakeReader) SetBalance(chainID int64, contract string, owner string, balance *big.Int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.balances[fakeKey(chainID, contract, owner, nil)] = balance
+}
+
+// SetOwner 设置ERC-721代币的所有者
+func (f *FakeReader) SetOwner(chainID int64, contract string, tokenID *big.Int, owner string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.owners[fakeKey(chainID, contract, "", tokenID)] = NormalizeAddress(owner)
+}
+
+// SetTokenBalance 设置ERC-1155指定代币的持有数量
+func (f *FakeReader) SetTokenBalance(chainID int64, contract string, owner string, tokenID *big.Int, balance *big.Int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokenBalances[fakeKey(chainID, contract, owner, tokenID)] = balance
+}
+
+// BalanceOf 查询ERC-20余额或ERC-721持有数量，未设置时为0
+func (f *FakeReader) BalanceOf(ctx context.Context, chainID int64, contract string, owner string) (*big.Int, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if balance, ok := f.balances[fakeKey(chainID, contract, owner, nil)]; ok {
+		return new(big.Int).Set(balance), nil
+	}
+	return new(big.Int), nil
+}
+
+// OwnerOf 查询ERC-721代币的所有者，未设置时视为代币不存在
+func (f *FakeReader) OwnerOf(ctx context.Context, chainID int64, contract string, tokenID *big.Int) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if owner, ok := f.owners[fakeKey(chainID, contract, "", tokenID)]; ok {
+		return owner, nil
+	}
+	return "", ErrExecutionReverted
+}
+
+// BalanceOfToken 查询ERC-1155指定代币的持有数量，未设置时为0
+func (f *FakeReader) BalanceOfToken(ctx context.Context, chainID int64, contract string, owner string, tokenID *big.Int) (*big.Int, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if balance, ok := f.tokenBalances[fakeKey(chainID, contract, owner, tokenID)]; ok {
+		return new(big.Int).Set(balance), nil
+	}
+	return new(big.Int), nil
+}
+
+// fakeKey 生成状态的查找key
+func fakeKey(chainID int64, contract string, owner string, tokenID *big.Int) string {
+	return fmt.Sprintf("%d:%s:%s:%v", chainID, NormalizeAddress(contract), NormalizeAddress(owner), tokenID)
+}
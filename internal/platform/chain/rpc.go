@@ -0,0 +1,175 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 合约方法选择器(方法签名keccak256哈希的前4字节)
+const (
+	selectorBalanceOf      = "70a08231" // balanceOf(address)
+	selectorOwnerOf        = "6352211e" // ownerOf(uint256)
+	selectorBalanceOfToken = "00fdd58e" // balanceOf(address,uint256)
+)
+
+// RPCReader 通过以太坊JSON-RPC的eth_call查询合约状态
+// 只需要手工编码三个固定签名的方法，因此没有引入完整的ABI库
+type RPCReader struct {
+	endpoints map[int64]string
+	client    *http.Client
+	nextID    atomic.Int64
+}
+
+// NewRPCReader 创建JSON-RPC查询实现，endpoints为链ID到节点地址的映射
+func NewRPCReader(endpoints map[int64]string) *RPCReader {
+	return &RPCReader{
+		endpoints: endpoints,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// BalanceOf 查询ERC-20余额或ERC-721持有数量
+func (r *RPCReader) BalanceOf(ctx context.Context, chainID int64, contract string, owner string) (*big.Int, error) {
+	ownerWord, err := encodeAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.call(ctx, chainID, contract, selectorBalanceOf+ownerWord)
+	if err != nil {
+		return nil, err
+	}
+	return decodeUint(result)
+}
+
+// OwnerOf 查询ERC-721代币的所有者
+func (r *RPCReader) OwnerOf(ctx context.Context, chainID int64, contract string, tokenID *big.Int) (string, error) {
+	result, err := r.call(ctx, chainID, contract, selectorOwnerOf+encodeUint(tokenID))
+	if err != nil {
+		return "", err
+	}
+	if len(result) < 32 {
+		return "", fmt.Errorf("unexpected ownerOf result length %d", len(result))
+	}
+	return "0x" + hex.EncodeToString(result[12:32]), nil
+}
+
+// BalanceOfToken 查询ERC-1155指定代币的持有数量
+func (r *RPCReader) BalanceOfToken(ctx context.Context, chainID int64, contract string, owner string, tokenID *big.Int) (*big.Int, error) {
+	ownerWord, err := encodeAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.call(ctx, chainID, contract, selectorBalanceOfToken+ownerWord+encodeUint(tokenID))
+	if err != nil {
+		return nil, err
+	}
+	return decodeUint(result)
+}
+
+// rpcRequest JSON-RPC请求
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int64         `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// rpcResponse JSON-RPC响应
+type rpcResponse struct {
+	Result string `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call 对最新区块执行eth_call，返回解码后的返回数据
+func (r *RPCReader) call(ctx context.Context, chainID int64, contract string, data string) ([]byte, error) {
+	endpoint, ok := r.endpoints[chainID]
+	if !ok {
+		return nil, fmt.Errorf("no rpc endpoint configured for chain %d", chainID)
+	}
+	if !IsAddress(contract) {
+		return nil, fmt.Errorf("invalid contract address: %s", contract)
+	}
+
+	payload, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      r.nextID.Add(1),
+		Method:  "eth_call",
+		Params: []interface{}{
+			map[string]string{"to": contract, "data": "0x" + data},
+			"latest",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rpc request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rpc request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rpc request failed: status %d", resp.StatusCode)
+	}
+
+	var body rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode rpc response: %w", err)
+	}
+	if body.Error != nil {
+		// 节点对合约回滚使用的错误码不统一，按消息判断
+		if body.Error.Code == 3 || strings.Contains(strings.ToLower(body.Error.Message), "revert") {
+			return nil, ErrExecutionReverted
+		}
+		return nil, fmt.Errorf("rpc error %d: %s", body.Error.Code, body.Error.Message)
+	}
+
+	result, err := hex.DecodeString(strings.TrimPrefix(body.Result, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid rpc result: %w", err)
+	}
+	// 对不存在的合约调用时节点返回空数据
+	if len(result) == 0 {
+		return nil, ErrExecutionReverted
+	}
+	return result, nil
+}
+
+// encodeAddress 将地址编码为32字节的ABI参数
+func encodeAddress(address string) (string, error) {
+	if !IsAddress(address) {
+		return "", fmt.Errorf("invalid address: %s", address)
+	}
+	return strings.Repeat("0", 24) + strings.ToLower(address[2:]), nil
+}
+
+// encodeUint 将无符号整数编码为32字节的ABI参数
+func encodeUint(n *big.Int) string {
+	return fmt.Sprintf("%064x", n)
+}
+
+// decodeUint 解码返回数据中的第一个uint256
+func decodeUint(result []byte) (*big.Int, error) {
+	if len(result) < 32 {
+		return nil, fmt.Errorf("unexpected uint256 result length %d", len(result))
+	}
+	return new(big.Int).SetBytes(result[:32]), nil
+}
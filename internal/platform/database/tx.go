@@ -0,0 +1,27 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey 在context中保存事务的键
+type txKey struct{}
+
+// Transaction 在事务中执行fn
+// fn收到的ctx携带该事务，仓库通过Conn取得连接后，不同仓库的写入会提交或回滚在同一个事务中；
+// 已在事务中时嵌套为保存点
+func Transaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	return Conn(ctx, db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Conn 返回ctx中的事务，不在事务中时返回db本身
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
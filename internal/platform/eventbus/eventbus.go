@@ -0,0 +1,54 @@
+package eventbus
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Handler 事件处理函数，payload的具体类型由事件主题约定
+type Handler func(ctx context.Context, payload interface{}) error
+
+// Bus 进程内事件总线，用于在模块之间传递领域事件而不直接互相依赖
+// 事件异步分发，处理函数的错误只记录日志，不影响发布方
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	wg       sync.WaitGroup
+}
+
+// New 创建事件总线
+func New() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe 订阅指定主题的事件
+func (b *Bus) Subscribe(topic string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[topic] = append(b.handlers[topic], handler)
+}
+
+// Publish 发布事件，每个处理函数在独立的goroutine中执行
+// 处理函数使用与发布方请求解耦的context，请求结束后仍会继续执行
+func (b *Bus) Publish(ctx context.Context, topic string, payload interface{}) {
+	b.mu.RLock()
+	handlers := b.handlers[topic]
+	b.mu.RUnlock()
+
+	ctx = context.WithoutCancel(ctx)
+	for _, handler := range handlers {
+		b.wg.Add(1)
+		go func(handler Handler) {
+			defer b.wg.Done()
+			if err := handler(ctx, payload); err != nil {
+				log.Printf("处理事件 %s 失败: %v", topic, err)
+			}
+		}(handler)
+	}
+}
+
+// Wait 等待已发布的事件处理完成，服务器关闭时调用
+func (b *Bus) Wait() {
+	b.wg.Wait()
+}
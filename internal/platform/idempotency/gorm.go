@@ -0,0 +1,125 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/platform/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordModel 是GORM幂等记录模型
+type RecordModel struct {
+	Key         string    `gorm:"type:varchar(300);primarykey"`
+	Fingerprint string    `gorm:"type:varchar(64);not null"`
+	Owner       string    `gorm:"type:varchar(64);not null;default:''"`
+	Completed   bool      `gorm:"not null;default:false"`
+	Status      int       `gorm:"not null;default:0"`
+	ContentType string    `gorm:"type:varchar(100)"`
+	Body        []byte    `gorm:"type:mediumblob"`
+	ExpiresAt   time.Time `gorm:"not null;index:idx_expires_at"`
+	CreatedAt   time.Time
+}
+
+// TableName 指定表名
+func (RecordModel) TableName() string {
+	return "idempotency_keys"
+}
+
+// GormStore 基于数据库的实现，多实例部署时共享记录
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore 创建数据库幂等记录存储
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+// Reserve 插入处理中的记录，主键冲突说明key已存在
+// 已存在的记录过期时先删除再重新插入
+func (s *GormStore) Reserve(ctx context.Context, key string, fingerprint string, owner string, ttl time.Duration) (*Record, error) {
+	conn := database.Conn(ctx, s.db)
+	for attempt := 0; attempt < 2; attempt++ {
+		now := time.Now()
+		result := conn.Clauses(clause.OnConflict{DoNothing: true}).Create(&RecordModel{
+			Key:         key,
+			Fingerprint: fingerprint,
+			Owner:       owner,
+			ExpiresAt:   now.Add(ttl),
+		})
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to reserve idempotency key: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return nil, nil
+		}
+
+		var models []RecordModel
+		if err := conn.Where("`key` = ?", key).Limit(1).Find(&models).Error; err != nil {
+			return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+		}
+		if len(models) == 0 {
+			continue
+		}
+		if now.Before(models[0].ExpiresAt) {
+			return &Record{
+				Fingerprint: models[0].Fingerprint,
+				Owner:       models[0].Owner,
+				Completed:   models[0].Completed,
+				Status:      models[0].Status,
+				ContentType: models[0].ContentType,
+				Body:        models[0].Body,
+			}, nil
+		}
+		if err := conn.Where("`key` = ? AND expires_at <= ?", key, now).Delete(&RecordModel{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete expired idempotency key: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("failed to reserve idempotency key %s: concurrent reservation", key)
+}
+
+// Complete 以owner和处理中状态为条件保存响应
+func (s *GormStore) Complete(ctx context.Context, key string, owner string, record *Record, ttl time.Duration) error {
+	result := database.Conn(ctx, s.db).Model(&RecordModel{}).
+		Where("`key` = ? AND owner = ? AND completed = ?", key, owner, false).
+		Updates(map[string]interface{}{
+			"completed":    true,
+			"status":       record.Status,
+			"content_type": record.ContentType,
+			"body":         record.Body,
+			"expires_at":   time.Now().Add(ttl),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// Release 删除owner的处理中记录
+func (s *GormStore) Release(ctx context.Context, key string, fingerprint string, owner string) error {
+	if err := database.Conn(ctx, s.db).Where("`key` = ? AND fingerprint = ? AND owner = ? AND completed = ?", key, fingerprint, owner, false).
+		Delete(&RecordModel{}).Error; err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired 删除已过期的记录
+func (s *GormStore) DeleteExpired(ctx context.Context) error {
+	if err := database.Conn(ctx, s.db).Where("expires_at <= ?", time.Now()).
+		Delete(&RecordModel{}).Error; err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return nil
+}
+
+// AutoMigrate 自动迁移数据库表结构
+func (s *GormStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&RecordModel{})
+}
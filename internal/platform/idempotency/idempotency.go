@@ -0,0 +1,60 @@
+// Package idempotency 保存带幂等键的请求及其响应，客户端重试时返回第一次请求的结果
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/config"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotOwner 记录已不属于调用方：处理超过锁定时长后被其他请求重新预留，或已经保存过响应
+var ErrNotOwner = errors.New("idempotency key is no longer held by this request")
+
+// Record 一个幂等键对应的请求记录
+type Record struct {
+	Fingerprint string `json:"fingerprint"`     // 请求方法、路径和请求体的摘要
+	Owner       string `json:"owner,omitempty"` // 预留记录的请求生成的随机令牌，只有该请求可以保存响应或释放记录
+	Completed   bool   `json:"completed"`       // 为false表示第一次请求仍在处理中
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Store 幂等记录存储接口
+type Store interface {
+	// Reserve 为第一次出现的key创建属于owner的处理中记录并返回nil，key已存在时返回已有的记录
+	// 处理中的记录在ttl后过期，过期后同一个key可以重新预留
+	Reserve(ctx context.Context, key string, fingerprint string, owner string, ttl time.Duration) (*Record, error)
+
+	// Complete 保存请求的响应，记录在ttl后过期
+	// 记录不再是owner的处理中记录时不做修改并返回ErrNotOwner
+	Complete(ctx context.Context, key string, owner string, record *Record, ttl time.Duration) error
+
+	// Release 删除owner以fingerprint预留的处理中记录，请求失败时调用，使客户端可以用同一个key重试
+	// 记录已被其他请求重新预留或已保存响应时不做任何事
+	Release(ctx context.Context, key string, fingerprint string, owner string) error
+
+	// DeleteExpired 删除已过期的记录，依赖存储自身过期机制的实现不做任何事
+	DeleteExpired(ctx context.Context) error
+}
+
+// New 根据配置创建幂等记录存储
+func New(cfg *config.IdempotencyConfig, redisCfg *config.RedisConfig, db *gorm.DB) (Store, error) {
+	switch cfg.Driver {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "database":
+		return NewGormStore(db), nil
+	case "redis":
+		if !redisCfg.Enabled {
+			return nil, fmt.Errorf("idempotency driver redis requires redis to be enabled")
+		}
+		return NewRedisStore(redisCfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported idempotency driver: %s", cfg.Driver)
+	}
+}
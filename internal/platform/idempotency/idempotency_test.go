@@ -0,0 +1,82 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestGormStore(t *testing.T) *GormStore {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "idempotency.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	store := NewGormStore(db)
+	if err := store.AutoMigrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return store
+}
+
+func TestStoreOwnership(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory":   func(t *testing.T) Store { return NewMemoryStore() },
+		"database": func(t *testing.T) Store { return newTestGormStore(t) },
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+			response := func(status int) *Record {
+				return &Record{Fingerprint: "fp", Completed: true, Status: status, ContentType: "application/json", Body: []byte("{}")}
+			}
+
+			// 第一个请求的锁定时长已过，重试的请求重新预留
+			if existing, err := store.Reserve(ctx, "k", "fp", "first", -time.Second); err != nil || existing != nil {
+				t.Fatalf("Reserve(first) = %v, %v", existing, err)
+			}
+			if existing, err := store.Reserve(ctx, "k", "fp", "second", time.Minute); err != nil || existing != nil {
+				t.Fatalf("Reserve(second) = %v, %v", existing, err)
+			}
+
+			// 超时的第一个请求不能删除或覆盖第二个请求的记录
+			if err := store.Release(ctx, "k", "fp", "first"); err != nil {
+				t.Fatalf("Release(first): %v", err)
+			}
+			if err := store.Complete(ctx, "k", "first", response(201), time.Hour); !errors.Is(err, ErrNotOwner) {
+				t.Fatalf("Complete(first) error = %v, want ErrNotOwner", err)
+			}
+			existing, err := store.Reserve(ctx, "k", "fp", "third", time.Minute)
+			if err != nil || existing == nil || existing.Completed {
+				t.Fatalf("Reserve(third) = %+v, %v, want in-flight record", existing, err)
+			}
+
+			if err := store.Complete(ctx, "k", "second", response(202), time.Hour); err != nil {
+				t.Fatalf("Complete(second): %v", err)
+			}
+			if err := store.Complete(ctx, "k", "second", response(500), time.Hour); !errors.Is(err, ErrNotOwner) {
+				t.Fatalf("Complete(second) again error = %v, want ErrNotOwner", err)
+			}
+			if err := store.Release(ctx, "k", "fp", "second"); err != nil {
+				t.Fatalf("Release(second): %v", err)
+			}
+			existing, err = store.Reserve(ctx, "k", "fp", "third", time.Minute)
+			if err != nil || existing == nil || !existing.Completed || existing.Status != 202 {
+				t.Fatalf("Reserve after complete = %+v, %v, want status 202", existing, err)
+			}
+		})
+	}
+}
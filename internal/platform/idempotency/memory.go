@@ -0,0 +1,84 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryEntry 内存中的记录及其过期时间
+type memoryEntry struct {
+	record    Record
+	expiresAt time.Time
+}
+
+// MemoryStore 基于内存的实现，只适用于单实例部署，重启后记录丢失
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+// NewMemoryStore 创建内存幂等记录存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*memoryEntry)}
+}
+
+// Reserve 创建处理中的记录，已存在未过期的记录时返回其副本
+func (s *MemoryStore) Reserve(ctx context.Context, key string, fingerprint string, owner string, ttl time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		record := entry.record
+		return &record, nil
+	}
+	s.entries[key] = &memoryEntry{
+		record:    Record{Fingerprint: fingerprint, Owner: owner},
+		expiresAt: now.Add(ttl),
+	}
+	return nil, nil
+}
+
+// Complete 保存响应
+func (s *MemoryStore) Complete(ctx context.Context, key string, owner string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.holds(key, owner) {
+		return ErrNotOwner
+	}
+	s.entries[key] = &memoryEntry{record: *record, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Release 删除owner的处理中记录，已完成或被重新预留的记录不受影响
+func (s *MemoryStore) Release(ctx context.Context, key string, fingerprint string, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.holds(key, owner) && s.entries[key].record.Fingerprint == fingerprint {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// holds 记录是否为owner的处理中记录，调用方需持有锁
+func (s *MemoryStore) holds(key string, owner string) bool {
+	entry, ok := s.entries[key]
+	return ok && !entry.record.Completed && entry.record.Owner == owner
+}
+
+// DeleteExpired 删除已过期的记录
+func (s *MemoryStore) DeleteExpired(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
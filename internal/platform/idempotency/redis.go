@@ -0,0 +1,91 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/platform/cache"
+)
+
+// RedisStore 基于Redis的实现，记录依靠键的过期时间自动删除
+type RedisStore struct {
+	redis *cache.RedisCache
+}
+
+// NewRedisStore 创建Redis幂等记录存储
+func NewRedisStore(cfg *config.RedisConfig) *RedisStore {
+	return &RedisStore{redis: cache.NewRedisCache(cfg)}
+}
+
+// Reserve 通过SET NX创建处理中的记录
+// 读取已有记录时该记录可能恰好过期，此时再尝试一次
+func (s *RedisStore) Reserve(ctx context.Context, key string, fingerprint string, owner string, ttl time.Duration) (*Record, error) {
+	value, err := json.Marshal(Record{Fingerprint: fingerprint, Owner: owner})
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		ok, err := s.redis.SetNX(ctx, key, value, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+		if ok {
+			return nil, nil
+		}
+
+		existing, found, err := s.redis.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+		}
+		if !found {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(existing, &record); err != nil {
+			return nil, fmt.Errorf("failed to decode idempotency key: %w", err)
+		}
+		return &record, nil
+	}
+	return nil, fmt.Errorf("failed to reserve idempotency key %s: concurrent reservation", key)
+}
+
+// Complete 键的值仍是owner预留时写入的处理中记录时保存响应
+func (s *RedisStore) Complete(ctx context.Context, key string, owner string, record *Record, ttl time.Duration) error {
+	reserved, err := json.Marshal(Record{Fingerprint: record.Fingerprint, Owner: owner})
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	ok, err := s.redis.CompareAndSet(ctx, key, reserved, value, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	if !ok {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// Release 键的值仍是owner预留时写入的处理中记录时删除
+// 处理中记录的内容由fingerprint和owner确定，比较整个值即可判断记录是否仍属于调用方
+func (s *RedisStore) Release(ctx context.Context, key string, fingerprint string, owner string) error {
+	reserved, err := json.Marshal(Record{Fingerprint: fingerprint, Owner: owner})
+	if err != nil {
+		return err
+	}
+	if _, err := s.redis.CompareAndDelete(ctx, key, reserved); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired Redis自动删除过期的键，不需要清理
+func (s *RedisStore) DeleteExpired(ctx context.Context) error {
+	return nil
+}
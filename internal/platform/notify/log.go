@@ -0,0 +1,24 @@
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// LogNotifier 只把通知写入日志，用于开发环境
+type LogNotifier struct{}
+
+// NewLogNotifier 创建日志通知实现
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Send 将通知写入日志，Secrets中的内容不会写入日志
+func (n *LogNotifier) Send(ctx context.Context, msg Message) error {
+	if len(msg.Secrets) > 0 {
+		log.Printf("通知用户 %d [%s] %s: %s (已隐去 %d 项敏感内容)", msg.UserID, msg.Type, msg.Title, msg.Body, len(msg.Secrets))
+		return nil
+	}
+	log.Printf("通知用户 %d [%s] %s: %s", msg.UserID, msg.Type, msg.Title, msg.Body)
+	return nil
+}
@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestLogNotifierRedactsSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(prev) })
+
+	err := NewLogNotifier().Send(context.Background(), Message{
+		UserID:  7,
+		Type:    "gift_card_issued",
+		Title:   "礼品卡已发放",
+		Body:    "尾号 WXYZ 面额 100.00",
+		Data:    map[string]string{"order_sn": "SN001"},
+		Secrets: map[string]string{"12": "ABCD-EFGH-JKLM-WXYZ"},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "ABCD-EFGH-JKLM-WXYZ") {
+		t.Errorf("日志中出现了兑换码: %s", out)
+	}
+	if !strings.Contains(out, "尾号 WXYZ") || !strings.Contains(out, "已隐去 1 项敏感内容") {
+		t.Errorf("日志内容不完整: %s", out)
+	}
+}
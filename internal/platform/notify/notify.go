@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"web3-ecommerce-app/internal/config"
+)
+
+// Message 发送给用户的通知
+// 兑换码等敏感内容只能放在Secrets中，由投递渠道直接交给用户，不能写入Body或日志
+type Message struct {
+	UserID  uint              `json:"user_id"`
+	Type    string            `json:"type"`
+	Title   string            `json:"title"`
+	Body    string            `json:"body"`
+	Data    map[string]string `json:"data,omitempty"`
+	Secrets map[string]string `json:"secrets,omitempty"`
+}
+
+// Notifier 通知发送接口，屏蔽具体的投递渠道
+type Notifier interface {
+	// Send 发送一条通知
+	Send(ctx context.Context, msg Message) error
+}
+
+// New 根据配置创建通知发送实现
+func New(cfg *config.NotifyConfig) (Notifier, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return NewLogNotifier(), nil
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("notify webhook_url is required")
+		}
+		return NewWebhookNotifier(cfg.WebhookURL, cfg.WebhookSecret, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unsupported notify driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,30 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Every 按固定间隔执行任务，直到ctx被取消
+// 任务返回的错误只记录日志，不会中断后续执行
+func Every(ctx context.Context, name string, interval time.Duration, task func(ctx context.Context) error) {
+	if interval <= 0 {
+		log.Printf("定时任务 %s 未启动: 执行间隔无效 (%s)", name, interval)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := task(ctx); err != nil {
+				log.Printf("定时任务 %s 执行失败: %v", name, err)
+			}
+		}
+	}
+}
@@ -18,6 +18,9 @@ const (
 	ErrorCodeValidationFailed    ErrorCode = "VALIDATION_FAILED"
 	ErrorCodeDuplicateEntity     ErrorCode = "DUPLICATE_ENTITY"
 	ErrorCodeWeb3SignatureError  ErrorCode = "WEB3_SIGNATURE_ERROR"
+	ErrorCodeInsufficientStock   ErrorCode = "INSUFFICIENT_STOCK"
+	ErrorCodeInvalidTransition   ErrorCode = "INVALID_STATE_TRANSITION"
+	ErrorCodeIdempotencyConflict ErrorCode = "IDEMPOTENCY_CONFLICT"
 )
 
 // APIError 表示API错误
@@ -115,3 +118,33 @@ func NewWeb3SignatureError(message string, detail string) *APIError {
 		Status:  http.StatusBadRequest,
 	}
 }
+
+// NewInsufficientStockError 创建库存不足错误
+func NewInsufficientStockError(message string, detail string) *APIError {
+	return &APIError{
+		Code:    ErrorCodeInsufficientStock,
+		Message: message,
+		Detail:  detail,
+		Status:  http.StatusConflict,
+	}
+}
+
+// NewInvalidStateTransitionError 创建状态变更不合法错误
+func NewInvalidStateTransitionError(message string, detail string) *APIError {
+	return &APIError{
+		Code:    ErrorCodeInvalidTransition,
+		Message: message,
+		Detail:  detail,
+		Status:  http.StatusConflict,
+	}
+}
+
+// NewIdempotencyConflictError 创建幂等键冲突错误
+func NewIdempotencyConflictError(message string, detail string) *APIError {
+	return &APIError{
+		Code:    ErrorCodeIdempotencyConflict,
+		Message: message,
+		Detail:  detail,
+		Status:  http.StatusConflict,
+	}
+}
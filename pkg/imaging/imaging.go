@@ -0,0 +1,137 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	// 注册GIF解码器
+	_ "image/gif"
+)
+
+// MaxPixels 允许解码的最大像素数，防止解压炸弹耗尽内存
+const MaxPixels = 40_000_000
+
+// 支持的图片MIME类型
+const (
+	MIMEJPEG = "image/jpeg"
+	MIMEPNG  = "image/png"
+	MIMEGIF  = "image/gif"
+)
+
+// ErrUnsupportedFormat 表示不支持的图片格式
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// DetectMIME 根据文件内容嗅探图片类型，不信任客户端声明的Content-Type
+func DetectMIME(data []byte) (string, error) {
+	switch mime := http.DetectContentType(data); mime {
+	case MIMEJPEG, MIMEPNG, MIMEGIF:
+		return mime, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, mime)
+	}
+}
+
+// Extension 返回MIME类型对应的文件扩展名
+func Extension(mime string) string {
+	switch mime {
+	case MIMEJPEG:
+		return ".jpg"
+	case MIMEPNG:
+		return ".png"
+	case MIMEGIF:
+		return ".gif"
+	default:
+		return ""
+	}
+}
+
+// Decode 解码图片，解码前先检查尺寸
+func Decode(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image header: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, fmt.Errorf("image dimensions %dx%d exceed limit", cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// Resize 将图片等比缩放到指定宽度，宽度不小于原图时直接返回原图
+// 使用区域平均采样，缩小时比最近邻插值有更好的画质
+func Resize(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if width <= 0 || width >= srcW {
+		return src
+	}
+
+	height := srcH * width / srcW
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		sy0 := bounds.Min.Y + y*srcH/height
+		sy1 := bounds.Min.Y + (y+1)*srcH/height
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < width; x++ {
+			sx0 := bounds.Min.X + x*srcW/width
+			sx1 := bounds.Min.X + (x+1)*srcW/width
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+// Encode 按MIME类型编码图片，GIF缩略图统一输出为PNG
+// 返回编码后的数据和实际使用的MIME类型
+func Encode(img image.Image, mime string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	switch mime {
+	case MIMEJPEG:
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", fmt.Errorf("failed to encode jpeg: %w", err)
+		}
+		return buf.Bytes(), MIMEJPEG, nil
+	default:
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", fmt.Errorf("failed to encode png: %w", err)
+		}
+		return buf.Bytes(), MIMEPNG, nil
+	}
+}
@@ -0,0 +1,268 @@
+// Package pdf 纯Go实现的简单PDF生成器，只支持文本和直线，适用于发票等表单类文件
+//
+// 西文使用PDF标准字体Helvetica，中文使用阅读器内置的Adobe宋体(STSong-Light)，
+// 两者都不需要嵌入字体文件。相同的输入总是生成相同的字节。
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// A4纸张尺寸，单位为点(1/72英寸)
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Align 文本的水平对齐方式
+type Align int
+
+const (
+	AlignLeft  Align = iota // x为文本左端
+	AlignRight              // x为文本右端
+)
+
+// Style 文本样式
+type Style struct {
+	Size  float64 // 字号，单位为点
+	Bold  bool    // 只对西文生效
+	Align Align
+}
+
+// 字体资源名称
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+	fontCJK     = "F3"
+)
+
+// Document PDF文档
+type Document struct {
+	pages []*Page
+}
+
+// Page 文档中的一页，坐标原点为页面左上角，y轴向下
+type Page struct {
+	content bytes.Buffer
+}
+
+// New 创建空文档
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage 添加一页A4纸
+func (d *Document) AddPage() *Page {
+	page := &Page{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Text 在(x, y)处绘制单行文本，y为基线位置
+func (p *Page) Text(x, y float64, style Style, s string) {
+	if s == "" {
+		return
+	}
+	if style.Align == AlignRight {
+		x -= TextWidth(s, style)
+	}
+
+	p.content.WriteString("BT\n")
+	fmt.Fprintf(&p.content, "%s Td\n", point(x, PageHeight-y))
+	for _, r := range splitRuns(s) {
+		if r.cjk {
+			fmt.Fprintf(&p.content, "/%s %s Tf <%s> Tj\n", fontCJK, number(style.Size), encodeCJK(r.text))
+		} else {
+			fmt.Fprintf(&p.content, "/%s %s Tf (%s) Tj\n", westernFont(style), number(style.Size), escape(r.text))
+		}
+	}
+	p.content.WriteString("ET\n")
+}
+
+// Line 从(x1, y1)到(x2, y2)绘制直线
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s m %s l S\n", number(width), point(x1, PageHeight-y1), point(x2, PageHeight-y2))
+}
+
+// TextWidth 计算文本按指定样式绘制时的宽度
+func TextWidth(s string, style Style) float64 {
+	widths := &helveticaWidths
+	if style.Bold {
+		widths = &helveticaBoldWidths
+	}
+
+	units := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			units += widths[r-32]
+		} else {
+			units += 1000
+		}
+	}
+	return float64(units) * style.Size / 1000
+}
+
+// Truncate 截断文本使其宽度不超过maxWidth，截断时以"..."结尾
+func Truncate(s string, style Style, maxWidth float64) string {
+	if TextWidth(s, style) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if candidate := string(runes) + "..."; TextWidth(candidate, style) <= maxWidth {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Bytes 生成PDF文件内容
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	d.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// WriteTo 将PDF文件写入w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	pages := d.pages
+	if len(pages) == 0 {
+		pages = []*Page{{}}
+	}
+
+	// 对象编号: 1目录 2页面树 3-7字体 之后每页依次为页面和内容流
+	const firstPage = 8
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // 页面树在确定页面对象编号后生成
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [6 0 R] >>",
+		"<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /FontDescriptor 7 0 R /DW 1000 >>",
+		"<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+			"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>",
+	}
+
+	kids := make([]string, 0, len(pages))
+	for i, page := range pages {
+		pageObj := firstPage + i*2
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Contents %d 0 R "+
+				"/Resources << /Font << /%s 3 0 R /%s 4 0 R /%s 5 0 R >> >> >>",
+				number(PageWidth), number(PageHeight), pageObj+1, fontRegular, fontBold, fontCJK),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// run 使用同一种字体绘制的连续文本
+type run struct {
+	text string
+	cjk  bool
+}
+
+// splitRuns 按字体将文本拆分为西文和中文片段
+func splitRuns(s string) []run {
+	var runs []run
+	var current strings.Builder
+	cjk := false
+	for i, r := range s {
+		isCJK := r < 32 || r > 126
+		if i > 0 && isCJK != cjk {
+			runs = append(runs, run{text: current.String(), cjk: cjk})
+			current.Reset()
+		}
+		cjk = isCJK
+		current.WriteRune(r)
+	}
+	if current.Len() > 0 {
+		runs = append(runs, run{text: current.String(), cjk: cjk})
+	}
+	return runs
+}
+
+// westernFont 返回西文片段使用的字体
+func westernFont(style Style) string {
+	if style.Bold {
+		return fontBold
+	}
+	return fontRegular
+}
+
+// escape 转义PDF字符串中的特殊字符
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
+
+// encodeCJK 将文本编码为UTF-16BE十六进制串，基本多文种平面以外的字符替换为问号
+func encodeCJK(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 0xFFFF || utf16.IsSurrogate(r) {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// point 格式化坐标
+func point(x, y float64) string {
+	return number(x) + " " + number(y)
+}
+
+// number 格式化数字，最多保留两位小数
+func number(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// helveticaWidths Helvetica字体ASCII 32-126的字符宽度，单位为千分之一字号
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// helveticaBoldWidths Helvetica-Bold字体ASCII 32-126的字符宽度
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
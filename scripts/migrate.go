@@ -4,10 +4,31 @@ import (
 	"fmt"
 	"log"
 	"web3-ecommerce-app/internal/config"
+	addressRepo "web3-ecommerce-app/internal/module/address/repository"
+	cartRepo "web3-ecommerce-app/internal/module/cart/repository"
+	digitalRepo "web3-ecommerce-app/internal/module/digital/repository"
+	giftCardRepo "web3-ecommerce-app/internal/module/giftcard/repository"
+	invoiceRepo "web3-ecommerce-app/internal/module/invoice/repository"
+	merchantRepo "web3-ecommerce-app/internal/module/merchant/repository"
+	orderRepo "web3-ecommerce-app/internal/module/order/repository"
+	productRepo "web3-ecommerce-app/internal/module/product/repository"
+	promotionRepo "web3-ecommerce-app/internal/module/promotion/repository"
+	reviewRepo "web3-ecommerce-app/internal/module/review/repository"
+	shippingRepo "web3-ecommerce-app/internal/module/shipping/repository"
+	subscriptionRepo "web3-ecommerce-app/internal/module/subscription/repository"
+	taxRepo "web3-ecommerce-app/internal/module/tax/repository"
 	"web3-ecommerce-app/internal/module/user/repository"
+	walletRepo "web3-ecommerce-app/internal/module/wallet/repository"
+	wishlistRepo "web3-ecommerce-app/internal/module/wishlist/repository"
 	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/internal/platform/idempotency"
 )
 
+// migrator 表示支持自动迁移表结构的仓库
+type migrator interface {
+	AutoMigrate() error
+}
+
 func main() {
 	// 加载配置
 	cfg, err := config.LoadConfig("configs/config.yaml")
@@ -34,5 +55,41 @@ func main() {
 		log.Fatalf("迁移失败: %v", err)
 	}
 
+	// 其它模块的仓库
+	repos := []interface{}{
+		productRepo.NewGormProductRepository(db),
+		productRepo.NewGormInventoryRepository(db),
+		productRepo.NewGormImageRepository(db),
+		productRepo.NewGormGateRuleRepository(db),
+		productRepo.NewGormPriceRepository(db),
+		promotionRepo.NewGormPromotionRepository(db),
+		reviewRepo.NewGormReviewRepository(db),
+		wishlistRepo.NewGormWishlistRepository(db),
+		cartRepo.NewGormCartRepository(db),
+		addressRepo.NewGormAddressRepository(db),
+		shippingRepo.NewGormZoneRepository(db),
+		taxRepo.NewGormRateRepository(db),
+		walletRepo.NewGormWalletRepository(db),
+		merchantRepo.NewGormMerchantRepository(db),
+		subscriptionRepo.NewGormSubscriptionRepository(db),
+		giftCardRepo.NewGormGiftCardRepository(db),
+		orderRepo.NewGormOrderRepository(db),
+		orderRepo.NewGormSNSegmentRepository(db),
+		digitalRepo.NewGormLicenseKeyRepository(db),
+		digitalRepo.NewGormAssetRepository(db),
+		digitalRepo.NewGormDownloadGrantRepository(db),
+		invoiceRepo.NewGormDocumentRepository(db),
+		idempotency.NewGormStore(db),
+	}
+	for _, repo := range repos {
+		m, ok := repo.(migrator)
+		if !ok {
+			log.Fatalf("无法转换仓库类型: %T", repo)
+		}
+		if err := m.AutoMigrate(); err != nil {
+			log.Fatalf("迁移失败: %v", err)
+		}
+	}
+
 	fmt.Println("数据库迁移成功完成")
 }
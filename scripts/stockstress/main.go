@@ -0,0 +1,145 @@
+// stockstress 对单个SKU发起大量并发预占，验证库存预占在高并发下不会超卖
+//
+// 用法: go run ./scripts/stockstress -stock 50 -workers 500
+// 需要先执行 scripts/migrate.go 创建数据表；
+// 不依赖MySQL的并发测试见 internal/module/product/repository/inventory_repository_test.go
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+	"web3-ecommerce-app/internal/config"
+	"web3-ecommerce-app/internal/domain/product"
+	productRepo "web3-ecommerce-app/internal/module/product/repository"
+	"web3-ecommerce-app/internal/platform/database"
+	"web3-ecommerce-app/pkg/apierror"
+
+	"gorm.io/gorm/logger"
+)
+
+func main() {
+	stock := flag.Int("stock", 50, "商品初始库存")
+	workers := flag.Int("workers", 500, "并发预占的goroutine数量")
+	quantity := flag.Int("quantity", 1, "每次预占的数量")
+	flag.Parse()
+
+	// 加载配置
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err != nil {
+		log.Fatalf("无法加载配置: %v", err)
+	}
+
+	// 初始化数据库连接
+	db, err := database.NewGormDB(&cfg.Database)
+	if err != nil {
+		log.Fatalf("数据库连接失败: %v", err)
+	}
+	db.Logger = db.Logger.LogMode(logger.Error)
+
+	ctx := context.Background()
+	productRepository := productRepo.NewGormProductRepository(db)
+	inventoryRepository := productRepo.NewGormInventoryRepository(db)
+
+	// 创建一个专用于压测的商品
+	target := &product.Product{
+		SKU:    fmt.Sprintf("STRESS-%d", time.Now().UnixNano()),
+		Name:   "库存压测商品",
+		Stock:  *stock,
+		Status: product.ProductStatusDraft,
+	}
+	if err := productRepository.Create(ctx, target); err != nil {
+		log.Fatalf("创建商品失败: %v", err)
+	}
+	defer productRepository.Delete(ctx, target.ID)
+
+	// 使用足够大的订单ID区间，避免与真实订单的预占记录冲突
+	baseOrderID := uint(time.Now().UnixMicro())
+
+	// 所有goroutine同时开始预占同一个SKU
+	var succeeded, rejected, failed int64
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	expiresAt := time.Now().Add(time.Hour)
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func(orderID uint) {
+			defer wg.Done()
+			<-start
+			_, err := inventoryRepository.Reserve(ctx, orderID, []product.ReservationItem{
+				{ProductID: target.ID, Quantity: *quantity},
+			}, expiresAt)
+
+			var apiErr *apierror.APIError
+			switch {
+			case err == nil:
+				atomic.AddInt64(&succeeded, 1)
+			case errors.As(err, &apiErr) && apiErr.Code == apierror.ErrorCodeInsufficientStock:
+				atomic.AddInt64(&rejected, 1)
+			default:
+				atomic.AddInt64(&failed, 1)
+				log.Printf("预占出错: %v", err)
+			}
+		}(baseOrderID + uint(i))
+	}
+	began := time.Now()
+	close(start)
+	wg.Wait()
+
+	reloaded, err := productRepository.FindByID(ctx, target.ID)
+	if err != nil {
+		log.Fatalf("查询商品失败: %v", err)
+	}
+
+	expected := *stock / *quantity
+	if expected > *workers {
+		expected = *workers
+	}
+	fmt.Printf("耗时 %s, 成功 %d, 库存不足 %d, 出错 %d\n", time.Since(began), succeeded, rejected, failed)
+	fmt.Printf("库存 %d, 已预占 %d\n", reloaded.Stock, reloaded.Reserved)
+
+	if failed > 0 || int(succeeded) != expected || reloaded.Reserved != int(succeeded)*(*quantity) {
+		log.Fatalf("校验失败: 期望成功 %d 次，实际 %d 次，已预占 %d", expected, succeeded, reloaded.Reserved)
+	}
+
+	// 一半订单支付确认，另一半释放，最终库存应与确认数量一致
+	for i := 0; i < *workers; i++ {
+		orderID := baseOrderID + uint(i)
+		if i%2 == 0 {
+			err = inventoryRepository.ConfirmByOrder(ctx, orderID)
+		} else {
+			err = inventoryRepository.ReleaseByOrder(ctx, orderID)
+		}
+		if err != nil {
+			log.Fatalf("处理订单 %d 的预占失败: %v", orderID, err)
+		}
+	}
+
+	var confirmed int
+	for i := 0; i < *workers; i += 2 {
+		reservations, err := inventoryRepository.FindByOrder(ctx, baseOrderID+uint(i))
+		if err != nil {
+			log.Fatalf("查询预占失败: %v", err)
+		}
+		for _, r := range reservations {
+			if r.ProductID == target.ID && r.Status == product.ReservationStatusConfirmed {
+				confirmed += r.Quantity
+			}
+		}
+	}
+
+	reloaded, err = productRepository.FindByID(ctx, target.ID)
+	if err != nil {
+		log.Fatalf("查询商品失败: %v", err)
+	}
+	if reloaded.Reserved != 0 || reloaded.Stock != *stock-confirmed {
+		log.Fatalf("校验失败: 库存 %d, 已预占 %d, 已确认 %d", reloaded.Stock, reloaded.Reserved, confirmed)
+	}
+
+	fmt.Printf("校验通过: 确认扣减 %d, 剩余库存 %d\n", confirmed, reloaded.Stock)
+}